@@ -95,6 +95,19 @@ func (al *CouchbaseRemoteAnalyticsLink) Validate() error {
 		(len(al.Encryption.ClientKey) == 0 && len(al.Encryption.ClientCertificate) > 0) {
 		return makeInvalidArgumentsError("client certificate and client key must be set together for couchbase analytics links")
 	}
+	if (al.Username != "" && al.Password == "") || (al.Username == "" && al.Password != "") {
+		return makeInvalidArgumentsError("username and password must be set together for couchbase analytics links")
+	}
+	if al.Username != "" && len(al.Encryption.ClientCertificate) > 0 {
+		return makeInvalidArgumentsError("username and password cannot be used alongside a client certificate for couchbase analytics links")
+	}
+	if al.Username == "" && len(al.Encryption.ClientCertificate) == 0 {
+		if al.Encryption.EncryptionLevel == AnalyticsEncryptionLevelFull {
+			return makeInvalidArgumentsError("either username and password or client certificate and client key must be set for couchbase analytics links")
+		}
+
+		return makeInvalidArgumentsError("username and password must be set for couchbase analytics links")
+	}
 
 	return nil
 }
@@ -208,6 +221,14 @@ func (al *AzureBlobExternalAnalyticsLink) Validate() error {
 	if al.LinkName == "" {
 		return makeInvalidArgumentsError("name must be set for azureblob analytics links")
 	}
+	if al.ConnectionString == "" {
+		if al.AccountName == "" {
+			return makeInvalidArgumentsError("connection string or account name must be set for azureblob analytics links")
+		}
+		if al.AccountKey == "" && al.SharedAccessSignature == "" {
+			return makeInvalidArgumentsError("account key or shared access signature must be set alongside account name for azureblob analytics links")
+		}
+	}
 
 	return nil
 }
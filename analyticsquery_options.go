@@ -28,12 +28,36 @@ type AnalyticsOptions struct {
 	Priority             bool
 	PositionalParameters []interface{}
 	NamedParameters      map[string]interface{}
-	Readonly             bool
-	ScanConsistency      AnalyticsScanConsistency
+
+	// Deferred submits the query to be run asynchronously. The analytics service responds as soon as the query has
+	// been accepted, without any rows, and AnalyticsResult.DeferredHandle can then be used to poll for the status of
+	// the query and to stream its rows once it has completed.
+	// UNCOMMITTED: This API may change in the future.
+	Deferred bool
+
+	// Readonly indicates that the statement must not modify any data. The analytics service enforces this by
+	// rejecting DDL and DML statements, such as CREATE DATASET or INSERT, with an error before they are executed.
+	Readonly bool
+
+	ScanConsistency AnalyticsScanConsistency
+
+	// ConsistentWith causes the query to wait until the datasets include at least the mutations held by the state.
+	// Cannot be used with ScanConsistency.
+	// UNCOMMITTED: This API may change in the future.
+	ConsistentWith *MutationState
+
+	// QueryContext is the context in which unqualified dataset names within the statement are resolved, in the form
+	// "default:`bucket`.`scope`". Scope.AnalyticsQuery defaults this to the scope that it is called on.
+	// UNCOMMITTED: This API may change in the future.
+	QueryContext string
 
 	// Raw provides a way to provide extra parameters in the request body for the query.
 	Raw map[string]interface{}
 
+	// Deserializer is used to decode the rows returned by Row and One, defaults to JSONDeserializer.
+	// UNCOMMITTED: This API may change in the future.
+	Deserializer Deserializer
+
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 
@@ -59,6 +83,10 @@ func (opts *AnalyticsOptions) toMap() (map[string]interface{}, error) {
 		execOpts["client_context_id"] = opts.ClientContextID
 	}
 
+	if opts.ScanConsistency != 0 && opts.ConsistentWith != nil {
+		return nil, makeInvalidArgumentsError("ScanConsistency and ConsistentWith must be used exclusively")
+	}
+
 	if opts.ScanConsistency != 0 {
 		if opts.ScanConsistency == AnalyticsScanConsistencyNotBounded {
 			execOpts["scan_consistency"] = "not_bounded"
@@ -69,6 +97,11 @@ func (opts *AnalyticsOptions) toMap() (map[string]interface{}, error) {
 		}
 	}
 
+	if opts.ConsistentWith != nil {
+		execOpts["scan_consistency"] = "at_plus"
+		execOpts["scan_vectors"] = opts.ConsistentWith
+	}
+
 	if opts.PositionalParameters != nil && opts.NamedParameters != nil {
 		return nil, makeInvalidArgumentsError("positional and named parameters must be used exclusively")
 	}
@@ -90,6 +123,14 @@ func (opts *AnalyticsOptions) toMap() (map[string]interface{}, error) {
 		execOpts["readonly"] = true
 	}
 
+	if opts.Deferred {
+		execOpts["mode"] = "async"
+	}
+
+	if opts.QueryContext != "" {
+		execOpts["query_context"] = opts.QueryContext
+	}
+
 	if opts.Raw != nil {
 		for k, v := range opts.Raw {
 			execOpts[k] = v
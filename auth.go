@@ -80,6 +80,20 @@ type CertificateAuthenticator struct {
 	ClientCertificate *tls.Certificate
 }
 
+// NewCertificateAuthenticatorFromPEM creates a CertificateAuthenticator from a PEM encoded client certificate and
+// private key held in memory, e.g. fetched from a secrets manager.
+// UNCOMMITTED: This API may change in the future.
+func NewCertificateAuthenticatorFromPEM(certPEM, keyPEM []byte) (CertificateAuthenticator, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return CertificateAuthenticator{}, makeInvalidArgumentsError("failed to parse client certificate: " + err.Error())
+	}
+
+	return CertificateAuthenticator{
+		ClientCertificate: &cert,
+	}, nil
+}
+
 // SupportsTLS returns whether this authenticator can authenticate a TLS connection.
 // VOLATILE: This API is subject to change at any time.
 func (ca CertificateAuthenticator) SupportsTLS() bool {
@@ -107,27 +121,29 @@ func (ca CertificateAuthenticator) Credentials(req AuthCredsRequest) ([]UserPass
 	}}, nil
 }
 
+// coreAuthWrapper looks up the authenticator on every call so that any change to the authenticator, such as
+// following a password change, is picked up by any subsequently created connections.
 type coreAuthWrapper struct {
-	auth Authenticator
+	auth func() Authenticator
 }
 
 func (auth *coreAuthWrapper) SupportsTLS() bool {
-	return auth.auth.SupportsTLS()
+	return auth.auth().SupportsTLS()
 }
 
 func (auth *coreAuthWrapper) SupportsNonTLS() bool {
-	return auth.auth.SupportsNonTLS()
+	return auth.auth().SupportsNonTLS()
 }
 
 func (auth *coreAuthWrapper) Certificate(req gocbcore.AuthCertRequest) (*tls.Certificate, error) {
-	return auth.auth.Certificate(AuthCertRequest{
+	return auth.auth().Certificate(AuthCertRequest{
 		Service:  ServiceType(req.Service),
 		Endpoint: req.Endpoint,
 	})
 }
 
 func (auth *coreAuthWrapper) Credentials(req gocbcore.AuthCredsRequest) ([]gocbcore.UserPassPair, error) {
-	creds, err := auth.auth.Credentials(AuthCredsRequest{
+	creds, err := auth.auth().Credentials(AuthCredsRequest{
 		Service:  ServiceType(req.Service),
 		Endpoint: req.Endpoint,
 	})
@@ -0,0 +1,46 @@
+package gocb
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+)
+
+func (suite *UnitTestSuite) TestNewCertificateAuthenticatorFromPEM() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().Nil(err, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gocb"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	suite.Require().Nil(err, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	suite.Require().Nil(err, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	auth, err := NewCertificateAuthenticatorFromPEM(certPEM, keyPEM)
+	suite.Require().Nil(err, err)
+
+	cert, err := auth.Certificate(AuthCertRequest{})
+	suite.Require().Nil(err, err)
+	suite.Require().NotNil(cert)
+	suite.Assert().Equal(certDER, cert.Certificate[0])
+
+	_, err = NewCertificateAuthenticatorFromPEM(certPEM, []byte("not a key"))
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
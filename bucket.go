@@ -13,35 +13,82 @@ type Bucket struct {
 	timeoutsConfig TimeoutsConfig
 
 	transcoder           Transcoder
+	deserializer         Deserializer
 	retryStrategyWrapper *retryStrategyWrapper
 	tracer               RequestTracer
 	meter                *meterWrapper
+	searchCapabilities   *searchCapabilities
+
+	preparedStatementCache *PreparedStatementCache
+	resultMemoryLimiter    *resultMemoryLimiter
+	observeBatcher         *observeBatcher
+	activeQueries          *activeQueryTracker
 
 	useServerDurations bool
 	useMutationTokens  bool
+	kvWarmupTimeout    time.Duration
+
+	durabilityFallback bool
 
 	bootstrapError    error
 	connectionManager connectionManager
+
+	circuitBreakers   *circuitBreakers
+	capabilityWatcher *capabilityWatcher
+	kvNodes           *kvNodeDirectory
+	kvPinner          *kvNodePinner
+	serverGroups      *serverGroupResolver
 }
 
 func newBucket(c *Cluster, bucketName string) *Bucket {
-	return &Bucket{
+	b := &Bucket{
 		bucketName: bucketName,
 
 		timeoutsConfig: c.timeoutsConfig,
 
-		transcoder: c.transcoder,
+		transcoder:   c.transcoder,
+		deserializer: c.deserializer,
 
 		retryStrategyWrapper: c.retryStrategyWrapper,
 
 		tracer: c.tracer,
 		meter:  c.meter,
 
+		searchCapabilities: c.searchCapabilities,
+
+		preparedStatementCache: c.preparedStatementCache,
+		resultMemoryLimiter:    c.resultMemoryLimiter,
+		observeBatcher:         c.observeBatcher,
+		activeQueries:          c.activeQueries,
+
 		useServerDurations: c.useServerDurations,
 		useMutationTokens:  c.useMutationTokens,
+		kvWarmupTimeout:    c.kvWarmupTimeout,
+
+		durabilityFallback: c.durabilityFallback,
 
 		connectionManager: c.connectionManager,
+		circuitBreakers:   c.circuitBreakers,
+	}
+
+	if c.pinKVToBootstrapHosts || c.preferredServerGroup != "" {
+		b.kvNodes = newKVNodeDirectory(b, c.preferredServerGroup != "")
 	}
+
+	if c.pinKVToBootstrapHosts {
+		addresses := c.connSpec().Addresses
+		hosts := make([]string, len(addresses))
+		for i, address := range addresses {
+			hosts[i] = address.Host
+		}
+		b.kvPinner = newKVNodePinner(b.kvNodes, hosts)
+	}
+
+	if c.preferredServerGroup != "" {
+		b.serverGroups = newServerGroupResolver(b.kvNodes, c.preferredServerGroup)
+	}
+
+	return b
 }
 
 func (b *Bucket) setBootstrapError(err error) {
@@ -84,7 +131,20 @@ func (b *Bucket) getQueryProvider() (queryProvider, error) {
 		return nil, err
 	}
 
-	return agent, nil
+	return b.circuitBreakers.wrapQueryProvider(agent), nil
+}
+
+func (b *Bucket) getSearchProvider() (searchProvider, error) {
+	if b.bootstrapError != nil {
+		return nil, b.bootstrapError
+	}
+
+	agent, err := b.connectionManager.getSearchProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	return b.circuitBreakers.wrapSearchProvider(agent), nil
 }
 
 func (b *Bucket) getAnalyticsProvider() (analyticsProvider, error) {
@@ -97,7 +157,7 @@ func (b *Bucket) getAnalyticsProvider() (analyticsProvider, error) {
 		return nil, err
 	}
 
-	return agent, nil
+	return b.circuitBreakers.wrapAnalyticsProvider(agent), nil
 }
 
 // Name returns the name of the bucket.
@@ -153,6 +213,11 @@ func (b *Bucket) Collections() *CollectionManager {
 // If no services are specified then will wait until KeyValue is ready.
 // Valid service types are: ServiceTypeKeyValue, ServiceTypeManagement, ServiceTypeQuery, ServiceTypeSearch,
 // ServiceTypeAnalytics, ServiceTypeViews.
+// A DesiredState of ClusterStateDegraded returns once at least one endpoint of each service is available, rather
+// than waiting for all of them.
+// If the timeout is reached then a *WaitUntilReadyError is returned, reporting the state of each service. The services
+// are pinged once more in order to build the report, which uses up to the last tenth of the timeout, capped at 2
+// seconds.
 func (b *Bucket) WaitUntilReady(timeout time.Duration, opts *WaitUntilReadyOptions) error {
 	if opts == nil {
 		opts = &WaitUntilReadyOptions{}
@@ -178,14 +243,13 @@ func (b *Bucket) WaitUntilReady(timeout time.Duration, opts *WaitUntilReadyOptio
 		gocbcoreServices[i] = gocbcore.ServiceType(svc)
 	}
 
-	wrapper := b.retryStrategyWrapper
-	if opts.RetryStrategy != nil {
-		wrapper = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	wrapper := waitUntilReadyRetryStrategy(b.retryStrategyWrapper, opts)
 
+	deadline := time.Now().Add(timeout)
+	reportTime := waitUntilReadyReportTime(timeout)
 	err = provider.WaitUntilReady(
 		opts.Context,
-		time.Now().Add(timeout),
+		deadline.Add(-reportTime),
 		gocbcore.WaitUntilReadyOptions{
 			DesiredState:  gocbcore.ClusterState(desiredState),
 			ServiceTypes:  gocbcoreServices,
@@ -193,7 +257,25 @@ func (b *Bucket) WaitUntilReady(timeout time.Duration, opts *WaitUntilReadyOptio
 		},
 	)
 	if err != nil {
-		return maybeEnhanceCoreErr(err)
+		diagProvider, diagErr := b.connectionManager.getDiagnosticsProvider(b.bucketName)
+		if diagErr != nil {
+			return maybeEnhanceCoreErr(err)
+		}
+
+		if len(services) == 0 {
+			services = []ServiceType{ServiceTypeKeyValue}
+		}
+
+		return waitUntilReadyError(err, services, desiredState, diagProvider, b.tracer, b.timeoutsConfig, reportTime)
+	}
+
+	if opts.WarmKVConnections {
+		diagProvider, err := b.connectionManager.getDiagnosticsProvider(b.bucketName)
+		if err != nil {
+			return err
+		}
+
+		return waitForKVConnectionPool(opts.Context, deadline, b.kvWarmupTimeout, diagProvider)
 	}
 
 	return nil
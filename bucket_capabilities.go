@@ -0,0 +1,257 @@
+package gocb
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultCapabilityPollInterval = 2500 * time.Millisecond
+
+var watchedCapabilities = []Capability{
+	CapabilityDurableWrites,
+	CapabilityCreateAsDeleted,
+	CapabilityReplaceBodyWithXattr,
+}
+
+// CapabilityChangeEvent is the payload passed to a CapabilityChangeListener.
+// VOLATILE: This API is subject to change at any time.
+type CapabilityChangeEvent struct {
+	// BucketName is the name of the bucket whose capability changed.
+	BucketName string
+	// Capability is the capability which changed.
+	Capability Capability
+	// PreviousStatus is the status of the capability before the change.
+	PreviousStatus CapabilityStatus
+	// Status is the status of the capability after the change.
+	Status CapabilityStatus
+}
+
+// CapabilityChangeListener is invoked whenever the support for a capability on an open bucket changes, for example
+// when a node running an older server version is added to the cluster during a rolling downgrade.
+// VOLATILE: This API is subject to change at any time.
+type CapabilityChangeListener func(event CapabilityChangeEvent)
+
+// CapabilityConfig specifies options for detecting changes to the capabilities of open buckets.
+// VOLATILE: This API is subject to change at any time.
+type CapabilityConfig struct {
+	// ChangeListener is invoked from a dedicated goroutine per bucket, events are delivered one at a time and in
+	// order so a slow listener will delay subsequent events but will never block SDK operations. The listener may
+	// close the cluster, once Cluster.Close has returned no further events are delivered.
+	ChangeListener CapabilityChangeListener
+
+	// RequiredCapabilities are capabilities which the application depends on. Whilst any of these is reported as
+	// unsupported by a bucket, KV operations against that bucket fail fast with ErrFeatureNotAvailable rather than
+	// being sent to the server.
+	RequiredCapabilities []Capability
+
+	// PollInterval is how often the capabilities of each open bucket are checked, defaults to 2.5s.
+	PollInterval time.Duration
+}
+
+type capabilityWatcher struct {
+	bucket   *Bucket
+	listener CapabilityChangeListener
+	required []Capability
+	interval time.Duration
+
+	lastStatuses map[Capability]CapabilityStatus
+
+	lostLock sync.Mutex
+	lostErr  error
+
+	// events passes changes from the polling goroutine to the goroutine which calls the listener, so that the
+	// listener can stop the watcher without waiting for itself to return.
+	events chan CapabilityChangeEvent
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+}
+
+func newCapabilityWatcher(bucket *Bucket, config CapabilityConfig) *capabilityWatcher {
+	interval := config.PollInterval
+	if interval == 0 {
+		interval = defaultCapabilityPollInterval
+	}
+
+	return &capabilityWatcher{
+		bucket:       bucket,
+		listener:     config.ChangeListener,
+		required:     config.RequiredCapabilities,
+		interval:     interval,
+		lastStatuses: make(map[Capability]CapabilityStatus),
+		events:       make(chan CapabilityChangeEvent),
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+}
+
+func (cw *capabilityWatcher) start() {
+	go cw.loop()
+	if cw.listener != nil {
+		go cw.dispatch()
+	}
+}
+
+func (cw *capabilityWatcher) stop() {
+	cw.stopOnce.Do(func() {
+		close(cw.stopCh)
+	})
+	<-cw.doneCh
+}
+
+func (cw *capabilityWatcher) loop() {
+	defer close(cw.doneCh)
+
+	for {
+		cw.poll()
+
+		select {
+		case <-cw.stopCh:
+			return
+		case <-time.After(cw.interval):
+		}
+	}
+}
+
+func (cw *capabilityWatcher) poll() {
+	statuses := make(map[Capability]CapabilityStatus, len(watchedCapabilities))
+	for _, capability := range watchedCapabilities {
+		status, err := cw.bucket.Internal().CapabilityStatus(capability)
+		if err != nil {
+			logDebugf("Failed to fetch bucket capabilities: %v", err)
+			return
+		}
+
+		statuses[capability] = status
+	}
+
+	events := cw.update(statuses)
+	if cw.listener == nil {
+		return
+	}
+
+	for _, event := range events {
+		if !cw.deliver(event) {
+			return
+		}
+	}
+}
+
+// deliver waits for the listener to accept the event, or for the watcher to be stopped, returning false if it was
+// stopped.
+func (cw *capabilityWatcher) deliver(event CapabilityChangeEvent) bool {
+	select {
+	case cw.events <- event:
+		return true
+	case <-cw.stopCh:
+		return false
+	}
+}
+
+func (cw *capabilityWatcher) dispatch() {
+	for {
+		select {
+		case event := <-cw.events:
+			select {
+			case <-cw.stopCh:
+				return
+			default:
+			}
+
+			cw.listener(event)
+		case <-cw.stopCh:
+			return
+		}
+	}
+}
+
+// update records the latest capability statuses, returning an event for each capability which has changed between
+// two known statuses. The statuses first seen for the bucket are taken as the baseline and do not produce events.
+func (cw *capabilityWatcher) update(statuses map[Capability]CapabilityStatus) []CapabilityChangeEvent {
+	var events []CapabilityChangeEvent
+	for _, capability := range watchedCapabilities {
+		status, ok := statuses[capability]
+		if !ok || status == CapabilityStatusUnknown {
+			continue
+		}
+
+		previous, seen := cw.lastStatuses[capability]
+		cw.lastStatuses[capability] = status
+		if !seen || previous == status {
+			continue
+		}
+
+		if status == CapabilityStatusUnsupported {
+			logWarnFieldsf(logFields{bucket: cw.bucket.Name()}, "Bucket %s no longer supports capability %s",
+				cw.bucket.Name(), capabilityToString(capability))
+		}
+
+		events = append(events, CapabilityChangeEvent{
+			BucketName:     cw.bucket.Name(),
+			Capability:     capability,
+			PreviousStatus: previous,
+			Status:         status,
+		})
+	}
+
+	var lostErr error
+	for _, capability := range cw.required {
+		if cw.lastStatuses[capability] == CapabilityStatusUnsupported {
+			lostErr = wrapError(ErrFeatureNotAvailable, fmt.Sprintf("required capability %s is not supported by bucket %s",
+				capabilityToString(capability), cw.bucket.Name()))
+			break
+		}
+	}
+
+	cw.lostLock.Lock()
+	cw.lostErr = lostErr
+	cw.lostLock.Unlock()
+
+	return events
+}
+
+func (cw *capabilityWatcher) requiredCapabilityErr() error {
+	if cw == nil {
+		return nil
+	}
+
+	cw.lostLock.Lock()
+	defer cw.lostLock.Unlock()
+
+	return cw.lostErr
+}
+
+func (c *Cluster) startCapabilityWatcher(b *Bucket) {
+	if c.capabilityConfig.ChangeListener == nil && len(c.capabilityConfig.RequiredCapabilities) == 0 {
+		return
+	}
+
+	c.capabilityWatchersLock.Lock()
+	defer c.capabilityWatchersLock.Unlock()
+
+	if watcher, ok := c.capabilityWatchers[b.Name()]; ok {
+		b.capabilityWatcher = watcher
+		return
+	}
+
+	watcher := newCapabilityWatcher(b, c.capabilityConfig)
+	if c.capabilityWatchers == nil {
+		c.capabilityWatchers = make(map[string]*capabilityWatcher)
+	}
+	c.capabilityWatchers[b.Name()] = watcher
+	b.capabilityWatcher = watcher
+	watcher.start()
+}
+
+func (c *Cluster) stopCapabilityWatchers() {
+	c.capabilityWatchersLock.Lock()
+	watchers := c.capabilityWatchers
+	c.capabilityWatchers = nil
+	c.capabilityWatchersLock.Unlock()
+
+	for _, watcher := range watchers {
+		watcher.stop()
+	}
+}
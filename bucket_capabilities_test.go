@@ -0,0 +1,112 @@
+package gocb
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestCapabilityWatcherUpdate() {
+	cw := newCapabilityWatcher(&Bucket{bucketName: "default"}, CapabilityConfig{
+		RequiredCapabilities: []Capability{CapabilityDurableWrites},
+	})
+	suite.Assert().Equal(defaultCapabilityPollInterval, cw.interval)
+
+	events := cw.update(map[Capability]CapabilityStatus{
+		CapabilityDurableWrites:   CapabilityStatusSupported,
+		CapabilityCreateAsDeleted: CapabilityStatusUnknown,
+	})
+	suite.Assert().Empty(events)
+	suite.Assert().Nil(cw.requiredCapabilityErr())
+
+	events = cw.update(map[Capability]CapabilityStatus{
+		CapabilityDurableWrites:   CapabilityStatusSupported,
+		CapabilityCreateAsDeleted: CapabilityStatusSupported,
+	})
+	suite.Assert().Empty(events)
+
+	events = cw.update(map[Capability]CapabilityStatus{
+		CapabilityDurableWrites:   CapabilityStatusUnsupported,
+		CapabilityCreateAsDeleted: CapabilityStatusSupported,
+	})
+	if suite.Assert().Len(events, 1) {
+		suite.Assert().Equal("default", events[0].BucketName)
+		suite.Assert().Equal(CapabilityDurableWrites, events[0].Capability)
+		suite.Assert().Equal(CapabilityStatusSupported, events[0].PreviousStatus)
+		suite.Assert().Equal(CapabilityStatusUnsupported, events[0].Status)
+	}
+
+	err := cw.requiredCapabilityErr()
+	suite.Require().NotNil(err)
+	suite.Assert().True(errors.Is(err, ErrFeatureNotAvailable))
+	suite.Assert().Contains(err.Error(), "durable_writes")
+
+	events = cw.update(map[Capability]CapabilityStatus{
+		CapabilityDurableWrites:   CapabilityStatusSupported,
+		CapabilityCreateAsDeleted: CapabilityStatusSupported,
+	})
+	suite.Assert().Len(events, 1)
+	suite.Assert().Nil(cw.requiredCapabilityErr())
+}
+
+func (suite *UnitTestSuite) TestCapabilityWatcherFailsFastKvOps() {
+	provider := new(mockKvProvider)
+	col := suite.collection("mock", "", "", provider)
+
+	cw := newCapabilityWatcher(col.bucket, CapabilityConfig{
+		RequiredCapabilities: []Capability{CapabilityDurableWrites},
+	})
+	cw.update(map[Capability]CapabilityStatus{
+		CapabilityDurableWrites: CapabilityStatusUnsupported,
+	})
+	col.bucket.capabilityWatcher = cw
+
+	_, err := col.Get("key", nil)
+	suite.Require().True(errors.Is(err, ErrFeatureNotAvailable), err)
+	provider.AssertNotCalled(suite.T(), "Get")
+}
+
+func (suite *UnitTestSuite) TestCapabilityWatcherListenerClosesCluster() {
+	var durableWritesPolls int32
+	verifier := new(mockKvCapabilityVerifier)
+	verifier.
+		On("BucketCapabilityStatus", mock.AnythingOfType("gocbcore.BucketCapability")).
+		Return(func(capability gocbcore.BucketCapability) gocbcore.BucketCapabilityStatus {
+			if capability == gocbcore.BucketCapabilityDurableWrites && atomic.AddInt32(&durableWritesPolls, 1) > 1 {
+				return gocbcore.BucketCapabilityStatusUnsupported
+			}
+
+			return gocbcore.BucketCapabilityStatusSupported
+		})
+
+	cli := new(mockConnectionManager)
+	cli.On("getKvCapabilitiesProvider", "default").Return(verifier, nil)
+	cli.On("close").Return(nil)
+
+	closedCh := make(chan error, 1)
+	var c *Cluster
+	c = clusterFromOptions(ClusterOptions{
+		Tracer: &NoopTracer{},
+		Meter:  &NoopMeter{},
+		CapabilityConfig: CapabilityConfig{
+			ChangeListener: func(event CapabilityChangeEvent) {
+				// Closing the cluster stops the watcher, which must not wait for the listener to return.
+				closedCh <- c.Close(nil)
+			},
+			PollInterval: time.Millisecond,
+		},
+	})
+	c.connectionManager = cli
+
+	c.startCapabilityWatcher(suite.bucket("default", TimeoutsConfig{}, cli))
+
+	select {
+	case err := <-closedCh:
+		suite.Assert().Nil(err)
+	case <-time.After(5 * time.Second):
+		suite.T().Fatalf("Timed out waiting for the listener to close the cluster")
+	}
+}
@@ -11,15 +11,45 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+)
+
+const (
+	// CollectionMaxExpiryBucketDefault indicates that the collection uses the max expiry of the bucket, this is the
+	// default for a collection.
+	CollectionMaxExpiryBucketDefault time.Duration = 0
 
-	"github.com/couchbase/gocbcore/v10"
+	// CollectionMaxExpiryNoExpiry indicates that documents in the collection never expire unless an expiry is set on
+	// the document itself, even if the bucket has a max expiry set.
+	// Requires Couchbase Server 7.6.0 or above.
+	CollectionMaxExpiryNoExpiry time.Duration = -1
 )
 
 // CollectionSpec describes the specification of a collection.
 type CollectionSpec struct {
 	Name      string
 	ScopeName string
+
+	// MaxExpiry is the maximum expiry of documents in the collection, this also applies as the expiry of documents
+	// which are written without one. The value must be a whole number of seconds,
+	// CollectionMaxExpiryBucketDefault (0) or CollectionMaxExpiryNoExpiry.
+	//
+	// Note that there is no per document equivalent of CollectionMaxExpiryNoExpiry, writing a document with an
+	// expiry of 0 means that the document has no expiry of its own, and so the max expiry of the collection, or else
+	// the bucket, applies to it.
 	MaxExpiry time.Duration
+
+	// History specifies whether the collection retains the history of changes to its documents, when nil the bucket
+	// default is used. History retention is only supported by buckets using the magma storage backend, on
+	// Couchbase Server 7.2.0 or above, setting History against any other bucket fails with ErrFeatureNotAvailable.
+	// GetAllScopes leaves History nil if the server does not report it.
+	// UNCOMMITTED: This API may change in the future.
+	History *CollectionHistorySettings
+}
+
+// CollectionHistorySettings specifies the history retention settings of a collection.
+// UNCOMMITTED: This API may change in the future.
+type CollectionHistorySettings struct {
+	Enabled bool
 }
 
 // ScopeSpec describes the specification of a scope.
@@ -44,6 +74,40 @@ type jsonManifestCollection struct {
 	UID uint32 `json:"uid"`
 }
 
+// These types are used in place of gocbcore.Manifest, which cannot represent a max expiry of -1.
+type jsonCollectionsManifest struct {
+	Scopes []jsonCollectionsManifestScope `json:"scopes"`
+}
+
+type jsonCollectionsManifestScope struct {
+	Name        string                              `json:"name"`
+	Collections []jsonCollectionsManifestCollection `json:"collections"`
+}
+
+type jsonCollectionsManifestCollection struct {
+	Name    string `json:"name"`
+	MaxTTL  int32  `json:"maxTTL"`
+	History *bool  `json:"history,omitempty"`
+}
+
+func collectionHistoryFromJSON(history *bool) *CollectionHistorySettings {
+	if history == nil {
+		return nil
+	}
+
+	return &CollectionHistorySettings{
+		Enabled: *history,
+	}
+}
+
+func collectionMaxExpiryFromTTL(maxTTL int32) time.Duration {
+	if maxTTL < 0 {
+		return CollectionMaxExpiryNoExpiry
+	}
+
+	return time.Duration(maxTTL) * time.Second
+}
+
 // CollectionManager provides methods for performing collections management.
 type CollectionManager struct {
 	mgmtProvider mgmtProvider
@@ -71,6 +135,14 @@ func (cm *CollectionManager) tryParseErrorMessage(req *mgmtRequest, resp *mgmtRe
 		return makeGenericMgmtError(ErrScopeNotFound, req, resp, string(b))
 	}
 
+	// Servers which do not support history retention, or buckets which cannot retain history, reject the history
+	// parameter rather than ignoring it.
+	if strings.Contains(errText, "history") && (strings.Contains(errText, "magma") ||
+		strings.Contains(errText, "not supported") || strings.Contains(errText, "not allowed") ||
+		strings.Contains(errText, "unsupported")) {
+		return makeGenericMgmtError(ErrFeatureNotAvailable, req, resp, string(b))
+	}
+
 	if strings.Contains(errText, "already exists") && strings.Contains(errText, "collection") {
 		return makeGenericMgmtError(ErrCollectionExists, req, resp, string(b))
 	} else if strings.Contains(errText, "already exists") && strings.Contains(errText, "scope") {
@@ -98,11 +170,16 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 		opts = &GetAllScopesOptions{}
 	}
 
+	return cm.getAllScopes("manager_collections_get_all_scopes", opts)
+}
+
+// getAllScopes fetches the manifest of the bucket, recording the request as the given operation.
+func (cm *CollectionManager) getAllScopes(operation string, opts *GetAllScopesOptions) ([]ScopeSpec, error) {
 	start := time.Now()
-	defer cm.meter.ValueRecord(meterValueServiceManagement, "manager_collections_get_all_scopes", start)
+	defer cm.meter.ValueRecord(meterValueServiceManagement, operation, start)
 
 	path := fmt.Sprintf("/pools/default/buckets/%s/scopes", cm.bucketName)
-	span := createSpan(cm.tracer, opts.ParentSpan, "manager_collections_get_all_scopes", "management")
+	span := createSpan(cm.tracer, opts.ParentSpan, operation, "management")
 	span.SetAttribute("db.name", cm.bucketName)
 	span.SetAttribute("db.operation", "GET "+path)
 	defer span.End()
@@ -132,10 +209,14 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 		return nil, makeMgmtBadStatusError("failed to get all scopes", &req, resp)
 	}
 
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var scopes []ScopeSpec
-	var mfest gocbcore.Manifest
-	jsonDec := json.NewDecoder(resp.Body)
-	err = jsonDec.Decode(&mfest)
+	var mfest jsonCollectionsManifest
+	err = json.Unmarshal(respBody, &mfest)
 	if err == nil {
 		for _, scope := range mfest.Scopes {
 			var collections []CollectionSpec
@@ -143,7 +224,8 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 				collections = append(collections, CollectionSpec{
 					Name:      col.Name,
 					ScopeName: scope.Name,
-					MaxExpiry: time.Duration(col.MaxTTL) * time.Second,
+					MaxExpiry: collectionMaxExpiryFromTTL(col.MaxTTL),
+					History:   collectionHistoryFromJSON(col.History),
 				})
 			}
 			scopes = append(scopes, ScopeSpec{
@@ -154,8 +236,7 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 	} else {
 		// Temporary support for older server version
 		var oldMfest jsonManifest
-		jsonDec := json.NewDecoder(resp.Body)
-		err = jsonDec.Decode(&oldMfest)
+		err = json.Unmarshal(respBody, &oldMfest)
 		if err != nil {
 			return nil, err
 		}
@@ -178,6 +259,131 @@ func (cm *CollectionManager) GetAllScopes(opts *GetAllScopesOptions) ([]ScopeSpe
 	return scopes, nil
 }
 
+// GetScopeOptions is the set of options available to the GetScope operation.
+// UNCOMMITTED: This API may change in the future.
+type GetScopeOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// GetScope gets a single scope, and its collections, from the bucket. Returns ErrScopeNotFound if the scope does not
+// exist. The server does not provide a way to get a single scope, so this makes the same single request for the
+// manifest of the bucket as GetAllScopes and selects the scope from it.
+// UNCOMMITTED: This API may change in the future.
+func (cm *CollectionManager) GetScope(scopeName string, opts *GetScopeOptions) (*ScopeSpec, error) {
+	if scopeName == "" {
+		return nil, makeInvalidArgumentsError("scope name cannot be empty")
+	}
+
+	if opts == nil {
+		opts = &GetScopeOptions{}
+	}
+
+	scopes, err := cm.getAllScopes("manager_collections_get_scope", &GetAllScopesOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range scopes {
+		if scopes[i].Name == scopeName {
+			return &scopes[i], nil
+		}
+	}
+
+	return nil, wrapError(ErrScopeNotFound, fmt.Sprintf("scope %s not found in bucket %s", scopeName, cm.bucketName))
+}
+
+// ScopeExistsOptions is the set of options available to the ScopeExists operation.
+// UNCOMMITTED: This API may change in the future.
+type ScopeExistsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// ScopeExists checks whether a scope exists on the bucket, see GetScope.
+// UNCOMMITTED: This API may change in the future.
+func (cm *CollectionManager) ScopeExists(scopeName string, opts *ScopeExistsOptions) (bool, error) {
+	if opts == nil {
+		opts = &ScopeExistsOptions{}
+	}
+
+	_, err := cm.GetScope(scopeName, &GetScopeOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		if errors.Is(err, ErrScopeNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CollectionExistsOptions is the set of options available to the CollectionExists operation.
+// UNCOMMITTED: This API may change in the future.
+type CollectionExistsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// CollectionExists checks whether a collection exists on the bucket. A collection within a scope which does not
+// exist does not exist either, rather than being an error.
+// UNCOMMITTED: This API may change in the future.
+func (cm *CollectionManager) CollectionExists(spec CollectionSpec, opts *CollectionExistsOptions) (bool, error) {
+	if spec.Name == "" {
+		return false, makeInvalidArgumentsError("collection name cannot be empty")
+	}
+
+	if opts == nil {
+		opts = &CollectionExistsOptions{}
+	}
+
+	scope, err := cm.GetScope(spec.ScopeName, &GetScopeOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		if errors.Is(err, ErrScopeNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, collection := range scope.Collections {
+		if collection.Name == spec.Name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // CreateCollectionOptions is the set of options available to the CreateCollection operation.
 type CreateCollectionOptions struct {
 	Timeout       time.Duration
@@ -188,6 +394,11 @@ type CreateCollectionOptions struct {
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
+
+	// IgnoreIfExists causes CreateCollection to succeed, rather than returning ErrCollectionExists, if the collection
+	// already exists. The settings of the existing collection are left unchanged.
+	// UNCOMMITTED: This API may change in the future.
+	IgnoreIfExists bool
 }
 
 // CreateCollection creates a new collection on the bucket.
@@ -200,6 +411,10 @@ func (cm *CollectionManager) CreateCollection(spec CollectionSpec, opts *CreateC
 		return makeInvalidArgumentsError("scope name cannot be empty")
 	}
 
+	if err := validateCollectionMaxExpiry(spec.MaxExpiry); err != nil {
+		return err
+	}
+
 	if opts == nil {
 		opts = &CreateCollectionOptions{}
 	}
@@ -217,10 +432,7 @@ func (cm *CollectionManager) CreateCollection(spec CollectionSpec, opts *CreateC
 
 	posts := url.Values{}
 	posts.Add("name", spec.Name)
-
-	if spec.MaxExpiry > 0 {
-		posts.Add("maxTTL", fmt.Sprintf("%d", int(spec.MaxExpiry.Seconds())))
-	}
+	addCollectionSettings(posts, spec)
 
 	eSpan := createSpan(cm.tracer, span, "request_encoding", "")
 	encoded := posts.Encode()
@@ -247,6 +459,9 @@ func (cm *CollectionManager) CreateCollection(spec CollectionSpec, opts *CreateC
 	if resp.StatusCode != 200 {
 		colErr := cm.tryParseErrorMessage(&req, resp)
 		if colErr != nil {
+			if opts.IgnoreIfExists && errors.Is(colErr, ErrCollectionExists) {
+				return nil
+			}
 			return colErr
 		}
 		return makeMgmtBadStatusError("failed to create collection", &req, resp)
@@ -260,6 +475,121 @@ func (cm *CollectionManager) CreateCollection(spec CollectionSpec, opts *CreateC
 	return nil
 }
 
+func validateCollectionMaxExpiry(maxExpiry time.Duration) error {
+	if maxExpiry < 0 && maxExpiry != CollectionMaxExpiryNoExpiry {
+		return makeInvalidArgumentsError("max expiry must be positive, CollectionMaxExpiryBucketDefault or CollectionMaxExpiryNoExpiry")
+	}
+
+	return nil
+}
+
+// addCollectionSettings adds the settings of spec which are sent when creating or updating a collection.
+func addCollectionSettings(posts url.Values, spec CollectionSpec) {
+	if spec.MaxExpiry == CollectionMaxExpiryNoExpiry {
+		posts.Add("maxTTL", "-1")
+	} else if spec.MaxExpiry > 0 {
+		posts.Add("maxTTL", fmt.Sprintf("%d", int(spec.MaxExpiry.Seconds())))
+	}
+
+	if spec.History != nil {
+		posts.Add("history", fmt.Sprintf("%t", spec.History.Enabled))
+	}
+}
+
+// UpdateCollectionOptions is the set of options available to the UpdateCollection operation.
+// UNCOMMITTED: This API may change in the future.
+type UpdateCollectionOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// UpdateCollection updates the settings of an existing collection. The MaxExpiry of spec is always sent, so the
+// current value must be passed to leave it unchanged, History is only changed when it is not nil.
+// Updating collections requires Couchbase Server 7.2.0 or above, and updating MaxExpiry requires 7.6.0 or above,
+// ErrFeatureNotAvailable is returned if the server or bucket does not support the update.
+// UNCOMMITTED: This API may change in the future.
+func (cm *CollectionManager) UpdateCollection(spec CollectionSpec, opts *UpdateCollectionOptions) error {
+	if spec.Name == "" {
+		return makeInvalidArgumentsError("collection name cannot be empty")
+	}
+
+	if spec.ScopeName == "" {
+		return makeInvalidArgumentsError("scope name cannot be empty")
+	}
+
+	if err := validateCollectionMaxExpiry(spec.MaxExpiry); err != nil {
+		return err
+	}
+
+	if opts == nil {
+		opts = &UpdateCollectionOptions{}
+	}
+
+	start := time.Now()
+	defer cm.meter.ValueRecord(meterValueServiceManagement, "manager_collections_update_collection", start)
+
+	path := fmt.Sprintf("/pools/default/buckets/%s/scopes/%s/collections/%s", cm.bucketName, spec.ScopeName, spec.Name)
+	span := createSpan(cm.tracer, opts.ParentSpan, "manager_collections_update_collection", "management")
+	span.SetAttribute("db.name", cm.bucketName)
+	span.SetAttribute("db.couchbase.scope", spec.ScopeName)
+	span.SetAttribute("db.couchbase.collection", spec.Name)
+	span.SetAttribute("db.operation", "PATCH "+path)
+	defer span.End()
+
+	posts := url.Values{}
+	addCollectionSettings(posts, spec)
+	if spec.MaxExpiry == CollectionMaxExpiryBucketDefault {
+		posts.Add("maxTTL", "0")
+	}
+
+	eSpan := createSpan(cm.tracer, span, "request_encoding", "")
+	encoded := posts.Encode()
+	eSpan.End()
+
+	req := mgmtRequest{
+		Service:       ServiceTypeManagement,
+		Path:          path,
+		Method:        "PATCH",
+		Body:          []byte(encoded),
+		ContentType:   "application/x-www-form-urlencoded",
+		RetryStrategy: opts.RetryStrategy,
+		UniqueID:      uuid.New().String(),
+		Timeout:       opts.Timeout,
+		parentSpanCtx: span.Context(),
+	}
+
+	resp, err := cm.mgmtProvider.executeMgmtRequest(opts.Context, req)
+	if err != nil {
+		return makeGenericMgmtError(err, &req, resp, "")
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	// Servers which do not support updating collections do not have a PATCH handler for the collection path.
+	if resp.StatusCode == 404 || resp.StatusCode == 405 {
+		colErr := cm.tryParseErrorMessage(&req, resp)
+		if errors.Is(colErr, ErrCollectionNotFound) || errors.Is(colErr, ErrScopeNotFound) {
+			return colErr
+		}
+		return makeGenericMgmtError(ErrFeatureNotAvailable, &req, resp, "")
+	}
+
+	if resp.StatusCode != 200 {
+		colErr := cm.tryParseErrorMessage(&req, resp)
+		if colErr != nil {
+			return colErr
+		}
+		return makeMgmtBadStatusError("failed to update collection", &req, resp)
+	}
+
+	return nil
+}
+
 // DropCollectionOptions is the set of options available to the DropCollection operation.
 type DropCollectionOptions struct {
 	Timeout       time.Duration
@@ -270,6 +600,11 @@ type DropCollectionOptions struct {
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
+
+	// IgnoreIfNotExists causes DropCollection to succeed, rather than returning ErrCollectionNotFound or
+	// ErrScopeNotFound, if the collection or its scope does not exist.
+	// UNCOMMITTED: This API may change in the future.
+	IgnoreIfNotExists bool
 }
 
 // DropCollection removes a collection.
@@ -316,6 +651,10 @@ func (cm *CollectionManager) DropCollection(spec CollectionSpec, opts *DropColle
 	if resp.StatusCode != 200 {
 		colErr := cm.tryParseErrorMessage(&req, resp)
 		if colErr != nil {
+			if opts.IgnoreIfNotExists &&
+				(errors.Is(colErr, ErrCollectionNotFound) || errors.Is(colErr, ErrScopeNotFound)) {
+				return nil
+			}
 			return colErr
 		}
 		return makeMgmtBadStatusError("failed to drop collection", &req, resp)
@@ -339,6 +678,10 @@ type CreateScopeOptions struct {
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
+
+	// IgnoreIfExists causes CreateScope to succeed, rather than returning ErrScopeExists, if the scope already exists.
+	// UNCOMMITTED: This API may change in the future.
+	IgnoreIfExists bool
 }
 
 // CreateScope creates a new scope on the bucket.
@@ -389,6 +732,9 @@ func (cm *CollectionManager) CreateScope(scopeName string, opts *CreateScopeOpti
 	if resp.StatusCode != 200 {
 		colErr := cm.tryParseErrorMessage(&req, resp)
 		if colErr != nil {
+			if opts.IgnoreIfExists && errors.Is(colErr, ErrScopeExists) {
+				return nil
+			}
 			return colErr
 		}
 		return makeMgmtBadStatusError("failed to create scope", &req, resp)
@@ -412,6 +758,11 @@ type DropScopeOptions struct {
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
+
+	// IgnoreIfNotExists causes DropScope to succeed, rather than returning ErrScopeNotFound, if the scope does not
+	// exist.
+	// UNCOMMITTED: This API may change in the future.
+	IgnoreIfNotExists bool
 }
 
 // DropScope removes a scope.
@@ -449,6 +800,9 @@ func (cm *CollectionManager) DropScope(scopeName string, opts *DropScopeOptions)
 	if resp.StatusCode != 200 {
 		colErr := cm.tryParseErrorMessage(&req, resp)
 		if colErr != nil {
+			if opts.IgnoreIfNotExists && errors.Is(colErr, ErrScopeNotFound) {
+				return nil
+			}
 			return colErr
 		}
 		return makeMgmtBadStatusError("failed to drop scope", &req, resp)
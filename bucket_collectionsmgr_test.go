@@ -1,9 +1,13 @@
 package gocb
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/stretchr/testify/mock"
+	"io/ioutil"
+	"net/url"
 	"strconv"
 	"time"
 )
@@ -592,3 +596,283 @@ func (suite *UnitTestSuite) TestGetAllScopesMgmtRequestFails() {
 	suite.Require().NotNil(err)
 	suite.Require().Nil(scopes)
 }
+
+func (suite *UnitTestSuite) TestCollectionMaxExpiryNoExpiry() {
+	var lastReq mgmtRequest
+	provider := new(mockMgmtProvider)
+	provider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("gocb.mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			lastReq = req
+			if req.Method == "GET" {
+				return &mgmtResponse{
+					StatusCode: 200,
+					Body: ioutil.NopCloser(bytes.NewReader([]byte(`{"uid":"2","scopes":[{"name":"_default","uid":"0",` +
+						`"collections":[{"name":"_default","uid":"0"},{"name":"forever","uid":"8","maxTTL":-1},` +
+						`{"name":"daily","uid":"9","maxTTL":86400}]}]}`))),
+				}
+			}
+
+			return &mgmtResponse{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{}`))),
+			}
+		}, nil)
+
+	mgr := CollectionManager{
+		mgmtProvider: provider,
+		bucketName:   "mock",
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}, isNoopMeter: true},
+	}
+
+	err := mgr.CreateCollection(CollectionSpec{
+		Name:      "forever",
+		ScopeName: "_default",
+		MaxExpiry: CollectionMaxExpiryNoExpiry,
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	form, err := url.ParseQuery(string(lastReq.Body))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("-1", form.Get("maxTTL"))
+
+	err = mgr.CreateCollection(CollectionSpec{
+		Name:      "default",
+		ScopeName: "_default",
+		MaxExpiry: CollectionMaxExpiryBucketDefault,
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	form, err = url.ParseQuery(string(lastReq.Body))
+	suite.Require().Nil(err, err)
+	suite.Assert().NotContains(form, "maxTTL")
+
+	err = mgr.CreateCollection(CollectionSpec{
+		Name:      "invalid",
+		ScopeName: "_default",
+		MaxExpiry: -5 * time.Second,
+	}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	scopes, err := mgr.GetAllScopes(nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]ScopeSpec{{
+		Name: "_default",
+		Collections: []CollectionSpec{
+			{Name: "_default", ScopeName: "_default", MaxExpiry: CollectionMaxExpiryBucketDefault},
+			{Name: "forever", ScopeName: "_default", MaxExpiry: CollectionMaxExpiryNoExpiry},
+			{Name: "daily", ScopeName: "_default", MaxExpiry: 24 * time.Hour},
+		},
+	}}, scopes)
+}
+
+func (suite *UnitTestSuite) TestCollectionHistorySettings() {
+	var lastReq mgmtRequest
+	var statusCode uint32
+	var respBody string
+	provider := new(mockMgmtProvider)
+	provider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("gocb.mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			lastReq = req
+			return &mgmtResponse{
+				StatusCode: statusCode,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(respBody))),
+			}
+		}, nil)
+
+	mgr := CollectionManager{
+		mgmtProvider: provider,
+		bucketName:   "mock",
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}, isNoopMeter: true},
+	}
+
+	statusCode = 200
+	respBody = `{}`
+	err := mgr.CreateCollection(CollectionSpec{
+		Name:      "audit",
+		ScopeName: "_default",
+		MaxExpiry: time.Hour,
+		History:   &CollectionHistorySettings{Enabled: true},
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	form, err := url.ParseQuery(string(lastReq.Body))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("true", form.Get("history"))
+	suite.Assert().Equal("3600", form.Get("maxTTL"))
+
+	err = mgr.UpdateCollection(CollectionSpec{
+		Name:      "audit",
+		ScopeName: "_default",
+		History:   &CollectionHistorySettings{Enabled: false},
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal("PATCH", lastReq.Method)
+	suite.Assert().Equal("/pools/default/buckets/mock/scopes/_default/collections/audit", lastReq.Path)
+	form, err = url.ParseQuery(string(lastReq.Body))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("false", form.Get("history"))
+	suite.Assert().Equal("0", form.Get("maxTTL"))
+
+	statusCode = 400
+	respBody = `{"errors":{"history":"Bucket must have storage_mode=magma"}}`
+	err = mgr.CreateCollection(CollectionSpec{
+		Name:      "audit",
+		ScopeName: "_default",
+		History:   &CollectionHistorySettings{Enabled: true},
+	}, nil)
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected feature not available error but was %v", err)
+	}
+
+	statusCode = 404
+	respBody = `Not found.`
+	err = mgr.UpdateCollection(CollectionSpec{
+		Name:      "audit",
+		ScopeName: "_default",
+	}, nil)
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected feature not available error but was %v", err)
+	}
+
+	statusCode = 200
+	respBody = `{"uid":"2","scopes":[{"name":"_default","uid":"0","collections":[{"name":"_default","uid":"0"},` +
+		`{"name":"audit","uid":"8","maxTTL":3600,"history":true},{"name":"plain","uid":"9","history":false}]}]}`
+	scopes, err := mgr.GetAllScopes(nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]ScopeSpec{{
+		Name: "_default",
+		Collections: []CollectionSpec{
+			{Name: "_default", ScopeName: "_default"},
+			{Name: "audit", ScopeName: "_default", MaxExpiry: time.Hour, History: &CollectionHistorySettings{Enabled: true}},
+			{Name: "plain", ScopeName: "_default", History: &CollectionHistorySettings{Enabled: false}},
+		},
+	}}, scopes)
+}
+
+type testCollectionManagerResponse struct {
+	statusCode uint32
+	body       string
+}
+
+func (suite *UnitTestSuite) collectionManagerResponses(
+	responses map[string]testCollectionManagerResponse,
+) *CollectionManager {
+	provider := new(mockMgmtProvider)
+	provider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("gocb.mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			resp, ok := responses[req.Method]
+			suite.Require().True(ok, "unexpected %s request", req.Method)
+
+			return &mgmtResponse{
+				StatusCode: resp.statusCode,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(resp.body))),
+			}
+		}, nil)
+
+	return &CollectionManager{
+		mgmtProvider: provider,
+		bucketName:   "mock",
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}, isNoopMeter: true},
+	}
+}
+
+func (suite *UnitTestSuite) TestCollectionManagerGetScope() {
+	mgr := suite.collectionManagerResponses(map[string]testCollectionManagerResponse{
+		"GET": {
+			statusCode: 200,
+			body: `{"uid":"2","scopes":[{"name":"_default","uid":"0","collections":[{"name":"_default","uid":"0"}]},` +
+				`{"name":"inventory","uid":"8","collections":[{"name":"airline","uid":"9"}]}]}`,
+		},
+	})
+
+	scope, err := mgr.GetScope("inventory", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(&ScopeSpec{
+		Name:        "inventory",
+		Collections: []CollectionSpec{{Name: "airline", ScopeName: "inventory"}},
+	}, scope)
+
+	_, err = mgr.GetScope("tenant", nil)
+	suite.Assert().True(errors.Is(err, ErrScopeNotFound), "expected scope not found but was %v", err)
+
+	exists, err := mgr.ScopeExists("inventory", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().True(exists)
+
+	exists, err = mgr.ScopeExists("tenant", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().False(exists)
+
+	exists, err = mgr.CollectionExists(CollectionSpec{Name: "airline", ScopeName: "inventory"}, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().True(exists)
+
+	exists, err = mgr.CollectionExists(CollectionSpec{Name: "airline", ScopeName: "_default"}, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().False(exists)
+
+	exists, err = mgr.CollectionExists(CollectionSpec{Name: "airline", ScopeName: "tenant"}, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().False(exists)
+}
+
+func (suite *UnitTestSuite) TestCollectionManagerIgnoreIfExists() {
+	mgr := suite.collectionManagerResponses(map[string]testCollectionManagerResponse{
+		"POST": {
+			statusCode: 400,
+			body:       `{"errors":{"name":"Scope with this name already exists"}}`,
+		},
+		"DELETE": {
+			statusCode: 404,
+			body:       `Scope with name "tenant" is not found`,
+		},
+	})
+
+	err := mgr.CreateScope("tenant", nil)
+	suite.Assert().True(errors.Is(err, ErrScopeExists), "expected scope exists but was %v", err)
+	err = mgr.CreateScope("tenant", &CreateScopeOptions{IgnoreIfExists: true})
+	suite.Assert().Nil(err, err)
+
+	err = mgr.DropScope("tenant", nil)
+	suite.Assert().True(errors.Is(err, ErrScopeNotFound), "expected scope not found but was %v", err)
+	err = mgr.DropScope("tenant", &DropScopeOptions{IgnoreIfNotExists: true})
+	suite.Assert().Nil(err, err)
+
+	// Dropping a collection from a scope which does not exist is also ignored.
+	err = mgr.DropCollection(CollectionSpec{Name: "airline", ScopeName: "tenant"}, nil)
+	suite.Assert().True(errors.Is(err, ErrScopeNotFound), "expected scope not found but was %v", err)
+	err = mgr.DropCollection(CollectionSpec{Name: "airline", ScopeName: "tenant"},
+		&DropCollectionOptions{IgnoreIfNotExists: true})
+	suite.Assert().Nil(err, err)
+
+	mgr = suite.collectionManagerResponses(map[string]testCollectionManagerResponse{
+		"POST": {
+			statusCode: 400,
+			body:       `{"errors":{"name":"Collection with this name already exists"}}`,
+		},
+		"DELETE": {
+			statusCode: 404,
+			body:       `Collection with name "airline" in scope "inventory" is not found`,
+		},
+	})
+	spec := CollectionSpec{Name: "airline", ScopeName: "inventory"}
+
+	err = mgr.CreateCollection(spec, nil)
+	suite.Assert().True(errors.Is(err, ErrCollectionExists), "expected collection exists but was %v", err)
+	err = mgr.CreateCollection(spec, &CreateCollectionOptions{IgnoreIfExists: true})
+	suite.Assert().Nil(err, err)
+
+	err = mgr.DropCollection(spec, nil)
+	suite.Assert().True(errors.Is(err, ErrCollectionNotFound), "expected collection not found but was %v", err)
+	err = mgr.DropCollection(spec, &DropCollectionOptions{IgnoreIfNotExists: true})
+	suite.Assert().Nil(err, err)
+}
@@ -0,0 +1,72 @@
+package gocb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// kvNodePinner restricts the KV operations of a bucket to the vbuckets which are active on the pinned hosts.
+// The nodes holding each vbucket are looked up in the directory of the bucket's KV nodes, which is fetched in the
+// background. Until the nodes have been fetched for the first time operations are not restricted, and whilst they are
+// being fetched again after a configuration change the previous nodes are used. The check can only ever be made at
+// the time that the operation is dispatched, should the vbucket move then the operation follows it as normal.
+type kvNodePinner struct {
+	nodes *kvNodeDirectory
+	hosts map[string]struct{}
+}
+
+func newKVNodePinner(nodes *kvNodeDirectory, hosts []string) *kvNodePinner {
+	hostSet := make(map[string]struct{}, len(hosts))
+	for _, host := range hosts {
+		hostSet[strings.ToLower(host)] = struct{}{}
+	}
+
+	return &kvNodePinner{
+		nodes: nodes,
+		hosts: hostSet,
+	}
+}
+
+// checkKey returns an error if the vbucket of the given key is known to be active on a node which is not pinned.
+func (p *kvNodePinner) checkKey(key string) error {
+	if p == nil || key == "" {
+		return nil
+	}
+
+	agent, err := p.nodes.bucket.getKvProvider()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := agent.ConfigSnapshot()
+	if err != nil {
+		return err
+	}
+
+	nodes := p.nodes.get(snapshot.RevID())
+	if nodes == nil {
+		return nil
+	}
+
+	vbID, err := snapshot.KeyToVbucket([]byte(key))
+	if err != nil {
+		return err
+	}
+
+	nodeIdx, err := snapshot.VbucketToServer(vbID, 0)
+	if err != nil {
+		return err
+	}
+
+	return p.checkNode(nodes, nodeIdx, vbID, key)
+}
+
+func (p *kvNodePinner) checkNode(nodes []kvNodeDetails, nodeIdx int, vbID uint16, key string) error {
+	// A node which is missing from the directory has been added since it was fetched and cannot be checked yet.
+	if nodeIdx < 0 || nodeIdx >= len(nodes) || nodes[nodeIdx].hasHostname(p.hosts) {
+		return nil
+	}
+
+	return wrapError(ErrVbucketNotOnPinnedNode, fmt.Sprintf("vbucket %d of document %s is active on node %s",
+		vbID, key, nodes[nodeIdx].hostnames[0]))
+}
@@ -0,0 +1,101 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestKVNodePinnerConnStrOption() {
+	cluster := &Cluster{}
+	spec, err := parseConnSpec("couchbase://10.0.0.2?kv_pin_to_bootstrap_hosts=true")
+	suite.Require().Nil(err, err)
+
+	err = cluster.parseExtraConnStrOptions(spec)
+	suite.Require().Nil(err, err)
+	suite.Assert().True(cluster.pinKVToBootstrapHosts)
+
+	cluster.cSpec = spec
+	bucket := newBucket(cluster, "default")
+	suite.Require().NotNil(bucket.kvPinner)
+	suite.Assert().Contains(bucket.kvPinner.hosts, "10.0.0.2")
+
+	_, err = ParseConnectionString("couchbase://10.0.0.2?kv_pin_to_bootstrap_hosts=maybe")
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	var unpinned *kvNodePinner
+	suite.Assert().Nil(unpinned.checkKey("key"))
+}
+
+func (suite *UnitTestSuite) TestKVNodesFromConfig() {
+	var config jsonTerseBucketConfig
+	err := json.Unmarshal([]byte(`{
+		"rev": 10,
+		"nodesExt": [
+			{"hostname": "10.0.0.1", "services": {"kv": 11210, "mgmt": 8091}, "serverGroup": "Group 1"},
+			{"hostname": "10.0.0.2", "services": {"kv": 11210, "mgmt": 8091}, "serverGroup": "Group 2",
+				"alternateAddresses": {"external": {"hostname": "KV2.example.com", "ports": {"kv": 31210}}}},
+			{"hostname": "10.0.0.3", "services": {"mgmt": 8091}}
+		],
+		"vBucketServerMap": {"serverList": ["10.0.0.2:11210", "10.0.0.1:11210"]}
+	}`), &config)
+	suite.Require().Nil(err, err)
+
+	nodes := kvNodesFromConfig(&config)
+	suite.Assert().Equal([]kvNodeDetails{
+		{hostnames: []string{"10.0.0.2", "kv2.example.com"}, serverGroup: "Group 2"},
+		{hostnames: []string{"10.0.0.1"}, serverGroup: "Group 1"},
+	}, nodes)
+
+	pinner := newKVNodePinner(nil, []string{"kv2.example.com"})
+	suite.Assert().Nil(pinner.checkNode(nodes, 0, 1, "key"))
+	suite.Assert().Nil(pinner.checkNode(nodes, 2, 1, "key"))
+	err = pinner.checkNode(nodes, 1, 1, "key")
+	if !errors.Is(err, ErrVbucketNotOnPinnedNode) {
+		suite.T().Fatalf("Expected vbucket not on pinned node error but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestKVNodeDirectoryFetchesInBackground() {
+	var fetches int32
+	release := make(chan struct{})
+	httpProvider := new(mockHttpProvider)
+	httpProvider.
+		On("DoHTTPRequest", mock.Anything, mock.AnythingOfType("*gocbcore.HTTPRequest")).
+		Return(func(ctx context.Context, req *gocbcore.HTTPRequest) *gocbcore.HTTPResponse {
+			suite.Assert().Equal("/pools/default/b/mock", req.Path)
+			atomic.AddInt32(&fetches, 1)
+			<-release
+
+			return suite.deferredHTTPResponse("http://10.0.0.1:8091",
+				`{"rev":10,"vBucketServerMap":{"serverList":["10.0.0.1:11210"]}}`)
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getHTTPProvider", "mock").Return(httpProvider, nil)
+
+	directory := newKVNodeDirectory(suite.bucket("mock", TimeoutsConfig{}, cli), false)
+
+	// Operations do not wait for the nodes to be fetched.
+	suite.Assert().Nil(directory.get(10))
+	suite.Assert().Nil(directory.get(10))
+	close(release)
+
+	suite.Require().Eventually(func() bool {
+		return directory.get(10) != nil
+	}, 5*time.Second, time.Millisecond)
+	suite.Assert().Equal(int32(1), atomic.LoadInt32(&fetches))
+
+	// The previous nodes are used whilst fetching again for a new configuration revision.
+	suite.Assert().Len(directory.get(11), 1)
+	suite.Require().Eventually(func() bool {
+		return atomic.LoadInt32(&fetches) == 2
+	}, 5*time.Second, time.Millisecond)
+}
@@ -0,0 +1,179 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// kvNodeDirectoryRetryInterval is how long the directory waits before fetching the nodes again after a failure.
+const kvNodeDirectoryRetryInterval = 5 * time.Second
+
+type jsonTerseBucketConfig struct {
+	Rev              int64              `json:"rev"`
+	NodesExt         []jsonTerseNodeExt `json:"nodesExt"`
+	VBucketServerMap struct {
+		ServerList []string `json:"serverList"`
+	} `json:"vBucketServerMap"`
+}
+
+type jsonTerseNodeExt struct {
+	Hostname           string                         `json:"hostname"`
+	Services           map[string]int                 `json:"services"`
+	AlternateAddresses map[string]jsonTerseAltAddress `json:"alternateAddresses"`
+	ServerGroup        string                         `json:"serverGroup"`
+}
+
+type jsonTerseAltAddress struct {
+	Hostname string `json:"hostname"`
+}
+
+// kvNodeDetails describes a single KV node of a bucket.
+type kvNodeDetails struct {
+	// hostnames are the lower cased hostnames of the node, including any alternate addresses.
+	hostnames []string
+
+	// serverGroup is the server group of the node, if the cluster reports it.
+	serverGroup string
+}
+
+func (n kvNodeDetails) hasHostname(hosts map[string]struct{}) bool {
+	for _, hostname := range n.hostnames {
+		if _, ok := hosts[hostname]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// kvNodeDirectory holds the details of the KV nodes of a bucket, in the order that the nodes are referenced by the
+// SDK's cluster configuration. The configuration snapshot only identifies nodes by index, so the details are fetched
+// from the management service. They are fetched again in the background whenever the revision of the configuration
+// changes, so operations never wait for the management service and use the most recently fetched details in the
+// meantime.
+type kvNodeDirectory struct {
+	bucket *Bucket
+
+	// withServerGroups also fetches the server groups of the cluster when the bucket configuration does not report
+	// the server group of each node.
+	withServerGroups bool
+
+	lock       sync.Mutex
+	nodes      []kvNodeDetails
+	revID      int64
+	fetching   bool
+	retryAfter time.Time
+}
+
+func newKVNodeDirectory(bucket *Bucket, withServerGroups bool) *kvNodeDirectory {
+	return &kvNodeDirectory{
+		bucket:           bucket,
+		withServerGroups: withServerGroups,
+		revID:            -1,
+	}
+}
+
+// get returns the most recently fetched nodes, or nil if they have not been fetched yet, fetching them again in the
+// background if they were fetched for a different configuration revision.
+func (d *kvNodeDirectory) get(revID int64) []kvNodeDetails {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.revID != revID && !d.fetching && !time.Now().Before(d.retryAfter) {
+		d.fetching = true
+		go d.fetch(revID)
+	}
+
+	return d.nodes
+}
+
+func (d *kvNodeDirectory) fetch(revID int64) {
+	nodes, err := d.bucket.fetchKVNodes()
+	if err == nil && d.withServerGroups {
+		err = d.bucket.addServerGroups(nodes)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.fetching = false
+	if err != nil {
+		logWarnFieldsf(logFields{bucket: d.bucket.Name()}, "Failed to fetch the KV nodes of bucket %s: %v",
+			d.bucket.Name(), err)
+		d.retryAfter = time.Now().Add(kvNodeDirectoryRetryInterval)
+		return
+	}
+
+	d.nodes = nodes
+	d.revID = revID
+}
+
+// fetchKVNodes returns the KV nodes of the bucket, in the order that they are referenced by the SDK's cluster
+// configuration.
+func (b *Bucket) fetchKVNodes() ([]kvNodeDetails, error) {
+	req := mgmtRequest{
+		Service:      ServiceTypeManagement,
+		Method:       "GET",
+		Path:         fmt.Sprintf("/pools/default/b/%s", b.Name()),
+		IsIdempotent: true,
+		UniqueID:     uuid.New().String(),
+		Timeout:      b.timeoutsConfig.ManagementTimeout,
+	}
+
+	resp, err := b.executeMgmtRequest(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return nil, makeMgmtBadStatusError("failed to get bucket config", &req, resp)
+	}
+
+	var config jsonTerseBucketConfig
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	return kvNodesFromConfig(&config), nil
+}
+
+// kvNodesFromConfig matches each entry of the server list of the bucket with the node which it refers to, in order to
+// find the alternate addresses and server group of the node.
+func kvNodesFromConfig(config *jsonTerseBucketConfig) []kvNodeDetails {
+	nodes := make([]kvNodeDetails, len(config.VBucketServerMap.ServerList))
+	for i, server := range config.VBucketServerMap.ServerList {
+		host, port, err := net.SplitHostPort(server)
+		if err != nil {
+			host = server
+		}
+		host = strings.ToLower(host)
+		nodes[i].hostnames = []string{host}
+
+		for _, ext := range config.NodesExt {
+			if strings.ToLower(ext.Hostname) != host || strconv.Itoa(ext.Services["kv"]) != port {
+				continue
+			}
+
+			for _, alt := range ext.AlternateAddresses {
+				if alt.Hostname != "" {
+					nodes[i].hostnames = append(nodes[i].hostnames, strings.ToLower(alt.Hostname))
+				}
+			}
+			nodes[i].serverGroup = ext.ServerGroup
+			break
+		}
+	}
+
+	return nodes
+}
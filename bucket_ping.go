@@ -3,18 +3,31 @@ package gocb
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
 // EndpointPingReport represents a single entry in a ping report.
 type EndpointPingReport struct {
-	ID        string
-	Local     string
-	Remote    string
-	State     PingState
-	Error     string
+	ID string
+
+	// Local is the local address of the connection which was pinged. It is not reported for any service at present
+	// and is always empty.
+	Local string
+
+	// Remote is the address of the endpoint which was pinged.
+	Remote string
+
+	// State is the outcome of the ping. An endpoint which responded with an error is reported as PingStateError,
+	// with the error in Error.
+	State PingState
+	Error string
+
+	// Namespace is the bucket which the endpoint was pinged for, where relevant.
 	Namespace string
-	Latency   time.Duration
+
+	// Latency is how long the endpoint took to respond, or how long was spent waiting for it if the ping failed.
+	Latency time.Duration
 }
 
 // PingResult encapsulates the details from a executed ping operation.
@@ -22,7 +35,8 @@ type PingResult struct {
 	ID       string
 	Services map[ServiceType][]EndpointPingReport
 
-	sdk string
+	sdk       string
+	configRev int64
 }
 
 type jsonEndpointPingReport struct {
@@ -36,19 +50,22 @@ type jsonEndpointPingReport struct {
 }
 
 type jsonPingReport struct {
-	Version  uint16                              `json:"version"`
-	SDK      string                              `json:"sdk,omitempty"`
-	ID       string                              `json:"id,omitempty"`
-	Services map[string][]jsonEndpointPingReport `json:"services,omitempty"`
+	Version   uint16                              `json:"version"`
+	SDK       string                              `json:"sdk,omitempty"`
+	ID        string                              `json:"id,omitempty"`
+	ConfigRev int64                               `json:"config_rev"`
+	Services  map[string][]jsonEndpointPingReport `json:"services,omitempty"`
 }
 
-// MarshalJSON generates a JSON representation of this ping report.
+// MarshalJSON generates a JSON representation of this ping report, following the diagnostics report format which is
+// shared by the Couchbase SDKs.
 func (report *PingResult) MarshalJSON() ([]byte, error) {
 	jsonReport := jsonPingReport{
-		Version:  2,
-		SDK:      report.sdk,
-		ID:       report.ID,
-		Services: make(map[string][]jsonEndpointPingReport),
+		Version:   2,
+		SDK:       report.sdk,
+		ID:        report.ID,
+		ConfigRev: report.configRev,
+		Services:  make(map[string][]jsonEndpointPingReport),
 	}
 
 	for serviceType, serviceInfo := range report.Services {
@@ -61,11 +78,11 @@ func (report *PingResult) MarshalJSON() ([]byte, error) {
 			jsonReport.Services[serviceStr] = append(jsonReport.Services[serviceStr], jsonEndpointPingReport{
 				ID:        service.ID,
 				Local:     service.Local,
-				Remote:    service.Remote,
+				Remote:    pingReportAddress(service.Remote),
 				State:     pingStateToString(service.State),
 				Error:     service.Error,
 				Namespace: service.Namespace,
-				LatencyUs: uint64(service.Latency / time.Nanosecond),
+				LatencyUs: uint64(service.Latency / time.Microsecond),
 			})
 		}
 	}
@@ -73,8 +90,19 @@ func (report *PingResult) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&jsonReport)
 }
 
+// pingReportAddress strips the scheme from the address of an HTTP endpoint, as the report format gives the address
+// as host and port only.
+func pingReportAddress(address string) string {
+	if idx := strings.Index(address, "://"); idx >= 0 {
+		return address[idx+3:]
+	}
+	return address
+}
+
 // PingOptions are the options available to the Ping operation.
 type PingOptions struct {
+	// ServiceTypes restricts the ping to the given services, by default all services are pinged. Only the KV, query,
+	// search, analytics, views and management services can be pinged.
 	ServiceTypes []ServiceType
 	ReportID     string
 	Timeout      time.Duration
@@ -97,7 +125,7 @@ func (b *Bucket) Ping(opts *PingOptions) (*PingResult, error) {
 	defer span.End()
 
 	startTime := time.Now()
-	defer b.meter.ValueRecord(meterValueServiceKV, "ping", startTime)
+	defer b.meter.KeyspaceValueRecord(meterValueServiceKV, "ping", "", meterKeyspace{bucket: b.Name()}, startTime)
 
 	provider, err := b.connectionManager.getDiagnosticsProvider(b.bucketName)
 	if err != nil {
@@ -0,0 +1,178 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// errServerGroupsNotFetched occurs when the server groups of the KV nodes have not been fetched for the first time.
+var errServerGroupsNotFetched = wrapError(ErrServerGroupMetadataUnavailable, "server groups have not been fetched yet")
+
+type jsonServerGroups struct {
+	Groups []jsonServerGroup `json:"groups"`
+}
+
+type jsonServerGroup struct {
+	Name  string                `json:"name"`
+	Nodes []jsonServerGroupNode `json:"nodes"`
+}
+
+type jsonServerGroupNode struct {
+	Hostname string `json:"hostname"`
+}
+
+// serverGroupResolver finds the copies of a document which are held within the preferred server group.
+// The server group of each KV node is looked up in the directory of the bucket's KV nodes, which is fetched in the
+// background, so resolving the copies never waits for the management service.
+type serverGroupResolver struct {
+	nodes *kvNodeDirectory
+	group string
+}
+
+func newServerGroupResolver(nodes *kvNodeDirectory, group string) *serverGroupResolver {
+	return &serverGroupResolver{
+		nodes: nodes,
+		group: group,
+	}
+}
+
+// copiesInGroup returns the indexes of the copies of the given key which are held within the preferred server group,
+// where index 0 is the active copy and the remaining indexes are replicas.
+func (r *serverGroupResolver) copiesInGroup(key string) ([]int, error) {
+	agent, err := r.nodes.bucket.getKvProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := agent.ConfigSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := r.nodes.get(snapshot.RevID())
+	if nodes == nil {
+		return nil, errServerGroupsNotFetched
+	}
+
+	vbID, err := snapshot.KeyToVbucket([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+
+	numReplicas, err := snapshot.NumReplicas()
+	if err != nil {
+		return nil, err
+	}
+
+	copyNodes := make([]int, numReplicas+1)
+	for replicaIdx := range copyNodes {
+		copyNodes[replicaIdx], err = snapshot.VbucketToServer(vbID, uint32(replicaIdx))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return serverGroupCopies(nodes, copyNodes, r.group)
+}
+
+// serverGroupCopies returns the indexes within copyNodes of the copies which are held by a node in the given server
+// group, where copyNodes holds the index of the node holding each copy.
+func serverGroupCopies(nodes []kvNodeDetails, copyNodes []int, group string) ([]int, error) {
+	var hasGroups bool
+	for _, node := range nodes {
+		hasGroups = hasGroups || node.serverGroup != ""
+	}
+	if !hasGroups {
+		return nil, ErrServerGroupMetadataUnavailable
+	}
+
+	var copies []int
+	for replicaIdx, nodeIdx := range copyNodes {
+		// A node which is missing from the directory has been added since it was fetched and cannot be checked yet.
+		if nodeIdx >= 0 && nodeIdx < len(nodes) && nodes[nodeIdx].serverGroup == group {
+			copies = append(copies, replicaIdx)
+		}
+	}
+
+	return copies, nil
+}
+
+// addServerGroups sets the server group of each node from the server groups of the cluster, for clusters which do
+// not report the server group of each node in the bucket configuration. Reading the server groups of the cluster
+// requires administrator privileges, if they are not held then the server groups of the nodes are left unset.
+func (b *Bucket) addServerGroups(nodes []kvNodeDetails) error {
+	for _, node := range nodes {
+		if node.serverGroup != "" {
+			return nil
+		}
+	}
+
+	groups, err := b.fetchServerGroups()
+	if err != nil {
+		if errors.Is(err, ErrServerGroupMetadataUnavailable) {
+			logWarnFieldsf(logFields{bucket: b.Name()}, "Cannot read the server groups of bucket %s: %v", b.Name(),
+				err)
+			return nil
+		}
+
+		return err
+	}
+
+	for _, group := range groups.Groups {
+		hosts := make(map[string]struct{}, len(group.Nodes))
+		for _, node := range group.Nodes {
+			host, _, err := net.SplitHostPort(node.Hostname)
+			if err != nil {
+				host = node.Hostname
+			}
+			hosts[strings.ToLower(host)] = struct{}{}
+		}
+
+		for i := range nodes {
+			if nodes[i].hasHostname(hosts) {
+				nodes[i].serverGroup = group.Name
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Bucket) fetchServerGroups() (*jsonServerGroups, error) {
+	req := mgmtRequest{
+		Service:      ServiceTypeManagement,
+		Method:       "GET",
+		Path:         "/pools/default/serverGroups",
+		IsIdempotent: true,
+		UniqueID:     uuid.New().String(),
+		Timeout:      b.timeoutsConfig.ManagementTimeout,
+	}
+
+	resp, err := b.executeMgmtRequest(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return nil, wrapError(ErrServerGroupMetadataUnavailable, "insufficient privileges to read server groups")
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, makeMgmtBadStatusError("failed to get server groups", &req, resp)
+	}
+
+	var groups jsonServerGroups
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return &groups, nil
+}
@@ -0,0 +1,153 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestServerGroupCopies() {
+	nodes := []kvNodeDetails{
+		{hostnames: []string{"10.0.0.1"}, serverGroup: "Group 1"},
+		{hostnames: []string{"10.0.0.2"}, serverGroup: "Group 2"},
+		{hostnames: []string{"kv3.example.com"}, serverGroup: "Group 2"},
+	}
+
+	copies, err := serverGroupCopies(nodes, []int{0, 2, 1}, "Group 2")
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]int{1, 2}, copies)
+
+	// Copies on nodes which are unknown to the directory, or which have no node, are never in the server group.
+	copies, err = serverGroupCopies(nodes, []int{3, -1}, "Group 2")
+	suite.Require().Nil(err, err)
+	suite.Assert().Empty(copies)
+
+	_, err = serverGroupCopies([]kvNodeDetails{{hostnames: []string{"10.0.0.1"}}}, []int{0}, "Group 1")
+	if !errors.Is(err, ErrServerGroupMetadataUnavailable) {
+		suite.T().Fatalf("Expected error to be server group metadata unavailable but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestKVNodeDirectoryAddsServerGroups() {
+	var paths []string
+	groupsStatus := 200
+	httpProvider := new(mockHttpProvider)
+	httpProvider.
+		On("DoHTTPRequest", mock.Anything, mock.AnythingOfType("*gocbcore.HTTPRequest")).
+		Return(func(ctx context.Context, req *gocbcore.HTTPRequest) *gocbcore.HTTPResponse {
+			paths = append(paths, req.Path)
+			if req.Path == "/pools/default/serverGroups" {
+				resp := suite.deferredHTTPResponse("http://10.0.0.1:8091",
+					`{"groups":[{"name":"Group 1","nodes":[{"hostname":"10.0.0.1:8091"}]},`+
+						`{"name":"Group 2","nodes":[{"hostname":"10.0.0.2:8091"}]}]}`)
+				resp.StatusCode = groupsStatus
+				return resp
+			}
+
+			return suite.deferredHTTPResponse("http://10.0.0.1:8091",
+				`{"rev":10,"vBucketServerMap":{"serverList":["10.0.0.1:11210","10.0.0.2:11210"]}}`)
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getHTTPProvider", "mock").Return(httpProvider, nil)
+
+	directory := newKVNodeDirectory(suite.bucket("mock", TimeoutsConfig{}, cli), true)
+	directory.fetch(10)
+	suite.Assert().Equal([]kvNodeDetails{
+		{hostnames: []string{"10.0.0.1"}, serverGroup: "Group 1"},
+		{hostnames: []string{"10.0.0.2"}, serverGroup: "Group 2"},
+	}, directory.nodes)
+	suite.Assert().Equal([]string{"/pools/default/b/mock", "/pools/default/serverGroups"}, paths)
+
+	// Without the privileges to read the server groups the nodes are still fetched, but without server groups.
+	groupsStatus = 403
+	directory.fetch(11)
+	suite.Assert().Equal(int64(11), directory.revID)
+	_, err := serverGroupCopies(directory.nodes, []int{0, 1}, "Group 2")
+	if !errors.Is(err, ErrServerGroupMetadataUnavailable) {
+		suite.T().Fatalf("Expected error to be server group metadata unavailable but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestGetReadPreferenceInvalidArguments() {
+	provider := new(mockKvProvider)
+	col := suite.collection("mock", "", "", provider)
+
+	_, err := col.Get("key", &GetOptions{
+		ReadPreference: ReadPreferenceSelectedServerGroup,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	_, err = col.Get("key", &GetOptions{
+		ReadPreference: ReadPreferenceSelectedServerGroup,
+		Project:        []string{"name"},
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	_, err = col.Get("key", &GetOptions{
+		ReplicaFallback: true,
+		WithExpiry:      true,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	provider.AssertNotCalled(suite.T(), "Get", mock.Anything, mock.Anything)
+}
+
+func (suite *UnitTestSuite) TestGetReplicaReadSplitsDeadline() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.On("Cancel").Return()
+
+	// The replica in the server group never responds, so it times out when its share of the deadline is reached.
+	var replicaDeadline time.Time
+	provider := new(mockKvProvider)
+	provider.
+		On("GetOneReplica", mock.AnythingOfType("gocbcore.GetOneReplicaOptions"),
+			mock.AnythingOfType("gocbcore.GetReplicaCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOneReplicaOptions)
+			cb := args.Get(1).(gocbcore.GetReplicaCallback)
+
+			replicaDeadline = opts.Deadline
+			time.AfterFunc(time.Until(opts.Deadline), func() {
+				cb(nil, gocbcore.ErrTimeout)
+			})
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"value"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	timeout := 400 * time.Millisecond
+	start := time.Now()
+	res, err := col.readReplicas("someid", &GetOptions{Timeout: timeout}, &replicaReadPlan{
+		stages:         []replicaReadStage{{copies: []int{1}}, {copies: []int{0}}},
+		splitDeadline:  true,
+		advanceOnError: true,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().WithinDuration(start.Add(timeout/2), replicaDeadline, timeout/4)
+
+	details := res.Internal().ReplicaFallback()
+	suite.Require().NotNil(details)
+	suite.Assert().True(details.FellBack)
+	suite.Assert().Zero(details.ReplicaIndex)
+	suite.Assert().False(res.IsReplica())
+}
@@ -0,0 +1,123 @@
+package gocb
+
+import (
+	"hash/crc32"
+)
+
+// VbucketMap is the mapping of the vbuckets of a bucket to the nodes which hold them, read from the cluster
+// configuration currently in use by the SDK.
+// The mapping changes whenever the cluster is rebalanced or a node fails over, so it should be read again, and
+// RevID compared, rather than being cached for long periods.
+// UNCOMMITTED: This API may change in the future.
+type VbucketMap struct {
+	// RevID is the revision of the cluster configuration which the map was read from.
+	RevID int64
+
+	// NumNodes is the number of KV nodes in the configuration. Node indexes range from 0 to NumNodes-1.
+	NumNodes int
+
+	// NumReplicas is the number of replicas configured for the bucket.
+	NumReplicas int
+
+	// Vbuckets contains the nodes of each vbucket, indexed by vbucket ID.
+	Vbuckets []VbucketNodes
+}
+
+// VbucketNodes are the indexes of the nodes which hold a single vbucket. Node indexes identify the same node across
+// all of the vbuckets of a VbucketMap, but are not comparable between maps with different RevIDs.
+// UNCOMMITTED: This API may change in the future.
+type VbucketNodes struct {
+	// Active is the index of the node holding the active copy of the vbucket, or -1 if there is none.
+	Active int
+
+	// Replicas contains the index of the node holding each replica of the vbucket, ordered by replica index. An
+	// index of -1 means that the replica is not currently assigned to any node.
+	Replicas []int
+}
+
+// VbucketForKey returns the ID of the vbucket which the document with the given key belongs to.
+func (m *VbucketMap) VbucketForKey(id string) uint16 {
+	if len(m.Vbuckets) == 0 {
+		return 0
+	}
+
+	crc := crc32.ChecksumIEEE([]byte(id))
+	crcMidBits := uint16(crc>>16) & ^uint16(0x8000)
+	return crcMidBits % uint16(len(m.Vbuckets))
+}
+
+// VbucketsOnNode returns the IDs of the vbuckets which are active on the node with the given index.
+func (m *VbucketMap) VbucketsOnNode(nodeIdx int) []uint16 {
+	var vbIDs []uint16
+	for vbID, nodes := range m.Vbuckets {
+		if nodes.Active == nodeIdx {
+			vbIDs = append(vbIDs, uint16(vbID))
+		}
+	}
+
+	return vbIDs
+}
+
+// VbucketMap returns the mapping of the vbuckets of the bucket to the nodes which hold them, as known by the SDK at
+// the time of the call. This is read from the SDK's current cluster configuration, it does not send any requests.
+// The bucket must be connected, use WaitUntilReady to wait for this. Memcached buckets do not have vbuckets and
+// return ErrFeatureNotAvailable.
+// UNCOMMITTED: This API may change in the future.
+func (b *Bucket) VbucketMap() (*VbucketMap, error) {
+	agent, err := b.getKvProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := agent.ConfigSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	numVbuckets, err := snapshot.NumVbuckets()
+	if err != nil {
+		return nil, err
+	}
+
+	if numVbuckets == 0 {
+		return nil, wrapError(ErrFeatureNotAvailable, "bucket does not use vbuckets")
+	}
+
+	numReplicas, err := snapshot.NumReplicas()
+	if err != nil {
+		return nil, err
+	}
+
+	numServers, err := snapshot.NumServers()
+	if err != nil {
+		return nil, err
+	}
+
+	vbuckets := make([]VbucketNodes, numVbuckets)
+	for vbID := range vbuckets {
+		active, err := snapshot.VbucketToServer(uint16(vbID), 0)
+		if err != nil {
+			return nil, err
+		}
+
+		replicas := make([]int, numReplicas)
+		for replicaIdx := range replicas {
+			replicas[replicaIdx], err = snapshot.VbucketToServer(uint16(vbID), uint32(replicaIdx+1))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		vbuckets[vbID] = VbucketNodes{
+			Active:   active,
+			Replicas: replicas,
+		}
+	}
+
+	return &VbucketMap{
+		RevID:       snapshot.RevID(),
+		NumNodes:    numServers,
+		NumReplicas: numReplicas,
+		Vbuckets:    vbuckets,
+	}, nil
+}
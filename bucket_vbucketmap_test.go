@@ -0,0 +1,45 @@
+package gocb
+
+func (suite *IntegrationTestSuite) TestBucketVbucketMap() {
+	suite.skipIfUnsupported(KeyValueFeature)
+
+	vbMap, err := globalBucket.VbucketMap()
+	suite.Require().Nil(err, err)
+
+	suite.Require().NotEmpty(vbMap.Vbuckets)
+	suite.Assert().Greater(vbMap.NumNodes, 0)
+	for _, nodes := range vbMap.Vbuckets {
+		suite.Assert().GreaterOrEqual(nodes.Active, 0)
+		suite.Assert().Less(nodes.Active, vbMap.NumNodes)
+		suite.Assert().Len(nodes.Replicas, vbMap.NumReplicas)
+	}
+
+	res, err := globalCollection.Upsert("vbucketMapDoc", "value", nil)
+	suite.Require().Nil(err, err)
+
+	token := res.MutationToken()
+	suite.Require().NotNil(token)
+	suite.Assert().Equal(token.PartitionID(), uint64(vbMap.VbucketForKey("vbucketMapDoc")))
+}
+
+func (suite *UnitTestSuite) TestVbucketMapVbucketsOnNode() {
+	vbMap := &VbucketMap{
+		NumNodes:    2,
+		NumReplicas: 1,
+		Vbuckets: []VbucketNodes{
+			{Active: 0, Replicas: []int{1}},
+			{Active: 1, Replicas: []int{0}},
+			{Active: 0, Replicas: []int{-1}},
+			{Active: -1, Replicas: []int{-1}},
+		},
+	}
+
+	suite.Assert().Equal([]uint16{0, 2}, vbMap.VbucketsOnNode(0))
+	suite.Assert().Equal([]uint16{1}, vbMap.VbucketsOnNode(1))
+	suite.Assert().Empty(vbMap.VbucketsOnNode(2))
+
+	for _, key := range []string{"a", "key", "vbucketMapDoc"} {
+		suite.Assert().Less(int(vbMap.VbucketForKey(key)), len(vbMap.Vbuckets))
+	}
+	suite.Assert().Equal(uint16(0), (&VbucketMap{}).VbucketForKey("key"))
+}
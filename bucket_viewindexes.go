@@ -156,6 +156,18 @@ func (vm *ViewIndexManager) doMgmtRequest(ctx context.Context, req mgmtRequest)
 	return resp, nil
 }
 
+func maybeDesignDocumentNotFound(err error, name string, namespace DesignDocumentNamespace) error {
+	if !errors.Is(err, ErrDesignDocumentNotFound) {
+		return err
+	}
+
+	return &DesignDocumentNotFoundError{
+		Name:       name,
+		Namespace:  namespace,
+		InnerError: err,
+	}
+}
+
 // GetDesignDocumentOptions is the set of options available to the ViewIndexManager GetDesignDocument operation.
 type GetDesignDocumentOptions struct {
 	Timeout       time.Duration
@@ -170,9 +182,7 @@ type GetDesignDocumentOptions struct {
 
 func (vm *ViewIndexManager) ddocName(name string, namespace DesignDocumentNamespace) string {
 	if namespace == DesignDocumentNamespaceProduction {
-		if strings.HasPrefix(name, "dev_") {
-			name = strings.TrimLeft(name, "dev_")
-		}
+		name = strings.TrimPrefix(name, "dev_")
 	} else {
 		if !strings.HasPrefix(name, "dev_") {
 			name = "dev_" + name
@@ -223,7 +233,7 @@ func (vm *ViewIndexManager) getDesignDocument(name string, namespace DesignDocum
 	if resp.StatusCode != 200 {
 		vwErr := vm.tryParseErrorMessage(req, resp)
 		if vwErr != nil {
-			return nil, vwErr
+			return nil, maybeDesignDocumentNotFound(vwErr, name, namespace)
 		}
 
 		return nil, makeMgmtBadStatusError("failed to get design document", &req, resp)
@@ -477,6 +487,16 @@ func (vm *ViewIndexManager) dropDesignDocument(tracectx RequestSpanContext, name
 	defer ensureBodyClosed(resp.Body)
 
 	if resp.StatusCode != 200 {
+		if resp.StatusCode == 404 {
+			// The body of a 404 may not be the not_found error returned by the views service, but either way there
+			// is no design document to drop.
+			return &DesignDocumentNotFoundError{
+				Name:       name,
+				Namespace:  namespace,
+				InnerError: makeGenericMgmtError(ErrDesignDocumentNotFound, &req, resp, ""),
+			}
+		}
+
 		vwErr := vm.tryParseErrorMessage(req, resp)
 		if vwErr != nil {
 			return vwErr
@@ -500,7 +520,10 @@ type PublishDesignDocumentOptions struct {
 	Context context.Context
 }
 
-// PublishDesignDocument publishes a design document to the given bucket.
+// PublishDesignDocument publishes a design document to the given bucket, copying the development version of the
+// design document, with the dev_ prefix, to the production namespace. The production design document is replaced
+// in a single request, so view queries see either the previous or the published version. If the development
+// design document does not exist a *DesignDocumentNotFoundError is returned.
 func (vm *ViewIndexManager) PublishDesignDocument(name string, opts *PublishDesignDocumentOptions) error {
 	startTime := time.Now()
 	if opts == nil {
@@ -349,3 +349,73 @@ func (suite *UnitTestSuite) TestViewIndexManagerGetAllDesignDocumentsFiltersCorr
 	suite.Assert().Equal("test", ddocs[1].Name)
 	suite.Assert().Equal("test12", ddocs[2].Name)
 }
+
+func (suite *UnitTestSuite) TestViewIndexManagerPublishRoundTrip() {
+	ddoc := `{"views":{"by_name":{"map":"function (doc, meta) { emit(doc.name, null); }","reduce":"_count"}}}`
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.MatchedBy(func(req mgmtRequest) bool { return req.Method == "GET" })).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/_design/dev_default", req.Path)
+		}).
+		Return(&mgmtResponse{
+			Endpoint:   "http://localhost:8092/default",
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(ddoc))),
+		}, nil).
+		Once()
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.MatchedBy(func(req mgmtRequest) bool { return req.Method == "PUT" })).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/_design/default", req.Path)
+			suite.Assert().JSONEq(ddoc, string(req.Body))
+		}).
+		Return(&mgmtResponse{
+			Endpoint:   "http://localhost:8092/default",
+			StatusCode: 201,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"ok":true,"id":"_design/default"}`))),
+		}, nil).
+		Once()
+
+	viewMgr := ViewIndexManager{
+		mgmtProvider: mockProvider,
+		bucketName:   "mock",
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := viewMgr.PublishDesignDocument("default", nil)
+	suite.Require().Nil(err, err)
+	mockProvider.AssertExpectations(suite.T())
+}
+
+func (suite *UnitTestSuite) TestViewIndexManagerDropDoesntExistTyped() {
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(&mgmtResponse{
+			Endpoint:   "http://localhost:8092/default",
+			StatusCode: 404,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"error":"missing","reason":"deleted"}`))),
+		}, nil)
+
+	viewMgr := ViewIndexManager{
+		mgmtProvider: mockProvider,
+		bucketName:   "mock",
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := viewMgr.DropDesignDocument("dev_ddoc", DesignDocumentNamespaceDevelopment, nil)
+
+	var notFoundErr *DesignDocumentNotFoundError
+	suite.Require().True(errors.As(err, &notFoundErr), "Expected DesignDocumentNotFoundError but was %v", err)
+	suite.Assert().Equal("dev_ddoc", notFoundErr.Name)
+	suite.Assert().Equal(DesignDocumentNamespaceDevelopment, notFoundErr.Namespace)
+	suite.Assert().True(errors.Is(err, ErrDesignDocumentNotFound))
+}
@@ -37,19 +37,42 @@ func (meta *ViewMetaData) fromData(data jsonViewResponse) error {
 
 // ViewRow represents a single row returned from a view query.
 type ViewRow struct {
+	// ID is the ID of the document which emitted this row, rows from reduced views are not associated with any one
+	// document so have an empty ID.
 	ID         string
 	keyBytes   []byte
 	valueBytes []byte
 }
 
 // Key returns the key associated with this view row.
+// Rows from reduced views which are not grouped have a null key, in which case valuePtr is left unchanged, as it is
+// when decoding a JSON null.
 func (vr *ViewRow) Key(valuePtr interface{}) error {
-	return json.Unmarshal(vr.keyBytes, valuePtr)
+	return unmarshalViewRowField(vr.keyBytes, valuePtr)
 }
 
 // Value returns the value associated with this view row.
 func (vr *ViewRow) Value(valuePtr interface{}) error {
-	return json.Unmarshal(vr.valueBytes, valuePtr)
+	return unmarshalViewRowField(vr.valueBytes, valuePtr)
+}
+
+// RawKey returns the undecoded JSON key associated with this view row, or nil if the row has no key.
+func (vr *ViewRow) RawKey() json.RawMessage {
+	return vr.keyBytes
+}
+
+// RawValue returns the undecoded JSON value associated with this view row, or nil if the row has no value.
+func (vr *ViewRow) RawValue() json.RawMessage {
+	return vr.valueBytes
+}
+
+func unmarshalViewRowField(data []byte, valuePtr interface{}) error {
+	// A field which is missing from the row is treated the same as one which is null.
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+
+	return json.Unmarshal(data, valuePtr)
 }
 
 type viewRowReader interface {
@@ -215,13 +238,19 @@ func (r *ViewResult) MetaData() (*ViewMetaData, error) {
 }
 
 // ViewQuery performs a view query and returns a list of rows or an error.
+// Views operate at the bucket level and only index documents within the default collection, documents stored in
+// any other collection are never returned by a view query.
 func (b *Bucket) ViewQuery(designDoc string, viewName string, opts *ViewOptions) (*ViewResult, error) {
 	if opts == nil {
 		opts = &ViewOptions{}
 	}
 
+	if err := opts.validateCollection(); err != nil {
+		return nil, err
+	}
+
 	start := time.Now()
-	defer b.meter.ValueRecord(meterValueServiceViews, "views", start)
+	defer b.meter.KeyspaceValueRecord(meterValueServiceViews, "views", "", meterKeyspace{bucket: b.Name()}, start)
 
 	designDoc = b.maybePrefixDevDocument(opts.Namespace, designDoc)
 
@@ -267,6 +296,7 @@ func (b *Bucket) execViewQuery(
 			ViewName:           viewName,
 		}
 	}
+	provider = b.circuitBreakers.wrapViewProvider(provider)
 
 	res, err := provider.ViewQuery(ctx, gocbcore.ViewQueryOptions{
 		DesignDocumentName: ddoc,
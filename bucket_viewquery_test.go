@@ -326,3 +326,50 @@ func (suite *UnitTestSuite) TestViewQueryRaw() {
 
 	suite.Assert().Equal(reader.Meta, metadata)
 }
+
+func (suite *UnitTestSuite) TestViewQueryNonDefaultCollection() {
+	cli := new(mockConnectionManager)
+	bucket := newBucket(suite.newCluster(cli), "mockBucket")
+
+	_, err := bucket.ViewQuery("ddoc", "view", &ViewOptions{
+		ScopeName:      "inventory",
+		CollectionName: "airlines",
+	})
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected error to be feature not available but was %v", err)
+	}
+	cli.AssertNotCalled(suite.T(), "getViewProvider", "mockBucket")
+}
+
+func (suite *UnitTestSuite) TestViewQueryReducedRow() {
+	reader := &mockViewRowReader{
+		Dataset: []jsonViewRow{
+			{Value: json.RawMessage("42")},
+		},
+		Suite: suite,
+	}
+	result := newViewResult(reader)
+
+	suite.Require().True(result.Next())
+	row := result.Row()
+	suite.Assert().Empty(row.ID)
+
+	key := "unchanged"
+	suite.Require().Nil(row.Key(&key))
+	suite.Assert().Equal("unchanged", key)
+	suite.Assert().Equal(json.RawMessage("null"), row.RawKey())
+
+	var val int
+	suite.Require().Nil(row.Value(&val))
+	suite.Assert().Equal(42, val)
+	suite.Assert().Equal(json.RawMessage("42"), row.RawValue())
+
+	suite.Assert().False(result.Next())
+	suite.Require().Nil(result.Err())
+
+	var missing interface{}
+	emptyRow := ViewRow{}
+	suite.Require().Nil(emptyRow.Key(&missing))
+	suite.Assert().Nil(missing)
+	suite.Assert().Nil(emptyRow.RawKey())
+}
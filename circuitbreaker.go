@@ -1,9 +1,19 @@
 package gocb
 
-import "time"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
-// CircuitBreakerCallback is the callback used by the circuit breaker to determine if an error should count toward
-// the circuit breaker failure count.
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+// CircuitBreakerCallback is the callback used by the circuit breaker to determine whether a request completed
+// successfully. It returns true if the error should count as a success and false if it should count toward the
+// circuit breaker failure count, by default only timeouts, and for the query, analytics, search and views services
+// internal server failures, count as failures.
 type CircuitBreakerCallback func(error) bool
 
 // CircuitBreakerConfig are the settings for configuring circuit breakers.
@@ -15,4 +25,408 @@ type CircuitBreakerConfig struct {
 	RollingWindow            time.Duration
 	CompletionCallback       CircuitBreakerCallback
 	CanaryTimeout            time.Duration
+
+	// StateChangeCallback is invoked whenever a circuit breaker changes state, e.g. for recording metrics.
+	// It is only invoked for the circuit breakers of the services configured by
+	// ClusterOptions.ServiceCircuitBreakerConfig, the KV circuit breakers are managed per connection and do not
+	// report state changes.
+	// UNCOMMITTED: This API may change in the future.
+	StateChangeCallback CircuitBreakerStateChangeCallback
+}
+
+// CircuitBreakerState is the state of a circuit breaker.
+// UNCOMMITTED: This API may change in the future.
+type CircuitBreakerState uint32
+
+const (
+	// CircuitBreakerStateClosed indicates that requests are being dispatched as normal.
+	CircuitBreakerStateClosed CircuitBreakerState = iota
+
+	// CircuitBreakerStateOpen indicates that too many requests have failed, so requests fail fast with a
+	// *CircuitBreakerOpenError until the sleep window has passed.
+	CircuitBreakerStateOpen
+
+	// CircuitBreakerStateHalfOpen indicates that the sleep window has passed and a single request is being dispatched
+	// to determine whether the service has recovered.
+	CircuitBreakerStateHalfOpen
+)
+
+// String returns the string representation of the state.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerStateClosed:
+		return "closed"
+	case CircuitBreakerStateOpen:
+		return "open"
+	case CircuitBreakerStateHalfOpen:
+		return "half_open"
+	}
+
+	return ""
+}
+
+// CircuitBreakerStateChangeEvent is the payload passed to a CircuitBreakerStateChangeCallback.
+// UNCOMMITTED: This API may change in the future.
+type CircuitBreakerStateChangeEvent struct {
+	Service       ServiceType
+	PreviousState CircuitBreakerState
+	State         CircuitBreakerState
+}
+
+// CircuitBreakerStateChangeCallback is invoked when a circuit breaker changes state.
+// UNCOMMITTED: This API may change in the future.
+type CircuitBreakerStateChangeCallback func(event CircuitBreakerStateChangeEvent)
+
+// CircuitBreakerOpenError occurs when a request is not dispatched because the circuit breaker for the service is
+// open.
+// UNCOMMITTED: This API may change in the future.
+type CircuitBreakerOpenError struct {
+	Service ServiceType
+}
+
+// Error returns the string representation of this error.
+func (e *CircuitBreakerOpenError) Error() string {
+	return fmt.Sprintf("%s: %s service", ErrCircuitBreakerOpen.Error(), gocbcore.ServiceType(e.Service).String())
+}
+
+// Is returns true if target is ErrCircuitBreakerOpen, so that open circuit breakers can be detected with errors.Is.
+func (e *CircuitBreakerOpenError) Is(target error) bool {
+	return target == ErrCircuitBreakerOpen
+}
+
+const (
+	defaultCircuitBreakerVolumeThreshold          = 20
+	defaultCircuitBreakerErrorThresholdPercentage = 50
+	defaultCircuitBreakerSleepWindow              = 5 * time.Second
+	defaultCircuitBreakerRollingWindow            = 1 * time.Minute
+)
+
+// defaultServiceCircuitBreakerCallback counts timeouts and internal server failures as failures, other errors such
+// as a failure to parse a query are the fault of the request rather than the service.
+func defaultServiceCircuitBreakerCallback(err error) bool {
+	return !errors.Is(err, ErrTimeout) && !errors.Is(err, ErrInternalServerFailure)
+}
+
+// circuitBreaker is a circuit breaker for the requests sent to a single HTTP based service. Unlike the KV circuit
+// breakers, which send a canary request of their own, the first request after the sleep window has passed is used
+// as the canary.
+type circuitBreaker struct {
+	service ServiceType
+	config  CircuitBreakerConfig
+	now     func() time.Time
+
+	lock        sync.Mutex
+	state       CircuitBreakerState
+	windowStart time.Time
+	total       int64
+	failed      int64
+	openedAt    time.Time
+}
+
+func newCircuitBreaker(service ServiceType, config CircuitBreakerConfig) *circuitBreaker {
+	if config.VolumeThreshold == 0 {
+		config.VolumeThreshold = defaultCircuitBreakerVolumeThreshold
+	}
+	if config.ErrorThresholdPercentage == 0 {
+		config.ErrorThresholdPercentage = defaultCircuitBreakerErrorThresholdPercentage
+	}
+	if config.SleepWindow == 0 {
+		config.SleepWindow = defaultCircuitBreakerSleepWindow
+	}
+	if config.RollingWindow == 0 {
+		config.RollingWindow = defaultCircuitBreakerRollingWindow
+	}
+	if config.CompletionCallback == nil {
+		config.CompletionCallback = defaultServiceCircuitBreakerCallback
+	}
+
+	return &circuitBreaker{
+		service: service,
+		config:  config,
+		now:     time.Now,
+	}
+}
+
+// allowRequest returns a *CircuitBreakerOpenError if the request must not be dispatched.
+func (cb *circuitBreaker) allowRequest() error {
+	cb.lock.Lock()
+	now := cb.now()
+
+	var event *CircuitBreakerStateChangeEvent
+	allowed := true
+	switch cb.state {
+	case CircuitBreakerStateOpen:
+		if now.Sub(cb.openedAt) < cb.config.SleepWindow {
+			allowed = false
+			break
+		}
+
+		event = cb.setState(CircuitBreakerStateHalfOpen)
+		cb.openedAt = now
+	case CircuitBreakerStateHalfOpen:
+		// Only a single canary is allowed at a time, unless the canary has failed to report back within the sleep
+		// window.
+		if now.Sub(cb.openedAt) < cb.config.SleepWindow {
+			allowed = false
+			break
+		}
+
+		cb.openedAt = now
+	}
+	cb.lock.Unlock()
+
+	cb.notify(event)
+
+	if !allowed {
+		return &CircuitBreakerOpenError{
+			Service: cb.service,
+		}
+	}
+
+	return nil
+}
+
+// markResult records the result of a dispatched request, callback overrides the configured CompletionCallback if
+// not nil.
+func (cb *circuitBreaker) markResult(err error, callback CircuitBreakerCallback) {
+	if callback == nil {
+		callback = cb.config.CompletionCallback
+	}
+	success := err == nil || callback(err)
+
+	cb.lock.Lock()
+	now := cb.now()
+
+	var event *CircuitBreakerStateChangeEvent
+	switch cb.state {
+	case CircuitBreakerStateHalfOpen:
+		if success {
+			event = cb.setState(CircuitBreakerStateClosed)
+			cb.resetWindow(now)
+		} else {
+			event = cb.setState(CircuitBreakerStateOpen)
+			cb.openedAt = now
+		}
+	case CircuitBreakerStateClosed:
+		if now.Sub(cb.windowStart) > cb.config.RollingWindow {
+			cb.resetWindow(now)
+		}
+
+		cb.total++
+		if !success {
+			cb.failed++
+		}
+
+		if cb.total >= cb.config.VolumeThreshold &&
+			float64(cb.failed)/float64(cb.total)*100 >= cb.config.ErrorThresholdPercentage {
+			event = cb.setState(CircuitBreakerStateOpen)
+			cb.openedAt = now
+		}
+	}
+	cb.lock.Unlock()
+
+	cb.notify(event)
+}
+
+func (cb *circuitBreaker) resetWindow(now time.Time) {
+	cb.windowStart = now
+	cb.total = 0
+	cb.failed = 0
+}
+
+// setState changes the state of the breaker, returning the event to be sent once the lock is released. The lock must
+// be held.
+func (cb *circuitBreaker) setState(state CircuitBreakerState) *CircuitBreakerStateChangeEvent {
+	event := &CircuitBreakerStateChangeEvent{
+		Service:       cb.service,
+		PreviousState: cb.state,
+		State:         state,
+	}
+	cb.state = state
+	logDebugf("Circuit breaker for %s service changed from %s to %s", gocbcore.ServiceType(cb.service).String(),
+		event.PreviousState, event.State)
+
+	return event
+}
+
+func (cb *circuitBreaker) notify(event *CircuitBreakerStateChangeEvent) {
+	if event == nil || cb.config.StateChangeCallback == nil {
+		return
+	}
+
+	cb.config.StateChangeCallback(*event)
+}
+
+// circuitBreakers holds the circuit breakers of the HTTP based services, a service without a circuit breaker has its
+// requests dispatched as normal.
+type circuitBreakers struct {
+	breakers map[ServiceType]*circuitBreaker
+}
+
+func newCircuitBreakers(configs map[ServiceType]CircuitBreakerConfig) *circuitBreakers {
+	breakers := make(map[ServiceType]*circuitBreaker)
+	for service, config := range configs {
+		if config.Disabled {
+			continue
+		}
+
+		switch service {
+		case ServiceTypeQuery, ServiceTypeAnalytics, ServiceTypeSearch, ServiceTypeViews:
+			breakers[service] = newCircuitBreaker(service, config)
+		default:
+			logWarnf("Ignoring circuit breaker config for unsupported %s service",
+				gocbcore.ServiceType(service).String())
+		}
+	}
+
+	return &circuitBreakers{
+		breakers: breakers,
+	}
+}
+
+func (cbs *circuitBreakers) get(service ServiceType) *circuitBreaker {
+	if cbs == nil {
+		return nil
+	}
+
+	return cbs.breakers[service]
+}
+
+func (cbs *circuitBreakers) wrapQueryProvider(provider queryProvider) queryProvider {
+	breaker := cbs.get(ServiceTypeQuery)
+	if breaker == nil {
+		return provider
+	}
+
+	return &circuitBreakerQueryProvider{
+		provider: provider,
+		breaker:  breaker,
+	}
+}
+
+func (cbs *circuitBreakers) wrapAnalyticsProvider(provider analyticsProvider) analyticsProvider {
+	breaker := cbs.get(ServiceTypeAnalytics)
+	if breaker == nil {
+		return provider
+	}
+
+	return &circuitBreakerAnalyticsProvider{
+		provider: provider,
+		breaker:  breaker,
+	}
+}
+
+func (cbs *circuitBreakers) wrapSearchProvider(provider searchProvider) searchProvider {
+	breaker := cbs.get(ServiceTypeSearch)
+	if breaker == nil {
+		return provider
+	}
+
+	return &circuitBreakerSearchProvider{
+		provider: provider,
+		breaker:  breaker,
+	}
+}
+
+func (cbs *circuitBreakers) wrapViewProvider(provider viewProvider) viewProvider {
+	breaker := cbs.get(ServiceTypeViews)
+	if breaker == nil {
+		return provider
+	}
+
+	return &circuitBreakerViewProvider{
+		provider: provider,
+		breaker:  breaker,
+	}
+}
+
+// withQueryCircuitBreakerCallback returns the provider with the CompletionCallback of its circuit breaker overridden
+// by callback, if the provider has a circuit breaker and callback is not nil.
+func withQueryCircuitBreakerCallback(provider queryProvider, callback CircuitBreakerCallback) queryProvider {
+	breakerProvider, ok := provider.(*circuitBreakerQueryProvider)
+	if !ok || callback == nil {
+		return provider
+	}
+
+	return &circuitBreakerQueryProvider{
+		provider: breakerProvider.provider,
+		breaker:  breakerProvider.breaker,
+		callback: callback,
+	}
+}
+
+type circuitBreakerQueryProvider struct {
+	provider queryProvider
+	breaker  *circuitBreaker
+	callback CircuitBreakerCallback
+}
+
+func (p *circuitBreakerQueryProvider) N1QLQuery(ctx context.Context,
+	opts gocbcore.N1QLQueryOptions) (queryRowReader, error) {
+	if err := p.breaker.allowRequest(); err != nil {
+		return nil, err
+	}
+
+	res, err := p.provider.N1QLQuery(ctx, opts)
+	p.breaker.markResult(maybeEnhanceQueryError(err), p.callback)
+	return res, err
+}
+
+func (p *circuitBreakerQueryProvider) PreparedN1QLQuery(ctx context.Context,
+	opts gocbcore.N1QLQueryOptions) (queryRowReader, error) {
+	if err := p.breaker.allowRequest(); err != nil {
+		return nil, err
+	}
+
+	res, err := p.provider.PreparedN1QLQuery(ctx, opts)
+	p.breaker.markResult(maybeEnhanceQueryError(err), p.callback)
+	return res, err
+}
+
+type circuitBreakerAnalyticsProvider struct {
+	provider analyticsProvider
+	breaker  *circuitBreaker
+}
+
+func (p *circuitBreakerAnalyticsProvider) AnalyticsQuery(ctx context.Context,
+	opts gocbcore.AnalyticsQueryOptions) (analyticsRowReader, error) {
+	if err := p.breaker.allowRequest(); err != nil {
+		return nil, err
+	}
+
+	res, err := p.provider.AnalyticsQuery(ctx, opts)
+	p.breaker.markResult(maybeEnhanceAnalyticsError(err), nil)
+	return res, err
+}
+
+type circuitBreakerSearchProvider struct {
+	provider searchProvider
+	breaker  *circuitBreaker
+}
+
+func (p *circuitBreakerSearchProvider) SearchQuery(ctx context.Context,
+	opts gocbcore.SearchQueryOptions) (searchRowReader, error) {
+	if err := p.breaker.allowRequest(); err != nil {
+		return nil, err
+	}
+
+	res, err := p.provider.SearchQuery(ctx, opts)
+	p.breaker.markResult(maybeEnhanceSearchError(err), nil)
+	return res, err
+}
+
+type circuitBreakerViewProvider struct {
+	provider viewProvider
+	breaker  *circuitBreaker
+}
+
+func (p *circuitBreakerViewProvider) ViewQuery(ctx context.Context,
+	opts gocbcore.ViewQueryOptions) (viewRowReader, error) {
+	if err := p.breaker.allowRequest(); err != nil {
+		return nil, err
+	}
+
+	res, err := p.provider.ViewQuery(ctx, opts)
+	p.breaker.markResult(maybeEnhanceViewError(err), nil)
+	return res, err
 }
@@ -0,0 +1,143 @@
+package gocb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestCircuitBreakerStateChanges() {
+	var events []CircuitBreakerStateChangeEvent
+	breaker := newCircuitBreaker(ServiceTypeQuery, CircuitBreakerConfig{
+		VolumeThreshold:          2,
+		ErrorThresholdPercentage: 60,
+		SleepWindow:              time.Second,
+		StateChangeCallback: func(event CircuitBreakerStateChangeEvent) {
+			events = append(events, event)
+		},
+	})
+
+	now := time.Now()
+	breaker.now = func() time.Time {
+		return now
+	}
+
+	suite.Require().Nil(breaker.allowRequest())
+	breaker.markResult(ErrTimeout, nil)
+	suite.Require().Nil(breaker.allowRequest())
+	breaker.markResult(ErrParsingFailure, nil)
+	suite.Require().Nil(breaker.allowRequest())
+	breaker.markResult(ErrTimeout, nil)
+
+	err := breaker.allowRequest()
+	if !errors.Is(err, ErrCircuitBreakerOpen) {
+		suite.T().Fatalf("Expected error to be circuit breaker open but was %v", err)
+	}
+	var openErr *CircuitBreakerOpenError
+	suite.Require().True(errors.As(err, &openErr))
+	suite.Assert().Equal(ServiceTypeQuery, openErr.Service)
+
+	// Once the sleep window has passed a single canary is allowed through.
+	now = now.Add(time.Second)
+	suite.Require().Nil(breaker.allowRequest())
+	suite.Assert().NotNil(breaker.allowRequest())
+
+	breaker.markResult(nil, nil)
+	suite.Require().Nil(breaker.allowRequest())
+
+	suite.Assert().Equal([]CircuitBreakerStateChangeEvent{
+		{Service: ServiceTypeQuery, PreviousState: CircuitBreakerStateClosed, State: CircuitBreakerStateOpen},
+		{Service: ServiceTypeQuery, PreviousState: CircuitBreakerStateOpen, State: CircuitBreakerStateHalfOpen},
+		{Service: ServiceTypeQuery, PreviousState: CircuitBreakerStateHalfOpen, State: CircuitBreakerStateClosed},
+	}, events)
+}
+
+func (suite *UnitTestSuite) TestCircuitBreakerFailedCanaryReopens() {
+	breaker := newCircuitBreaker(ServiceTypeSearch, CircuitBreakerConfig{
+		VolumeThreshold: 1,
+		SleepWindow:     time.Second,
+	})
+
+	now := time.Now()
+	breaker.now = func() time.Time {
+		return now
+	}
+
+	breaker.markResult(ErrTimeout, nil)
+	suite.Assert().NotNil(breaker.allowRequest())
+
+	now = now.Add(time.Second)
+	suite.Require().Nil(breaker.allowRequest())
+	breaker.markResult(ErrTimeout, nil)
+	suite.Assert().Equal(CircuitBreakerStateOpen, breaker.state)
+	suite.Assert().NotNil(breaker.allowRequest())
+}
+
+func (suite *UnitTestSuite) TestCircuitBreakerCallbackCountsFailures() {
+	breaker := newCircuitBreaker(ServiceTypeQuery, CircuitBreakerConfig{
+		VolumeThreshold: 1,
+		CompletionCallback: func(err error) bool {
+			return !errors.Is(err, ErrParsingFailure)
+		},
+	})
+
+	// The callback decides what counts as a failure, a timeout no longer does.
+	breaker.markResult(ErrTimeout, nil)
+	suite.Require().Nil(breaker.allowRequest())
+
+	breaker.markResult(ErrParsingFailure, nil)
+	suite.Assert().Equal(CircuitBreakerStateOpen, breaker.state)
+	suite.Assert().NotNil(breaker.allowRequest())
+
+	// A callback passed with the request takes precedence over the configured one.
+	breaker = newCircuitBreaker(ServiceTypeQuery, CircuitBreakerConfig{
+		VolumeThreshold: 1,
+	})
+	breaker.markResult(ErrIndexNotFound, func(err error) bool {
+		return false
+	})
+	suite.Assert().Equal(CircuitBreakerStateOpen, breaker.state)
+}
+
+func (suite *UnitTestSuite) TestQueryCircuitBreaker() {
+	provider := new(mockQueryProvider)
+	provider.
+		On("N1QLQuery", mock.Anything, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(nil, ErrTimeout)
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(provider, nil)
+
+	cluster := clusterFromOptions(ClusterOptions{
+		Tracer: &NoopTracer{},
+		Meter:  &NoopMeter{},
+		ServiceCircuitBreakerConfig: map[ServiceType]CircuitBreakerConfig{
+			ServiceTypeQuery: {
+				VolumeThreshold:          2,
+				ErrorThresholdPercentage: 60,
+			},
+		},
+	})
+	cluster.connectionManager = cli
+
+	// Timeouts do not count as failures for this query, so the breaker stays closed.
+	_, err := cluster.Query("SELECT 1=1", &QueryOptions{
+		Adhoc: true,
+		CircuitBreakerCallback: func(err error) bool {
+			return true
+		},
+	})
+	suite.Require().True(errors.Is(err, ErrTimeout))
+
+	for i := 0; i < 2; i++ {
+		_, err = cluster.Query("SELECT 1=1", &QueryOptions{Adhoc: true})
+		suite.Require().True(errors.Is(err, ErrTimeout))
+	}
+
+	_, err = cluster.Query("SELECT 1=1", &QueryOptions{Adhoc: true})
+	if !errors.Is(err, ErrCircuitBreakerOpen) {
+		suite.T().Fatalf("Expected error to be circuit breaker open but was %v", err)
+	}
+	provider.AssertNumberOfCalls(suite.T(), "N1QLQuery", 3)
+}
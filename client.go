@@ -49,23 +49,6 @@ func (c *stdConnectionMgr) buildConfig(cluster *Cluster) error {
 		}
 	}
 
-	var tlsRootCAProvider func() *x509.CertPool
-	if cluster.internalConfig.TLSRootCAProvider == nil {
-		tlsRootCAProvider = func() *x509.CertPool {
-			if cluster.securityConfig.TLSSkipVerify {
-				return nil
-			}
-
-			if cluster.securityConfig.TLSRootCAs == nil {
-				return &x509.CertPool{}
-			}
-
-			return cluster.securityConfig.TLSRootCAs
-		}
-	} else {
-		tlsRootCAProvider = cluster.internalConfig.TLSRootCAProvider
-	}
-
 	var authMechanisms []gocbcore.AuthMechanism
 	for _, mech := range cluster.securityConfig.AllowedSaslMechanisms {
 		authMechanisms = append(authMechanisms, gocbcore.AuthMechanism(mech))
@@ -75,7 +58,7 @@ func (c *stdConnectionMgr) buildConfig(cluster *Cluster) error {
 		AgentConfig: gocbcore.AgentConfig{
 			UserAgent: Identifier(),
 			SecurityConfig: gocbcore.SecurityConfig{
-				TLSRootCAProvider: tlsRootCAProvider,
+				TLSRootCAProvider: cluster.tlsRootCAProvider(),
 				AuthMechanisms:    authMechanisms,
 			},
 			IoConfig: gocbcore.IoConfig{
@@ -84,8 +67,18 @@ func (c *stdConnectionMgr) buildConfig(cluster *Cluster) error {
 				UseMutationTokens:      cluster.useMutationTokens,
 				UseOutOfOrderResponses: true,
 			},
+			ConfigPollerConfig: gocbcore.ConfigPollerConfig{
+				CccpPollPeriod: cluster.configPollerConfig.PollInterval,
+				CccpMaxWait:    cluster.configPollerConfig.MaxWait,
+			},
+			CompressionConfig: gocbcore.CompressionConfig{
+				Enabled:  cluster.compressionConfig.Enabled,
+				MinSize:  int(cluster.compressionConfig.MinSize),
+				MinRatio: cluster.compressionConfig.MinRatio,
+			},
 			KVConfig: gocbcore.KVConfig{
 				ConnectTimeout: cluster.timeoutsConfig.ConnectTimeout,
+				PoolSize:       cluster.numKVConnections,
 			},
 			DefaultRetryStrategy: cluster.retryStrategyWrapper,
 			CircuitBreakerConfig: gocbcore.CircuitBreakerConfig{
@@ -113,13 +106,19 @@ func (c *stdConnectionMgr) buildConfig(cluster *Cluster) error {
 		},
 	}
 
-	err := config.FromConnStr(cluster.connSpec().String())
+	spec := cluster.connSpec()
+	err := config.FromConnStr(spec.String())
 	if err != nil {
 		return err
 	}
 
+	// The couchbases scheme must never connect without TLS, including to the targets of a DNS SRV record.
+	if spec.Scheme == "couchbases" && !config.SecurityConfig.UseTLS {
+		return makeInvalidArgumentsError("couchbases scheme requires TLS to be enabled")
+	}
+
 	config.SecurityConfig.Auth = &coreAuthWrapper{
-		auth: cluster.authenticator(),
+		auth: cluster.authenticator,
 	}
 
 	c.config = config
@@ -276,3 +275,29 @@ func (c *stdConnectionMgr) close() error {
 	defer c.lock.Unlock()
 	return c.agentgroup.Close()
 }
+
+// tlsRootCAProvider returns the function used by the SDK to get the certificate authorities used to verify the
+// certificates presented by the cluster, a nil pool disables verification.
+func (c *Cluster) tlsRootCAProvider() func() *x509.CertPool {
+	if c.internalConfig.TLSRootCAProvider != nil {
+		return c.internalConfig.TLSRootCAProvider
+	}
+
+	return func() *x509.CertPool {
+		if c.securityConfig.TLSSkipVerify {
+			return nil
+		}
+
+		pool := c.securityConfig.TLSRootCAs
+		if c.securityConfig.TLSRootCAProvider != nil {
+			pool = c.securityConfig.TLSRootCAProvider()
+		}
+
+		// A nil pool would disable verification, which must only happen when TLSSkipVerify is set.
+		if pool == nil {
+			return &x509.CertPool{}
+		}
+
+		return pool
+	}
+}
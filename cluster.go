@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	gocbcore "github.com/couchbase/gocbcore/v10"
@@ -14,17 +16,25 @@ import (
 
 // Cluster represents a connection to a specific Couchbase cluster.
 type Cluster struct {
-	cSpec gocbconnstr.ConnSpec
-	auth  Authenticator
+	cSpec    gocbconnstr.ConnSpec
+	auth     Authenticator
+	authLock sync.Mutex
 
 	connectionManager connectionManager
 
 	useServerDurations bool
 	useMutationTokens  bool
+	numKVConnections   int
+	kvWarmupTimeout    time.Duration
+
+	pinKVToBootstrapHosts bool
+	preferredServerGroup  string
+	durabilityFallback    bool
 
 	timeoutsConfig TimeoutsConfig
 
 	transcoder           Transcoder
+	deserializer         Deserializer
 	retryStrategyWrapper *retryStrategyWrapper
 
 	orphanLoggerEnabled    bool
@@ -34,18 +44,73 @@ type Cluster struct {
 	tracer RequestTracer
 	meter  *meterWrapper
 
+	searchCapabilities *searchCapabilities
+
+	preparedStatementCache *PreparedStatementCache
+	resultMemoryLimiter    *resultMemoryLimiter
+	observeBatcher         *observeBatcher
+	activeQueries          *activeQueryTracker
+
 	circuitBreakerConfig CircuitBreakerConfig
+	circuitBreakers      *circuitBreakers
+	configPollerConfig   ConfigPollerConfig
+	compressionConfig    CompressionConfig
 	securityConfig       SecurityConfig
 	internalConfig       InternalConfig
 	transactionsConfig   TransactionsConfig
+	topologyConfig       TopologyConfig
+	dnsConfig            DNSConfig
+	capabilityConfig     CapabilityConfig
+	connectConfig        ConnectConfig
 
-	transactions *Transactions
+	transactions    *Transactions
+	topologyWatcher *topologyWatcher
+	configWatcher   *configWatcher
+
+	capabilityWatchers     map[string]*capabilityWatcher
+	capabilityWatchersLock sync.Mutex
+
+	bucketOpened uint32
 }
 
 // IoConfig specifies IO related configuration options.
 type IoConfig struct {
 	DisableMutationTokens  bool
 	DisableServerDurations bool
+
+	// NumKVConnections is the number of KV connections opened to each node, defaults to 1. Requests to a node are
+	// dispatched to whichever of its connections is next free, so using more connections allows small requests to
+	// proceed whilst another connection is busy sending or receiving a large value. Each connection uses a file
+	// descriptor and memory on both the client and the server, so this should only be raised for workloads which
+	// are limited by a single connection. The kv_pool_size connection string option takes precedence.
+	//
+	// Requests are pipelined over each connection, many requests can be in flight on a single connection at once and
+	// the server may respond to them out of order, so a larger pool does not increase the number of requests which
+	// can be outstanding. Rather it spreads the writing and reading of requests across more sockets, which helps when
+	// values are large or when a single socket is saturated. The connections of the pool are established in the
+	// background, see WaitUntilReadyOptions.WarmKVConnections to wait for all of them before sending requests.
+	// UNCOMMITTED: This API may change in the future.
+	NumKVConnections int
+
+	// WarmupTimeout is the maximum time that WaitUntilReady spends waiting for every KV connection of the pool to be
+	// established when WaitUntilReadyOptions.WarmKVConnections is set. This is bounded by the timeout passed to
+	// WaitUntilReady, if zero then only that timeout applies.
+	// UNCOMMITTED: This API may change in the future.
+	WarmupTimeout time.Duration
+
+	// PinKVToBootstrapHosts restricts KV operations to the nodes given in the connection string, for deployments
+	// such as sidecars where the application should only ever send KV traffic to a co-located node. The cluster
+	// configuration is still fetched and refreshed as normal, and query, search, analytics and management requests
+	// still use every node in the cluster. A KV operation on a document whose vbucket is not active on one of the
+	// pinned nodes fails with ErrVbucketNotOnPinnedNode rather than being sent to the node which owns it. The
+	// kv_pin_to_bootstrap_hosts connection string option takes precedence.
+	//
+	// The SDK still maintains connections to the other KV nodes, but does not send operations to them. Replica
+	// reads, and the observe requests used by PersistTo and ReplicateTo, are sent to the nodes holding the
+	// replicas and so are not restricted. Synchronous durability (DurabilityLevel) is coordinated by the active node
+	// and is unaffected, but as with any durable write it still depends on the replica nodes being available.
+	// UNCOMMITTED: This API may change in the future.
+	PinKVToBootstrapHosts bool
 }
 
 // TimeoutsConfig specifies options for various operation timeouts.
@@ -64,25 +129,115 @@ type TimeoutsConfig struct {
 // OrphanReporterConfig specifies options for controlling the orphan
 // reporter which records when the SDK receives responses for requests
 // that are no longer in the system (usually due to being timed out).
+//
+// Every ReportInterval the reporter emits a JSON report of the orphaned responses received since the last report,
+// giving for each service the total count and the slowest responses with the operation, the local and remote
+// addresses, the server duration and the time since the request was dispatched. Reports are written to the logger at
+// the warn level, with the component gocbcore when set with SetStructuredLogger, and nothing is emitted for an interval
+// in which no responses were orphaned.
 type OrphanReporterConfig struct {
-	Disabled       bool
+	// Disabled stops orphaned responses from being recorded and reported.
+	Disabled bool
+
+	// ReportInterval is how often the orphaned responses are reported. Defaults to 10 seconds.
 	ReportInterval time.Duration
-	SampleSize     uint32
+
+	// SampleSize is the number of the slowest orphaned responses included in each report, per service. Defaults to
+	// 10.
+	SampleSize uint32
+}
+
+// ConfigPollerConfig specifies options for controlling how often the SDK fetches the cluster configuration.
+//
+// When an operation is sent to a node which no longer owns the vbucket for the document, for example during a
+// rebalance, the server responds with not-my-vbucket (NMVB). If that response carries a newer configuration then it
+// is applied immediately and the operation is retried against the new owner, otherwise the operation is retried
+// until the next configuration is fetched. Such operations are retried with KVNotMyVBucketRetryReason, which is
+// reported by Result.RetryReasons when the operation succeeds, and within the RetryReasons of KeyValueError should
+// the operation ultimately fail. The SDK cannot report whether an NMVB response caused a new configuration to be
+// applied, as gocbcore applies configurations internally without notifying the SDK.
+// Reducing PollInterval causes the SDK to pick up a new configuration sooner following a burst of NMVB responses,
+// at the cost of more configuration requests being made to the cluster.
+// UNCOMMITTED: This API may change in the future.
+type ConfigPollerConfig struct {
+	// PollInterval is how often the cluster configuration is polled over the KV connections, defaults to 2.5s.
+	PollInterval time.Duration
+
+	// MaxWait is the maximum time to wait for a node to respond to a configuration request before trying the
+	// next node, defaults to 2s.
+	MaxWait time.Duration
+}
+
+// CompressionConfig specifies options for controlling whether the SDK compresses document values using Snappy.
+//
+// When enabled the SDK advertises Snappy support to the server when connecting, values which are at least MinSize
+// bytes are compressed before they are sent, and the server is able to return compressed values which the SDK
+// decompresses transparently. A value is only sent compressed if the compressed size is at most MinRatio of the
+// original size, otherwise it is sent uncompressed. When disabled Snappy is not advertised, so the server never sends
+// compressed values to the SDK. The compression, compression_min_size and compression_min_ratio connection string
+// options take precedence.
+// UNCOMMITTED: This API may change in the future.
+type CompressionConfig struct {
+	// Enabled causes Snappy to be negotiated with the server and values to be compressed.
+	Enabled bool
+
+	// MinSize is the minimum size in bytes of a value before it is compressed, defaults to 32 bytes.
+	MinSize uint32
+
+	// MinRatio is the maximum ratio of the compressed size to the original size of a value for the compressed value
+	// to be sent, defaults to 0.83.
+	MinRatio float64
 }
 
 // SecurityConfig specifies options for controlling security related
 // items such as TLS root certificates and verification skipping.
 type SecurityConfig struct {
-	TLSRootCAs    *x509.CertPool
+	// TLSRootCAs is the pool of certificate authorities used to verify the certificates presented by the cluster,
+	// this allows certificates held in memory, e.g. fetched from a secrets manager, to be used.
+	TLSRootCAs *x509.CertPool
+
+	// TLSRootCAProvider is called whenever a TLS connection is established to get the pool of certificate
+	// authorities used to verify the certificates presented by the cluster, allowing the certificate authorities to be
+	// rotated without reconnecting. Cannot be used with TLSRootCAs.
+	// UNCOMMITTED: This API may change in the future.
+	TLSRootCAProvider func() *x509.CertPool
+
+	// TLSSkipVerify disables verification of the certificates presented by the cluster, leaving connections open to
+	// man-in-the-middle attacks. This must only ever be used in development, a warning is logged on every Connect
+	// whilst it is enabled.
 	TLSSkipVerify bool
 
 	// AllowedSaslMechanisms is the list of mechanisms that the SDK can use to attempt authentication.
 	// Note that if you add PLAIN to the list, this will cause credential leakage on the network
 	// since PLAIN sends the credentials in cleartext. It is disabled by default to prevent downgrade attacks. We
 	// recommend using a TLS connection if using PLAIN.
+	// When set, the SDK only ever authenticates using a mechanism in the list, regardless of which mechanisms the
+	// server advertises, so this can be used to require SCRAM-SHA512 by setting it to only ScramSha512SaslMechanism.
+	// This applies to TLS connections as well, which otherwise default to PLAIN. If the server supports none of the
+	// listed mechanisms then connections to it cannot be authenticated, the SDK never falls back to a mechanism
+	// outside of the list. When ConnectConfig.WaitUntilConnected is set Connect then returns a *ConnectError wrapping
+	// a *SaslMechanismError, which names both the allowed mechanisms and those offered by the server. Connect returns
+	// ErrInvalidArgument if the list contains an unknown mechanism, such as CRAM-MD5 which the SDK does not support.
 	AllowedSaslMechanisms []SaslMechanism
 }
 
+func (config SecurityConfig) validate() error {
+	if config.TLSRootCAs != nil && config.TLSRootCAProvider != nil {
+		return makeInvalidArgumentsError("TLSRootCAs and TLSRootCAProvider must be used exclusively")
+	}
+
+	for _, mech := range config.AllowedSaslMechanisms {
+		switch mech {
+		case PlainSaslMechanism, ScramSha1SaslMechanism, ScramSha256SaslMechanism, ScramSha512SaslMechanism:
+		default:
+			return makeInvalidArgumentsError(fmt.Sprintf("unsupported sasl mechanism %q, must be one of %s, %s, %s or %s",
+				mech, PlainSaslMechanism, ScramSha1SaslMechanism, ScramSha256SaslMechanism, ScramSha512SaslMechanism))
+		}
+	}
+
+	return nil
+}
+
 // InternalConfig specifies options for controlling various internal
 // items.
 // Internal: This should never be used and is not supported.
@@ -107,7 +262,21 @@ type ClusterOptions struct {
 	// Transcoder is used for trancoding data used in KV operations.
 	Transcoder Transcoder
 
-	// RetryStrategy is used to automatically retry operations if they fail.
+	// Serializer is used to encode and decode JSON in place of encoding/json. It is used by the default
+	// JSONTranscoder, when Transcoder is not set, and to decode the rows of query and analytics results and the
+	// fields of search rows, when a Deserializer is not specified for the request.
+	// The Serializer is shared by all operations and so must be safe for concurrent use by multiple goroutines.
+	// UNCOMMITTED: This API may change in the future.
+	Serializer JSONSerializer
+
+	// CryptoManager, if set, is used to encrypt and decrypt the struct fields tagged with `encrypted:"<alias>"`
+	// by wrapping Transcoder in a FieldEncryptionTranscoder. Transcoders set on individual operations are not
+	// wrapped.
+	// UNCOMMITTED: This API may change in the future.
+	CryptoManager CryptoManager
+
+	// RetryStrategy is used to automatically retry operations if they fail. It is the default for every operation,
+	// the RetryStrategy set on the options of an individual operation overrides it for that operation.
 	RetryStrategy RetryStrategy
 
 	// Tracer specifies the tracer to use for requests.
@@ -121,15 +290,83 @@ type ClusterOptions struct {
 	// CircuitBreakerConfig specifies options for the circuit breakers.
 	CircuitBreakerConfig CircuitBreakerConfig
 
+	// ServiceCircuitBreakerConfig enables circuit breakers for the query, analytics, search and views services,
+	// keyed by service. By default only KV requests pass through circuit breakers, which are configured by
+	// CircuitBreakerConfig. Requests to a service whose circuit breaker is open fail with a *CircuitBreakerOpenError
+	// rather than being dispatched. Only errors returned when the request is dispatched count towards the failure
+	// count, errors which occur whilst streaming rows do not. CanaryTimeout is not used, the first request after the
+	// sleep window has passed is dispatched as the canary.
+	// UNCOMMITTED: This API may change in the future.
+	ServiceCircuitBreakerConfig map[ServiceType]CircuitBreakerConfig
+
+	// ConfigPollerConfig specifies options for how often the cluster configuration is fetched.
+	// UNCOMMITTED: This API may change in the future.
+	ConfigPollerConfig ConfigPollerConfig
+
 	// IoConfig specifies IO related configuration options.
 	IoConfig IoConfig
 
+	// CompressionConfig specifies options for compressing document values.
+	// UNCOMMITTED: This API may change in the future.
+	CompressionConfig CompressionConfig
+
 	// SecurityConfig specifies security related configuration options.
 	SecurityConfig SecurityConfig
 
 	// TransactionsConfig specifies transactions related configuration options.
 	TransactionsConfig TransactionsConfig
 
+	// TopologyConfig specifies options for being notified of cluster topology changes.
+	// VOLATILE: This API is subject to change at any time.
+	TopologyConfig TopologyConfig
+
+	// DNSConfig specifies options for resolving the hostnames within the connection string.
+	// UNCOMMITTED: This API may change in the future.
+	DNSConfig DNSConfig
+
+	// ConnectConfig specifies options for whether Connect waits for the cluster to be reachable before returning.
+	// UNCOMMITTED: This API may change in the future.
+	ConnectConfig ConnectConfig
+
+	// CapabilityConfig specifies options for being notified of changes to the capabilities of open buckets.
+	// VOLATILE: This API is subject to change at any time.
+	CapabilityConfig CapabilityConfig
+
+	// ResultMemoryConfig specifies options for limiting the memory used by the rows of query and analytics results.
+	// UNCOMMITTED: This API may change in the future.
+	ResultMemoryConfig ResultMemoryConfig
+
+	// BootstrapBucket is the name of a bucket to open as part of Connect. Cluster level operations, such as
+	// Cluster.Query, locate the services of the cluster using the global cluster configuration. Clusters prior to
+	// Couchbase Server 6.5, and clusters where the user does not have access to the global configuration, do not
+	// provide it, in which case a bucket must be opened before cluster level operations can be performed. Setting
+	// BootstrapBucket ensures that the configuration of the bucket is available as soon as Connect returns, and
+	// Connect returns an error if the bucket cannot be opened. This is equivalent to calling Cluster.Bucket
+	// immediately after Connect.
+	// UNCOMMITTED: This API may change in the future.
+	BootstrapBucket string
+
+	// PreparedStatementCacheSize is the maximum number of prepared statements cached for queries executed with
+	// QueryOptions.Adhoc set to false, defaults to 5000. See Cluster.PreparedStatementCache.
+	// UNCOMMITTED: This API may change in the future.
+	PreparedStatementCacheSize int
+
+	// PreferredServerGroup is the name of the server group which is local to the application, used by reads with
+	// a ReadPreference of ReadPreferenceSelectedServerGroup, e.g. GetOptions.ReadPreference.
+	// UNCOMMITTED: This API may change in the future.
+	PreferredServerGroup string
+
+	// ObserveDurabilityFallback causes mutations which specify a DurabilityLevel against a bucket which does not
+	// support synchronous durability, such as a bucket on a server older than Couchbase Server 6.5, to be sent without
+	// a durability level and to instead poll the active and replica copies until the equivalent PersistTo and
+	// ReplicateTo requirements are met. A majority is more than half of the active and replica copies. The polling is
+	// bounded by the timeout of the mutation, if it is not met in time then ErrDurabilityAmbiguous is returned as the
+	// mutation has been applied but its durability is not known. Until it is known whether the bucket supports
+	// synchronous durability a mutation is sent with its durability level, and falls back if the server rejects it.
+	// Mutation tokens must be enabled.
+	// UNCOMMITTED: This API may change in the future.
+	ObserveDurabilityFallback bool
+
 	// Internal: This should never be used and is not supported.
 	InternalConfig InternalConfig
 }
@@ -180,7 +417,10 @@ func clusterFromOptions(opts ClusterOptions) *Cluster {
 		managementTimeout = opts.TimeoutsConfig.ManagementTimeout
 	}
 	if opts.Transcoder == nil {
-		opts.Transcoder = NewJSONTranscoder()
+		opts.Transcoder = NewJSONTranscoderWithSerializer(opts.Serializer)
+	}
+	if opts.CryptoManager != nil {
+		opts.Transcoder = NewCryptoManagerTranscoder(opts.CryptoManager, opts.Transcoder)
 	}
 	if opts.RetryStrategy == nil {
 		opts.RetryStrategy = NewBestEffortRetryStrategy(nil)
@@ -222,7 +462,13 @@ func clusterFromOptions(opts ClusterOptions) *Cluster {
 			ManagementTimeout: managementTimeout,
 		},
 		transcoder:             opts.Transcoder,
+		deserializer:           newSerializerDeserializer(opts.Serializer),
 		useMutationTokens:      useMutationTokens,
+		numKVConnections:       opts.IoConfig.NumKVConnections,
+		kvWarmupTimeout:        opts.IoConfig.WarmupTimeout,
+		pinKVToBootstrapHosts:  opts.IoConfig.PinKVToBootstrapHosts,
+		preferredServerGroup:   opts.PreferredServerGroup,
+		durabilityFallback:     opts.ObserveDurabilityFallback,
 		retryStrategyWrapper:   newRetryStrategyWrapper(opts.RetryStrategy),
 		orphanLoggerEnabled:    !opts.OrphanReporterConfig.Disabled,
 		orphanLoggerInterval:   opts.OrphanReporterConfig.ReportInterval,
@@ -230,33 +476,61 @@ func clusterFromOptions(opts ClusterOptions) *Cluster {
 		useServerDurations:     useServerDurations,
 		tracer:                 initialTracer,
 		meter:                  newMeterWrapper(meter),
+		searchCapabilities:     &searchCapabilities{},
+		preparedStatementCache: newPreparedStatementCache(opts.PreparedStatementCacheSize),
+		resultMemoryLimiter:    newResultMemoryLimiter(opts.ResultMemoryConfig),
+		observeBatcher:         newObserveBatcher(),
+		activeQueries:          newActiveQueryTracker(),
 		circuitBreakerConfig:   opts.CircuitBreakerConfig,
+		circuitBreakers:        newCircuitBreakers(opts.ServiceCircuitBreakerConfig),
+		configPollerConfig:     opts.ConfigPollerConfig,
+		compressionConfig:      opts.CompressionConfig,
 		securityConfig:         opts.SecurityConfig,
 		internalConfig:         opts.InternalConfig,
 		transactionsConfig:     opts.TransactionsConfig,
+		topologyConfig:         opts.TopologyConfig,
+		dnsConfig:              opts.DNSConfig,
+		capabilityConfig:       opts.CapabilityConfig,
+		connectConfig:          opts.ConnectConfig,
 	}
 }
 
 // Connect creates and returns a Cluster instance created using the
 // provided options and a connection string.
 func Connect(connStr string, opts ClusterOptions) (*Cluster, error) {
-	connSpec, err := gocbconnstr.Parse(connStr)
+	connSpec, err := parseConnSpec(connStr)
 	if err != nil {
 		return nil, err
 	}
 
-	if connSpec.Scheme == "http" {
-		return nil, errors.New("http scheme is not supported, use couchbase or couchbases instead")
+	cluster := clusterFromOptions(opts)
+
+	err = cluster.parseExtraConnStrOptions(connSpec)
+	if err != nil {
+		return nil, err
 	}
 
-	cluster := clusterFromOptions(opts)
+	connSpec, err = resolveSRVConnSpec(connSpec, cluster.dnsConfig, cluster.timeoutsConfig.ConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
 	cluster.cSpec = connSpec
 
-	err = cluster.parseExtraConnStrOptions(connSpec)
+	err = cluster.transactionsConfig.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.securityConfig.validate()
 	if err != nil {
 		return nil, err
 	}
 
+	if cluster.securityConfig.TLSSkipVerify {
+		logWarnf("TLSSkipVerify is enabled, the certificates presented by the cluster will not be verified. " +
+			"This must never be used in production.")
+	}
+
 	cli := newConnectionMgr()
 	err = cli.buildConfig(cluster)
 	if err != nil {
@@ -269,11 +543,44 @@ func Connect(connStr string, opts ClusterOptions) (*Cluster, error) {
 	}
 	cluster.connectionManager = cli
 
+	if opts.BootstrapBucket != "" {
+		err = cli.openBucket(opts.BootstrapBucket)
+		if err != nil {
+			closeErr := cli.close()
+			if closeErr != nil {
+				logWarnf("Failed to close cluster connection after failing to open bootstrap bucket: %v", closeErr)
+			}
+			return nil, wrapError(err, "failed to open bootstrap bucket "+opts.BootstrapBucket)
+		}
+		cluster.setBucketOpened()
+	}
+
+	if cluster.connectConfig.WaitUntilConnected {
+		err = cluster.waitUntilConnected(opts.BootstrapBucket)
+		if err != nil {
+			closeErr := cli.close()
+			if closeErr != nil {
+				logWarnf("Failed to close cluster connection after failing to connect: %v", closeErr)
+			}
+			return nil, err
+		}
+	}
+
 	cluster.transactions, err = cluster.initTransactions(cluster.transactionsConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	if cluster.topologyConfig.ChangeListener != nil {
+		cluster.topologyWatcher = newTopologyWatcher(cluster, cluster.topologyConfig)
+		cluster.topologyWatcher.start()
+	}
+
+	if cluster.topologyConfig.ConfigChangeListener != nil {
+		cluster.configWatcher = newConfigWatcher(cluster, cluster.topologyConfig)
+		cluster.configWatcher.start()
+	}
+
 	return cluster, nil
 }
 
@@ -318,6 +625,14 @@ func (c *Cluster) parseExtraConnStrOptions(spec gocbconnstr.ConnSpec) error {
 		c.timeoutsConfig.ViewTimeout = time.Duration(val) * time.Millisecond
 	}
 
+	if valStr, ok := fetchOption("kv_pin_to_bootstrap_hosts"); ok {
+		val, err := strconv.ParseBool(valStr)
+		if err != nil {
+			return fmt.Errorf("kv_pin_to_bootstrap_hosts option must be a boolean")
+		}
+		c.pinKVToBootstrapHosts = val
+	}
+
 	return nil
 }
 
@@ -327,12 +642,51 @@ func (c *Cluster) Bucket(bucketName string) *Bucket {
 	err := c.connectionManager.openBucket(bucketName)
 	if err != nil {
 		b.setBootstrapError(err)
+	} else {
+		c.setBucketOpened()
+		c.startCapabilityWatcher(b)
 	}
 
 	return b
 }
 
+func (c *Cluster) setBucketOpened() {
+	atomic.StoreUint32(&c.bucketOpened, 1)
+}
+
+// maybeEnhanceNoBucketErr adds a hint to the error of a cluster level request which timed out without ever being
+// dispatched whilst no bucket has been opened, as this usually means that the global cluster configuration is not
+// available and so the services of the cluster cannot be located.
+func (c *Cluster) maybeEnhanceNoBucketErr(err error) error {
+	if atomic.LoadUint32(&c.bucketOpened) == 1 {
+		return err
+	}
+
+	const hint = "no global cluster configuration may be available, " +
+		"open a bucket before performing cluster level operations or set ClusterOptions.BootstrapBucket"
+
+	switch tErr := err.(type) {
+	case *QueryError:
+		if tErr.Endpoint == "" && errors.Is(tErr.InnerError, ErrTimeout) {
+			tErr.InnerError = wrapError(tErr.InnerError, hint)
+		}
+	case *AnalyticsError:
+		if tErr.Endpoint == "" && errors.Is(tErr.InnerError, ErrTimeout) {
+			tErr.InnerError = wrapError(tErr.InnerError, hint)
+		}
+	case *SearchError:
+		if tErr.Endpoint == "" && errors.Is(tErr.InnerError, ErrTimeout) {
+			tErr.InnerError = wrapError(tErr.InnerError, hint)
+		}
+	}
+
+	return err
+}
+
 func (c *Cluster) authenticator() Authenticator {
+	c.authLock.Lock()
+	defer c.authLock.Unlock()
+
 	return c.auth
 }
 
@@ -351,6 +705,140 @@ type WaitUntilReadyOptions struct {
 
 	// VOLATILE: This API is subject to change at any time.
 	RetryStrategy RetryStrategy
+
+	// PollBackoff calculates how long to wait before checking again whether the cluster is ready, given the number
+	// of checks which have been retried so far. This can be used to reduce the load placed on the cluster when many
+	// clients start at once. The retry strategy still decides whether another check is made at all, and the timeout
+	// still applies to the overall wait. If nil, or if it returns zero, then the backoff of the retry strategy is used.
+	// UNCOMMITTED: This API may change in the future.
+	PollBackoff BackoffCalculator
+
+	// WarmKVConnections causes WaitUntilReady to also wait for every connection within the KV connection pool of
+	// each node to be established, see IoConfig.NumKVConnections, rather than returning once a single connection to
+	// each node is ready. This avoids the first requests sent after a cold start paying the cost of establishing
+	// connections. The time spent waiting is limited by IoConfig.WarmupTimeout.
+	// UNCOMMITTED: This API may change in the future.
+	WarmKVConnections bool
+}
+
+// pollBackoffRetryStrategy overrides the backoff of a retry strategy whilst leaving the decision of whether to retry
+// with the strategy.
+type pollBackoffRetryStrategy struct {
+	wrapped RetryStrategy
+	backoff BackoffCalculator
+}
+
+func (rs *pollBackoffRetryStrategy) RetryAfter(req RetryRequest, reason RetryReason) RetryAction {
+	action := rs.wrapped.RetryAfter(req, reason)
+	if action == nil || action.Duration() == 0 {
+		return action
+	}
+
+	backoff := rs.backoff(req.RetryAttempts())
+	if backoff <= 0 {
+		// A zero duration would stop the retries, so fall back to the backoff of the strategy.
+		return action
+	}
+
+	return &WithDurationRetryAction{WithDuration: backoff}
+}
+
+func waitUntilReadyRetryStrategy(wrapper *retryStrategyWrapper, opts *WaitUntilReadyOptions) *retryStrategyWrapper {
+	if opts.RetryStrategy != nil {
+		wrapper = newRetryStrategyWrapper(opts.RetryStrategy)
+	}
+
+	if opts.PollBackoff != nil {
+		wrapper = newRetryStrategyWrapper(&pollBackoffRetryStrategy{
+			wrapped: wrapper.wrapped,
+			backoff: opts.PollBackoff,
+		})
+	}
+
+	return wrapper
+}
+
+// waitUntilReadyError adds the state of each of the services to a timeout returned by WaitUntilReady, so that the
+// services which were not ready can be identified.
+func waitUntilReadyError(err error, services []ServiceType, desiredState ClusterState, provider diagnosticsProvider,
+	tracer RequestTracer, timeouts TimeoutsConfig, reportTime time.Duration) error {
+	err = maybeEnhanceCoreErr(err)
+	if !errors.Is(err, ErrTimeout) {
+		return err
+	}
+
+	readyErr := &WaitUntilReadyError{
+		InnerError:   err,
+		DesiredState: desiredState,
+	}
+
+	if reportTime <= 0 {
+		return readyErr
+	}
+
+	span := createSpan(tracer, nil, "ping", "kv")
+	defer span.End()
+
+	result, pingErr := ping(nil, provider, &PingOptions{
+		ServiceTypes: services,
+		Timeout:      reportTime,
+	}, timeouts, span)
+	if pingErr != nil {
+		logDebugf("Failed to ping services after wait until ready failed: %v", pingErr)
+		return readyErr
+	}
+
+	readyErr.Services = result.Services
+	return readyErr
+}
+
+// waitForKVConnectionPool waits for every KV connection reported by the provider to be connected, polling until the
+// deadline, or the warmup timeout, is reached.
+func waitForKVConnectionPool(ctx context.Context, deadline time.Time, warmupTimeout time.Duration,
+	provider diagnosticsProvider) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if warmupTimeout > 0 {
+		warmupDeadline := time.Now().Add(warmupTimeout)
+		if warmupDeadline.Before(deadline) {
+			deadline = warmupDeadline
+		}
+	}
+
+	for {
+		info, err := provider.Diagnostics(gocbcore.DiagnosticsOptions{})
+		if err != nil {
+			return maybeEnhanceCoreErr(err)
+		}
+
+		var connected int
+		for _, conn := range info.MemdConns {
+			if conn.State == gocbcore.EndpointStateConnected {
+				connected++
+			}
+		}
+
+		if len(info.MemdConns) > 0 && connected == len(info.MemdConns) {
+			return nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return wrapError(ErrUnambiguousTimeout, fmt.Sprintf("timed out waiting for KV connections to be "+
+				"established, %d of %d connections are connected", connected, len(info.MemdConns)))
+		}
+		if wait > kvWarmupPollInterval {
+			wait = kvWarmupPollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
 }
 
 // WaitUntilReady will wait for the cluster object to be ready for use.
@@ -360,6 +848,13 @@ type WaitUntilReadyOptions struct {
 // If no services are specified then ServiceTypeManagement, ServiceTypeQuery, ServiceTypeSearch, ServiceTypeAnalytics
 // will be pinged.
 // Valid service types are: ServiceTypeManagement, ServiceTypeQuery, ServiceTypeSearch, ServiceTypeAnalytics.
+// If the cluster does not provide a global cluster configuration, see ClusterOptions.BootstrapBucket, then this
+// may not complete until a bucket has been opened.
+// A DesiredState of ClusterStateDegraded returns once at least one endpoint of each service is available, rather
+// than waiting for all of them.
+// If the timeout is reached then a *WaitUntilReadyError is returned, reporting the state of each service. The services
+// are pinged once more in order to build the report, which uses up to the last tenth of the timeout, capped at 2
+// seconds.
 func (c *Cluster) WaitUntilReady(timeout time.Duration, opts *WaitUntilReadyOptions) error {
 	if opts == nil {
 		opts = &WaitUntilReadyOptions{}
@@ -385,14 +880,13 @@ func (c *Cluster) WaitUntilReady(timeout time.Duration, opts *WaitUntilReadyOpti
 		gocbcoreServices[i] = gocbcore.ServiceType(svc)
 	}
 
-	wrapper := c.retryStrategyWrapper
-	if opts.RetryStrategy != nil {
-		wrapper = newRetryStrategyWrapper(opts.RetryStrategy)
-	}
+	wrapper := waitUntilReadyRetryStrategy(c.retryStrategyWrapper, opts)
 
+	deadline := time.Now().Add(timeout)
+	reportTime := waitUntilReadyReportTime(timeout)
 	err = provider.WaitUntilReady(
 		opts.Context,
-		time.Now().Add(timeout),
+		deadline.Add(-reportTime),
 		gocbcore.WaitUntilReadyOptions{
 			DesiredState:  gocbcore.ClusterState(desiredState),
 			ServiceTypes:  gocbcoreServices,
@@ -400,7 +894,22 @@ func (c *Cluster) WaitUntilReady(timeout time.Duration, opts *WaitUntilReadyOpti
 		},
 	)
 	if err != nil {
-		return maybeEnhanceCoreErr(err)
+		diagProvider, diagErr := c.getDiagnosticsProvider()
+		if diagErr != nil {
+			return maybeEnhanceCoreErr(err)
+		}
+
+		return waitUntilReadyError(err, opts.ServiceTypes, desiredState, diagProvider, c.tracer, c.timeoutsConfig,
+			reportTime)
+	}
+
+	if opts.WarmKVConnections {
+		diagProvider, err := c.getDiagnosticsProvider()
+		if err != nil {
+			return err
+		}
+
+		return waitForKVConnectionPool(opts.Context, deadline, c.kvWarmupTimeout, diagProvider)
 	}
 
 	return nil
@@ -419,6 +928,18 @@ func (c *Cluster) Close(opts *ClusterCloseOptions) error {
 		c.transactions = nil
 	}
 
+	if c.topologyWatcher != nil {
+		c.topologyWatcher.stop()
+		c.topologyWatcher = nil
+	}
+
+	if c.configWatcher != nil {
+		c.configWatcher.stop()
+		c.configWatcher = nil
+	}
+
+	c.stopCapabilityWatchers()
+
 	if c.connectionManager != nil {
 		err := c.connectionManager.close()
 		if err != nil {
@@ -456,7 +977,7 @@ func (c *Cluster) getQueryProvider() (queryProvider, error) {
 		return nil, err
 	}
 
-	return provider, nil
+	return c.circuitBreakers.wrapQueryProvider(provider), nil
 }
 
 func (c *Cluster) getAnalyticsProvider() (analyticsProvider, error) {
@@ -465,7 +986,7 @@ func (c *Cluster) getAnalyticsProvider() (analyticsProvider, error) {
 		return nil, err
 	}
 
-	return provider, nil
+	return c.circuitBreakers.wrapAnalyticsProvider(provider), nil
 }
 
 func (c *Cluster) getSearchProvider() (searchProvider, error) {
@@ -474,7 +995,7 @@ func (c *Cluster) getSearchProvider() (searchProvider, error) {
 		return nil, err
 	}
 
-	return provider, nil
+	return c.circuitBreakers.wrapSearchProvider(provider), nil
 }
 
 func (c *Cluster) getHTTPProvider() (httpProvider, error) {
@@ -519,6 +1040,7 @@ func (c *Cluster) AnalyticsIndexes() *AnalyticsIndexManager {
 func (c *Cluster) QueryIndexes() *QueryIndexManager {
 	return &QueryIndexManager{
 		provider:      c,
+		mgmtProvider:  c,
 		globalTimeout: c.timeoutsConfig.ManagementTimeout,
 		tracer:        c.tracer,
 		meter:         c.meter,
@@ -538,9 +1060,34 @@ func (c *Cluster) SearchIndexes() *SearchIndexManager {
 // Volatile: This API is subject to change at any time.
 func (c *Cluster) EventingFunctions() *EventingFunctionManager {
 	return &EventingFunctionManager{
-		mgmtProvider: c,
-		tracer:       c.tracer,
-		meter:        c.meter,
+		mgmtProvider:  c,
+		globalTimeout: c.timeoutsConfig.ManagementTimeout,
+		tracer:        c.tracer,
+		meter:         c.meter,
+	}
+}
+
+// RemoteClusters returns a RemoteClusterManager for managing XDCR remote cluster references.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) RemoteClusters() *RemoteClusterManager {
+	return &RemoteClusterManager{
+		xdcrManager: xdcrManager{
+			mgmtProvider: c,
+			tracer:       c.tracer,
+			meter:        c.meter,
+		},
+	}
+}
+
+// Replications returns a ReplicationManager for managing XDCR replications.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) Replications() *ReplicationManager {
+	return &ReplicationManager{
+		xdcrManager: xdcrManager{
+			mgmtProvider: c,
+			tracer:       c.tracer,
+			meter:        c.meter,
+		},
 	}
 }
 
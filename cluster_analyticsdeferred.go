@@ -0,0 +1,366 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnalyticsDeferredStatus is the status of a deferred analytics query.
+// UNCOMMITTED: This API may change in the future.
+type AnalyticsDeferredStatus string
+
+const (
+	// AnalyticsDeferredStatusRunning indicates that the query has been accepted but has not yet completed.
+	AnalyticsDeferredStatusRunning AnalyticsDeferredStatus = "running"
+
+	// AnalyticsDeferredStatusSuccess indicates that the query has completed and its results can be fetched.
+	AnalyticsDeferredStatusSuccess AnalyticsDeferredStatus = "success"
+
+	// AnalyticsDeferredStatusFailed indicates that the query failed.
+	AnalyticsDeferredStatusFailed AnalyticsDeferredStatus = "failed"
+)
+
+// AnalyticsDeferredOptions is the set of options available when polling or fetching the results of a deferred
+// analytics query.
+// UNCOMMITTED: This API may change in the future.
+type AnalyticsDeferredOptions struct {
+	// Deserializer is used to decode the rows returned by Row and One, defaults to JSONDeserializer.
+	Deserializer Deserializer
+
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+type analyticsDeferredConfig struct {
+	provider      mgmtProvider
+	tracer        RequestTracer
+	meter         *meterWrapper
+	timeout       time.Duration
+	memoryLimiter *resultMemoryLimiter
+	deserializer  Deserializer
+}
+
+type jsonAnalyticsErrorDesc struct {
+	Code    uint32 `json:"code"`
+	Message string `json:"msg"`
+}
+
+type jsonAnalyticsDeferredStatus struct {
+	Status string                   `json:"status"`
+	Handle string                   `json:"handle"`
+	Errors []jsonAnalyticsErrorDesc `json:"errors"`
+}
+
+// AnalyticsDeferredResultHandle is a handle to an analytics query which was run with AnalyticsOptions.Deferred. It
+// can be used to poll the status of the query and to stream its rows once it has completed.
+// The handle is immutable and so is safe to pass between, and use concurrently from, multiple goroutines.
+// UNCOMMITTED: This API may change in the future.
+type AnalyticsDeferredResultHandle struct {
+	uri             string
+	clientContextID string
+	config          analyticsDeferredConfig
+}
+
+// DeferredHandle returns a handle which can be used to poll the status of, and fetch the rows for, a query which
+// was run with AnalyticsOptions.Deferred. The results are closed by calling DeferredHandle.
+// UNCOMMITTED: This API may change in the future.
+func (r *AnalyticsResult) DeferredHandle() (*AnalyticsDeferredResultHandle, error) {
+	if r.reader == nil {
+		return nil, r.Err()
+	}
+
+	for r.Next() {
+		// A deferred query returns no rows, but skip any we are given so that the meta-data can be read.
+	}
+
+	if err := r.Close(); err != nil {
+		return nil, err
+	}
+
+	metaDataBytes, err := r.reader.MetaData()
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp jsonAnalyticsResponse
+	err = json.Unmarshal(metaDataBytes, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+
+	if jsonResp.Handle == "" || r.deferred == nil {
+		return nil, makeInvalidArgumentsError("results do not belong to a deferred query")
+	}
+
+	return &AnalyticsDeferredResultHandle{
+		uri:             jsonResp.Handle,
+		clientContextID: jsonResp.ClientContextID,
+		config:          *r.deferred,
+	}, nil
+}
+
+// URI returns the handle URI which the analytics service assigned to the query, which is useful for debugging.
+func (h *AnalyticsDeferredResultHandle) URI() string {
+	return h.uri
+}
+
+// Status fetches the current status of the query. If the query failed then AnalyticsDeferredStatusFailed is returned
+// alongside an *AnalyticsError describing the failure.
+func (h *AnalyticsDeferredResultHandle) Status(opts *AnalyticsDeferredOptions) (AnalyticsDeferredStatus, error) {
+	if opts == nil {
+		opts = &AnalyticsDeferredOptions{}
+	}
+
+	start := time.Now()
+	defer h.config.meter.ValueRecord(meterValueServiceAnalytics, "analytics_deferred_status", start)
+
+	span := createSpan(h.config.tracer, opts.ParentSpan, "analytics_deferred_status", "analytics")
+	defer span.End()
+
+	status, _, _, err := h.fetchStatus(span, opts)
+	return status, err
+}
+
+// Results fetches the rows of the query, returning ErrAnalyticsResultNotReady if the query has not yet completed.
+// The rows are streamed from the server in the same way as the rows of any other query.
+// Meta-data is not available for the results of a deferred query.
+func (h *AnalyticsDeferredResultHandle) Results(opts *AnalyticsDeferredOptions) (*AnalyticsResult, error) {
+	if opts == nil {
+		opts = &AnalyticsDeferredOptions{}
+	}
+
+	start := time.Now()
+	defer h.config.meter.ValueRecord(meterValueServiceAnalytics, "analytics_deferred_results", start)
+
+	span := createSpan(h.config.tracer, opts.ParentSpan, "analytics_deferred_results", "analytics")
+	defer span.End()
+
+	status, resultURI, endpoint, err := h.fetchStatus(span, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != AnalyticsDeferredStatusSuccess || resultURI == "" {
+		return nil, h.makeError(ErrAnalyticsResultNotReady, endpoint, 0, nil, "")
+	}
+
+	resp, err := h.get(span, resultURI, endpoint, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		defer ensureBodyClosed(resp.Body)
+		return nil, h.makeResponseError(resp)
+	}
+
+	res := newAnalyticsResult(newAnalyticsDeferredRowReader(resp.Body))
+	res.memory.limiter = h.config.memoryLimiter
+	res.memory.ctx = opts.Context
+	res.deserializer = resultDeserializer(opts.Deserializer, h.config.deserializer)
+
+	return res, nil
+}
+
+func (h *AnalyticsDeferredResultHandle) fetchStatus(span RequestSpan,
+	opts *AnalyticsDeferredOptions) (AnalyticsDeferredStatus, string, string, error) {
+	resp, err := h.get(span, h.uri, "", opts)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return "", "", "", h.makeResponseError(resp)
+	}
+
+	var jsonStatus jsonAnalyticsDeferredStatus
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&jsonStatus)
+	if err != nil {
+		return "", "", "", h.makeError(wrapError(err, "failed to decode deferred status"), resp.Endpoint,
+			int(resp.StatusCode), nil, "")
+	}
+
+	status := AnalyticsDeferredStatus(jsonStatus.Status)
+	if status == AnalyticsDeferredStatusFailed {
+		return status, "", resp.Endpoint, h.makeError(nil, resp.Endpoint, int(resp.StatusCode), jsonStatus.Errors, "")
+	}
+
+	return status, jsonStatus.Handle, resp.Endpoint, nil
+}
+
+// get sends a GET request for uri, which is either a path or an absolute URL as returned by the analytics service.
+// Handles are local to the node which is running the query, so an absolute URL or endpoint pins the request to it.
+func (h *AnalyticsDeferredResultHandle) get(span RequestSpan, uri, endpoint string,
+	opts *AnalyticsDeferredOptions) (*mgmtResponse, error) {
+	path := uri
+	if parsed, err := url.Parse(uri); err == nil && parsed.Host != "" {
+		endpoint = parsed.Scheme + "://" + parsed.Host
+		path = parsed.RequestURI()
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, makeInvalidArgumentsError("invalid deferred query handle: " + uri)
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = h.config.timeout
+	}
+
+	req := mgmtRequest{
+		Service:       ServiceTypeAnalytics,
+		Endpoint:      endpoint,
+		Method:        "GET",
+		Path:          path,
+		IsIdempotent:  true,
+		UniqueID:      uuid.New().String(),
+		Timeout:       timeout,
+		RetryStrategy: opts.RetryStrategy,
+		parentSpanCtx: span.Context(),
+	}
+
+	resp, err := h.config.provider.executeMgmtRequest(opts.Context, req)
+	if err != nil {
+		return nil, h.makeError(err, endpoint, 0, nil, "")
+	}
+
+	return resp, nil
+}
+
+func (h *AnalyticsDeferredResultHandle) makeResponseError(resp *mgmtResponse) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logDebugf("Failed to read deferred analytics response body: %s", err)
+	}
+
+	var descs []jsonAnalyticsErrorDesc
+	var jsonStatus jsonAnalyticsDeferredStatus
+	if json.Unmarshal(b, &jsonStatus) == nil {
+		descs = jsonStatus.Errors
+	}
+
+	var innerErr error
+	if len(descs) == 0 {
+		switch {
+		case resp.StatusCode == 404:
+			innerErr = errors.New("deferred query handle not found")
+		case resp.StatusCode >= 500:
+			innerErr = ErrInternalServerFailure
+		default:
+			innerErr = errors.New("unexpected response from analytics service")
+		}
+	}
+
+	return h.makeError(innerErr, resp.Endpoint, int(resp.StatusCode), descs, string(b))
+}
+
+// makeError returns an *AnalyticsError for the handle, if err is nil then the inner error is derived from descs.
+func (h *AnalyticsDeferredResultHandle) makeError(err error, endpoint string, statusCode int,
+	descs []jsonAnalyticsErrorDesc, errText string) error {
+	var errDescs []AnalyticsErrorDesc
+	for _, desc := range descs {
+		errDescs = append(errDescs, AnalyticsErrorDesc{
+			Code:    desc.Code,
+			Message: desc.Message,
+		})
+	}
+	if err == nil {
+		err = analyticsErrorFromDescs(errDescs)
+	}
+
+	return &AnalyticsError{
+		InnerError:      err,
+		ClientContextID: h.clientContextID,
+		Errors:          errDescs,
+		Endpoint:        endpoint,
+		ErrorText:       errText,
+		HTTPStatusCode:  statusCode,
+	}
+}
+
+// analyticsDeferredRowReader streams the rows of a deferred query, which are returned as a single JSON array.
+type analyticsDeferredRowReader struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+	started bool
+	done    bool
+	err     error
+}
+
+func newAnalyticsDeferredRowReader(body io.ReadCloser) *analyticsDeferredRowReader {
+	return &analyticsDeferredRowReader{
+		body:    body,
+		decoder: json.NewDecoder(body),
+	}
+}
+
+func (r *analyticsDeferredRowReader) NextRow() []byte {
+	if r.done {
+		return nil
+	}
+
+	if !r.started {
+		r.started = true
+		tok, err := r.decoder.Token()
+		if err != nil {
+			r.finish(err)
+			return nil
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			r.finish(errors.New("expected deferred results to be an array"))
+			return nil
+		}
+	}
+
+	if !r.decoder.More() {
+		r.finish(nil)
+		return nil
+	}
+
+	var row json.RawMessage
+	if err := r.decoder.Decode(&row); err != nil {
+		r.finish(err)
+		return nil
+	}
+
+	return row
+}
+
+func (r *analyticsDeferredRowReader) finish(err error) {
+	r.done = true
+	if err != nil {
+		r.err = wrapError(err, "failed to read deferred results")
+	}
+}
+
+func (r *analyticsDeferredRowReader) Err() error {
+	return r.err
+}
+
+func (r *analyticsDeferredRowReader) MetaData() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (r *analyticsDeferredRowReader) Close() error {
+	closeErr := r.body.Close()
+	if r.err != nil {
+		return r.err
+	}
+
+	return closeErr
+}
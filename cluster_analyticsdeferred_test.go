@@ -0,0 +1,167 @@
+package gocb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) deferredHTTPResponse(endpoint, body string) *gocbcore.HTTPResponse {
+	return &gocbcore.HTTPResponse{
+		Endpoint:   endpoint,
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}
+}
+
+func (suite *UnitTestSuite) TestAnalyticsDeferredQuery() {
+	var dataset testAnalyticsDataset
+	err := loadJSONTestDataset("beer_sample_analytics_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	reader := &mockAnalyticsRowReader{
+		Meta:  []byte(`{"requestID":"1","clientContextID":"ctx","status":"running","handle":"/analytics/service/status/1-0"}`),
+		Suite: suite,
+	}
+
+	analyticsProvider := new(mockAnalyticsProvider)
+	analyticsProvider.
+		On("AnalyticsQuery", nil, mock.AnythingOfType("gocbcore.AnalyticsQueryOptions")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(1).(gocbcore.AnalyticsQueryOptions)
+
+			var actualOptions map[string]interface{}
+			err := json.Unmarshal(opts.Payload, &actualOptions)
+			suite.Require().Nil(err)
+
+			suite.Assert().Equal("async", actualOptions["mode"])
+		}).
+		Return(reader, nil)
+
+	isPath := func(path string) interface{} {
+		return mock.MatchedBy(func(req *gocbcore.HTTPRequest) bool {
+			return req.Path == path
+		})
+	}
+
+	httpProvider := new(mockHttpProvider)
+	for i := 0; i < 2; i++ {
+		httpProvider.
+			On("DoHTTPRequest", nil, isPath("/analytics/service/status/1-0")).
+			Return(suite.deferredHTTPResponse("http://10.0.0.1:8095", `{"status":"running"}`), nil).
+			Once()
+	}
+	httpProvider.
+		On("DoHTTPRequest", nil, isPath("/analytics/service/status/1-0")).
+		Return(suite.deferredHTTPResponse("http://10.0.0.1:8095",
+			`{"status":"success","handle":"/analytics/service/result/1-0"}`), nil).
+		Once()
+	httpProvider.
+		On("DoHTTPRequest", nil, isPath("/analytics/service/result/1-0")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(*gocbcore.HTTPRequest)
+
+			suite.Assert().Equal(gocbcore.CbasService, req.Service)
+			suite.Assert().Equal("GET", req.Method)
+			suite.Assert().Equal("http://10.0.0.1:8095", req.Endpoint)
+		}).
+		Return(suite.deferredHTTPResponse("http://10.0.0.1:8095",
+			string(suite.mustConvertToBytes(dataset.Results))), nil).
+		Once()
+
+	cli := new(mockConnectionManager)
+	cli.On("getAnalyticsProvider").Return(analyticsProvider, nil)
+	cli.On("getHTTPProvider", "").Return(httpProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	result, err := cluster.AnalyticsQuery("SELECT * FROM dataset", &AnalyticsOptions{
+		Deferred: true,
+	})
+	suite.Require().Nil(err, err)
+
+	handle, err := result.DeferredHandle()
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("/analytics/service/status/1-0", handle.URI())
+
+	statusCh := make(chan AnalyticsDeferredStatus, 1)
+	go func() {
+		status, err := handle.Status(nil)
+		suite.Assert().Nil(err, err)
+		statusCh <- status
+	}()
+	suite.Assert().Equal(AnalyticsDeferredStatusRunning, <-statusCh)
+
+	_, err = handle.Results(nil)
+	if !errors.Is(err, ErrAnalyticsResultNotReady) {
+		suite.T().Fatalf("Expected error to be analytics result not ready but was %v", err)
+	}
+
+	results, err := handle.Results(nil)
+	suite.Require().Nil(err, err)
+
+	var breweries []testBreweryDocument
+	for results.Next() {
+		var doc testBreweryDocument
+		err := results.Row(&doc)
+		suite.Require().Nil(err, err)
+		breweries = append(breweries, doc)
+	}
+	suite.Require().Nil(results.Err())
+	suite.Require().Nil(results.Close())
+
+	suite.Assert().Equal(dataset.Results, breweries)
+	httpProvider.AssertExpectations(suite.T())
+}
+
+func (suite *UnitTestSuite) TestAnalyticsDeferredQueryFailed() {
+	tests := []struct {
+		code    uint32
+		wantErr error
+	}{
+		{23007, ErrJobQueueFull},
+		{24001, ErrCompilationFailure},
+		{24045, ErrDatasetNotFound},
+	}
+	for _, tt := range tests {
+		httpProvider := new(mockHttpProvider)
+		httpProvider.
+			On("DoHTTPRequest", nil, mock.AnythingOfType("*gocbcore.HTTPRequest")).
+			Run(func(args mock.Arguments) {
+				req := args.Get(1).(*gocbcore.HTTPRequest)
+
+				suite.Assert().Equal("http://10.0.0.2:8095", req.Endpoint)
+				suite.Assert().Equal("/analytics/service/status/2-0", req.Path)
+			}).
+			Return(suite.deferredHTTPResponse("http://10.0.0.2:8095",
+				`{"status":"failed","errors":[{"code":`+string(suite.mustConvertToBytes(tt.code))+`,"msg":"failed"}]}`), nil)
+
+		cli := new(mockConnectionManager)
+		cli.On("getHTTPProvider", "").Return(httpProvider, nil)
+
+		cluster := suite.newCluster(cli)
+
+		handle := &AnalyticsDeferredResultHandle{
+			uri: "http://10.0.0.2:8095/analytics/service/status/2-0",
+			config: analyticsDeferredConfig{
+				provider: cluster,
+				tracer:   cluster.tracer,
+				meter:    cluster.meter,
+			},
+		}
+
+		status, err := handle.Status(nil)
+		suite.Assert().Equal(AnalyticsDeferredStatusFailed, status)
+		if !errors.Is(err, tt.wantErr) {
+			suite.T().Fatalf("Expected error to be %v but was %v", tt.wantErr, err)
+		}
+
+		var analyticsErr *AnalyticsError
+		suite.Require().True(errors.As(err, &analyticsErr))
+		suite.Assert().Equal([]AnalyticsErrorDesc{{Code: tt.code, Message: "failed"}}, analyticsErr.Errors)
+	}
+}
@@ -0,0 +1,139 @@
+package gocb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// AnalyticsPlanOperator represents a single operator within an analytics query plan.
+// UNCOMMITTED: This API may change in the future.
+type AnalyticsPlanOperator struct {
+	// Operator is the logical operator, e.g. "data-scan" or "distribute-result".
+	Operator string `json:"operator"`
+	// OperatorID is the identifier of the operator within the plan.
+	OperatorID string `json:"operatorId"`
+	// PhysicalOperator is the physical implementation chosen for the operator, e.g. "DATASOURCE_SCAN".
+	PhysicalOperator string `json:"physical-operator"`
+	// ExecutionMode is how the operator is executed across the cluster, e.g. "PARTITIONED" or "UNPARTITIONED".
+	ExecutionMode string `json:"execution-mode"`
+	// Inputs are the operators which feed into this operator.
+	Inputs []AnalyticsPlanOperator `json:"inputs"`
+}
+
+// AnalyticsQueryPlan is the plan which the analytics service produced for a statement.
+// UNCOMMITTED: This API may change in the future.
+type AnalyticsQueryPlan struct {
+	// Raw is the plan exactly as it was returned by the analytics service.
+	Raw json.RawMessage
+
+	// Root is the final operator of the plan, from which the rest of the plan can be walked via its Inputs.
+	// Root is only populated when the plan is returned in the JSON plan format, which is the default.
+	Root *AnalyticsPlanOperator
+}
+
+// analyticsNonExplainableKeywords are the statement types which the analytics service cannot explain.
+var analyticsNonExplainableKeywords = map[string]struct{}{
+	"ALTER":      {},
+	"ANALYZE":    {},
+	"CONNECT":    {},
+	"COPY":       {},
+	"CREATE":     {},
+	"DELETE":     {},
+	"DISCONNECT": {},
+	"DROP":       {},
+	"INSERT":     {},
+	"LOAD":       {},
+	"UPSERT":     {},
+}
+
+func analyticsExplainStatement(statement string) (string, error) {
+	trimmed := strings.TrimSpace(statement)
+	fields := strings.Fields(trimmed)
+	if len(fields) == 0 {
+		return "", makeInvalidArgumentsError("statement cannot be empty")
+	}
+
+	keyword := strings.ToUpper(fields[0])
+	if keyword == "EXPLAIN" {
+		return trimmed, nil
+	}
+
+	if _, ok := analyticsNonExplainableKeywords[keyword]; ok {
+		return "", makeInvalidArgumentsError(fmt.Sprintf("%s statements cannot be explained, only queries can", keyword))
+	}
+
+	return "EXPLAIN " + trimmed, nil
+}
+
+func analyticsQueryPlanFromResult(res *AnalyticsResult) (*AnalyticsQueryPlan, error) {
+	var raw json.RawMessage
+	err := res.One(&raw)
+	if err != nil {
+		// An error from the service, such as the statement failing to compile, surfaces on the stream rather than
+		// as a missing row.
+		if streamErr := res.Err(); streamErr != nil {
+			return nil, streamErr
+		}
+		return nil, err
+	}
+
+	plan := &AnalyticsQueryPlan{
+		Raw: raw,
+	}
+
+	var root AnalyticsPlanOperator
+	if err := json.Unmarshal(raw, &root); err != nil {
+		// The plan is in a format other than JSON, e.g. a string when a plan-format of STRING was requested via Raw.
+		logDebugf("Failed to parse analytics query plan: %v", err)
+		return plan, nil
+	}
+
+	if root.Operator != "" {
+		plan.Root = &root
+	}
+
+	return plan, nil
+}
+
+// AnalyticsExplain returns the plan which the analytics service would use to execute the statement, without
+// executing it. Only queries can be explained, attempting to explain a DDL or DML statement returns an error
+// wrapping ErrInvalidArgument. The statement may optionally already begin with EXPLAIN.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) AnalyticsExplain(statement string, opts *AnalyticsOptions) (*AnalyticsQueryPlan, error) {
+	explainStatement, err := analyticsExplainStatement(statement)
+	if err != nil {
+		return nil, AnalyticsError{
+			InnerError: err,
+			Statement:  statement,
+		}
+	}
+
+	res, err := c.AnalyticsQuery(explainStatement, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return analyticsQueryPlanFromResult(res)
+}
+
+// AnalyticsExplain returns the plan which the analytics service would use to execute the statement, constraining the
+// query to the bucket and scope, without executing it. Only queries can be explained, attempting to explain a DDL or
+// DML statement returns an error wrapping ErrInvalidArgument. The statement may optionally already begin with EXPLAIN.
+// UNCOMMITTED: This API may change in the future.
+func (s *Scope) AnalyticsExplain(statement string, opts *AnalyticsOptions) (*AnalyticsQueryPlan, error) {
+	explainStatement, err := analyticsExplainStatement(statement)
+	if err != nil {
+		return nil, AnalyticsError{
+			InnerError: err,
+			Statement:  statement,
+		}
+	}
+
+	res, err := s.AnalyticsQuery(explainStatement, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return analyticsQueryPlanFromResult(res)
+}
@@ -138,7 +138,7 @@ func (am *AnalyticsIndexManager) CreateDataverse(dataverseName string, opts *Cre
 
 	if dataverseName == "" {
 		return invalidArgumentsError{
-			message: "dataset name cannot be empty",
+			message: "dataverse name cannot be empty",
 		}
 	}
 
@@ -163,6 +163,9 @@ func (am *AnalyticsIndexManager) CreateDataverse(dataverseName string, opts *Cre
 		Context:         opts.Context,
 	})
 	if err != nil {
+		if opts.IgnoreIfExists && errors.Is(err, ErrDataverseExists) {
+			return nil
+		}
 		return err
 	}
 
@@ -189,6 +192,12 @@ func (am *AnalyticsIndexManager) DropDataverse(dataverseName string, opts *DropA
 		opts = &DropAnalyticsDataverseOptions{}
 	}
 
+	if dataverseName == "" {
+		return invalidArgumentsError{
+			message: "dataverse name cannot be empty",
+		}
+	}
+
 	start := time.Now()
 	defer am.meter.ValueRecord(meterValueServiceManagement, "manager_analytics_drop_dataverse", start)
 
@@ -209,17 +218,28 @@ func (am *AnalyticsIndexManager) DropDataverse(dataverseName string, opts *DropA
 		Context:       opts.Context,
 	})
 	if err != nil {
+		if opts.IgnoreIfNotExists && errors.Is(err, ErrDataverseNotFound) {
+			return nil
+		}
 		return err
 	}
 
-	return err
+	return nil
 }
 
 // CreateAnalyticsDatasetOptions is the set of options available to the AnalyticsManager CreateDataset operation.
 type CreateAnalyticsDatasetOptions struct {
 	IgnoreIfExists bool
-	Condition      string
-	DataverseName  string
+	// Condition is a WHERE clause used to filter the documents which are included in the dataset, the WHERE keyword
+	// is optional.
+	Condition     string
+	DataverseName string
+
+	// ScopeName and CollectionName bind the dataset to a collection within the bucket, rather than to the default
+	// collection. If only CollectionName is set then the collection is assumed to be within the default scope.
+	// UNCOMMITTED: This API may change in the future.
+	ScopeName      string
+	CollectionName string
 
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
@@ -242,6 +262,16 @@ func (am *AnalyticsIndexManager) CreateDataset(datasetName, bucketName string, o
 			message: "dataset name cannot be empty",
 		}
 	}
+	if bucketName == "" {
+		return invalidArgumentsError{
+			message: "bucket name cannot be empty",
+		}
+	}
+	if opts.ScopeName != "" && opts.CollectionName == "" {
+		return invalidArgumentsError{
+			message: "collection name must be specified alongside scope name",
+		}
+	}
 
 	start := time.Now()
 	defer am.meter.ValueRecord(meterValueServiceManagement, "manager_analytics_create_dataset", start)
@@ -265,7 +295,16 @@ func (am *AnalyticsIndexManager) CreateDataset(datasetName, bucketName string, o
 		datasetName = fmt.Sprintf("%s.`%s`", am.uncompoundName(opts.DataverseName), datasetName)
 	}
 
-	q := fmt.Sprintf("CREATE DATASET %s %s ON `%s` %s", ignoreStr, datasetName, bucketName, where)
+	keyspace := fmt.Sprintf("`%s`", bucketName)
+	if opts.CollectionName != "" {
+		scopeName := opts.ScopeName
+		if scopeName == "" {
+			scopeName = "_default"
+		}
+		keyspace = fmt.Sprintf("%s.`%s`.`%s`", keyspace, scopeName, opts.CollectionName)
+	}
+
+	q := fmt.Sprintf("CREATE DATASET %s %s ON %s %s", ignoreStr, datasetName, keyspace, where)
 
 	span := createSpan(am.tracer, opts.ParentSpan, "manager_analytics_create_dataset", "management")
 	defer span.End()
@@ -277,6 +316,9 @@ func (am *AnalyticsIndexManager) CreateDataset(datasetName, bucketName string, o
 		Context:       opts.Context,
 	})
 	if err != nil {
+		if opts.IgnoreIfExists && errors.Is(err, ErrDatasetExists) {
+			return nil
+		}
 		return err
 	}
 
@@ -304,6 +346,12 @@ func (am *AnalyticsIndexManager) DropDataset(datasetName string, opts *DropAnaly
 		opts = &DropAnalyticsDatasetOptions{}
 	}
 
+	if datasetName == "" {
+		return invalidArgumentsError{
+			message: "dataset name cannot be empty",
+		}
+	}
+
 	start := time.Now()
 	defer am.meter.ValueRecord(meterValueServiceManagement, "manager_analytics_drop_dataset", start)
 
@@ -330,6 +378,9 @@ func (am *AnalyticsIndexManager) DropDataset(datasetName string, opts *DropAnaly
 		Context:       opts.Context,
 	})
 	if err != nil {
+		if opts.IgnoreIfNotExists && errors.Is(err, ErrDatasetNotFound) {
+			return nil
+		}
 		return err
 	}
 
@@ -452,6 +503,9 @@ func (am *AnalyticsIndexManager) CreateIndex(datasetName, indexName string, fiel
 		Context:       opts.Context,
 	})
 	if err != nil {
+		if opts.IgnoreIfExists && errors.Is(err, ErrIndexExists) {
+			return nil
+		}
 		return err
 	}
 
@@ -479,6 +533,12 @@ func (am *AnalyticsIndexManager) DropIndex(datasetName, indexName string, opts *
 		opts = &DropAnalyticsIndexOptions{}
 	}
 
+	if indexName == "" {
+		return invalidArgumentsError{
+			message: "index name cannot be empty",
+		}
+	}
+
 	start := time.Now()
 	defer am.meter.ValueRecord(meterValueServiceManagement, "manager_analytics_drop_index", start)
 
@@ -493,7 +553,7 @@ func (am *AnalyticsIndexManager) DropIndex(datasetName, indexName string, opts *
 		datasetName = fmt.Sprintf("%s.`%s`", am.uncompoundName(opts.DataverseName), datasetName)
 	}
 
-	q := fmt.Sprintf("DROP INDEX %s.%s %s", datasetName, indexName, ignoreStr)
+	q := fmt.Sprintf("DROP INDEX %s.`%s` %s", datasetName, indexName, ignoreStr)
 
 	span := createSpan(am.tracer, opts.ParentSpan, "manager_analytics_drop_index", "management")
 	span.SetAttribute("db.statement", q)
@@ -506,6 +566,9 @@ func (am *AnalyticsIndexManager) DropIndex(datasetName, indexName string, opts *
 		Context:       opts.Context,
 	})
 	if err != nil {
+		if opts.IgnoreIfNotExists && errors.Is(err, ErrIndexNotFound) {
+			return nil
+		}
 		return err
 	}
 
@@ -1044,7 +1107,10 @@ type GetAnalyticsLinksOptions struct {
 	Context context.Context
 }
 
-// GetLinks retrieves all external or remote analytics links.
+// GetLinks retrieves all external or remote analytics links. The links are returned as their concrete types,
+// *CouchbaseRemoteAnalyticsLink, *S3ExternalAnalyticsLink or *AzureBlobExternalAnalyticsLink. Secret credentials,
+// such as passwords and keys, are masked by the server and so are left unset, they must be provided again before
+// passing a link to ReplaceLink.
 func (am *AnalyticsIndexManager) GetLinks(opts *GetAnalyticsLinksOptions) ([]AnalyticsLink, error) {
 	if opts == nil {
 		opts = &GetAnalyticsLinksOptions{}
@@ -3,6 +3,7 @@ package gocb
 import (
 	"errors"
 	"net/url"
+	"testing"
 )
 
 func (suite *IntegrationTestSuite) TestAnalyticsIndexesCrud() {
@@ -880,3 +881,181 @@ func (suite *UnitTestSuite) TestAnalyticsIndexesCouchbaseLinksFormEncode() {
 	suite.Assert().Equal("clientcertificate", q.Get("clientCertificate"))
 	suite.Assert().Equal("clientkey", q.Get("clientKey"))
 }
+
+func (suite *UnitTestSuite) TestAnalyticsIndexesLinksValidate() {
+	type tCase struct {
+		name  string
+		link  AnalyticsLink
+		valid bool
+	}
+
+	testCases := []tCase{
+		{
+			name: "couchbase with username and password",
+			link: NewCouchbaseRemoteAnalyticsLink("link", "host", "scope", &NewCouchbaseRemoteAnalyticsLinkOptions{
+				Username: "username",
+				Password: "password",
+			}),
+			valid: true,
+		},
+		{
+			name:  "couchbase without credentials",
+			link:  NewCouchbaseRemoteAnalyticsLink("link", "host", "scope", nil),
+			valid: false,
+		},
+		{
+			name: "couchbase with username only",
+			link: NewCouchbaseRemoteAnalyticsLink("link", "host", "scope", &NewCouchbaseRemoteAnalyticsLinkOptions{
+				Username: "username",
+			}),
+			valid: false,
+		},
+		{
+			name: "couchbase with client certificate",
+			link: NewCouchbaseRemoteAnalyticsLink("link", "host", "scope", &NewCouchbaseRemoteAnalyticsLinkOptions{
+				Encryption: CouchbaseRemoteAnalyticsEncryptionSettings{
+					EncryptionLevel:   AnalyticsEncryptionLevelFull,
+					Certificate:       []byte("certificate"),
+					ClientCertificate: []byte("clientcertificate"),
+					ClientKey:         []byte("clientkey"),
+				},
+			}),
+			valid: true,
+		},
+		{
+			name: "couchbase with client certificate and password",
+			link: NewCouchbaseRemoteAnalyticsLink("link", "host", "scope", &NewCouchbaseRemoteAnalyticsLinkOptions{
+				Username: "username",
+				Password: "password",
+				Encryption: CouchbaseRemoteAnalyticsEncryptionSettings{
+					EncryptionLevel:   AnalyticsEncryptionLevelFull,
+					Certificate:       []byte("certificate"),
+					ClientCertificate: []byte("clientcertificate"),
+					ClientKey:         []byte("clientkey"),
+				},
+			}),
+			valid: false,
+		},
+		{
+			name:  "s3",
+			link:  NewS3ExternalAnalyticsLink("link", "scope", "accesskey", "secretkey", "us-east-1", nil),
+			valid: true,
+		},
+		{
+			name:  "s3 without region",
+			link:  NewS3ExternalAnalyticsLink("link", "scope", "accesskey", "secretkey", "", nil),
+			valid: false,
+		},
+		{
+			name: "azure with connection string",
+			link: NewAzureBlobExternalAnalyticsLink("link", "scope", &NewAzureBlobExternalAnalyticsLinkOptions{
+				ConnectionString: "connstr",
+			}),
+			valid: true,
+		},
+		{
+			name: "azure with account key",
+			link: NewAzureBlobExternalAnalyticsLink("link", "scope", &NewAzureBlobExternalAnalyticsLinkOptions{
+				AccountName: "account",
+				AccountKey:  "key",
+			}),
+			valid: true,
+		},
+		{
+			name: "azure with account name only",
+			link: NewAzureBlobExternalAnalyticsLink("link", "scope", &NewAzureBlobExternalAnalyticsLinkOptions{
+				AccountName: "account",
+			}),
+			valid: false,
+		},
+		{
+			name:  "azure without credentials",
+			link:  NewAzureBlobExternalAnalyticsLink("link", "scope", nil),
+			valid: false,
+		},
+	}
+
+	for _, tCase := range testCases {
+		suite.T().Run(tCase.name, func(te *testing.T) {
+			err := tCase.link.Validate()
+			if tCase.valid {
+				if err != nil {
+					te.Fatalf("Expected link to be valid but was %v", err)
+				}
+			} else if !errors.Is(err, ErrInvalidArgument) {
+				te.Fatalf("Expected error to be invalid argument but was %v", err)
+			}
+		})
+	}
+}
+
+type testAnalyticsIndexQueryProvider struct {
+	statements []string
+	err        error
+}
+
+func (p *testAnalyticsIndexQueryProvider) AnalyticsQuery(statement string, _ *AnalyticsOptions) (*AnalyticsResult,
+	error) {
+	p.statements = append(p.statements, statement)
+	return nil, p.err
+}
+
+func (suite *UnitTestSuite) TestAnalyticsIndexesIgnoreErrors() {
+	provider := &testAnalyticsIndexQueryProvider{}
+	mgr := &AnalyticsIndexManager{
+		aProvider: provider,
+		tracer:    &NoopTracer{},
+		meter:     &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	provider.err = ErrDataverseExists
+	err := mgr.CreateDataverse("a/b", &CreateAnalyticsDataverseOptions{IgnoreIfExists: true})
+	suite.Require().Nil(err, err)
+	err = mgr.CreateDataverse("a/b", nil)
+	if !errors.Is(err, ErrDataverseExists) {
+		suite.T().Fatalf("Expected error to be dataverse exists but was %v", err)
+	}
+
+	provider.err = ErrDatasetExists
+	err = mgr.CreateDataset("dataset", "bucket", &CreateAnalyticsDatasetOptions{
+		IgnoreIfExists: true,
+		Condition:      "`type` = \"beer\"",
+		DataverseName:  "a/b",
+		CollectionName: "collection",
+	})
+	suite.Require().Nil(err, err)
+
+	provider.err = ErrIndexNotFound
+	err = mgr.DropIndex("dataset", "index", &DropAnalyticsIndexOptions{
+		IgnoreIfNotExists: true,
+		DataverseName:     "a/b",
+	})
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal([]string{
+		"CREATE DATAVERSE `a`.`b` IF NOT EXISTS",
+		"CREATE DATAVERSE `a`.`b` ",
+		"CREATE DATASET IF NOT EXISTS `a`.`b`.`dataset` ON `bucket`.`_default`.`collection` WHERE `type` = \"beer\"",
+		"DROP INDEX `a`.`b`.`dataset`.`index` IF EXISTS",
+	}, provider.statements)
+}
+
+func (suite *UnitTestSuite) TestAnalyticsIndexesInvalidArguments() {
+	provider := &testAnalyticsIndexQueryProvider{}
+	mgr := &AnalyticsIndexManager{
+		aProvider: provider,
+		tracer:    &NoopTracer{},
+		meter:     &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := mgr.DropDataverse("", nil)
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument), err)
+	err = mgr.DropDataset("", nil)
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument), err)
+	err = mgr.DropIndex("dataset", "", nil)
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument), err)
+	err = mgr.CreateDataset("dataset", "bucket", &CreateAnalyticsDatasetOptions{ScopeName: "scope"})
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument), err)
+
+	suite.Assert().Empty(provider.statements)
+}
@@ -33,6 +33,7 @@ type jsonAnalyticsResponse struct {
 	Warnings        []jsonAnalyticsWarning `json:"warnings"`
 	Metrics         jsonAnalyticsMetrics   `json:"metrics"`
 	Signature       interface{}            `json:"signature"`
+	Handle          string                 `json:"handle,omitempty"`
 }
 
 // AnalyticsMetrics encapsulates various metrics gathered during a queries execution.
@@ -158,6 +159,15 @@ type AnalyticsResult struct {
 	reader analyticsRowReader
 
 	rowBytes []byte
+
+	memory    resultMemoryReservation
+	memoryErr error
+
+	deserializer Deserializer
+
+	canceller streamCanceller
+
+	deferred *analyticsDeferredConfig
 }
 
 func newAnalyticsResult(reader analyticsRowReader) *AnalyticsResult {
@@ -187,12 +197,20 @@ func (r *AnalyticsResult) Raw() *AnalyticsResultRaw {
 
 // Next assigns the next result from the results into the value pointer, returning whether the read was successful.
 func (r *AnalyticsResult) Next() bool {
-	if r.reader == nil {
+	if r.reader == nil || r.canceller.isCanceled() {
 		return false
 	}
 
 	rowBytes := r.reader.NextRow()
 	if rowBytes == nil {
+		r.memory.release()
+		return false
+	}
+
+	if err := r.memory.reserve(len(rowBytes)); err != nil {
+		r.memoryErr = err
+		r.rowBytes = nil
+		r.Cancel()
 		return false
 	}
 
@@ -210,12 +228,7 @@ func (r *AnalyticsResult) Row(valuePtr interface{}) error {
 		return ErrNoResult
 	}
 
-	if bytesPtr, ok := valuePtr.(*json.RawMessage); ok {
-		*bytesPtr = r.rowBytes
-		return nil
-	}
-
-	return json.Unmarshal(r.rowBytes, valuePtr)
+	return deserializeRow(r.deserializer, r.rowBytes, valuePtr)
 }
 
 // Err returns any errors that have occurred on the stream
@@ -224,6 +237,14 @@ func (r *AnalyticsResult) Err() error {
 		return errors.New("result object is no longer valid")
 	}
 
+	if r.memoryErr != nil {
+		return r.memoryErr
+	}
+
+	if r.canceller.isCanceled() {
+		return ErrRequestCanceled
+	}
+
 	err := r.reader.Err()
 	if err != nil {
 		return maybeEnhanceAnalyticsError(err)
@@ -232,13 +253,30 @@ func (r *AnalyticsResult) Err() error {
 	return nil
 }
 
+// Cancel aborts the query by closing the underlying stream of results, unblocking any call to Next which is waiting
+// on the server. Once canceled Next returns false and Err returns ErrRequestCanceled.
+// Cancel is safe to call concurrently with iterating the results and may be called more than once.
+// UNCOMMITTED: This API may change in the future.
+func (r *AnalyticsResult) Cancel() {
+	reader := r.reader
+	if reader == nil {
+		return
+	}
+
+	r.memory.release()
+	r.canceller.cancel(reader.Close)
+}
+
 // Close marks the results as closed, returning any errors that occurred during reading the results.
 func (r *AnalyticsResult) Close() error {
 	if r.reader == nil {
 		return r.Err()
 	}
 
-	err := r.reader.Close()
+	r.memory.release()
+
+	// The stream may already have been closed by Cancel, possibly from another goroutine.
+	err := r.canceller.close(r.reader.Close)
 	if err != nil {
 		return maybeEnhanceAnalyticsError(err)
 	}
@@ -266,7 +304,7 @@ func (r *AnalyticsResult) One(valuePtr interface{}) error {
 		// do nothing with the row
 	}
 
-	return json.Unmarshal(valueBytes, valuePtr)
+	return deserializeRow(r.deserializer, valueBytes, valuePtr)
 }
 
 // MetaData returns any meta-data that was available from this query.  Note that
@@ -346,7 +384,24 @@ func (c *Cluster) AnalyticsQuery(statement string, opts *AnalyticsOptions) (*Ana
 		}
 	}
 
-	return execAnalyticsQuery(opts.Context, span, queryOpts, priorityInt, deadline, retryStrategy, provider, c.tracer, opts.Internal.User)
+	res, err := execAnalyticsQuery(opts.Context, span, queryOpts, priorityInt, deadline, retryStrategy, provider, c.tracer, opts.Internal.User)
+	if err != nil {
+		return nil, c.maybeEnhanceNoBucketErr(err)
+	}
+	res.memory.limiter = c.resultMemoryLimiter
+	res.memory.ctx = opts.Context
+	res.memory.deadline = deadline
+	res.deserializer = resultDeserializer(opts.Deserializer, c.deserializer)
+	res.deferred = &analyticsDeferredConfig{
+		provider:      c,
+		tracer:        c.tracer,
+		meter:         c.meter,
+		timeout:       c.timeoutsConfig.AnalyticsTimeout,
+		memoryLimiter: c.resultMemoryLimiter,
+		deserializer:  c.deserializer,
+	}
+
+	return res, nil
 }
 
 func maybeGetAnalyticsOption(options map[string]interface{}, name string) string {
@@ -696,3 +696,85 @@ func (suite *UnitTestSuite) TestAnalyticsQueryRaw() {
 
 	suite.Assert().Equal(reader.Meta, metadata)
 }
+
+type mockAnalyticsRawRowReader struct {
+	mockAnalyticsRowReader
+	Rows [][]byte
+}
+
+func (arr *mockAnalyticsRawRowReader) NextRow() []byte {
+	if arr.idx == len(arr.Rows) {
+		return nil
+	}
+
+	idx := arr.idx
+	arr.idx++
+
+	return arr.Rows[idx]
+}
+
+func (suite *UnitTestSuite) TestAnalyticsExplain() {
+	planBytes := []byte(`{"operator":"distribute-result","operatorId":"1.1","physical-operator":"DISTRIBUTE_RESULT",` +
+		`"execution-mode":"PARTITIONED","inputs":[{"operator":"data-scan","operatorId":"1.2",` +
+		`"physical-operator":"DATASOURCE_SCAN","execution-mode":"PARTITIONED"}]}`)
+
+	reader := &mockAnalyticsRawRowReader{
+		Rows: [][]byte{planBytes},
+	}
+
+	cluster := suite.analyticsCluster(nil, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.AnalyticsQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal("EXPLAIN SELECT * FROM dataset", actualOptions["statement"])
+	}, reader)
+
+	plan, err := cluster.AnalyticsExplain(" SELECT * FROM dataset", nil)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal(json.RawMessage(planBytes), plan.Raw)
+	suite.Assert().Equal(&AnalyticsPlanOperator{
+		Operator:         "distribute-result",
+		OperatorID:       "1.1",
+		PhysicalOperator: "DISTRIBUTE_RESULT",
+		ExecutionMode:    "PARTITIONED",
+		Inputs: []AnalyticsPlanOperator{{
+			Operator:         "data-scan",
+			OperatorID:       "1.2",
+			PhysicalOperator: "DATASOURCE_SCAN",
+			ExecutionMode:    "PARTITIONED",
+		}},
+	}, plan.Root)
+
+	_, err = cluster.AnalyticsExplain("CREATE DATASET breweries ON `beer-sample`", nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestAnalyticsQueryConsistentWith() {
+	state := NewMutationState(MutationToken{
+		token: gocbcore.MutationToken{
+			VbID:   1,
+			VbUUID: gocbcore.VbUUID(9),
+			SeqNo:  gocbcore.SeqNo(12),
+		},
+		bucketName: "frank",
+	})
+
+	execOpts, err := (&AnalyticsOptions{ConsistentWith: state}).toMap()
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("at_plus", execOpts["scan_consistency"])
+	suite.Assert().Equal(state, execOpts["scan_vectors"])
+
+	_, err = (&AnalyticsOptions{
+		ScanConsistency: AnalyticsScanConsistencyRequestPlus,
+		ConsistentWith:  state,
+	}).toMap()
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
@@ -87,6 +87,22 @@ const (
 	StorageBackendMagma StorageBackend = "magma"
 )
 
+// HistoryRetentionCollectionDefault specifies whether history retention is enabled by default for the collections
+// of a bucket.
+// UNCOMMITTED: This API may change in the future.
+type HistoryRetentionCollectionDefault uint8
+
+const (
+	// HistoryRetentionCollectionDefaultUnset specifies that the server default should be used.
+	HistoryRetentionCollectionDefaultUnset HistoryRetentionCollectionDefault = iota
+
+	// HistoryRetentionCollectionDefaultEnabled specifies that history retention is enabled by default.
+	HistoryRetentionCollectionDefaultEnabled
+
+	// HistoryRetentionCollectionDefaultDisabled specifies that history retention is disabled by default.
+	HistoryRetentionCollectionDefaultDisabled
+)
+
 type jsonBucketSettings struct {
 	Name        string `json:"name"`
 	Controllers struct {
@@ -105,6 +121,10 @@ type jsonBucketSettings struct {
 	CompressionMode        string `json:"compressionMode"`
 	MinimumDurabilityLevel string `json:"durabilityMinLevel"`
 	StorageBackend         string `json:"storageBackend"`
+
+	HistoryRetentionCollectionDefault *bool  `json:"historyRetentionCollectionDefault"`
+	HistoryRetentionBytes             uint64 `json:"historyRetentionBytes"`
+	HistoryRetentionSeconds           uint64 `json:"historyRetentionSeconds"`
 }
 
 // BucketSettings holds information about the settings for a bucket.
@@ -121,8 +141,50 @@ type BucketSettings struct {
 	MaxExpiry              time.Duration
 	CompressionMode        CompressionMode
 	MinimumDurabilityLevel DurabilityLevel
+	// StorageBackend cannot be changed once a bucket has been created.
 	// UNCOMMITTED: This API may change in the future.
 	StorageBackend StorageBackend
+
+	// HistoryRetentionCollectionDefault, HistoryRetentionBytes and HistoryRetentionDuration control the retention
+	// of document history, used by change streams, for magma buckets. Zero values leave the server defaults unchanged.
+	// UNCOMMITTED: This API may change in the future.
+	HistoryRetentionCollectionDefault HistoryRetentionCollectionDefault
+	// UNCOMMITTED: This API may change in the future.
+	HistoryRetentionBytes uint64
+	// UNCOMMITTED: This API may change in the future.
+	HistoryRetentionDuration time.Duration
+
+	// Raw provides a way to set bucket properties which are not otherwise exposed by BucketSettings, such as those
+	// added in newer server versions, when creating or updating a bucket. Each value is formatted using fmt.Sprint
+	// and sent alongside the typed settings, which take precedence should the same property be set by both.
+	// Properties are passed to the server without any validation, so unknown or invalid properties will result in
+	// an error from the server.
+	// UNCOMMITTED: This API may change in the future.
+	Raw map[string]interface{}
+
+	rawProperties map[string]json.RawMessage
+}
+
+// RawProperties returns all of the properties of the bucket as returned by the server, including those not
+// exposed by BucketSettings. This is only populated for settings returned by GetBucket and GetAllBuckets.
+// UNCOMMITTED: This API may change in the future.
+func (bs *BucketSettings) RawProperties() map[string]json.RawMessage {
+	return bs.rawProperties
+}
+
+func (bs *BucketSettings) fromRawData(data json.RawMessage) error {
+	var bucketData jsonBucketSettings
+	err := json.Unmarshal(data, &bucketData)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(data, &bs.rawProperties)
+	if err != nil {
+		return err
+	}
+
+	return bs.fromData(bucketData)
 }
 
 func (bs *BucketSettings) fromData(data jsonBucketSettings) error {
@@ -137,6 +199,16 @@ func (bs *BucketSettings) fromData(data jsonBucketSettings) error {
 	bs.CompressionMode = CompressionMode(data.CompressionMode)
 	bs.MinimumDurabilityLevel = durabilityLevelFromManagementAPI(data.MinimumDurabilityLevel)
 	bs.StorageBackend = StorageBackend(data.StorageBackend)
+	bs.HistoryRetentionBytes = data.HistoryRetentionBytes
+	bs.HistoryRetentionDuration = time.Duration(data.HistoryRetentionSeconds) * time.Second
+
+	if data.HistoryRetentionCollectionDefault != nil {
+		if *data.HistoryRetentionCollectionDefault {
+			bs.HistoryRetentionCollectionDefault = HistoryRetentionCollectionDefaultEnabled
+		} else {
+			bs.HistoryRetentionCollectionDefault = HistoryRetentionCollectionDefaultDisabled
+		}
+	}
 
 	switch data.BucketType {
 	case "membase":
@@ -298,7 +370,7 @@ func (bm *BucketManager) get(ctx context.Context, tracectx RequestSpanContext, p
 		return nil, makeMgmtBadStatusError("failed to get bucket", &req, resp)
 	}
 
-	var bucketData jsonBucketSettings
+	var bucketData json.RawMessage
 	jsonDec := json.NewDecoder(resp.Body)
 	err = jsonDec.Decode(&bucketData)
 	if err != nil {
@@ -306,7 +378,7 @@ func (bm *BucketManager) get(ctx context.Context, tracectx RequestSpanContext, p
 	}
 
 	var settings BucketSettings
-	err = settings.fromData(bucketData)
+	err = settings.fromRawData(bucketData)
 	if err != nil {
 		return nil, err
 	}
@@ -365,7 +437,7 @@ func (bm *BucketManager) GetAllBuckets(opts *GetAllBucketsOptions) (map[string]B
 		return nil, makeMgmtBadStatusError("failed to get all buckets", &req, resp)
 	}
 
-	var bucketsData []*jsonBucketSettings
+	var bucketsData []json.RawMessage
 	jsonDec := json.NewDecoder(resp.Body)
 	err = jsonDec.Decode(&bucketsData)
 	if err != nil {
@@ -375,7 +447,7 @@ func (bm *BucketManager) GetAllBuckets(opts *GetAllBucketsOptions) (map[string]B
 	buckets := make(map[string]BucketSettings, len(bucketsData))
 	for _, bucketData := range bucketsData {
 		var bucket BucketSettings
-		err := bucket.fromData(*bucketData)
+		err := bucket.fromRawData(bucketData)
 		if err != nil {
 			return nil, err
 		}
@@ -389,6 +461,10 @@ func (bm *BucketManager) GetAllBuckets(opts *GetAllBucketsOptions) (map[string]B
 // CreateBucketSettings are the settings available when creating a bucket.
 type CreateBucketSettings struct {
 	BucketSettings
+
+	// ConflictResolutionType is the conflict resolution used by XDCR for the bucket, it can only be set when the
+	// bucket is created. It is valid for couchbase and ephemeral buckets but not for memcached buckets, which do not
+	// support XDCR. Defaults to ConflictResolutionTypeSequenceNumber.
 	ConflictResolutionType ConflictResolutionType
 }
 
@@ -424,7 +500,17 @@ func (bm *BucketManager) CreateBucket(settings CreateBucketSettings, opts *Creat
 	}
 
 	if settings.ConflictResolutionType != "" {
-		posts.Add("conflictResolutionType", string(settings.ConflictResolutionType))
+		switch settings.ConflictResolutionType {
+		case ConflictResolutionTypeTimestamp, ConflictResolutionTypeSequenceNumber, ConflictResolutionTypeCustom:
+		default:
+			return makeInvalidArgumentsError("unrecognized conflict resolution type")
+		}
+
+		if settings.BucketType == MemcachedBucketType {
+			return makeInvalidArgumentsError("conflict resolution type is not valid for memcached buckets")
+		}
+
+		posts.Set("conflictResolutionType", string(settings.ConflictResolutionType))
 	}
 
 	eSpan := createSpan(bm.tracer, span, "request_encoding", "")
@@ -474,6 +560,8 @@ type UpdateBucketOptions struct {
 }
 
 // UpdateBucket updates a bucket on the cluster.
+// The storage backend of a bucket cannot be changed, if settings specifies a StorageBackend which differs from that of
+// the bucket then an InvalidArgumentError is returned.
 func (bm *BucketManager) UpdateBucket(settings BucketSettings, opts *UpdateBucketOptions) error {
 	if opts == nil {
 		opts = &UpdateBucketOptions{}
@@ -493,6 +581,21 @@ func (bm *BucketManager) UpdateBucket(settings BucketSettings, opts *UpdateBucke
 		return err
 	}
 
+	if settings.StorageBackend != "" {
+		existing, err := bm.get(opts.Context, span.Context(), path, opts.RetryStrategy, opts.Timeout)
+		if err != nil {
+			return err
+		}
+
+		if existing.StorageBackend != settings.StorageBackend {
+			return makeInvalidArgumentsError(fmt.Sprintf("storage backend of bucket %s cannot be changed from %s to %s",
+				settings.Name, existing.StorageBackend, settings.StorageBackend))
+		}
+
+		// The server rejects the storage backend being sent on update, even when it is unchanged.
+		posts.Del("storageBackend")
+	}
+
 	eSpan := createSpan(bm.tracer, span, "request_encoding", "")
 	d := posts.Encode()
 	eSpan.End()
@@ -724,5 +827,30 @@ func (bm *BucketManager) settingsToPostData(settings *BucketSettings) (url.Value
 		posts.Add("storageBackend", string(settings.StorageBackend))
 	}
 
+	switch settings.HistoryRetentionCollectionDefault {
+	case HistoryRetentionCollectionDefaultUnset:
+	case HistoryRetentionCollectionDefaultEnabled:
+		posts.Add("historyRetentionCollectionDefault", "true")
+	case HistoryRetentionCollectionDefaultDisabled:
+		posts.Add("historyRetentionCollectionDefault", "false")
+	default:
+		return nil, makeInvalidArgumentsError("unrecognized history retention collection default")
+	}
+
+	if settings.HistoryRetentionBytes > 0 {
+		posts.Add("historyRetentionBytes", fmt.Sprintf("%d", settings.HistoryRetentionBytes))
+	}
+
+	if settings.HistoryRetentionDuration > 0 {
+		posts.Add("historyRetentionSeconds", fmt.Sprintf("%d", settings.HistoryRetentionDuration/time.Second))
+	}
+
+	for key, value := range settings.Raw {
+		if _, ok := posts[key]; ok {
+			continue
+		}
+		posts.Add(key, fmt.Sprint(value))
+	}
+
 	return posts, nil
 }
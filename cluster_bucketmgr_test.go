@@ -1,9 +1,17 @@
 package gocb
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"net/url"
 	"testing"
 	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
 )
 
 func (suite *IntegrationTestSuite) TestBucketMgrOps() {
@@ -633,3 +641,195 @@ func (suite *IntegrationTestSuite) TestBucketMgrCustomConflictResolution() {
 	suite.Require().Nil(err, err)
 	suite.Assert().Equal(bName, b.Name)
 }
+
+func (suite *UnitTestSuite) TestBucketMgrRawSettings() {
+	var postedForm url.Values
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			if req.Method == "GET" {
+				return &mgmtResponse{
+					StatusCode: 200,
+					Body: ioutil.NopCloser(bytes.NewReader([]byte(`{"name":"test","bucketType":"membase",` +
+						`"historyRetentionSeconds":3600}`))),
+				}
+			}
+
+			var err error
+			postedForm, err = url.ParseQuery(string(req.Body))
+			suite.Require().Nil(err, err)
+
+			return &mgmtResponse{
+				StatusCode: 202,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+			}
+		}, nil)
+
+	mgr := BucketManager{
+		provider: mockProvider,
+		tracer:   &NoopTracer{},
+		meter:    &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	settings, err := mgr.GetBucket("test", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(json.RawMessage("3600"), settings.RawProperties()["historyRetentionSeconds"])
+
+	err = mgr.CreateBucket(CreateBucketSettings{
+		BucketSettings: BucketSettings{
+			Name:       "test",
+			RAMQuotaMB: 100,
+			BucketType: CouchbaseBucketType,
+			Raw: map[string]interface{}{
+				"historyRetentionSeconds": 3600,
+				"ramQuotaMB":              200,
+			},
+		},
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal([]string{"3600"}, postedForm["historyRetentionSeconds"])
+	suite.Assert().Equal([]string{"100"}, postedForm["ramQuotaMB"])
+}
+
+func (suite *UnitTestSuite) TestBucketMgrCreateConflictResolution() {
+	var postedForm url.Values
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			var err error
+			postedForm, err = url.ParseQuery(string(req.Body))
+			suite.Require().Nil(err, err)
+
+			return &mgmtResponse{
+				StatusCode: 202,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+			}
+		}, nil)
+
+	mgr := BucketManager{
+		provider: mockProvider,
+		tracer:   &NoopTracer{},
+		meter:    &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := mgr.CreateBucket(CreateBucketSettings{
+		BucketSettings: BucketSettings{
+			Name:           "test",
+			RAMQuotaMB:     100,
+			BucketType:     EphemeralBucketType,
+			EvictionPolicy: EvictionPolicyTypeNoEviction,
+		},
+		ConflictResolutionType: ConflictResolutionTypeTimestamp,
+	}, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]string{"ephemeral"}, postedForm["bucketType"])
+	suite.Assert().Equal([]string{"lww"}, postedForm["conflictResolutionType"])
+
+	err = mgr.CreateBucket(CreateBucketSettings{
+		BucketSettings: BucketSettings{
+			Name:       "test",
+			RAMQuotaMB: 100,
+			BucketType: MemcachedBucketType,
+		},
+		ConflictResolutionType: ConflictResolutionTypeTimestamp,
+	}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	err = mgr.CreateBucket(CreateBucketSettings{
+		BucketSettings: BucketSettings{
+			Name:       "test",
+			RAMQuotaMB: 100,
+			BucketType: CouchbaseBucketType,
+		},
+		ConflictResolutionType: "clock",
+	}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+	mockProvider.AssertNumberOfCalls(suite.T(), "executeMgmtRequest", 1)
+}
+
+func (suite *UnitTestSuite) TestBucketMgrContextCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	httpProvider := new(mockHttpProvider)
+	httpProvider.
+		On("DoHTTPRequest", ctx, mock.AnythingOfType("*gocbcore.HTTPRequest")).
+		Return(nil, &gocbcore.HTTPError{InnerError: gocbcore.ErrRequestCanceled})
+
+	cli := new(mockConnectionManager)
+	cli.On("getHTTPProvider", "").Return(httpProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	_, err := cluster.Buckets().GetBucket("test", &GetBucketOptions{
+		Context: ctx,
+	})
+	if !errors.Is(err, context.Canceled) {
+		suite.T().Fatalf("Expected error to be context canceled but was %v", err)
+	}
+	suite.Assert().True(errors.Is(err, ErrRequestCanceled))
+}
+
+func (suite *UnitTestSuite) TestBucketMgrHistoryRetentionAndStorageBackend() {
+	var postedForm url.Values
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			if req.Method == "GET" {
+				return &mgmtResponse{
+					StatusCode: 200,
+					Body: ioutil.NopCloser(bytes.NewReader([]byte(`{"name":"test","bucketType":"membase",` +
+						`"storageBackend":"magma","historyRetentionCollectionDefault":false,` +
+						`"historyRetentionBytes":2147483648,"historyRetentionSeconds":3600}`))),
+				}
+			}
+
+			var err error
+			postedForm, err = url.ParseQuery(string(req.Body))
+			suite.Require().Nil(err, err)
+
+			return &mgmtResponse{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+			}
+		}, nil)
+
+	mgr := BucketManager{
+		provider: mockProvider,
+		tracer:   &NoopTracer{},
+		meter:    &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	settings, err := mgr.GetBucket("test", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(StorageBackendMagma, settings.StorageBackend)
+	suite.Assert().Equal(HistoryRetentionCollectionDefaultDisabled, settings.HistoryRetentionCollectionDefault)
+	suite.Assert().Equal(uint64(2147483648), settings.HistoryRetentionBytes)
+	suite.Assert().Equal(time.Hour, settings.HistoryRetentionDuration)
+
+	settings.RAMQuotaMB = 200
+	settings.HistoryRetentionCollectionDefault = HistoryRetentionCollectionDefaultEnabled
+	err = mgr.UpdateBucket(*settings, nil)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal([]string{"true"}, postedForm["historyRetentionCollectionDefault"])
+	suite.Assert().Equal([]string{"2147483648"}, postedForm["historyRetentionBytes"])
+	suite.Assert().Equal([]string{"3600"}, postedForm["historyRetentionSeconds"])
+	suite.Assert().NotContains(postedForm, "storageBackend")
+
+	postedForm = nil
+	settings.StorageBackend = StorageBackendCouchstore
+	err = mgr.UpdateBucket(*settings, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+	suite.Assert().Nil(postedForm)
+}
@@ -0,0 +1,160 @@
+package gocb
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+)
+
+// ConfigChangeEvent is the payload passed to a ConfigChangeListener.
+// VOLATILE: This API is subject to change at any time.
+type ConfigChangeEvent struct {
+	// PreviousRevID is the revision of the cluster configuration which was replaced, this is -1 for the first
+	// configuration seen after connecting.
+	PreviousRevID int64
+	// RevID is the revision of the cluster configuration which has been applied.
+	RevID int64
+	// Updates is the number of changes of revision seen since connecting, including this one. Revisions which are
+	// applied in quick succession, within the TopologyConfig.PollInterval, are seen as a single change.
+	Updates uint64
+}
+
+// ConfigChangeListener is invoked whenever the SDK applies a new revision of the cluster configuration, which
+// happens when the topology of the cluster changes such as during a rebalance or failover.
+// VOLATILE: This API is subject to change at any time.
+type ConfigChangeListener func(event ConfigChangeEvent)
+
+// configWatcher tracks the revision of the cluster configuration held by the SDK, it is only started when a
+// ConfigChangeListener is configured. The SDK does not expose when configurations are applied so the revision is
+// sampled periodically, several updates applied between samples are seen as a single update.
+type configWatcher struct {
+	cluster  *Cluster
+	listener ConfigChangeListener
+	interval time.Duration
+
+	revID   int64
+	updates uint64
+
+	// events passes changes from the polling goroutine to the goroutine which calls the listener, so that the
+	// listener can stop the watcher without waiting for itself to return.
+	events chan ConfigChangeEvent
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+}
+
+func newConfigWatcher(cluster *Cluster, config TopologyConfig) *configWatcher {
+	interval := config.PollInterval
+	if interval == 0 {
+		interval = defaultTopologyPollInterval
+	}
+
+	return &configWatcher{
+		cluster:  cluster,
+		listener: config.ConfigChangeListener,
+		interval: interval,
+		revID:    -1,
+		events:   make(chan ConfigChangeEvent),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+func (cw *configWatcher) start() {
+	go cw.loop()
+	go cw.dispatch()
+}
+
+func (cw *configWatcher) stop() {
+	cw.stopOnce.Do(func() {
+		close(cw.stopCh)
+	})
+	<-cw.doneCh
+}
+
+func (cw *configWatcher) loop() {
+	defer close(cw.doneCh)
+
+	for {
+		cw.poll()
+
+		select {
+		case <-cw.stopCh:
+			return
+		case <-time.After(cw.interval):
+		}
+	}
+}
+
+func (cw *configWatcher) poll() {
+	provider, err := cw.cluster.getDiagnosticsProvider()
+	if err != nil {
+		logDebugf("Failed to get diagnostics provider for config watcher: %v", err)
+		return
+	}
+
+	info, err := provider.Diagnostics(gocbcore.DiagnosticsOptions{})
+	if err != nil {
+		logDebugf("Failed to fetch config revision: %v", err)
+		return
+	}
+
+	event, changed := cw.update(info.ConfigRev)
+	if !changed || cw.listener == nil {
+		return
+	}
+
+	select {
+	case cw.events <- event:
+	case <-cw.stopCh:
+	}
+}
+
+func (cw *configWatcher) dispatch() {
+	for {
+		select {
+		case event := <-cw.events:
+			select {
+			case <-cw.stopCh:
+				return
+			default:
+			}
+
+			cw.listener(event)
+		case <-cw.stopCh:
+			return
+		}
+	}
+}
+
+func (cw *configWatcher) update(revID int64) (ConfigChangeEvent, bool) {
+	// A revision of 0 or below means that no configuration has been applied yet.
+	if revID <= 0 {
+		return ConfigChangeEvent{}, false
+	}
+
+	previous := cw.revID
+	if revID == previous {
+		return ConfigChangeEvent{}, false
+	}
+
+	cw.revID = revID
+	updates := atomic.AddUint64(&cw.updates, 1)
+
+	return ConfigChangeEvent{
+		PreviousRevID: previous,
+		RevID:         revID,
+		Updates:       updates,
+	}, true
+}
+
+func (cw *configWatcher) updateCount() uint64 {
+	if cw == nil {
+		return 0
+	}
+
+	return atomic.LoadUint64(&cw.updates)
+}
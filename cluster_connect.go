@@ -0,0 +1,248 @@
+package gocb
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	gocbconnstr "github.com/couchbase/gocbcore/v10/connstr"
+	"github.com/couchbase/gocbcore/v10/memd"
+)
+
+// connectProbeTimeout is how long Connect spends probing each bootstrap host, after failing to connect, in order to
+// report why it could not be connected to.
+const connectProbeTimeout = 2 * time.Second
+
+// Ports which are connected to when the connection string does not specify a port for a host.
+const (
+	defaultMemdPort    = 11210
+	defaultMemdTLSPort = 11207
+)
+
+// ConnectConfig specifies options for whether Connect waits for the cluster to be reachable before returning.
+// UNCOMMITTED: This API may change in the future.
+type ConnectConfig struct {
+	// WaitUntilConnected causes Connect to block until at least one node of the cluster has responded over the KV
+	// service, checking again with Backoff, or until TimeoutsConfig.ConnectTimeout elapses. If BootstrapBucket is set
+	// then the nodes must respond for that bucket. If no node responds in time then Connect returns a *ConnectError
+	// which reports why each of the bootstrap hosts could not be connected to. By default Connect returns as soon as
+	// connecting has begun and a cluster which cannot be reached is only reported by the operations which follow.
+	WaitUntilConnected bool
+
+	// Backoff calculates how long to wait before checking again whether a node has responded, given the number of
+	// checks made so far. Defaults to an exponential backoff from 10ms up to 1s.
+	Backoff BackoffCalculator
+}
+
+func (config ConnectConfig) backoff() BackoffCalculator {
+	if config.Backoff == nil {
+		return ExponentialBackoff(10*time.Millisecond, time.Second, 2)
+	}
+
+	return config.Backoff
+}
+
+// waitUntilConnected waits for at least one node to respond over the KV service, for the bucket if one is given.
+func (c *Cluster) waitUntilConnected(bucketName string) error {
+	provider, err := c.connectionManager.getWaitUntilReadyProvider(bucketName)
+	if err != nil {
+		return err
+	}
+
+	wrapper := waitUntilReadyRetryStrategy(c.retryStrategyWrapper, &WaitUntilReadyOptions{
+		PollBackoff: c.connectConfig.backoff(),
+	})
+
+	err = provider.WaitUntilReady(
+		nil,
+		time.Now().Add(c.timeoutsConfig.ConnectTimeout),
+		gocbcore.WaitUntilReadyOptions{
+			DesiredState:  gocbcore.ClusterStateDegraded,
+			ServiceTypes:  []gocbcore.ServiceType{gocbcore.MemdService},
+			RetryStrategy: wrapper,
+		},
+	)
+	if err != nil {
+		hosts := c.probeBootstrapHosts(err)
+		innerErr := maybeEnhanceCoreErr(err)
+
+		// A host which offers none of the allowed mechanisms explains the authentication failure better than the
+		// error reported by the SDK, which cannot tell why the server rejected it.
+		for _, host := range hosts {
+			var mechErr *SaslMechanismError
+			if errors.As(host.Err, &mechErr) {
+				innerErr = mechErr
+				break
+			}
+		}
+
+		return &ConnectError{
+			InnerError: innerErr,
+			Hosts:      hosts,
+		}
+	}
+
+	return nil
+}
+
+// probeBootstrapHosts connects to each of the bootstrap hosts in turn to find out why they could not be connected
+// to by the SDK. The probes are made concurrently, so that unreachable hosts do not delay each other.
+func (c *Cluster) probeBootstrapHosts(connectErr error) []ConnectHostFailure {
+	spec := c.connSpec()
+
+	var tlsConfig *tls.Config
+	port := defaultMemdPort
+	if spec.Scheme == "couchbases" {
+		rootCAs := c.tlsRootCAProvider()()
+		tlsConfig = &tls.Config{
+			RootCAs:            rootCAs,
+			InsecureSkipVerify: rootCAs == nil, // nolint: gosec
+		}
+		port = defaultMemdTLSPort
+	}
+
+	addresses := spec.Addresses
+	failures := make([]ConnectHostFailure, len(addresses))
+	var wg sync.WaitGroup
+	for i, address := range addresses {
+		if address.Port < 0 {
+			address.Port = port
+		}
+
+		wg.Add(1)
+		go func(i int, address gocbconnstr.Address) {
+			defer wg.Done()
+			failures[i] = probeBootstrapHost(address, tlsConfig, connectErr, c.securityConfig.AllowedSaslMechanisms)
+		}(i, address)
+	}
+	wg.Wait()
+
+	return failures
+}
+
+func probeBootstrapHost(address gocbconnstr.Address, tlsConfig *tls.Config, connectErr error,
+	allowedMechs []SaslMechanism) ConnectHostFailure {
+	ctx, cancel := context.WithTimeout(context.Background(), connectProbeTimeout)
+	defer cancel()
+
+	failure := ConnectHostFailure{
+		Address: net.JoinHostPort(address.Host, strconv.Itoa(address.Port)),
+	}
+
+	if net.ParseIP(address.Host) == nil {
+		_, err := net.DefaultResolver.LookupHost(ctx, address.Host)
+		if err != nil {
+			failure.Reason = ConnectFailureReasonDNS
+			failure.Err = err
+			return failure
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", failure.Address)
+	if err != nil {
+		failure.Reason = ConnectFailureReasonTCP
+		failure.Err = err
+		return failure
+	}
+	defer func() {
+		err := conn.Close()
+		if err != nil {
+			logDebugf("Failed to close connection to bootstrap host %s: %v", failure.Address, err)
+		}
+	}()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(deadline)
+		if err != nil {
+			failure.Reason = ConnectFailureReasonTCP
+			failure.Err = err
+			return failure
+		}
+	}
+
+	var stream io.ReadWriter = conn
+	if tlsConfig != nil {
+		hostConfig := tlsConfig.Clone()
+		hostConfig.ServerName = address.Host
+		tlsConn := tls.Client(conn, hostConfig)
+		err = tlsConn.Handshake()
+		if err != nil {
+			failure.Reason = ConnectFailureReasonTLS
+			failure.Err = err
+			return failure
+		}
+		stream = tlsConn
+	}
+
+	// The host can be reached, so the failure is only known if the SDK reported it.
+	if errors.Is(connectErr, ErrAuthenticationFailure) {
+		failure.Reason = ConnectFailureReasonAuthentication
+		failure.Err = connectErr
+
+		if len(allowedMechs) > 0 {
+			offered, err := listSaslMechanisms(stream)
+			if err != nil {
+				logDebugf("Failed to list the sasl mechanisms of bootstrap host %s: %v", failure.Address, err)
+			} else if !saslMechanismsOverlap(allowedMechs, offered) {
+				failure.Err = &SaslMechanismError{
+					Requested: allowedMechs,
+					Offered:   offered,
+				}
+			}
+		}
+
+		return failure
+	}
+
+	failure.Reason = ConnectFailureReasonUnknown
+	return failure
+}
+
+// listSaslMechanisms asks a node which sasl mechanisms it offers for authentication.
+func listSaslMechanisms(stream io.ReadWriter) ([]SaslMechanism, error) {
+	conn := memd.NewConn(stream)
+	err := conn.WritePacket(&memd.Packet{
+		Magic:   memd.CmdMagicReq,
+		Command: memd.CmdSASLListMechs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, _, err := conn.ReadPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Command != memd.CmdSASLListMechs || resp.Status != memd.StatusSuccess {
+		return nil, fmt.Errorf("unexpected response to sasl list mechanisms with status 0x%02x", uint16(resp.Status))
+	}
+
+	var mechs []SaslMechanism
+	for _, mech := range strings.Fields(string(resp.Value)) {
+		mechs = append(mechs, SaslMechanism(mech))
+	}
+
+	return mechs, nil
+}
+
+func saslMechanismsOverlap(allowed, offered []SaslMechanism) bool {
+	for _, allowedMech := range allowed {
+		for _, offeredMech := range offered {
+			if allowedMech == offeredMech {
+				return true
+			}
+		}
+	}
+
+	return false
+}
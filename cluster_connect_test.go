@@ -0,0 +1,160 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	gocbconnstr "github.com/couchbase/gocbcore/v10/connstr"
+	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestClusterWaitUntilConnectedReportsHosts() {
+	// Accepts connections and closes them straight away, which is enough for TCP but fails any TLS handshake.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().Nil(err, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().Nil(err, err)
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	openPort := listener.Addr().(*net.TCPAddr).Port
+	waitErr := ErrUnambiguousTimeout
+	provider := new(mockWaitUntilReadyProvider)
+	provider.
+		On("WaitUntilReady", nil, mock.AnythingOfType("time.Time"), mock.AnythingOfType("gocbcore.WaitUntilReadyOptions")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(2).(gocbcore.WaitUntilReadyOptions)
+			suite.Assert().Equal(gocbcore.ClusterStateDegraded, opts.DesiredState)
+			suite.Assert().Equal([]gocbcore.ServiceType{gocbcore.MemdService}, opts.ServiceTypes)
+
+			action := opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{attempts: 2, idempotent: true},
+				gocbcore.UnknownRetryReason)
+			suite.Assert().Equal(5*time.Millisecond, action.Duration())
+		}).
+		Return(func(ctx context.Context, deadline time.Time, opts gocbcore.WaitUntilReadyOptions) error {
+			return waitErr
+		})
+
+	cli := new(mockConnectionManager)
+	cli.On("getWaitUntilReadyProvider", "default").Return(provider, nil)
+
+	cluster := suite.newCluster(cli)
+	cluster.connectConfig = ConnectConfig{
+		WaitUntilConnected: true,
+		Backoff: func(retryAttempts uint32) time.Duration {
+			return time.Duration(retryAttempts) * 2500 * time.Microsecond
+		},
+	}
+	cluster.cSpec = gocbconnstr.ConnSpec{
+		Addresses: []gocbconnstr.Address{
+			{Host: "bootstrap.invalid", Port: -1},
+			{Host: "127.0.0.1", Port: closedPort},
+			{Host: "127.0.0.1", Port: openPort},
+		},
+	}
+
+	err = cluster.waitUntilConnected("default")
+	var connectErr *ConnectError
+	suite.Require().True(errors.As(err, &connectErr), "expected connect error but was %v", err)
+	suite.Assert().True(errors.Is(err, ErrUnambiguousTimeout))
+	suite.Require().Len(connectErr.Hosts, 3)
+	suite.Assert().Equal("bootstrap.invalid:11210", connectErr.Hosts[0].Address)
+	suite.Assert().Equal(ConnectFailureReasonDNS, connectErr.Hosts[0].Reason)
+	suite.Assert().Equal(ConnectFailureReasonTCP, connectErr.Hosts[1].Reason)
+	suite.Assert().Equal("127.0.0.1:"+strconv.Itoa(openPort), connectErr.Hosts[2].Address)
+	suite.Assert().Equal(ConnectFailureReasonUnknown, connectErr.Hosts[2].Reason)
+	suite.Assert().Contains(err.Error(), "127.0.0.1:"+strconv.Itoa(closedPort)+" tcp")
+
+	cluster.cSpec.Scheme = "couchbases"
+	cluster.securityConfig.TLSSkipVerify = true
+	err = cluster.waitUntilConnected("default")
+	suite.Require().True(errors.As(err, &connectErr), "expected connect error but was %v", err)
+	suite.Assert().Equal("bootstrap.invalid:11207", connectErr.Hosts[0].Address)
+	suite.Assert().Equal(ConnectFailureReasonTLS, connectErr.Hosts[2].Reason)
+
+	cluster.cSpec.Scheme = "couchbase"
+	waitErr = ErrAuthenticationFailure
+	err = cluster.waitUntilConnected("default")
+	suite.Require().True(errors.As(err, &connectErr), "expected connect error but was %v", err)
+	suite.Assert().True(errors.Is(err, ErrAuthenticationFailure))
+	suite.Assert().Equal(ConnectFailureReasonAuthentication, connectErr.Hosts[2].Reason)
+}
+
+func (suite *UnitTestSuite) TestClusterWaitUntilConnectedSaslMechanismMismatch() {
+	// Answers the sasl list mechanisms request of the probe, offering only PLAIN.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().Nil(err, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			memdConn := memd.NewConn(conn)
+			req, _, err := memdConn.ReadPacket()
+			if err == nil && req.Command == memd.CmdSASLListMechs {
+				_ = memdConn.WritePacket(&memd.Packet{
+					Magic:   memd.CmdMagicRes,
+					Command: memd.CmdSASLListMechs,
+					Status:  memd.StatusSuccess,
+					Opaque:  req.Opaque,
+					Value:   []byte("PLAIN"),
+				})
+			}
+			conn.Close()
+		}
+	}()
+
+	provider := new(mockWaitUntilReadyProvider)
+	provider.
+		On("WaitUntilReady", nil, mock.AnythingOfType("time.Time"), mock.AnythingOfType("gocbcore.WaitUntilReadyOptions")).
+		Return(ErrAuthenticationFailure)
+
+	cli := new(mockConnectionManager)
+	cli.On("getWaitUntilReadyProvider", "default").Return(provider, nil)
+
+	cluster := suite.newCluster(cli)
+	cluster.connectConfig = ConnectConfig{WaitUntilConnected: true}
+	cluster.securityConfig.AllowedSaslMechanisms = []SaslMechanism{ScramSha512SaslMechanism, ScramSha256SaslMechanism}
+	cluster.cSpec = gocbconnstr.ConnSpec{
+		Addresses: []gocbconnstr.Address{
+			{Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port},
+		},
+	}
+
+	err = cluster.waitUntilConnected("default")
+	var mechErr *SaslMechanismError
+	suite.Require().True(errors.As(err, &mechErr), "expected sasl mechanism error but was %v", err)
+	suite.Assert().True(errors.Is(err, ErrAuthenticationFailure))
+	suite.Assert().Equal([]SaslMechanism{ScramSha512SaslMechanism, ScramSha256SaslMechanism}, mechErr.Requested)
+	suite.Assert().Equal([]SaslMechanism{PlainSaslMechanism}, mechErr.Offered)
+	suite.Assert().Contains(err.Error(), "allowed [SCRAM-SHA512, SCRAM-SHA256], offered [PLAIN]")
+
+	var connectErr *ConnectError
+	suite.Require().True(errors.As(err, &connectErr), "expected connect error but was %v", err)
+	suite.Assert().Equal(ConnectFailureReasonAuthentication, connectErr.Hosts[0].Reason)
+
+	// A server which offers an allowed mechanism must have rejected the credentials instead.
+	cluster.securityConfig.AllowedSaslMechanisms = []SaslMechanism{PlainSaslMechanism}
+	err = cluster.waitUntilConnected("default")
+	suite.Assert().False(errors.As(err, &mechErr), "expected no sasl mechanism error but was %v", err)
+	suite.Assert().True(errors.Is(err, ErrAuthenticationFailure))
+}
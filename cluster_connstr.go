@@ -0,0 +1,90 @@
+package gocb
+
+import (
+	"errors"
+
+	"github.com/couchbase/gocbcore/v10"
+	gocbconnstr "github.com/couchbase/gocbcore/v10/connstr"
+)
+
+// ConnectionStringAddress is a single address within a connection string.
+// UNCOMMITTED: This API may change in the future.
+type ConnectionStringAddress struct {
+	Host string
+
+	// Port is the port of the address, or -1 if no port was specified and the default port for the scheme is used.
+	Port int
+}
+
+// ConnectionString is the result of parsing and validating a connection string.
+// UNCOMMITTED: This API may change in the future.
+type ConnectionString struct {
+	Scheme    string
+	Addresses []ConnectionStringAddress
+	Bucket    string
+	Options   map[string][]string
+
+	spec gocbconnstr.ConnSpec
+}
+
+// String returns the connection string in its normalized form.
+func (cs *ConnectionString) String() string {
+	return cs.spec.String()
+}
+
+// ParseConnectionString parses and validates a connection string, returning its components. This applies the same
+// validation as Connect, including of the options within the connection string, allowing configuration to be
+// checked before attempting to connect. Any error returned wraps ErrInvalidArgument.
+// When the connection string contains a single hostname without a port, validating it may perform a DNS SRV lookup
+// for the hostname, as is done by Connect.
+// UNCOMMITTED: This API may change in the future.
+func ParseConnectionString(connStr string) (*ConnectionString, error) {
+	spec, err := parseConnSpec(connStr)
+	if err != nil {
+		return nil, makeInvalidArgumentsError(err.Error())
+	}
+
+	if len(spec.Addresses) == 0 {
+		return nil, makeInvalidArgumentsError("connection string must contain at least one address")
+	}
+
+	err = (&Cluster{}).parseExtraConnStrOptions(spec)
+	if err != nil {
+		return nil, makeInvalidArgumentsError(err.Error())
+	}
+
+	var config gocbcore.AgentGroupConfig
+	err = config.FromConnStr(spec.String())
+	if err != nil {
+		return nil, makeInvalidArgumentsError(err.Error())
+	}
+
+	addresses := make([]ConnectionStringAddress, len(spec.Addresses))
+	for i, address := range spec.Addresses {
+		addresses[i] = ConnectionStringAddress{
+			Host: address.Host,
+			Port: address.Port,
+		}
+	}
+
+	return &ConnectionString{
+		Scheme:    spec.Scheme,
+		Addresses: addresses,
+		Bucket:    spec.Bucket,
+		Options:   spec.Options,
+		spec:      spec,
+	}, nil
+}
+
+func parseConnSpec(connStr string) (gocbconnstr.ConnSpec, error) {
+	connSpec, err := gocbconnstr.Parse(connStr)
+	if err != nil {
+		return gocbconnstr.ConnSpec{}, err
+	}
+
+	if connSpec.Scheme == "http" {
+		return gocbconnstr.ConnSpec{}, errors.New("http scheme is not supported, use couchbase or couchbases instead")
+	}
+
+	return connSpec, nil
+}
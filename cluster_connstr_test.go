@@ -0,0 +1,35 @@
+package gocb
+
+import (
+	"errors"
+)
+
+func (suite *UnitTestSuite) TestParseConnectionString() {
+	connStr, err := ParseConnectionString("couchbases://10.112.191.101:11207,10.112.191.102/default?query_timeout=1000")
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal("couchbases", connStr.Scheme)
+	suite.Assert().Equal([]ConnectionStringAddress{
+		{Host: "10.112.191.101", Port: 11207},
+		{Host: "10.112.191.102", Port: -1},
+	}, connStr.Addresses)
+	suite.Assert().Equal("default", connStr.Bucket)
+	suite.Assert().Equal([]string{"1000"}, connStr.Options["query_timeout"])
+
+	reparsed, err := ParseConnectionString(connStr.String())
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(connStr.Addresses, reparsed.Addresses)
+
+	invalid := []string{
+		"http://10.112.191.101",
+		"couchbase://10.112.191.101?query_timeout=soon",
+		"couchbase://10.112.191.101?kv_timeout=soon",
+		"couchbase://",
+	}
+	for _, connStr := range invalid {
+		_, err := ParseConnectionString(connStr)
+		if !errors.Is(err, ErrInvalidArgument) {
+			suite.T().Fatalf("Expected invalid argument error for %s but was %v", connStr, err)
+		}
+	}
+}
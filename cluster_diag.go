@@ -18,6 +18,12 @@ type EndPointDiagnostics struct {
 	LastActivity time.Time
 	State        EndpointState
 	Namespace    string
+
+	// ConfigRev is the revision of the configuration which the SDK holds for the bucket given by Namespace, which
+	// is used to route requests to this endpoint. Endpoints which are not bucket scoped use the cluster
+	// configuration. The SDK does not expose the epoch of the configuration, so only the revision is reported.
+	// VOLATILE: This API is subject to change at any time.
+	ConfigRev int64
 }
 
 // DiagnosticsResult encapsulates the results of a Diagnostics operation.
@@ -26,6 +32,18 @@ type DiagnosticsResult struct {
 	Services map[string][]EndPointDiagnostics
 	sdk      string
 	State    ClusterState
+
+	// ConfigRev is the revision of the cluster configuration currently held by the SDK. Endpoints which belong to a
+	// bucket report the revision of the bucket configuration in EndPointDiagnostics.ConfigRev.
+	// VOLATILE: This API is subject to change at any time.
+	ConfigRev int64
+
+	// ConfigUpdates is the number of changes of revision of the cluster configuration seen by the SDK since
+	// connecting, an increase between two reports indicates that the topology of the cluster changed, e.g. due to
+	// a rebalance. It is only counted whilst a TopologyConfig.ConfigChangeListener is configured, see
+	// ConfigChangeEvent.Updates for how changes are counted.
+	// VOLATILE: This API is subject to change at any time.
+	ConfigUpdates uint64
 }
 
 type jsonDiagnosticEntry struct {
@@ -36,6 +54,7 @@ type jsonDiagnosticEntry struct {
 	State          string `json:"state,omitempty"`
 	Details        string `json:"details,omitempty"`
 	Namespace      string `json:"namespace,omitempty"`
+	ConfigRev      int64  `json:"config_rev,omitempty"`
 }
 
 type jsonDiagnosticReport struct {
@@ -44,6 +63,9 @@ type jsonDiagnosticReport struct {
 	ID       string                           `json:"id,omitempty"`
 	Services map[string][]jsonDiagnosticEntry `json:"services"`
 	State    string                           `json:"state"`
+
+	ConfigRev     int64  `json:"config_rev"`
+	ConfigUpdates uint64 `json:"config_updates"`
 }
 
 // MarshalJSON generates a JSON representation of this diagnostics report.
@@ -54,6 +76,9 @@ func (report *DiagnosticsResult) MarshalJSON() ([]byte, error) {
 		ID:       report.ID,
 		Services: make(map[string][]jsonDiagnosticEntry),
 		State:    clusterStateToString(report.State),
+
+		ConfigRev:     report.ConfigRev,
+		ConfigUpdates: report.ConfigUpdates,
 	}
 
 	for _, serviceType := range report.Services {
@@ -69,6 +94,7 @@ func (report *DiagnosticsResult) MarshalJSON() ([]byte, error) {
 				State:          stateStr,
 				Details:        "",
 				Namespace:      service.Namespace,
+				ConfigRev:      service.ConfigRev,
 			})
 		}
 	}
@@ -106,13 +132,23 @@ func (c *Cluster) Diagnostics(opts *DiagnosticsOptions) (*DiagnosticsResult, err
 		Services: make(map[string][]EndPointDiagnostics),
 		sdk:      Identifier(),
 		State:    ClusterState(agentReport.State),
+
+		ConfigRev:     agentReport.ConfigRev,
+		ConfigUpdates: c.configWatcher.updateCount(),
 	}
 
 	report.Services["kv"] = make([]EndPointDiagnostics, 0)
 
+	configRevs := map[string]int64{"": agentReport.ConfigRev}
 	for _, conn := range agentReport.MemdConns {
 		state := EndpointState(conn.State)
 
+		configRev, ok := configRevs[conn.Scope]
+		if !ok {
+			configRev = c.bucketConfigRev(conn.Scope)
+			configRevs[conn.Scope] = configRev
+		}
+
 		report.Services["kv"] = append(report.Services["kv"], EndPointDiagnostics{
 			Type:         ServiceTypeKeyValue,
 			State:        state,
@@ -121,8 +157,46 @@ func (c *Cluster) Diagnostics(opts *DiagnosticsOptions) (*DiagnosticsResult, err
 			LastActivity: conn.LastActivity,
 			Namespace:    conn.Scope,
 			ID:           conn.ID,
+			ConfigRev:    configRev,
 		})
 	}
 
 	return report, nil
 }
+
+// bucketConfigRev returns the revision of the configuration held for the given bucket, or 0 if the bucket does not
+// have a configuration yet.
+func (c *Cluster) bucketConfigRev(bucketName string) int64 {
+	agent, err := c.connectionManager.getKvProvider(bucketName)
+	if err != nil {
+		logDebugf("Failed to get KV provider for bucket %s: %v", bucketName, err)
+		return 0
+	}
+
+	snapshot, err := agent.ConfigSnapshot()
+	if err != nil {
+		logDebugf("Failed to get config snapshot for bucket %s: %v", bucketName, err)
+		return 0
+	}
+
+	return snapshot.RevID()
+}
+
+// ConnectionsByNode returns the connections within the report grouped by the address of the node that they are to,
+// allowing the connections held open to each node to be inspected, e.g. when debugging connection usage. Endpoints
+// which are not currently connected to a node are grouped under an empty address.
+//
+// Only the connections to the key value service are reported, the connections to the HTTP based services are pooled
+// by the Go HTTP client and cannot be enumerated. The time at which each connection was opened is not known, the
+// LastActivity of each connection is reported instead.
+// VOLATILE: This API is subject to change at any time.
+func (report *DiagnosticsResult) ConnectionsByNode() map[string][]EndPointDiagnostics {
+	nodes := make(map[string][]EndPointDiagnostics)
+	for _, endpoints := range report.Services {
+		for _, endpoint := range endpoints {
+			nodes[endpoint.Remote] = append(nodes[endpoint.Remote], endpoint)
+		}
+	}
+
+	return nodes
+}
@@ -2,6 +2,7 @@ package gocb
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/stretchr/testify/mock"
@@ -48,6 +49,7 @@ func (suite *UnitTestSuite) TestDiagnostics() {
 
 	cli := new(mockConnectionManager)
 	cli.On("getDiagnosticsProvider", "").Return(provider, nil)
+	cli.On("getKvProvider", "bucket").Return(nil, errors.New("no bucket config"))
 
 	c := &Cluster{
 		connectionManager: cli,
@@ -183,3 +185,135 @@ func (suite *UnitTestSuite) TestDiagnosticsWithID() {
 		suite.T().Fatalf("Report ID should have been myreportid but was %s", report.ID)
 	}
 }
+
+func (suite *UnitTestSuite) TestDiagnosticsConnectionsByNode() {
+	info := &gocbcore.DiagnosticInfo{
+		ConfigRev: 1,
+		MemdConns: []gocbcore.MemdConnInfo{
+			{
+				LocalAddr:  "10.112.191.101:50001",
+				RemoteAddr: "10.112.191.102:11210",
+				Scope:      "bucket",
+				State:      gocbcore.EndpointStateConnected,
+				ID:         "0xc000094120",
+			},
+			{
+				LocalAddr:  "10.112.191.101:50002",
+				RemoteAddr: "10.112.191.102:11210",
+				Scope:      "bucket",
+				State:      gocbcore.EndpointStateConnected,
+				ID:         "0xc000094121",
+			},
+			{
+				LocalAddr:  "10.112.191.101:50003",
+				RemoteAddr: "10.112.191.103:11210",
+				Scope:      "bucket",
+				State:      gocbcore.EndpointStateConnected,
+				ID:         "0xc000094122",
+			},
+			{
+				State: gocbcore.EndpointStateDisconnected,
+			},
+		},
+	}
+
+	provider := new(mockDiagnosticsProvider)
+	provider.
+		On("Diagnostics", mock.AnythingOfType("gocbcore.DiagnosticsOptions")).
+		Return(info, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getDiagnosticsProvider", "").Return(provider, nil)
+	cli.On("getKvProvider", "bucket").Return(nil, errors.New("no bucket config"))
+
+	c := &Cluster{
+		connectionManager: cli,
+	}
+
+	report, err := c.Diagnostics(nil)
+	suite.Require().Nil(err, err)
+
+	nodes := report.ConnectionsByNode()
+	suite.Require().Len(nodes, 3)
+	suite.Require().Len(nodes["10.112.191.102:11210"], 2)
+	suite.Assert().Equal("0xc000094120", nodes["10.112.191.102:11210"][0].ID)
+	suite.Assert().Equal("0xc000094121", nodes["10.112.191.102:11210"][1].ID)
+	suite.Require().Len(nodes["10.112.191.103:11210"], 1)
+	suite.Assert().Equal(EndpointStateConnected, nodes["10.112.191.103:11210"][0].State)
+	suite.Require().Len(nodes[""], 1)
+	suite.Assert().Equal(EndpointStateDisconnected, nodes[""][0].State)
+}
+
+func (suite *UnitTestSuite) TestDiagnosticsConfigUpdates() {
+	revs := []int64{0, 10, 10, 12}
+	var calls int
+	provider := new(mockDiagnosticsProvider)
+	provider.
+		On("Diagnostics", mock.AnythingOfType("gocbcore.DiagnosticsOptions")).
+		Return(func(opts gocbcore.DiagnosticsOptions) *gocbcore.DiagnosticInfo {
+			rev := revs[len(revs)-1]
+			if calls < len(revs) {
+				rev = revs[calls]
+			}
+			calls++
+
+			return &gocbcore.DiagnosticInfo{
+				ConfigRev: rev,
+				MemdConns: []gocbcore.MemdConnInfo{
+					{RemoteAddr: "10.0.0.1:11210"},
+					{RemoteAddr: "10.0.0.1:11210", Scope: "default"},
+				},
+			}
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getDiagnosticsProvider", "").Return(provider, nil)
+	cli.On("getKvProvider", "default").Return(nil, errors.New("no bucket config"))
+
+	c := &Cluster{
+		connectionManager: cli,
+	}
+
+	// Updates are only counted once a listener has subscribed.
+	report, err := c.Diagnostics(nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Zero(report.ConfigUpdates)
+	calls = 0
+
+	eventCh := make(chan ConfigChangeEvent, len(revs))
+	c.configWatcher = newConfigWatcher(c, TopologyConfig{
+		ConfigChangeListener: func(event ConfigChangeEvent) {
+			eventCh <- event
+		},
+	})
+	go c.configWatcher.dispatch()
+	defer close(c.configWatcher.stopCh)
+	for range revs {
+		c.configWatcher.poll()
+	}
+
+	suite.Assert().Equal(ConfigChangeEvent{PreviousRevID: -1, RevID: 10, Updates: 1}, <-eventCh)
+	suite.Assert().Equal(ConfigChangeEvent{PreviousRevID: 10, RevID: 12, Updates: 2}, <-eventCh)
+
+	report, err = c.Diagnostics(nil)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal(int64(12), report.ConfigRev)
+	suite.Assert().Equal(uint64(2), report.ConfigUpdates)
+
+	// Cluster level endpoints use the cluster configuration, bucket configurations are looked up per bucket.
+	suite.Require().Len(report.Services["kv"], 2)
+	suite.Assert().Equal(int64(12), report.Services["kv"][0].ConfigRev)
+	suite.Assert().Zero(report.Services["kv"][1].ConfigRev)
+	cli.AssertNumberOfCalls(suite.T(), "getKvProvider", 2)
+
+	marshaled, err := json.Marshal(report)
+	suite.Require().Nil(err, err)
+
+	var jsonReport jsonDiagnosticReport
+	err = json.Unmarshal(marshaled, &jsonReport)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal(int64(12), jsonReport.ConfigRev)
+	suite.Assert().Equal(uint64(2), jsonReport.ConfigUpdates)
+}
@@ -0,0 +1,143 @@
+package gocb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	gocbconnstr "github.com/couchbase/gocbcore/v10/connstr"
+)
+
+// DNSSRVResolver looks up DNS SRV records, it is implemented by *net.Resolver.
+// UNCOMMITTED: This API may change in the future.
+type DNSSRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (cname string, addrs []*net.SRV, err error)
+}
+
+// DNSConfig specifies options for how the hostnames within the connection string are resolved.
+//
+// The SDK does not cache resolved addresses, every connection attempt resolves the hostname afresh, so after a DNS
+// based failover the SDK reconnects to the new address once the connection to the old address fails. Connections which
+// remain open are not closed when the address of their hostname changes.
+//
+// When the connection string contains a single hostname without a port, such as couchbases://cb.example.com, the
+// bootstrap hosts are first looked up from the _couchbases._tcp.cb.example.com DNS SRV record, or from
+// _couchbase._tcp.cb.example.com for the couchbase scheme. If there is no such record then the hostname is used as the
+// address of a node. A connection string which contains several hostnames without ports has each of them looked up,
+// and fails with ErrInvalidArgument if any has an SRV record, as explicit hosts cannot be mixed with an SRV record.
+// With the couchbases scheme the SRV record must not target the non-TLS KV port, 11210.
+//
+// The SRV record is only looked up by Connect, the SDK does not periodically look it up again. gocbcore cannot change
+// the bootstrap hosts of a running Cluster, and nodes added to or removed from the cluster are already discovered from
+// the cluster configuration, so the record only needs to point at a node which is in the cluster when connecting.
+// UNCOMMITTED: This API may change in the future.
+type DNSConfig struct {
+	// SRVResolver is used to look up DNS SRV records, allowing lookups to be stubbed out in tests. Defaults to
+	// net.DefaultResolver.
+	SRVResolver DNSSRVResolver
+
+	// RequireSRV causes Connect to fail if the bootstrap hosts cannot be looked up from a DNS SRV record, rather than
+	// falling back to using the hostname as the address of a node. The connection string must contain a single
+	// hostname without a port, a connection string which mixes the SRV hostname with explicit hosts or ports fails
+	// with ErrInvalidArgument.
+	RequireSRV bool
+}
+
+func (config DNSConfig) srvResolver() DNSSRVResolver {
+	if config.SRVResolver == nil {
+		return net.DefaultResolver
+	}
+
+	return config.SRVResolver
+}
+
+// resolveSRVConnSpec replaces the address of spec with the targets of its DNS SRV record, if it has one. If spec does
+// not refer to an SRV record, or the lookup fails and the record is not required, then spec is returned unchanged.
+func resolveSRVConnSpec(spec gocbconnstr.ConnSpec, config DNSConfig,
+	timeout time.Duration) (gocbconnstr.ConnSpec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	record := spec.SrvRecordName()
+	if record == "" {
+		if config.RequireSRV {
+			return spec, makeInvalidArgumentsError("connection string must contain a single hostname without a " +
+				"port when RequireSRV is enabled, explicit hosts cannot be mixed with a DNS SRV record")
+		}
+
+		if len(spec.Addresses) > 1 {
+			if mixed := findSRVRecord(ctx, spec, config); mixed != "" {
+				return spec, makeInvalidArgumentsError("explicit hosts cannot be mixed with the DNS SRV record " +
+					mixed + ", the connection string must contain only the SRV hostname")
+			}
+		}
+
+		return spec, nil
+	}
+
+	_, srvs, err := config.srvResolver().LookupSRV(ctx, "", "", record)
+	if err == nil && len(srvs) == 0 {
+		err = fmt.Errorf("no targets found for DNS SRV record %s", record)
+	}
+	if err != nil {
+		if config.RequireSRV {
+			return spec, wrapError(err, "failed to look up DNS SRV record "+record)
+		}
+
+		logDebugf("Failed to look up DNS SRV record %s, using %s as a node address: %v", record,
+			spec.Addresses[0].Host, err)
+		return spec, nil
+	}
+
+	resolved := spec
+	resolved.Addresses = srvAddresses(srvs)
+
+	// The SRV record decides which addresses are connected to, so it must not be able to downgrade a couchbases
+	// connection string to the non-TLS KV port.
+	if spec.Scheme == "couchbases" {
+		for _, address := range resolved.Addresses {
+			if address.Port == gocbconnstr.DefaultMemdPort {
+				return spec, makeInvalidArgumentsError(fmt.Sprintf("DNS SRV record %s targets the non-TLS port "+
+					"%d of %s, which cannot be used with the couchbases scheme", record, address.Port, address.Host))
+			}
+		}
+	}
+
+	logDebugf("Resolved DNS SRV record %s to %v", record, resolved.Addresses)
+	return resolved, nil
+}
+
+// findSRVRecord returns the name of the first DNS SRV record found for the hostnames of spec which have no port, or
+// an empty string if there is none.
+func findSRVRecord(ctx context.Context, spec gocbconnstr.ConnSpec, config DNSConfig) string {
+	for _, address := range spec.Addresses {
+		single := spec
+		single.Addresses = []gocbconnstr.Address{address}
+
+		record := single.SrvRecordName()
+		if record == "" {
+			continue
+		}
+
+		_, srvs, err := config.srvResolver().LookupSRV(ctx, "", "", record)
+		if err == nil && len(srvs) > 0 {
+			return record
+		}
+	}
+
+	return ""
+}
+
+func srvAddresses(srvs []*net.SRV) []gocbconnstr.Address {
+	addresses := make([]gocbconnstr.Address, len(srvs))
+	for i, srv := range srvs {
+		addresses[i] = gocbconnstr.Address{
+			Host: strings.TrimSuffix(srv.Target, "."),
+			Port: int(srv.Port),
+		}
+	}
+
+	return addresses
+}
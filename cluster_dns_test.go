@@ -0,0 +1,155 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	gocbconnstr "github.com/couchbase/gocbcore/v10/connstr"
+)
+
+type testSRVResolver struct {
+	records map[string][]*net.SRV
+	lookups []string
+}
+
+func (r *testSRVResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	r.lookups = append(r.lookups, name)
+	srvs, ok := r.records[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	return name, srvs, nil
+}
+
+func (suite *UnitTestSuite) TestResolveSRVConnSpec() {
+	resolver := &testSRVResolver{
+		records: map[string][]*net.SRV{
+			"_couchbases._tcp.cb.example.com": {
+				{Target: "node1.example.com.", Port: 11207},
+				{Target: "node2.example.com.", Port: 11207},
+			},
+		},
+	}
+	config := DNSConfig{SRVResolver: resolver}
+
+	spec, err := gocbconnstr.Parse("couchbases://cb.example.com/default")
+	suite.Require().Nil(err, err)
+
+	resolved, err := resolveSRVConnSpec(spec, config, time.Second)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("couchbases", resolved.Scheme)
+	suite.Assert().Equal("default", resolved.Bucket)
+	suite.Assert().Equal([]gocbconnstr.Address{
+		{Host: "node1.example.com", Port: 11207},
+		{Host: "node2.example.com", Port: 11207},
+	}, resolved.Addresses)
+
+	// Without an SRV record the hostname is used as a node address, unless the record is required.
+	spec, err = gocbconnstr.Parse("couchbase://node1.example.com")
+	suite.Require().Nil(err, err)
+
+	resolved, err = resolveSRVConnSpec(spec, config, time.Second)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(spec.Addresses, resolved.Addresses)
+
+	config.RequireSRV = true
+	_, err = resolveSRVConnSpec(spec, config, time.Second)
+	suite.Assert().NotNil(err)
+	suite.Assert().Equal([]string{"_couchbase._tcp.node1.example.com", "_couchbase._tcp.node1.example.com"},
+		resolver.lookups)
+
+	// Explicit hosts and ports are never looked up as SRV records.
+	mixed := []string{
+		"couchbases://cb.example.com,node1.example.com",
+		"couchbases://cb.example.com:11207",
+	}
+	for _, connStr := range mixed {
+		resolver.lookups = nil
+		_, err = Connect(connStr, ClusterOptions{
+			DNSConfig: config,
+		})
+		if !errors.Is(err, ErrInvalidArgument) {
+			suite.T().Fatalf("Expected invalid argument error for %s but was %v", connStr, err)
+		}
+		suite.Assert().Empty(resolver.lookups)
+	}
+}
+
+func (suite *UnitTestSuite) TestResolveSRVConnSpecMixedHosts() {
+	resolver := &testSRVResolver{
+		records: map[string][]*net.SRV{
+			"_couchbases._tcp.cb.example.com": {
+				{Target: "node1.example.com.", Port: 11207},
+			},
+		},
+	}
+	config := DNSConfig{SRVResolver: resolver}
+
+	// An SRV hostname mixed with explicit hosts is rejected even when the SRV record is not required.
+	spec, err := gocbconnstr.Parse("couchbases://node1.example.com,cb.example.com")
+	suite.Require().Nil(err, err)
+
+	_, err = resolveSRVConnSpec(spec, config, time.Second)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+	suite.Assert().Equal([]string{"_couchbases._tcp.node1.example.com", "_couchbases._tcp.cb.example.com"},
+		resolver.lookups)
+
+	// Hosts without SRV records, and hosts with explicit ports, are used as node addresses.
+	connStrs := []string{
+		"couchbases://node1.example.com,node2.example.com",
+		"couchbases://node1.example.com:11207,cb.example.com:11207",
+		"couchbase://cb.example.com,node1.example.com",
+	}
+	for _, connStr := range connStrs {
+		spec, err = gocbconnstr.Parse(connStr)
+		suite.Require().Nil(err, err)
+
+		resolved, err := resolveSRVConnSpec(spec, config, time.Second)
+		suite.Require().Nil(err, err)
+		suite.Assert().Equal(spec.Addresses, resolved.Addresses)
+	}
+}
+
+func (suite *UnitTestSuite) TestResolveSRVConnSpecTLS() {
+	resolver := &testSRVResolver{
+		records: map[string][]*net.SRV{
+			"_couchbases._tcp.cb.example.com": {
+				{Target: "node1.example.com.", Port: 11207},
+			},
+			"_couchbases._tcp.plain.example.com": {
+				{Target: "node1.example.com.", Port: 11207},
+				{Target: "node2.example.com.", Port: 11210},
+			},
+		},
+	}
+	config := DNSConfig{SRVResolver: resolver}
+
+	spec, err := gocbconnstr.Parse("couchbases://plain.example.com")
+	suite.Require().Nil(err, err)
+
+	_, err = resolveSRVConnSpec(spec, config, time.Second)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	spec, err = gocbconnstr.Parse("couchbases://cb.example.com")
+	suite.Require().Nil(err, err)
+
+	resolved, err := resolveSRVConnSpec(spec, config, time.Second)
+	suite.Require().Nil(err, err)
+
+	// The targets of the SRV record must still be connected to using TLS.
+	cluster := clusterFromOptions(ClusterOptions{})
+	defer tracerDecRef(cluster.tracer)
+	cluster.cSpec = resolved
+
+	mgr := newConnectionMgr()
+	err = mgr.buildConfig(cluster)
+	suite.Require().Nil(err, err)
+	suite.Assert().True(mgr.config.SecurityConfig.UseTLS)
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,9 +17,10 @@ import (
 // versions but that is not tested and is not supported.
 // Volatile: This API is subject to change at any time.
 type EventingFunctionManager struct {
-	mgmtProvider mgmtProvider
-	tracer       RequestTracer
-	meter        *meterWrapper
+	mgmtProvider  mgmtProvider
+	globalTimeout time.Duration
+	tracer        RequestTracer
+	meter         *meterWrapper
 }
 
 func (efm *EventingFunctionManager) doMgmtRequest(ctx context.Context, req mgmtRequest) (*mgmtResponse, error) {
@@ -652,6 +655,23 @@ type eventingRequestOptions struct {
 
 func (efm *EventingFunctionManager) doRequest(path string, method string, opName string, function *EventingFunction,
 	target interface{}, opts eventingRequestOptions) error {
+	b, err := efm.doRawRequest(path, method, opName, function, opts)
+	if err != nil {
+		return err
+	}
+
+	if target != nil {
+		err = json.Unmarshal(b, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (efm *EventingFunctionManager) doRawRequest(path string, method string, opName string, function *EventingFunction,
+	opts eventingRequestOptions) ([]byte, error) {
 	start := time.Now()
 	defer efm.meter.ValueRecord(meterValueServiceManagement, opName, start)
 
@@ -665,7 +685,7 @@ func (efm *EventingFunctionManager) doRequest(path string, method string, opName
 		var err error
 		b, err = json.Marshal(function)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -680,28 +700,82 @@ func (efm *EventingFunctionManager) doRequest(path string, method string, opName
 	}
 	resp, err := efm.doMgmtRequest(opts.Context, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer ensureBodyClosed(resp.Body)
 
 	if resp.StatusCode != 200 {
 		idxErr := efm.tryParseErrorMessage(&req, resp)
 		if idxErr != nil {
-			return idxErr
+			return nil, idxErr
 		}
 
-		return makeMgmtBadStatusError("failed eventing "+opName, &req, resp)
+		return nil, makeMgmtBadStatusError("failed eventing "+opName, &req, resp)
 	}
 
-	if target != nil {
-		jsonDec := json.NewDecoder(resp.Body)
-		err = jsonDec.Decode(target)
+	return ioutil.ReadAll(resp.Body)
+}
+
+const defaultEventingFunctionStatusPollInterval = 500 * time.Millisecond
+
+// waitForFunctionStatus polls FunctionsStatus until the named function reaches the given status, or the deadline
+// passes.
+func (efm *EventingFunctionManager) waitForFunctionStatus(name string, status EventingFunctionStatus,
+	deadline time.Time, pollInterval time.Duration, opts eventingRequestOptions) error {
+	if pollInterval == 0 {
+		pollInterval = defaultEventingFunctionStatusPollInterval
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return ErrUnambiguousTimeout
+		}
+
+		functions, err := efm.FunctionsStatus(&EventingFunctionsStatusOptions{
+			Timeout:       remaining,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
 		if err != nil {
 			return err
 		}
-	}
 
-	return nil
+		var found bool
+		for _, fn := range functions.Functions {
+			if fn.Name != name {
+				continue
+			}
+
+			found = true
+			if fn.Status == status {
+				return nil
+			}
+			logDebugf("Eventing function %s has status %s, waiting for %s", name, fn.Status, status)
+		}
+		if !found {
+			return ErrEventingFunctionNotFound
+		}
+
+		// Make sure we don't sleep past our overall deadline, if we do then it will be caught at the top of this
+		// loop as a timeout.
+		sleepDeadline := time.Now().Add(pollInterval)
+		if sleepDeadline.After(deadline) {
+			sleepDeadline = deadline
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(sleepDeadline)):
+		}
+	}
 }
 
 // UpsertEventingFunctionOptions are the options available when using the UpsertFunction operation.
@@ -760,6 +834,16 @@ func (efm *EventingFunctionManager) DropFunction(name string, opts *DropEventing
 
 // DeployEventingFunctionOptions are the options available when using the DeployFunction operation.
 type DeployEventingFunctionOptions struct {
+	// WaitUntilReady causes DeployFunction to block until the function is reported as deployed by FunctionsStatus. The
+	// Timeout applies to the operation as a whole, including the wait.
+	// UNCOMMITTED: This API may change in the future.
+	WaitUntilReady bool
+
+	// PollInterval is how long to wait between each check of the function status when WaitUntilReady is set,
+	// defaults to 500ms.
+	// UNCOMMITTED: This API may change in the future.
+	PollInterval time.Duration
+
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
@@ -776,17 +860,47 @@ func (efm *EventingFunctionManager) DeployFunction(name string, opts *DeployEven
 		opts = &DeployEventingFunctionOptions{}
 	}
 
-	return efm.doRequest(fmt.Sprintf("/api/v1/functions/%s/deploy", name), "POST",
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = efm.globalTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	err := efm.doRequest(fmt.Sprintf("/api/v1/functions/%s/deploy", name), "POST",
 		"deploy_function", nil, nil, eventingRequestOptions{
 			Timeout:       opts.Timeout,
 			RetryStrategy: opts.RetryStrategy,
 			ParentSpan:    opts.ParentSpan,
 			Context:       opts.Context,
 		})
+	if err != nil {
+		return err
+	}
+
+	if !opts.WaitUntilReady {
+		return nil
+	}
+
+	return efm.waitForFunctionStatus(name, EventingFunctionStateDeployed, deadline, opts.PollInterval,
+		eventingRequestOptions{
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
 }
 
 // UndeployEventingFunctionOptions are the options available when using the UndeployFunction operation.
 type UndeployEventingFunctionOptions struct {
+	// WaitUntilReady causes UndeployFunction to block until the function is reported as undeployed by FunctionsStatus. The
+	// Timeout applies to the operation as a whole, including the wait.
+	// UNCOMMITTED: This API may change in the future.
+	WaitUntilReady bool
+
+	// PollInterval is how long to wait between each check of the function status when WaitUntilReady is set,
+	// defaults to 500ms.
+	// UNCOMMITTED: This API may change in the future.
+	PollInterval time.Duration
+
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
@@ -803,13 +917,33 @@ func (efm *EventingFunctionManager) UndeployFunction(name string, opts *Undeploy
 		opts = &UndeployEventingFunctionOptions{}
 	}
 
-	return efm.doRequest(fmt.Sprintf("/api/v1/functions/%s/undeploy", name), "POST",
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = efm.globalTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	err := efm.doRequest(fmt.Sprintf("/api/v1/functions/%s/undeploy", name), "POST",
 		"undeploy_function", nil, nil, eventingRequestOptions{
 			Timeout:       opts.Timeout,
 			RetryStrategy: opts.RetryStrategy,
 			ParentSpan:    opts.ParentSpan,
 			Context:       opts.Context,
 		})
+	if err != nil {
+		return err
+	}
+
+	if !opts.WaitUntilReady {
+		return nil
+	}
+
+	return efm.waitForFunctionStatus(name, EventingFunctionStateUndeployed, deadline, opts.PollInterval,
+		eventingRequestOptions{
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
 }
 
 // GetAllEventingFunctionsOptions are the options available when using the GetAllFunctions operation.
@@ -964,3 +1098,92 @@ func (efm *EventingFunctionManager) FunctionsStatus(opts *EventingFunctionsStatu
 
 	return functions, nil
 }
+
+// GetEventingFunctionLogsOptions are the options available when using the GetFunctionLogs operation.
+type GetEventingFunctionLogsOptions struct {
+	// Size is the maximum number of bytes of the end of the log to fetch, defaults to the server default.
+	Size int
+	// Aggregate fetches the logs from every eventing node rather than only the node which handles the request.
+	Aggregate bool
+
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// GetFunctionLogs fetches the tail of the application log of a deployed eventing function, one entry per line.
+// Returns ErrEventingFunctionNotDeployed if the function is not deployed.
+func (efm *EventingFunctionManager) GetFunctionLogs(name string, opts *GetEventingFunctionLogsOptions) ([]string, error) {
+	if opts == nil {
+		opts = &GetEventingFunctionLogsOptions{}
+	}
+
+	if opts.Size < 0 {
+		return nil, makeInvalidArgumentsError("size cannot be negative")
+	}
+
+	query := url.Values{}
+	query.Set("name", name)
+	query.Set("aggregate", strconv.FormatBool(opts.Aggregate))
+	if opts.Size > 0 {
+		query.Set("size", strconv.Itoa(opts.Size))
+	}
+
+	b, err := efm.doRawRequest("/getAppLog?"+query.Encode(), "GET",
+		"get_function_logs", nil, eventingRequestOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// ResetEventingFunctionStatsOptions are the options available when using the ResetFunctionStats operation.
+type ResetEventingFunctionStatsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// ResetFunctionStats resets the processing statistics counters of a deployed eventing function.
+// Returns ErrEventingFunctionNotDeployed if the function is not deployed.
+func (efm *EventingFunctionManager) ResetFunctionStats(name string, opts *ResetEventingFunctionStatsOptions) error {
+	if opts == nil {
+		opts = &ResetEventingFunctionStatsOptions{}
+	}
+
+	query := url.Values{}
+	query.Set("appName", name)
+
+	return efm.doRequest("/resetStatsCounters?"+query.Encode(), "GET",
+		"reset_function_stats", nil, nil, eventingRequestOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+}
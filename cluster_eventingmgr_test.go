@@ -1,10 +1,15 @@
 package gocb
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"github.com/google/uuid"
+	"io/ioutil"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
 )
 
 func (suite *IntegrationTestSuite) TestEventingManagerUpsertGetDrop() {
@@ -599,3 +604,180 @@ func (suite *IntegrationTestSuite) mustWaitForCollections(scopeName string, coll
 	})
 	suite.Require().True(success, "Collections did not come online in time")
 }
+
+func (suite *UnitTestSuite) TestEventingManagerGetFunctionLogs() {
+	resp := &mgmtResponse{
+		Endpoint:   "http://localhost:8096",
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte("first line\nsecond line\r\n\n"))),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/getAppLog?aggregate=true&name=myfn&size=1024", req.Path)
+			suite.Assert().Equal(ServiceTypeEventing, req.Service)
+			suite.Assert().Equal("GET", req.Method)
+		}).
+		Return(resp, nil)
+
+	mgr := EventingFunctionManager{
+		mgmtProvider: mockProvider,
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	lines, err := mgr.GetFunctionLogs("myfn", &GetEventingFunctionLogsOptions{
+		Size:      1024,
+		Aggregate: true,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]string{"first line", "second line"}, lines)
+}
+
+func (suite *UnitTestSuite) TestEventingManagerResetFunctionStatsNotDeployed() {
+	retErr := `{"name":"ERR_APP_NOT_DEPLOYED","code":20,"description":"Function: myfn not deployed"}`
+	resp := &mgmtResponse{
+		Endpoint:   "http://localhost:8096",
+		StatusCode: 406,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(retErr))),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/resetStatsCounters?appName=myfn", req.Path)
+			suite.Assert().Equal(ServiceTypeEventing, req.Service)
+			suite.Assert().Equal("GET", req.Method)
+		}).
+		Return(resp, nil)
+
+	mgr := EventingFunctionManager{
+		mgmtProvider: mockProvider,
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := mgr.ResetFunctionStats("myfn", nil)
+	if !errors.Is(err, ErrEventingFunctionNotDeployed) {
+		suite.T().Fatalf("Expected function not deployed but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestEventingManagerDeployFunctionWaitUntilReady() {
+	statuses := []string{"deploying", "deployed"}
+	var paths []string
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			paths = append(paths, req.Method+" "+req.Path)
+
+			body := ""
+			if req.Path == "/api/v1/status" {
+				body = fmt.Sprintf(`{"apps":[{"name":"myfn","composite_status":"%s"}],"num_eventing_nodes":1}`,
+					statuses[0])
+				statuses = statuses[1:]
+			}
+			return &mgmtResponse{
+				Endpoint:   "http://localhost:8096",
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}
+		}, nil)
+
+	mgr := EventingFunctionManager{
+		mgmtProvider:  mockProvider,
+		globalTimeout: 10 * time.Second,
+		tracer:        &NoopTracer{},
+		meter:         &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := mgr.DeployFunction("myfn", &DeployEventingFunctionOptions{
+		WaitUntilReady: true,
+		PollInterval:   time.Millisecond,
+	})
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal([]string{
+		"POST /api/v1/functions/myfn/deploy",
+		"GET /api/v1/status",
+		"GET /api/v1/status",
+	}, paths)
+}
+
+func (suite *UnitTestSuite) TestEventingManagerUndeployFunctionWaitUntilReadyTimeout() {
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			body := ""
+			if req.Path == "/api/v1/status" {
+				body = `{"apps":[{"name":"myfn","composite_status":"undeploying"}],"num_eventing_nodes":1}`
+			}
+			return &mgmtResponse{
+				Endpoint:   "http://localhost:8096",
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}
+		}, nil)
+
+	mgr := EventingFunctionManager{
+		mgmtProvider: mockProvider,
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := mgr.UndeployFunction("myfn", &UndeployEventingFunctionOptions{
+		WaitUntilReady: true,
+		PollInterval:   10 * time.Millisecond,
+		Timeout:        100 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrTimeout) {
+		suite.T().Fatalf("Expected error to be timeout but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestEventingManagerUndeployFunctionWaitUntilReadyContextDone() {
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", mock.Anything, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			body := ""
+			if req.Path == "/api/v1/status" {
+				body = `{"apps":[{"name":"myfn","composite_status":"undeploying"}],"num_eventing_nodes":1}`
+			}
+			return &mgmtResponse{
+				Endpoint:   "http://localhost:8096",
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}
+		}, nil)
+
+	mgr := EventingFunctionManager{
+		mgmtProvider: mockProvider,
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := mgr.UndeployFunction("myfn", &UndeployEventingFunctionOptions{
+		WaitUntilReady: true,
+		PollInterval:   10 * time.Second,
+		Timeout:        20 * time.Second,
+		Context:        ctx,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		suite.T().Fatalf("Expected error to be context deadline exceeded but was %v", err)
+	}
+	suite.Assert().Less(int64(time.Since(start)), int64(5*time.Second))
+}
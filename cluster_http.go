@@ -0,0 +1,118 @@
+package gocb
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ManagementRequest is a raw HTTP request to be sent to a Couchbase service, allowing endpoints which the SDK does
+// not yet model to be used. The request is sent using the same connections as the rest of the SDK, with node
+// selection, authentication, TLS and retries handled in the same way as for any other request to the service.
+// UNCOMMITTED: This API may change in the future.
+type ManagementRequest struct {
+	// Method is the HTTP method of the request, e.g. "GET".
+	Method string
+
+	// Path is the path of the request, including any query string, e.g. "/settings/querySettings".
+	Path string
+
+	Body        []byte
+	ContentType string
+	Headers     map[string]string
+
+	// IsIdempotent specifies whether the request can safely be sent more than once, which allows it to be retried.
+	IsIdempotent bool
+
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// ManagementResponse is the response to a ManagementRequest.
+// Responses are returned for any status code, it is up to the caller to check StatusCode. Body streams the response
+// from the server and must always be closed.
+// UNCOMMITTED: This API may change in the future.
+type ManagementResponse struct {
+	// Endpoint is the address of the node which the request was sent to.
+	Endpoint   string
+	StatusCode uint32
+	Body       io.ReadCloser
+}
+
+// ManagementHTTP sends a raw HTTP request to the management service of the cluster.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) ManagementHTTP(req ManagementRequest) (*ManagementResponse, error) {
+	return doRawHTTPRequest(c, c.tracer, c.meter, ServiceTypeManagement, meterValueServiceManagement,
+		"manager_http_request", c.timeoutsConfig.ManagementTimeout, req)
+}
+
+// QueryHTTP sends a raw HTTP request to the query service of the cluster.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) QueryHTTP(req ManagementRequest) (*ManagementResponse, error) {
+	return doRawHTTPRequest(c, c.tracer, c.meter, ServiceTypeQuery, meterValueServiceQuery,
+		"query_http_request", c.timeoutsConfig.QueryTimeout, req)
+}
+
+// SearchHTTP sends a raw HTTP request to the search service, using the connections of the bucket that this scope
+// belongs to.
+// UNCOMMITTED: This API may change in the future.
+func (s *Scope) SearchHTTP(req ManagementRequest) (*ManagementResponse, error) {
+	return doRawHTTPRequest(s.bucket, s.tracer, s.meter, ServiceTypeSearch, meterValueServiceSearch,
+		"search_http_request", s.timeoutsConfig.SearchTimeout, req)
+}
+
+func doRawHTTPRequest(provider mgmtProvider, tracer RequestTracer, meter *meterWrapper, service ServiceType,
+	meterService, operationName string, defaultTimeout time.Duration, req ManagementRequest) (*ManagementResponse, error) {
+	if req.Method == "" {
+		return nil, makeInvalidArgumentsError("method cannot be empty")
+	}
+	if !strings.HasPrefix(req.Path, "/") {
+		return nil, makeInvalidArgumentsError("path must begin with /")
+	}
+
+	start := time.Now()
+	defer meter.ValueRecord(meterService, operationName, start)
+
+	span := createSpan(tracer, req.ParentSpan, operationName, meterService)
+	span.SetAttribute("db.operation", req.Method+" "+req.Path)
+	defer span.End()
+
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	mgmtReq := mgmtRequest{
+		Service:       service,
+		Method:        req.Method,
+		Path:          req.Path,
+		Body:          req.Body,
+		Headers:       req.Headers,
+		ContentType:   req.ContentType,
+		IsIdempotent:  req.IsIdempotent,
+		UniqueID:      uuid.New().String(),
+		Timeout:       timeout,
+		RetryStrategy: req.RetryStrategy,
+		parentSpanCtx: span.Context(),
+	}
+
+	resp, err := provider.executeMgmtRequest(req.Context, mgmtReq)
+	if err != nil {
+		return nil, makeGenericMgmtError(err, &mgmtReq, resp, "")
+	}
+
+	return &ManagementResponse{
+		Endpoint:   resp.Endpoint,
+		StatusCode: resp.StatusCode,
+		Body:       resp.Body,
+	}, nil
+}
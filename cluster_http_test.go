@@ -0,0 +1,64 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestClusterManagementHTTP() {
+	httpProvider := new(mockHttpProvider)
+	httpProvider.
+		On("DoHTTPRequest", nil, mock.AnythingOfType("*gocbcore.HTTPRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(*gocbcore.HTTPRequest)
+
+			suite.Assert().Equal(gocbcore.MgmtService, req.Service)
+			suite.Assert().Equal("POST", req.Method)
+			suite.Assert().Equal("/settings/new", req.Path)
+			suite.Assert().Equal("a=b", string(req.Body))
+			suite.Assert().Equal("application/x-www-form-urlencoded", req.ContentType)
+			suite.Assert().False(req.IsIdempotent)
+			suite.Assert().WithinDuration(time.Now().Add(5*time.Second), req.Deadline, time.Second)
+		}).
+		Return(&gocbcore.HTTPResponse{
+			Endpoint:   "http://localhost:8091",
+			StatusCode: 404,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`not found`))),
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getHTTPProvider", "").Return(httpProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	resp, err := cluster.ManagementHTTP(ManagementRequest{
+		Method:      "POST",
+		Path:        "/settings/new",
+		Body:        []byte("a=b"),
+		ContentType: "application/x-www-form-urlencoded",
+		Timeout:     5 * time.Second,
+	})
+	suite.Require().Nil(err, err)
+	defer ensureBodyClosed(resp.Body)
+
+	suite.Assert().Equal(uint32(404), resp.StatusCode)
+	suite.Assert().Equal("http://localhost:8091", resp.Endpoint)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("not found", string(body))
+
+	_, err = cluster.QueryHTTP(ManagementRequest{
+		Method: "GET",
+		Path:   "admin/settings",
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+	httpProvider.AssertNumberOfCalls(suite.T(), "DoHTTPRequest", 1)
+}
@@ -0,0 +1,110 @@
+package gocb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keyspace identifies a collection by the names of the bucket, scope and collection which contain it.
+// UNCOMMITTED: This API may change in the future.
+type Keyspace struct {
+	BucketName     string
+	ScopeName      string
+	CollectionName string
+}
+
+// String returns the fully qualified name of the keyspace in the form "bucket.scope.collection". The bucket name is
+// escaped with backticks if it contains a period.
+func (k Keyspace) String() string {
+	bucketName := k.BucketName
+	if strings.Contains(bucketName, ".") {
+		bucketName = "`" + bucketName + "`"
+	}
+
+	return bucketName + "." + k.ScopeName + "." + k.CollectionName
+}
+
+// ParseKeyspace parses a fully qualified keyspace of the form "bucket.scope.collection". A keyspace consisting of
+// only a bucket name refers to the default collection of that bucket. Any part of the keyspace may be escaped with
+// backticks, which is required for bucket names which contain a period, e.g. "`my.bucket`.inventory.airline".
+// UNCOMMITTED: This API may change in the future.
+func ParseKeyspace(keyspace string) (Keyspace, error) {
+	parts, err := splitKeyspace(keyspace)
+	if err != nil {
+		return Keyspace{}, err
+	}
+
+	switch len(parts) {
+	case 1:
+		return Keyspace{
+			BucketName:     parts[0],
+			ScopeName:      "_default",
+			CollectionName: "_default",
+		}, nil
+	case 3:
+		return Keyspace{
+			BucketName:     parts[0],
+			ScopeName:      parts[1],
+			CollectionName: parts[2],
+		}, nil
+	}
+
+	return Keyspace{}, makeInvalidArgumentsError(
+		fmt.Sprintf("keyspace %q must be of the form bucket or bucket.scope.collection", keyspace))
+}
+
+func splitKeyspace(keyspace string) ([]string, error) {
+	var parts []string
+	remaining := keyspace
+	for {
+		var part string
+		if strings.HasPrefix(remaining, "`") {
+			endIdx := strings.Index(remaining[1:], "`")
+			if endIdx < 0 {
+				return nil, makeInvalidArgumentsError(fmt.Sprintf("keyspace %q has an unterminated backtick", keyspace))
+			}
+
+			part = remaining[1 : endIdx+1]
+			remaining = remaining[endIdx+2:]
+			if remaining != "" && !strings.HasPrefix(remaining, ".") {
+				return nil, makeInvalidArgumentsError(
+					fmt.Sprintf("keyspace %q has unexpected characters after a backtick", keyspace))
+			}
+		} else {
+			sepIdx := strings.Index(remaining, ".")
+			if sepIdx < 0 {
+				sepIdx = len(remaining)
+			}
+
+			part = remaining[:sepIdx]
+			remaining = remaining[sepIdx:]
+		}
+
+		if part == "" {
+			return nil, makeInvalidArgumentsError(fmt.Sprintf("keyspace %q contains an empty name", keyspace))
+		}
+		parts = append(parts, part)
+
+		if remaining == "" {
+			return parts, nil
+		}
+
+		// Skip the separator.
+		remaining = remaining[1:]
+		if remaining == "" {
+			return nil, makeInvalidArgumentsError(fmt.Sprintf("keyspace %q contains an empty name", keyspace))
+		}
+	}
+}
+
+// Collection returns the collection identified by a fully qualified keyspace, opening its bucket. See ParseKeyspace
+// for the keyspaces which are accepted.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) Collection(keyspace string) (*Collection, error) {
+	ks, err := ParseKeyspace(keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Bucket(ks.BucketName).Scope(ks.ScopeName).Collection(ks.CollectionName), nil
+}
@@ -0,0 +1,55 @@
+package gocb
+
+import "errors"
+
+func (suite *UnitTestSuite) TestParseKeyspace() {
+	type tCase struct {
+		keyspace string
+		expected Keyspace
+	}
+
+	validCases := []tCase{
+		{"travel-sample", Keyspace{"travel-sample", "_default", "_default"}},
+		{"travel-sample.inventory.airline", Keyspace{"travel-sample", "inventory", "airline"}},
+		{"`my.bucket`.inventory.airline", Keyspace{"my.bucket", "inventory", "airline"}},
+		{"`my.bucket`", Keyspace{"my.bucket", "_default", "_default"}},
+		{"bucket.`inventory`.`airline`", Keyspace{"bucket", "inventory", "airline"}},
+	}
+	for _, tc := range validCases {
+		ks, err := ParseKeyspace(tc.keyspace)
+		suite.Require().Nil(err, err)
+		suite.Assert().Equal(tc.expected, ks, tc.keyspace)
+	}
+
+	invalidKeyspaces := []string{
+		"",
+		"bucket.collection",
+		"bucket.scope.collection.extra",
+		"bucket..collection",
+		"bucket.scope.",
+		"`my.bucket",
+		"`my.bucket`x.scope.collection",
+	}
+	for _, keyspace := range invalidKeyspaces {
+		_, err := ParseKeyspace(keyspace)
+		suite.Assert().True(errors.Is(err, ErrInvalidArgument), keyspace)
+	}
+
+	suite.Assert().Equal("`my.bucket`.inventory.airline", Keyspace{"my.bucket", "inventory", "airline"}.String())
+	suite.Assert().Equal("bucket._default._default", Keyspace{"bucket", "_default", "_default"}.String())
+}
+
+func (suite *UnitTestSuite) TestClusterCollection() {
+	cli := new(mockConnectionManager)
+	cli.On("openBucket", "travel-sample").Return(nil)
+	cluster := suite.newCluster(cli)
+
+	col, err := cluster.Collection("travel-sample.inventory.airline")
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("travel-sample", col.Bucket().Name())
+	suite.Assert().Equal("inventory", col.ScopeName())
+	suite.Assert().Equal("airline", col.Name())
+
+	_, err = cluster.Collection("travel-sample.airline")
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument))
+}
@@ -2,6 +2,7 @@ package gocb
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/couchbase/gocbcore/v10"
@@ -31,11 +32,17 @@ func (c *Cluster) Ping(opts *PingOptions) (*PingResult, error) {
 
 func ping(ctx context.Context, provider diagnosticsProvider, opts *PingOptions, timeouts TimeoutsConfig,
 	parentSpan RequestSpan) (*PingResult, error) {
-	services := opts.ServiceTypes
-
-	gocbcoreServices := make([]gocbcore.ServiceType, len(services))
-	for i, svc := range services {
-		gocbcoreServices[i] = gocbcore.ServiceType(svc)
+	requested := make(map[ServiceType]struct{}, len(opts.ServiceTypes))
+	gocbcoreServices := make([]gocbcore.ServiceType, 0, len(opts.ServiceTypes))
+	for _, svc := range opts.ServiceTypes {
+		if !isPingableService(svc) {
+			return nil, makeInvalidArgumentsError("service type " + strconv.Itoa(int(svc)) + " cannot be pinged")
+		}
+		if _, ok := requested[svc]; ok {
+			continue
+		}
+		requested[svc] = struct{}{}
+		gocbcoreServices = append(gocbcoreServices, gocbcore.ServiceType(svc))
 	}
 
 	coreopts := gocbcore.PingOptions{
@@ -73,17 +80,24 @@ func ping(ctx context.Context, provider diagnosticsProvider, opts *PingOptions,
 	reportSvcs := make(map[ServiceType][]EndpointPingReport)
 	for svcType, svc := range result.Services {
 		st := ServiceType(svcType)
+		if _, ok := requested[st]; len(requested) > 0 && !ok {
+			continue
+		}
 
 		svcs := make([]EndpointPingReport, len(svc))
 		for i, rep := range svc {
+			state := PingState(rep.State)
 			var errStr string
 			if rep.Error != nil {
 				errStr = rep.Error.Error()
+				if state == PingStateOk {
+					state = PingStateError
+				}
 			}
 			svcs[i] = EndpointPingReport{
 				ID:        rep.ID,
 				Remote:    rep.Endpoint,
-				State:     PingState(rep.State),
+				State:     state,
 				Error:     errStr,
 				Namespace: rep.Scope,
 				Latency:   rep.Latency,
@@ -94,8 +108,18 @@ func ping(ctx context.Context, provider diagnosticsProvider, opts *PingOptions,
 	}
 
 	return &PingResult{
-		ID:       id,
-		sdk:      Identifier() + " " + "gocbcore/" + gocbcore.Version(),
-		Services: reportSvcs,
+		ID:        id,
+		sdk:       Identifier() + " " + "gocbcore/" + gocbcore.Version(),
+		configRev: result.ConfigRev,
+		Services:  reportSvcs,
 	}, nil
 }
+
+func isPingableService(svc ServiceType) bool {
+	switch svc {
+	case ServiceTypeKeyValue, ServiceTypeQuery, ServiceTypeSearch, ServiceTypeAnalytics, ServiceTypeViews,
+		ServiceTypeManagement:
+		return true
+	}
+	return false
+}
@@ -234,3 +234,77 @@ func (suite *UnitTestSuite) TestClusterPingOne() {
 		suite.Assert().Equal(expectedService.ID, service.ID)
 	}
 }
+
+func (suite *UnitTestSuite) TestClusterPingServiceTypesAndJSON() {
+	pingResult := &gocbcore.PingResult{
+		ConfigRev: 64,
+		Services: map[gocbcore.ServiceType][]gocbcore.EndpointPingResult{
+			gocbcore.N1qlService: {
+				{
+					ID:       "0x1",
+					Endpoint: "http://10.0.0.1:8093",
+					Latency:  1500 * time.Microsecond,
+					State:    gocbcore.PingStateOK,
+				},
+				{
+					ID:       "0x2",
+					Endpoint: "http://10.0.0.2:8093",
+					Latency:  20 * time.Millisecond,
+					Error:    errors.New("connection refused"),
+					State:    gocbcore.PingStateOK,
+				},
+			},
+			gocbcore.FtsService: {
+				{
+					Endpoint: "http://10.0.0.1:8094",
+					Latency:  time.Millisecond,
+					State:    gocbcore.PingStateOK,
+				},
+			},
+		},
+	}
+
+	pingProvider := new(mockDiagnosticsProvider)
+	pingProvider.
+		On("Ping", nil, mock.AnythingOfType("gocbcore.PingOptions")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(1).(gocbcore.PingOptions)
+			suite.Assert().Equal([]gocbcore.ServiceType{gocbcore.N1qlService}, opts.ServiceTypes)
+		}).
+		Return(pingResult, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getDiagnosticsProvider", "").Return(pingProvider, nil)
+
+	c := suite.newCluster(cli)
+
+	_, err := c.Ping(&PingOptions{ServiceTypes: []ServiceType{ServiceTypeEventing}})
+	suite.Require().True(errors.Is(err, ErrInvalidArgument), "expected invalid argument but was %v", err)
+
+	report, err := c.Ping(&PingOptions{
+		ServiceTypes: []ServiceType{ServiceTypeQuery, ServiceTypeQuery},
+		ReportID:     "report",
+	})
+	suite.Require().Nil(err, err)
+	suite.Require().Len(report.Services, 1)
+	suite.Require().Len(report.Services[ServiceTypeQuery], 2)
+	suite.Assert().Equal(PingStateError, report.Services[ServiceTypeQuery][1].State)
+	suite.Assert().Equal(20*time.Millisecond, report.Services[ServiceTypeQuery][1].Latency)
+
+	report.sdk = "gocb"
+	data, err := report.MarshalJSON()
+	suite.Require().Nil(err, err)
+	suite.Assert().JSONEq(`{
+		"version": 2,
+		"sdk": "gocb",
+		"id": "report",
+		"config_rev": 64,
+		"services": {
+			"query": [
+				{"id": "0x1", "remote": "10.0.0.1:8093", "state": "ok", "latency_us": 1500},
+				{"id": "0x2", "remote": "10.0.0.2:8093", "state": "error", "error": "connection refused",
+					"latency_us": 20000}
+			]
+		}
+	}`, string(data))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	gocbcore "github.com/couchbase/gocbcore/v10"
@@ -26,17 +27,46 @@ type jsonQueryWarning struct {
 }
 
 type jsonQueryResponse struct {
-	RequestID       string             `json:"requestID"`
-	ClientContextID string             `json:"clientContextID"`
-	Status          QueryStatus        `json:"status"`
-	Warnings        []jsonQueryWarning `json:"warnings"`
-	Metrics         *jsonQueryMetrics  `json:"metrics,omitempty"`
-	Profile         interface{}        `json:"profile"`
-	Signature       interface{}        `json:"signature"`
-	Prepared        string             `json:"prepared"`
+	RequestID       string                 `json:"requestID"`
+	ClientContextID string                 `json:"clientContextID"`
+	Status          QueryStatus            `json:"status"`
+	Warnings        []jsonQueryWarning     `json:"warnings"`
+	Metrics         *jsonQueryMetrics      `json:"metrics,omitempty"`
+	Profile         json.RawMessage        `json:"profile,omitempty"`
+	Signature       interface{}            `json:"signature"`
+	Prepared        string                 `json:"prepared"`
+	Controls        map[string]interface{} `json:"controls,omitempty"`
+}
+
+type jsonQueryProfile struct {
+	PhaseTimes       map[string]string         `json:"phaseTimes,omitempty"`
+	PhaseCounts      map[string]uint64         `json:"phaseCounts,omitempty"`
+	PhaseOperators   map[string]uint64         `json:"phaseOperators,omitempty"`
+	ExecutionTimings *jsonQueryOperatorProfile `json:"executionTimings,omitempty"`
+}
+
+type jsonQueryOperatorProfile struct {
+	Operator string                     `json:"#operator"`
+	Stats    *jsonQueryOperatorStats    `json:"#stats,omitempty"`
+	Child    *jsonQueryOperatorProfile  `json:"~child,omitempty"`
+	Children []jsonQueryOperatorProfile `json:"~children,omitempty"`
+}
+
+type jsonQueryOperatorStats struct {
+	ItemsIn       uint64 `json:"#itemsIn"`
+	ItemsOut      uint64 `json:"#itemsOut"`
+	PhaseSwitches uint64 `json:"#phaseSwitches"`
+	ExecTime      string `json:"execTime"`
+	KernTime      string `json:"kernTime"`
+	ServTime      string `json:"servTime"`
 }
 
 // QueryMetrics encapsulates various metrics gathered during a queries execution.
+//
+// MutationCount is the total number of documents mutated by the statement. For a MERGE statement this is the sum of
+// the documents updated, deleted and inserted, as the query service does not report a breakdown by clause. Where the
+// breakdown is required the clauses can be run as separate statements, or a RETURNING clause added to the MERGE so
+// that the mutated documents can be inspected.
 type QueryMetrics struct {
 	ElapsedTime   time.Duration
 	ExecutionTime time.Duration
@@ -71,6 +101,104 @@ func (metrics *QueryMetrics) fromData(data *jsonQueryMetrics) error {
 	return nil
 }
 
+// QueryProfile encapsulates the profiling information returned by a query when QueryOptions.Profile is set.
+// UNCOMMITTED: This API may change in the future.
+type QueryProfile struct {
+	// PhaseTimes is the time spent in each phase of the query, e.g. "authorize", "fetch" or "indexScan".
+	PhaseTimes map[string]time.Duration
+
+	// PhaseCounts is the number of documents processed by each phase of the query.
+	PhaseCounts map[string]uint64
+
+	// PhaseOperators is the number of operators executing each phase of the query.
+	PhaseOperators map[string]uint64
+
+	// ExecutionTimings is the root of the tree of operators which executed the query, this is only populated when
+	// using QueryProfileModeTimings.
+	ExecutionTimings *QueryOperatorProfile
+
+	// Raw contains the profile as returned by the query service, the profile contents depend on the server version.
+	Raw map[string]interface{}
+}
+
+// QueryOperatorProfile encapsulates the timings of a single operator within a query execution plan.
+// UNCOMMITTED: This API may change in the future.
+type QueryOperatorProfile struct {
+	Operator      string
+	ItemsIn       uint64
+	ItemsOut      uint64
+	PhaseSwitches uint64
+	ExecTime      time.Duration
+	KernTime      time.Duration
+	ServTime      time.Duration
+	Children      []QueryOperatorProfile
+}
+
+func (profile *QueryProfile) fromData(data json.RawMessage) error {
+	var jsonProfile jsonQueryProfile
+	if err := json.Unmarshal(data, &jsonProfile); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &profile.Raw); err != nil {
+		return err
+	}
+
+	if jsonProfile.PhaseTimes != nil {
+		profile.PhaseTimes = make(map[string]time.Duration, len(jsonProfile.PhaseTimes))
+		for phase, phaseTime := range jsonProfile.PhaseTimes {
+			profile.PhaseTimes[phase] = parseQueryProfileDuration(phaseTime)
+		}
+	}
+	profile.PhaseCounts = jsonProfile.PhaseCounts
+	profile.PhaseOperators = jsonProfile.PhaseOperators
+
+	if jsonProfile.ExecutionTimings != nil {
+		profile.ExecutionTimings = &QueryOperatorProfile{}
+		profile.ExecutionTimings.fromData(jsonProfile.ExecutionTimings)
+	}
+
+	return nil
+}
+
+func (operator *QueryOperatorProfile) fromData(data *jsonQueryOperatorProfile) {
+	operator.Operator = data.Operator
+	if data.Stats != nil {
+		operator.ItemsIn = data.Stats.ItemsIn
+		operator.ItemsOut = data.Stats.ItemsOut
+		operator.PhaseSwitches = data.Stats.PhaseSwitches
+		operator.ExecTime = parseQueryProfileDuration(data.Stats.ExecTime)
+		operator.KernTime = parseQueryProfileDuration(data.Stats.KernTime)
+		operator.ServTime = parseQueryProfileDuration(data.Stats.ServTime)
+	}
+
+	// Operators such as Parallel have a single child whereas operators such as Sequence have many.
+	children := data.Children
+	if data.Child != nil {
+		children = append([]jsonQueryOperatorProfile{*data.Child}, children...)
+	}
+
+	if len(children) > 0 {
+		operator.Children = make([]QueryOperatorProfile, len(children))
+		for childIdx := range children {
+			operator.Children[childIdx].fromData(&children[childIdx])
+		}
+	}
+}
+
+func parseQueryProfileDuration(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logDebugf("Failed to parse query profile duration: %s", err)
+	}
+
+	return duration
+}
+
 // QueryWarning encapsulates any warnings returned by a query.
 type QueryWarning struct {
 	Code    uint32
@@ -94,9 +222,34 @@ type QueryMetaData struct {
 	Warnings        []QueryWarning
 	Profile         interface{}
 
+	// ParsedProfile is Profile decoded into a QueryProfile, this is only populated when QueryOptions.Profile is set
+	// to QueryProfileModePhases or QueryProfileModeTimings and the profile could be decoded, otherwise it is nil.
+	// UNCOMMITTED: This API may change in the future.
+	ParsedProfile *QueryProfile
+
+	// Controls are the request level settings which the query service applied to the query, this is only populated
+	// when QueryOptions.Controls is set and the query service reports them, otherwise it is nil.
+	// UNCOMMITTED: This API may change in the future.
+	Controls *QueryControls
+
+	// Endpoint is the query node which executed the query, such as the node chosen by a QuerySession.
+	// UNCOMMITTED: This API may change in the future.
+	Endpoint string
+
 	preparedName string
 }
 
+// QueryControls are the request level settings which the query service applied to a query.
+// UNCOMMITTED: This API may change in the future.
+type QueryControls struct {
+	// ScanConsistency is the scan consistency which the query service applied to the query as it reported it, e.g.
+	// "unbounded", "request_plus" or "at_plus". This is empty if the query service did not report it.
+	ScanConsistency string
+
+	// Raw contains every control reported by the query service, the set of controls depends on the server version.
+	Raw map[string]interface{}
+}
+
 func (meta *QueryMetaData) fromData(data jsonQueryResponse) error {
 	metrics := QueryMetrics{}
 	if data.Metrics != nil {
@@ -119,12 +272,62 @@ func (meta *QueryMetaData) fromData(data jsonQueryResponse) error {
 	meta.Metrics = metrics
 	meta.Signature = data.Signature
 	meta.Warnings = warnings
-	meta.Profile = data.Profile
 	meta.preparedName = data.Prepared
 
+	if len(data.Profile) > 0 && string(data.Profile) != "null" {
+		if err := json.Unmarshal(data.Profile, &meta.Profile); err != nil {
+			return err
+		}
+
+		profile := &QueryProfile{}
+		if err := profile.fromData(data.Profile); err != nil {
+			logDebugf("Failed to decode query profile: %s", err)
+		} else {
+			meta.ParsedProfile = profile
+		}
+	}
+
+	if data.Controls != nil {
+		scanConsistency, _ := data.Controls["scan_consistency"].(string)
+		meta.Controls = &QueryControls{
+			ScanConsistency: scanConsistency,
+			Raw:             data.Controls,
+		}
+	}
+
 	return nil
 }
 
+// ExecutionTime returns the time taken by the query service to execute the query.
+// UNCOMMITTED: This API may change in the future.
+func (meta *QueryMetaData) ExecutionTime() time.Duration {
+	return meta.Metrics.ExecutionTime
+}
+
+// ResultCount returns the number of rows returned by the query.
+// UNCOMMITTED: This API may change in the future.
+func (meta *QueryMetaData) ResultCount() uint64 {
+	return meta.Metrics.ResultCount
+}
+
+// ResultSize returns the size in bytes of the rows returned by the query.
+// UNCOMMITTED: This API may change in the future.
+func (meta *QueryMetaData) ResultSize() uint64 {
+	return meta.Metrics.ResultSize
+}
+
+// SortCount returns the number of rows sorted by the query.
+// UNCOMMITTED: This API may change in the future.
+func (meta *QueryMetaData) SortCount() uint64 {
+	return meta.Metrics.SortCount
+}
+
+// MutationCount returns the number of documents mutated by the query.
+// UNCOMMITTED: This API may change in the future.
+func (meta *QueryMetaData) MutationCount() uint64 {
+	return meta.Metrics.MutationCount
+}
+
 // QueryResultRaw provides raw access to query data.
 // VOLATILE: This API is subject to change at any time.
 type QueryResultRaw struct {
@@ -162,11 +365,28 @@ func (qrr *QueryResultRaw) MetaData() ([]byte, error) {
 }
 
 // QueryResult allows access to the results of a query.
+//
+// Rows are streamed from the server as they are read, so if the query fails partway through, for example because
+// the node servicing it goes away, every row received before the failure is still delivered by Next. Once the
+// stream ends Next returns false and Err returns the error which ended it, so callers can make use of any partial
+// results and must always check Err to find out whether those results are complete.
 type QueryResult struct {
 	reader queryRowReader
 
 	rowBytes []byte
 	endpoint string
+
+	maxRows         uint64
+	rowsRead        uint64
+	maxRowsExceeded bool
+
+	memory    resultMemoryReservation
+	memoryErr error
+
+	deserializer Deserializer
+
+	canceller streamCanceller
+	tracking  *queryResultTracking
 }
 
 func newQueryResult(reader queryRowReader) *QueryResult {
@@ -184,18 +404,33 @@ func (r *QueryResult) Raw() *QueryResultRaw {
 		reader: r.reader,
 	}
 
+	r.endTracking()
 	r.reader = nil
 	return vr
 }
 
 // Next assigns the next result from the results into the value pointer, returning whether the read was successful.
 func (r *QueryResult) Next() bool {
-	if r.reader == nil {
+	if r.reader == nil || r.canceller.isCanceled() {
 		return false
 	}
 
 	rowBytes := r.reader.NextRow()
 	if rowBytes == nil {
+		r.memory.release()
+		r.endTracking()
+		return false
+	}
+
+	if r.checkMaxRows() {
+		r.rowBytes = nil
+		return false
+	}
+
+	if err := r.memory.reserve(len(rowBytes)); err != nil {
+		r.memoryErr = err
+		r.rowBytes = nil
+		r.Cancel()
 		return false
 	}
 
@@ -203,6 +438,36 @@ func (r *QueryResult) Next() bool {
 	return true
 }
 
+// NextBytes reads the next row from the results, returning the raw JSON of the row without decoding it and whether
+// the read was successful. This allows rows to be decoded using a different codec, or skipped without the cost of
+// decoding them. NextBytes advances the same stream as Next and behaves in the same way, so once it has returned
+// false Err should be checked. The returned bytes are also available from Row until the next row is read.
+// UNCOMMITTED: This API may change in the future.
+func (r *QueryResult) NextBytes() ([]byte, bool) {
+	if !r.Next() {
+		return nil, false
+	}
+
+	return r.rowBytes, true
+}
+
+// checkMaxRows counts a row which has been read from the stream, canceling the stream if the row takes the number
+// of rows read past QueryOptions.MaxRows.
+func (r *QueryResult) checkMaxRows() bool {
+	r.rowsRead++
+	if r.maxRows == 0 || r.rowsRead <= r.maxRows {
+		return false
+	}
+
+	r.maxRowsExceeded = true
+	r.Cancel()
+	return true
+}
+
+func (r *QueryResult) maxRowsErr() error {
+	return wrapError(ErrQueryMaxRowsExceeded, fmt.Sprintf("more than %d rows were returned", r.maxRows))
+}
+
 // Row returns the contents of the current row
 func (r *QueryResult) Row(valuePtr interface{}) error {
 	if r.reader == nil {
@@ -213,20 +478,27 @@ func (r *QueryResult) Row(valuePtr interface{}) error {
 		return ErrNoResult
 	}
 
-	if bytesPtr, ok := valuePtr.(*json.RawMessage); ok {
-		*bytesPtr = r.rowBytes
-		return nil
-	}
-
-	return json.Unmarshal(r.rowBytes, valuePtr)
+	return deserializeRow(r.deserializer, r.rowBytes, valuePtr)
 }
 
-// Err returns any errors that have occurred on the stream
+// Err returns any errors that have occurred on the stream, this should be checked once Next has returned false.
 func (r *QueryResult) Err() error {
 	if r.reader == nil {
 		return errors.New("result object is no longer valid")
 	}
 
+	if r.maxRowsExceeded {
+		return r.maxRowsErr()
+	}
+
+	if r.memoryErr != nil {
+		return r.memoryErr
+	}
+
+	if r.canceller.isCanceled() {
+		return r.canceledErr()
+	}
+
 	err := r.reader.Err()
 	if err != nil {
 		return maybeEnhanceQueryError(err)
@@ -235,13 +507,34 @@ func (r *QueryResult) Err() error {
 	return nil
 }
 
+// Cancel aborts the query by closing the underlying stream of results, unblocking any call to Next which is waiting
+// on the server. Once canceled Next returns false and Err returns ErrRequestCanceled.
+// If the server was still sending results then it is also asked to stop executing the query.
+// Cancel is safe to call concurrently with iterating the results and may be called more than once.
+// UNCOMMITTED: This API may change in the future.
+func (r *QueryResult) Cancel() {
+	reader := r.reader
+	if reader == nil {
+		return
+	}
+
+	r.memory.release()
+	r.cancelOnServer()
+	r.canceller.cancel(reader.Close)
+	r.endTracking()
+}
+
 // Close marks the results as closed, returning any errors that occurred during reading the results.
 func (r *QueryResult) Close() error {
 	if r.reader == nil {
 		return r.Err()
 	}
 
-	err := r.reader.Close()
+	r.memory.release()
+	r.endTracking()
+
+	// The stream may already have been closed by Cancel, possibly from another goroutine.
+	err := r.canceller.close(r.reader.Close)
 	if err != nil {
 		return maybeEnhanceQueryError(err)
 	}
@@ -253,6 +546,10 @@ func (r *QueryResult) Close() error {
 // It will close the results but not before iterating through all remaining
 // results, as such this should only be used for very small resultsets - ideally
 // of, at most, length 1.
+// If the stream fails after the first row was received then that row is still assigned to the value pointer and
+// the error is returned.
+// If more than QueryOptions.MaxRows rows are returned then the first row is still assigned to the value pointer and
+// ErrQueryMaxRowsExceeded is returned.
 func (r *QueryResult) One(valuePtr interface{}) error {
 	if r.reader == nil {
 		return r.Err()
@@ -261,20 +558,43 @@ func (r *QueryResult) One(valuePtr interface{}) error {
 	// Read the bytes from the first row
 	valueBytes := r.reader.NextRow()
 	if valueBytes == nil {
+		r.endTracking()
+		if err := r.reader.Err(); err != nil {
+			return maybeEnhanceQueryError(err)
+		}
+
 		return ErrNoResult
 	}
 
+	r.checkMaxRows()
+
 	// Skip through the remaining rows
 	for r.reader.NextRow() != nil {
-		// do nothing with the row
+		if r.checkMaxRows() {
+			break
+		}
 	}
+	r.endTracking()
 
-	return json.Unmarshal(valueBytes, valuePtr)
+	err := deserializeRow(r.deserializer, valueBytes, valuePtr)
+	if err != nil {
+		return err
+	}
+
+	if r.maxRowsExceeded {
+		return r.maxRowsErr()
+	}
+
+	if err := r.reader.Err(); err != nil {
+		return maybeEnhanceQueryError(err)
+	}
+
+	return nil
 }
 
 // MetaData returns any meta-data that was available from this query.  Note that
 // the meta-data will only be available once the object has been closed (either
-// implicitly or explicitly).
+// implicitly or explicitly), or all of the rows have been read using Next or NextBytes.
 func (r *QueryResult) MetaData() (*QueryMetaData, error) {
 	if r.reader == nil {
 		return nil, r.Err()
@@ -296,6 +616,7 @@ func (r *QueryResult) MetaData() (*QueryMetaData, error) {
 	if err != nil {
 		return nil, err
 	}
+	metaData.Endpoint = r.endpoint
 
 	return &metaData, nil
 }
@@ -345,7 +666,7 @@ func (c *Cluster) Query(statement string, opts *QueryOptions) (*QueryResult, err
 	if timeout == 0 {
 		timeout = c.timeoutsConfig.QueryTimeout
 	}
-	deadline := time.Now().Add(timeout)
+	deadline := queryDeadline(opts.Context, timeout)
 
 	retryStrategy := c.retryStrategyWrapper
 	if opts.RetryStrategy != nil {
@@ -361,6 +682,17 @@ func (c *Cluster) Query(statement string, opts *QueryOptions) (*QueryResult, err
 		}
 	}
 
+	if opts.ScanVectors != nil {
+		err = opts.validateScanVectors(c.connectionManager.getKvProvider)
+		if err != nil {
+			return nil, QueryError{
+				InnerError:      wrapError(err, "failed to validate scan vectors"),
+				Statement:       statement,
+				ClientContextID: opts.ClientContextID,
+			}
+		}
+	}
+
 	queryOpts["statement"] = statement
 
 	provider, err := c.getQueryProvider()
@@ -371,8 +703,10 @@ func (c *Cluster) Query(statement string, opts *QueryOptions) (*QueryResult, err
 			ClientContextID: maybeGetQueryOption(queryOpts, "client_context_id"),
 		}
 	}
+	canceller := newQueryServerCanceller(provider, queryOpts, c.timeoutsConfig.ManagementTimeout)
+	provider = withQueryCircuitBreakerCallback(provider, opts.CircuitBreakerCallback)
 
-	return execN1qlQuery(
+	res, err := execN1qlQuery(
 		opts.Context,
 		span,
 		queryOpts,
@@ -380,10 +714,37 @@ func (c *Cluster) Query(statement string, opts *QueryOptions) (*QueryResult, err
 		retryStrategy,
 		opts.Adhoc,
 		provider,
+		c.preparedStatementCache,
 		c.tracer,
 		opts.Internal.User,
 		opts.Internal.Endpoint,
 	)
+	if err != nil {
+		canceller.cancelIfContextDone(opts.Context)
+		return nil, c.maybeEnhanceNoBucketErr(maybeWrapContextErr(opts.Context, err))
+	}
+	res.track(opts.Context, c.activeQueries, canceller)
+	res.maxRows = opts.MaxRows
+	res.memory.limiter = c.resultMemoryLimiter
+	res.memory.ctx = opts.Context
+	res.memory.deadline = deadline
+	res.deserializer = resultDeserializer(opts.Deserializer, c.deserializer)
+
+	return res, nil
+}
+
+// queryDeadline returns the deadline for a query, which is the earlier of the timeout and the deadline of the context.
+// gocbcore sends the time remaining until this deadline to the query service as the server timeout on every attempt,
+// so the server stops executing the query once the client has given up on it.
+func queryDeadline(ctx context.Context, timeout time.Duration) time.Time {
+	deadline := time.Now().Add(timeout)
+	if ctx != nil {
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+	}
+
+	return deadline
 }
 
 func maybeGetQueryOption(options map[string]interface{}, name string) string {
@@ -401,6 +762,7 @@ func execN1qlQuery(
 	retryStrategy *retryStrategyWrapper,
 	adHoc bool,
 	provider queryProvider,
+	preparedCache *PreparedStatementCache,
 	tracer RequestTracer,
 	user,
 	endpoint string,
@@ -429,14 +791,8 @@ func execN1qlQuery(
 			Endpoint:      endpoint,
 		})
 	} else {
-		res, qErr = provider.PreparedN1QLQuery(ctx, gocbcore.N1QLQueryOptions{
-			Payload:       reqBytes,
-			RetryStrategy: retryStrategy,
-			Deadline:      deadline,
-			TraceContext:  span.Context(),
-			User:          user,
-			Endpoint:      endpoint,
-		})
+		res, qErr = execPreparedN1qlQuery(ctx, span, options, reqBytes, deadline, retryStrategy, provider,
+			preparedCache, user, endpoint)
 	}
 	if qErr != nil {
 		return nil, maybeEnhanceQueryError(qErr)
@@ -0,0 +1,206 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/google/uuid"
+)
+
+// activeQueryTracker tracks the client context IDs of the queries which have been dispatched by a cluster and whose
+// results are still being streamed.
+type activeQueryTracker struct {
+	lock    sync.Mutex
+	queries map[string]int
+}
+
+func newActiveQueryTracker() *activeQueryTracker {
+	return &activeQueryTracker{
+		queries: make(map[string]int),
+	}
+}
+
+func (t *activeQueryTracker) add(clientContextID string) {
+	t.lock.Lock()
+	t.queries[clientContextID]++
+	t.lock.Unlock()
+}
+
+func (t *activeQueryTracker) remove(clientContextID string) {
+	t.lock.Lock()
+	t.queries[clientContextID]--
+	if t.queries[clientContextID] <= 0 {
+		delete(t.queries, clientContextID)
+	}
+	t.lock.Unlock()
+}
+
+func (t *activeQueryTracker) clientContextIDs() []string {
+	t.lock.Lock()
+	ids := make([]string, 0, len(t.queries))
+	for id := range t.queries {
+		ids = append(ids, id)
+	}
+	t.lock.Unlock()
+
+	sort.Strings(ids)
+	return ids
+}
+
+// ActiveQueries returns the client context IDs of the queries which have been sent by this client and whose results
+// have not yet been fully read, closed or canceled.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) ActiveQueries() []string {
+	if c.activeQueries == nil {
+		return nil
+	}
+
+	return c.activeQueries.clientContextIDs()
+}
+
+// queryServerCanceller asks the query service to stop executing a query which the client has stopped waiting for,
+// so that the resources held by the query on the server are freed without waiting for it to complete.
+type queryServerCanceller struct {
+	provider        queryProvider
+	clientContextID string
+	endpoint        string
+	timeout         time.Duration
+}
+
+func newQueryServerCanceller(provider queryProvider, queryOpts map[string]interface{},
+	timeout time.Duration) *queryServerCanceller {
+	return &queryServerCanceller{
+		provider:        provider,
+		clientContextID: maybeGetQueryOption(queryOpts, "client_context_id"),
+		timeout:         timeout,
+	}
+}
+
+// cancelIfContextDone cancels the query on the server if ctx was done before the query returned, in which case the
+// query may already be executing on the server even though the client has given up on it.
+func (qc *queryServerCanceller) cancelIfContextDone(ctx context.Context) {
+	if ctx == nil || ctx.Err() == nil {
+		return
+	}
+
+	qc.cancel()
+}
+
+// cancel deletes the query from the active requests of the query service. This is done in the background as the
+// caller has already abandoned the query, and failures are only logged as the query may have already completed.
+func (qc *queryServerCanceller) cancel() {
+	if qc == nil || qc.clientContextID == "" {
+		return
+	}
+
+	go func() {
+		payload, err := json.Marshal(map[string]interface{}{
+			"statement":         "DELETE FROM system:active_requests WHERE clientContextID = $clientContextID",
+			"$clientContextID":  qc.clientContextID,
+			"client_context_id": uuid.New().String(),
+			"timeout":           qc.timeout.String(),
+		})
+		if err != nil {
+			logDebugf("Failed to encode server side cancellation of query %s: %v", qc.clientContextID, err)
+			return
+		}
+
+		res, err := qc.provider.N1QLQuery(context.Background(), gocbcore.N1QLQueryOptions{
+			Payload:  payload,
+			Deadline: time.Now().Add(qc.timeout),
+			Endpoint: qc.endpoint,
+		})
+		if err != nil {
+			logDebugFieldsf(logFields{operation: "query", node: qc.endpoint}, "Failed to cancel query %s on the server: %v",
+				qc.clientContextID, err)
+			return
+		}
+
+		for res.NextRow() != nil {
+			// The rows of a delete without RETURNING are empty, they only need to be drained.
+		}
+		if err := res.Close(); err != nil {
+			logDebugFieldsf(logFields{operation: "query", node: qc.endpoint}, "Failed to cancel query %s on the server: %v",
+				qc.clientContextID, err)
+		}
+	}()
+}
+
+// queryResultTracking holds the state used to track a query whilst its results are being streamed.
+type queryResultTracking struct {
+	ctx       context.Context
+	tracker   *activeQueryTracker
+	canceller *queryServerCanceller
+
+	ended  uint32
+	doneCh chan struct{}
+	once   sync.Once
+}
+
+// track registers the query as active until its results end, and cancels the query if ctx is done before then.
+func (r *QueryResult) track(ctx context.Context, tracker *activeQueryTracker, canceller *queryServerCanceller) {
+	canceller.endpoint = r.endpoint
+	r.tracking = &queryResultTracking{
+		ctx:       ctx,
+		tracker:   tracker,
+		canceller: canceller,
+		doneCh:    make(chan struct{}),
+	}
+
+	if tracker != nil {
+		tracker.add(canceller.clientContextID)
+	}
+
+	if ctx == nil || ctx.Done() == nil {
+		return
+	}
+
+	go func(tracking *queryResultTracking) {
+		select {
+		case <-ctx.Done():
+			r.Cancel()
+		case <-tracking.doneCh:
+		}
+	}(r.tracking)
+}
+
+// endTracking marks the results as no longer being streamed from the server.
+func (r *QueryResult) endTracking() {
+	tracking := r.tracking
+	if tracking == nil {
+		return
+	}
+
+	tracking.once.Do(func() {
+		atomic.StoreUint32(&tracking.ended, 1)
+		close(tracking.doneCh)
+		if tracking.tracker != nil {
+			tracking.tracker.remove(tracking.canceller.clientContextID)
+		}
+	})
+}
+
+// cancelOnServer cancels the query on the server if its results were still being streamed.
+func (r *QueryResult) cancelOnServer() {
+	tracking := r.tracking
+	if tracking == nil || atomic.LoadUint32(&tracking.ended) == 1 {
+		return
+	}
+
+	tracking.canceller.cancel()
+}
+
+// canceledErr returns the error for results which were canceled, wrapping the error of the context if it was the
+// context being done which canceled them.
+func (r *QueryResult) canceledErr() error {
+	if r.tracking == nil {
+		return ErrRequestCanceled
+	}
+
+	return maybeWrapContextErr(r.tracking.ctx, ErrRequestCanceled)
+}
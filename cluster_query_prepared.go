@@ -0,0 +1,306 @@
+package gocb
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+)
+
+const defaultPreparedStatementCacheSize = 5000
+
+// PreparedStatementCache holds the names of the prepared statements used by queries which are executed with
+// QueryOptions.Adhoc set to false. Once the cache is full the least recently used statement is evicted.
+// Statements are prepared and executed in a single request, which requires Couchbase Server 6.5 or later. Against
+// older servers the statements are prepared by the SDK's connection layer and are not held in this cache.
+// UNCOMMITTED: This API may change in the future.
+type PreparedStatementCache struct {
+	lock    sync.Mutex
+	maxSize int
+	entries map[preparedStatementKey]*list.Element
+	lru     *list.List
+}
+
+type preparedStatementKey struct {
+	statement    string
+	queryContext string
+}
+
+type preparedStatementEntry struct {
+	key  preparedStatementKey
+	name string
+}
+
+func newPreparedStatementCache(maxSize int) *PreparedStatementCache {
+	if maxSize <= 0 {
+		maxSize = defaultPreparedStatementCacheSize
+	}
+
+	return &PreparedStatementCache{
+		maxSize: maxSize,
+		entries: make(map[preparedStatementKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// PreparedStatementCache returns the cache of prepared statements used by this cluster, and any buckets and scopes
+// opened from it.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) PreparedStatementCache() *PreparedStatementCache {
+	return c.preparedStatementCache
+}
+
+// Len returns the number of prepared statements currently cached.
+func (pc *PreparedStatementCache) Len() int {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	return pc.lru.Len()
+}
+
+// InvalidatePreparedStatement removes the prepared statement for the given statement from the cache, for every
+// scope that it has been executed against. The next execution of the statement prepares it again.
+func (pc *PreparedStatementCache) InvalidatePreparedStatement(statement string) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	for key, elem := range pc.entries {
+		if key.statement == statement {
+			pc.lru.Remove(elem)
+			delete(pc.entries, key)
+		}
+	}
+}
+
+// ClearPreparedStatements removes all prepared statements from the cache.
+func (pc *PreparedStatementCache) ClearPreparedStatements() {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	pc.entries = make(map[preparedStatementKey]*list.Element)
+	pc.lru.Init()
+}
+
+func (pc *PreparedStatementCache) get(key preparedStatementKey) (string, bool) {
+	if pc == nil {
+		return "", false
+	}
+
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	elem, ok := pc.entries[key]
+	if !ok {
+		return "", false
+	}
+	pc.lru.MoveToFront(elem)
+
+	return elem.Value.(*preparedStatementEntry).name, true
+}
+
+func (pc *PreparedStatementCache) put(key preparedStatementKey, name string) {
+	if pc == nil {
+		return
+	}
+
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	if elem, ok := pc.entries[key]; ok {
+		elem.Value.(*preparedStatementEntry).name = name
+		pc.lru.MoveToFront(elem)
+		return
+	}
+
+	pc.entries[key] = pc.lru.PushFront(&preparedStatementEntry{
+		key:  key,
+		name: name,
+	})
+
+	for pc.lru.Len() > pc.maxSize {
+		oldest := pc.lru.Back()
+		pc.lru.Remove(oldest)
+		delete(pc.entries, oldest.Value.(*preparedStatementEntry).key)
+	}
+}
+
+// remove only removes the entry if it still refers to the given name, so that a statement which has already been
+// prepared again by a concurrent query is not evicted.
+func (pc *PreparedStatementCache) remove(key preparedStatementKey, name string) {
+	if pc == nil {
+		return
+	}
+
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	elem, ok := pc.entries[key]
+	if !ok || elem.Value.(*preparedStatementEntry).name != name {
+		return
+	}
+
+	pc.lru.Remove(elem)
+	delete(pc.entries, key)
+}
+
+// preparedStatementRetryStrategy stops queries which are executed using a cached prepared statement from being
+// retried when the prepared statement has failed, the statement is prepared again instead.
+type preparedStatementRetryStrategy struct {
+	wrapped RetryStrategy
+}
+
+func (rs *preparedStatementRetryStrategy) RetryAfter(req RetryRequest, reason RetryReason) RetryAction {
+	if reason == QueryPreparedStatementFailureRetryReason {
+		return &NoRetryRetryAction{}
+	}
+
+	return rs.wrapped.RetryAfter(req, reason)
+}
+
+func isPreparedStatementFailure(err error) bool {
+	if errors.Is(err, ErrPreparedStatementFailure) {
+		return true
+	}
+
+	var qErr *QueryError
+	if !errors.As(err, &qErr) {
+		return false
+	}
+
+	for _, desc := range qErr.Errors {
+		switch desc.Code {
+		case 4040, 4050, 4070:
+			return true
+		}
+	}
+
+	return false
+}
+
+func execPreparedN1qlQuery(
+	ctx context.Context,
+	span RequestSpan,
+	options map[string]interface{},
+	reqBytes []byte,
+	deadline time.Time,
+	retryStrategy *retryStrategyWrapper,
+	provider queryProvider,
+	cache *PreparedStatementCache,
+	user,
+	endpoint string,
+) (queryRowReader, error) {
+	key := preparedStatementKey{
+		statement:    maybeGetQueryOption(options, "statement"),
+		queryContext: maybeGetQueryOption(options, "query_context"),
+	}
+
+	if name, ok := cache.get(key); ok {
+		execOpts := make(map[string]interface{}, len(options))
+		for k, v := range options {
+			execOpts[k] = v
+		}
+		delete(execOpts, "statement")
+		execOpts["prepared"] = name
+
+		execBytes, err := json.Marshal(execOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		execRetryStrategy := retryStrategy
+		if retryStrategy != nil && retryStrategy.wrapped != nil {
+			execRetryStrategy = newRetryStrategyWrapper(&preparedStatementRetryStrategy{wrapped: retryStrategy.wrapped})
+		}
+
+		res, err := provider.N1QLQuery(ctx, gocbcore.N1QLQueryOptions{
+			Payload:       execBytes,
+			RetryStrategy: execRetryStrategy,
+			Deadline:      deadline,
+			TraceContext:  span.Context(),
+			User:          user,
+			Endpoint:      endpoint,
+		})
+		if err == nil {
+			return res, nil
+		}
+		if !isPreparedStatementFailure(maybeEnhanceQueryError(err)) {
+			return nil, err
+		}
+
+		// The prepared statement is stale, for example because the server has restarted or the schema has changed,
+		// so evict it and prepare the statement again.
+		logDebugf("Prepared statement %s failed, preparing again: %v", name, err)
+		cache.remove(key, name)
+	}
+
+	// The statement is prepared and executed in a single request, which is sent as a plain query so that this cache
+	// is the only one that the prepared statement is held in.
+	prepOpts := make(map[string]interface{}, len(options)+1)
+	for k, v := range options {
+		prepOpts[k] = v
+	}
+	prepOpts["statement"] = "PREPARE " + key.statement
+	prepOpts["auto_execute"] = true
+
+	prepBytes, err := json.Marshal(prepOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := provider.N1QLQuery(ctx, gocbcore.N1QLQueryOptions{
+		Payload:       prepBytes,
+		RetryStrategy: retryStrategy,
+		Deadline:      deadline,
+		TraceContext:  span.Context(),
+		User:          user,
+		Endpoint:      endpoint,
+	})
+	if err != nil {
+		if !isAutoExecuteUnsupported(maybeEnhanceQueryError(err)) {
+			return nil, err
+		}
+
+		// Servers before 6.5 cannot prepare and execute a statement in one request, gocbcore falls back to
+		// preparing the statement separately and caches it itself.
+		logDebugf("Server does not support auto executing prepared statements, using legacy prepared statements")
+		return provider.PreparedN1QLQuery(ctx, gocbcore.N1QLQueryOptions{
+			Payload:       reqBytes,
+			RetryStrategy: retryStrategy,
+			Deadline:      deadline,
+			TraceContext:  span.Context(),
+			User:          user,
+			Endpoint:      endpoint,
+		})
+	}
+
+	name, err := res.PreparedName()
+	if err != nil {
+		logDebugf("Failed to read prepared statement name: %v", err)
+	} else if name != "" {
+		cache.put(key, name)
+	}
+
+	return res, nil
+}
+
+// isAutoExecuteUnsupported returns whether a query failed because the server does not recognise the auto_execute
+// parameter.
+func isAutoExecuteUnsupported(err error) bool {
+	var qErr *QueryError
+	if !errors.As(err, &qErr) {
+		return false
+	}
+
+	for _, desc := range qErr.Errors {
+		if desc.Code == 1065 && strings.Contains(desc.Message, "auto_execute") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package gocb
+
+import (
+	"sync"
+	"time"
+)
+
+// QuerySession executes queries against a single query node, so that the plans of prepared statements and other
+// per-node state stay warm on that node. The node is chosen by the first query executed through the session and the
+// session stays pinned to it until it becomes unavailable, at which point the query is retried against any node,
+// within its timeout, and the session moves to that node.
+//
+// Pinning trades load balancing for locality. Queries made through a session are not spread across the query nodes,
+// so a busy session can overload its node whilst others are idle, and a new node added to the cluster is not used by
+// existing sessions until their node becomes unavailable. Only a node which cannot be reached, as reported by the
+// retry and circuit breaker logic, causes the session to move, a node which is reachable but slow does not.
+//
+// A QuerySession is safe for concurrent use.
+// UNCOMMITTED: This API may change in the future.
+type QuerySession struct {
+	cluster *Cluster
+
+	lock     sync.Mutex
+	endpoint string
+}
+
+// QuerySession creates a new session which pins the queries executed through it to a single query node.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) QuerySession() *QuerySession {
+	return &QuerySession{
+		cluster: c,
+	}
+}
+
+// Endpoint returns the query node which the session is pinned to, or an empty string if a node has not been chosen
+// yet.
+func (s *QuerySession) Endpoint() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.endpoint
+}
+
+func (s *QuerySession) pin(endpoint string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// When several queries choose a node at the same time the first one wins.
+	if s.endpoint == "" {
+		s.endpoint = endpoint
+	}
+}
+
+func (s *QuerySession) release(endpoint string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The session may already have moved to another node because of a concurrent query.
+	if s.endpoint == endpoint {
+		s.endpoint = ""
+	}
+}
+
+// Query executes the query statement on the node which the session is pinned to, see Cluster.Query.
+func (s *QuerySession) Query(statement string, opts *QueryOptions) (*QueryResult, error) {
+	if opts == nil {
+		opts = &QueryOptions{}
+	}
+
+	if opts.Internal.Endpoint != "" {
+		return s.cluster.Query(statement, opts)
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = s.cluster.timeoutsConfig.QueryTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	endpoint := s.Endpoint()
+	if endpoint != "" {
+		wrapped := opts.RetryStrategy
+		if wrapped == nil {
+			wrapped = s.cluster.retryStrategyWrapper.wrapped
+		}
+		retryStrategy := &nodeUnavailableRetryStrategy{
+			wrapped: wrapped,
+		}
+
+		pinnedOpts := *opts
+		pinnedOpts.Timeout = timeout
+		pinnedOpts.RetryStrategy = retryStrategy
+		pinnedOpts.Internal.Endpoint = endpoint
+
+		res, err := s.cluster.Query(statement, &pinnedOpts)
+		if err == nil {
+			return res, nil
+		}
+
+		if !retryStrategy.nodeUnavailable() {
+			return nil, err
+		}
+
+		s.release(endpoint)
+
+		timeout = time.Until(deadline)
+		if timeout <= 0 {
+			return nil, err
+		}
+
+		logDebugf("Query node %s is unavailable, moving query session to another node: %v", endpoint, err)
+	}
+
+	unpinnedOpts := *opts
+	unpinnedOpts.Timeout = timeout
+
+	res, err := s.cluster.Query(statement, &unpinnedOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.endpoint != "" {
+		s.pin(res.endpoint)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestQuerySessionPinsAndFailsOver() {
+	var unpinned []string
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", nil, mock.MatchedBy(func(opts gocbcore.N1QLQueryOptions) bool {
+			return opts.Endpoint == ""
+		})).
+		Return(func(ctx context.Context, opts gocbcore.N1QLQueryOptions) queryRowReader {
+			remote := "http://node1:8093"
+			if len(unpinned) > 0 {
+				remote = "http://node2:8093"
+			}
+			unpinned = append(unpinned, remote)
+
+			return &mockQueryRowReader{
+				mockQueryRowReaderBase: mockQueryRowReaderBase{
+					Meta:   []byte(`{}`),
+					Remote: remote,
+					Suite:  suite,
+				},
+			}
+		}, nil)
+
+	var pinnedCalls int
+	queryProvider.
+		On("N1QLQuery", nil, mock.MatchedBy(func(opts gocbcore.N1QLQueryOptions) bool {
+			return opts.Endpoint == "http://node1:8093"
+		})).
+		Return(func(ctx context.Context, opts gocbcore.N1QLQueryOptions) queryRowReader {
+			pinnedCalls++
+			if pinnedCalls == 1 {
+				return &mockQueryRowReader{
+					mockQueryRowReaderBase: mockQueryRowReaderBase{
+						Meta:   []byte(`{}`),
+						Remote: opts.Endpoint,
+						Suite:  suite,
+					},
+				}
+			}
+
+			// The node has gone away, which the session must notice from the retry reason rather than waiting for
+			// the timeout.
+			action := opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{idempotent: true},
+				gocbcore.SocketNotAvailableRetryReason)
+			suite.Assert().Zero(action.Duration())
+			return nil
+		}, func(ctx context.Context, opts gocbcore.N1QLQueryOptions) error {
+			if pinnedCalls == 1 {
+				return nil
+			}
+			return errors.New("connection refused")
+		})
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	cluster := suite.newCluster(cli)
+	session := cluster.QuerySession()
+	suite.Assert().Empty(session.Endpoint())
+
+	opts := &QueryOptions{Adhoc: true, Timeout: time.Second}
+	for i, expected := range []string{"http://node1:8093", "http://node1:8093", "http://node2:8093"} {
+		res, err := session.Query("SELECT 1", opts)
+		suite.Require().Nil(err, err)
+
+		meta, err := res.MetaData()
+		suite.Require().Nil(err, err)
+		suite.Assert().Equal(expected, meta.Endpoint, "query %d", i)
+		suite.Assert().Equal(expected, session.Endpoint(), "query %d", i)
+	}
+
+	suite.Assert().Equal([]string{"http://node1:8093", "http://node2:8093"}, unpinned)
+	suite.Assert().Equal(2, pinnedCalls)
+}
+
+func (suite *UnitTestSuite) TestQuerySessionStaysPinnedOnQueryError() {
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(nil, errors.New("syntax error"))
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	session := suite.newCluster(cli).QuerySession()
+	session.pin("http://node1:8093")
+
+	_, err := session.Query("SELEC 1", &QueryOptions{Adhoc: true})
+	suite.Require().NotNil(err)
+	suite.Assert().Equal("http://node1:8093", session.Endpoint())
+	queryProvider.AssertNumberOfCalls(suite.T(), "N1QLQuery", 1)
+}
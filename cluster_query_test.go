@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/couchbase/gocbcore/v10"
@@ -269,6 +271,57 @@ func (suite *IntegrationTestSuite) setupClusterQuery() int {
 	return n
 }
 
+func (suite *IntegrationTestSuite) TestClusterQueryScanVectorsLength() {
+	suite.skipIfUnsupported(QueryFeature)
+
+	vbMap, err := globalBucket.VbucketMap()
+	suite.Require().Nil(err, err)
+
+	_, err = globalCluster.Query("SELECT 1=1", &QueryOptions{
+		ScanVectors: map[string]QueryScanVector{
+			globalBucket.Name(): make(QueryScanVector, len(vbMap.Vbuckets)-1),
+		},
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error was %s", err)
+	}
+}
+
+func (suite *IntegrationTestSuite) TestClusterQueryConsistentWith() {
+	suite.skipIfUnsupported(QueryFeature)
+
+	suite.setupClusterQuery()
+
+	docID := uuid.New().String()
+	res, err := globalBucket.DefaultCollection().Upsert(docID, map[string]string{"service": "consistentwith"}, nil)
+	suite.Require().Nil(err, err)
+
+	state := NewMutationState()
+	state.AddResults(*res)
+
+	query := fmt.Sprintf("SELECT META().id FROM `%s` WHERE META().id=$id", globalBucket.Name())
+	result, err := globalCluster.Query(query, &QueryOptions{
+		ConsistentWith:  state,
+		NamedParameters: map[string]interface{}{"id": docID},
+	})
+	suite.Require().Nil(err, err)
+
+	var row struct {
+		ID string `json:"id"`
+	}
+	err = result.One(&row)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(docID, row.ID)
+
+	_, err = globalCluster.Query(query, &QueryOptions{
+		ConsistentWith:  state,
+		ScanConsistency: QueryScanConsistencyRequestPlus,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
 func (suite *IntegrationTestSuite) TestClusterQueryContext() {
 	suite.skipIfUnsupported(QueryFeature)
 
@@ -307,6 +360,7 @@ type mockQueryRowReaderBase struct {
 	CloseErr error
 	RowsErr  error
 	PName    string
+	Remote   string
 
 	Suite *UnitTestSuite
 
@@ -341,17 +395,23 @@ func (arr *mockQueryRowReaderBase) PreparedName() (string, error) {
 }
 
 func (arr *mockQueryRowReaderBase) Endpoint() string {
-	return ""
+	return arr.Remote
 }
 
 func (suite *UnitTestSuite) newMockQueryProvider(prepared bool, reader queryRowReader) (*mockQueryProvider, *mock.Call) {
 	queryProvider := new(mockQueryProvider)
-	methodName := "N1QLQuery"
-	if prepared {
-		methodName = "PreparedN1QLQuery"
-	}
+
+	// Prepared statements are prepared and executed in a single query, only matched when prepared is set.
 	call := queryProvider.
-		On(methodName, nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		On("N1QLQuery", nil, mock.MatchedBy(func(opts gocbcore.N1QLQueryOptions) bool {
+			var payload map[string]interface{}
+			if err := json.Unmarshal(opts.Payload, &payload); err != nil {
+				return false
+			}
+
+			autoExecute, _ := payload["auto_execute"].(bool)
+			return autoExecute == prepared
+		})).
 		Return(reader, nil).
 		Once()
 
@@ -517,6 +577,274 @@ func (suite *UnitTestSuite) TestQueryResultsOne() {
 	suite.Assert().Equal(&aMeta, metadata)
 }
 
+func (suite *UnitTestSuite) TestQueryResultsCancel() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	reader := &mockQueryRowReader{
+		Dataset: dataset.Results,
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta:     suite.mustConvertToBytes(dataset.jsonQueryResponse),
+			CloseErr: errors.New("some error"),
+			Suite:    suite,
+		},
+	}
+	result := newQueryResult(reader)
+
+	suite.Require().True(result.Next())
+
+	result.Cancel()
+	result.Cancel()
+
+	suite.Assert().False(result.Next())
+	suite.Assert().True(errors.Is(result.Err(), ErrRequestCanceled))
+	suite.Assert().Nil(result.Close())
+}
+
+// blockingQueryRowReader blocks reading rows until it is closed, like a query which is still executing.
+type blockingQueryRowReader struct {
+	mockQueryRowReaderBase
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	closes    int32
+}
+
+func (r *blockingQueryRowReader) NextRow() []byte {
+	<-r.closeCh
+	return nil
+}
+
+func (r *blockingQueryRowReader) Close() error {
+	atomic.AddInt32(&r.closes, 1)
+	r.closeOnce.Do(func() {
+		close(r.closeCh)
+	})
+	return nil
+}
+
+func (suite *UnitTestSuite) TestQueryResultsCloseRacesCancel() {
+	reader := &blockingQueryRowReader{closeCh: make(chan struct{})}
+	result := newQueryResult(reader)
+
+	nextCh := make(chan bool)
+	go func() {
+		nextCh <- result.Next()
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		result.Cancel()
+		wg.Done()
+	}()
+	go func() {
+		suite.Assert().Nil(result.Close())
+		wg.Done()
+	}()
+	wg.Wait()
+
+	select {
+	case next := <-nextCh:
+		suite.Assert().False(next)
+	case <-time.After(5 * time.Second):
+		suite.T().Fatalf("Next was not unblocked by closing the results")
+	}
+	suite.Assert().Equal(int32(1), atomic.LoadInt32(&reader.closes))
+}
+
+func (suite *UnitTestSuite) TestQueryContextCancelCancelsOnServer() {
+	cancelPayloads := make(chan map[string]interface{}, 1)
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", mock.Anything, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(func(ctx context.Context, opts gocbcore.N1QLQueryOptions) queryRowReader {
+			var payload map[string]interface{}
+			suite.Require().Nil(json.Unmarshal(opts.Payload, &payload))
+			if payload["statement"] == "SELECT * FROM beer" {
+				return &blockingQueryRowReader{closeCh: make(chan struct{})}
+			}
+
+			cancelPayloads <- payload
+			return &mockQueryRowReader{mockQueryRowReaderBase: mockQueryRowReaderBase{Suite: suite}}
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result, err := cluster.Query("SELECT * FROM beer", &QueryOptions{
+		Adhoc:           true,
+		Context:         ctx,
+		ClientContextID: "long-running",
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]string{"long-running"}, cluster.ActiveQueries())
+
+	cancel()
+	suite.Assert().False(result.Next())
+	suite.Assert().True(errors.Is(result.Err(), ErrRequestCanceled))
+	suite.Assert().True(errors.Is(result.Err(), context.Canceled))
+	suite.Assert().Empty(cluster.ActiveQueries())
+
+	select {
+	case payload := <-cancelPayloads:
+		suite.Assert().Equal("DELETE FROM system:active_requests WHERE clientContextID = $clientContextID",
+			payload["statement"])
+		suite.Assert().Equal("long-running", payload["$clientContextID"])
+	case <-time.After(5 * time.Second):
+		suite.T().Fatalf("Query was not canceled on the server")
+	}
+}
+
+func (suite *UnitTestSuite) TestQueryActiveQueriesEndWithResults() {
+	reader := &mockQueryRowReader{
+		Dataset:                []testBreweryDocument{{Name: "beer"}},
+		mockQueryRowReaderBase: mockQueryRowReaderBase{Suite: suite},
+	}
+
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", mock.Anything, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(reader, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	result, err := cluster.Query("SELECT * FROM beer", &QueryOptions{
+		Adhoc:           true,
+		ClientContextID: "short",
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]string{"short"}, cluster.ActiveQueries())
+
+	for result.Next() {
+	}
+	suite.Require().Nil(result.Err())
+	suite.Assert().Empty(cluster.ActiveQueries())
+
+	// The query has completed so it is not canceled on the server.
+	result.Cancel()
+	queryProvider.AssertNumberOfCalls(suite.T(), "N1QLQuery", 1)
+}
+
+func (suite *UnitTestSuite) TestQueryResultsMaxRows() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+	suite.Require().True(len(dataset.Results) > 2)
+
+	newReader := func() *mockQueryRowReader {
+		return &mockQueryRowReader{
+			Dataset: dataset.Results,
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+				Suite: suite,
+			},
+		}
+	}
+
+	result := newQueryResult(newReader())
+	result.maxRows = 2
+
+	var numRows int
+	for result.Next() {
+		numRows++
+	}
+	suite.Assert().Equal(2, numRows)
+	suite.Assert().True(errors.Is(result.Err(), ErrQueryMaxRowsExceeded))
+	suite.Assert().Nil(result.Close())
+
+	result = newQueryResult(newReader())
+	result.maxRows = 2
+
+	var doc testBreweryDocument
+	err = result.One(&doc)
+	suite.Assert().True(errors.Is(err, ErrQueryMaxRowsExceeded))
+	suite.Assert().Equal(dataset.Results[0], doc)
+
+	result = newQueryResult(newReader())
+	result.maxRows = uint64(len(dataset.Results))
+
+	numRows = 0
+	for result.Next() {
+		numRows++
+	}
+	suite.Assert().Equal(len(dataset.Results), numRows)
+	suite.Assert().Nil(result.Err())
+}
+
+type countingDeserializer struct {
+	calls int
+}
+
+func (d *countingDeserializer) Deserialize(data []byte, out interface{}) error {
+	d.calls++
+	return json.Unmarshal(data, out)
+}
+
+func (suite *UnitTestSuite) TestQueryResultsDeserializerAndNextBytes() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	newReader := func() *mockQueryRowReader {
+		return &mockQueryRowReader{
+			Dataset: dataset.Results,
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+				Suite: suite,
+			},
+		}
+	}
+
+	deserializer := &countingDeserializer{}
+	result := newQueryResult(newReader())
+	result.deserializer = deserializer
+
+	var numRows int
+	for result.Next() {
+		var doc testBreweryDocument
+		suite.Require().Nil(result.Row(&doc))
+		suite.Assert().Equal(dataset.Results[numRows], doc)
+		numRows++
+	}
+	suite.Require().Nil(result.Err())
+	suite.Assert().Equal(len(dataset.Results), numRows)
+	suite.Assert().Equal(len(dataset.Results), deserializer.calls)
+
+	_, err = result.MetaData()
+	suite.Require().Nil(err, err)
+
+	deserializer = &countingDeserializer{}
+	result = newQueryResult(newReader())
+	result.deserializer = deserializer
+
+	numRows = 0
+	for {
+		rowBytes, ok := result.NextBytes()
+		if !ok {
+			break
+		}
+
+		var doc testBreweryDocument
+		suite.Require().Nil(json.Unmarshal(rowBytes, &doc))
+		suite.Assert().Equal(dataset.Results[numRows], doc)
+		numRows++
+	}
+	suite.Require().Nil(result.Err())
+	suite.Assert().Equal(len(dataset.Results), numRows)
+	suite.Assert().Zero(deserializer.calls)
+
+	_, err = result.MetaData()
+	suite.Require().Nil(err, err)
+}
+
 func (suite *UnitTestSuite) TestQueryResultsErr() {
 	reader := &mockQueryRowReader{
 		mockQueryRowReaderBase: mockQueryRowReaderBase{
@@ -532,6 +860,41 @@ func (suite *UnitTestSuite) TestQueryResultsErr() {
 	suite.Require().NotNil(err, err)
 }
 
+func (suite *UnitTestSuite) TestQueryResultsPartialErr() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	newReader := func() *mockQueryRowReader {
+		return &mockQueryRowReader{
+			Dataset: dataset.Results[:2],
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				RowsErr: errors.New("some error"),
+				Suite:   suite,
+			},
+		}
+	}
+
+	result := newQueryResult(newReader())
+
+	var breweries []testBreweryDocument
+	for result.Next() {
+		var doc testBreweryDocument
+		err := result.Row(&doc)
+		suite.Require().Nil(err, err)
+		breweries = append(breweries, doc)
+	}
+	suite.Assert().Equal(dataset.Results[:2], breweries)
+	suite.Require().NotNil(result.Err())
+
+	result = newQueryResult(newReader())
+
+	var doc testBreweryDocument
+	err = result.One(&doc)
+	suite.Require().NotNil(err)
+	suite.Assert().Equal(dataset.Results[0], doc)
+}
+
 func (suite *UnitTestSuite) TestQueryResultsCloseErr() {
 	reader := &mockQueryRowReader{
 		mockQueryRowReaderBase: mockQueryRowReaderBase{
@@ -685,6 +1048,91 @@ func (suite *UnitTestSuite) TestQueryPositionalParams() {
 	suite.Require().NotNil(result)
 }
 
+func (suite *UnitTestSuite) TestQueryBothParams() {
+	queryProvider := new(mockQueryProvider)
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	result, err := cluster.Query("SELECT * FROM dataset WHERE name=$1 AND num=$num", &QueryOptions{
+		PositionalParameters: []interface{}{"imafish"},
+		NamedParameters: map[string]interface{}{
+			"num": 1,
+		},
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error was %s", err)
+	}
+	suite.Require().Nil(result)
+	queryProvider.AssertNotCalled(suite.T(), "N1QLQuery")
+	queryProvider.AssertNotCalled(suite.T(), "PreparedN1QLQuery")
+}
+
+func (suite *UnitTestSuite) TestQueryScanVectors() {
+	vectors := map[string]QueryScanVector{
+		"default": {
+			{SeqNo: 12, VbUUID: 1234},
+			{SeqNo: 0, VbUUID: 5678},
+		},
+	}
+
+	opts := &QueryOptions{ScanVectors: vectors}
+	execOpts, err := opts.toMap()
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("at_plus", execOpts["scan_consistency"])
+
+	encoded, err := json.Marshal(execOpts["scan_vectors"])
+	suite.Require().Nil(err, err)
+	suite.Assert().JSONEq(`{"default":[[12,"1234"],[0,"5678"]]}`, string(encoded))
+
+	_, err = (&QueryOptions{ScanVectors: vectors, ScanConsistency: QueryScanConsistencyRequestPlus}).toMap()
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument))
+
+	_, err = (&QueryOptions{ScanVectors: vectors, ConsistentWith: NewMutationState()}).toMap()
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument))
+
+	queryProvider := new(mockQueryProvider)
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+	cli.On("getKvProvider", "default").Return(nil, ErrBucketNotFound)
+
+	cluster := suite.newCluster(cli)
+
+	result, err := cluster.Query("SELECT * FROM default", &QueryOptions{ScanVectors: vectors})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error was %s", err)
+	}
+	suite.Require().Nil(result)
+	queryProvider.AssertNotCalled(suite.T(), "N1QLQuery")
+}
+
+func (suite *UnitTestSuite) TestQueryReadonly() {
+	reader := new(mockQueryRowReader)
+
+	statement := "SELECT * FROM dataset"
+
+	cluster := suite.queryCluster(false, reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal(statement, actualOptions["statement"])
+		suite.Assert().Equal(true, actualOptions["readonly"])
+	})
+
+	result, err := cluster.Query(statement, &QueryOptions{
+		Readonly: true,
+		Adhoc:    true,
+	})
+	suite.Require().Nil(err)
+	suite.Require().NotNil(result)
+}
+
 func (suite *UnitTestSuite) TestQueryClientContextID() {
 	reader := new(mockQueryRowReader)
 
@@ -760,6 +1208,150 @@ func (suite *UnitTestSuite) TestQueryNoMetrics() {
 	suite.Assert().Zero(metadata.Metrics.WarningCount)
 }
 
+func (suite *UnitTestSuite) TestQueryControls() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	dataset.jsonQueryResponse.Controls = map[string]interface{}{
+		"scan_consistency": "request_plus",
+		"use_cbo":          "true",
+	}
+
+	reader := &mockQueryRowReader{
+		Dataset: dataset.Results,
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+			Suite: suite,
+		},
+	}
+
+	cluster := suite.queryCluster(false, reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal(true, actualOptions["controls"])
+		suite.Assert().Equal("request_plus", actualOptions["scan_consistency"])
+	})
+
+	result, err := cluster.Query("SELECT * FROM dataset", &QueryOptions{
+		ScanConsistency: QueryScanConsistencyRequestPlus,
+		Controls:        true,
+		Adhoc:           true,
+	})
+	suite.Require().Nil(err, err)
+
+	suite.assertQueryBeerResult(dataset, result)
+
+	metadata, err := result.MetaData()
+	suite.Require().Nil(err, err)
+	suite.Require().NotNil(metadata.Controls)
+	suite.Assert().Equal("request_plus", metadata.Controls.ScanConsistency)
+	suite.Assert().Equal("true", metadata.Controls.Raw["use_cbo"])
+
+	dataset.jsonQueryResponse.Controls = nil
+	var meta QueryMetaData
+	err = meta.fromData(dataset.jsonQueryResponse)
+	suite.Require().Nil(err, err)
+	suite.Assert().Nil(meta.Controls)
+}
+
+func (suite *UnitTestSuite) TestQueryProfile() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	dataset.jsonQueryResponse.Profile = json.RawMessage(`{
+		"phaseTimes": {"authorize": "1.5ms", "fetch": "10ms"},
+		"phaseCounts": {"fetch": 16},
+		"phaseOperators": {"authorize": 1, "fetch": 1},
+		"executionTimings": {
+			"#operator": "Sequence",
+			"#stats": {"#phaseSwitches": 1, "execTime": "2µs"},
+			"~children": [
+				{"#operator": "Authorize", "#stats": {"#itemsOut": 1, "servTime": "1.5ms"}},
+				{"#operator": "Parallel", "~child": {"#operator": "Fetch", "#stats": {"#itemsIn": 16, "#itemsOut": 16}}}
+			]
+		}
+	}`)
+
+	reader := &mockQueryRowReader{
+		Dataset: dataset.Results,
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+			Suite: suite,
+		},
+	}
+
+	cluster := suite.queryCluster(false, reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal("timings", actualOptions["profile"])
+	})
+
+	result, err := cluster.Query("SELECT * FROM dataset", &QueryOptions{
+		Profile: QueryProfileModeTimings,
+		Adhoc:   true,
+	})
+	suite.Require().Nil(err, err)
+
+	suite.assertQueryBeerResult(dataset, result)
+
+	metadata, err := result.MetaData()
+	suite.Require().Nil(err, err)
+	suite.Require().IsType(map[string]interface{}{}, metadata.Profile)
+	suite.Require().NotNil(metadata.ParsedProfile)
+
+	profile := metadata.ParsedProfile
+	suite.Assert().Equal(map[string]time.Duration{
+		"authorize": 1500 * time.Microsecond,
+		"fetch":     10 * time.Millisecond,
+	}, profile.PhaseTimes)
+	suite.Assert().Equal(map[string]uint64{"fetch": 16}, profile.PhaseCounts)
+	suite.Assert().Equal(map[string]uint64{"authorize": 1, "fetch": 1}, profile.PhaseOperators)
+	suite.Assert().Contains(profile.Raw, "executionTimings")
+
+	suite.Assert().Equal(&QueryOperatorProfile{
+		Operator:      "Sequence",
+		PhaseSwitches: 1,
+		ExecTime:      2 * time.Microsecond,
+		Children: []QueryOperatorProfile{
+			{Operator: "Authorize", ItemsOut: 1, ServTime: 1500 * time.Microsecond},
+			{Operator: "Parallel", Children: []QueryOperatorProfile{
+				{Operator: "Fetch", ItemsIn: 16, ItemsOut: 16},
+			}},
+		},
+	}, profile.ExecutionTimings)
+
+	suite.Assert().Equal(metadata.Metrics.ExecutionTime, metadata.ExecutionTime())
+	suite.Assert().Equal(metadata.Metrics.ResultCount, metadata.ResultCount())
+	suite.Assert().Equal(metadata.Metrics.ResultSize, metadata.ResultSize())
+	suite.Assert().Equal(metadata.Metrics.SortCount, metadata.SortCount())
+	suite.Assert().Equal(metadata.Metrics.MutationCount, metadata.MutationCount())
+
+	// A profile which cannot be decoded is still returned as it was received.
+	dataset.jsonQueryResponse.Profile = json.RawMessage(`{"phaseCounts": {"fetch": "many"}}`)
+	var meta QueryMetaData
+	err = meta.fromData(dataset.jsonQueryResponse)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(map[string]interface{}{"phaseCounts": map[string]interface{}{"fetch": "many"}}, meta.Profile)
+	suite.Assert().Nil(meta.ParsedProfile)
+
+	dataset.jsonQueryResponse.Profile = nil
+	meta = QueryMetaData{}
+	err = meta.fromData(dataset.jsonQueryResponse)
+	suite.Require().Nil(err, err)
+	suite.Assert().Nil(meta.Profile)
+	suite.Assert().Nil(meta.ParsedProfile)
+}
+
 func (suite *UnitTestSuite) TestQueryRaw() {
 	var dataset testQueryDataset
 	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
@@ -833,3 +1425,201 @@ func (suite *UnitTestSuite) TestQueryPreserveExpiry() {
 	suite.Require().Nil(err)
 	suite.Require().NotNil(result)
 }
+
+func (suite *UnitTestSuite) TestQueryPreparedStatementCache() {
+	statement := "SELECT * FROM dataset"
+	newReader := func() *mockQueryRowReader {
+		return &mockQueryRowReader{
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Suite: suite,
+				PName: "prepared-name",
+			},
+		}
+	}
+
+	var payloads []map[string]interface{}
+	recordPayload := func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		suite.Require().Nil(json.Unmarshal(opts.Payload, &actualOptions))
+		payloads = append(payloads, actualOptions)
+	}
+
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Run(recordPayload).
+		Return(newReader(), nil).
+		Twice()
+	queryProvider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Run(recordPayload).
+		Return(nil, &gocbcore.N1QLError{
+			InnerError: gocbcore.ErrPreparedStatementFailure,
+			Errors:     []gocbcore.N1QLErrorDesc{{Code: 4040, Message: "No such prepared statement"}},
+		}).
+		Once()
+	queryProvider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Run(recordPayload).
+		Return(newReader(), nil).
+		Once()
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	cluster := suite.newCluster(cli)
+	cache := cluster.PreparedStatementCache()
+
+	// The first execution prepares the statement, the second uses the cached prepared statement.
+	_, err := cluster.Query(statement, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(1, cache.Len())
+
+	_, err = cluster.Query(statement, nil)
+	suite.Require().Nil(err, err)
+
+	// A stale prepared statement is evicted and the statement is prepared again.
+	_, err = cluster.Query(statement, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(1, cache.Len())
+
+	suite.Require().Len(payloads, 4)
+	for _, i := range []int{0, 3} {
+		suite.Assert().Equal("PREPARE "+statement, payloads[i]["statement"])
+		suite.Assert().Equal(true, payloads[i]["auto_execute"])
+	}
+	for _, i := range []int{1, 2} {
+		suite.Assert().Equal("prepared-name", payloads[i]["prepared"])
+		suite.Assert().NotContains(payloads[i], "statement")
+	}
+	queryProvider.AssertNotCalled(suite.T(), "PreparedN1QLQuery", mock.Anything, mock.Anything)
+
+	cache.InvalidatePreparedStatement(statement)
+	suite.Assert().Equal(0, cache.Len())
+}
+
+func (suite *UnitTestSuite) TestQueryPreparedLegacyServer() {
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(nil, &gocbcore.N1QLError{
+			InnerError: errors.New("unrecognized parameter"),
+			Errors:     []gocbcore.N1QLErrorDesc{{Code: 1065, Message: "Unrecognized parameter in request: auto_execute"}},
+		}).
+		Once()
+	queryProvider.
+		On("PreparedN1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+			var actualOptions map[string]interface{}
+			suite.Require().Nil(json.Unmarshal(opts.Payload, &actualOptions))
+			suite.Assert().Equal("SELECT 1", actualOptions["statement"])
+		}).
+		Return(&mockQueryRowReader{mockQueryRowReaderBase: mockQueryRowReaderBase{Suite: suite}}, nil).
+		Once()
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	_, err := cluster.Query("SELECT 1", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Zero(cluster.PreparedStatementCache().Len())
+	queryProvider.AssertExpectations(suite.T())
+}
+
+func (suite *UnitTestSuite) TestPreparedStatementCacheEviction() {
+	cache := newPreparedStatementCache(2)
+
+	cache.put(preparedStatementKey{statement: "one"}, "p1")
+	cache.put(preparedStatementKey{statement: "two"}, "p2")
+	cache.put(preparedStatementKey{statement: "two", queryContext: "default.scope"}, "p3")
+	suite.Assert().Equal(2, cache.Len())
+
+	_, ok := cache.get(preparedStatementKey{statement: "one"})
+	suite.Assert().False(ok)
+
+	name, ok := cache.get(preparedStatementKey{statement: "two"})
+	suite.Require().True(ok)
+	suite.Assert().Equal("p2", name)
+
+	cache.InvalidatePreparedStatement("two")
+	suite.Assert().Equal(0, cache.Len())
+
+	cache.put(preparedStatementKey{statement: "one"}, "p1")
+	cache.ClearPreparedStatements()
+	suite.Assert().Equal(0, cache.Len())
+}
+
+func (suite *UnitTestSuite) TestQueryDeadlineFromContext() {
+	reader := &mockQueryRowReader{
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Suite: suite,
+		},
+	}
+
+	// gocbcore replaces the timeout in the payload with the time remaining until the deadline on every attempt, so the
+	// deadline is the only way for the client deadline to reach the server.
+	var deadline time.Time
+	var clientContextID interface{}
+	runFn := func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+		deadline = opts.Deadline
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		clientContextID = actualOptions["client_context_id"]
+	}
+
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", mock.Anything, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Run(runFn).
+		Return(reader, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctxDeadline, _ := ctx.Deadline()
+
+	_, err := cluster.Query("SELECT 1=1", &QueryOptions{
+		Adhoc:   true,
+		Timeout: 10 * time.Second,
+		Context: ctx,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(ctxDeadline, deadline)
+	suite.Assert().NotEmpty(clientContextID)
+
+	_, err = cluster.Query("SELECT 1=1", &QueryOptions{
+		Adhoc:   true,
+		Timeout: time.Second,
+		Context: ctx,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().True(deadline.Before(ctxDeadline), "deadline should have come from the timeout")
+}
+
+func (suite *IntegrationTestSuite) TestClusterQueryServerTimeout() {
+	suite.skipIfUnsupported(QueryFeature)
+
+	// This query runs for far longer than the timeout, so the server must give up on it rather than the client alone.
+	_, err := globalCluster.Query("SELECT COUNT(*) FROM ARRAY_RANGE(0, 100000) AS a, ARRAY_RANGE(0, 100000) AS b",
+		&QueryOptions{
+			Adhoc:   true,
+			Timeout: 500 * time.Millisecond,
+		})
+	if !errors.Is(err, ErrTimeout) {
+		suite.T().Fatalf("Expected error to be timeout but was %v", err)
+	}
+}
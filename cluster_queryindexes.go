@@ -12,7 +12,8 @@ import (
 
 // QueryIndexManager provides methods for performing Couchbase query index management.
 type QueryIndexManager struct {
-	provider queryIndexQueryProvider
+	provider     queryIndexQueryProvider
+	mgmtProvider mgmtProvider
 
 	globalTimeout time.Duration
 	tracer        RequestTracer
@@ -72,7 +73,7 @@ func (qm *QueryIndexManager) doQuery(q string, opts *QueryOptions) ([][]byte, er
 
 	result, err := qm.provider.Query(q, opts)
 	if err != nil {
-		return nil, qm.tryParseErrorMessage(err)
+		return nil, maybeWrapContextErr(opts.Context, qm.tryParseErrorMessage(err))
 	}
 
 	var rows [][]byte
@@ -87,7 +88,7 @@ func (qm *QueryIndexManager) doQuery(q string, opts *QueryOptions) ([][]byte, er
 	}
 	err = result.Err()
 	if err != nil {
-		return nil, qm.tryParseErrorMessage(err)
+		return nil, maybeWrapContextErr(opts.Context, qm.tryParseErrorMessage(err))
 	}
 
 	return rows, nil
@@ -672,7 +673,9 @@ func (qm *QueryIndexManager) BuildDeferredIndexes(bucketName string, opts *Build
 	return deferredList, nil
 }
 
-func checkIndexesActive(indexes []QueryIndex, checkList []string) (bool, error) {
+// checkIndexesActive reports the state of each index in the watch list, and of the primary index if watchPrimary is
+// set, along with whether all of those indexes are online.
+func checkIndexesActive(indexes []QueryIndex, checkList []string, watchPrimary bool) ([]QueryIndexProgress, bool, error) {
 	var checkIndexes []QueryIndex
 	for i := 0; i < len(checkList); i++ {
 		indexName := checkList[i]
@@ -686,21 +689,61 @@ func checkIndexesActive(indexes []QueryIndex, checkList []string) (bool, error)
 	}
 
 	if len(checkIndexes) != len(checkList) {
-		return false, ErrIndexNotFound
+		return nil, false, ErrIndexNotFound
 	}
 
+	if watchPrimary {
+		var foundPrimary bool
+		for j := 0; j < len(indexes); j++ {
+			if indexes[j].IsPrimary {
+				foundPrimary = true
+				checkIndexes = append(checkIndexes, indexes[j])
+				break
+			}
+		}
+		if !foundPrimary {
+			return nil, false, ErrIndexNotFound
+		}
+	}
+
+	allOnline := true
+	progress := make([]QueryIndexProgress, len(checkIndexes))
 	for i := 0; i < len(checkIndexes); i++ {
-		if checkIndexes[i].State != "online" {
-			return false, nil
+		progress[i] = QueryIndexProgress{
+			Name:  checkIndexes[i].Name,
+			State: checkIndexes[i].State,
+		}
+		if checkIndexes[i].State == "online" {
+			progress[i].Progress = 100
+		} else {
+			allOnline = false
 		}
 	}
-	return true, nil
+	return progress, allOnline, nil
+}
+
+// QueryIndexProgress describes the state of an index being watched by WatchIndexes.
+// UNCOMMITTED: This API may change in the future.
+type QueryIndexProgress struct {
+	Name  string
+	State string
+
+	// Progress is the percentage of the index which has been built, as reported by the index service. This is 100
+	// once the index is online, and 0 if the index service could not be asked for the progress of the build.
+	Progress int
 }
 
 // WatchQueryIndexOptions is the set of options available to the query indexes Watch operation.
 type WatchQueryIndexOptions struct {
+	// WatchPrimary adds the primary index to the set of indexes being watched, whatever its name.
 	WatchPrimary bool
 
+	// OnProgress is invoked after each poll with the state of every index being watched, including the primary index
+	// if WatchPrimary is set. Setting OnProgress causes the build progress of each index to be fetched from the index
+	// service on every poll.
+	// UNCOMMITTED: This API may change in the future.
+	OnProgress func([]QueryIndexProgress)
+
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
@@ -730,10 +773,6 @@ func (qm *QueryIndexManager) WatchIndexes(bucketName string, watchList []string,
 	span := createSpan(qm.tracer, opts.ParentSpan, "manager_query_watch_indexes", "management")
 	defer span.End()
 
-	if opts.WatchPrimary {
-		watchList = append(watchList, "#primary")
-	}
-
 	deadline := time.Now().Add(timeout)
 
 	curInterval := 50 * time.Millisecond
@@ -756,11 +795,19 @@ func (qm *QueryIndexManager) WatchIndexes(bucketName string, watchList []string,
 			return err
 		}
 
-		allOnline, err := checkIndexesActive(indexes, watchList)
+		progress, allOnline, err := checkIndexesActive(indexes, watchList, opts.WatchPrimary)
 		if err != nil {
 			return err
 		}
 
+		if opts.OnProgress != nil {
+			if !allOnline {
+				qm.addIndexBuildProgress(opts.Context, span, bucketName, opts.ScopeName, opts.CollectionName,
+					time.Until(deadline), opts.RetryStrategy, progress)
+			}
+			opts.OnProgress(progress)
+		}
+
 		if allOnline {
 			break
 		}
@@ -783,3 +830,236 @@ func (qm *QueryIndexManager) WatchIndexes(bucketName string, watchList []string,
 
 	return nil
 }
+
+const defaultWaitForQueryIndexPollInterval = 500 * time.Millisecond
+
+// WaitForQueryIndexOptions is the set of options available to the query indexes WaitForIndex operation.
+// UNCOMMITTED: This API may change in the future.
+type WaitForQueryIndexOptions struct {
+	// PollInterval is how long to wait between each check of the index state, defaults to 500ms.
+	PollInterval time.Duration
+
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	ScopeName      string
+	CollectionName string
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// WaitForIndex waits for the named index to reach the given state, e.g. "online" once it has been built or "deferred"
+// once a deferred index has been created. If the index does not exist, or stops existing whilst waiting, then
+// ErrIndexNotFound is returned. If the index has not reached the state before the timeout then
+// ErrUnambiguousTimeout is returned.
+// UNCOMMITTED: This API may change in the future.
+func (qm *QueryIndexManager) WaitForIndex(bucketName, indexName, state string, timeout time.Duration,
+	opts *WaitForQueryIndexOptions) error {
+	if opts == nil {
+		opts = &WaitForQueryIndexOptions{}
+	}
+	if indexName == "" {
+		return makeInvalidArgumentsError("an invalid index name was specified")
+	}
+	if state == "" {
+		return makeInvalidArgumentsError("an invalid index state was specified")
+	}
+	if err := qm.validateScopeCollection(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer qm.meter.ValueRecord(meterValueServiceManagement, "manager_query_wait_for_index", start)
+
+	span := createSpan(qm.tracer, opts.ParentSpan, "manager_query_wait_for_index", "management")
+	defer span.End()
+
+	pollInterval := opts.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultWaitForQueryIndexPollInterval
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if deadline.Before(time.Now()) {
+			return ErrUnambiguousTimeout
+		}
+
+		indexes, err := qm.getAllIndexes(
+			opts.Context,
+			span,
+			bucketName,
+			&GetAllQueryIndexesOptions{
+				Timeout:        time.Until(deadline),
+				RetryStrategy:  opts.RetryStrategy,
+				ScopeName:      opts.ScopeName,
+				CollectionName: opts.CollectionName,
+			})
+		if err != nil {
+			return err
+		}
+
+		var found bool
+		for _, index := range indexes {
+			if index.Name != indexName {
+				continue
+			}
+
+			if index.State == state {
+				return nil
+			}
+			found = true
+			break
+		}
+		if !found {
+			return wrapError(ErrIndexNotFound, fmt.Sprintf("index %s not found", indexName))
+		}
+
+		// Make sure we don't sleep past our overall deadline, if we adjust the
+		// deadline then it will be caught at the top of this loop as a timeout.
+		sleepDeadline := time.Now().Add(pollInterval)
+		if sleepDeadline.After(deadline) {
+			sleepDeadline = deadline
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(sleepDeadline)):
+		}
+	}
+}
+
+// WaitUntilQueryIndexesReadyOptions is the set of options available to the query indexes WaitUntilReady operation.
+// UNCOMMITTED: This API may change in the future.
+type WaitUntilQueryIndexesReadyOptions struct {
+	// PollInterval is how long to wait between each check of the index states, defaults to 500ms.
+	PollInterval time.Duration
+
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	ScopeName      string
+	CollectionName string
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// WaitUntilReady waits for all of the named indexes to be online, e.g. so that an application does not serve traffic
+// until the indexes which it depends on can be used. Unlike WatchIndexes, which follows the build of indexes which
+// have just been created, an index which does not exist is not an error and is waited for in the same way as one
+// which is still building. If any of the indexes is not online before the timeout then a *QueryIndexesNotReadyError
+// wrapping the timeout error is returned, reporting the state of each index which is not as of the last check. The
+// same error, wrapping the error of the Context, is returned if the Context is done first.
+// UNCOMMITTED: This API may change in the future.
+func (qm *QueryIndexManager) WaitUntilReady(bucketName string, indexNames []string, timeout time.Duration,
+	opts *WaitUntilQueryIndexesReadyOptions) error {
+	if opts == nil {
+		opts = &WaitUntilQueryIndexesReadyOptions{}
+	}
+	if len(indexNames) == 0 {
+		return makeInvalidArgumentsError("at least one index name must be specified")
+	}
+	if err := qm.validateScopeCollection(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer qm.meter.ValueRecord(meterValueServiceManagement, "manager_query_wait_until_ready", start)
+
+	span := createSpan(qm.tracer, opts.ParentSpan, "manager_query_wait_until_ready", "management")
+	defer span.End()
+
+	pollInterval := opts.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultWaitForQueryIndexPollInterval
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	deadline := time.Now().Add(timeout)
+	var notOnline map[string]string
+	for {
+		if deadline.Before(time.Now()) {
+			return &QueryIndexesNotReadyError{
+				InnerError: ErrUnambiguousTimeout,
+				Indexes:    notOnline,
+			}
+		}
+
+		indexes, err := qm.getAllIndexes(
+			opts.Context,
+			span,
+			bucketName,
+			&GetAllQueryIndexesOptions{
+				Timeout:        time.Until(deadline),
+				RetryStrategy:  opts.RetryStrategy,
+				ScopeName:      opts.ScopeName,
+				CollectionName: opts.CollectionName,
+			})
+		if err != nil {
+			// Running out of time part way through a check still leaves the states from the previous check to report.
+			if errors.Is(err, ErrTimeout) || ctx.Err() != nil {
+				return &QueryIndexesNotReadyError{
+					InnerError: err,
+					Indexes:    notOnline,
+				}
+			}
+
+			return err
+		}
+
+		notOnline = queryIndexesNotOnline(indexes, indexNames)
+		if len(notOnline) == 0 {
+			return nil
+		}
+
+		// Make sure we don't sleep past our overall deadline, if we adjust the
+		// deadline then it will be caught at the top of this loop as a timeout.
+		sleepDeadline := time.Now().Add(pollInterval)
+		if sleepDeadline.After(deadline) {
+			sleepDeadline = deadline
+		}
+
+		select {
+		case <-ctx.Done():
+			return &QueryIndexesNotReadyError{
+				InnerError: ctx.Err(),
+				Indexes:    notOnline,
+			}
+		case <-time.After(time.Until(sleepDeadline)):
+		}
+	}
+}
+
+// queryIndexesNotOnline returns the state of each of the named indexes which is not online.
+func queryIndexesNotOnline(indexes []QueryIndex, indexNames []string) map[string]string {
+	notOnline := make(map[string]string)
+	for _, name := range indexNames {
+		state := queryIndexStateAbsent
+		for _, index := range indexes {
+			if index.Name == name {
+				state = index.State
+				break
+			}
+		}
+
+		if state != "online" {
+			notOnline[name] = state
+		}
+	}
+
+	return notOnline
+}
@@ -0,0 +1,215 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryIndexStats contains runtime statistics for a single GSI index.
+// UNCOMMITTED: This API may change in the future.
+type QueryIndexStats struct {
+	// ItemsCount is the number of items currently indexed.
+	ItemsCount uint64
+	// DataSize is the size of the indexed data, in bytes.
+	DataSize uint64
+	// NumDocsPending is the number of documents pending to be indexed.
+	NumDocsPending uint64
+}
+
+type jsonIndexStatsResponse struct {
+	Op struct {
+		Samples map[string][]float64 `json:"samples"`
+	} `json:"op"`
+}
+
+// GetQueryIndexStatsOptions is the set of options available to the query indexes GetIndexStats operation.
+// UNCOMMITTED: This API may change in the future.
+type GetQueryIndexStatsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// GetIndexStats returns the most recent runtime statistics for each of the indexes on a bucket, keyed by the index
+// name as reported by the server.
+// UNCOMMITTED: This API may change in the future.
+func (qm *QueryIndexManager) GetIndexStats(bucketName string, opts *GetQueryIndexStatsOptions) (map[string]QueryIndexStats, error) {
+	if bucketName == "" {
+		return nil, makeInvalidArgumentsError("bucket name cannot be empty")
+	}
+
+	if opts == nil {
+		opts = &GetQueryIndexStatsOptions{}
+	}
+
+	start := time.Now()
+	defer qm.meter.ValueRecord(meterValueServiceManagement, "manager_query_get_index_stats", start)
+
+	path := fmt.Sprintf("/pools/default/buckets/@index-%s/stats", url.PathEscape(bucketName))
+	span := createSpan(qm.tracer, opts.ParentSpan, "manager_query_get_index_stats", "management")
+	span.SetAttribute("db.name", bucketName)
+	span.SetAttribute("db.operation", "GET "+path)
+	defer span.End()
+
+	req := mgmtRequest{
+		Service:       ServiceTypeManagement,
+		Method:        "GET",
+		Path:          path,
+		IsIdempotent:  true,
+		RetryStrategy: opts.RetryStrategy,
+		UniqueID:      uuid.New().String(),
+		Timeout:       opts.Timeout,
+		parentSpanCtx: span.Context(),
+	}
+
+	resp, err := qm.mgmtProvider.executeMgmtRequest(opts.Context, req)
+	if err != nil {
+		return nil, makeGenericMgmtError(err, &req, resp, "")
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	if resp.StatusCode == 404 {
+		return nil, makeGenericMgmtError(ErrBucketNotFound, &req, resp, "")
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, makeMgmtBadStatusError("failed to get index stats", &req, resp)
+	}
+
+	var statsData jsonIndexStatsResponse
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&statsData)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseQueryIndexStats(statsData.Op.Samples), nil
+}
+
+// parseQueryIndexStats converts samples of the form "index/<index name>/<stat name>" into stats keyed by index name,
+// using the most recent sample for each stat.
+func parseQueryIndexStats(samples map[string][]float64) map[string]QueryIndexStats {
+	stats := make(map[string]QueryIndexStats)
+	for key, values := range samples {
+		if !strings.HasPrefix(key, "index/") || len(values) == 0 {
+			continue
+		}
+
+		sepIdx := strings.LastIndex(key, "/")
+		if sepIdx <= len("index/") {
+			// Aggregate stats such as "index/fragmentation" are not specific to any one index.
+			continue
+		}
+
+		indexName := key[len("index/"):sepIdx]
+		statName := key[sepIdx+1:]
+		value := uint64(values[len(values)-1])
+
+		indexStats := stats[indexName]
+		switch statName {
+		case "items_count":
+			indexStats.ItemsCount = value
+		case "data_size":
+			indexStats.DataSize = value
+		case "num_docs_pending":
+			indexStats.NumDocsPending = value
+		default:
+			continue
+		}
+		stats[indexName] = indexStats
+	}
+
+	return stats
+}
+
+type jsonIndexStatusResponse struct {
+	Indexes []jsonIndexStatus `json:"indexes"`
+}
+
+type jsonIndexStatus struct {
+	Index      string `json:"index"`
+	Bucket     string `json:"bucket"`
+	Scope      string `json:"scope"`
+	Collection string `json:"collection"`
+	Progress   int    `json:"progress"`
+}
+
+// addIndexBuildProgress fills in the build progress of each index which is not yet online from the index status
+// reported by the cluster manager. Failing to fetch the status is not fatal, as it only affects progress reporting.
+func (qm *QueryIndexManager) addIndexBuildProgress(ctx context.Context, parent RequestSpan, bucketName, scopeName,
+	collectionName string, timeout time.Duration, retryStrategy RetryStrategy, progress []QueryIndexProgress) {
+	if qm.mgmtProvider == nil {
+		return
+	}
+
+	span := createSpan(qm.tracer, parent, "manager_query_get_index_status", "management")
+	defer span.End()
+
+	req := mgmtRequest{
+		Service:       ServiceTypeManagement,
+		Method:        "GET",
+		Path:          "/indexStatus",
+		IsIdempotent:  true,
+		RetryStrategy: retryStrategy,
+		UniqueID:      uuid.New().String(),
+		Timeout:       timeout,
+		parentSpanCtx: span.Context(),
+	}
+
+	resp, err := qm.mgmtProvider.executeMgmtRequest(ctx, req)
+	if err != nil {
+		logDebugf("Failed to fetch index status: %v", err)
+		return
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	if resp.StatusCode != 200 {
+		logDebugf("Failed to fetch index status, status code %d", resp.StatusCode)
+		return
+	}
+
+	var statusData jsonIndexStatusResponse
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&statusData)
+	if err != nil {
+		logDebugf("Failed to decode index status: %v", err)
+		return
+	}
+
+	if scopeName == "" {
+		scopeName = "_default"
+	}
+	if collectionName == "" {
+		collectionName = "_default"
+	}
+
+	for _, status := range statusData.Indexes {
+		if status.Bucket != bucketName {
+			continue
+		}
+		// Servers prior to 7.0 do not report the scope or collection of an index.
+		if status.Scope != "" && status.Scope != scopeName {
+			continue
+		}
+		if status.Collection != "" && status.Collection != collectionName {
+			continue
+		}
+
+		for i := range progress {
+			if progress[i].Name == status.Index && progress[i].State != "online" {
+				progress[i].Progress = status.Progress
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package gocb
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestQueryIndexManagerGetIndexStats() {
+	body := `{"op":{"samples":{
+		"index/ih/items_count":[10,12],
+		"index/ih/data_size":[1024,2048],
+		"index/ih/num_docs_pending":[3,0],
+		"index/ih/disk_size":[4096,4096],
+		"index/#primary/items_count":[100,101],
+		"index/fragmentation":[1,2],
+		"index_memory_quota":[500,500]
+	},"samplesCount":2}}`
+	resp := &mgmtResponse{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/pools/default/buckets/@index-mybucket/stats", req.Path)
+			suite.Assert().True(req.IsIdempotent)
+			suite.Assert().Equal("GET", req.Method)
+		}).
+		Return(resp, nil)
+
+	mgr := &QueryIndexManager{
+		mgmtProvider: mockProvider,
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	stats, err := mgr.GetIndexStats("mybucket", nil)
+	suite.Require().Nil(err, err)
+
+	suite.Require().Len(stats, 2)
+	suite.Assert().Equal(QueryIndexStats{
+		ItemsCount:     12,
+		DataSize:       2048,
+		NumDocsPending: 0,
+	}, stats["ih"])
+	suite.Assert().Equal(QueryIndexStats{
+		ItemsCount: 101,
+	}, stats["#primary"])
+}
+
+func (suite *UnitTestSuite) TestQueryIndexManagerGetIndexStatsBucketNotFound() {
+	resp := &mgmtResponse{
+		StatusCode: 404,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte("Requested resource not found."))),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(resp, nil)
+
+	mgr := &QueryIndexManager{
+		mgmtProvider: mockProvider,
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	_, err := mgr.GetIndexStats("mybucket", nil)
+	if !errors.Is(err, ErrBucketNotFound) {
+		suite.T().Fatalf("Expected bucket not found error, %s", err)
+	}
+}
@@ -1,8 +1,14 @@
 package gocb
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io/ioutil"
 	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
 )
 
 func (suite *IntegrationTestSuite) TestQueryIndexesCrud() {
@@ -536,3 +542,129 @@ func (suite *UnitTestSuite) TestQueryIndexesParsing() {
 	suite.Assert().Empty(index.Condition)
 	suite.Assert().Equal("HASH(`_type`)", index.Partition)
 }
+
+func (suite *UnitTestSuite) TestQueryIndexesWaitForIndex() {
+	var dataset testQueryIndexDataset
+	err := loadJSONTestDataset("query_index_response", &dataset)
+	suite.Require().Nil(err, err)
+
+	newMgr := func() *QueryIndexManager {
+		reader := &mockQueryIndexRowReader{
+			Dataset: dataset.Results,
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+				Suite: suite,
+			},
+		}
+
+		return &QueryIndexManager{
+			provider: suite.queryCluster(false, reader, nil),
+			tracer:   &NoopTracer{},
+			meter:    &meterWrapper{meter: &NoopMeter{}},
+		}
+	}
+
+	err = newMgr().WaitForIndex("mybucket", "ih", "online", 5*time.Second, nil)
+	suite.Require().Nil(err, err)
+
+	err = newMgr().WaitForIndex("mybucket", "missing", "online", 5*time.Second, nil)
+	if !errors.Is(err, ErrIndexNotFound) {
+		suite.T().Fatalf("Expected index not found error but was %v", err)
+	}
+
+	err = newMgr().WaitForIndex("mybucket", "ih", "", 5*time.Second, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestQueryIndexesWatchIndexesProgress() {
+	var dataset testQueryIndexDataset
+	err := loadJSONTestDataset("query_index_response", &dataset)
+	suite.Require().Nil(err, err)
+
+	building := make(map[string]interface{})
+	for k, v := range dataset.Results[0] {
+		building[k] = v
+	}
+	building["state"] = "building"
+	primary := map[string]interface{}{
+		"name":         "#primary",
+		"is_primary":   true,
+		"state":        "online",
+		"keyspace_id":  "test",
+		"namespace_id": "default",
+		"using":        "gsi",
+	}
+
+	var polls int
+	newReader := func() queryRowReader {
+		polls++
+		index := dataset.Results[0]
+		if polls == 1 {
+			index = building
+		}
+
+		return &mockQueryIndexRowReader{
+			Dataset: []map[string]interface{}{primary, index},
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+				Suite: suite,
+			},
+		}
+	}
+
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(func(context.Context, gocbcore.N1QLQueryOptions) queryRowReader {
+			return newReader()
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+	cluster := suite.newCluster(cli)
+
+	mgmtProvider := new(mockMgmtProvider)
+	mgmtProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+			suite.Assert().Equal("/indexStatus", req.Path)
+		}).
+		Return(&mgmtResponse{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewReader([]byte(`{"indexes":[
+				{"index":"ih","bucket":"mybucket","scope":"_default","collection":"_default","progress":45},
+				{"index":"ih","bucket":"otherbucket","scope":"_default","collection":"_default","progress":80}
+			]}`))),
+		}, nil).
+		Once()
+
+	mgr := &QueryIndexManager{
+		provider:     cluster,
+		mgmtProvider: mgmtProvider,
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	var reports [][]QueryIndexProgress
+	err = mgr.WatchIndexes("mybucket", []string{"ih"}, 5*time.Second, &WatchQueryIndexOptions{
+		WatchPrimary: true,
+		OnProgress: func(progress []QueryIndexProgress) {
+			reports = append(reports, progress)
+		},
+	})
+	suite.Require().Nil(err, err)
+
+	suite.Require().Len(reports, 2)
+	suite.Assert().Equal([]QueryIndexProgress{
+		{Name: "ih", State: "building", Progress: 45},
+		{Name: "#primary", State: "online", Progress: 100},
+	}, reports[0])
+	suite.Assert().Equal([]QueryIndexProgress{
+		{Name: "ih", State: "online", Progress: 100},
+		{Name: "#primary", State: "online", Progress: 100},
+	}, reports[1])
+	mgmtProvider.AssertNumberOfCalls(suite.T(), "executeMgmtRequest", 1)
+}
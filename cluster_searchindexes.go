@@ -95,10 +95,22 @@ func (si *SearchIndex) toData() (jsonSearchIndex, error) {
 type SearchIndexManager struct {
 	mgmtProvider mgmtProvider
 
+	// bucketName and scopeName are set when managing the indexes of a single scope.
+	bucketName string
+	scopeName  string
+
 	tracer RequestTracer
 	meter  *meterWrapper
 }
 
+func (sm *SearchIndexManager) indexesPath() string {
+	if sm.scopeName == "" {
+		return "/api/index"
+	}
+
+	return fmt.Sprintf("/api/bucket/%s/scope/%s/index", sm.bucketName, sm.scopeName)
+}
+
 func (sm *SearchIndexManager) checkForRateLimitError(statusCode uint32, errMsg string) error {
 	errMsg = strings.ToLower(errMsg)
 
@@ -174,13 +186,14 @@ func (sm *SearchIndexManager) GetAllIndexes(opts *GetAllSearchIndexOptions) ([]S
 	defer sm.meter.ValueRecord(meterValueServiceManagement, "manager_search_get_all_indexes", start)
 
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_get_all_indexes", "management")
-	span.SetAttribute("db.operation", "GET /api/index")
+	path := sm.indexesPath()
+	span.SetAttribute("db.operation", "GET "+path)
 	defer span.End()
 
 	req := mgmtRequest{
 		Service:       ServiceTypeSearch,
 		Method:        "GET",
-		Path:          "/api/index",
+		Path:          path,
 		IsIdempotent:  true,
 		RetryStrategy: opts.RetryStrategy,
 		Timeout:       opts.Timeout,
@@ -244,7 +257,7 @@ func (sm *SearchIndexManager) GetIndex(indexName string, opts *GetSearchIndexOpt
 	start := time.Now()
 	defer sm.meter.ValueRecord(meterValueServiceManagement, "manager_search_get_index", start)
 
-	path := fmt.Sprintf("/api/index/%s", indexName)
+	path := fmt.Sprintf("%s/%s", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_get_index", "management")
 	span.SetAttribute("db.operation", "GET "+path)
 	defer span.End()
@@ -317,7 +330,7 @@ func (sm *SearchIndexManager) UpsertIndex(indexDefinition SearchIndex, opts *Ups
 	start := time.Now()
 	defer sm.meter.ValueRecord(meterValueServiceManagement, "manager_search_upsert_index", start)
 
-	path := fmt.Sprintf("/api/index/%s", indexDefinition.Name)
+	path := fmt.Sprintf("%s/%s", sm.indexesPath(), indexDefinition.Name)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_upsert_index", "management")
 	span.SetAttribute("db.operation", "PUT "+path)
 	defer span.End()
@@ -387,7 +400,7 @@ func (sm *SearchIndexManager) DropIndex(indexName string, opts *DropSearchIndexO
 	start := time.Now()
 	defer sm.meter.ValueRecord(meterValueServiceManagement, "manager_search_drop_index", start)
 
-	path := fmt.Sprintf("/api/index/%s", indexName)
+	path := fmt.Sprintf("%s/%s", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_drop_index", "management")
 	span.SetAttribute("db.operation", "DELETE "+path)
 	defer span.End()
@@ -435,7 +448,7 @@ func (sm *SearchIndexManager) AnalyzeDocument(indexName string, doc interface{},
 		return nil, invalidArgumentsError{"indexName cannot be empty"}
 	}
 
-	path := fmt.Sprintf("/api/index/%s/analyzeDoc", indexName)
+	path := fmt.Sprintf("%s/%s/analyzeDoc", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_analyze_document", "management")
 	span.SetAttribute("db.operation", "POST "+path)
 	defer span.End()
@@ -505,7 +518,7 @@ func (sm *SearchIndexManager) GetIndexedDocumentsCount(indexName string, opts *G
 		return 0, invalidArgumentsError{"indexName cannot be empty"}
 	}
 
-	path := fmt.Sprintf("/api/index/%s/count", indexName)
+	path := fmt.Sprintf("%s/%s/count", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_get_indexed_documents_count", "management")
 	span.SetAttribute("db.operation", "GET "+path)
 	defer span.End()
@@ -603,7 +616,7 @@ func (sm *SearchIndexManager) PauseIngest(indexName string, opts *PauseIngestSea
 		return invalidArgumentsError{"indexName cannot be empty"}
 	}
 
-	path := fmt.Sprintf("/api/index/%s/ingestControl/pause", indexName)
+	path := fmt.Sprintf("%s/%s/ingestControl/pause", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_pause_ingest", "management")
 	span.SetAttribute("db.operation", "POST "+path)
 	defer span.End()
@@ -639,7 +652,7 @@ func (sm *SearchIndexManager) ResumeIngest(indexName string, opts *ResumeIngestS
 		return invalidArgumentsError{"indexName cannot be empty"}
 	}
 
-	path := fmt.Sprintf("/api/index/%s/ingestControl/resume", indexName)
+	path := fmt.Sprintf("%s/%s/ingestControl/resume", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_resume_ingest", "management")
 	span.SetAttribute("db.operation", "POST "+path)
 	defer span.End()
@@ -675,7 +688,7 @@ func (sm *SearchIndexManager) AllowQuerying(indexName string, opts *AllowQueryin
 		return invalidArgumentsError{"indexName cannot be empty"}
 	}
 
-	path := fmt.Sprintf("/api/index/%s/queryControl/allow", indexName)
+	path := fmt.Sprintf("%s/%s/queryControl/allow", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_allow_querying", "management")
 	span.SetAttribute("db.operation", "POST "+path)
 	defer span.End()
@@ -711,7 +724,7 @@ func (sm *SearchIndexManager) DisallowQuerying(indexName string, opts *AllowQuer
 		return invalidArgumentsError{"indexName cannot be empty"}
 	}
 
-	path := fmt.Sprintf("/api/index/%s/queryControl/disallow", indexName)
+	path := fmt.Sprintf("%s/%s/queryControl/disallow", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_disallow_querying", "management")
 	span.SetAttribute("db.operation", "POST "+path)
 	defer span.End()
@@ -747,7 +760,7 @@ func (sm *SearchIndexManager) FreezePlan(indexName string, opts *AllowQueryingSe
 		return invalidArgumentsError{"indexName cannot be empty"}
 	}
 
-	path := fmt.Sprintf("/api/index/%s/planFreezeControl/freeze", indexName)
+	path := fmt.Sprintf("%s/%s/planFreezeControl/freeze", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_freeze_plan", "management")
 	span.SetAttribute("db.operation", "POST "+path)
 	defer span.End()
@@ -783,7 +796,7 @@ func (sm *SearchIndexManager) UnfreezePlan(indexName string, opts *AllowQuerying
 		return invalidArgumentsError{"indexName cannot be empty"}
 	}
 
-	path := fmt.Sprintf("/api/index/%s/planFreezeControl/unfreeze", indexName)
+	path := fmt.Sprintf("%s/%s/planFreezeControl/unfreeze", sm.indexesPath(), indexName)
 	span := createSpan(sm.tracer, opts.ParentSpan, "manager_search_unfreeze_plan", "management")
 	span.SetAttribute("db.operation", "POST "+path)
 	defer span.End()
@@ -0,0 +1,177 @@
+package gocb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const searchIndexTypeAlias = "fulltext-alias"
+
+// AliasTargets returns the names of the indexes targeted by a search index alias, or nil if this index is not an
+// alias.
+// UNCOMMITTED: This API may change in the future.
+func (si *SearchIndex) AliasTargets() []string {
+	if si.Type != searchIndexTypeAlias {
+		return nil
+	}
+
+	targets, ok := si.Params["targets"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func newSearchAliasParams(targets []string) map[string]interface{} {
+	targetsMap := make(map[string]interface{}, len(targets))
+	for _, target := range targets {
+		targetsMap[target] = map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"targets": targetsMap,
+	}
+}
+
+// CreateSearchAliasOptions is the set of options available to the search index manager CreateAlias operation.
+// UNCOMMITTED: This API may change in the future.
+type CreateSearchAliasOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// CreateAlias creates a search index alias which targets the given indexes, queries against the alias are run
+// against all of its targets. Every target must be an existing index or alias, otherwise ErrIndexNotFound is
+// returned. If an index or alias with the same name already exists then ErrIndexExists is returned.
+// UNCOMMITTED: This API may change in the future.
+func (sm *SearchIndexManager) CreateAlias(aliasName string, targets []string, opts *CreateSearchAliasOptions) error {
+	if opts == nil {
+		opts = &CreateSearchAliasOptions{}
+	}
+
+	if aliasName == "" {
+		return invalidArgumentsError{"alias name cannot be empty"}
+	}
+
+	_, err := sm.getAliasTargetCandidates(aliasName, targets, &GetAllSearchIndexOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return err
+	}
+
+	return sm.UpsertIndex(SearchIndex{
+		Name:       aliasName,
+		Type:       searchIndexTypeAlias,
+		SourceType: "nil",
+		Params:     newSearchAliasParams(targets),
+	}, &UpsertSearchIndexOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+}
+
+// UpdateSearchAliasOptions is the set of options available to the search index manager UpdateAliasTargets operation.
+// UNCOMMITTED: This API may change in the future.
+type UpdateSearchAliasOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// UpdateAliasTargets replaces the set of indexes targeted by an existing search index alias, allowing an alias to
+// be switched from one index to another, e.g. once a replacement index has finished building.
+// Every target must be an existing index or alias, otherwise ErrIndexNotFound is returned. The update is made
+// against the UUID of the alias as it was read, so if the alias is concurrently modified then the update fails
+// rather than overwriting that change.
+// UNCOMMITTED: This API may change in the future.
+func (sm *SearchIndexManager) UpdateAliasTargets(aliasName string, targets []string, opts *UpdateSearchAliasOptions) error {
+	if opts == nil {
+		opts = &UpdateSearchAliasOptions{}
+	}
+
+	if aliasName == "" {
+		return invalidArgumentsError{"alias name cannot be empty"}
+	}
+
+	indexes, err := sm.getAliasTargetCandidates(aliasName, targets, &GetAllSearchIndexOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return err
+	}
+
+	alias, ok := indexes[aliasName]
+	if !ok {
+		return makeGenericMgmtError(ErrIndexNotFound, nil, nil, fmt.Sprintf("alias %s not found", aliasName))
+	}
+	if alias.Type != searchIndexTypeAlias {
+		return invalidArgumentsError{fmt.Sprintf("%s is not a search index alias", aliasName)}
+	}
+
+	alias.Params = newSearchAliasParams(targets)
+
+	return sm.UpsertIndex(alias, &UpsertSearchIndexOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+}
+
+// getAliasTargetCandidates validates the targets of an alias against the indexes which currently exist, returning
+// those indexes keyed by name.
+func (sm *SearchIndexManager) getAliasTargetCandidates(aliasName string, targets []string,
+	opts *GetAllSearchIndexOptions) (map[string]SearchIndex, error) {
+	if len(targets) == 0 {
+		return nil, invalidArgumentsError{"alias must have at least one target"}
+	}
+
+	indexes, err := sm.GetAllIndexes(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	indexesByName := make(map[string]SearchIndex, len(indexes))
+	for _, index := range indexes {
+		indexesByName[index.Name] = index
+	}
+
+	for _, target := range targets {
+		if target == aliasName {
+			return nil, invalidArgumentsError{"alias cannot target itself"}
+		}
+
+		if _, ok := indexesByName[target]; !ok {
+			return nil, makeGenericMgmtError(ErrIndexNotFound, nil, nil, fmt.Sprintf("alias target %s not found", target))
+		}
+	}
+
+	return indexesByName, nil
+}
@@ -0,0 +1,123 @@
+package gocb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) searchAliasMgmtProvider(putFn func(index jsonSearchIndex)) *mockMgmtProvider {
+	indexesResp := []byte(`{"status":"ok","indexDefs":{"indexDefs":{` +
+		`"idx-blue":{"name":"idx-blue","type":"fulltext-index","uuid":"1"},` +
+		`"idx-green":{"name":"idx-green","type":"fulltext-index","uuid":"2"},` +
+		`"live":{"name":"live","type":"fulltext-alias","uuid":"3","params":{"targets":{"idx-blue":{}}}}}}}`)
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			suite.Assert().Equal(ServiceTypeSearch, req.Service)
+
+			if req.Method == "GET" {
+				suite.Assert().Equal("/api/index", req.Path)
+
+				return &mgmtResponse{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewReader(indexesResp)),
+				}
+			}
+
+			suite.Assert().Equal("PUT", req.Method)
+
+			var index jsonSearchIndex
+			err := json.Unmarshal(req.Body, &index)
+			suite.Require().Nil(err, err)
+			suite.Assert().Equal("/api/index/"+index.Name, req.Path)
+			putFn(index)
+
+			return &mgmtResponse{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"status":"ok"}`))),
+			}
+		}, nil)
+
+	return mockProvider
+}
+
+func (suite *UnitTestSuite) TestSearchIndexesCreateAlias() {
+	var putIndex *jsonSearchIndex
+	mgr := SearchIndexManager{
+		mgmtProvider: suite.searchAliasMgmtProvider(func(index jsonSearchIndex) {
+			putIndex = &index
+		}),
+		tracer: &NoopTracer{},
+		meter:  &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := mgr.CreateAlias("staging", []string{"idx-green"}, nil)
+	suite.Require().Nil(err, err)
+
+	suite.Require().NotNil(putIndex)
+	suite.Assert().Equal("staging", putIndex.Name)
+	suite.Assert().Equal("fulltext-alias", putIndex.Type)
+	suite.Assert().Empty(putIndex.UUID)
+	suite.Assert().Equal(map[string]interface{}{"idx-green": map[string]interface{}{}}, putIndex.Params["targets"])
+
+	putIndex = nil
+	err = mgr.CreateAlias("staging", []string{"idx-missing"}, nil)
+	if !errors.Is(err, ErrIndexNotFound) {
+		suite.T().Fatalf("Expected error to be index not found but was %v", err)
+	}
+	suite.Assert().Nil(putIndex)
+
+	err = mgr.CreateAlias("staging", nil, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestSearchIndexesUpdateAliasTargets() {
+	var putIndex *jsonSearchIndex
+	mgr := SearchIndexManager{
+		mgmtProvider: suite.searchAliasMgmtProvider(func(index jsonSearchIndex) {
+			putIndex = &index
+		}),
+		tracer: &NoopTracer{},
+		meter:  &meterWrapper{meter: &NoopMeter{}},
+	}
+
+	err := mgr.UpdateAliasTargets("live", []string{"idx-green"}, nil)
+	suite.Require().Nil(err, err)
+
+	suite.Require().NotNil(putIndex)
+	suite.Assert().Equal("live", putIndex.Name)
+	suite.Assert().Equal("3", putIndex.UUID)
+	suite.Assert().Equal(map[string]interface{}{"idx-green": map[string]interface{}{}}, putIndex.Params["targets"])
+
+	err = mgr.UpdateAliasTargets("idx-blue", []string{"idx-green"}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	err = mgr.UpdateAliasTargets("missing", []string{"idx-green"}, nil)
+	if !errors.Is(err, ErrIndexNotFound) {
+		suite.T().Fatalf("Expected error to be index not found but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestSearchIndexAliasTargets() {
+	index := SearchIndex{
+		Type: "fulltext-alias",
+		Params: map[string]interface{}{
+			"targets": map[string]interface{}{"idx-blue": map[string]interface{}{}},
+		},
+	}
+	suite.Assert().Equal([]string{"idx-blue"}, index.AliasTargets())
+
+	index.Type = "fulltext-index"
+	suite.Assert().Nil(index.AliasTargets())
+}
@@ -0,0 +1,377 @@
+package gocb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// searchFieldMappingTypes are the field types which can be added using SearchIndex.AddFieldMapping.
+var searchFieldMappingTypes = map[string]struct{}{
+	"text":     {},
+	"number":   {},
+	"datetime": {},
+	"boolean":  {},
+	"geopoint": {},
+	"geoshape": {},
+	"IP":       {},
+}
+
+// SearchFieldMapping describes how a single document field is indexed by a search index.
+// UNCOMMITTED: This API may change in the future.
+type SearchFieldMapping struct {
+	// Type is the type of the field, one of text, number, datetime, boolean, geopoint, geoshape or IP.
+	Type string
+	// Analyzer is the analyzer to use for the field, only applicable to text fields. If empty then the default
+	// analyzer of the type mapping is used.
+	Analyzer string
+	// Store is whether the field content is stored in the index so that it can be returned in search results.
+	Store bool
+	// IncludeInAll is whether the field is included in the composite field used by queries which do not specify a
+	// field.
+	IncludeInAll bool
+	// IncludeTermVectors is whether term vectors are stored, these are required for highlighting and phrase queries.
+	IncludeTermVectors bool
+	// DocValues is whether doc values are stored for the field, these are required for sorting and facets.
+	DocValues bool
+}
+
+// SearchTypeMapping describes a type mapping within a search index.
+// UNCOMMITTED: This API may change in the future.
+type SearchTypeMapping struct {
+	// Dynamic is whether fields without an explicit field mapping are indexed.
+	Dynamic bool
+	// DefaultAnalyzer is the analyzer to use for text fields of the type which do not specify one.
+	DefaultAnalyzer string
+}
+
+// AddTypeMapping adds a type mapping to the definition of the search index, to which field mappings can then be added
+// using AddFieldMapping. If a type mapping with the same name already exists then an error wrapping
+// ErrInvalidArgument is returned. The index definition is only updated locally, the index must then be updated
+// using SearchIndexManager.UpsertIndex.
+// UNCOMMITTED: This API may change in the future.
+func (si *SearchIndex) AddTypeMapping(typeName string, typeMapping SearchTypeMapping) error {
+	if typeName == "" {
+		return makeInvalidArgumentsError("type name cannot be empty")
+	}
+
+	return si.updateMapping(func(mapping map[string]interface{}) error {
+		types, ok := mapping["types"].(map[string]interface{})
+		if !ok {
+			types = make(map[string]interface{})
+			mapping["types"] = types
+		}
+
+		if _, ok := types[typeName]; ok {
+			return makeInvalidArgumentsError(fmt.Sprintf("type mapping %s already exists", typeName))
+		}
+
+		docMapping := map[string]interface{}{
+			"enabled": true,
+			"dynamic": typeMapping.Dynamic,
+		}
+		if typeMapping.DefaultAnalyzer != "" {
+			docMapping["default_analyzer"] = typeMapping.DefaultAnalyzer
+		}
+		types[typeName] = docMapping
+
+		return nil
+	})
+}
+
+// RemoveTypeMapping removes a type mapping, along with all of its field mappings, from the definition of the search
+// index. If the type mapping does not exist then an error wrapping ErrInvalidArgument is returned. The index
+// definition is only updated locally, the index must then be updated using SearchIndexManager.UpsertIndex.
+// UNCOMMITTED: This API may change in the future.
+func (si *SearchIndex) RemoveTypeMapping(typeName string) error {
+	return si.updateMapping(func(mapping map[string]interface{}) error {
+		types, ok := mapping["types"].(map[string]interface{})
+		if !ok {
+			return makeInvalidArgumentsError(fmt.Sprintf("type mapping %s does not exist", typeName))
+		}
+
+		if _, ok := types[typeName]; !ok {
+			return makeInvalidArgumentsError(fmt.Sprintf("type mapping %s does not exist", typeName))
+		}
+		delete(types, typeName)
+
+		return nil
+	})
+}
+
+// AddFieldMapping adds a field mapping for the field at path, e.g. "address.city", to the type mapping with the given
+// name, or to the default mapping if typeName is empty. Any existing field mappings for the path are replaced, and
+// any missing parent objects are added with dynamic indexing disabled. The type mapping must already exist, see
+// AddTypeMapping. The index definition is only updated locally, the index must then be updated using
+// SearchIndexManager.UpsertIndex.
+// UNCOMMITTED: This API may change in the future.
+func (si *SearchIndex) AddFieldMapping(typeName, path string, field SearchFieldMapping) error {
+	if _, ok := searchFieldMappingTypes[field.Type]; !ok {
+		return makeInvalidArgumentsError(fmt.Sprintf("unsupported field type %s", field.Type))
+	}
+	if field.Analyzer != "" && field.Type != "text" {
+		return makeInvalidArgumentsError("analyzer can only be set for text fields")
+	}
+
+	parts, err := splitSearchFieldPath(path)
+	if err != nil {
+		return err
+	}
+
+	return si.updateMapping(func(mapping map[string]interface{}) error {
+		docMapping, err := searchDocumentMapping(mapping, typeName)
+		if err != nil {
+			return err
+		}
+
+		for _, part := range parts {
+			properties, ok := docMapping["properties"].(map[string]interface{})
+			if !ok {
+				properties = make(map[string]interface{})
+				docMapping["properties"] = properties
+			}
+
+			child, ok := properties[part].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{
+					"enabled": true,
+					"dynamic": false,
+				}
+				properties[part] = child
+			}
+			docMapping = child
+		}
+
+		fieldMapping := map[string]interface{}{
+			"name":                 parts[len(parts)-1],
+			"type":                 field.Type,
+			"index":                true,
+			"store":                field.Store,
+			"include_in_all":       field.IncludeInAll,
+			"include_term_vectors": field.IncludeTermVectors,
+			"docvalues":            field.DocValues,
+		}
+		if field.Analyzer != "" {
+			fieldMapping["analyzer"] = field.Analyzer
+		}
+		docMapping["fields"] = []interface{}{fieldMapping}
+
+		return nil
+	})
+}
+
+// RemoveFieldMapping removes the mapping of the field at path, e.g. "address.city", from the type mapping with the
+// given name, or from the default mapping if typeName is empty. Any mappings of child fields of the path are also
+// removed. If there is no mapping for the path then an error wrapping ErrInvalidArgument is returned. The index
+// definition is only updated locally, the index must then be updated using SearchIndexManager.UpsertIndex.
+// UNCOMMITTED: This API may change in the future.
+func (si *SearchIndex) RemoveFieldMapping(typeName, path string) error {
+	parts, err := splitSearchFieldPath(path)
+	if err != nil {
+		return err
+	}
+
+	return si.updateMapping(func(mapping map[string]interface{}) error {
+		docMapping, err := searchDocumentMapping(mapping, typeName)
+		if err != nil {
+			return err
+		}
+
+		for i, part := range parts {
+			properties, ok := docMapping["properties"].(map[string]interface{})
+			if !ok {
+				break
+			}
+
+			child, ok := properties[part].(map[string]interface{})
+			if !ok {
+				break
+			}
+
+			if i == len(parts)-1 {
+				delete(properties, part)
+				return nil
+			}
+			docMapping = child
+		}
+
+		return makeInvalidArgumentsError(fmt.Sprintf("no field mapping exists for %s", path))
+	})
+}
+
+// ValidateMapping checks that the mapping within the definition of the search index is well-formed. This only
+// validates the structure of the mapping, the search service may still reject the definition, e.g. if it references
+// an analyzer which does not exist. Any error returned wraps ErrInvalidArgument.
+// UNCOMMITTED: This API may change in the future.
+func (si *SearchIndex) ValidateMapping() error {
+	mappingVal, ok := si.Params["mapping"]
+	if !ok {
+		return nil
+	}
+
+	mapping, ok := mappingVal.(map[string]interface{})
+	if !ok {
+		return makeInvalidArgumentsError("search index mapping must be an object")
+	}
+
+	if defaultMapping, ok := mapping["default_mapping"]; ok {
+		if err := validateSearchDocumentMapping("default_mapping", defaultMapping); err != nil {
+			return err
+		}
+	}
+
+	if typesVal, ok := mapping["types"]; ok {
+		types, ok := typesVal.(map[string]interface{})
+		if !ok {
+			return makeInvalidArgumentsError("search index mapping types must be an object")
+		}
+
+		for name, docMapping := range types {
+			if name == "" {
+				return makeInvalidArgumentsError("search index mapping type names cannot be empty")
+			}
+
+			if err := validateSearchDocumentMapping("types."+name, docMapping); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateMapping applies fn to a copy of the mapping of the index, only updating the index if fn succeeds and the
+// resulting mapping is valid.
+func (si *SearchIndex) updateMapping(fn func(mapping map[string]interface{}) error) error {
+	if si.Type == searchIndexTypeAlias {
+		return makeInvalidArgumentsError("search index aliases do not have mappings")
+	}
+
+	var params map[string]interface{}
+	if si.Params != nil {
+		b, err := json.Marshal(si.Params)
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal(b, &params)
+		if err != nil {
+			return err
+		}
+	} else {
+		params = make(map[string]interface{})
+	}
+
+	mapping, ok := params["mapping"].(map[string]interface{})
+	if !ok {
+		if _, ok := params["mapping"]; ok {
+			return makeInvalidArgumentsError("search index mapping must be an object")
+		}
+
+		mapping = map[string]interface{}{
+			"default_mapping": map[string]interface{}{
+				"enabled": true,
+				"dynamic": true,
+			},
+		}
+		params["mapping"] = mapping
+	}
+
+	if err := fn(mapping); err != nil {
+		return err
+	}
+
+	updated := SearchIndex{Params: params}
+	if err := updated.ValidateMapping(); err != nil {
+		return err
+	}
+
+	si.Params = params
+	return nil
+}
+
+func searchDocumentMapping(mapping map[string]interface{}, typeName string) (map[string]interface{}, error) {
+	if typeName == "" {
+		docMapping, ok := mapping["default_mapping"].(map[string]interface{})
+		if !ok {
+			docMapping = map[string]interface{}{
+				"enabled": true,
+				"dynamic": true,
+			}
+			mapping["default_mapping"] = docMapping
+		}
+
+		return docMapping, nil
+	}
+
+	types, _ := mapping["types"].(map[string]interface{})
+	docMapping, ok := types[typeName].(map[string]interface{})
+	if !ok {
+		return nil, makeInvalidArgumentsError(fmt.Sprintf("type mapping %s does not exist", typeName))
+	}
+
+	return docMapping, nil
+}
+
+func splitSearchFieldPath(path string) ([]string, error) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts {
+		if part == "" {
+			return nil, makeInvalidArgumentsError(fmt.Sprintf("invalid field path %s", path))
+		}
+	}
+
+	return parts, nil
+}
+
+func validateSearchDocumentMapping(path string, docMappingVal interface{}) error {
+	docMapping, ok := docMappingVal.(map[string]interface{})
+	if !ok {
+		return makeInvalidArgumentsError(fmt.Sprintf("search index mapping %s must be an object", path))
+	}
+
+	for _, key := range []string{"enabled", "dynamic"} {
+		if val, ok := docMapping[key]; ok {
+			if _, ok := val.(bool); !ok {
+				return makeInvalidArgumentsError(fmt.Sprintf("search index mapping %s.%s must be a boolean", path, key))
+			}
+		}
+	}
+
+	if propertiesVal, ok := docMapping["properties"]; ok {
+		properties, ok := propertiesVal.(map[string]interface{})
+		if !ok {
+			return makeInvalidArgumentsError(fmt.Sprintf("search index mapping %s.properties must be an object", path))
+		}
+
+		for name, property := range properties {
+			if name == "" {
+				return makeInvalidArgumentsError(fmt.Sprintf("search index mapping %s has a property with no name", path))
+			}
+
+			if err := validateSearchDocumentMapping(path+".properties."+name, property); err != nil {
+				return err
+			}
+		}
+	}
+
+	if fieldsVal, ok := docMapping["fields"]; ok {
+		fields, ok := fieldsVal.([]interface{})
+		if !ok {
+			return makeInvalidArgumentsError(fmt.Sprintf("search index mapping %s.fields must be an array", path))
+		}
+
+		for _, fieldVal := range fields {
+			field, ok := fieldVal.(map[string]interface{})
+			if !ok {
+				return makeInvalidArgumentsError(fmt.Sprintf("search index mapping %s.fields must contain objects", path))
+			}
+
+			if fieldType, _ := field["type"].(string); fieldType == "" {
+				return makeInvalidArgumentsError(fmt.Sprintf("search index mapping %s.fields must each have a type", path))
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package gocb
+
+import (
+	"errors"
+)
+
+func (suite *UnitTestSuite) TestSearchIndexFieldMappings() {
+	index := SearchIndex{
+		Name: "test",
+		Type: "fulltext-index",
+	}
+
+	err := index.AddTypeMapping("brewery", SearchTypeMapping{DefaultAnalyzer: "en"})
+	suite.Require().Nil(err, err)
+
+	err = index.AddFieldMapping("brewery", "name", SearchFieldMapping{Type: "text", Store: true})
+	suite.Require().Nil(err, err)
+
+	err = index.AddFieldMapping("brewery", "address.city", SearchFieldMapping{Type: "text", Analyzer: "keyword"})
+	suite.Require().Nil(err, err)
+
+	err = index.AddFieldMapping("", "updated", SearchFieldMapping{Type: "datetime", DocValues: true})
+	suite.Require().Nil(err, err)
+
+	suite.Require().Nil(index.ValidateMapping())
+
+	mapping := index.Params["mapping"].(map[string]interface{})
+	brewery := mapping["types"].(map[string]interface{})["brewery"].(map[string]interface{})
+	suite.Assert().Equal(true, brewery["enabled"])
+	suite.Assert().Equal(false, brewery["dynamic"])
+	suite.Assert().Equal("en", brewery["default_analyzer"])
+
+	properties := brewery["properties"].(map[string]interface{})
+	name := properties["name"].(map[string]interface{})
+	suite.Assert().Equal([]interface{}{map[string]interface{}{
+		"name":                 "name",
+		"type":                 "text",
+		"index":                true,
+		"store":                true,
+		"include_in_all":       false,
+		"include_term_vectors": false,
+		"docvalues":            false,
+	}}, name["fields"])
+
+	address := properties["address"].(map[string]interface{})
+	suite.Assert().Equal(false, address["dynamic"])
+	city := address["properties"].(map[string]interface{})["city"].(map[string]interface{})
+	cityField := city["fields"].([]interface{})[0].(map[string]interface{})
+	suite.Assert().Equal("city", cityField["name"])
+	suite.Assert().Equal("keyword", cityField["analyzer"])
+
+	defaultMapping := mapping["default_mapping"].(map[string]interface{})
+	suite.Assert().Equal(true, defaultMapping["dynamic"])
+	suite.Assert().Contains(defaultMapping["properties"], "updated")
+
+	err = index.RemoveFieldMapping("brewery", "address.city")
+	suite.Require().Nil(err, err)
+	// The definition is updated as a copy, so the mapping must be fetched again.
+	mapping = index.Params["mapping"].(map[string]interface{})
+	brewery = mapping["types"].(map[string]interface{})["brewery"].(map[string]interface{})
+	address = brewery["properties"].(map[string]interface{})["address"].(map[string]interface{})
+	suite.Assert().NotContains(address["properties"], "city")
+
+	err = index.RemoveTypeMapping("brewery")
+	suite.Require().Nil(err, err)
+	suite.Assert().NotContains(index.Params["mapping"].(map[string]interface{})["types"], "brewery")
+}
+
+func (suite *UnitTestSuite) TestSearchIndexFieldMappingsInvalid() {
+	index := SearchIndex{
+		Name: "test",
+		Type: "fulltext-index",
+		Params: map[string]interface{}{
+			"store": map[string]interface{}{"indexType": "scorch"},
+		},
+	}
+
+	assertInvalid := func(err error) {
+		if !errors.Is(err, ErrInvalidArgument) {
+			suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+		}
+	}
+
+	assertInvalid(index.AddFieldMapping("", "name", SearchFieldMapping{Type: "string"}))
+	assertInvalid(index.AddFieldMapping("", "count", SearchFieldMapping{Type: "number", Analyzer: "en"}))
+	assertInvalid(index.AddFieldMapping("", "address..city", SearchFieldMapping{Type: "text"}))
+	assertInvalid(index.AddFieldMapping("missing", "name", SearchFieldMapping{Type: "text"}))
+	assertInvalid(index.RemoveFieldMapping("", "name"))
+	assertInvalid(index.RemoveTypeMapping("missing"))
+	assertInvalid(index.AddTypeMapping("", SearchTypeMapping{}))
+
+	// Failed updates must leave the definition untouched.
+	suite.Assert().Equal(map[string]interface{}{
+		"store": map[string]interface{}{"indexType": "scorch"},
+	}, index.Params)
+
+	suite.Require().Nil(index.AddTypeMapping("brewery", SearchTypeMapping{}))
+	assertInvalid(index.AddTypeMapping("brewery", SearchTypeMapping{}))
+
+	index.Params["mapping"].(map[string]interface{})["default_mapping"] = map[string]interface{}{
+		"dynamic": "yes",
+	}
+	assertInvalid(index.ValidateMapping())
+	assertInvalid(index.AddFieldMapping("brewery", "name", SearchFieldMapping{Type: "text"}))
+
+	index.Params["mapping"].(map[string]interface{})["default_mapping"] = map[string]interface{}{
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"fields": []interface{}{map[string]interface{}{"name": "name"}},
+			},
+		},
+	}
+	assertInvalid(index.ValidateMapping())
+
+	alias := SearchIndex{
+		Name: "alias",
+		Type: "fulltext-alias",
+	}
+	assertInvalid(alias.AddTypeMapping("brewery", SearchTypeMapping{}))
+}
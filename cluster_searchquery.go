@@ -162,6 +162,51 @@ func (fr *SearchFacetResult) fromData(data jsonSearchFacet) error {
 	return nil
 }
 
+// StartTime parses the start of the date range.
+// UNCOMMITTED: This API may change in the future.
+func (dr SearchDateRangeFacetResult) StartTime() (time.Time, error) {
+	return time.Parse(time.RFC3339, dr.Start)
+}
+
+// EndTime parses the end of the date range.
+// UNCOMMITTED: This API may change in the future.
+func (dr SearchDateRangeFacetResult) EndTime() (time.Time, error) {
+	return time.Parse(time.RFC3339, dr.End)
+}
+
+// TermFacetResult holds the result of a term facet.
+// UNCOMMITTED: This API may change in the future.
+type TermFacetResult struct {
+	Name    string
+	Field   string
+	Total   uint64
+	Missing uint64
+	Other   uint64
+	Terms   []SearchTermFacetResult
+}
+
+// NumericRangeFacetResult holds the result of a numeric range facet.
+// UNCOMMITTED: This API may change in the future.
+type NumericRangeFacetResult struct {
+	Name          string
+	Field         string
+	Total         uint64
+	Missing       uint64
+	Other         uint64
+	NumericRanges []SearchNumericRangeFacetResult
+}
+
+// DateRangeFacetResult holds the result of a date range facet.
+// UNCOMMITTED: This API may change in the future.
+type DateRangeFacetResult struct {
+	Name       string
+	Field      string
+	Total      uint64
+	Missing    uint64
+	Other      uint64
+	DateRanges []SearchDateRangeFacetResult
+}
+
 // SearchRowLocation represents the location of a row match
 type SearchRowLocation struct {
 	Position       uint32
@@ -188,11 +233,13 @@ type SearchRow struct {
 	Locations   map[string]map[string][]SearchRowLocation
 	Fragments   map[string][]string
 	fieldsBytes []byte
+
+	deserializer Deserializer
 }
 
 // Fields decodes the fields included in a search hit.
 func (sr *SearchRow) Fields(valuePtr interface{}) error {
-	return json.Unmarshal(sr.fieldsBytes, valuePtr)
+	return deserializeRow(sr.deserializer, sr.fieldsBytes, valuePtr)
 }
 
 type searchRowReader interface {
@@ -244,6 +291,14 @@ type SearchResult struct {
 
 	currentRow SearchRow
 	jsonErr    error
+
+	deserializer Deserializer
+
+	canceller streamCanceller
+
+	// drained is set once all rows have been read, at which point the facets are available.
+	drained         bool
+	requestedFacets map[string]cbsearch.Facet
 }
 
 func newSearchResult(reader searchRowReader) *SearchResult {
@@ -266,16 +321,19 @@ func (r *SearchResult) Raw() *SearchResultRaw {
 
 // Next assigns the next result from the results into the value pointer, returning whether the read was successful.
 func (r *SearchResult) Next() bool {
-	if r.reader == nil {
+	if r.reader == nil || r.canceller.isCanceled() {
 		return false
 	}
 
 	rowBytes := r.reader.NextRow()
 	if rowBytes == nil {
+		r.drained = true
 		return false
 	}
 
-	r.currentRow = SearchRow{}
+	r.currentRow = SearchRow{
+		deserializer: r.deserializer,
+	}
 
 	var rowData jsonSearchRow
 	if err := json.Unmarshal(rowBytes, &rowData); err != nil {
@@ -327,6 +385,10 @@ func (r *SearchResult) Err() error {
 		return errors.New("result object is no longer valid")
 	}
 
+	if r.canceller.isCanceled() {
+		return ErrRequestCanceled
+	}
+
 	err := r.reader.Err()
 	if err != nil {
 		return maybeEnhanceSearchError(err)
@@ -335,16 +397,31 @@ func (r *SearchResult) Err() error {
 	return r.jsonErr
 }
 
+// Cancel aborts the query by closing the underlying stream of results, unblocking any call to Next which is waiting
+// on the server. Once canceled Next returns false and Err returns ErrRequestCanceled.
+// Cancel is safe to call concurrently with iterating the results and may be called more than once.
+// UNCOMMITTED: This API may change in the future.
+func (r *SearchResult) Cancel() {
+	reader := r.reader
+	if reader == nil {
+		return
+	}
+
+	r.canceller.cancel(reader.Close)
+}
+
 // Close marks the results as closed, returning any errors that occurred during reading the results.
 func (r *SearchResult) Close() error {
 	if r.reader == nil {
 		return r.Err()
 	}
 
-	err := r.reader.Close()
+	// The stream may already have been closed by Cancel, possibly from another goroutine.
+	err := r.canceller.close(r.reader.Close)
 	if err != nil {
 		return maybeEnhanceSearchError(err)
 	}
+	r.drained = true
 
 	return nil
 }
@@ -386,10 +463,18 @@ func (r *SearchResult) MetaData() (*SearchMetaData, error) {
 	return &metaData, nil
 }
 
-// Facets returns any facets that were returned with this query.  Note that the
-// facets will only be available once the object has been closed (either
-// implicitly or explicitly).
+// Facets returns any facets that were returned with this query, keyed by facet name.  Note that the
+// facets will only be available once all rows have been read or the object has been closed, an error
+// is returned if they are read before then.
 func (r *SearchResult) Facets() (map[string]SearchFacetResult, error) {
+	if r.reader == nil {
+		return nil, r.Err()
+	}
+
+	if !r.drained {
+		return nil, makeInvalidArgumentsError("facets are only available once all rows have been read")
+	}
+
 	jsonResp, err := r.getJSONResp()
 	if err != nil {
 		return nil, err
@@ -409,6 +494,97 @@ func (r *SearchResult) Facets() (map[string]SearchFacetResult, error) {
 	return facets, nil
 }
 
+// facet returns the named facet, checking that it was requested as the expected type of facet.
+func (r *SearchResult) facet(name, facetType string, isType func(cbsearch.Facet) bool) (*SearchFacetResult, error) {
+	if requested, ok := r.requestedFacets[name]; ok && !isType(requested) {
+		return nil, makeInvalidArgumentsError("facet " + name + " is not a " + facetType + " facet")
+	}
+
+	facets, err := r.Facets()
+	if err != nil {
+		return nil, err
+	}
+
+	facet, ok := facets[name]
+	if !ok {
+		return nil, makeInvalidArgumentsError("facet " + name + " was not returned by the query")
+	}
+
+	return &facet, nil
+}
+
+// TermFacet returns the result of the named term facet, once all rows have been read.
+// UNCOMMITTED: This API may change in the future.
+func (r *SearchResult) TermFacet(name string) (*TermFacetResult, error) {
+	facet, err := r.facet(name, "term", func(f cbsearch.Facet) bool {
+		switch f.(type) {
+		case *cbsearch.TermFacet, cbsearch.TermFacet:
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TermFacetResult{
+		Name:    facet.Name,
+		Field:   facet.Field,
+		Total:   facet.Total,
+		Missing: facet.Missing,
+		Other:   facet.Other,
+		Terms:   facet.Terms,
+	}, nil
+}
+
+// NumericRangeFacet returns the result of the named numeric range facet, once all rows have been read.
+// UNCOMMITTED: This API may change in the future.
+func (r *SearchResult) NumericRangeFacet(name string) (*NumericRangeFacetResult, error) {
+	facet, err := r.facet(name, "numeric range", func(f cbsearch.Facet) bool {
+		switch f.(type) {
+		case *cbsearch.NumericFacet, cbsearch.NumericFacet:
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &NumericRangeFacetResult{
+		Name:          facet.Name,
+		Field:         facet.Field,
+		Total:         facet.Total,
+		Missing:       facet.Missing,
+		Other:         facet.Other,
+		NumericRanges: facet.NumericRanges,
+	}, nil
+}
+
+// DateRangeFacet returns the result of the named date range facet, once all rows have been read.
+// UNCOMMITTED: This API may change in the future.
+func (r *SearchResult) DateRangeFacet(name string) (*DateRangeFacetResult, error) {
+	facet, err := r.facet(name, "date range", func(f cbsearch.Facet) bool {
+		switch f.(type) {
+		case *cbsearch.DateFacet, cbsearch.DateFacet:
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DateRangeFacetResult{
+		Name:       facet.Name,
+		Field:      facet.Field,
+		Total:      facet.Total,
+		Missing:    facet.Missing,
+		Other:      facet.Other,
+		DateRanges: facet.DateRanges,
+	}, nil
+}
+
 // SearchQuery executes the analytics query statement on the server.
 func (c *Cluster) SearchQuery(indexName string, query cbsearch.Query, opts *SearchOptions) (*SearchResult, error) {
 	if opts == nil {
@@ -443,7 +619,75 @@ func (c *Cluster) SearchQuery(indexName string, query cbsearch.Query, opts *Sear
 
 	searchOpts["query"] = query
 
-	return c.execSearchQuery(opts.Context, span, indexName, searchOpts, deadline, retryStrategy, opts.Internal.User)
+	res, err := execSearchQuery(opts.Context, span, c.getSearchProvider, c.tracer, indexName, searchOpts, deadline,
+		retryStrategy, opts.Internal.User)
+	if err != nil {
+		return nil, c.maybeEnhanceNoBucketErr(err)
+	}
+	res.deserializer = resultDeserializer(opts.Deserializer, c.deserializer)
+	res.requestedFacets = opts.Facets
+
+	return res, nil
+}
+
+// Search executes a search request, which may contain a search query, vector queries or both, against the search
+// index. Vector search requires Couchbase Server 7.6 or above, older servers return ErrFeatureNotAvailable.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) Search(indexName string, request SearchRequest, opts *SearchOptions) (*SearchResult, error) {
+	if opts == nil {
+		opts = &SearchOptions{}
+	}
+
+	start := time.Now()
+	defer c.meter.ValueRecord(meterValueServiceSearch, "search", start)
+
+	span := createSpan(c.tracer, opts.ParentSpan, "search", "search")
+	span.SetAttribute("db.operation", indexName)
+	defer span.End()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = c.timeoutsConfig.SearchTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	retryStrategy := c.retryStrategyWrapper
+	if opts.RetryStrategy != nil {
+		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	}
+
+	searchOpts, err := opts.toMap(indexName)
+	if err != nil {
+		return nil, SearchError{
+			InnerError: wrapError(err, "failed to generate query options"),
+			Query:      request.SearchQuery,
+		}
+	}
+
+	err = request.applyTo(searchOpts)
+	if err != nil {
+		return nil, SearchError{
+			InnerError: wrapError(err, "failed to generate search request"),
+			Query:      request.SearchQuery,
+		}
+	}
+
+	if request.VectorSearch != nil {
+		err = verifyVectorSearchSupported(opts.Context, c, c.searchCapabilities, span, deadline, retryStrategy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := execSearchQuery(opts.Context, span, c.getSearchProvider, c.tracer, indexName, searchOpts, deadline,
+		retryStrategy, opts.Internal.User)
+	if err != nil {
+		return nil, c.maybeEnhanceNoBucketErr(err)
+	}
+	res.deserializer = resultDeserializer(opts.Deserializer, c.deserializer)
+	res.requestedFacets = opts.Facets
+
+	return res, nil
 }
 
 func maybeGetSearchOptionQuery(options map[string]interface{}) interface{} {
@@ -453,16 +697,18 @@ func maybeGetSearchOptionQuery(options map[string]interface{}) interface{} {
 	return ""
 }
 
-func (c *Cluster) execSearchQuery(
+func execSearchQuery(
 	ctx context.Context,
 	span RequestSpan,
+	getProvider func() (searchProvider, error),
+	tracer RequestTracer,
 	indexName string,
 	options map[string]interface{},
 	deadline time.Time,
 	retryStrategy *retryStrategyWrapper,
 	user string,
 ) (*SearchResult, error) {
-	provider, err := c.getSearchProvider()
+	provider, err := getProvider()
 	if err != nil {
 		return nil, SearchError{
 			InnerError: wrapError(err, "failed to get query provider"),
@@ -470,7 +716,7 @@ func (c *Cluster) execSearchQuery(
 		}
 	}
 
-	eSpan := createSpan(c.tracer, span, "request_encoding", "")
+	eSpan := createSpan(tracer, span, "request_encoding", "")
 	reqBytes, err := json.Marshal(options)
 	eSpan.End()
 	if err != nil {
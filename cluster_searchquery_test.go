@@ -1,9 +1,12 @@
 package gocb
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"time"
 
 	"github.com/couchbase/gocbcore/v10"
@@ -511,3 +514,189 @@ func (suite *UnitTestSuite) TestSearchQueryCollections() {
 	})
 	suite.Require().Nil(err, err)
 }
+
+func (suite *UnitTestSuite) TestSearchVectorQuery() {
+	reader := &mockSearchRowReader{
+		Dataset: []jsonSearchRow{},
+		Meta:    []byte{},
+		Suite:   suite,
+	}
+
+	var cluster *Cluster
+	cluster = suite.searchCluster(reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.SearchQueryOptions)
+		suite.Assert().Equal("testindex", opts.IndexName)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal(map[string]interface{}{"match_none": nil}, actualOptions["query"])
+		suite.Assert().Equal("and", actualOptions["knn_operator"])
+		suite.Assert().Equal([]interface{}{
+			map[string]interface{}{"field": "embedding", "vector": []interface{}{0.5, -1.0}, "k": float64(3)},
+			map[string]interface{}{"field": "title_embedding", "vector": []interface{}{0.25}, "k": float64(10),
+				"boost": float64(2)},
+		}, actualOptions["knn"])
+	})
+	cluster.searchCapabilities.vectorSearchSupported = 1
+
+	_, err := cluster.Search("testindex", SearchRequest{
+		VectorSearch: &VectorSearch{
+			Queries: []VectorQuery{
+				{Field: "embedding", Vector: []float32{0.5, -1}},
+				{Field: "title_embedding", Vector: []float32{0.25}, NumCandidates: 10, Boost: 2},
+			},
+			Combination: VectorQueryCombinationAnd,
+		},
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	_, err = cluster.Search("testindex", SearchRequest{}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+	var searchErr SearchError
+	suite.Assert().True(errors.As(err, &searchErr), "expected search error but was %v", err)
+
+	_, err = cluster.Search("testindex", SearchRequest{
+		VectorSearch: &VectorSearch{
+			Queries: []VectorQuery{{Field: "embedding"}},
+		},
+	}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestVerifyVectorSearchSupported() {
+	strategy := NewBestEffortRetryStrategy(nil)
+	compatibility := vectorSearchClusterCompatibility - 1
+
+	provider := new(mockMgmtProvider)
+	provider.
+		On("executeMgmtRequest", mock.Anything, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			suite.Assert().Equal("/pools/default", req.Path)
+			suite.Assert().Equal(strategy, req.RetryStrategy)
+
+			body := fmt.Sprintf(`{"nodes":[{"clusterCompatibility":%d}]}`, compatibility)
+			return &mgmtResponse{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}
+		}, nil)
+
+	ctx := context.Background()
+	caps := &searchCapabilities{}
+	span := defaultNoopSpan
+	deadline := time.Now().Add(time.Second)
+
+	err := verifyVectorSearchSupported(ctx, provider, caps, span, deadline, newRetryStrategyWrapper(strategy))
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected feature not available error but was %v", err)
+	}
+	suite.Assert().Zero(caps.vectorSearchSupported)
+
+	compatibility = vectorSearchClusterCompatibility
+	err = verifyVectorSearchSupported(ctx, provider, caps, span, deadline, newRetryStrategyWrapper(strategy))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(1), caps.vectorSearchSupported)
+
+	// Support is cached once seen.
+	err = verifyVectorSearchSupported(ctx, provider, caps, span, deadline, nil)
+	suite.Require().Nil(err, err)
+	provider.AssertNumberOfCalls(suite.T(), "executeMgmtRequest", 2)
+}
+
+func (suite *UnitTestSuite) TestSearchResultTypedFacets() {
+	reader := &mockSearchRowReader{
+		Dataset: []jsonSearchRow{
+			{ID: "hit1", Fragments: map[string][]string{"name": {"<mark>21st</mark> Amendment"}}},
+			{ID: "hit2"},
+		},
+		Meta: []byte(`{"total_hits":2,"facets":{` +
+			`"country":{"field":"country","total":7,"missing":1,"other":2,"terms":[{"term":"belgium","count":4}]},` +
+			`"abv":{"field":"abv","total":5,"numeric_ranges":[{"name":"strong","min":8,"count":5}]},` +
+			`"updated":{"field":"updated","total":3,"date_ranges":[{"name":"2010","start":"2010-01-01T00:00:00Z","end":"2011-01-01T00:00:00Z","count":3}]}}}`),
+		Suite: suite,
+	}
+
+	result := newSearchResult(reader)
+	result.requestedFacets = map[string]search.Facet{
+		"country": search.NewTermFacet("country", 5),
+		"abv":     search.NewNumericFacet("abv", 5).AddRange("strong", 8, 0),
+		"updated": search.NewDateFacet("updated", 5).AddRange("2010", "2010-01-01T00:00:00Z", "2011-01-01T00:00:00Z"),
+	}
+
+	_, err := result.Facets()
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	suite.Require().True(result.Next())
+	suite.Assert().Equal([]string{"<mark>21st</mark> Amendment"}, result.Row().Fragments["name"])
+
+	_, err = result.TermFacet("country")
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	for result.Next() {
+	}
+	suite.Require().Nil(result.Err())
+
+	terms, err := result.TermFacet("country")
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(&TermFacetResult{
+		Field:   "country",
+		Total:   7,
+		Missing: 1,
+		Other:   2,
+		Terms:   []SearchTermFacetResult{{Term: "belgium", Count: 4}},
+	}, terms)
+
+	numeric, err := result.NumericRangeFacet("abv")
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint64(5), numeric.Total)
+	suite.Assert().Equal([]SearchNumericRangeFacetResult{{Name: "strong", Min: 8, Count: 5}}, numeric.NumericRanges)
+
+	dates, err := result.DateRangeFacet("updated")
+	suite.Require().Nil(err, err)
+	suite.Require().Len(dates.DateRanges, 1)
+	start, err := dates.DateRanges[0].StartTime()
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), start)
+
+	_, err = result.DateRangeFacet("country")
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	_, err = result.TermFacet("missing")
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestSearchOptionsHighlightStyle() {
+	opts := &SearchOptions{
+		Highlight: &SearchHighlightOptions{
+			Style:  AnsiHightlightStyle,
+			Fields: []string{"name"},
+		},
+	}
+
+	data, err := opts.toMap("index")
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(map[string]interface{}{
+		"style":  "ansi",
+		"fields": []string{"name"},
+	}, data["highlight"])
+
+	opts.Highlight.Style = "bold"
+	_, err = opts.toMap("index")
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package gocb
+
+import (
+	"context"
+	"time"
+)
+
+// ServerTimeOptions is the set of options available to the ServerTime operation.
+// UNCOMMITTED: This API may change in the future.
+type ServerTimeOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// ServerTimeResult is the result of a ServerTime operation.
+// UNCOMMITTED: This API may change in the future.
+type ServerTimeResult struct {
+	// ServerTime is the time on the server which handled the request, to millisecond precision.
+	ServerTime time.Time
+
+	// Skew is how far the server clock is ahead of the local clock, a negative value means that the server clock is
+	// behind. The server time is compared against the local time halfway through the request, so the skew is
+	// accurate to within half of RoundTrip.
+	Skew time.Duration
+
+	// RoundTrip is how long the request to the server took.
+	RoundTrip time.Duration
+}
+
+type jsonServerTime struct {
+	// NOW_MILLIS can include fractions of a millisecond.
+	Now float64 `json:"now"`
+}
+
+// ServerTime reads the current time from the cluster, along with the skew between it and the local clock. This can
+// be used to detect clock skew, which affects the calculation of document expiry times.
+// The time is read from a query node using a lightweight query, and so requires the query service. Document expiry
+// is evaluated by the data service, so this is only representative of the data nodes if the clocks of the nodes
+// within the cluster are synchronized, as they should be.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) ServerTime(opts *ServerTimeOptions) (*ServerTimeResult, error) {
+	if opts == nil {
+		opts = &ServerTimeOptions{}
+	}
+
+	start := time.Now()
+	res, err := c.Query("SELECT NOW_MILLIS() AS now", &QueryOptions{
+		Readonly:      true,
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var row jsonServerTime
+	err = res.One(&row)
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now()
+
+	roundTrip := end.Sub(start)
+	serverTime := time.Unix(0, int64(row.Now*float64(time.Millisecond)))
+
+	return &ServerTimeResult{
+		ServerTime: serverTime,
+		Skew:       serverTime.Sub(start.Add(roundTrip / 2)),
+		RoundTrip:  roundTrip,
+	}, nil
+}
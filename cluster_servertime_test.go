@@ -0,0 +1,41 @@
+package gocb
+
+import (
+	"encoding/json"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestClusterServerTime() {
+	serverTime := time.Now().Add(-90 * time.Second)
+
+	reader := &mockQueryIndexRowReader{
+		Dataset: []map[string]interface{}{
+			{"now": float64(serverTime.UnixNano()) / float64(time.Millisecond)},
+		},
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta:  []byte("{}"),
+			Suite: suite,
+		},
+	}
+
+	cluster := suite.queryCluster(false, reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal("SELECT NOW_MILLIS() AS now", actualOptions["statement"])
+		suite.Assert().Equal(true, actualOptions["readonly"])
+	})
+
+	res, err := cluster.ServerTime(nil)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().WithinDuration(serverTime, res.ServerTime, time.Millisecond)
+	suite.Assert().InDelta(float64(-90*time.Second), float64(res.Skew), float64(time.Second))
+	suite.Assert().Less(int64(res.RoundTrip), int64(time.Second))
+}
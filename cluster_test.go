@@ -1,10 +1,47 @@
 package gocb
 
 import (
+	"crypto/x509"
 	"errors"
+	"strings"
 	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	gocbconnstr "github.com/couchbase/gocbcore/v10/connstr"
+	"github.com/stretchr/testify/mock"
 )
 
+func (suite *IntegrationTestSuite) TestClusterCompressionRoundTrip() {
+	suite.skipIfUnsupported(KeyValueFeature)
+
+	c, err := Connect(globalConfig.Server, ClusterOptions{
+		Authenticator: PasswordAuthenticator{
+			Username: globalConfig.User,
+			Password: globalConfig.Password,
+		},
+		CompressionConfig: CompressionConfig{
+			Enabled: true,
+		},
+	})
+	suite.Require().Nil(err, err)
+	defer c.Close(nil)
+
+	col := c.Bucket(globalConfig.Bucket).DefaultCollection()
+
+	doc := map[string]string{
+		"body": strings.Repeat("compressible ", 1000),
+	}
+	_, err = col.Upsert("TestClusterCompressionRoundTrip", doc, nil)
+	suite.Require().Nil(err, err)
+
+	res, err := col.Get("TestClusterCompressionRoundTrip", nil)
+	suite.Require().Nil(err, err)
+
+	var actual map[string]string
+	suite.Require().Nil(res.Content(&actual))
+	suite.Assert().Equal(doc, actual)
+}
+
 func (suite *IntegrationTestSuite) TestClusterWaitUntilReady() {
 	suite.skipIfUnsupported(WaitUntilReadyFeature)
 	suite.skipIfUnsupported(WaitUntilReadyClusterFeature)
@@ -104,3 +141,362 @@ func (suite *IntegrationTestSuite) TestClusterWaitUntilReadyKeyValueService() {
 		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
 	}
 }
+
+func (suite *UnitTestSuite) TestClusterConfigPollerConfig() {
+	cluster := clusterFromOptions(ClusterOptions{
+		ConfigPollerConfig: ConfigPollerConfig{
+			PollInterval: 500 * time.Millisecond,
+			MaxWait:      time.Second,
+		},
+	})
+	defer tracerDecRef(cluster.tracer)
+
+	connSpec, err := gocbconnstr.Parse("couchbase://localhost")
+	suite.Require().Nil(err, err)
+	cluster.cSpec = connSpec
+
+	mgr := newConnectionMgr()
+	err = mgr.buildConfig(cluster)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal(500*time.Millisecond, mgr.config.ConfigPollerConfig.CccpPollPeriod)
+	suite.Assert().Equal(time.Second, mgr.config.ConfigPollerConfig.CccpMaxWait)
+}
+
+func (suite *UnitTestSuite) TestClusterCompressionConfig() {
+	buildConfig := func(opts ClusterOptions, connStr string) *gocbcore.AgentGroupConfig {
+		cluster := clusterFromOptions(opts)
+		defer tracerDecRef(cluster.tracer)
+
+		connSpec, err := gocbconnstr.Parse(connStr)
+		suite.Require().Nil(err, err)
+		cluster.cSpec = connSpec
+
+		mgr := newConnectionMgr()
+		err = mgr.buildConfig(cluster)
+		suite.Require().Nil(err, err)
+
+		return mgr.config
+	}
+
+	config := buildConfig(ClusterOptions{}, "couchbase://localhost")
+	suite.Assert().False(config.CompressionConfig.Enabled)
+
+	config = buildConfig(ClusterOptions{
+		CompressionConfig: CompressionConfig{
+			Enabled:  true,
+			MinSize:  1024,
+			MinRatio: 0.5,
+		},
+	}, "couchbase://localhost")
+	suite.Assert().True(config.CompressionConfig.Enabled)
+	suite.Assert().Equal(1024, config.CompressionConfig.MinSize)
+	suite.Assert().Equal(0.5, config.CompressionConfig.MinRatio)
+
+	config = buildConfig(ClusterOptions{
+		CompressionConfig: CompressionConfig{
+			Enabled: true,
+		},
+	}, "couchbase://localhost?compression=false")
+	suite.Assert().False(config.CompressionConfig.Enabled)
+}
+
+func (suite *UnitTestSuite) TestClusterNumKVConnections() {
+	buildConfig := func(opts ClusterOptions, connStr string) *gocbcore.AgentGroupConfig {
+		cluster := clusterFromOptions(opts)
+		defer tracerDecRef(cluster.tracer)
+
+		connSpec, err := gocbconnstr.Parse(connStr)
+		suite.Require().Nil(err, err)
+		cluster.cSpec = connSpec
+
+		mgr := newConnectionMgr()
+		err = mgr.buildConfig(cluster)
+		suite.Require().Nil(err, err)
+
+		return mgr.config
+	}
+
+	config := buildConfig(ClusterOptions{}, "couchbase://localhost")
+	suite.Assert().Equal(0, config.KVConfig.PoolSize)
+
+	config = buildConfig(ClusterOptions{
+		IoConfig: IoConfig{
+			NumKVConnections: 4,
+		},
+	}, "couchbase://localhost")
+	suite.Assert().Equal(4, config.KVConfig.PoolSize)
+
+	config = buildConfig(ClusterOptions{
+		IoConfig: IoConfig{
+			NumKVConnections: 4,
+		},
+	}, "couchbase://localhost?kv_pool_size=2")
+	suite.Assert().Equal(2, config.KVConfig.PoolSize)
+}
+
+func (suite *UnitTestSuite) TestClusterAllowedSaslMechanisms() {
+	_, err := Connect("couchbase://localhost", ClusterOptions{
+		SecurityConfig: SecurityConfig{
+			AllowedSaslMechanisms: []SaslMechanism{ScramSha512SaslMechanism, "CRAM-MD5"},
+		},
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	cluster := clusterFromOptions(ClusterOptions{
+		SecurityConfig: SecurityConfig{
+			AllowedSaslMechanisms: []SaslMechanism{ScramSha512SaslMechanism},
+		},
+	})
+	defer tracerDecRef(cluster.tracer)
+	suite.Require().Nil(cluster.securityConfig.validate())
+
+	connSpec, err := gocbconnstr.Parse("couchbases://localhost")
+	suite.Require().Nil(err, err)
+	cluster.cSpec = connSpec
+
+	mgr := newConnectionMgr()
+	err = mgr.buildConfig(cluster)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal([]gocbcore.AuthMechanism{gocbcore.ScramSha512AuthMechanism}, mgr.config.SecurityConfig.AuthMechanisms)
+}
+
+func (suite *UnitTestSuite) TestClusterTLSRootCAProvider() {
+	_, err := Connect("couchbases://localhost", ClusterOptions{
+		SecurityConfig: SecurityConfig{
+			TLSRootCAs: x509.NewCertPool(),
+			TLSRootCAProvider: func() *x509.CertPool {
+				return x509.NewCertPool()
+			},
+		},
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	rotated := x509.NewCertPool()
+	cluster := clusterFromOptions(ClusterOptions{
+		SecurityConfig: SecurityConfig{
+			TLSRootCAProvider: func() *x509.CertPool {
+				return rotated
+			},
+		},
+	})
+	defer tracerDecRef(cluster.tracer)
+	suite.Assert().Same(rotated, cluster.tlsRootCAProvider()())
+
+	// A nil pool from the provider must not disable verification.
+	rotated = nil
+	suite.Assert().NotNil(cluster.tlsRootCAProvider()())
+
+	cluster.securityConfig.TLSSkipVerify = true
+	suite.Assert().Nil(cluster.tlsRootCAProvider()())
+}
+
+func (suite *UnitTestSuite) TestClusterNoBucketOpenTimeoutHint() {
+	cli := new(mockConnectionManager)
+	cli.On("openBucket", "default").Return(nil)
+
+	cluster := suite.newCluster(cli)
+
+	err := cluster.maybeEnhanceNoBucketErr(&QueryError{InnerError: ErrUnambiguousTimeout})
+	suite.Assert().True(errors.Is(err, ErrUnambiguousTimeout))
+	suite.Assert().Contains(err.Error(), "open a bucket")
+
+	// A request which reached the service timed out for some other reason.
+	err = cluster.maybeEnhanceNoBucketErr(&SearchError{InnerError: ErrUnambiguousTimeout, Endpoint: "localhost:8094"})
+	suite.Assert().NotContains(err.Error(), "open a bucket")
+
+	err = cluster.maybeEnhanceNoBucketErr(&AnalyticsError{InnerError: ErrParsingFailure})
+	suite.Assert().NotContains(err.Error(), "open a bucket")
+
+	cluster.Bucket("default")
+
+	err = cluster.maybeEnhanceNoBucketErr(&QueryError{InnerError: ErrUnambiguousTimeout})
+	suite.Assert().True(errors.Is(err, ErrUnambiguousTimeout))
+	suite.Assert().NotContains(err.Error(), "open a bucket")
+}
+
+func (suite *UnitTestSuite) TestClusterWaitUntilReadyPollBackoff() {
+	provider := new(mockWaitUntilReadyProvider)
+	provider.
+		On("WaitUntilReady", nil, mock.AnythingOfType("time.Time"), mock.AnythingOfType("gocbcore.WaitUntilReadyOptions")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(2).(gocbcore.WaitUntilReadyOptions)
+
+			req := &mockGocbcoreRequest{attempts: 3, idempotent: true}
+			action := opts.RetryStrategy.RetryAfter(req, gocbcore.UnknownRetryReason)
+			suite.Assert().Equal(300*time.Millisecond, action.Duration())
+
+			// The retry strategy still decides whether to retry at all.
+			action = opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{attempts: 3}, gocbcore.UnknownRetryReason)
+			suite.Assert().Zero(action.Duration())
+		}).
+		Return(nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getWaitUntilReadyProvider", "").Return(provider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	err := cluster.WaitUntilReady(time.Second, &WaitUntilReadyOptions{
+		PollBackoff: func(retryAttempts uint32) time.Duration {
+			return time.Duration(retryAttempts) * 100 * time.Millisecond
+		},
+	})
+	suite.Require().Nil(err, err)
+	provider.AssertExpectations(suite.T())
+}
+
+func (suite *UnitTestSuite) TestClusterWaitUntilReadyTimeoutReportsServices() {
+	provider := new(mockWaitUntilReadyProvider)
+	provider.
+		On("WaitUntilReady", nil, mock.AnythingOfType("time.Time"), mock.AnythingOfType("gocbcore.WaitUntilReadyOptions")).
+		Run(func(args mock.Arguments) {
+			// A tenth of the timeout is set aside for pinging the services.
+			suite.Assert().WithinDuration(time.Now().Add(900*time.Millisecond), args.Get(1).(time.Time), 50*time.Millisecond)
+
+			opts := args.Get(2).(gocbcore.WaitUntilReadyOptions)
+			suite.Assert().Equal(gocbcore.ClusterStateDegraded, opts.DesiredState)
+			suite.Assert().Equal([]gocbcore.ServiceType{gocbcore.N1qlService, gocbcore.FtsService}, opts.ServiceTypes)
+		}).
+		Return(gocbcore.ErrUnambiguousTimeout)
+
+	pingProvider := new(mockDiagnosticsProvider)
+	pingProvider.
+		On("Ping", nil, mock.AnythingOfType("gocbcore.PingOptions")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(1).(gocbcore.PingOptions)
+			suite.Assert().Equal([]gocbcore.ServiceType{gocbcore.N1qlService, gocbcore.FtsService}, opts.ServiceTypes)
+		}).
+		Return(&gocbcore.PingResult{
+			Services: map[gocbcore.ServiceType][]gocbcore.EndpointPingResult{
+				gocbcore.N1qlService: {
+					{Endpoint: "server1", State: gocbcore.PingStateOK},
+					{Endpoint: "server2", State: gocbcore.PingStateTimeout},
+				},
+				gocbcore.FtsService: {
+					{Endpoint: "server1", State: gocbcore.PingStateError, Error: errors.New("connection refused")},
+				},
+			},
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getWaitUntilReadyProvider", "").Return(provider, nil)
+	cli.On("getDiagnosticsProvider", "").Return(pingProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	err := cluster.WaitUntilReady(time.Second, &WaitUntilReadyOptions{
+		DesiredState: ClusterStateDegraded,
+		ServiceTypes: []ServiceType{ServiceTypeQuery, ServiceTypeSearch},
+	})
+	suite.Require().True(errors.Is(err, ErrTimeout), "expected timeout but was %v", err)
+
+	var readyErr *WaitUntilReadyError
+	suite.Require().True(errors.As(err, &readyErr), "expected wait until ready error but was %v", err)
+	suite.Assert().Equal(ClusterStateDegraded, readyErr.DesiredState)
+	suite.Assert().Equal(ClusterStateDegraded, readyErr.ServiceState(ServiceTypeQuery))
+	suite.Assert().Equal(ClusterStateOffline, readyErr.ServiceState(ServiceTypeSearch))
+	suite.Assert().Equal(ClusterStateOffline, readyErr.ServiceState(ServiceTypeAnalytics))
+	suite.Assert().Contains(err.Error(), "connection refused")
+
+	suite.Assert().Equal(waitUntilReadyReportTimeout, waitUntilReadyReportTime(time.Minute))
+	suite.Assert().Zero(waitUntilReadyReportTime(0))
+}
+
+func (suite *UnitTestSuite) TestClusterWaitUntilReadyWarmKVConnections() {
+	provider := new(mockWaitUntilReadyProvider)
+	provider.
+		On("WaitUntilReady", nil, mock.AnythingOfType("time.Time"), mock.AnythingOfType("gocbcore.WaitUntilReadyOptions")).
+		Return(nil)
+
+	// The second connection to each node is still being established on the first check.
+	var checks int
+	diagProvider := new(mockDiagnosticsProvider)
+	diagProvider.
+		On("Diagnostics", mock.AnythingOfType("gocbcore.DiagnosticsOptions")).
+		Return(func(opts gocbcore.DiagnosticsOptions) *gocbcore.DiagnosticInfo {
+			checks++
+			secondState := gocbcore.EndpointStateConnected
+			if checks == 1 {
+				secondState = gocbcore.EndpointStateConnecting
+			}
+
+			return &gocbcore.DiagnosticInfo{
+				MemdConns: []gocbcore.MemdConnInfo{
+					{RemoteAddr: "10.112.191.102:11210", State: gocbcore.EndpointStateConnected, ID: "0x1"},
+					{RemoteAddr: "10.112.191.102:11210", State: secondState, ID: "0x2"},
+					{RemoteAddr: "10.112.191.103:11210", State: gocbcore.EndpointStateConnected, ID: "0x3"},
+					{RemoteAddr: "10.112.191.103:11210", State: secondState, ID: "0x4"},
+				},
+			}
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getWaitUntilReadyProvider", "").Return(provider, nil)
+	cli.On("getDiagnosticsProvider", "").Return(diagProvider, nil)
+
+	cluster := suite.newCluster(cli)
+
+	err := cluster.WaitUntilReady(time.Second, &WaitUntilReadyOptions{
+		WarmKVConnections: true,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(2, checks)
+
+	report, err := cluster.Diagnostics(nil)
+	suite.Require().Nil(err, err)
+
+	nodes := report.ConnectionsByNode()
+	suite.Require().Len(nodes, 2)
+	for node, endpoints := range nodes {
+		suite.Require().Len(endpoints, 2, node)
+		for _, endpoint := range endpoints {
+			suite.Assert().Equal(EndpointStateConnected, endpoint.State)
+		}
+	}
+}
+
+func (suite *UnitTestSuite) TestClusterWaitUntilReadyWarmKVConnectionsTimeout() {
+	provider := new(mockWaitUntilReadyProvider)
+	provider.
+		On("WaitUntilReady", nil, mock.AnythingOfType("time.Time"), mock.AnythingOfType("gocbcore.WaitUntilReadyOptions")).
+		Return(nil)
+
+	diagProvider := new(mockDiagnosticsProvider)
+	diagProvider.
+		On("Diagnostics", mock.AnythingOfType("gocbcore.DiagnosticsOptions")).
+		Return(&gocbcore.DiagnosticInfo{
+			MemdConns: []gocbcore.MemdConnInfo{
+				{RemoteAddr: "10.112.191.102:11210", State: gocbcore.EndpointStateConnected},
+				{State: gocbcore.EndpointStateConnecting},
+			},
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getWaitUntilReadyProvider", "").Return(provider, nil)
+	cli.On("getDiagnosticsProvider", "").Return(diagProvider, nil)
+
+	cluster := clusterFromOptions(ClusterOptions{
+		Tracer: &NoopTracer{},
+		Meter:  &NoopMeter{},
+		IoConfig: IoConfig{
+			WarmupTimeout: 100 * time.Millisecond,
+		},
+	})
+	cluster.connectionManager = cli
+
+	start := time.Now()
+	err := cluster.WaitUntilReady(10*time.Second, &WaitUntilReadyOptions{
+		WarmKVConnections: true,
+	})
+	if !errors.Is(err, ErrUnambiguousTimeout) {
+		suite.T().Fatalf("Expected error to be unambiguous timeout but was %v", err)
+	}
+	suite.Assert().Less(int64(time.Since(start)), int64(5*time.Second))
+	suite.Assert().Contains(err.Error(), "1 of 2 connections are connected")
+}
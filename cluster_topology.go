@@ -0,0 +1,217 @@
+package gocb
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultTopologyPollInterval = 2500 * time.Millisecond
+
+// TopologyNode describes a single node within a TopologyChangeEvent.
+// VOLATILE: This API is subject to change at any time.
+type TopologyNode struct {
+	// Hostname is the hostname and management port of the node, e.g. "10.0.0.1:8091".
+	Hostname string
+	// Status is the health of the node as reported by the cluster manager, e.g. "healthy" or "unhealthy".
+	Status string
+	// ClusterMembership is the membership state of the node, e.g. "active" or "inactiveAdded".
+	ClusterMembership string
+	// Version is the server version running on the node.
+	Version string
+}
+
+// TopologyChangeEvent is the payload passed to a TopologyChangeListener.
+// VOLATILE: This API is subject to change at any time.
+type TopologyChangeEvent struct {
+	// Nodes is the full set of nodes now in the cluster, sorted by Hostname.
+	Nodes []TopologyNode
+	// Added contains the nodes which were not present in the previous event.
+	Added []TopologyNode
+	// Removed contains the nodes which are no longer present since the previous event.
+	Removed []TopologyNode
+}
+
+// TopologyChangeListener is invoked whenever the set of nodes in the cluster, or the state of any of those nodes,
+// changes. The first invocation occurs once the initial node set has been fetched and has no Removed nodes.
+// VOLATILE: This API is subject to change at any time.
+type TopologyChangeListener func(event TopologyChangeEvent)
+
+// TopologyConfig specifies options for topology change notifications.
+// VOLATILE: This API is subject to change at any time.
+type TopologyConfig struct {
+	// ChangeListener is invoked from a dedicated goroutine, events are delivered one at a time and in order so a
+	// slow listener will delay subsequent events but will never block SDK operations. The listener may close the
+	// cluster, once Cluster.Close has returned no further events are delivered.
+	ChangeListener TopologyChangeListener
+
+	// ConfigChangeListener is invoked from a dedicated goroutine whenever a new revision of the cluster configuration
+	// is applied by the SDK, allowing application errors to be correlated with rebalances. Unlike ChangeListener it
+	// does not poll the cluster manager, the configuration already held by the SDK is inspected. The revision is only
+	// tracked, and DiagnosticsResult.ConfigUpdates only counted, whilst this is set. Like ChangeListener it may close
+	// the cluster.
+	ConfigChangeListener ConfigChangeListener
+
+	// PollInterval is how often the cluster manager is polled for the current node set, and how often the revision
+	// of the cluster configuration is checked, defaults to 2.5s.
+	PollInterval time.Duration
+}
+
+type topologyWatcher struct {
+	cluster  *Cluster
+	listener TopologyChangeListener
+	interval time.Duration
+
+	lastNodes []TopologyNode
+
+	// events passes changes from the polling goroutine to the goroutine which calls the listener, so that the
+	// listener can stop the watcher without waiting for itself to return.
+	events chan TopologyChangeEvent
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+}
+
+func newTopologyWatcher(cluster *Cluster, config TopologyConfig) *topologyWatcher {
+	interval := config.PollInterval
+	if interval == 0 {
+		interval = defaultTopologyPollInterval
+	}
+
+	return &topologyWatcher{
+		cluster:  cluster,
+		listener: config.ChangeListener,
+		interval: interval,
+		events:   make(chan TopologyChangeEvent),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+func (tw *topologyWatcher) start() {
+	go tw.loop()
+	go tw.dispatch()
+}
+
+func (tw *topologyWatcher) stop() {
+	tw.stopOnce.Do(func() {
+		close(tw.stopCh)
+	})
+	<-tw.doneCh
+}
+
+func (tw *topologyWatcher) loop() {
+	defer close(tw.doneCh)
+
+	for {
+		tw.poll()
+
+		select {
+		case <-tw.stopCh:
+			return
+		case <-time.After(tw.interval):
+		}
+	}
+}
+
+func (tw *topologyWatcher) poll() {
+	nodes, err := tw.cluster.Internal().GetNodesMetadata(&GetNodesMetadataOptions{
+		Timeout: tw.interval,
+	})
+	if err != nil {
+		logDebugf("Failed to fetch cluster topology: %v", err)
+		return
+	}
+
+	event, changed := tw.update(nodes)
+	if !changed {
+		return
+	}
+
+	tw.deliver(event)
+}
+
+// deliver waits for the listener to accept the event, or for the watcher to be stopped.
+func (tw *topologyWatcher) deliver(event TopologyChangeEvent) {
+	select {
+	case tw.events <- event:
+	case <-tw.stopCh:
+	}
+}
+
+func (tw *topologyWatcher) dispatch() {
+	for {
+		select {
+		case event := <-tw.events:
+			select {
+			case <-tw.stopCh:
+				return
+			default:
+			}
+
+			tw.listener(event)
+		case <-tw.stopCh:
+			return
+		}
+	}
+}
+
+func (tw *topologyWatcher) update(nodes []NodeMetadata) (TopologyChangeEvent, bool) {
+	newNodes := make([]TopologyNode, len(nodes))
+	for i, node := range nodes {
+		newNodes[i] = TopologyNode{
+			Hostname:          node.Hostname,
+			Status:            node.Status,
+			ClusterMembership: node.ClusterMembership,
+			Version:           node.Version,
+		}
+	}
+	sort.Slice(newNodes, func(i, j int) bool {
+		return newNodes[i].Hostname < newNodes[j].Hostname
+	})
+
+	if tw.lastNodes != nil && topologyNodesEqual(tw.lastNodes, newNodes) {
+		return TopologyChangeEvent{}, false
+	}
+
+	event := TopologyChangeEvent{
+		Nodes:   newNodes,
+		Added:   topologyNodesDifference(newNodes, tw.lastNodes),
+		Removed: topologyNodesDifference(tw.lastNodes, newNodes),
+	}
+	tw.lastNodes = newNodes
+
+	return event, true
+}
+
+func topologyNodesEqual(a, b []TopologyNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// topologyNodesDifference returns the nodes within a whose hostname does not appear in b.
+func topologyNodesDifference(a, b []TopologyNode) []TopologyNode {
+	hostnames := make(map[string]struct{}, len(b))
+	for _, node := range b {
+		hostnames[node.Hostname] = struct{}{}
+	}
+
+	var diff []TopologyNode
+	for _, node := range a {
+		if _, ok := hostnames[node.Hostname]; !ok {
+			diff = append(diff, node)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,74 @@
+package gocb
+
+import (
+	"time"
+)
+
+func (suite *UnitTestSuite) TestTopologyWatcherUpdate() {
+	tw := newTopologyWatcher(nil, TopologyConfig{})
+	suite.Assert().Equal(defaultTopologyPollInterval, tw.interval)
+
+	nodeA := NodeMetadata{Hostname: "10.0.0.1:8091", Status: "healthy", ClusterMembership: "active", Version: "7.1.0"}
+	nodeB := NodeMetadata{Hostname: "10.0.0.2:8091", Status: "healthy", ClusterMembership: "active", Version: "7.1.0"}
+	nodeC := NodeMetadata{Hostname: "10.0.0.3:8091", Status: "healthy", ClusterMembership: "inactiveAdded", Version: "7.1.0"}
+
+	event, changed := tw.update([]NodeMetadata{nodeB, nodeA})
+	suite.Require().True(changed)
+	suite.Require().Len(event.Nodes, 2)
+	suite.Assert().Equal("10.0.0.1:8091", event.Nodes[0].Hostname)
+	suite.Assert().Equal("10.0.0.2:8091", event.Nodes[1].Hostname)
+	suite.Assert().Len(event.Added, 2)
+	suite.Assert().Empty(event.Removed)
+
+	_, changed = tw.update([]NodeMetadata{nodeA, nodeB})
+	suite.Assert().False(changed)
+
+	event, changed = tw.update([]NodeMetadata{nodeA, nodeC})
+	suite.Require().True(changed)
+	suite.Assert().Len(event.Nodes, 2)
+	if suite.Assert().Len(event.Added, 1) {
+		suite.Assert().Equal(nodeC.Hostname, event.Added[0].Hostname)
+		suite.Assert().Equal("inactiveAdded", event.Added[0].ClusterMembership)
+	}
+	if suite.Assert().Len(event.Removed, 1) {
+		suite.Assert().Equal(nodeB.Hostname, event.Removed[0].Hostname)
+	}
+
+	nodeC.Status = "unhealthy"
+	event, changed = tw.update([]NodeMetadata{nodeA, nodeC})
+	suite.Require().True(changed)
+	suite.Assert().Empty(event.Added)
+	suite.Assert().Empty(event.Removed)
+	suite.Assert().Equal("unhealthy", event.Nodes[1].Status)
+}
+
+func (suite *UnitTestSuite) TestTopologyWatcherListenerStops() {
+	var tw *topologyWatcher
+	var events []TopologyChangeEvent
+	tw = newTopologyWatcher(nil, TopologyConfig{
+		ChangeListener: func(event TopologyChangeEvent) {
+			events = append(events, event)
+
+			// Closing the cluster from the listener stops the watcher, which must not wait for the listener.
+			tw.stop()
+		},
+	})
+	go tw.dispatch()
+
+	// Stands in for the polling loop.
+	go func() {
+		defer close(tw.doneCh)
+		tw.deliver(TopologyChangeEvent{Nodes: []TopologyNode{{Hostname: "10.0.0.1:8091"}}})
+		<-tw.stopCh
+		tw.deliver(TopologyChangeEvent{})
+	}()
+
+	select {
+	case <-tw.doneCh:
+	case <-time.After(5 * time.Second):
+		suite.T().Fatalf("Timed out waiting for the watcher to stop")
+	}
+
+	suite.Require().Len(events, 1)
+	suite.Assert().Equal("10.0.0.1:8091", events[0].Nodes[0].Hostname)
+}
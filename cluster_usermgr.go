@@ -91,6 +91,57 @@ func (ro *Role) fromData(data jsonRole) error {
 	return nil
 }
 
+// encodeRoles encodes roles in the form expected by the server, e.g. data_reader[travel-sample:inventory:airline].
+// An empty or "*" Scope or Collection grants the role across all scopes or collections respectively.
+func encodeRoles(roles []Role) (string, error) {
+	parseWildcard := func(str string) string {
+		if str == "*" {
+			return ""
+		}
+
+		return str
+	}
+
+	isNullOrWildcard := func(str string) bool {
+		if str == "*" || str == "" {
+			return true
+		}
+
+		return false
+	}
+
+	var reqRoleStrs []string
+	for _, roleData := range roles {
+		if roleData.Bucket == "" {
+			reqRoleStrs = append(reqRoleStrs, roleData.Name)
+			continue
+		}
+
+		scope := parseWildcard(roleData.Scope)
+		collection := parseWildcard(roleData.Collection)
+
+		if scope != "" && isNullOrWildcard(roleData.Bucket) {
+			return "", makeInvalidArgumentsError("when a scope is specified, the bucket cannot be null or wildcard")
+		}
+		if collection != "" && isNullOrWildcard(scope) {
+			return "", makeInvalidArgumentsError("when a collection is specified, the scope cannot be null or wildcard")
+		}
+
+		roleStr := fmt.Sprintf("%s[%s", roleData.Name, roleData.Bucket)
+		if scope != "" {
+			roleStr += ":" + scope
+		}
+		if collection != "" {
+			roleStr += ":" + collection
+		}
+		roleStr += "]"
+
+		reqRoleStrs = append(reqRoleStrs, roleStr)
+	}
+
+	return strings.Join(reqRoleStrs, ","), nil
+}
+
 // RoleAndDescription represents a role with its display name and description.
 type RoleAndDescription struct {
 	Role
@@ -451,54 +502,14 @@ func (um *UserManager) UpsertUser(user User, opts *UpsertUserOptions) error {
 		opts.DomainName = string(LocalDomain)
 	}
 
-	parseWildcard := func(str string) string {
-		if str == "*" {
-			return ""
-		}
-
-		return str
-	}
-
-	isNullOrWildcard := func(str string) bool {
-		if str == "*" || str == "" {
-			return true
-		}
-
-		return false
-	}
-
 	path := fmt.Sprintf("/settings/rbac/users/%s/%s", opts.DomainName, user.Username)
 	span := createSpan(um.tracer, opts.ParentSpan, "manager_users_upsert_user", "management")
 	span.SetAttribute("db.operation", "PUT "+path)
 	defer span.End()
 
-	var reqRoleStrs []string
-	for _, roleData := range user.Roles {
-		if roleData.Bucket == "" {
-			reqRoleStrs = append(reqRoleStrs, roleData.Name)
-		} else {
-			scope := parseWildcard(roleData.Scope)
-			collection := parseWildcard(roleData.Collection)
-
-			if scope != "" && isNullOrWildcard(roleData.Bucket) {
-				return makeInvalidArgumentsError("when a scope is specified, the bucket cannot be null or wildcard")
-			}
-			if collection != "" && isNullOrWildcard(scope) {
-				return makeInvalidArgumentsError("when a collection is specified, the scope cannot be null or wildcard")
-			}
-
-			roleStr := fmt.Sprintf("%s[%s", roleData.Name, roleData.Bucket)
-			if scope != "" {
-				roleStr += ":" + roleData.Scope
-			}
-			if collection != "" {
-				roleStr += ":" + roleData.Collection
-			}
-			roleStr += "]"
-
-			reqRoleStrs = append(reqRoleStrs, roleStr)
-
-		}
+	reqRoles, err := encodeRoles(user.Roles)
+	if err != nil {
+		return err
 	}
 
 	reqForm := make(url.Values)
@@ -509,7 +520,7 @@ func (um *UserManager) UpsertUser(user User, opts *UpsertUserOptions) error {
 	if len(user.Groups) > 0 {
 		reqForm.Add("groups", strings.Join(user.Groups, ","))
 	}
-	reqForm.Add("roles", strings.Join(reqRoleStrs, ","))
+	reqForm.Add("roles", reqRoles)
 
 	req := mgmtRequest{
 		Service:       ServiceTypeManagement,
@@ -835,19 +846,15 @@ func (um *UserManager) UpsertGroup(group Group, opts *UpsertGroupOptions) error
 	span.SetAttribute("db.operation", "PUT "+path)
 	defer span.End()
 
-	var reqRoleStrs []string
-	for _, roleData := range group.Roles {
-		if roleData.Bucket == "" {
-			reqRoleStrs = append(reqRoleStrs, roleData.Name)
-		} else {
-			reqRoleStrs = append(reqRoleStrs, fmt.Sprintf("%s[%s]", roleData.Name, roleData.Bucket))
-		}
+	reqRoles, err := encodeRoles(group.Roles)
+	if err != nil {
+		return err
 	}
 
 	reqForm := make(url.Values)
 	reqForm.Add("description", group.Description)
 	reqForm.Add("ldap_group_ref", group.LDAPGroupReference)
-	reqForm.Add("roles", strings.Join(reqRoleStrs, ","))
+	reqForm.Add("roles", reqRoles)
 
 	req := mgmtRequest{
 		Service:       ServiceTypeManagement,
@@ -934,3 +941,96 @@ func (um *UserManager) DropGroup(groupName string, opts *DropGroupOptions) error
 
 	return nil
 }
+
+// ChangePasswordOptions is the set of options available to the user manager ChangePassword operation.
+type ChangePasswordOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// ChangePassword changes the password of the currently authenticated user.
+// The server does not expose a way to list or revoke the existing sessions of a user, connections which have already
+// authenticated are not closed by the server when the password changes.
+// If the server does not support changing passwords then ErrFeatureNotAvailable is returned.
+func (um *UserManager) ChangePassword(newPassword string, opts *ChangePasswordOptions) error {
+	if newPassword == "" {
+		return makeInvalidArgumentsError("new password cannot be empty")
+	}
+
+	if opts == nil {
+		opts = &ChangePasswordOptions{}
+	}
+
+	start := time.Now()
+	defer um.meter.ValueRecord(meterValueServiceManagement, "manager_users_change_password", start)
+
+	path := "/controller/changePassword"
+	span := createSpan(um.tracer, opts.ParentSpan, "manager_users_change_password", "management")
+	span.SetAttribute("db.operation", "POST "+path)
+	defer span.End()
+
+	reqForm := make(url.Values)
+	reqForm.Add("password", newPassword)
+
+	req := mgmtRequest{
+		Service:       ServiceTypeManagement,
+		Method:        "POST",
+		Path:          path,
+		Body:          []byte(reqForm.Encode()),
+		ContentType:   "application/x-www-form-urlencoded",
+		RetryStrategy: opts.RetryStrategy,
+		UniqueID:      uuid.New().String(),
+		Timeout:       opts.Timeout,
+		parentSpanCtx: span.Context(),
+	}
+
+	resp, err := um.provider.executeMgmtRequest(opts.Context, req)
+	if err != nil {
+		return makeGenericMgmtError(err, &req, resp, "")
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	if resp.StatusCode == 404 {
+		return makeGenericMgmtError(ErrFeatureNotAvailable, &req, resp, "")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		usrErr := um.tryParseErrorMessage(&req, resp)
+		if usrErr != nil {
+			return usrErr
+		}
+		return makeMgmtBadStatusError("failed to change password", &req, resp)
+	}
+
+	return nil
+}
+
+// ChangePassword changes the password of the user that the cluster is authenticated as.
+//
+// If the cluster was connected using a PasswordAuthenticator then the new password is used for any connections
+// created after the change. Existing connections remain authenticated and do not need to be recreated, however any
+// connection which is re-established (e.g. after a network failure or during rebalance) will use the new password.
+// Other authenticators are unchanged, in which case the application must reconnect with the new credentials.
+func (c *Cluster) ChangePassword(newPassword string, opts *ChangePasswordOptions) error {
+	err := c.Users().ChangePassword(newPassword, opts)
+	if err != nil {
+		return err
+	}
+
+	c.authLock.Lock()
+	switch auth := c.auth.(type) {
+	case PasswordAuthenticator:
+		c.auth = PasswordAuthenticator{Username: auth.Username, Password: newPassword}
+	case *PasswordAuthenticator:
+		c.auth = PasswordAuthenticator{Username: auth.Username, Password: newPassword}
+	}
+	c.authLock.Unlock()
+
+	return nil
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"io/ioutil"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/couchbase/gocbcore/v10"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -631,3 +633,141 @@ func (suite *UnitTestSuite) TestUserManagerDropGroupDoesntExist() {
 		suite.T().Fatalf("Expected user not found error, %s", err)
 	}
 }
+
+func (suite *UnitTestSuite) TestUserManagerChangePassword() {
+	resp := &mgmtResponse{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/controller/changePassword", req.Path)
+			suite.Assert().False(req.IsIdempotent)
+			suite.Assert().Equal("POST", req.Method)
+			suite.Assert().Equal("application/x-www-form-urlencoded", req.ContentType)
+			suite.Assert().Equal("password=n3w%26pass", string(req.Body))
+		}).
+		Return(resp, nil)
+
+	usrMgr := &UserManager{
+		provider: mockProvider,
+		tracer:   &NoopTracer{},
+		meter:    &meterWrapper{meter: &NoopMeter{}},
+	}
+	err := usrMgr.ChangePassword("n3w&pass", nil)
+	suite.Require().Nil(err, err)
+}
+
+func (suite *UnitTestSuite) TestUserManagerChangePasswordNotSupported() {
+	resp := &mgmtResponse{
+		StatusCode: 404,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte("Not found."))),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(resp, nil)
+
+	usrMgr := &UserManager{
+		provider: mockProvider,
+		tracer:   &NoopTracer{},
+		meter:    &meterWrapper{meter: &NoopMeter{}},
+	}
+	err := usrMgr.ChangePassword("newpass", nil)
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected feature not available error, %s", err)
+	}
+
+	err = usrMgr.ChangePassword("", nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error, %s", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestClusterChangePasswordUpdatesAuthenticator() {
+	httpProvider := new(mockHttpProvider)
+	httpProvider.
+		On("DoHTTPRequest", nil, mock.AnythingOfType("*gocbcore.HTTPRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(*gocbcore.HTTPRequest)
+
+			suite.Assert().Equal("/controller/changePassword", req.Path)
+			suite.Assert().Equal("password=newpass", string(req.Body))
+		}).
+		Return(&gocbcore.HTTPResponse{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getHTTPProvider", "").Return(httpProvider, nil)
+
+	cluster := suite.newCluster(cli)
+	cluster.auth = PasswordAuthenticator{
+		Username: "barry",
+		Password: "oldpass",
+	}
+
+	err := cluster.ChangePassword("newpass", nil)
+	suite.Require().Nil(err, err)
+
+	creds, err := cluster.authenticator().Credentials(AuthCredsRequest{})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]UserPassPair{{Username: "barry", Password: "newpass"}}, creds)
+}
+
+func (suite *UnitTestSuite) TestUserManagerUpsertGroupCollectionRoles() {
+	resp := &mgmtResponse{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/settings/rbac/groups/test", req.Path)
+			suite.Assert().Equal("PUT", req.Method)
+
+			form, err := url.ParseQuery(string(req.Body))
+			suite.Require().Nil(err, err)
+			suite.Assert().Equal("admin,data_reader[travel-sample:inventory:airline],query_select[travel-sample:inventory],"+
+				"data_writer[travel-sample]", form.Get("roles"))
+		}).
+		Return(resp, nil)
+
+	usrMgr := &UserManager{
+		provider: mockProvider,
+		tracer:   &NoopTracer{},
+		meter:    &meterWrapper{meter: &NoopMeter{}},
+	}
+	err := usrMgr.UpsertGroup(Group{
+		Name: "test",
+		Roles: []Role{
+			{Name: "admin"},
+			{Name: "data_reader", Bucket: "travel-sample", Scope: "inventory", Collection: "airline"},
+			{Name: "query_select", Bucket: "travel-sample", Scope: "inventory", Collection: "*"},
+			{Name: "data_writer", Bucket: "travel-sample", Scope: "*"},
+		},
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	err = usrMgr.UpsertGroup(Group{
+		Name: "test",
+		Roles: []Role{
+			{Name: "data_reader", Bucket: "travel-sample", Collection: "airline"},
+		},
+	}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+	mockProvider.AssertNumberOfCalls(suite.T(), "executeMgmtRequest", 1)
+}
@@ -0,0 +1,693 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RemoteClusterEncryptionType specifies how connections to a remote cluster are encrypted.
+// UNCOMMITTED: This API may change in the future.
+type RemoteClusterEncryptionType string
+
+const (
+	// RemoteClusterEncryptionTypeNone indicates that connections to the remote cluster are not encrypted.
+	RemoteClusterEncryptionTypeNone RemoteClusterEncryptionType = "none"
+
+	// RemoteClusterEncryptionTypeHalf indicates that only credentials are encrypted, data is sent unencrypted.
+	RemoteClusterEncryptionTypeHalf RemoteClusterEncryptionType = "half"
+
+	// RemoteClusterEncryptionTypeFull indicates that all data sent to the remote cluster is encrypted.
+	RemoteClusterEncryptionTypeFull RemoteClusterEncryptionType = "full"
+)
+
+// ReplicationCompressionType specifies the compression used for data sent by a replication.
+// UNCOMMITTED: This API may change in the future.
+type ReplicationCompressionType string
+
+const (
+	// ReplicationCompressionTypeNone indicates that data is sent uncompressed.
+	ReplicationCompressionTypeNone ReplicationCompressionType = "None"
+
+	// ReplicationCompressionTypeAuto indicates that data is compressed whenever the remote cluster supports it.
+	ReplicationCompressionTypeAuto ReplicationCompressionType = "Auto"
+)
+
+// ReplicationPriority specifies the priority of a replication relative to the other replications on the cluster.
+// UNCOMMITTED: This API may change in the future.
+type ReplicationPriority string
+
+const (
+	// ReplicationPriorityHigh indicates a high priority replication.
+	ReplicationPriorityHigh ReplicationPriority = "High"
+
+	// ReplicationPriorityMedium indicates a medium priority replication.
+	ReplicationPriorityMedium ReplicationPriority = "Medium"
+
+	// ReplicationPriorityLow indicates a low priority replication.
+	ReplicationPriorityLow ReplicationPriority = "Low"
+)
+
+// xdcrManager holds the state shared by the remote cluster and replication managers, which both use the cluster
+// manager XDCR REST API.
+type xdcrManager struct {
+	mgmtProvider mgmtProvider
+	tracer       RequestTracer
+	meter        *meterWrapper
+}
+
+func (xm *xdcrManager) tryParseErrorMessage(req *mgmtRequest, resp *mgmtResponse) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logDebugf("Failed to read xdcr response body: %s", err)
+		return nil
+	}
+
+	var baseErr error
+	strBody := strings.ToLower(string(b))
+	if strings.Contains(strBody, "unknown remote cluster") || strings.Contains(strBody, "cannot find remote cluster") {
+		baseErr = ErrRemoteClusterNotFound
+	} else if strings.Contains(strBody, "duplicate cluster names") ||
+		(strings.Contains(strBody, "cluster reference") && strings.Contains(strBody, "already exists")) {
+		baseErr = ErrRemoteClusterExists
+	} else if strings.Contains(strBody, "replication") && strings.Contains(strBody, "already exists") {
+		baseErr = ErrReplicationExists
+	} else if resp.StatusCode == 404 {
+		if strings.HasPrefix(req.Path, "/pools/default/remoteClusters") {
+			baseErr = ErrRemoteClusterNotFound
+		} else {
+			baseErr = ErrReplicationNotFound
+		}
+	} else {
+		return nil
+	}
+
+	return makeGenericMgmtError(baseErr, req, resp, string(b))
+}
+
+type xdcrRequestOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+	Context       context.Context
+}
+
+func (xm *xdcrManager) doRequest(path, method, opName string, form url.Values, target interface{},
+	opts xdcrRequestOptions) error {
+	start := time.Now()
+	defer xm.meter.ValueRecord(meterValueServiceManagement, opName, start)
+
+	span := createSpan(xm.tracer, opts.ParentSpan, opName, "management")
+	span.SetAttribute("db.operation", method+" "+path)
+	defer span.End()
+
+	req := mgmtRequest{
+		Service:       ServiceTypeManagement,
+		Method:        method,
+		Path:          path,
+		IsIdempotent:  method == "GET",
+		RetryStrategy: opts.RetryStrategy,
+		UniqueID:      uuid.New().String(),
+		Timeout:       opts.Timeout,
+		parentSpanCtx: span.Context(),
+	}
+	if form != nil {
+		req.Body = []byte(form.Encode())
+		req.ContentType = "application/x-www-form-urlencoded"
+	}
+
+	resp, err := xm.mgmtProvider.executeMgmtRequest(opts.Context, req)
+	if err != nil {
+		return makeGenericMgmtError(err, &req, resp, "")
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	if resp.StatusCode != 200 {
+		xdcrErr := xm.tryParseErrorMessage(&req, resp)
+		if xdcrErr != nil {
+			return xdcrErr
+		}
+
+		return makeMgmtBadStatusError("failed xdcr "+opName, &req, resp)
+	}
+
+	if target != nil {
+		jsonDec := json.NewDecoder(resp.Body)
+		err = jsonDec.Decode(target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoteClusterManager provides methods for managing the remote cluster references used as the targets of XDCR
+// replications.
+// UNCOMMITTED: This API may change in the future.
+type RemoteClusterManager struct {
+	xdcrManager
+}
+
+// RemoteClusterSettings are the settings used to create a remote cluster reference.
+// UNCOMMITTED: This API may change in the future.
+type RemoteClusterSettings struct {
+	Name     string
+	Hostname string
+	Username string
+	Password string
+
+	// EncryptionType defaults to RemoteClusterEncryptionTypeNone.
+	EncryptionType RemoteClusterEncryptionType
+
+	// Certificate is the PEM encoded root certificate of the remote cluster, required when EncryptionType is
+	// RemoteClusterEncryptionTypeFull.
+	Certificate string
+}
+
+// RemoteCluster represents a remote cluster reference.
+// UNCOMMITTED: This API may change in the future.
+type RemoteCluster struct {
+	Name           string
+	UUID           string
+	Hostname       string
+	Username       string
+	EncryptionType RemoteClusterEncryptionType
+}
+
+type jsonRemoteCluster struct {
+	Name             string `json:"name"`
+	UUID             string `json:"uuid"`
+	Hostname         string `json:"hostname"`
+	Username         string `json:"username"`
+	Deleted          bool   `json:"deleted"`
+	SecureType       string `json:"secureType"`
+	DemandEncryption bool   `json:"demandEncryption"`
+	EncryptionType   string `json:"encryptionType"`
+}
+
+func (rc *RemoteCluster) fromData(data jsonRemoteCluster) {
+	rc.Name = data.Name
+	rc.UUID = data.UUID
+	rc.Hostname = data.Hostname
+	rc.Username = data.Username
+
+	switch {
+	case data.SecureType != "":
+		// secureType is only returned by newer servers.
+		rc.EncryptionType = RemoteClusterEncryptionType(data.SecureType)
+	case data.DemandEncryption && data.EncryptionType != "":
+		rc.EncryptionType = RemoteClusterEncryptionType(data.EncryptionType)
+	case data.DemandEncryption:
+		rc.EncryptionType = RemoteClusterEncryptionTypeFull
+	default:
+		rc.EncryptionType = RemoteClusterEncryptionTypeNone
+	}
+}
+
+// CreateRemoteClusterOptions is the set of options available to the remote cluster manager CreateRemoteCluster
+// operation.
+// UNCOMMITTED: This API may change in the future.
+type CreateRemoteClusterOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// CreateRemoteCluster creates a reference to a remote cluster, which can then be used as the target of replications.
+// If a reference with the same name already exists then ErrRemoteClusterExists is returned.
+// UNCOMMITTED: This API may change in the future.
+func (rm *RemoteClusterManager) CreateRemoteCluster(settings RemoteClusterSettings, opts *CreateRemoteClusterOptions) error {
+	if opts == nil {
+		opts = &CreateRemoteClusterOptions{}
+	}
+
+	if settings.Name == "" {
+		return makeInvalidArgumentsError("remote cluster name cannot be empty")
+	}
+	if settings.Hostname == "" {
+		return makeInvalidArgumentsError("remote cluster hostname cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Add("name", settings.Name)
+	form.Add("hostname", settings.Hostname)
+	form.Add("username", settings.Username)
+	form.Add("password", settings.Password)
+
+	switch settings.EncryptionType {
+	case "", RemoteClusterEncryptionTypeNone:
+		form.Add("demandEncryption", "0")
+	case RemoteClusterEncryptionTypeHalf, RemoteClusterEncryptionTypeFull:
+		form.Add("demandEncryption", "1")
+		form.Add("encryptionType", string(settings.EncryptionType))
+	default:
+		return makeInvalidArgumentsError("unrecognized remote cluster encryption type")
+	}
+
+	if settings.Certificate != "" {
+		form.Add("certificate", settings.Certificate)
+	} else if settings.EncryptionType == RemoteClusterEncryptionTypeFull {
+		return makeInvalidArgumentsError("a certificate must be specified for full encryption")
+	}
+
+	return rm.doRequest("/pools/default/remoteClusters", "POST", "manager_xdcr_create_remote_cluster", form, nil,
+		xdcrRequestOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+}
+
+// GetAllRemoteClustersOptions is the set of options available to the remote cluster manager GetAllRemoteClusters
+// operation.
+// UNCOMMITTED: This API may change in the future.
+type GetAllRemoteClustersOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// GetAllRemoteClusters returns all of the remote cluster references on the cluster.
+// UNCOMMITTED: This API may change in the future.
+func (rm *RemoteClusterManager) GetAllRemoteClusters(opts *GetAllRemoteClustersOptions) ([]RemoteCluster, error) {
+	if opts == nil {
+		opts = &GetAllRemoteClustersOptions{}
+	}
+
+	var jsonClusters []jsonRemoteCluster
+	err := rm.doRequest("/pools/default/remoteClusters", "GET", "manager_xdcr_get_all_remote_clusters", nil,
+		&jsonClusters, xdcrRequestOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []RemoteCluster
+	for _, jsonCluster := range jsonClusters {
+		// References which have been deleted are retained by the server for a while but are no longer usable.
+		if jsonCluster.Deleted {
+			continue
+		}
+
+		var cluster RemoteCluster
+		cluster.fromData(jsonCluster)
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters, nil
+}
+
+// GetRemoteClusterOptions is the set of options available to the remote cluster manager GetRemoteCluster operation.
+// UNCOMMITTED: This API may change in the future.
+type GetRemoteClusterOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// GetRemoteCluster returns the remote cluster reference with the given name, or ErrRemoteClusterNotFound.
+// UNCOMMITTED: This API may change in the future.
+func (rm *RemoteClusterManager) GetRemoteCluster(name string, opts *GetRemoteClusterOptions) (*RemoteCluster, error) {
+	if opts == nil {
+		opts = &GetRemoteClusterOptions{}
+	}
+
+	clusters, err := rm.GetAllRemoteClusters(&GetAllRemoteClustersOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cluster := range clusters {
+		if cluster.Name == name {
+			return &cluster, nil
+		}
+	}
+
+	return nil, makeGenericMgmtError(ErrRemoteClusterNotFound, nil, nil, fmt.Sprintf("remote cluster %s not found", name))
+}
+
+// DropRemoteClusterOptions is the set of options available to the remote cluster manager DropRemoteCluster operation.
+// UNCOMMITTED: This API may change in the future.
+type DropRemoteClusterOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// DropRemoteCluster removes a remote cluster reference, any replications which target it must be dropped first.
+// If no reference with the given name exists then ErrRemoteClusterNotFound is returned.
+// UNCOMMITTED: This API may change in the future.
+func (rm *RemoteClusterManager) DropRemoteCluster(name string, opts *DropRemoteClusterOptions) error {
+	if opts == nil {
+		opts = &DropRemoteClusterOptions{}
+	}
+
+	if name == "" {
+		return makeInvalidArgumentsError("remote cluster name cannot be empty")
+	}
+
+	return rm.doRequest(fmt.Sprintf("/pools/default/remoteClusters/%s", url.PathEscape(name)), "DELETE",
+		"manager_xdcr_drop_remote_cluster", nil, nil, xdcrRequestOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+}
+
+// ReplicationManager provides methods for managing XDCR replications.
+// UNCOMMITTED: This API may change in the future.
+type ReplicationManager struct {
+	xdcrManager
+}
+
+// ReplicationSettings are the settings used to create a replication.
+// UNCOMMITTED: This API may change in the future.
+type ReplicationSettings struct {
+	SourceBucket string
+
+	// TargetCluster is the name of the remote cluster reference to replicate to.
+	TargetCluster string
+	TargetBucket  string
+
+	// FilterExpression limits the documents which are replicated to those matching the expression.
+	FilterExpression string
+
+	// Paused creates the replication in a paused state.
+	Paused bool
+
+	CompressionType    ReplicationCompressionType
+	Priority           ReplicationPriority
+	CheckpointInterval time.Duration
+
+	// Any settings left as zero use the server default.
+	WorkerBatchSize     uint32
+	DocBatchSizeKB      uint32
+	SourceNozzlePerNode uint32
+	TargetNozzlePerNode uint32
+}
+
+func (rs ReplicationSettings) toPostData() (url.Values, error) {
+	if rs.SourceBucket == "" {
+		return nil, makeInvalidArgumentsError("source bucket cannot be empty")
+	}
+	if rs.TargetCluster == "" {
+		return nil, makeInvalidArgumentsError("target cluster cannot be empty")
+	}
+	if rs.TargetBucket == "" {
+		return nil, makeInvalidArgumentsError("target bucket cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Add("fromBucket", rs.SourceBucket)
+	form.Add("toCluster", rs.TargetCluster)
+	form.Add("toBucket", rs.TargetBucket)
+	form.Add("replicationType", "continuous")
+
+	if rs.FilterExpression != "" {
+		form.Add("filterExpression", rs.FilterExpression)
+	}
+	if rs.Paused {
+		form.Add("pauseRequested", "true")
+	}
+	if rs.CompressionType != "" {
+		form.Add("compressionType", string(rs.CompressionType))
+	}
+	if rs.Priority != "" {
+		form.Add("priority", string(rs.Priority))
+	}
+	if rs.CheckpointInterval > 0 {
+		form.Add("checkpointInterval", strconv.Itoa(int(rs.CheckpointInterval/time.Second)))
+	}
+	if rs.WorkerBatchSize > 0 {
+		form.Add("workerBatchSize", strconv.Itoa(int(rs.WorkerBatchSize)))
+	}
+	if rs.DocBatchSizeKB > 0 {
+		form.Add("docBatchSizeKb", strconv.Itoa(int(rs.DocBatchSizeKB)))
+	}
+	if rs.SourceNozzlePerNode > 0 {
+		form.Add("sourceNozzlePerNode", strconv.Itoa(int(rs.SourceNozzlePerNode)))
+	}
+	if rs.TargetNozzlePerNode > 0 {
+		form.Add("targetNozzlePerNode", strconv.Itoa(int(rs.TargetNozzlePerNode)))
+	}
+
+	return form, nil
+}
+
+// Replication represents an XDCR replication.
+// UNCOMMITTED: This API may change in the future.
+type Replication struct {
+	ID                string
+	SourceBucket      string
+	TargetClusterUUID string
+	TargetBucket      string
+	FilterExpression  string
+
+	// Status is the status reported by the server, e.g. "running", "paused" or "notRunning".
+	Status string
+}
+
+type jsonReplicationTask struct {
+	Type             string `json:"type"`
+	ID               string `json:"id"`
+	Source           string `json:"source"`
+	Target           string `json:"target"`
+	FilterExpression string `json:"filterExpression"`
+	Status           string `json:"status"`
+}
+
+func (r *Replication) fromData(data jsonReplicationTask) {
+	r.ID = data.ID
+	r.SourceBucket = data.Source
+	r.FilterExpression = data.FilterExpression
+	r.Status = data.Status
+
+	// The target is of the form /remoteClusters/<uuid>/buckets/<bucket>.
+	parts := strings.Split(strings.TrimPrefix(data.Target, "/"), "/")
+	if len(parts) == 4 && parts[0] == "remoteClusters" && parts[2] == "buckets" {
+		r.TargetClusterUUID = parts[1]
+		r.TargetBucket = parts[3]
+	}
+}
+
+// CreateReplicationOptions is the set of options available to the replication manager CreateReplication operation.
+// UNCOMMITTED: This API may change in the future.
+type CreateReplicationOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// CreateReplication creates a continuous replication from a local bucket to a bucket on a remote cluster, returning
+// the ID of the replication. If the remote cluster reference does not exist then ErrRemoteClusterNotFound is
+// returned, if a replication between the same buckets already exists then ErrReplicationExists is returned.
+// UNCOMMITTED: This API may change in the future.
+func (rm *ReplicationManager) CreateReplication(settings ReplicationSettings, opts *CreateReplicationOptions) (string, error) {
+	if opts == nil {
+		opts = &CreateReplicationOptions{}
+	}
+
+	form, err := settings.toPostData()
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+	err = rm.doRequest("/controller/createReplication", "POST", "manager_xdcr_create_replication", form, &resp,
+		xdcrRequestOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ID, nil
+}
+
+// GetAllReplicationsOptions is the set of options available to the replication manager GetAllReplications operation.
+// UNCOMMITTED: This API may change in the future.
+type GetAllReplicationsOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// GetAllReplications returns all of the replications from this cluster.
+// UNCOMMITTED: This API may change in the future.
+func (rm *ReplicationManager) GetAllReplications(opts *GetAllReplicationsOptions) ([]Replication, error) {
+	if opts == nil {
+		opts = &GetAllReplicationsOptions{}
+	}
+
+	var tasks []jsonReplicationTask
+	err := rm.doRequest("/pools/default/tasks", "GET", "manager_xdcr_get_all_replications", nil, &tasks,
+		xdcrRequestOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var replications []Replication
+	for _, task := range tasks {
+		if task.Type != "xdcr" {
+			continue
+		}
+
+		var replication Replication
+		replication.fromData(task)
+		replications = append(replications, replication)
+	}
+
+	return replications, nil
+}
+
+// PauseReplicationOptions is the set of options available to the replication manager PauseReplication operation.
+// UNCOMMITTED: This API may change in the future.
+type PauseReplicationOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// PauseReplication pauses a replication, it can be restarted from where it left off using ResumeReplication.
+// UNCOMMITTED: This API may change in the future.
+func (rm *ReplicationManager) PauseReplication(id string, opts *PauseReplicationOptions) error {
+	if opts == nil {
+		opts = &PauseReplicationOptions{}
+	}
+
+	return rm.setPauseRequested(id, true, "manager_xdcr_pause_replication", xdcrRequestOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+}
+
+// ResumeReplicationOptions is the set of options available to the replication manager ResumeReplication operation.
+// UNCOMMITTED: This API may change in the future.
+type ResumeReplicationOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// ResumeReplication resumes a paused replication.
+// UNCOMMITTED: This API may change in the future.
+func (rm *ReplicationManager) ResumeReplication(id string, opts *ResumeReplicationOptions) error {
+	if opts == nil {
+		opts = &ResumeReplicationOptions{}
+	}
+
+	return rm.setPauseRequested(id, false, "manager_xdcr_resume_replication", xdcrRequestOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+}
+
+func (rm *ReplicationManager) setPauseRequested(id string, paused bool, opName string, opts xdcrRequestOptions) error {
+	if id == "" {
+		return makeInvalidArgumentsError("replication id cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Add("pauseRequested", strconv.FormatBool(paused))
+
+	return rm.doRequest(fmt.Sprintf("/settings/replications/%s", url.PathEscape(id)), "POST", opName, form, nil,
+		opts)
+}
+
+// DropReplicationOptions is the set of options available to the replication manager DropReplication operation.
+// UNCOMMITTED: This API may change in the future.
+type DropReplicationOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	Context context.Context
+}
+
+// DropReplication removes a replication, if no replication with the given ID exists then ErrReplicationNotFound is
+// returned.
+// UNCOMMITTED: This API may change in the future.
+func (rm *ReplicationManager) DropReplication(id string, opts *DropReplicationOptions) error {
+	if opts == nil {
+		opts = &DropReplicationOptions{}
+	}
+
+	if id == "" {
+		return makeInvalidArgumentsError("replication id cannot be empty")
+	}
+
+	return rm.doRequest(fmt.Sprintf("/controller/cancelXDCR/%s", url.PathEscape(id)), "DELETE",
+		"manager_xdcr_drop_replication", nil, nil, xdcrRequestOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+}
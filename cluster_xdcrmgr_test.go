@@ -0,0 +1,170 @@
+package gocb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) newXDCRManager(respond func(req mgmtRequest) *mgmtResponse) xdcrManager {
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Return(func(ctx context.Context, req mgmtRequest) *mgmtResponse {
+			return respond(req)
+		}, nil)
+
+	return xdcrManager{
+		mgmtProvider: mockProvider,
+		tracer:       &NoopTracer{},
+		meter:        &meterWrapper{meter: &NoopMeter{}},
+	}
+}
+
+func (suite *UnitTestSuite) TestRemoteClusterManager() {
+	var lastReq mgmtRequest
+	mgr := &RemoteClusterManager{
+		xdcrManager: suite.newXDCRManager(func(req mgmtRequest) *mgmtResponse {
+			lastReq = req
+			switch req.Method {
+			case "GET":
+				return &mgmtResponse{
+					StatusCode: 200,
+					Body: ioutil.NopCloser(bytes.NewReader([]byte(`[` +
+						`{"name":"dr","uuid":"abc","hostname":"10.0.0.1:8091","username":"admin","deleted":false,"secureType":"full"},` +
+						`{"name":"old","uuid":"def","hostname":"10.0.0.2:8091","username":"admin","deleted":true}]`))),
+				}
+			case "DELETE":
+				return &mgmtResponse{
+					StatusCode: 404,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte(`"unknown remote cluster"`))),
+				}
+			default:
+				return &mgmtResponse{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{}`))),
+				}
+			}
+		}),
+	}
+
+	err := mgr.CreateRemoteCluster(RemoteClusterSettings{
+		Name:           "dr",
+		Hostname:       "10.0.0.1",
+		Username:       "admin",
+		Password:       "password",
+		EncryptionType: RemoteClusterEncryptionTypeHalf,
+	}, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("/pools/default/remoteClusters", lastReq.Path)
+
+	form, err := url.ParseQuery(string(lastReq.Body))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("dr", form.Get("name"))
+	suite.Assert().Equal("1", form.Get("demandEncryption"))
+	suite.Assert().Equal("half", form.Get("encryptionType"))
+
+	clusters, err := mgr.GetAllRemoteClusters(nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]RemoteCluster{{
+		Name:           "dr",
+		UUID:           "abc",
+		Hostname:       "10.0.0.1:8091",
+		Username:       "admin",
+		EncryptionType: RemoteClusterEncryptionTypeFull,
+	}}, clusters)
+
+	_, err = mgr.GetRemoteCluster("old", nil)
+	if !errors.Is(err, ErrRemoteClusterNotFound) {
+		suite.T().Fatalf("Expected remote cluster not found error but was %v", err)
+	}
+
+	err = mgr.DropRemoteCluster("missing", nil)
+	if !errors.Is(err, ErrRemoteClusterNotFound) {
+		suite.T().Fatalf("Expected remote cluster not found error but was %v", err)
+	}
+
+	err = mgr.CreateRemoteCluster(RemoteClusterSettings{
+		Name:           "dr",
+		Hostname:       "10.0.0.1",
+		EncryptionType: RemoteClusterEncryptionTypeFull,
+	}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestReplicationManager() {
+	var lastReq mgmtRequest
+	mgr := &ReplicationManager{
+		xdcrManager: suite.newXDCRManager(func(req mgmtRequest) *mgmtResponse {
+			lastReq = req
+			switch req.Path {
+			case "/pools/default/tasks":
+				return &mgmtResponse{
+					StatusCode: 200,
+					Body: ioutil.NopCloser(bytes.NewReader([]byte(`[` +
+						`{"type":"rebalance","status":"notRunning"},` +
+						`{"type":"xdcr","id":"abc/src/dst","source":"src","target":"/remoteClusters/abc/buckets/dst",` +
+						`"filterExpression":"REGEXP_CONTAINS(META().id, '^user')","status":"running"}]`))),
+				}
+			case "/controller/createReplication":
+				return &mgmtResponse{
+					StatusCode: 400,
+					Body: ioutil.NopCloser(bytes.NewReader(
+						[]byte(`{"errors":{"_":"Replication to the same remote cluster and bucket already exists"}}`))),
+				}
+			default:
+				return &mgmtResponse{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{}`))),
+				}
+			}
+		}),
+	}
+
+	_, err := mgr.CreateReplication(ReplicationSettings{
+		SourceBucket:     "src",
+		TargetCluster:    "dr",
+		TargetBucket:     "dst",
+		FilterExpression: "REGEXP_CONTAINS(META().id, '^user')",
+		CompressionType:  ReplicationCompressionTypeAuto,
+	}, nil)
+	if !errors.Is(err, ErrReplicationExists) {
+		suite.T().Fatalf("Expected replication exists error but was %v", err)
+	}
+
+	form, err := url.ParseQuery(string(lastReq.Body))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("src", form.Get("fromBucket"))
+	suite.Assert().Equal("dr", form.Get("toCluster"))
+	suite.Assert().Equal("dst", form.Get("toBucket"))
+	suite.Assert().Equal("continuous", form.Get("replicationType"))
+	suite.Assert().Equal("REGEXP_CONTAINS(META().id, '^user')", form.Get("filterExpression"))
+	suite.Assert().Equal("Auto", form.Get("compressionType"))
+
+	replications, err := mgr.GetAllReplications(nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]Replication{{
+		ID:                "abc/src/dst",
+		SourceBucket:      "src",
+		TargetClusterUUID: "abc",
+		TargetBucket:      "dst",
+		FilterExpression:  "REGEXP_CONTAINS(META().id, '^user')",
+		Status:            "running",
+	}}, replications)
+
+	err = mgr.PauseReplication("abc/src/dst", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("/settings/replications/abc%2Fsrc%2Fdst", lastReq.Path)
+	suite.Assert().Equal("pauseRequested=true", string(lastReq.Body))
+
+	err = mgr.DropReplication("abc/src/dst", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("DELETE", lastReq.Method)
+	suite.Assert().Equal("/controller/cancelXDCR/abc%2Fsrc%2Fdst", lastReq.Path)
+}
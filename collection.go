@@ -23,10 +23,20 @@ type Collection struct {
 	meter                *meterWrapper
 
 	useMutationTokens bool
+	defaultExpiry     time.Duration
 
 	getKvProvider func() (kvProvider, error)
 }
 
+// CollectionOptions are the options available when obtaining a Collection.
+// UNCOMMITTED: This API may change in the future.
+type CollectionOptions struct {
+	// DefaultExpiry is applied to Insert, Upsert and Replace operations which do not specify an Expiry.
+	// An Expiry set on an individual operation always takes precedence over the DefaultExpiry, and the
+	// DefaultExpiry is not applied to Replace operations which specify PreserveExpiry.
+	DefaultExpiry time.Duration
+}
+
 func newCollection(scope *Scope, collectionName string) *Collection {
 	return &Collection{
 		collectionName: collectionName,
@@ -49,6 +59,33 @@ func newCollection(scope *Scope, collectionName string) *Collection {
 	}
 }
 
+// expiryOrDefault returns the expiry to use for a mutation, falling back to the default expiry for the collection.
+func (c *Collection) expiryOrDefault(expiry time.Duration) time.Duration {
+	if expiry == 0 {
+		return c.defaultExpiry
+	}
+
+	return expiry
+}
+
+// mutationExpiry returns the encoded expiry to send for a mutation given either a relative expiry or an absolute
+// expiry time, optionally falling back to the default expiry for the collection if neither is set.
+func (c *Collection) mutationExpiry(expiry time.Duration, expiryTime time.Time, useDefault bool) (uint32, error) {
+	if expiryTime.IsZero() {
+		if useDefault {
+			expiry = c.expiryOrDefault(expiry)
+		}
+
+		return durationToExpiry(expiry), nil
+	}
+
+	if expiry > 0 {
+		return 0, makeInvalidArgumentsError("cannot use expiry and expiry time together")
+	}
+
+	return expiryTimeToExpiry(expiryTime)
+}
+
 func (c *Collection) name() string {
 	return c.collectionName
 }
@@ -86,3 +123,11 @@ func (c *Collection) startKvOpTrace(operationName string, tracectx RequestSpanCo
 func (c *Collection) bucketName() string {
 	return c.bucket.Name()
 }
+
+func (c *Collection) meterKeyspace() meterKeyspace {
+	return meterKeyspace{
+		bucket:     c.bucketName(),
+		scope:      c.ScopeName(),
+		collection: c.Name(),
+	}
+}
@@ -22,6 +22,19 @@ type AppendOptions struct {
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
 
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -43,9 +56,11 @@ func (c *Collection) binaryAppend(id string, val []byte, opts *AppendOptions) (m
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -56,34 +71,35 @@ func (c *Collection) binaryAppend(id string, val []byte, opts *AppendOptions) (m
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.Append(gocbcore.AdjoinOptions{
-		Key:                    opm.DocumentID(),
-		Value:                  val,
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		Cas:                    gocbcore.Cas(opts.Cas),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-	}, func(res *gocbcore.AdjoinResult, err error) {
-		if err != nil {
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
-
-		mutOut = &MutationResult{}
-		mutOut.cas = Cas(res.Cas)
-		mutOut.mt = opm.EnhanceMt(res.MutationToken)
-
-		opm.Resolve(mutOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.Append(gocbcore.AdjoinOptions{
+			Key:                    opm.DocumentID(),
+			Value:                  val,
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			Cas:                    gocbcore.Cas(opts.Cas),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+		}, func(res *gocbcore.AdjoinResult, err error) {
+			if err != nil {
+				errOut = opm.EnhanceErr(err)
+				opm.Reject()
+				return
+			}
+
+			mutOut = &MutationResult{}
+			mutOut.cas = Cas(res.Cas)
+			mutOut.serverDuration = opm.ServerDuration()
+			mutOut.retries = opm.Retries()
+			mutOut.mt = opm.EnhanceMt(res.MutationToken)
+
+			opm.Resolve(mutOut.mt)
+		})
+	})
 	return
 }
 
@@ -102,6 +118,19 @@ type PrependOptions struct {
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
 
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -123,9 +152,11 @@ func (c *Collection) binaryPrepend(id string, val []byte, opts *PrependOptions)
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -136,34 +167,35 @@ func (c *Collection) binaryPrepend(id string, val []byte, opts *PrependOptions)
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.Prepend(gocbcore.AdjoinOptions{
-		Key:                    opm.DocumentID(),
-		Value:                  val,
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		Cas:                    gocbcore.Cas(opts.Cas),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-	}, func(res *gocbcore.AdjoinResult, err error) {
-		if err != nil {
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
-
-		mutOut = &MutationResult{}
-		mutOut.cas = Cas(res.Cas)
-		mutOut.mt = opm.EnhanceMt(res.MutationToken)
-
-		opm.Resolve(mutOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.Prepend(gocbcore.AdjoinOptions{
+			Key:                    opm.DocumentID(),
+			Value:                  val,
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			Cas:                    gocbcore.Cas(opts.Cas),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+		}, func(res *gocbcore.AdjoinResult, err error) {
+			if err != nil {
+				errOut = opm.EnhanceErr(err)
+				opm.Reject()
+				return
+			}
+
+			mutOut = &MutationResult{}
+			mutOut.cas = Cas(res.Cas)
+			mutOut.serverDuration = opm.ServerDuration()
+			mutOut.retries = opm.Retries()
+			mutOut.mt = opm.EnhanceMt(res.MutationToken)
+
+			opm.Resolve(mutOut.mt)
+		})
+	})
 	return
 }
 
@@ -172,16 +204,31 @@ func (c *BinaryCollection) Prepend(id string, val []byte, opts *PrependOptions)
 	return c.collection.binaryPrepend(id, val, opts)
 }
 
+// counterNoInitial is the initial value which tells the server not to create a counter document.
+const counterNoInitial = uint64(0xFFFFFFFFFFFFFFFF)
+
+// counterInitial converts the Initial value of counter options into the value sent to the server.
+func counterInitial(initial int64) uint64 {
+	if initial < 0 {
+		return counterNoInitial
+	}
+
+	return uint64(initial)
+}
+
 // IncrementOptions are the options available to the Increment operation.
 type IncrementOptions struct {
 	Timeout time.Duration
 	// Expiry is the length of time that the document will be stored in Couchbase.
 	// A value of 0 will set the document to never expire.
 	Expiry time.Duration
-	// Initial, if non-negative, is the `initial` value to use for the document if it does not exist.
-	// If present, this is the value that will be returned by a successful operation.
+	// Initial, if non-negative, is the value that the document is created with if it does not exist, in which case
+	// Delta is not applied and Initial is the value returned. Set Initial to a negative value, e.g. -1, to not create
+	// the document, the operation then fails with ErrDocumentNotFound if the document does not exist.
+	// Note that the zero value creates the document with a value of 0.
 	Initial int64
-	// Delta is the value to use for incrementing/decrementing if Initial is not present.
+	// Delta is the amount to add to the counter when the document exists. Adding past the maximum uint64 value
+	// wraps around, e.g. incrementing 18446744073709551615 by 1 results in 0.
 	Delta           uint64
 	DurabilityLevel DurabilityLevel
 	PersistTo       uint
@@ -190,6 +237,19 @@ type IncrementOptions struct {
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
 
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -211,16 +271,13 @@ func (c *Collection) binaryIncrement(id string, opts *IncrementOptions) (countOu
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
-	realInitial := uint64(0xFFFFFFFFFFFFFFFF)
-	if opts.Initial >= 0 {
-		realInitial = uint64(opts.Initial)
-	}
-
 	if err := opm.CheckReadyForOp(); err != nil {
 		return nil, err
 	}
@@ -229,43 +286,45 @@ func (c *Collection) binaryIncrement(id string, opts *IncrementOptions) (countOu
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.Increment(gocbcore.CounterOptions{
-		Key:                    opm.DocumentID(),
-		Delta:                  opts.Delta,
-		Initial:                realInitial,
-		Expiry:                 durationToExpiry(opts.Expiry),
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		Cas:                    gocbcore.Cas(opts.Cas),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-	}, func(res *gocbcore.CounterResult, err error) {
-		if err != nil {
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
-
-		countOut = &CounterResult{}
-		countOut.cas = Cas(res.Cas)
-		countOut.mt = opm.EnhanceMt(res.MutationToken)
-		countOut.content = res.Value
-
-		opm.Resolve(countOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.Increment(gocbcore.CounterOptions{
+			Key:                    opm.DocumentID(),
+			Delta:                  opts.Delta,
+			Initial:                counterInitial(opts.Initial),
+			Expiry:                 durationToExpiry(opts.Expiry),
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			Cas:                    gocbcore.Cas(opts.Cas),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+		}, func(res *gocbcore.CounterResult, err error) {
+			if err != nil {
+				errOut = opm.EnhanceErr(err)
+				opm.Reject()
+				return
+			}
+
+			countOut = &CounterResult{}
+			countOut.cas = Cas(res.Cas)
+			countOut.serverDuration = opm.ServerDuration()
+			countOut.retries = opm.Retries()
+			countOut.mt = opm.EnhanceMt(res.MutationToken)
+			countOut.content = res.Value
+
+			opm.Resolve(countOut.mt)
+		})
+	})
 	return
 }
 
 // Increment performs an atomic addition for an integer document. Passing a
 // non-negative `initial` value will cause the document to be created if it did not
-// already exist.
+// already exist, otherwise ErrDocumentNotFound is returned for a missing document.
+// The counter is an unsigned 64 bit value which wraps around to 0 when incremented past its maximum.
 func (c *BinaryCollection) Increment(id string, opts *IncrementOptions) (countOut *CounterResult, errOut error) {
 	return c.collection.binaryIncrement(id, opts)
 }
@@ -276,10 +335,13 @@ type DecrementOptions struct {
 	// Expiry is the length of time that the document will be stored in Couchbase.
 	// A value of 0 will set the document to never expire.
 	Expiry time.Duration
-	// Initial, if non-negative, is the `initial` value to use for the document if it does not exist.
-	// If present, this is the value that will be returned by a successful operation.
+	// Initial, if non-negative, is the value that the document is created with if it does not exist, in which case
+	// Delta is not applied and Initial is the value returned. Set Initial to a negative value, e.g. -1, to not create
+	// the document, the operation then fails with ErrDocumentNotFound if the document does not exist.
+	// Note that the zero value creates the document with a value of 0.
 	Initial int64
-	// Delta is the value to use for incrementing/decrementing if Initial is not present.
+	// Delta is the amount to subtract from the counter when the document exists. Counters do not go below 0, so
+	// subtracting more than the current value results in 0 rather than wrapping around.
 	Delta           uint64
 	DurabilityLevel DurabilityLevel
 	PersistTo       uint
@@ -288,6 +350,19 @@ type DecrementOptions struct {
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
 
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -309,16 +384,13 @@ func (c *Collection) binaryDecrement(id string, opts *DecrementOptions) (countOu
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
-	realInitial := uint64(0xFFFFFFFFFFFFFFFF)
-	if opts.Initial >= 0 {
-		realInitial = uint64(opts.Initial)
-	}
-
 	if err := opm.CheckReadyForOp(); err != nil {
 		return nil, err
 	}
@@ -327,43 +399,45 @@ func (c *Collection) binaryDecrement(id string, opts *DecrementOptions) (countOu
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.Decrement(gocbcore.CounterOptions{
-		Key:                    opm.DocumentID(),
-		Delta:                  opts.Delta,
-		Initial:                realInitial,
-		Expiry:                 durationToExpiry(opts.Expiry),
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		Cas:                    gocbcore.Cas(opts.Cas),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-	}, func(res *gocbcore.CounterResult, err error) {
-		if err != nil {
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
-
-		countOut = &CounterResult{}
-		countOut.cas = Cas(res.Cas)
-		countOut.mt = opm.EnhanceMt(res.MutationToken)
-		countOut.content = res.Value
-
-		opm.Resolve(countOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.Decrement(gocbcore.CounterOptions{
+			Key:                    opm.DocumentID(),
+			Delta:                  opts.Delta,
+			Initial:                counterInitial(opts.Initial),
+			Expiry:                 durationToExpiry(opts.Expiry),
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			Cas:                    gocbcore.Cas(opts.Cas),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+		}, func(res *gocbcore.CounterResult, err error) {
+			if err != nil {
+				errOut = opm.EnhanceErr(err)
+				opm.Reject()
+				return
+			}
+
+			countOut = &CounterResult{}
+			countOut.cas = Cas(res.Cas)
+			countOut.serverDuration = opm.ServerDuration()
+			countOut.retries = opm.Retries()
+			countOut.mt = opm.EnhanceMt(res.MutationToken)
+			countOut.content = res.Value
+
+			opm.Resolve(countOut.mt)
+		})
+	})
 	return
 }
 
 // Decrement performs an atomic subtraction for an integer document. Passing a
 // non-negative `initial` value will cause the document to be created if it did not
-// already exist.
+// already exist, otherwise ErrDocumentNotFound is returned for a missing document.
+// The counter is an unsigned 64 bit value which stops at 0 when decremented past it.
 func (c *BinaryCollection) Decrement(id string, opts *DecrementOptions) (countOut *CounterResult, errOut error) {
 	return c.collection.binaryDecrement(id, opts)
 }
@@ -1,6 +1,12 @@
 package gocb
 
-import "github.com/couchbase/gocbcore/v10/memd"
+import (
+	"errors"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
+)
 
 func (suite *IntegrationTestSuite) TestBinaryAppend() {
 	suite.skipIfUnsupported(KeyValueFeature)
@@ -267,3 +273,106 @@ func (suite *IntegrationTestSuite) TestBinaryDecrement() {
 	suite.AssertKVMetrics(meterNameCBOperations, "decrement", 3, false)
 	suite.AssertKVMetrics(meterNameCBOperations, "get", 1, false)
 }
+
+func (suite *IntegrationTestSuite) TestBinaryCounterBounds() {
+	suite.skipIfUnsupported(KeyValueFeature)
+
+	colBinary := globalCollection.Binary()
+
+	_, err := colBinary.Increment("binaryCounterBoundsMissing", &IncrementOptions{
+		Delta:   1,
+		Initial: -1,
+	})
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected error to be document not found but was %v", err)
+	}
+
+	_, err = colBinary.Decrement("binaryCounterBoundsMissing", &DecrementOptions{
+		Delta:   1,
+		Initial: -1,
+	})
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected error to be document not found but was %v", err)
+	}
+
+	_, err = globalCollection.Upsert("binaryCounterBounds", "18446744073709551615", &UpsertOptions{
+		Transcoder: NewRawStringTranscoder(),
+	})
+	suite.Require().Nil(err, err)
+
+	res, err := colBinary.Increment("binaryCounterBounds", &IncrementOptions{
+		Delta:   1,
+		Initial: -1,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint64(0), res.Content())
+
+	res, err = colBinary.Decrement("binaryCounterBounds", &DecrementOptions{
+		Delta:   1,
+		Initial: -1,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint64(0), res.Content())
+}
+
+func (suite *UnitTestSuite) TestBinaryCounterInitialAndDurability() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var sentOpts []gocbcore.CounterOptions
+	provider := new(mockKvProvider)
+	for _, method := range []string{"Increment", "Decrement"} {
+		provider.
+			On(method, mock.AnythingOfType("gocbcore.CounterOptions"), mock.AnythingOfType("gocbcore.CounterCallback")).
+			Run(func(args mock.Arguments) {
+				opts := args.Get(0).(gocbcore.CounterOptions)
+				cb := args.Get(1).(gocbcore.CounterCallback)
+
+				sentOpts = append(sentOpts, opts)
+				if opts.Initial == 0xFFFFFFFFFFFFFFFF {
+					cb(nil, &gocbcore.KeyValueError{
+						InnerError: gocbcore.ErrDocumentNotFound,
+					})
+					return
+				}
+
+				cb(&gocbcore.CounterResult{
+					Value: opts.Initial,
+					Cas:   gocbcore.Cas(123),
+				}, nil)
+			}).
+			Return(pendingOp, nil)
+	}
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.Binary().Increment("someid", &IncrementOptions{
+		Delta:           1,
+		Initial:         5,
+		DurabilityLevel: DurabilityLevelMajority,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint64(5), res.Content())
+
+	_, err = col.Binary().Increment("someid", &IncrementOptions{
+		Delta:   1,
+		Initial: -1,
+	})
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected error to be document not found but was %v", err)
+	}
+
+	_, err = col.Binary().Decrement("someid", &DecrementOptions{
+		Delta:   1,
+		Initial: -1,
+	})
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected error to be document not found but was %v", err)
+	}
+
+	suite.Require().Len(sentOpts, 3)
+	suite.Assert().Equal(uint64(5), sentOpts[0].Initial)
+	suite.Assert().Equal(memd.DurabilityLevelMajority, sentOpts[0].DurabilityLevel)
+	suite.Assert().Equal(uint64(0xFFFFFFFFFFFFFFFF), sentOpts[1].Initial)
+	suite.Assert().Equal(uint64(0xFFFFFFFFFFFFFFFF), sentOpts[2].Initial)
+}
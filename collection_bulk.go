@@ -3,6 +3,8 @@ package gocb
 
 import (
 	"context"
+	"encoding/json"
+	"sync"
 	"time"
 
 	"github.com/couchbase/gocbcore/v10"
@@ -14,7 +16,10 @@ type bulkOp struct {
 }
 
 func (op *bulkOp) cancel() {
-	op.pendop.Cancel()
+	// The op may have failed to dispatch, in which case there is nothing to cancel.
+	if op.pendop != nil {
+		op.pendop.Cancel()
+	}
 }
 
 func (op *bulkOp) finish() {
@@ -40,13 +45,58 @@ type BulkOpOptions struct {
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// CollectionNotFoundRetries is the number of times that an individual operation will be retried when the
+	// collection cannot be found, before failing that operation with ErrCollectionNotFound. Defaults to 3.
+	// UNCOMMITTED: This API may change in the future.
+	CollectionNotFoundRetries uint32
+
+	// BatchSize is the maximum number of operations which are dispatched together, larger sets of operations are
+	// split into batches of this size and each batch is completed before the next is dispatched. Defaults to 1024.
+	// UNCOMMITTED: This API may change in the future.
+	BatchSize uint32
+
+	// BatchConcurrency is the number of batches which are executed at the same time, so at most
+	// BatchSize * BatchConcurrency operations are in flight at once. Defaults to 1, executing batches sequentially.
+	// UNCOMMITTED: This API may change in the future.
+	BatchConcurrency uint32
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
 }
 
+const (
+	defaultBulkCollectionNotFoundRetries = 3
+	defaultBulkBatchSize                 = 1024
+	defaultBulkBatchConcurrency          = 1
+)
+
+// bulkOpRetryStrategy limits the number of retries for operations against a collection which cannot be found.
+// Without this a collection being dropped part way through a batch would cause every remaining operation to retry
+// until the (potentially very long) batch timeout.
+type bulkOpRetryStrategy struct {
+	wrapped                   RetryStrategy
+	collectionNotFoundRetries uint32
+}
+
+func (rs *bulkOpRetryStrategy) RetryAfter(req RetryRequest, reason RetryReason) RetryAction {
+	if reason == KVCollectionOutdatedRetryReason && req.RetryAttempts() >= rs.collectionNotFoundRetries {
+		return &NoRetryRetryAction{}
+	}
+
+	return rs.wrapped.RetryAfter(req, reason)
+}
+
 // Do execute one or more `BulkOp` items in parallel.
+// Each operation succeeds or fails independently and any error is reported on that operation rather than from Do.
+// If the collection is dropped part way through the batch then each remaining operation will fail with
+// ErrCollectionNotFound once CollectionNotFoundRetries has been exhausted. A batch only ever targets a single
+// collection, operations against multiple collections should be submitted with a Do call per collection.
+// Large sets of operations are automatically split into batches of BatchSize, with BatchConcurrency batches being
+// executed at a time. The timeout applies to the Do call as a whole rather than to each batch.
+// If the Context is cancelled then in flight operations are cancelled and any operations which have not yet been
+// dispatched fail with ErrRequestCanceled.
 // UNCOMMITTED: This API may change in the future.
 func (c *Collection) Do(ops []BulkOp, opts *BulkOpOptions) error {
 	if opts == nil {
@@ -66,11 +116,21 @@ func (c *Collection) Do(ops []BulkOp, opts *BulkOpOptions) error {
 		timeout = c.timeoutsConfig.KVTimeout * time.Duration(len(ops))
 	}
 
-	retryWrapper := c.retryStrategyWrapper
+	retryStrategy := c.retryStrategyWrapper.wrapped
 	if opts.RetryStrategy != nil {
-		retryWrapper = newRetryStrategyWrapper(opts.RetryStrategy)
+		retryStrategy = opts.RetryStrategy
+	}
+
+	collectionNotFoundRetries := opts.CollectionNotFoundRetries
+	if collectionNotFoundRetries == 0 {
+		collectionNotFoundRetries = defaultBulkCollectionNotFoundRetries
 	}
 
+	retryWrapper := newRetryStrategyWrapper(&bulkOpRetryStrategy{
+		wrapped:                   retryStrategy,
+		collectionNotFoundRetries: collectionNotFoundRetries,
+	})
+
 	if opts.Transcoder == nil {
 		opts.Transcoder = c.transcoder
 	}
@@ -80,20 +140,93 @@ func (c *Collection) Do(ops []BulkOp, opts *BulkOpOptions) error {
 		return err
 	}
 
-	// Make the channel big enough to hold all our ops in case
-	//   we get delayed inside execute (don't want to block the
-	//   individual op handlers when they dispatch their signal).
-	signal := make(chan BulkOp, len(ops))
-	for _, item := range ops {
-		item.execute(span.Context(), c, agent, opts.Transcoder, signal, retryWrapper, time.Now().Add(timeout), c.startKvOpTrace)
+	batchSize := int(opts.BatchSize)
+	if batchSize == 0 {
+		batchSize = defaultBulkBatchSize
+	}
+
+	batchConcurrency := int(opts.BatchConcurrency)
+	if batchConcurrency == 0 {
+		batchConcurrency = defaultBulkBatchConcurrency
 	}
 
-	for range ops {
-		item := <-signal
-		// We're really just clearing the pendop from this thread,
-		//   since it already completed, no cancel actually occurs
-		item.finish()
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	executeBatch := func(batch []BulkOp) {
+		// If the context has already been cancelled then there's no point dispatching
+		//   any more of the ops, we just fail them all.
+		if ctx.Err() != nil {
+			for _, item := range batch {
+				item.markError(ErrRequestCanceled)
+			}
+			return
+		}
+
+		// Make the channel big enough to hold all our ops in case
+		//   we get delayed inside execute (don't want to block the
+		//   individual op handlers when they dispatch their signal).
+		signal := make(chan BulkOp, len(batch))
+		for _, item := range batch {
+			item.execute(span.Context(), c, agent, opts.Transcoder, signal, retryWrapper, deadline, c.startKvOpTrace)
+		}
+
+		done := ctx.Done()
+		for range batch {
+			select {
+			case item := <-signal:
+				// We're really just clearing the pendop from this thread,
+				//   since it already completed, no cancel actually occurs
+				item.finish()
+				continue
+			case <-done:
+				// Cancelling the ops causes each of them to signal, so we keep
+				//   waiting on the signal channel but stop watching the context.
+				for _, item := range batch {
+					item.cancel()
+				}
+				done = nil
+			}
+
+			item := <-signal
+			item.finish()
+		}
+	}
+
+	if len(ops) <= batchSize {
+		executeBatch(ops)
+		return nil
+	}
+
+	batchCh := make(chan []BulkOp)
+	var wg sync.WaitGroup
+	for i := 0; i < batchConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchCh {
+				executeBatch(batch)
+			}
+		}()
+	}
+
+	for start := 0; start < len(ops); start += batchSize {
+		end := start + batchSize
+		if end > len(ops) {
+			end = len(ops)
+		}
+		batchCh <- ops[start:end]
 	}
+	close(batchCh)
+	wg.Wait()
+
 	return nil
 }
 
@@ -117,7 +250,7 @@ func (item *GetOp) execute(tracectx RequestSpanContext, c *Collection, provider
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "get", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "get", "", c.meterKeyspace(), start)
 	}
 
 	op, err := provider.Get(gocbcore.GetOptions{
@@ -170,7 +303,7 @@ func (item *GetAndTouchOp) execute(tracectx RequestSpanContext, c *Collection, p
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "get_and_touch", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "get_and_touch", "", c.meterKeyspace(), start)
 	}
 
 	op, err := provider.GetAndTouch(gocbcore.GetAndTouchOptions{
@@ -224,7 +357,7 @@ func (item *TouchOp) execute(tracectx RequestSpanContext, c *Collection, provide
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "touch", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "touch", "", c.meterKeyspace(), start)
 	}
 
 	op, err := provider.Touch(gocbcore.TouchOptions{
@@ -246,8 +379,10 @@ func (item *TouchOp) execute(tracectx RequestSpanContext, c *Collection, provide
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
@@ -262,6 +397,69 @@ func (item *TouchOp) execute(tracectx RequestSpanContext, c *Collection, provide
 	}
 }
 
+// LookupInOp represents a type of `BulkOp` used for LookupIn operations. See BulkOp.
+// UNCOMMITTED: This API may change in the future.
+type LookupInOp struct {
+	bulkOp
+
+	ID     string
+	Ops    []LookupInSpec
+	Result *LookupInResult
+	Err    error
+}
+
+func (item *LookupInOp) markError(err error) {
+	item.Err = err
+}
+
+func (item *LookupInOp) execute(tracectx RequestSpanContext, c *Collection, provider kvProvider, transcoder Transcoder, signal chan BulkOp,
+	retryWrapper *retryStrategyWrapper, deadline time.Time, startSpanFunc func(string, RequestSpanContext, bool) RequestSpan) {
+	span := startSpanFunc("lookup_in", tracectx, false)
+	start := time.Now()
+	item.bulkOp.finishFn = func() {
+		span.End()
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "lookup_in", "", c.meterKeyspace(), start)
+	}
+
+	subdocs, err := lookupInSpecsToSubdocs(item.Ops)
+	if err != nil {
+		item.Err = makeInvalidArgumentsError(err.Error())
+		signal <- item
+		return
+	}
+
+	op, err := provider.LookupIn(gocbcore.LookupInOptions{
+		Key:            []byte(item.ID),
+		Ops:            subdocs,
+		CollectionName: c.name(),
+		ScopeName:      c.ScopeName(),
+		RetryStrategy:  retryWrapper,
+		TraceContext:   span.Context(),
+		Deadline:       deadline,
+	}, func(res *gocbcore.LookupInResult, err error) {
+		if err != nil && res == nil {
+			item.Err = maybeEnhanceCollKVErr(err, provider, c, item.ID)
+		}
+
+		if res != nil {
+			item.Result = &LookupInResult{}
+			item.Result.cas = Cas(res.Cas)
+			item.Result.contents = make([]lookupInPartial, len(subdocs))
+			for i, opRes := range res.Ops {
+				item.Result.contents[i].err = maybeEnhanceCollKVErr(opRes.Err, provider, c, item.ID)
+				item.Result.contents[i].data = json.RawMessage(opRes.Value)
+			}
+		}
+		signal <- item
+	})
+	if err != nil {
+		item.Err = err
+		signal <- item
+	} else {
+		item.bulkOp.pendop = op
+	}
+}
+
 // RemoveOp represents a type of `BulkOp` used for Remove operations. See BulkOp.
 // UNCOMMITTED: This API may change in the future.
 type RemoveOp struct {
@@ -283,7 +481,7 @@ func (item *RemoveOp) execute(tracectx RequestSpanContext, c *Collection, provid
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "remove", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "remove", "", c.meterKeyspace(), start)
 	}
 
 	op, err := provider.Delete(gocbcore.DeleteOptions{
@@ -305,8 +503,10 @@ func (item *RemoveOp) execute(tracectx RequestSpanContext, c *Collection, provid
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
@@ -344,7 +544,7 @@ func (item *UpsertOp) execute(tracectx RequestSpanContext, c *Collection, provid
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "upsert", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "upsert", "", c.meterKeyspace(), start)
 	}
 
 	etrace := c.startKvOpTrace("request_encoding", span.Context(), true)
@@ -378,8 +578,10 @@ func (item *UpsertOp) execute(tracectx RequestSpanContext, c *Collection, provid
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
@@ -416,7 +618,7 @@ func (item *InsertOp) execute(tracectx RequestSpanContext, c *Collection, provid
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "insert", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "insert", "", c.meterKeyspace(), start)
 	}
 
 	etrace := c.startKvOpTrace("request_encoding", span.Context(), true)
@@ -450,8 +652,10 @@ func (item *InsertOp) execute(tracectx RequestSpanContext, c *Collection, provid
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
@@ -489,7 +693,7 @@ func (item *ReplaceOp) execute(tracectx RequestSpanContext, c *Collection, provi
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "replace", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "replace", "", c.meterKeyspace(), start)
 	}
 
 	etrace := c.startKvOpTrace("request_encoding", span.Context(), true)
@@ -524,8 +728,10 @@ func (item *ReplaceOp) execute(tracectx RequestSpanContext, c *Collection, provi
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
@@ -561,7 +767,7 @@ func (item *AppendOp) execute(tracectx RequestSpanContext, c *Collection, provid
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "append", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "append", "", c.meterKeyspace(), start)
 	}
 
 	op, err := provider.Append(gocbcore.AdjoinOptions{
@@ -583,8 +789,10 @@ func (item *AppendOp) execute(tracectx RequestSpanContext, c *Collection, provid
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
@@ -620,7 +828,7 @@ func (item *PrependOp) execute(tracectx RequestSpanContext, c *Collection, provi
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "prepend", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "prepend", "", c.meterKeyspace(), start)
 	}
 
 	op, err := provider.Prepend(gocbcore.AdjoinOptions{
@@ -642,8 +850,10 @@ func (item *PrependOp) execute(tracectx RequestSpanContext, c *Collection, provi
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
@@ -682,7 +892,7 @@ func (item *IncrementOp) execute(tracectx RequestSpanContext, c *Collection, pro
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "increment", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "increment", "", c.meterKeyspace(), start)
 	}
 
 	realInitial := uint64(0xFFFFFFFFFFFFFFFF)
@@ -714,8 +924,10 @@ func (item *IncrementOp) execute(tracectx RequestSpanContext, c *Collection, pro
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
@@ -754,7 +966,7 @@ func (item *DecrementOp) execute(tracectx RequestSpanContext, c *Collection, pro
 	start := time.Now()
 	item.bulkOp.finishFn = func() {
 		span.End()
-		c.meter.ValueRecord(meterValueServiceKV, "decrement", start)
+		c.meter.KeyspaceValueRecord(meterValueServiceKV, "decrement", "", c.meterKeyspace(), start)
 	}
 
 	realInitial := uint64(0xFFFFFFFFFFFFFFFF)
@@ -786,8 +998,10 @@ func (item *DecrementOp) execute(tracectx RequestSpanContext, c *Collection, pro
 
 			if res.MutationToken.VbUUID != 0 {
 				mutTok := &MutationToken{
-					token:      res.MutationToken,
-					bucketName: c.bucketName(),
+					token:          res.MutationToken,
+					bucketName:     c.bucketName(),
+					scopeName:      c.ScopeName(),
+					collectionName: c.Name(),
 				}
 				item.Result.mt = mutTok
 			}
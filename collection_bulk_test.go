@@ -1,9 +1,15 @@
 package gocb
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/couchbase/gocbcore/v10/memd"
+	"sync"
 	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
 )
 
 func (suite *IntegrationTestSuite) TestUpsertGetBulk() {
@@ -277,3 +283,319 @@ func (suite *IntegrationTestSuite) TestRemoveOperationBulk() {
 	suite.AssertKVMetrics(meterNameCBOperations, "upsert", 20, false)
 	suite.AssertKVMetrics(meterNameCBOperations, "remove", 20, false)
 }
+
+func (suite *UnitTestSuite) TestBulkOpRetryStrategyCollectionNotFound() {
+	strategy := &bulkOpRetryStrategy{
+		wrapped:                   NewBestEffortRetryStrategy(mockBackoffCalculator),
+		collectionNotFoundRetries: 2,
+	}
+
+	action := strategy.RetryAfter(&mockRetryRequest{attempts: 1}, KVCollectionOutdatedRetryReason)
+	suite.Assert().Equal(1*time.Millisecond, action.Duration())
+
+	action = strategy.RetryAfter(&mockRetryRequest{attempts: 2}, KVCollectionOutdatedRetryReason)
+	suite.Assert().Zero(action.Duration())
+
+	action = strategy.RetryAfter(&mockRetryRequest{attempts: 5}, KVLockedRetryReason)
+	suite.Assert().Equal(5*time.Millisecond, action.Duration())
+}
+
+func (suite *UnitTestSuite) TestBulkGetCollectionNotFound() {
+	pendingOp := new(mockPendingOp)
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			if _, ok := opts.RetryStrategy.(*retryStrategyWrapper).wrapped.(*bulkOpRetryStrategy); !ok {
+				suite.T().Errorf("Expected retry strategy to be a bulk op retry strategy")
+			}
+
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(nil, gocbcore.ErrCollectionNotFound)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	ops := []BulkOp{
+		&GetOp{ID: "one"},
+		&GetOp{ID: "two"},
+	}
+	err := col.Do(ops, nil)
+	suite.Require().Nil(err, err)
+
+	for _, op := range ops {
+		getOp := op.(*GetOp)
+		if !errors.Is(getOp.Err, ErrCollectionNotFound) {
+			suite.T().Fatalf("Expected collection not found error but was %v", getOp.Err)
+		}
+		suite.Assert().Nil(getOp.Result)
+	}
+}
+
+func (suite *UnitTestSuite) TestBulkGetCollectionNotFoundRetryLimit() {
+	var lock sync.Mutex
+	var retries []int
+
+	pendingOp := new(mockPendingOp)
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+
+			// Retries the request for as long as the strategy allows, as the SDK would for a missing collection.
+			var opRetries int
+			for attempts := uint32(0); attempts < 100; attempts++ {
+				action := opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{attempts: attempts, idempotent: true},
+					gocbcore.KVCollectionOutdatedRetryReason)
+				if action.Duration() == 0 {
+					break
+				}
+				opRetries++
+			}
+
+			lock.Lock()
+			retries = append(retries, opRetries)
+			lock.Unlock()
+
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(nil, gocbcore.ErrCollectionNotFound)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+	strategy := NewBestEffortRetryStrategy(func(retryAttempts uint32) time.Duration {
+		return time.Millisecond
+	})
+
+	err := col.Do([]BulkOp{&GetOp{ID: "one"}, &GetOp{ID: "two"}}, &BulkOpOptions{
+		CollectionNotFoundRetries: 5,
+		RetryStrategy:             strategy,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]int{5, 5}, retries)
+
+	retries = nil
+	err = col.Do([]BulkOp{&GetOp{ID: "one"}}, &BulkOpOptions{
+		RetryStrategy: strategy,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]int{defaultBulkCollectionNotFoundRetries}, retries)
+}
+
+func (suite *UnitTestSuite) TestBulkGetBatched() {
+	pendingOp := new(mockPendingOp)
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: []byte(fmt.Sprintf("%q", opts.Key)),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	var ops []BulkOp
+	for i := 0; i < 5; i++ {
+		ops = append(ops, &GetOp{ID: fmt.Sprintf("key%d", i)})
+	}
+	err := col.Do(ops, &BulkOpOptions{
+		BatchSize:        2,
+		BatchConcurrency: 2,
+	})
+	suite.Require().Nil(err, err)
+
+	provider.AssertNumberOfCalls(suite.T(), "Get", 5)
+	for _, op := range ops {
+		getOp := op.(*GetOp)
+		suite.Require().Nil(getOp.Err, getOp.Err)
+
+		var val string
+		err := getOp.Result.Content(&val)
+		suite.Require().Nil(err, err)
+		suite.Assert().Equal(getOp.ID, val)
+	}
+}
+
+func (suite *UnitTestSuite) TestBulkGetContextDeadline() {
+	pendingOp := new(mockPendingOp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctxDeadline, _ := ctx.Deadline()
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			suite.Assert().Equal(ctxDeadline, opts.Deadline)
+
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"value"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	ops := []BulkOp{&GetOp{ID: "one"}}
+	err := col.Do(ops, &BulkOpOptions{
+		Timeout: time.Minute,
+		Context: ctx,
+	})
+	suite.Require().Nil(err, err)
+	suite.Require().Nil(ops[0].(*GetOp).Err)
+}
+
+func (suite *UnitTestSuite) TestBulkGetContextCancelled() {
+	provider := new(mockKvProvider)
+
+	col := suite.collection("mock", "", "", provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ops []BulkOp
+	for i := 0; i < 5; i++ {
+		ops = append(ops, &GetOp{ID: fmt.Sprintf("key%d", i)})
+	}
+	err := col.Do(ops, &BulkOpOptions{
+		BatchSize: 2,
+		Context:   ctx,
+	})
+	suite.Require().Nil(err, err)
+
+	provider.AssertNotCalled(suite.T(), "Get", mock.Anything, mock.Anything)
+	for _, op := range ops {
+		getOp := op.(*GetOp)
+		if !errors.Is(getOp.Err, ErrRequestCanceled) {
+			suite.T().Fatalf("Expected request canceled error but was %v", getOp.Err)
+		}
+	}
+}
+
+func (suite *UnitTestSuite) TestGetMulti() {
+	pendingOp := new(mockPendingOp)
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			cb := args.Get(1).(gocbcore.GetCallback)
+
+			if string(opts.Key) == "missing" {
+				cb(nil, &gocbcore.KeyValueError{
+					InnerError: gocbcore.ErrDocumentNotFound,
+				})
+				return
+			}
+
+			cb(&gocbcore.GetResult{
+				Value: []byte(fmt.Sprintf("%q", opts.Key)),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	results, err := col.GetMulti([]string{"one", "missing", "two", "one", ""}, &GetMultiOptions{
+		Transcoder: NewJSONTranscoder(),
+	})
+	suite.Require().Nil(err, err)
+	suite.Require().Len(results, 4)
+
+	provider.AssertNumberOfCalls(suite.T(), "Get", 3)
+
+	for _, id := range []string{"one", "two"} {
+		suite.Require().Nil(results[id].Err, results[id].Err)
+
+		var content string
+		err := results[id].Content(&content)
+		suite.Require().Nil(err, err)
+		suite.Assert().Equal(id, content)
+		suite.Assert().Equal(Cas(1), results[id].Result.Cas())
+	}
+
+	suite.Assert().Nil(results["missing"].Result)
+	if !errors.Is(results["missing"].Err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected document not found error but was %v", results["missing"].Err)
+	}
+	var content string
+	if !errors.Is(results["missing"].Content(&content), ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected content to return document not found error")
+	}
+
+	if !errors.Is(results[""].Err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", results[""].Err)
+	}
+}
+
+func (suite *UnitTestSuite) TestTouchMulti() {
+	pendingOp := new(mockPendingOp)
+
+	var expiries []uint32
+	provider := new(mockKvProvider)
+	provider.
+		On("Touch", mock.AnythingOfType("gocbcore.TouchOptions"), mock.AnythingOfType("gocbcore.TouchCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.TouchOptions)
+			cb := args.Get(1).(gocbcore.TouchCallback)
+			expiries = append(expiries, opts.Expiry)
+
+			if string(opts.Key) == "missing" {
+				cb(nil, &gocbcore.KeyValueError{
+					InnerError: gocbcore.ErrDocumentNotFound,
+				})
+				return
+			}
+
+			cb(&gocbcore.TouchResult{
+				Cas: gocbcore.Cas(1),
+				MutationToken: gocbcore.MutationToken{
+					VbID:   1,
+					VbUUID: 2,
+					SeqNo:  3,
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	results, err := col.TouchMulti([]string{"one", "missing", "two", "one", ""}, 10*time.Second, nil)
+	suite.Require().Nil(err, err)
+	suite.Require().Len(results, 4)
+
+	provider.AssertNumberOfCalls(suite.T(), "Touch", 3)
+	suite.Assert().Equal([]uint32{10, 10, 10}, expiries)
+
+	for _, id := range []string{"one", "two"} {
+		suite.Require().Nil(results[id].Err, results[id].Err)
+		suite.Assert().Equal(Cas(1), results[id].Result.Cas())
+		suite.Require().NotNil(results[id].Result.MutationToken())
+		suite.Assert().Equal(uint64(3), results[id].Result.MutationToken().SequenceNumber())
+	}
+
+	suite.Assert().Nil(results["missing"].Result)
+	if !errors.Is(results["missing"].Err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected document not found error but was %v", results["missing"].Err)
+	}
+
+	if !errors.Is(results[""].Err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", results[""].Err)
+	}
+}
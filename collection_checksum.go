@@ -0,0 +1,350 @@
+package gocb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+const defaultChecksumXattr = "checksum"
+
+// ChecksumAlgorithm is the algorithm used to calculate the checksum of a document body.
+// UNCOMMITTED: This API may change in the future.
+type ChecksumAlgorithm string
+
+const (
+	// ChecksumAlgorithmCRC32C calculates checksums using CRC-32 with the Castagnoli polynomial. This is cheap to
+	// calculate and detects accidental corruption.
+	ChecksumAlgorithmCRC32C ChecksumAlgorithm = "crc32c"
+
+	// ChecksumAlgorithmSHA256 calculates checksums using SHA-256.
+	ChecksumAlgorithmSHA256 ChecksumAlgorithm = "sha256"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+func (alg ChecksumAlgorithm) checksum(value []byte) (string, error) {
+	switch alg {
+	case ChecksumAlgorithmCRC32C:
+		return fmt.Sprintf("%08x", crc32.Checksum(value, crc32cTable)), nil
+	case ChecksumAlgorithmSHA256:
+		sum := sha256.Sum256(value)
+		return hex.EncodeToString(sum[:]), nil
+	}
+
+	return "", makeInvalidArgumentsError(fmt.Sprintf("unsupported checksum algorithm %s", alg))
+}
+
+type jsonChecksum struct {
+	Algorithm ChecksumAlgorithm `json:"alg"`
+	Value     string            `json:"value"`
+}
+
+// ChecksumMismatchError occurs when the body of a document read through a ChecksumCollection does not match the
+// checksum which was stored alongside it, indicating that the document has been corrupted or was modified without
+// going through a ChecksumCollection.
+// UNCOMMITTED: This API may change in the future.
+type ChecksumMismatchError struct {
+	DocumentID string
+	Algorithm  ChecksumAlgorithm
+
+	// Expected is the checksum stored with the document, or empty if the document had no checksum.
+	Expected string
+
+	// Actual is the checksum of the body of the document as read.
+	Actual string
+}
+
+// Error returns the string representation of this error.
+func (e *ChecksumMismatchError) Error() string {
+	if e.Expected == "" {
+		return fmt.Sprintf("checksum mismatch: document %s has no checksum", e.DocumentID)
+	}
+
+	return fmt.Sprintf("checksum mismatch: document %s has %s checksum %s but was stored with %s", e.DocumentID,
+		e.Algorithm, e.Actual, e.Expected)
+}
+
+// ChecksumCollectionOptions are the options available when creating a ChecksumCollection.
+// UNCOMMITTED: This API may change in the future.
+type ChecksumCollectionOptions struct {
+	// Algorithm is the algorithm used to calculate the checksums of written documents, defaults to
+	// ChecksumAlgorithmCRC32C. Documents are always verified using the algorithm which they were written with.
+	Algorithm ChecksumAlgorithm
+
+	// XattrName is the extended attribute in which the checksum is stored, defaults to "checksum".
+	XattrName string
+
+	// AllowMissing allows documents which have no checksum, such as those written before checksums were used, to be
+	// read without verification. By default reading these returns a ChecksumMismatchError.
+	AllowMissing bool
+}
+
+// ChecksumCollection wraps a Collection, storing a checksum of the body of each document that it writes in an
+// extended attribute and verifying that checksum whenever a document is read, so that silent corruption is detected.
+//
+// Documents are written using MutateIn, so the body and checksum are stored atomically, and so values must encode
+// to JSON. Documents written through the underlying Collection, or by other clients, will not have an up to date
+// checksum and so will fail verification.
+// UNCOMMITTED: This API may change in the future.
+type ChecksumCollection struct {
+	collection   *Collection
+	algorithm    ChecksumAlgorithm
+	xattrName    string
+	allowMissing bool
+}
+
+// Checksummed returns a ChecksumCollection which stores and verifies checksums of the documents in this collection.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) Checksummed(opts *ChecksumCollectionOptions) *ChecksumCollection {
+	if opts == nil {
+		opts = &ChecksumCollectionOptions{}
+	}
+
+	algorithm := opts.Algorithm
+	if algorithm == "" {
+		algorithm = ChecksumAlgorithmCRC32C
+	}
+
+	xattrName := opts.XattrName
+	if xattrName == "" {
+		xattrName = defaultChecksumXattr
+	}
+
+	return &ChecksumCollection{
+		collection:   c,
+		algorithm:    algorithm,
+		xattrName:    xattrName,
+		allowMissing: opts.AllowMissing,
+	}
+}
+
+// Collection returns the underlying Collection, which does not store or verify checksums.
+func (cc *ChecksumCollection) Collection() *Collection {
+	return cc.collection
+}
+
+// Insert creates a new document, along with the checksum of its body.
+func (cc *ChecksumCollection) Insert(id string, val interface{}, opts *InsertOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &InsertOptions{}
+	}
+
+	expiry := opts.Expiry
+	if opts.ExpiryTime.IsZero() {
+		expiry = cc.collection.expiryOrDefault(expiry)
+	}
+
+	return cc.store(id, val, opts.Transcoder, &MutateInOptions{
+		Expiry:          expiry,
+		ExpiryTime:      opts.ExpiryTime,
+		PersistTo:       opts.PersistTo,
+		ReplicateTo:     opts.ReplicateTo,
+		DurabilityLevel: opts.DurabilityLevel,
+		StoreSemantic:   StoreSemanticsInsert,
+		Timeout:         opts.Timeout,
+		RetryStrategy:   opts.RetryStrategy,
+		ParentSpan:      opts.ParentSpan,
+		OperationLabel:  opts.OperationLabel,
+		Context:         opts.Context,
+	})
+}
+
+// Upsert creates a new document or replaces an existing one, along with the checksum of its body.
+func (cc *ChecksumCollection) Upsert(id string, val interface{}, opts *UpsertOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &UpsertOptions{}
+	}
+
+	expiry := opts.Expiry
+	if !opts.PreserveExpiry && opts.ExpiryTime.IsZero() {
+		expiry = cc.collection.expiryOrDefault(expiry)
+	}
+
+	return cc.store(id, val, opts.Transcoder, &MutateInOptions{
+		Expiry:          expiry,
+		ExpiryTime:      opts.ExpiryTime,
+		PreserveExpiry:  opts.PreserveExpiry,
+		PersistTo:       opts.PersistTo,
+		ReplicateTo:     opts.ReplicateTo,
+		DurabilityLevel: opts.DurabilityLevel,
+		StoreSemantic:   StoreSemanticsUpsert,
+		Timeout:         opts.Timeout,
+		RetryStrategy:   opts.RetryStrategy,
+		ParentSpan:      opts.ParentSpan,
+		OperationLabel:  opts.OperationLabel,
+		Context:         opts.Context,
+	})
+}
+
+// Replace replaces an existing document, along with the checksum of its body. ReplaceOptions.ReturnDocument is
+// not supported.
+func (cc *ChecksumCollection) Replace(id string, val interface{}, opts *ReplaceOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &ReplaceOptions{}
+	}
+
+	if opts.ReturnDocument {
+		return nil, makeInvalidArgumentsError("return document is not supported with checksums")
+	}
+
+	expiry := opts.Expiry
+	if !opts.PreserveExpiry && opts.ExpiryTime.IsZero() {
+		expiry = cc.collection.expiryOrDefault(expiry)
+	}
+
+	return cc.store(id, val, opts.Transcoder, &MutateInOptions{
+		Expiry:          expiry,
+		ExpiryTime:      opts.ExpiryTime,
+		PreserveExpiry:  opts.PreserveExpiry,
+		Cas:             opts.Cas,
+		PersistTo:       opts.PersistTo,
+		ReplicateTo:     opts.ReplicateTo,
+		DurabilityLevel: opts.DurabilityLevel,
+		StoreSemantic:   StoreSemanticsReplace,
+		Timeout:         opts.Timeout,
+		RetryStrategy:   opts.RetryStrategy,
+		ParentSpan:      opts.ParentSpan,
+		OperationLabel:  opts.OperationLabel,
+		Context:         opts.Context,
+	})
+}
+
+func (cc *ChecksumCollection) store(id string, val interface{}, transcoder Transcoder,
+	opts *MutateInOptions) (*MutationResult, error) {
+	if transcoder == nil {
+		transcoder = cc.collection.transcoder
+	}
+
+	value, _, err := transcoder.Encode(val)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(value) {
+		return nil, makeInvalidArgumentsError("value must encode to JSON to be stored with a checksum")
+	}
+
+	// The checksum must cover exactly the bytes which are sent, which are compacted when the spec is encoded.
+	value, err = json.Marshal(json.RawMessage(value))
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := cc.algorithm.checksum(value)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cc.collection.MutateIn(id, []MutateInSpec{
+		UpsertSpec(cc.xattrName, jsonChecksum{Algorithm: cc.algorithm, Value: checksum}, &UpsertSpecOptions{
+			CreatePath: true,
+			IsXattr:    true,
+		}),
+		ReplaceSpec("", json.RawMessage(value), nil),
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.MutationResult, nil
+}
+
+// Get reads a document and verifies its body against its stored checksum, returning a *ChecksumMismatchError if
+// they do not match. GetOptions.Project, GetOptions.WithExpiry, GetOptions.ReplicaFallbackDelay,
+// GetOptions.ReplicaFallback and GetOptions.ReadPreference are not supported.
+func (cc *ChecksumCollection) Get(id string, opts *GetOptions) (*GetResult, error) {
+	if opts == nil {
+		opts = &GetOptions{}
+	}
+
+	if len(opts.Project) > 0 || opts.WithExpiry || opts.ReplicaFallbackDelay > 0 || opts.ReplicaFallback ||
+		opts.ReadPreference != ReadPreferenceNoPreference {
+		return nil, makeInvalidArgumentsError("project, expiry and replica reads are not supported with checksums")
+	}
+
+	transcoder := opts.Transcoder
+	if transcoder == nil {
+		transcoder = cc.collection.transcoder
+	}
+
+	res, err := cc.collection.LookupIn(id, []LookupInSpec{
+		GetSpec(cc.xattrName, &GetSpecOptions{IsXattr: true}),
+		GetSpec("", nil),
+	}, &LookupInOptions{
+		Timeout:        opts.Timeout,
+		RetryStrategy:  opts.RetryStrategy,
+		ParentSpan:     opts.ParentSpan,
+		OperationLabel: opts.OperationLabel,
+		Context:        opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var value json.RawMessage
+	err = res.ContentAt(1, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cc.verify(id, res, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetResult{
+		Result: Result{
+			cas:            res.Cas(),
+			serverDuration: res.serverDuration,
+		},
+		transcoder: transcoder,
+		flags:      gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression),
+		contents:   value,
+	}, nil
+}
+
+func (cc *ChecksumCollection) verify(id string, res *LookupInResult, value []byte) error {
+	var stored jsonChecksum
+	err := res.ContentAt(0, &stored)
+	if err != nil {
+		if !errors.Is(err, ErrPathNotFound) {
+			return err
+		}
+
+		if cc.allowMissing {
+			return nil
+		}
+
+		actual, err := cc.algorithm.checksum(value)
+		if err != nil {
+			return err
+		}
+
+		return &ChecksumMismatchError{
+			DocumentID: id,
+			Algorithm:  cc.algorithm,
+			Actual:     actual,
+		}
+	}
+
+	actual, err := stored.Algorithm.checksum(value)
+	if err != nil {
+		return err
+	}
+
+	if actual != stored.Value {
+		return &ChecksumMismatchError{
+			DocumentID: id,
+			Algorithm:  stored.Algorithm,
+			Expected:   stored.Value,
+			Actual:     actual,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package gocb
+
+import (
+	"errors"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestChecksumCollection() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var storedChecksum, storedBody []byte
+	provider := new(mockKvProvider)
+	provider.
+		On("MutateIn", mock.AnythingOfType("gocbcore.MutateInOptions"), mock.AnythingOfType("gocbcore.MutateInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.MutateInOptions)
+			cb := args.Get(1).(gocbcore.MutateInCallback)
+
+			suite.Assert().Equal(memd.SubdocDocFlagMkDoc, opts.Flags)
+			suite.Require().Len(opts.Ops, 2)
+			suite.Assert().Equal(memd.SubDocOpDictSet, opts.Ops[0].Op)
+			suite.Assert().Equal("integrity", opts.Ops[0].Path)
+			suite.Assert().Equal(memd.SubdocFlagXattrPath|memd.SubdocFlagMkDirP, opts.Ops[0].Flags)
+			suite.Assert().Equal(memd.SubDocOpSetDoc, opts.Ops[1].Op)
+
+			storedChecksum = opts.Ops[0].Value
+			storedBody = opts.Ops[1].Value
+
+			cb(&gocbcore.MutateInResult{
+				Cas: gocbcore.Cas(123),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			suite.Require().Len(opts.Ops, 2)
+			suite.Assert().Equal("integrity", opts.Ops[0].Path)
+			suite.Assert().Equal(memd.SubdocFlagXattrPath, opts.Ops[0].Flags)
+			suite.Assert().Equal(memd.SubDocOpGetDoc, opts.Ops[1].Op)
+
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{
+					{Value: storedChecksum},
+					{Value: storedBody},
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider).Checksummed(&ChecksumCollectionOptions{
+		XattrName: "integrity",
+	})
+
+	res, err := col.Upsert("someid", map[string]interface{}{"setting": "enabled", "limit": 10}, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(Cas(123), res.Cas())
+	suite.Assert().Equal(`{"alg":"crc32c","value":"`, string(storedChecksum[:25]))
+
+	getRes, err := col.Get("someid", nil)
+	suite.Require().Nil(err, err)
+
+	var content map[string]interface{}
+	suite.Require().Nil(getRes.Content(&content))
+	suite.Assert().Equal(map[string]interface{}{"setting": "enabled", "limit": float64(10)}, content)
+
+	// Corrupt the stored body.
+	storedBody = []byte(`{"limit":10,"setting":"disabled"}`)
+	_, err = col.Get("someid", nil)
+	var mismatchErr *ChecksumMismatchError
+	suite.Require().True(errors.As(err, &mismatchErr), "expected checksum mismatch but was %v", err)
+	suite.Assert().Equal("someid", mismatchErr.DocumentID)
+	suite.Assert().Equal(ChecksumAlgorithmCRC32C, mismatchErr.Algorithm)
+	suite.Assert().NotEqual(mismatchErr.Expected, mismatchErr.Actual)
+
+	_, err = col.Get("someid", &GetOptions{WithExpiry: true})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestChecksumAlgorithms() {
+	crc, err := ChecksumAlgorithmCRC32C.checksum([]byte("123456789"))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("e3069283", crc)
+
+	sha, err := ChecksumAlgorithmSHA256.checksum([]byte("abc"))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", sha)
+
+	_, err = ChecksumAlgorithm("md5").checksum([]byte("abc"))
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
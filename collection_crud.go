@@ -3,6 +3,7 @@ package gocb
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -46,6 +47,25 @@ type InsertOptions struct {
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
 
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// ExpiryTime is the absolute time at which the document will expire, as an alternative to Expiry. Times within
+	// 30 days are sent to the server as a relative expiry, and later times as a unix timestamp. A time in the past
+	// causes the document to expire immediately. It cannot be used together with Expiry.
+	// UNCOMMITTED: This API may change in the future.
+	ExpiryTime time.Time
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -63,6 +83,11 @@ func (c *Collection) Insert(id string, val interface{}, opts *InsertOptions) (mu
 		opts = &InsertOptions{}
 	}
 
+	expiry, err := c.mutationExpiry(opts.Expiry, opts.ExpiryTime, true)
+	if err != nil {
+		return nil, err
+	}
+
 	opm := c.newKvOpManager("insert", opts.ParentSpan)
 	defer opm.Finish(false)
 
@@ -70,9 +95,11 @@ func (c *Collection) Insert(id string, val interface{}, opts *InsertOptions) (mu
 	opm.SetTranscoder(opts.Transcoder)
 	opm.SetValue(val)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -83,35 +110,36 @@ func (c *Collection) Insert(id string, val interface{}, opts *InsertOptions) (mu
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.Add(gocbcore.AddOptions{
-		Key:                    opm.DocumentID(),
-		Value:                  opm.ValueBytes(),
-		Flags:                  opm.ValueFlags(),
-		Expiry:                 durationToExpiry(opts.Expiry),
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-	}, func(res *gocbcore.StoreResult, err error) {
-		if err != nil {
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.Add(gocbcore.AddOptions{
+			Key:                    opm.DocumentID(),
+			Value:                  opm.ValueBytes(),
+			Flags:                  opm.ValueFlags(),
+			Expiry:                 expiry,
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+		}, func(res *gocbcore.StoreResult, err error) {
+			if err != nil {
+				errOut = opm.EnhanceErr(err)
+				opm.Reject()
+				return
+			}
 
-		mutOut = &MutationResult{}
-		mutOut.cas = Cas(res.Cas)
-		mutOut.mt = opm.EnhanceMt(res.MutationToken)
+			mutOut = &MutationResult{}
+			mutOut.cas = Cas(res.Cas)
+			mutOut.serverDuration = opm.ServerDuration()
+			mutOut.retries = opm.Retries()
+			mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
-		opm.Resolve(mutOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+			opm.Resolve(mutOut.mt)
+		})
+	})
 	return
 }
 
@@ -125,7 +153,29 @@ type UpsertOptions struct {
 	Timeout         time.Duration
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
-	PreserveExpiry  bool
+	// PreserveExpiry causes the existing expiry of the document to be retained, rather than being cleared by the
+	// mutation. It cannot be used together with Expiry. This requires Couchbase Server 7.0 or above, older servers
+	// return ErrFeatureNotAvailable.
+	PreserveExpiry bool
+
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// ExpiryTime is the absolute time at which the document will expire, as an alternative to Expiry. Times within
+	// 30 days are sent to the server as a relative expiry, and later times as a unix timestamp. A time in the past
+	// causes the document to expire immediately. It cannot be used together with Expiry or PreserveExpiry.
+	// UNCOMMITTED: This API may change in the future.
+	ExpiryTime time.Time
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
 
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
@@ -144,6 +194,15 @@ func (c *Collection) Upsert(id string, val interface{}, opts *UpsertOptions) (mu
 		opts = &UpsertOptions{}
 	}
 
+	if (opts.Expiry > 0 || !opts.ExpiryTime.IsZero()) && opts.PreserveExpiry {
+		return nil, makeInvalidArgumentsError("cannot use expiry and preserve ttl together for upsert")
+	}
+
+	expiry, err := c.mutationExpiry(opts.Expiry, opts.ExpiryTime, true)
+	if err != nil {
+		return nil, err
+	}
+
 	opm := c.newKvOpManager("upsert", opts.ParentSpan)
 	defer opm.Finish(false)
 
@@ -151,9 +210,11 @@ func (c *Collection) Upsert(id string, val interface{}, opts *UpsertOptions) (mu
 	opm.SetTranscoder(opts.Transcoder)
 	opm.SetValue(val)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 	opm.SetPreserveExpiry(opts.PreserveExpiry)
 
@@ -165,36 +226,37 @@ func (c *Collection) Upsert(id string, val interface{}, opts *UpsertOptions) (mu
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.Set(gocbcore.SetOptions{
-		Key:                    opm.DocumentID(),
-		Value:                  opm.ValueBytes(),
-		Flags:                  opm.ValueFlags(),
-		Expiry:                 durationToExpiry(opts.Expiry),
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-		PreserveExpiry:         opm.PreserveExpiry(),
-	}, func(res *gocbcore.StoreResult, err error) {
-		if err != nil {
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.Set(gocbcore.SetOptions{
+			Key:                    opm.DocumentID(),
+			Value:                  opm.ValueBytes(),
+			Flags:                  opm.ValueFlags(),
+			Expiry:                 expiry,
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+			PreserveExpiry:         opm.PreserveExpiry(),
+		}, func(res *gocbcore.StoreResult, err error) {
+			if err != nil {
+				errOut = opm.EnhanceErr(err)
+				opm.Reject()
+				return
+			}
 
-		mutOut = &MutationResult{}
-		mutOut.cas = Cas(res.Cas)
-		mutOut.mt = opm.EnhanceMt(res.MutationToken)
+			mutOut = &MutationResult{}
+			mutOut.cas = Cas(res.Cas)
+			mutOut.serverDuration = opm.ServerDuration()
+			mutOut.retries = opm.Retries()
+			mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
-		opm.Resolve(mutOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+			opm.Resolve(mutOut.mt)
+		})
+	})
 	return
 }
 
@@ -209,7 +271,36 @@ type ReplaceOptions struct {
 	Timeout         time.Duration
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
-	PreserveExpiry  bool
+	// PreserveExpiry causes the existing expiry of the document to be retained, rather than being cleared by the
+	// mutation. It cannot be used together with Expiry. This requires Couchbase Server 7.0 or above, older servers
+	// return ErrFeatureNotAvailable.
+	PreserveExpiry bool
+
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// ExpiryTime is the absolute time at which the document will expire, as an alternative to Expiry. Times within
+	// 30 days are sent to the server as a relative expiry, and later times as a unix timestamp. A time in the past
+	// causes the document to expire immediately. It cannot be used together with Expiry or PreserveExpiry.
+	// UNCOMMITTED: This API may change in the future.
+	ExpiryTime time.Time
+
+	// ReturnDocument causes the value written by the operation, as encoded by the transcoder, to be made available
+	// via MutationResult.Content, saving a subsequent Get in order to return the updated document. The value is not
+	// read back from the server, it is the value passed to Replace, which is the whole body of the stored document.
+	// As this requires no additional data from the server it is supported by all server versions.
+	// UNCOMMITTED: This API may change in the future.
+	ReturnDocument bool
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
 
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
@@ -228,10 +319,15 @@ func (c *Collection) Replace(id string, val interface{}, opts *ReplaceOptions) (
 		opts = &ReplaceOptions{}
 	}
 
-	if opts.Expiry > 0 && opts.PreserveExpiry {
+	if (opts.Expiry > 0 || !opts.ExpiryTime.IsZero()) && opts.PreserveExpiry {
 		return nil, makeInvalidArgumentsError("cannot use expiry and preserve ttl together for replace")
 	}
 
+	expiry, err := c.mutationExpiry(opts.Expiry, opts.ExpiryTime, !opts.PreserveExpiry)
+	if err != nil {
+		return nil, err
+	}
+
 	opm := c.newKvOpManager("replace", opts.ParentSpan)
 	defer opm.Finish(false)
 
@@ -239,9 +335,11 @@ func (c *Collection) Replace(id string, val interface{}, opts *ReplaceOptions) (
 	opm.SetTranscoder(opts.Transcoder)
 	opm.SetValue(val)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 	opm.SetPreserveExpiry(opts.PreserveExpiry)
 
@@ -253,52 +351,100 @@ func (c *Collection) Replace(id string, val interface{}, opts *ReplaceOptions) (
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.Replace(gocbcore.ReplaceOptions{
-		Key:                    opm.DocumentID(),
-		Value:                  opm.ValueBytes(),
-		Flags:                  opm.ValueFlags(),
-		Expiry:                 durationToExpiry(opts.Expiry),
-		Cas:                    gocbcore.Cas(opts.Cas),
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-		PreserveExpiry:         opm.PreserveExpiry(),
-	}, func(res *gocbcore.StoreResult, err error) {
-		if err != nil {
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.Replace(gocbcore.ReplaceOptions{
+			Key:                    opm.DocumentID(),
+			Value:                  opm.ValueBytes(),
+			Flags:                  opm.ValueFlags(),
+			Expiry:                 expiry,
+			Cas:                    gocbcore.Cas(opts.Cas),
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+			PreserveExpiry:         opm.PreserveExpiry(),
+		}, func(res *gocbcore.StoreResult, err error) {
+			if err != nil {
+				errOut = opm.EnhanceErr(err)
+				opm.Reject()
+				return
+			}
 
-		mutOut = &MutationResult{}
-		mutOut.cas = Cas(res.Cas)
-		mutOut.mt = opm.EnhanceMt(res.MutationToken)
+			mutOut = &MutationResult{}
+			mutOut.cas = Cas(res.Cas)
+			mutOut.serverDuration = opm.ServerDuration()
+			mutOut.retries = opm.Retries()
+			mutOut.mt = opm.EnhanceMt(res.MutationToken)
+			if opts.ReturnDocument {
+				mutOut.contents = opm.ValueBytes()
+				mutOut.flags = opm.ValueFlags()
+				mutOut.transcoder = opm.Transcoder()
+			}
 
-		opm.Resolve(mutOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+			opm.Resolve(mutOut.mt)
+		})
+	})
 	return
 }
 
 // GetOptions are the options available to a Get operation.
 type GetOptions struct {
+	// WithExpiry fetches the expiry time of the document alongside its content, which is then available from
+	// GetResult.ExpiryTime and GetResult.HasExpiry. This is performed using a subdocument lookup, and can be used
+	// together with Project.
 	WithExpiry bool
 	// Project causes the Get operation to only fetch the fields indicated
 	// by the paths. The result of the operation is then treated as a
 	// standard GetResult.
+	// Paths may be nested, e.g. foo.bar.baz, and Content returns only the projected subtree. When more paths are
+	// requested than the server can look up at once, or a path refers to the document root, the full document is
+	// fetched and the projection is applied by the SDK.
 	Project       []string
 	Transcoder    Transcoder
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
+	// ReplicaFallbackDelay enables reading from the replicas when the preferred copy, the active unless
+	// ReadPreference is set, is slow to respond. If it has not responded within the delay then the remaining copies
+	// are also read, and the first copy received, including the preferred copy, is returned.
+	// See Get for how the replica options are applied.
+	// UNCOMMITTED: This API may change in the future.
+	ReplicaFallbackDelay time.Duration
+
+	// ReplicaFallback enables reading from the replicas when the node holding the active copy cannot be reached, such
+	// as during a failover. Rather than being retried until the timeout when its node is unavailable the document is
+	// instead read from the replicas. Only node and connection unavailability triggers the fallback, any other error,
+	// including ErrDocumentNotFound, is returned as normal. Not my vbucket responses are always retried by the SDK,
+	// without consulting the retry strategy, so they never trigger the fallback.
+	// See Get for how the replica options are applied.
+	// UNCOMMITTED: This API may change in the future.
+	ReplicaFallback bool
+
+	// ReadPreference prefers the copies of the document held within ClusterOptions.PreferredServerGroup, which may be
+	// replicas, in order to avoid reading across regions. The copies within the server group are read in turn, active
+	// first, each within an equal share of the remaining time so that a copy which does not respond cannot use up the
+	// timeout, and if none of them can be read then the behaviour depends on the ReadPreference. The server group of
+	// each node is fetched in the background; until it has been fetched reads using
+	// ReadPreferenceSelectedServerGroupOrAllAvailable are served by the active and reads using
+	// ReadPreferenceSelectedServerGroup fail with ErrServerGroupMetadataUnavailable, which is also returned if the
+	// cluster does not provide server group information.
+	// See Get for how the replica options are applied.
+	// UNCOMMITTED: This API may change in the future.
+	ReadPreference ReadPreference
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -313,11 +459,26 @@ type GetOptions struct {
 // Get performs a fetch operation against the collection. This can take 3 paths, a standard full document
 // fetch, a subdocument full document fetch also fetching document expiry (when WithExpiry is set),
 // or a subdocument fetch (when Project is used).
+// The replica options, ReplicaFallbackDelay, ReplicaFallback and ReadPreference, can be combined and cannot be used
+// with Project or WithExpiry. When any of them is set the preferred copy is read first, and the remaining copies are
+// only read when one of the enabled conditions is met, all within the same timeout. If no copy can be read then the
+// error from the preferred copy is returned. Documents read from a replica may be stale, see GetResult.IsReplica.
+// How the read was served is available from the Internal ReplicaFallback of the result, and reads which fall back
+// are also recorded in the operation metrics under the get_replica_fallback operation.
 func (c *Collection) Get(id string, opts *GetOptions) (docOut *GetResult, errOut error) {
 	if opts == nil {
 		opts = &GetOptions{}
 	}
 
+	if opts.ReplicaFallbackDelay > 0 || opts.ReplicaFallback || opts.ReadPreference != ReadPreferenceNoPreference {
+		if len(opts.Project) > 0 || opts.WithExpiry {
+			return nil, makeInvalidArgumentsError("ReplicaFallbackDelay, ReplicaFallback and ReadPreference cannot be " +
+				"used with Project or WithExpiry")
+		}
+
+		return c.getWithReplicas(id, opts)
+	}
+
 	if len(opts.Project) == 0 && !opts.WithExpiry {
 		return c.getDirect(id, opts)
 	}
@@ -325,6 +486,20 @@ func (c *Collection) Get(id string, opts *GetOptions) (docOut *GetResult, errOut
 	return c.getProjected(id, opts)
 }
 
+func (c *Collection) numReplicas() (int, error) {
+	agent, err := c.getKvProvider()
+	if err != nil {
+		return 0, err
+	}
+
+	snapshot, err := agent.ConfigSnapshot()
+	if err != nil {
+		return 0, err
+	}
+
+	return snapshot.NumReplicas()
+}
+
 func (c *Collection) getDirect(id string, opts *GetOptions) (docOut *GetResult, errOut error) {
 	if opts == nil {
 		opts = &GetOptions{}
@@ -338,6 +513,7 @@ func (c *Collection) getDirect(id string, opts *GetOptions) (docOut *GetResult,
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -365,7 +541,9 @@ func (c *Collection) getDirect(id string, opts *GetOptions) (docOut *GetResult,
 
 		doc := &GetResult{
 			Result: Result{
-				cas: Cas(res.Cas),
+				cas:            Cas(res.Cas),
+				serverDuration: opm.ServerDuration(),
+				retries:        opm.Retries(),
 			},
 			transcoder: opm.Transcoder(),
 			contents:   res.Value,
@@ -395,6 +573,7 @@ func (c *Collection) getProjected(id string, opts *GetOptions) (docOut *GetResul
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -411,10 +590,18 @@ func (c *Collection) getProjected(id string, opts *GetOptions) (docOut *GetResul
 		numProjects = 1 + numProjects
 	}
 
+	// The server limits lookups to 16 paths, including the expiry, and a lookup of the document root cannot be
+	// combined with other paths. In either case the full document is fetched and the projection is applied here.
 	projections := opts.Project
 	if numProjects > 16 {
 		projections = nil
 	}
+	for _, path := range opts.Project {
+		if path == "" {
+			projections = nil
+			break
+		}
+	}
 
 	var ops []LookupInSpec
 
@@ -454,7 +641,11 @@ func (c *Collection) getProjected(id string, opts *GetOptions) (docOut *GetResul
 			return nil, err
 		}
 
-		expiryTime := time.Unix(expires, 0)
+		// An expiry of 0 means that the document does not expire, which we represent as the zero time.
+		var expiryTime time.Time
+		if expires > 0 {
+			expiryTime = time.Unix(expires, 0)
+		}
 		doc.expiryTime = &expiryTime
 
 		ops = ops[1:]
@@ -476,6 +667,7 @@ func (c *Collection) getProjected(id string, opts *GetOptions) (docOut *GetResul
 
 	doc.transcoder = opm.Transcoder()
 	doc.cas = result.cas
+	doc.serverDuration = result.serverDuration
 	if projections == nil {
 		err = doc.fromFullProjection(ops, result, opts.Project)
 		if err != nil {
@@ -491,12 +683,60 @@ func (c *Collection) getProjected(id string, opts *GetOptions) (docOut *GetResul
 	return doc, nil
 }
 
+// GetIfModified fetches a document only if its CAS differs from the given CAS, returning ErrNotModified if it does
+// not. The CAS is first checked using a LookupIn, which does not transfer the document body, so this is useful for
+// efficiently polling large documents which rarely change. If the document changes again between the check and the
+// fetch then the latest version is returned. Project, WithExpiry and ReplicaFallbackDelay are applied to the fetch
+// as they are for Get.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) GetIfModified(id string, cas Cas, opts *GetOptions) (*GetResult, error) {
+	if opts == nil {
+		opts = &GetOptions{}
+	}
+
+	lookupOpts := &LookupInOptions{
+		Timeout:        opts.Timeout,
+		RetryStrategy:  opts.RetryStrategy,
+		ParentSpan:     opts.ParentSpan,
+		OperationLabel: opts.OperationLabel,
+		Context:        opts.Context,
+	}
+	lookupOpts.Internal.User = opts.Internal.User
+
+	res, err := c.LookupIn(id, []LookupInSpec{
+		GetSpec("$document.CAS", &GetSpecOptions{IsXattr: true}),
+	}, lookupOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Cas() == cas {
+		return nil, ErrNotModified
+	}
+
+	return c.Get(id, opts)
+}
+
 // ExistsOptions are the options available to the Exists command.
 type ExistsOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// WithDeleted causes a document which has been deleted, but whose tombstone still exists on the server, such as one
+	// which was soft-deleted whilst retaining its xattrs, to be reported by ExistsResult.IsDeleted with the CAS of the
+	// tombstone. Without it a deleted document is only reported as not existing.
+	// UNCOMMITTED: This API may change in the future.
+	WithDeleted bool
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -508,7 +748,8 @@ type ExistsOptions struct {
 	}
 }
 
-// Exists checks if a document exists for the given id.
+// Exists checks if a document exists for the given id. Only the metadata of the document is fetched, so the CAS of
+// the document can be found using ExistsResult.Cas without transferring its body, e.g. for a conditional replace.
 func (c *Collection) Exists(id string, opts *ExistsOptions) (docOut *ExistsResult, errOut error) {
 	if opts == nil {
 		opts = &ExistsOptions{}
@@ -521,6 +762,7 @@ func (c *Collection) Exists(id string, opts *ExistsOptions) (docOut *ExistsResul
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -543,7 +785,9 @@ func (c *Collection) Exists(id string, opts *ExistsOptions) (docOut *ExistsResul
 		if errors.Is(err, ErrDocumentNotFound) {
 			docOut = &ExistsResult{
 				Result: Result{
-					cas: Cas(0),
+					cas:            Cas(0),
+					serverDuration: opm.ServerDuration(),
+					retries:        opm.Retries(),
 				},
 				docExists: false,
 			}
@@ -560,9 +804,12 @@ func (c *Collection) Exists(id string, opts *ExistsOptions) (docOut *ExistsResul
 		if res != nil {
 			docOut = &ExistsResult{
 				Result: Result{
-					cas: Cas(res.Cas),
+					cas:            Cas(res.Cas),
+					serverDuration: opm.ServerDuration(),
+					retries:        opm.Retries(),
 				},
 				docExists: res.Deleted == 0,
+				isDeleted: opts.WithDeleted && res.Deleted != 0,
 			}
 		}
 
@@ -618,6 +865,8 @@ func (c *Collection) getOneReplica(
 
 			docOut = &GetReplicaResult{}
 			docOut.cas = Cas(res.Cas)
+			docOut.serverDuration = opm.ServerDuration()
+			docOut.retries = opm.Retries()
 			docOut.transcoder = opm.Transcoder()
 			docOut.contents = res.Value
 			docOut.flags = res.Flags
@@ -649,10 +898,13 @@ func (c *Collection) getOneReplica(
 
 		docOut = &GetReplicaResult{}
 		docOut.cas = Cas(res.Cas)
+		docOut.serverDuration = opm.ServerDuration()
+		docOut.retries = opm.Retries()
 		docOut.transcoder = opm.Transcoder()
 		docOut.contents = res.Value
 		docOut.flags = res.Flags
 		docOut.isReplica = true
+		docOut.source = GetResultSourceReplica
 
 		opm.Resolve(nil)
 	}))
@@ -669,8 +921,24 @@ type GetAllReplicaOptions struct {
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// MaxResponses is the number of successful responses, from the active and the replicas combined, after which the
+	// results are complete. Once this many responses have been received the outstanding requests are canceled and
+	// Next returns nil, rather than waiting for every replica to respond. A value of 0, or a value greater than the
+	// number of configured replicas plus the active, waits for every replica.
+	// UNCOMMITTED: This API may change in the future.
+	MaxResponses uint32
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
-	// also applies to global level timeouts.
+	// also applies to global level timeouts. Canceling the Context closes the results, canceling any outstanding
+	// replica requests.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
 
@@ -686,8 +954,10 @@ type GetAllReplicaOptions struct {
 type GetAllReplicasResult struct {
 	lock                sync.Mutex
 	totalRequests       uint32
+	maxResults          uint32
 	successResults      uint32
 	totalResults        uint32
+	finished            bool
 	resCh               chan *GetReplicaResult
 	cancelCh            chan struct{}
 	span                RequestSpan
@@ -698,12 +968,7 @@ type GetAllReplicasResult struct {
 
 func (r *GetAllReplicasResult) addFailed() {
 	r.lock.Lock()
-
-	r.totalResults++
-	if r.totalResults == r.totalRequests {
-		close(r.childReqsCompleteCh)
-	}
-
+	r.requestCompleted()
 	r.lock.Unlock()
 }
 
@@ -713,64 +978,70 @@ func (r *GetAllReplicasResult) addResult(res *GetReplicaResult) {
 	// closed.  IE: T1-Incr, T2-Incr, T2-Send, T2-Close, T1-Send[PANIC]
 	r.lock.Lock()
 
-	r.successResults++
-	resultCount := r.successResults
-
-	if resultCount <= r.totalRequests {
+	if !r.finished {
+		r.successResults++
 		r.resCh <- res
-	}
-
-	if resultCount == r.totalRequests {
-		close(r.cancelCh)
-		close(r.resCh)
 
-		r.span.End()
-		if r.valueRecorder != nil {
-			r.valueRecorder.RecordValue(uint64(time.Since(r.startedTime).Microseconds()))
+		if r.successResults == r.maxResults {
+			r.finish()
+			if r.valueRecorder != nil {
+				r.valueRecorder.RecordValue(uint64(time.Since(r.startedTime).Microseconds()))
+			}
 		}
 	}
 
+	r.requestCompleted()
+
+	r.lock.Unlock()
+}
+
+// requestCompleted records that a replica request has completed, finishing the results if it was the last one so
+// that Next doesn't wait for the timeout when some of the requests failed. The lock must be held.
+func (r *GetAllReplicasResult) requestCompleted() {
 	r.totalResults++
 	if r.totalResults == r.totalRequests {
+		if !r.finished {
+			r.finish()
+		}
+
 		close(r.childReqsCompleteCh)
+
+		// The span is only ended once every child request has completed, otherwise we would close our span before
+		// the child spans.
+		r.span.End()
 	}
+}
 
-	r.lock.Unlock()
+// finish closes the results and cancels any outstanding replica requests. The lock must be held.
+func (r *GetAllReplicasResult) finish() {
+	r.finished = true
+	close(r.cancelCh)
+	close(r.resCh)
 }
 
-// Next fetches the next replica result.
+// Next fetches the next replica result, returning nil once all of the results have been returned or the results
+// have been closed.
 func (r *GetAllReplicasResult) Next() *GetReplicaResult {
 	return <-r.resCh
 }
 
-// Close cancels all remaining get replica requests.
+// Close cancels all remaining get replica requests, waiting for them to complete so that no requests are left
+// running once Close has returned.
 func (r *GetAllReplicasResult) Close() error {
 	// See addResult discussion on lock usage.
 	r.lock.Lock()
 
-	// Note that this number increment must be high enough to be clear that
-	// the result set was closed, but low enough that it won't overflow if
-	// additional result objects are processed after the close.
-	prevResultCount := r.successResults
-	r.successResults += 100000
-
-	// We only have to close everything if the addResult method didn't already
-	// close them due to already having completed every request
-	var weClosed bool
-	if prevResultCount < r.totalRequests {
-		close(r.cancelCh)
-		close(r.resCh)
-
-		weClosed = true
+	// We only have to close everything if the results weren't already finished due to having completed every
+	// request, or having received enough results.
+	weClosed := !r.finished
+	if weClosed {
+		r.finish()
 	}
 
 	r.lock.Unlock()
 
-	if weClosed {
-		// We need to wait for the child requests spans to be completed.
-		<-r.childReqsCompleteCh
-		r.span.End()
-	}
+	// We need to wait for the child requests to be completed, they will already have been canceled.
+	<-r.childReqsCompleteCh
 
 	return nil
 }
@@ -793,6 +1064,9 @@ func (c *Collection) GetAllReplicas(id string, opts *GetAllReplicaOptions) (docO
 	}
 
 	span := c.startKvOpTrace("get_all_replicas", tracectx, false)
+	if opts.OperationLabel != "" {
+		span.SetAttribute(spanAttribOperationLabelKey, opts.OperationLabel)
+	}
 
 	// Timeout needs to be adjusted here, since we use it at the bottom of this
 	// function, but the remaining options are all passed downwards and get handled
@@ -827,14 +1101,21 @@ func (c *Collection) GetAllReplicas(id string, opts *GetAllReplicaOptions) (docO
 
 	var recorder ValueRecorder
 	if !opts.noMetrics {
-		recorder, err = c.meter.ValueRecorder(meterValueServiceKV, "get_all_replicas")
+		recorder, err = c.meter.KeyspaceValueRecorder(meterValueServiceKV, "get_all_replicas", opts.OperationLabel,
+			c.meterKeyspace())
 		if err != nil {
 			logDebugf("Failed to create value recorder: %v", err)
 		}
 	}
 
+	maxResults := uint32(numServers)
+	if opts.MaxResponses > 0 && opts.MaxResponses < maxResults {
+		maxResults = opts.MaxResponses
+	}
+
 	repRes := &GetAllReplicasResult{
 		totalRequests:       uint32(numServers),
+		maxResults:          maxResults,
 		resCh:               outCh,
 		cancelCh:            cancelCh,
 		span:                span,
@@ -849,7 +1130,7 @@ func (c *Collection) GetAllReplicas(id string, opts *GetAllReplicaOptions) (docO
 			// This timeout value will cause the getOneReplica operation to timeout after our deadline has expired,
 			// as the deadline has already begun. getOneReplica timing out before our deadline would cause inconsistent
 			// behaviour.
-			res, err := c.getOneReplica(context.Background(), span, id, replicaIdx, transcoder, retryStrategy, cancelCh,
+			res, err := c.getOneReplica(ctx, span, id, replicaIdx, transcoder, retryStrategy, cancelCh,
 				timeout, opts.Internal.User)
 			if err != nil {
 				repRes.addFailed()
@@ -860,10 +1141,14 @@ func (c *Collection) GetAllReplicas(id string, opts *GetAllReplicaOptions) (docO
 		}(replicaIdx)
 	}
 
-	// Start a timer to close it after the deadline
+	// Start a timer to close it after the deadline, the timer is stopped once the results are finished so that it
+	// doesn't outlive them.
 	go func() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+
 		select {
-		case <-time.After(time.Until(deadline)):
+		case <-timer.C:
 			// If we timeout, we should close the result
 			err := repRes.Close()
 			if err != nil {
@@ -889,6 +1174,14 @@ type GetAnyReplicaOptions struct {
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -907,7 +1200,8 @@ func (c *Collection) GetAnyReplica(id string, opts *GetAnyReplicaOptions) (docOu
 	}
 
 	start := time.Now()
-	defer c.meter.ValueRecord("kv", "get_any_replica", start)
+	defer c.meter.KeyspaceValueRecord(meterValueServiceKV, "get_any_replica", opts.OperationLabel, c.meterKeyspace(),
+		start)
 
 	var tracectx RequestSpanContext
 	if opts.ParentSpan != nil {
@@ -917,6 +1211,10 @@ func (c *Collection) GetAnyReplica(id string, opts *GetAnyReplicaOptions) (docOu
 	span := c.startKvOpTrace("get_any_replica", tracectx, false)
 	defer span.End()
 
+	if opts.OperationLabel != "" {
+		span.SetAttribute(spanAttribOperationLabelKey, opts.OperationLabel)
+	}
+
 	repRes, err := c.GetAllReplicas(id, &GetAllReplicaOptions{
 		Timeout:       opts.Timeout,
 		Transcoder:    opts.Transcoder,
@@ -951,6 +1249,146 @@ func (c *Collection) GetAnyReplica(id string, opts *GetAnyReplicaOptions) (docOu
 	return res, nil
 }
 
+// GetFreshestReplicaOptions are the options available to the GetFreshestReplica command.
+// UNCOMMITTED: This API may change in the future.
+type GetFreshestReplicaOptions struct {
+	Transcoder    Transcoder
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Window is how long to wait for further copies of the document once the first copy has been received, the
+	// freshest copy received within the window is returned. If not set then every copy is waited for, up to Timeout.
+	Window time.Duration
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+
+	// Internal: This should never be used and is not supported.
+	Internal struct {
+		User string
+	}
+}
+
+// GetFreshestReplica returns the freshest copy of a document that can be read from the active and replica servers.
+// A read is issued to every copy of the document in parallel and, of the copies received, the one with the highest
+// CAS is returned. This trades latency for freshness, e.g. during a failover when a replica may be ahead of a newly
+// promoted active. Use Window to bound how long to wait for the remaining copies once the first has been received.
+// If the Context is canceled before any copy is received then ErrRequestCanceled is returned, otherwise the freshest
+// copy received so far is returned.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) GetFreshestReplica(id string, opts *GetFreshestReplicaOptions) (docOut *GetReplicaResult, errOut error) {
+	if opts == nil {
+		opts = &GetFreshestReplicaOptions{}
+	}
+
+	start := time.Now()
+	defer c.meter.KeyspaceValueRecord(meterValueServiceKV, "get_freshest_replica", opts.OperationLabel, c.meterKeyspace(),
+		start)
+
+	var tracectx RequestSpanContext
+	if opts.ParentSpan != nil {
+		tracectx = opts.ParentSpan.Context()
+	}
+
+	span := c.startKvOpTrace("get_freshest_replica", tracectx, false)
+	defer span.End()
+
+	if opts.OperationLabel != "" {
+		span.SetAttribute(spanAttribOperationLabelKey, opts.OperationLabel)
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	repRes, err := c.GetAllReplicas(id, &GetAllReplicaOptions{
+		Timeout:       opts.Timeout,
+		Transcoder:    opts.Transcoder,
+		RetryStrategy: opts.RetryStrategy,
+		Internal:      opts.Internal,
+		ParentSpan:    span,
+		noMetrics:     true,
+		Context:       ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := repRes.Close()
+		if err != nil {
+			logDebugf("failed to close GetFreshestReplica response: %s", err)
+		}
+	}()
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	resCh := make(chan *GetReplicaResult)
+	go func() {
+		defer close(resCh)
+		for res := repRes.Next(); res != nil; res = repRes.Next() {
+			select {
+			case resCh <- res:
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+
+	var freshest *GetReplicaResult
+	var windowCh <-chan time.Time
+	for {
+		select {
+		case res, ok := <-resCh:
+			if !ok {
+				return c.freshestReplicaResult(ctx, freshest)
+			}
+
+			if freshest == nil || res.Cas() > freshest.Cas() {
+				freshest = res
+			}
+
+			if windowCh == nil && opts.Window > 0 {
+				windowCh = time.After(opts.Window)
+			}
+		case <-windowCh:
+			return freshest, nil
+		case <-ctx.Done():
+			return c.freshestReplicaResult(ctx, freshest)
+		}
+	}
+}
+
+func (c *Collection) freshestReplicaResult(ctx context.Context, freshest *GetReplicaResult) (*GetReplicaResult, error) {
+	if freshest != nil {
+		return freshest, nil
+	}
+
+	innerErr := ErrDocumentUnretrievable
+	if ctx.Err() != nil {
+		innerErr = ErrRequestCanceled
+	}
+
+	return nil, &KeyValueError{
+		InnerError:     innerErr,
+		BucketName:     c.bucketName(),
+		ScopeName:      c.scope,
+		CollectionName: c.collectionName,
+	}
+}
+
 // RemoveOptions are the options available to the Remove command.
 type RemoveOptions struct {
 	Cas             Cas
@@ -961,6 +1399,19 @@ type RemoveOptions struct {
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
 
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -983,9 +1434,11 @@ func (c *Collection) Remove(id string, opts *RemoveOptions) (mutOut *MutationRes
 
 	opm.SetDocumentID(id)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -996,33 +1449,34 @@ func (c *Collection) Remove(id string, opts *RemoveOptions) (mutOut *MutationRes
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.Delete(gocbcore.DeleteOptions{
-		Key:                    opm.DocumentID(),
-		Cas:                    gocbcore.Cas(opts.Cas),
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-	}, func(res *gocbcore.DeleteResult, err error) {
-		if err != nil {
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.Delete(gocbcore.DeleteOptions{
+			Key:                    opm.DocumentID(),
+			Cas:                    gocbcore.Cas(opts.Cas),
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+		}, func(res *gocbcore.DeleteResult, err error) {
+			if err != nil {
+				errOut = opm.EnhanceErr(err)
+				opm.Reject()
+				return
+			}
 
-		mutOut = &MutationResult{}
-		mutOut.cas = Cas(res.Cas)
-		mutOut.mt = opm.EnhanceMt(res.MutationToken)
+			mutOut = &MutationResult{}
+			mutOut.cas = Cas(res.Cas)
+			mutOut.serverDuration = opm.ServerDuration()
+			mutOut.retries = opm.Retries()
+			mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
-		opm.Resolve(mutOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+			opm.Resolve(mutOut.mt)
+		})
+	})
 	return
 }
 
@@ -1033,6 +1487,14 @@ type GetAndTouchOptions struct {
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -1058,6 +1520,7 @@ func (c *Collection) GetAndTouch(id string, expiry time.Duration, opts *GetAndTo
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -1087,7 +1550,9 @@ func (c *Collection) GetAndTouch(id string, expiry time.Duration, opts *GetAndTo
 		if res != nil {
 			doc := &GetResult{
 				Result: Result{
-					cas: Cas(res.Cas),
+					cas:            Cas(res.Cas),
+					serverDuration: opm.ServerDuration(),
+					retries:        opm.Retries(),
 				},
 				transcoder: opm.Transcoder(),
 				contents:   res.Value,
@@ -1112,6 +1577,19 @@ type GetAndLockOptions struct {
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// StrictLockTime causes GetAndLock to fail with ErrInvalidArgument when the lockTime is longer than the maximum
+	// lock time supported by the server, rather than clamping it to the maximum.
+	// UNCOMMITTED: This API may change in the future.
+	StrictLockTime bool
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -1123,14 +1601,31 @@ type GetAndLockOptions struct {
 	}
 }
 
+// maxLockTime is the longest time which the server will lock a document for.
+const maxLockTime = 30 * time.Second
+
 // GetAndLock locks a document for a period of time, providing exclusive RW access to it.
-// A lockTime value of over 30 seconds will be treated as 30 seconds. The resolution used to send this value to
-// the server is seconds and is calculated using uint32(lockTime/time.Second).
+// The server does not lock documents for more than 30 seconds, so a longer lockTime is clamped to 30 seconds and a
+// warning is logged, or ErrInvalidArgument is returned if GetAndLockOptions.StrictLockTime is set. The resolution
+// used to send this value to the server is seconds and is calculated using uint32(lockTime/time.Second).
 func (c *Collection) GetAndLock(id string, lockTime time.Duration, opts *GetAndLockOptions) (docOut *GetResult, errOut error) {
 	if opts == nil {
 		opts = &GetAndLockOptions{}
 	}
 
+	if lockTime < 0 {
+		return nil, makeInvalidArgumentsError("lockTime cannot be negative")
+	}
+	if lockTime > maxLockTime {
+		if opts.StrictLockTime {
+			return nil, makeInvalidArgumentsError(fmt.Sprintf("lockTime cannot be longer than %s", maxLockTime))
+		}
+
+		logWarnf("GetAndLock lockTime of %s for %s is longer than the maximum of %s, using %s", lockTime, id,
+			maxLockTime, maxLockTime)
+		lockTime = maxLockTime
+	}
+
 	opm := c.newKvOpManager("get_and_lock", opts.ParentSpan)
 	defer opm.Finish(false)
 
@@ -1139,6 +1634,7 @@ func (c *Collection) GetAndLock(id string, lockTime time.Duration, opts *GetAndL
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -1168,7 +1664,9 @@ func (c *Collection) GetAndLock(id string, lockTime time.Duration, opts *GetAndL
 		if res != nil {
 			doc := &GetResult{
 				Result: Result{
-					cas: Cas(res.Cas),
+					cas:            Cas(res.Cas),
+					serverDuration: opm.ServerDuration(),
+					retries:        opm.Retries(),
 				},
 				transcoder: opm.Transcoder(),
 				contents:   res.Value,
@@ -1192,6 +1690,14 @@ type UnlockOptions struct {
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -1204,6 +1710,9 @@ type UnlockOptions struct {
 }
 
 // Unlock unlocks a document which was locked with GetAndLock.
+// If cas does not match the cas that the document was locked with then a *CasMismatchError is returned, which
+// satisfies errors.Is(err, ErrCasMismatch). Servers before 7.0 instead respond with a temporary failure, which is
+// retried until the operation times out.
 func (c *Collection) Unlock(id string, cas Cas, opts *UnlockOptions) (errOut error) {
 	if opts == nil {
 		opts = &UnlockOptions{}
@@ -1216,6 +1725,7 @@ func (c *Collection) Unlock(id string, cas Cas, opts *UnlockOptions) (errOut err
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -1231,13 +1741,20 @@ func (c *Collection) Unlock(id string, cas Cas, opts *UnlockOptions) (errOut err
 		Cas:            gocbcore.Cas(cas),
 		CollectionName: opm.CollectionName(),
 		ScopeName:      opm.ScopeName(),
-		RetryStrategy:  opm.RetryStrategy(),
+		RetryStrategy:  &unlockRetryStrategy{wrapped: opm.RetryStrategy()},
 		TraceContext:   opm.TraceSpanContext(),
 		Deadline:       opm.Deadline(),
 		User:           opm.Impersonate(),
 	}, func(res *gocbcore.UnlockResult, err error) {
 		if err != nil {
 			errOut = opm.EnhanceErr(err)
+			if isUnlockCasMismatch(err) {
+				errOut = &CasMismatchError{
+					DocumentID: id,
+					Cas:        cas,
+					InnerError: errOut,
+				}
+			}
 			opm.Reject()
 			return
 		}
@@ -1251,12 +1768,55 @@ func (c *Collection) Unlock(id string, cas Cas, opts *UnlockOptions) (errOut err
 	return
 }
 
+// unlockRetryStrategy stops an unlock from being retried when the server responds that the document is locked, which
+// servers from 7.0 do when unlocking with a cas that does not match the lock. Retrying cannot succeed until the lock
+// expires, at which point the cas no longer unlocks it. Any other reason is passed to the wrapped strategy.
+type unlockRetryStrategy struct {
+	wrapped gocbcore.RetryStrategy
+}
+
+func (rs *unlockRetryStrategy) RetryAfter(req gocbcore.RetryRequest, reason gocbcore.RetryReason) gocbcore.RetryAction {
+	if reason == gocbcore.KVLockedRetryReason {
+		return &gocbcore.NoRetryRetryAction{}
+	}
+
+	return rs.wrapped.RetryAfter(req, reason)
+}
+
+// isUnlockCasMismatch returns whether err is one of the errors that servers return when unlocking with a cas that
+// does not match the lock, either a cas mismatch or, from server 7.0, a locked response. Older servers respond with a
+// temporary failure, which cannot be told apart from a genuine temporary failure and so is retried as normal.
+func isUnlockCasMismatch(err error) bool {
+	return errors.Is(err, ErrCasMismatch) || errors.Is(err, ErrDocumentLocked)
+}
+
 // TouchOptions are the options available to the Touch operation.
 type TouchOptions struct {
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// PersistTo and ReplicateTo wait for the touch to be persisted to, or replicated to, the given number of nodes
+	// before returning. Mutation tokens must be enabled.
+	// UNCOMMITTED: This API may change in the future.
+	PersistTo   uint
+	ReplicateTo uint
+
+	// DurabilityLevel waits for the touch to meet the given durability level before returning. The server does not
+	// support synchronous durability for touch, so the level is met by observing the active and replica copies of the
+	// document in the same way as PersistTo and ReplicateTo, and cannot be used alongside them.
+	// Mutation tokens must be enabled.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityLevel DurabilityLevel
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
@@ -1278,9 +1838,11 @@ func (c *Collection) Touch(id string, expiry time.Duration, opts *TouchOptions)
 	defer opm.Finish(false)
 
 	opm.SetDocumentID(id)
+	opm.SetObserveDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
@@ -1309,6 +1871,8 @@ func (c *Collection) Touch(id string, expiry time.Duration, opts *TouchOptions)
 
 		mutOut = &MutationResult{}
 		mutOut.cas = Cas(res.Cas)
+		mutOut.serverDuration = opm.ServerDuration()
+		mutOut.retries = opm.Retries()
 		mutOut.mt = opm.EnhanceMt(res.MutationToken)
 
 		opm.Resolve(mutOut.mt)
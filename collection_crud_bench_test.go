@@ -194,3 +194,58 @@ func BenchmarkTouch(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkUpsertMixedSizesKVConnections compares throughput for a workload mixing small and large values, using a
+// single KV connection per node and using several.
+func BenchmarkUpsertMixedSizesKVConnections(b *testing.B) {
+	smallDoc := benchDoc{Data: make([]byte, 256)}
+	largeDoc := benchDoc{Data: make([]byte, 1024*1024)}
+
+	for _, numConns := range []int{1, 4} {
+		b.Run(fmt.Sprintf("connections-%d", numConns), func(b *testing.B) {
+			cluster, err := Connect(globalConfig.Server, ClusterOptions{
+				Authenticator: PasswordAuthenticator{
+					Username: globalConfig.User,
+					Password: globalConfig.Password,
+				},
+				IoConfig: IoConfig{
+					NumKVConnections: numConns,
+				},
+			})
+			if err != nil {
+				b.Fatalf("failed to connect: %v", err)
+			}
+			defer cluster.Close(nil)
+
+			bucket := cluster.Bucket(globalConfig.Bucket)
+			err = bucket.WaitUntilReady(5*time.Second, &WaitUntilReadyOptions{
+				ServiceTypes: []ServiceType{ServiceTypeKeyValue},
+			})
+			if err != nil {
+				b.Fatalf("Wait until ready failed: %v", err)
+			}
+			collection := bucket.Scope(globalConfig.Scope).Collection(globalConfig.Collection)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				var i uint32
+				for pb.Next() {
+					keyNum := atomic.AddUint32(&i, 1)
+
+					// One in every ten upserts is of a large value, which would otherwise hold up the small values
+					// queued behind it on a single connection.
+					doc := smallDoc
+					if keyNum%10 == 0 {
+						doc = largeDoc
+					}
+
+					_, err := collection.Upsert(fmt.Sprintf("upsert-mixed-%d", keyNum%100), doc, nil)
+					if err != nil {
+						b.Fatalf("failed to upsert %d: %v", keyNum, err)
+					}
+				}
+			})
+		})
+	}
+}
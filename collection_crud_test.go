@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/couchbase/gocbcore/v10/memd"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -200,6 +202,18 @@ func (suite *IntegrationTestSuite) TestPreserveExpiry() {
 	suite.Assert().InDelta(start.Add(25*time.Second).Unix(), replacedDoc.ExpiryTime().Unix(), 5)
 }
 
+func (suite *IntegrationTestSuite) TestPreserveExpiryFeatureNotAvailable() {
+	suite.skipIfUnsupported(KeyValueFeature)
+	if globalCluster.isMock() || globalCluster.SupportsFeature(PreserveExpiryFeature) {
+		suite.T().Skip("Skipping test as server supports preserve expiry")
+	}
+
+	_, err := globalCollection.Upsert("preservettlunsupported", "test", &UpsertOptions{PreserveExpiry: true})
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected feature not available error but was %v", err)
+	}
+}
+
 func (suite *IntegrationTestSuite) TestInsertGetWithExpiry() {
 	suite.skipIfUnsupported(KeyValueFeature)
 	suite.skipIfUnsupported(XattrFeature)
@@ -2328,6 +2342,401 @@ func (suite *UnitTestSuite) TestGetErrorProperties() {
 	suite.Assert().Nil(res)
 }
 
+func (suite *UnitTestSuite) TestCollectionDefaultExpiry() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var expectedExpiry uint32
+	provider := new(mockKvProvider)
+	provider.
+		On("Set", mock.AnythingOfType("gocbcore.SetOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.SetOptions)
+			cb := args.Get(1).(gocbcore.StoreCallback)
+
+			suite.Assert().Equal(expectedExpiry, opts.Expiry)
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(123),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("Replace", mock.AnythingOfType("gocbcore.ReplaceOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.ReplaceOptions)
+			cb := args.Get(1).(gocbcore.StoreCallback)
+
+			suite.Assert().Equal(expectedExpiry, opts.Expiry)
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(123),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+	col.defaultExpiry = 10 * time.Second
+
+	expectedExpiry = 10
+	_, err := col.Upsert("someid", "someval", nil)
+	suite.Require().Nil(err, err)
+
+	_, err = col.Replace("someid", "someval", nil)
+	suite.Require().Nil(err, err)
+
+	expectedExpiry = 5
+	_, err = col.Upsert("someid", "someval", &UpsertOptions{
+		Expiry: 5 * time.Second,
+	})
+	suite.Require().Nil(err, err)
+
+	expectedExpiry = 0
+	_, err = col.Replace("someid", "someval", &ReplaceOptions{
+		PreserveExpiry: true,
+	})
+	suite.Require().Nil(err, err)
+}
+
+func (suite *UnitTestSuite) TestPreserveExpiryWithExpiry() {
+	col := suite.collection("mock", "", "", new(mockKvProvider))
+
+	assertInvalid := func(err error) {
+		if !errors.Is(err, ErrInvalidArgument) {
+			suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+		}
+	}
+
+	_, err := col.Upsert("someid", "someval", &UpsertOptions{
+		Expiry:         5 * time.Second,
+		PreserveExpiry: true,
+	})
+	assertInvalid(err)
+
+	_, err = col.Replace("someid", "someval", &ReplaceOptions{
+		Expiry:         5 * time.Second,
+		PreserveExpiry: true,
+	})
+	assertInvalid(err)
+
+	for _, semantic := range []StoreSemantics{StoreSemanticsReplace, StoreSemanticsUpsert, StoreSemanticsInsert} {
+		_, err = col.MutateIn("someid", []MutateInSpec{
+			UpsertSpec("test", "test", nil),
+		}, &MutateInOptions{
+			Expiry:         5 * time.Second,
+			PreserveExpiry: true,
+			StoreSemantic:  semantic,
+		})
+		assertInvalid(err)
+	}
+}
+
+func (suite *UnitTestSuite) TestReplaceReturnDocument() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Replace", mock.AnythingOfType("gocbcore.ReplaceOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.StoreCallback)
+
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(123),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	doc := map[string]interface{}{"name": "barry"}
+	res, err := col.Replace("someid", doc, &ReplaceOptions{
+		ReturnDocument: true,
+	})
+	suite.Require().Nil(err, err)
+
+	var content map[string]interface{}
+	err = res.Content(&content)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(doc, content)
+
+	res, err = col.Replace("someid", doc, nil)
+	suite.Require().Nil(err, err)
+
+	err = res.Content(&content)
+	if !errors.Is(err, ErrNoResult) {
+		suite.T().Fatalf("Expected error to be no result but was %v", err)
+	}
+}
+
+// TestCompressedValueInterop verifies that values which are stored compressed, as other SDKs may do, are read back
+// correctly regardless of whether compression is enabled for the reading client.
+func (suite *IntegrationTestSuite) TestCompressedValueInterop() {
+	suite.skipIfUnsupported(KeyValueFeature)
+	if globalCluster.isMock() {
+		suite.T().Skip("Skipping test, mock does not support snappy compression")
+	}
+
+	var clusters []*Cluster
+	defer func() {
+		for _, c := range clusters {
+			c.Close(nil)
+		}
+	}()
+
+	connect := func(compression bool) *Collection {
+		connStr := globalConfig.Server
+		if strings.Contains(connStr, "?") {
+			connStr += "&"
+		} else {
+			connStr += "?"
+		}
+		connStr += "compression=" + strconv.FormatBool(compression) + "&compression_min_size=32"
+
+		c, err := Connect(connStr, ClusterOptions{Authenticator: PasswordAuthenticator{
+			Username: globalConfig.User,
+			Password: globalConfig.Password,
+		}})
+		suite.Require().Nil(err, err)
+		clusters = append(clusters, c)
+
+		b := c.Bucket(globalBucket.Name())
+		err = b.WaitUntilReady(7*time.Second, nil)
+		suite.Require().Nil(err, err)
+
+		return b.Scope(globalScope.Name()).Collection(globalCollection.Name())
+	}
+
+	compressed := connect(true)
+	uncompressed := connect(false)
+
+	doc := map[string]string{
+		"description": strings.Repeat("a highly compressible value ", 100),
+	}
+
+	for _, writer := range []*Collection{compressed, uncompressed} {
+		_, err := writer.Upsert("compressedValueInterop", doc, nil)
+		suite.Require().Nil(err, err)
+
+		for _, reader := range []*Collection{compressed, uncompressed} {
+			res, err := reader.Get("compressedValueInterop", nil)
+			suite.Require().Nil(err, err)
+
+			var actual map[string]string
+			suite.Require().Nil(res.Content(&actual))
+			suite.Assert().Equal(doc, actual)
+		}
+	}
+}
+
+func (suite *UnitTestSuite) TestGetIfModified() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			suite.Require().Len(opts.Ops, 1)
+			suite.Assert().Equal("$document.CAS", opts.Ops[0].Path)
+			suite.Assert().Equal(memd.SubdocFlagXattrPath, opts.Ops[0].Flags)
+
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{
+					{Value: []byte(`"0x7b00000000000000"`)},
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"someval"`),
+				Cas:   gocbcore.Cas(123),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	_, err := col.GetIfModified("someid", Cas(123), nil)
+	if !errors.Is(err, ErrNotModified) {
+		suite.T().Fatalf("Expected error to be not modified but was %v", err)
+	}
+	provider.AssertNotCalled(suite.T(), "Get", mock.Anything, mock.Anything)
+
+	res, err := col.GetIfModified("someid", Cas(100), nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(Cas(123), res.Cas())
+
+	var val string
+	suite.Require().Nil(res.Content(&val))
+	suite.Assert().Equal("someval", val)
+}
+
+func (suite *UnitTestSuite) TestGetAndLockClampsLockTime() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var lockTimes []uint32
+	provider := new(mockKvProvider)
+	provider.
+		On("GetAndLock", mock.AnythingOfType("gocbcore.GetAndLockOptions"), mock.AnythingOfType("gocbcore.GetAndLockCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetAndLockOptions)
+			cb := args.Get(1).(gocbcore.GetAndLockCallback)
+
+			lockTimes = append(lockTimes, opts.LockTime)
+			cb(&gocbcore.GetAndLockResult{
+				Value: []byte(`"someval"`),
+				Cas:   gocbcore.Cas(123),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	_, err := col.GetAndLock("someid", 10*time.Second, nil)
+	suite.Require().Nil(err, err)
+
+	_, err = col.GetAndLock("someid", 60*time.Second, nil)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal([]uint32{10, 30}, lockTimes)
+
+	_, err = col.GetAndLock("someid", 60*time.Second, &GetAndLockOptions{
+		StrictLockTime: true,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	_, err = col.GetAndLock("someid", -1*time.Second, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	provider.AssertNumberOfCalls(suite.T(), "GetAndLock", 2)
+}
+
+func (suite *UnitTestSuite) TestExistsWithDeleted() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var deleted uint32
+	provider := new(mockKvProvider)
+	provider.
+		On("GetMeta", mock.AnythingOfType("gocbcore.GetMetaOptions"), mock.AnythingOfType("gocbcore.GetMetaCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetMetaCallback)
+			cb(&gocbcore.GetMetaResult{
+				Cas:     gocbcore.Cas(123),
+				Deleted: deleted,
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.Exists("someid", &ExistsOptions{WithDeleted: true})
+	suite.Require().Nil(err, err)
+	suite.Assert().True(res.Exists())
+	suite.Assert().False(res.IsDeleted())
+	suite.Assert().Equal(Cas(123), res.Cas())
+
+	deleted = 1
+	res, err = col.Exists("someid", &ExistsOptions{WithDeleted: true})
+	suite.Require().Nil(err, err)
+	suite.Assert().False(res.Exists())
+	suite.Assert().True(res.IsDeleted())
+	suite.Assert().Equal(Cas(123), res.Cas())
+
+	res, err = col.Exists("someid", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().False(res.Exists())
+	suite.Assert().False(res.IsDeleted())
+}
+
+func (suite *UnitTestSuite) TestUnlockCasMismatchError() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	for _, serverErr := range []error{gocbcore.ErrCasMismatch, gocbcore.ErrDocumentLocked} {
+		provider := new(mockKvProvider)
+		provider.
+			On("Unlock", mock.AnythingOfType("gocbcore.UnlockOptions"), mock.AnythingOfType("gocbcore.UnlockCallback")).
+			Run(func(args mock.Arguments) {
+				opts := args.Get(0).(gocbcore.UnlockOptions)
+				cb := args.Get(1).(gocbcore.UnlockCallback)
+
+				action := opts.RetryStrategy.RetryAfter(nil, gocbcore.KVLockedRetryReason)
+				suite.Assert().Equal(time.Duration(0), action.Duration())
+
+				cb(nil, &gocbcore.KeyValueError{
+					InnerError: serverErr,
+				})
+			}).
+			Return(pendingOp, nil)
+
+		col := suite.collection("mock", "", "", provider)
+
+		err := col.Unlock("someid", Cas(123), nil)
+
+		var casErr *CasMismatchError
+		if !errors.As(err, &casErr) {
+			suite.T().Fatalf("Expected error to be CasMismatchError but was %v", err)
+		}
+		suite.Assert().Equal("someid", casErr.DocumentID)
+		suite.Assert().Equal(Cas(123), casErr.Cas)
+		suite.Assert().True(errors.Is(err, ErrCasMismatch))
+		suite.Assert().True(errors.Is(err, serverErr))
+	}
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Unlock", mock.AnythingOfType("gocbcore.UnlockOptions"), mock.AnythingOfType("gocbcore.UnlockCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.UnlockCallback)
+			cb(nil, &gocbcore.KeyValueError{
+				InnerError: gocbcore.ErrDocumentNotFound,
+			})
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	err := col.Unlock("someid", Cas(123), nil)
+	suite.Assert().True(errors.Is(err, ErrDocumentNotFound))
+	suite.Assert().False(errors.Is(err, ErrCasMismatch))
+
+	// A temporary failure goes through the normal retry path and is not a cas mismatch.
+	provider = new(mockKvProvider)
+	provider.
+		On("Unlock", mock.AnythingOfType("gocbcore.UnlockOptions"), mock.AnythingOfType("gocbcore.UnlockCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.UnlockOptions)
+			cb := args.Get(1).(gocbcore.UnlockCallback)
+
+			action := opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{}, gocbcore.KVTemporaryFailureRetryReason)
+			suite.Assert().NotZero(action.Duration())
+
+			cb(nil, &gocbcore.KeyValueError{
+				InnerError: gocbcore.ErrTemporaryFailure,
+			})
+		}).
+		Return(pendingOp, nil)
+
+	col = suite.collection("mock", "", "", provider)
+
+	err = col.Unlock("someid", Cas(123), nil)
+	suite.Assert().True(errors.Is(err, ErrTemporaryFailure))
+	suite.Assert().False(errors.Is(err, ErrCasMismatch))
+}
+
 func (suite *UnitTestSuite) TestExpiryConversion5Seconds() {
 	pendingOp := new(mockPendingOp)
 	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
@@ -2455,3 +2864,1154 @@ func (suite *UnitTestSuite) TestExpiryConversion31Days() {
 
 	suite.Assert().Equal(Cas(123), res.Cas())
 }
+
+func (suite *UnitTestSuite) TestExpiryTimeConversion() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var sentExpiry uint32
+	provider := new(mockKvProvider)
+	provider.
+		On("Set", mock.AnythingOfType("gocbcore.SetOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.SetOptions)
+			cb := args.Get(1).(gocbcore.StoreCallback)
+
+			sentExpiry = opts.Expiry
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(123),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	_, err := col.Upsert("someid", "someval", &UpsertOptions{
+		ExpiryTime: time.Now().Add(10 * 24 * time.Hour),
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().InDelta(10*24*60*60, sentExpiry, 2)
+
+	expiryTime := time.Now().Add(60 * 24 * time.Hour)
+	_, err = col.Upsert("someid", "someval", &UpsertOptions{
+		ExpiryTime: expiryTime,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(expiryTime.Unix()), sentExpiry)
+
+	_, err = col.Upsert("someid", "someval", &UpsertOptions{
+		ExpiryTime: time.Now().Add(-1 * time.Hour),
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(1), sentExpiry)
+}
+
+func (suite *UnitTestSuite) TestExpiryTimeWithExpiry() {
+	col := suite.collection("mock", "", "", new(mockKvProvider))
+
+	_, err := col.Insert("someid", "someval", &InsertOptions{
+		Expiry:     5 * time.Second,
+		ExpiryTime: time.Now().Add(time.Hour),
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	_, err = col.MutateIn("someid", []MutateInSpec{UpsertSpec("key", "value", nil)}, &MutateInOptions{
+		Expiry:     5 * time.Second,
+		ExpiryTime: time.Now().Add(time.Hour),
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	_, err = col.Replace("someid", "someval", &ReplaceOptions{
+		ExpiryTime:     time.Now().Add(time.Hour),
+		PreserveExpiry: true,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestGetReplicaResultSource() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"active"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("GetOneReplica", mock.AnythingOfType("gocbcore.GetOneReplicaOptions"), mock.AnythingOfType("gocbcore.GetReplicaCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetReplicaCallback)
+			cb(&gocbcore.GetReplicaResult{
+				Value: []byte(`"replica"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.Get("someid", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(GetResultSourceActive, res.Source())
+
+	replicaRes, err := col.getOneReplica(context.Background(), nil, "someid", 0, col.transcoder, nil, nil, 0, "")
+	suite.Require().Nil(err, err)
+	suite.Assert().False(replicaRes.IsReplica())
+	suite.Assert().Equal(GetResultSourceActive, replicaRes.Source())
+
+	replicaRes, err = col.getOneReplica(context.Background(), nil, "someid", 1, col.transcoder, nil, nil, 0, "")
+	suite.Require().Nil(err, err)
+	suite.Assert().True(replicaRes.IsReplica())
+	suite.Assert().Equal(GetResultSourceReplica, replicaRes.Source())
+}
+
+func (suite *UnitTestSuite) TestGetServerDuration() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	coreTracer := &coreRequestTracerWrapper{tracer: &NoopTracer{}}
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			cb := args.Get(1).(gocbcore.GetCallback)
+
+			// gocbcore reports the server duration on the dispatch span that it creates as a child of the span for
+			// the operation.
+			opSpan := coreTracer.RequestSpan(opts.TraceContext, "Get")
+			span := coreTracer.RequestSpan(opSpan.Context(), "dispatch_to_server")
+			span.SetAttribute(spanAttribServerDurationKey, 25*time.Microsecond)
+			span.End()
+			opSpan.End()
+
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"value"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.Get("someid", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(25*time.Microsecond, res.Internal().ServerDuration())
+
+	mutRes := &MutationResult{}
+	suite.Assert().Zero(mutRes.Internal().ServerDuration())
+}
+
+func (suite *UnitTestSuite) TestGetRetries() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			cb := args.Get(1).(gocbcore.GetCallback)
+
+			// gocbcore consults the strategy before counting the attempt on the request.
+			action := opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{idempotent: true},
+				gocbcore.KVLockedRetryReason)
+			suite.Require().NotZero(action.Duration())
+			action = opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{
+				attempts:   1,
+				idempotent: true,
+				reasons:    []gocbcore.RetryReason{gocbcore.KVLockedRetryReason},
+			}, gocbcore.KVTemporaryFailureRetryReason)
+			suite.Require().NotZero(action.Duration())
+
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"value"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.Get("someid", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(2), res.RetryAttempts())
+	suite.Assert().Equal([]RetryReason{KVLockedRetryReason, KVTemporaryFailureRetryReason}, res.RetryReasons())
+
+	// Retries are also recorded when the operation sets its own retry strategy.
+	res, err = col.Get("someid", &GetOptions{RetryStrategy: NewBestEffortRetryStrategy(nil)})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(2), res.RetryAttempts())
+
+	mutRes := &MutationResult{}
+	suite.Assert().Zero(mutRes.RetryAttempts())
+	suite.Assert().Empty(mutRes.RetryReasons())
+}
+
+type retryCountingPendingOp struct {
+	gocbcore.PendingOp
+	attempts uint32
+	reasons  []gocbcore.RetryReason
+}
+
+func (op *retryCountingPendingOp) RetryAttempts() uint32 {
+	return op.attempts
+}
+
+func (op *retryCountingPendingOp) RetryReasons() []gocbcore.RetryReason {
+	return op.reasons
+}
+
+func (suite *UnitTestSuite) TestGetRetriesAlwaysRetried() {
+	// Not my vbucket is retried by gocbcore without consulting the strategy, so it is only counted by the request.
+	pendingOp := &retryCountingPendingOp{
+		attempts: 3,
+		reasons:  []gocbcore.RetryReason{gocbcore.KVNotMyVBucketRetryReason, gocbcore.KVLockedRetryReason},
+	}
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			cb := args.Get(1).(gocbcore.GetCallback)
+
+			action := opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{
+				attempts:   1,
+				idempotent: true,
+				reasons:    []gocbcore.RetryReason{gocbcore.KVNotMyVBucketRetryReason},
+			}, gocbcore.KVLockedRetryReason)
+			suite.Require().NotZero(action.Duration())
+
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"value"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.Get("someid", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(3), res.RetryAttempts())
+	suite.Assert().Equal([]RetryReason{KVNotMyVBucketRetryReason, KVLockedRetryReason}, res.RetryReasons())
+}
+
+type tagRecordingMeter struct {
+	NoopMeter
+	tags []map[string]string
+}
+
+func (tm *tagRecordingMeter) ValueRecorder(name string, tags map[string]string) (ValueRecorder, error) {
+	tm.tags = append(tm.tags, tags)
+	return defaultNoopValueRecorder, nil
+}
+
+func (suite *UnitTestSuite) TestGetOperationLabel() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"value"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	tracer := newTestTracer()
+	meter := &tagRecordingMeter{}
+
+	col := suite.collection("mock", "scope", "coll", provider)
+	col.tracer = tracer
+	col.meter = newMeterWrapper(meter)
+
+	_, err := col.Get("someid", &GetOptions{
+		OperationLabel: "loadUserProfile",
+	})
+	suite.Require().Nil(err, err)
+
+	_, err = col.Get("someid", nil)
+	suite.Require().Nil(err, err)
+
+	spans := tracer.GetSpans()[nil]
+	suite.Require().Len(spans, 2)
+	suite.Assert().Equal("loadUserProfile", spans[0].Tags[spanAttribOperationLabelKey])
+	suite.Assert().NotContains(spans[1].Tags, spanAttribOperationLabelKey)
+
+	suite.Require().Len(meter.tags, 2)
+	suite.Assert().Equal(map[string]string{
+		meterAttribServiceKey:     meterValueServiceKV,
+		meterAttribOperationKey:   "get",
+		meterAttribOperationLabel: "loadUserProfile",
+		meterAttribBucketKey:      "mock",
+		meterAttribScopeKey:       "scope",
+		meterAttribCollectionKey:  "coll",
+	}, meter.tags[0])
+	suite.Assert().Equal(map[string]string{
+		meterAttribServiceKey:    meterValueServiceKV,
+		meterAttribOperationKey:  "get",
+		meterAttribBucketKey:     "mock",
+		meterAttribScopeKey:      "scope",
+		meterAttribCollectionKey: "coll",
+	}, meter.tags[1])
+}
+
+func (suite *IntegrationTestSuite) TestGetFreshestReplica() {
+	suite.skipIfUnsupported(KeyValueFeature)
+	suite.skipIfUnsupported(ReplicasFeature)
+
+	var doc testBeerDocument
+	err := loadJSONTestDataset("beer_sample_single", &doc)
+	if err != nil {
+		suite.T().Fatalf("Could not read test dataset: %v", err)
+	}
+
+	_, err = globalCollection.Upsert("freshestReplicaDoc", doc, nil)
+	if err != nil {
+		suite.T().Fatalf("Upsert failed, error was %v", err)
+	}
+
+	doc.Name = "updated"
+	mutRes, err := globalCollection.Upsert("freshestReplicaDoc", doc, nil)
+	if err != nil {
+		suite.T().Fatalf("Upsert failed, error was %v", err)
+	}
+
+	freshestDoc, err := globalCollection.GetFreshestReplica("freshestReplicaDoc", &GetFreshestReplicaOptions{
+		Window: 100 * time.Millisecond,
+	})
+	if err != nil {
+		suite.T().Fatalf("GetFreshestReplica failed, error was %v", err)
+	}
+
+	// The active always has the latest mutation so the freshest copy must include it, regardless of the replicas.
+	suite.Assert().Equal(mutRes.Cas(), freshestDoc.Cas())
+
+	var freshestDocContent testBeerDocument
+	err = freshestDoc.Content(&freshestDocContent)
+	if err != nil {
+		suite.T().Fatalf("Content failed, error was %v", err)
+	}
+	suite.Assert().Equal(doc, freshestDocContent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = globalCollection.GetFreshestReplica("freshestReplicaDoc", &GetFreshestReplicaOptions{
+		Context: ctx,
+	})
+	if !errors.Is(err, ErrRequestCanceled) {
+		suite.T().Fatalf("Expected error to be canceled but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestUpsertRetryDurabilityImpossible() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var attempts int
+	provider := new(mockKvProvider)
+	provider.
+		On("Set", mock.AnythingOfType("gocbcore.SetOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.StoreCallback)
+			attempts++
+			if attempts < 3 {
+				cb(nil, gocbcore.ErrDurabilityImpossible)
+				return
+			}
+
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	_, err := col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+	})
+	if !errors.Is(err, ErrDurabilityImpossible) {
+		suite.T().Fatalf("Expected durability impossible error but was %v", err)
+	}
+	suite.Assert().Equal(1, attempts)
+
+	// Custom strategies are never asked to retry, even if they would retry any reason.
+	attempts = 0
+	custom := &mockRetryStrategy{action: &WithDurationRetryAction{WithDuration: time.Millisecond}}
+	_, err = col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+		RetryStrategy:   custom,
+	})
+	if !errors.Is(err, ErrDurabilityImpossible) {
+		suite.T().Fatalf("Expected durability impossible error but was %v", err)
+	}
+	suite.Assert().Equal(1, attempts)
+	suite.Assert().False(custom.retried)
+
+	attempts = 0
+	strategy := NewBestEffortRetryStrategy(func(retryAttempts uint32) time.Duration {
+		return time.Millisecond
+	})
+	strategy.RetryDurabilityImpossible = true
+
+	res, err := col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+		RetryStrategy:   strategy,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(Cas(1), res.Cas())
+	suite.Assert().Equal(3, attempts)
+}
+
+func (suite *UnitTestSuite) TestUpsertDurabilityModeBestEffort() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var levels []memd.DurabilityLevel
+	provider := new(mockKvProvider)
+	provider.
+		On("Set", mock.AnythingOfType("gocbcore.SetOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.SetOptions)
+			cb := args.Get(1).(gocbcore.StoreCallback)
+			levels = append(levels, opts.DurabilityLevel)
+			if opts.DurabilityLevel > 0 {
+				cb(nil, gocbcore.ErrDurabilityImpossible)
+				return
+			}
+
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	_, err := col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+		DurabilityMode:  DurabilityModeStrict,
+	})
+	if !errors.Is(err, ErrDurabilityImpossible) {
+		suite.T().Fatalf("Expected durability impossible error but was %v", err)
+	}
+	suite.Assert().Equal([]memd.DurabilityLevel{memd.DurabilityLevelMajority}, levels)
+
+	levels = nil
+	res, err := col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+		DurabilityMode:  DurabilityModeBestEffort,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(Cas(1), res.Cas())
+	suite.Assert().Equal([]memd.DurabilityLevel{memd.DurabilityLevelMajority, 0}, levels)
+}
+
+func (suite *UnitTestSuite) TestUpsertObserveDurabilityFallback() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var levels []memd.DurabilityLevel
+	provider := new(mockKvProvider)
+	provider.
+		On("Set", mock.AnythingOfType("gocbcore.SetOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.SetOptions)
+			cb := args.Get(1).(gocbcore.StoreCallback)
+			levels = append(levels, opts.DurabilityLevel)
+
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	capabilities := new(mockKvCapabilityVerifier)
+	capabilities.On(
+		"BucketCapabilityStatus",
+		gocbcore.BucketCapabilityDurableWrites,
+	).Return(gocbcore.BucketCapabilityStatusUnsupported)
+
+	cli := new(mockConnectionManager)
+	cli.On("getKvCapabilitiesProvider", "mock").Return(capabilities, nil)
+
+	col := suite.collection("mock", "", "", provider)
+	col.bucket = suite.bucket("mock", suite.defaultTimeoutConfig(), cli)
+
+	// Without fallback enabled the durability level is always sent to the server.
+	_, err := col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]memd.DurabilityLevel{memd.DurabilityLevelMajority}, levels)
+
+	// Observing the durability of a mutation requires its mutation token.
+	col.bucket.durabilityFallback = true
+	_, err = col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+	suite.Assert().Len(levels, 1)
+}
+
+func (suite *UnitTestSuite) TestUpsertObserveDurabilityFallbackUnknownCapability() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	configErr := errors.New("no config")
+	var levels []memd.DurabilityLevel
+	provider := new(mockKvProvider)
+	provider.
+		On("Set", mock.AnythingOfType("gocbcore.SetOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.SetOptions)
+			cb := args.Get(1).(gocbcore.StoreCallback)
+			levels = append(levels, opts.DurabilityLevel)
+
+			if opts.DurabilityLevel > 0 {
+				cb(nil, gocbcore.ErrFeatureNotAvailable)
+				return
+			}
+
+			cb(&gocbcore.StoreResult{
+				Cas:           gocbcore.Cas(1),
+				MutationToken: gocbcore.MutationToken{VbID: 1, VbUUID: 2, SeqNo: 3},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("ConfigSnapshot").
+		Return(nil, configErr)
+
+	capabilities := new(mockKvCapabilityVerifier)
+	capabilities.On(
+		"BucketCapabilityStatus",
+		gocbcore.BucketCapabilityDurableWrites,
+	).Return(gocbcore.BucketCapabilityStatusUnknown)
+
+	cli := new(mockConnectionManager)
+	cli.On("getKvCapabilitiesProvider", "mock").Return(capabilities, nil)
+
+	col := suite.collection("mock", "", "", provider)
+	col.useMutationTokens = true
+	col.bucket = suite.bucket("mock", suite.defaultTimeoutConfig(), cli)
+	col.bucket.durabilityFallback = true
+
+	// Support for synchronous durability is not known yet, so the durability level is sent and once the server
+	// rejects it the mutation is sent again and its durability observed.
+	_, err := col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+	})
+	suite.Assert().Equal(configErr, err)
+	suite.Assert().Equal([]memd.DurabilityLevel{memd.DurabilityLevelMajority, 0}, levels)
+	provider.AssertNumberOfCalls(suite.T(), "ConfigSnapshot", 1)
+
+	// Without fallback enabled the rejection is returned.
+	levels = nil
+	col.bucket.durabilityFallback = false
+	_, err = col.Upsert("someid", "value", &UpsertOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+	})
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected error to be feature not available but was %v", err)
+	}
+	suite.Assert().Equal([]memd.DurabilityLevel{memd.DurabilityLevelMajority}, levels)
+}
+
+func (suite *UnitTestSuite) TestObserveDurabilityPolling() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	token := gocbcore.MutationToken{VbID: 1, VbUUID: 2, SeqNo: 10}
+
+	var lock sync.Mutex
+	polls := make(map[int]int)
+	var reachedSeqNo gocbcore.SeqNo
+	provider := new(mockKvProvider)
+	provider.
+		On("ObserveVb", mock.AnythingOfType("gocbcore.ObserveVbOptions"), mock.AnythingOfType("gocbcore.ObserveVbCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.ObserveVbOptions)
+			cb := args.Get(1).(gocbcore.ObserveVbCallback)
+			suite.Assert().Equal(token.VbID, opts.VbID)
+			suite.Assert().Equal(token.VbUUID, opts.VbUUID)
+
+			lock.Lock()
+			polls[opts.ReplicaIdx]++
+			seqNo := token.SeqNo - 1
+			// Each copy only catches up with the mutation after it has been polled once.
+			if polls[opts.ReplicaIdx] > 1 {
+				seqNo = reachedSeqNo
+			}
+			lock.Unlock()
+
+			cb(&gocbcore.ObserveVbResult{
+				VbID:         opts.VbID,
+				VbUUID:       opts.VbUUID,
+				CurrentSeqNo: seqNo,
+				PersistSeqNo: seqNo,
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	reachedSeqNo = token.SeqNo
+	opm := col.newKvOpManager("observe", nil)
+	err := col.observeDurability(context.Background(), opm, "someid", token, 0, 0, DurabilityLevelMajority, 1,
+		time.Now().Add(time.Second), make(chan struct{}), "", DurabilityModeStrict)
+	opm.Finish(true)
+	suite.Require().Nil(err, err)
+
+	lock.Lock()
+	suite.Assert().GreaterOrEqual(polls[1], 2)
+	lock.Unlock()
+
+	// A mutation which never reaches a majority of the copies is applied but its durability is not known.
+	lock.Lock()
+	polls = make(map[int]int)
+	reachedSeqNo = token.SeqNo - 1
+	lock.Unlock()
+	opm = col.newKvOpManager("observe", nil)
+	err = col.observeDurability(context.Background(), opm, "someid", token, 0, 0, DurabilityLevelMajority, 1,
+		time.Now().Add(50*time.Millisecond), make(chan struct{}), "", DurabilityModeStrict)
+	opm.Finish(true)
+	if !errors.Is(err, ErrDurabilityAmbiguous) {
+		suite.T().Fatalf("Expected error to be durability ambiguous but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestTouchDurabilityInvalidArguments() {
+	provider := new(mockKvProvider)
+	col := suite.collection("mock", "", "", provider)
+
+	// Touch durability is observed, which requires mutation tokens.
+	_, err := col.Touch("someid", time.Second, &TouchOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	col.useMutationTokens = true
+	_, err = col.Touch("someid", time.Second, &TouchOptions{
+		DurabilityLevel: DurabilityLevelMajority,
+		ReplicateTo:     1,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+
+	provider.AssertNotCalled(suite.T(), "Touch", mock.Anything, mock.Anything)
+}
+
+func (suite *UnitTestSuite) TestObserveDurabilityRequirements() {
+	type tCase struct {
+		level       DurabilityLevel
+		numReplicas int
+		persistTo   uint
+		replicateTo uint
+	}
+
+	testCases := []tCase{
+		{level: DurabilityLevelMajority, numReplicas: 1, persistTo: 0, replicateTo: 1},
+		{level: DurabilityLevelMajority, numReplicas: 2, persistTo: 0, replicateTo: 1},
+		{level: DurabilityLevelMajority, numReplicas: 3, persistTo: 0, replicateTo: 2},
+		{level: DurabilityLevelMajorityAndPersistOnMaster, numReplicas: 1, persistTo: 1, replicateTo: 1},
+		{level: DurabilityLevelMajorityAndPersistOnMaster, numReplicas: 2, persistTo: 1, replicateTo: 1},
+		{level: DurabilityLevelPersistToMajority, numReplicas: 1, persistTo: 2, replicateTo: 0},
+		{level: DurabilityLevelPersistToMajority, numReplicas: 2, persistTo: 2, replicateTo: 0},
+		{level: DurabilityLevelPersistToMajority, numReplicas: 3, persistTo: 3, replicateTo: 0},
+	}
+
+	for _, tc := range testCases {
+		persistTo, replicateTo := observeDurabilityRequirements(tc.level, tc.numReplicas)
+		suite.Assert().Equal(tc.persistTo, persistTo, "level %d with %d replicas", tc.level, tc.numReplicas)
+		suite.Assert().Equal(tc.replicateTo, replicateTo, "level %d with %d replicas", tc.level, tc.numReplicas)
+	}
+}
+
+func (suite *IntegrationTestSuite) TestGetReplicaFallback() {
+	suite.skipIfUnsupported(KeyValueFeature)
+	suite.skipIfUnsupported(ReplicasFeature)
+
+	var doc testBeerDocument
+	err := loadJSONTestDataset("beer_sample_single", &doc)
+	if err != nil {
+		suite.T().Fatalf("Could not read test dataset: %v", err)
+	}
+
+	_, err = globalCollection.Upsert("replicaFallbackDoc", doc, nil)
+	if err != nil {
+		suite.T().Fatalf("Upsert failed, error was %v", err)
+	}
+
+	// A delay this short will almost always fall back, either the active or a replica may then serve the read.
+	getDoc, err := globalCollection.Get("replicaFallbackDoc", &GetOptions{
+		ReplicaFallbackDelay: time.Nanosecond,
+	})
+	if err != nil {
+		suite.T().Fatalf("Get failed, error was %v", err)
+	}
+
+	details := getDoc.Internal().ReplicaFallback()
+	suite.Require().NotNil(details)
+	if details.ReplicaIndex == 0 {
+		suite.Assert().Equal(GetResultSourceActive, getDoc.Source())
+	} else {
+		suite.Assert().True(details.FellBack)
+		suite.Assert().Equal(GetResultSourceReplica, getDoc.Source())
+	}
+
+	var getDocContent testBeerDocument
+	err = getDoc.Content(&getDocContent)
+	if err != nil {
+		suite.T().Fatalf("Content failed, error was %v", err)
+	}
+	suite.Assert().Equal(doc, getDocContent)
+
+	_, err = globalCollection.Get("replicaFallbackMissingDoc", &GetOptions{
+		ReplicaFallbackDelay: time.Nanosecond,
+	})
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected error to be document not found but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestGetReplicaFallbackActiveFirst() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: []byte(`"value"`),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.Get("someid", &GetOptions{
+		ReplicaFallbackDelay: time.Minute,
+	})
+	suite.Require().Nil(err, err)
+
+	details := res.Internal().ReplicaFallback()
+	suite.Require().NotNil(details)
+	suite.Assert().False(details.FellBack)
+	suite.Assert().Zero(details.ReplicaIndex)
+	suite.Assert().Less(int64(details.ActiveWait), int64(time.Minute))
+	suite.Assert().Equal(GetResultSourceActive, res.Source())
+
+	// The details are only populated when the fallback is used.
+	res, err = col.Get("someid", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Nil(res.Internal().ReplicaFallback())
+
+	_, err = col.Get("someid", &GetOptions{
+		ReplicaFallbackDelay: time.Minute,
+		WithExpiry:           true,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestGetReplicaFallbackOnActiveUnavailable() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	// The node holding the active is down, so the retry strategy is asked whether to retry.
+	var retryAfter time.Duration
+	getErr := gocbcore.ErrRequestCanceled
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			cb := args.Get(1).(gocbcore.GetCallback)
+
+			retryAfter = opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{idempotent: true},
+				gocbcore.NodeNotAvailableRetryReason).Duration()
+			cb(nil, getErr)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("ConfigSnapshot").
+		Return(nil, errors.New("no config"))
+
+	col := suite.collection("mock", "", "", provider)
+
+	// Reading the replicas also fails here, so the error from the active is returned.
+	_, err := col.Get("someid", &GetOptions{
+		ReplicaFallback: true,
+	})
+	suite.Assert().True(errors.Is(err, ErrRequestCanceled), "expected request canceled but was %v", err)
+	suite.Assert().Zero(retryAfter)
+	provider.AssertNumberOfCalls(suite.T(), "ConfigSnapshot", 1)
+
+	// Without the option the active is retried and the read is never attempted against the replicas.
+	_, err = col.Get("someid", nil)
+	suite.Assert().NotNil(err)
+	suite.Assert().NotZero(retryAfter)
+	provider.AssertNumberOfCalls(suite.T(), "ConfigSnapshot", 1)
+
+	// A document which does not exist is not read from the replicas.
+	getErr = gocbcore.ErrDocumentNotFound
+	_, err = col.Get("someid", &GetOptions{
+		ReplicaFallback: true,
+	})
+	suite.Assert().True(errors.Is(err, ErrDocumentNotFound), "expected document not found but was %v", err)
+	provider.AssertNumberOfCalls(suite.T(), "ConfigSnapshot", 1)
+
+	_, err = col.Get("someid", &GetOptions{
+		ReplicaFallback: true,
+		WithExpiry:      true,
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestGetReplicaFallbackServedByReplica() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOptions)
+			cb := args.Get(1).(gocbcore.GetCallback)
+
+			opts.RetryStrategy.RetryAfter(&mockGocbcoreRequest{idempotent: true}, gocbcore.NodeNotAvailableRetryReason)
+			cb(nil, gocbcore.ErrRequestCanceled)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("GetOneReplica", mock.AnythingOfType("gocbcore.GetOneReplicaOptions"),
+			mock.AnythingOfType("gocbcore.GetReplicaCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.GetOneReplicaOptions)
+			cb := args.Get(1).(gocbcore.GetReplicaCallback)
+			suite.Assert().Equal(1, opts.ReplicaIdx)
+
+			cb(&gocbcore.GetReplicaResult{
+				Value: []byte(`"replica"`),
+				Cas:   gocbcore.Cas(2),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	// The default plan with a single replica, the number of replicas is otherwise read from the cluster config.
+	plan := &replicaReadPlan{
+		stages: []replicaReadStage{{copies: []int{0}}, {copies: []int{1}}},
+	}
+	res, err := col.readReplicas("someid", &GetOptions{ReplicaFallback: true}, plan)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().True(res.IsReplica())
+	suite.Assert().Equal(GetResultSourceReplica, res.Source())
+	suite.Assert().Equal(Cas(2), res.Cas())
+
+	var content string
+	suite.Require().Nil(res.Content(&content))
+	suite.Assert().Equal("replica", content)
+
+	details := res.Internal().ReplicaFallback()
+	suite.Require().NotNil(details)
+	suite.Assert().True(details.FellBack)
+	suite.Assert().Equal(1, details.ReplicaIndex)
+	provider.AssertNumberOfCalls(suite.T(), "Get", 1)
+	provider.AssertNumberOfCalls(suite.T(), "GetOneReplica", 1)
+}
+
+func (suite *UnitTestSuite) TestGetAllReplicasResultMaxResults() {
+	newResult := func(maxResults uint32) *GetAllReplicasResult {
+		return &GetAllReplicasResult{
+			totalRequests:       3,
+			maxResults:          maxResults,
+			resCh:               make(chan *GetReplicaResult, 3),
+			cancelCh:            make(chan struct{}),
+			span:                defaultNoopSpan,
+			childReqsCompleteCh: make(chan struct{}),
+		}
+	}
+
+	res := newResult(2)
+	res.addResult(&GetReplicaResult{})
+	res.addResult(&GetReplicaResult{isReplica: true})
+
+	suite.Assert().NotNil(res.Next())
+	suite.Assert().NotNil(res.Next())
+	suite.Assert().Nil(res.Next())
+
+	select {
+	case <-res.cancelCh:
+	default:
+		suite.T().Fatalf("Expected the outstanding request to be canceled")
+	}
+
+	res.addResult(&GetReplicaResult{})
+	suite.Assert().Nil(res.Next())
+	suite.Require().Nil(res.Close())
+
+	// Once every request has completed the results are finished even though some failed, rather than Next waiting
+	// for the timeout.
+	res = newResult(3)
+	res.addFailed()
+	res.addResult(&GetReplicaResult{})
+	res.addFailed()
+
+	suite.Assert().NotNil(res.Next())
+	suite.Assert().Nil(res.Next())
+	suite.Require().Nil(res.Close())
+
+	// Close waits for the outstanding requests, which have been canceled, to complete.
+	res = newResult(3)
+	res.addResult(&GetReplicaResult{})
+
+	closedCh := make(chan struct{})
+	go func() {
+		suite.Assert().Nil(res.Close())
+		close(closedCh)
+	}()
+
+	<-res.cancelCh
+	res.addFailed()
+	res.addFailed()
+
+	select {
+	case <-closedCh:
+	case <-time.After(5 * time.Second):
+		suite.T().Fatalf("Close did not return once the outstanding requests completed")
+	}
+
+	suite.Assert().NotNil(res.Next())
+	suite.Assert().Nil(res.Next())
+}
+
+func (suite *UnitTestSuite) TestGetWithExpiryAndProjection() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	var expiryValue []byte
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			suite.Require().Len(opts.Ops, 2)
+			suite.Assert().Equal("$document.exptime", opts.Ops[0].Path)
+			suite.Assert().Equal(memd.SubdocFlagXattrPath, opts.Ops[0].Flags)
+			suite.Assert().Equal("name", opts.Ops[1].Path)
+
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{
+					{Value: expiryValue},
+					{Value: []byte(`"beer"`)},
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	expiryValue = []byte(strconv.FormatInt(expiry.Unix(), 10))
+	res, err := col.Get("someid", &GetOptions{
+		WithExpiry: true,
+		Project:    []string{"name"},
+	})
+	suite.Require().Nil(err, err)
+
+	var content map[string]string
+	suite.Require().Nil(res.Content(&content))
+	suite.Assert().Equal(map[string]string{"name": "beer"}, content)
+	suite.Assert().True(res.HasExpiry())
+	suite.Assert().True(expiry.Equal(res.ExpiryTime()))
+
+	expiryValue = []byte("0")
+	res, err = col.Get("someid", &GetOptions{
+		WithExpiry: true,
+		Project:    []string{"name"},
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().False(res.HasExpiry())
+	suite.Assert().True(res.ExpiryTime().IsZero())
+}
+
+func (suite *UnitTestSuite) TestGetProjectionPathLimit() {
+	doc := map[string]interface{}{
+		"foo": map[string]interface{}{
+			"bar": map[string]interface{}{
+				"baz": "nested",
+				"qux": "other",
+			},
+		},
+		"list": []interface{}{"a", "b", "c"},
+	}
+	for i := 1; i <= 17; i++ {
+		doc[fmt.Sprintf("field%d", i)] = i
+	}
+	docBytes, err := json.Marshal(doc)
+	suite.Require().Nil(err, err)
+
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			var ops []gocbcore.SubDocResult
+			for _, op := range opts.Ops {
+				if op.Path == "" {
+					ops = append(ops, gocbcore.SubDocResult{Value: docBytes})
+					continue
+				}
+
+				value, ok := projectionValue(doc, op.Path)
+				suite.Require().True(ok, op.Path)
+				ops = append(ops, gocbcore.SubDocResult{Value: suite.mustConvertToBytes(value)})
+			}
+
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: ops,
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	paths := func(n int) []string {
+		var fields []string
+		for i := 1; i <= n; i++ {
+			fields = append(fields, fmt.Sprintf("field%d", i))
+		}
+		return fields
+	}
+
+	suite.Run("16 paths", func() {
+		res, err := col.Get("someid", &GetOptions{Project: paths(16)})
+		suite.Require().Nil(err, err)
+
+		lastCall := provider.Calls[len(provider.Calls)-1]
+		suite.Assert().Len(lastCall.Arguments.Get(0).(gocbcore.LookupInOptions).Ops, 16)
+
+		var content map[string]int
+		suite.Require().Nil(res.Content(&content))
+		suite.Assert().Len(content, 16)
+		suite.Assert().Equal(16, content["field16"])
+	})
+
+	suite.Run("17 paths", func() {
+		res, err := col.Get("someid", &GetOptions{Project: paths(17)})
+		suite.Require().Nil(err, err)
+
+		lastCall := provider.Calls[len(provider.Calls)-1]
+		lastOps := lastCall.Arguments.Get(0).(gocbcore.LookupInOptions).Ops
+		suite.Require().Len(lastOps, 1)
+		suite.Assert().Equal("", lastOps[0].Path)
+
+		var content map[string]int
+		suite.Require().Nil(res.Content(&content))
+		suite.Assert().Len(content, 17)
+		suite.Assert().Equal(17, content["field17"])
+	})
+
+	expectedNested := map[string]interface{}{
+		"foo": map[string]interface{}{
+			"bar": map[string]interface{}{
+				"baz": "nested",
+				"qux": "other",
+			},
+		},
+	}
+
+	suite.Run("nested paths", func() {
+		res, err := col.Get("someid", &GetOptions{Project: []string{"foo.bar.baz", "foo.bar.qux"}})
+		suite.Require().Nil(err, err)
+
+		var content map[string]interface{}
+		suite.Require().Nil(res.Content(&content))
+		suite.Assert().Equal(expectedNested, content)
+	})
+
+	suite.Run("nested paths fallback", func() {
+		res, err := col.Get("someid", &GetOptions{Project: append(paths(16), "foo.bar.baz", "foo.bar.qux")})
+		suite.Require().Nil(err, err)
+
+		var content map[string]interface{}
+		suite.Require().Nil(res.Content(&content))
+		suite.Assert().Equal(expectedNested["foo"], content["foo"])
+		suite.Assert().Len(content, 17)
+	})
+
+	suite.Run("fallback missing path", func() {
+		_, err := col.Get("someid", &GetOptions{Project: append(paths(17), "missing.path")})
+		if !errors.Is(err, ErrPathNotFound) {
+			suite.T().Fatalf("Expected error to be path not found but was %v", err)
+		}
+	})
+}
+
+func (suite *UnitTestSuite) TestProjectionValue() {
+	var doc interface{}
+	err := json.Unmarshal([]byte(`{"foo":{"bar":[{"baz":1},{"baz":2}]},"list":[[1,2],[3,4]]}`), &doc)
+	suite.Require().Nil(err, err)
+
+	tests := map[string]interface{}{
+		"foo.bar[1].baz": float64(2),
+		"foo.bar[-1]":    map[string]interface{}{"baz": float64(2)},
+		"list[1][0]":     float64(3),
+	}
+	for path, expected := range tests {
+		value, ok := projectionValue(doc, path)
+		suite.Assert().True(ok, path)
+		suite.Assert().Equal(expected, value, path)
+	}
+
+	for _, path := range []string{"foo.missing", "foo.bar[2]", "list.foo", "foo.bar[x]"} {
+		_, ok := projectionValue(doc, path)
+		suite.Assert().False(ok, path)
+	}
+}
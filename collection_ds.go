@@ -1,6 +1,7 @@
 package gocb
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -9,6 +10,7 @@ import (
 type CouchbaseList struct {
 	collection *Collection
 	id         string
+	maxSize    uint
 }
 
 // List returns a new CouchbaseList for the document specified by id.
@@ -19,17 +21,49 @@ func (c *Collection) List(id string) *CouchbaseList {
 	}
 }
 
+// CouchbaseListOptions are the options available when creating a CouchbaseList.
+// UNCOMMITTED: This API may change in the future.
+type CouchbaseListOptions struct {
+	// MaxSize is the maximum number of items in the list, 0 meaning unbounded. When an item is appended or prepended
+	// to a full list then items are removed from the opposite end of the list, i.e. the oldest items are dropped.
+	// The new item is added and the list trimmed within a single atomic sub-document mutation, guarded by CAS.
+	// At most 15 items are removed by any one mutation, so if MaxSize is reduced the list converges to the new size
+	// over subsequent additions.
+	MaxSize uint
+}
+
+// ListWithOptions returns a new CouchbaseList for the document specified by id, using the provided options.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) ListWithOptions(id string, opts *CouchbaseListOptions) *CouchbaseList {
+	if opts == nil {
+		opts = &CouchbaseListOptions{}
+	}
+
+	return &CouchbaseList{
+		collection: c,
+		id:         id,
+		maxSize:    opts.MaxSize,
+	}
+}
+
 // Iterator returns an iterable for all items in the list.
 func (cl *CouchbaseList) Iterator() ([]interface{}, error) {
+	return cl.IteratorContext(context.Background())
+}
+
+// IteratorContext is the same as Iterator, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) IteratorContext(ctx context.Context) ([]interface{}, error) {
 	span := cl.collection.startKvOpTrace("list_iterator", nil, false)
 	defer span.End()
 
-	return dsListIterator(span, cl.collection, cl.id)
+	return dsListIterator(ctx, span, cl.collection, cl.id)
 }
 
-func dsListIterator(span RequestSpan, collection *Collection, id string) ([]interface{}, error) {
+func dsListIterator(ctx context.Context, span RequestSpan, collection *Collection, id string) ([]interface{}, error) {
 	content, err := collection.Get(id, &GetOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return nil, err
@@ -46,12 +80,19 @@ func dsListIterator(span RequestSpan, collection *Collection, id string) ([]inte
 
 // At retrieves the value specified at the given index from the list.
 func (cl *CouchbaseList) At(index int, valuePtr interface{}) error {
+	return cl.AtContext(context.Background(), index, valuePtr)
+}
+
+// AtContext is the same as At, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) AtContext(ctx context.Context, index int, valuePtr interface{}) error {
 	span := cl.collection.startKvOpTrace("list_at", nil, false)
 	defer span.End()
 	ops := make([]LookupInSpec, 1)
 	ops[0] = GetSpec(fmt.Sprintf("[%d]", index), nil)
 	result, err := cl.collection.LookupIn(cl.id, ops, &LookupInOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return err
@@ -62,12 +103,19 @@ func (cl *CouchbaseList) At(index int, valuePtr interface{}) error {
 
 // RemoveAt removes the value specified at the given index from the list.
 func (cl *CouchbaseList) RemoveAt(index int) error {
+	return cl.RemoveAtContext(context.Background(), index)
+}
+
+// RemoveAtContext is the same as RemoveAt, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) RemoveAtContext(ctx context.Context, index int) error {
 	span := cl.collection.startKvOpTrace("list_remove_at", nil, false)
 	defer span.End()
 	ops := make([]MutateInSpec, 1)
 	ops[0] = RemoveSpec(fmt.Sprintf("[%d]", index), nil)
 	_, err := cl.collection.MutateIn(cl.id, ops, &MutateInOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return err
@@ -78,13 +126,24 @@ func (cl *CouchbaseList) RemoveAt(index int) error {
 
 // Append appends an item to the list.
 func (cl *CouchbaseList) Append(val interface{}) error {
+	return cl.AppendContext(context.Background(), val)
+}
+
+// AppendContext is the same as Append, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) AppendContext(ctx context.Context, val interface{}) error {
 	span := cl.collection.startKvOpTrace("list_append", nil, false)
 	defer span.End()
+	if cl.maxSize > 0 {
+		return dsBoundedListPush(ctx, span, cl.collection, cl.id, ArrayAppendSpec("", val, nil), "[0]", cl.maxSize)
+	}
+
 	ops := make([]MutateInSpec, 1)
 	ops[0] = ArrayAppendSpec("", val, nil)
 	_, err := cl.collection.MutateIn(cl.id, ops, &MutateInOptions{
 		StoreSemantic: StoreSemanticsUpsert,
 		ParentSpan:    span,
+		Context:       ctx,
 	})
 	if err != nil {
 		return err
@@ -95,18 +154,80 @@ func (cl *CouchbaseList) Append(val interface{}) error {
 
 // Prepend prepends an item to the list.
 func (cl *CouchbaseList) Prepend(val interface{}) error {
+	return cl.PrependContext(context.Background(), val)
+}
+
+// PrependContext is the same as Prepend, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) PrependContext(ctx context.Context, val interface{}) error {
 	span := cl.collection.startKvOpTrace("list_prepend", nil, false)
 	defer span.End()
+	if cl.maxSize > 0 {
+		return dsBoundedListPush(ctx, span, cl.collection, cl.id, ArrayPrependSpec("", val, nil), "[-1]", cl.maxSize)
+	}
 
-	return dsListPrepend(span, cl.collection, cl.id, val)
+	return dsListPrepend(ctx, span, cl.collection, cl.id, val)
 }
 
-func dsListPrepend(span RequestSpan, collection *Collection, id string, val interface{}) error {
+// dsBoundedListPush adds an item to a list using pushSpec, removing items at trimPath within the same mutation so
+// that the list holds no more than maxSize items.
+func dsBoundedListPush(ctx context.Context, span RequestSpan, collection *Collection, id string, pushSpec MutateInSpec,
+	trimPath string, maxSize uint) error {
+	for i := 0; i < 16; i++ {
+		ops := make([]LookupInSpec, 1)
+		ops[0] = CountSpec("", nil)
+		result, err := collection.LookupIn(id, ops, &LookupInOptions{
+			ParentSpan: span,
+			Context:    ctx,
+		})
+		if errors.Is(err, ErrDocumentNotFound) {
+			_, err = collection.MutateIn(id, []MutateInSpec{pushSpec}, &MutateInOptions{
+				StoreSemantic: StoreSemanticsInsert,
+				ParentSpan:    span,
+				Context:       ctx,
+			})
+			if errors.Is(err, ErrDocumentExists) {
+				continue
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		var count uint
+		err = result.ContentAt(0, &count)
+		if err != nil {
+			return err
+		}
+
+		// The server allows at most 16 operations within a single mutation.
+		mutateOps := []MutateInSpec{pushSpec}
+		for size := count + 1; size > maxSize && len(mutateOps) < 16; size-- {
+			mutateOps = append(mutateOps, RemoveSpec(trimPath, nil))
+		}
+
+		_, err = collection.MutateIn(id, mutateOps, &MutateInOptions{
+			Cas:        result.Cas(),
+			ParentSpan: span,
+			Context:    ctx,
+		})
+		if errors.Is(err, ErrCasMismatch) || errors.Is(err, ErrDocumentExists) {
+			continue
+		}
+		return err
+	}
+
+	return errors.New("failed to perform operation after 16 retries")
+}
+
+func dsListPrepend(ctx context.Context, span RequestSpan, collection *Collection, id string, val interface{}) error {
 	ops := make([]MutateInSpec, 1)
 	ops[0] = ArrayPrependSpec("", val, nil)
 	_, err := collection.MutateIn(id, ops, &MutateInOptions{
 		StoreSemantic: StoreSemanticsUpsert,
 		ParentSpan:    span,
+		Context:       ctx,
 	})
 	if err != nil {
 		return err
@@ -117,10 +238,17 @@ func dsListPrepend(span RequestSpan, collection *Collection, id string, val inte
 
 // IndexOf gets the index of the item in the list.
 func (cl *CouchbaseList) IndexOf(val interface{}) (int, error) {
+	return cl.IndexOfContext(context.Background(), val)
+}
+
+// IndexOfContext is the same as IndexOf, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) IndexOfContext(ctx context.Context, val interface{}) (int, error) {
 	span := cl.collection.startKvOpTrace("list_index_of", nil, false)
 	defer span.End()
 	content, err := cl.collection.Get(cl.id, &GetOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return 0, err
@@ -143,17 +271,24 @@ func (cl *CouchbaseList) IndexOf(val interface{}) (int, error) {
 
 // Size returns the size of the list.
 func (cl *CouchbaseList) Size() (int, error) {
+	return cl.SizeContext(context.Background())
+}
+
+// SizeContext is the same as Size, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) SizeContext(ctx context.Context) (int, error) {
 	span := cl.collection.startKvOpTrace("list_size", nil, false)
 	defer span.End()
 
-	return dsListSize(span, cl.collection, cl.id)
+	return dsListSize(ctx, span, cl.collection, cl.id)
 }
 
-func dsListSize(span RequestSpan, collection *Collection, id string) (int, error) {
+func dsListSize(ctx context.Context, span RequestSpan, collection *Collection, id string) (int, error) {
 	ops := make([]LookupInSpec, 1)
 	ops[0] = CountSpec("", nil)
 	result, err := collection.LookupIn(id, ops, &LookupInOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return 0, err
@@ -170,15 +305,22 @@ func dsListSize(span RequestSpan, collection *Collection, id string) (int, error
 
 // Clear clears a list, also removing it.
 func (cl *CouchbaseList) Clear() error {
+	return cl.ClearContext(context.Background())
+}
+
+// ClearContext is the same as Clear, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) ClearContext(ctx context.Context) error {
 	span := cl.collection.startKvOpTrace("list_clear", nil, false)
 	defer span.End()
 
-	return dsListClear(span, cl.collection, cl.id)
+	return dsListClear(ctx, span, cl.collection, cl.id)
 }
 
-func dsListClear(span RequestSpan, collection *Collection, id string) error {
+func dsListClear(ctx context.Context, span RequestSpan, collection *Collection, id string) error {
 	_, err := collection.Remove(id, &RemoveOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return err
@@ -203,10 +345,17 @@ func (c *Collection) Map(id string) *CouchbaseMap {
 
 // Iterator returns an iterable for all items in the map.
 func (cl *CouchbaseMap) Iterator() (map[string]interface{}, error) {
+	return cl.IteratorContext(context.Background())
+}
+
+// IteratorContext is the same as Iterator, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) IteratorContext(ctx context.Context) (map[string]interface{}, error) {
 	span := cl.collection.startKvOpTrace("map_iterator", nil, false)
 	defer span.End()
 	content, err := cl.collection.Get(cl.id, &GetOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return nil, err
@@ -223,12 +372,19 @@ func (cl *CouchbaseMap) Iterator() (map[string]interface{}, error) {
 
 // At retrieves the item for the given id from the map.
 func (cl *CouchbaseMap) At(id string, valuePtr interface{}) error {
+	return cl.AtContext(context.Background(), id, valuePtr)
+}
+
+// AtContext is the same as At, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) AtContext(ctx context.Context, id string, valuePtr interface{}) error {
 	span := cl.collection.startKvOpTrace("map_at", nil, false)
 	defer span.End()
 	ops := make([]LookupInSpec, 1)
 	ops[0] = GetSpec(id, nil)
 	result, err := cl.collection.LookupIn(cl.id, ops, &LookupInOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return err
@@ -239,6 +395,12 @@ func (cl *CouchbaseMap) At(id string, valuePtr interface{}) error {
 
 // Add adds an item to the map.
 func (cl *CouchbaseMap) Add(id string, val interface{}) error {
+	return cl.AddContext(context.Background(), id, val)
+}
+
+// AddContext is the same as Add, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) AddContext(ctx context.Context, id string, val interface{}) error {
 	span := cl.collection.startKvOpTrace("map_add", nil, false)
 	defer span.End()
 	ops := make([]MutateInSpec, 1)
@@ -246,6 +408,7 @@ func (cl *CouchbaseMap) Add(id string, val interface{}) error {
 	_, err := cl.collection.MutateIn(cl.id, ops, &MutateInOptions{
 		StoreSemantic: StoreSemanticsUpsert,
 		ParentSpan:    span,
+		Context:       ctx,
 	})
 	if err != nil {
 		return err
@@ -256,12 +419,19 @@ func (cl *CouchbaseMap) Add(id string, val interface{}) error {
 
 // Remove removes an item from the map.
 func (cl *CouchbaseMap) Remove(id string) error {
+	return cl.RemoveContext(context.Background(), id)
+}
+
+// RemoveContext is the same as Remove, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) RemoveContext(ctx context.Context, id string) error {
 	span := cl.collection.startKvOpTrace("map_remove", nil, false)
 	defer span.End()
 	ops := make([]MutateInSpec, 1)
 	ops[0] = RemoveSpec(id, nil)
 	_, err := cl.collection.MutateIn(cl.id, ops, &MutateInOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return err
@@ -272,11 +442,20 @@ func (cl *CouchbaseMap) Remove(id string) error {
 
 // Exists verifies whether or a id exists in the map.
 func (cl *CouchbaseMap) Exists(id string) (bool, error) {
+	return cl.ExistsContext(context.Background(), id)
+}
+
+// ExistsContext is the same as Exists, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) ExistsContext(ctx context.Context, id string) (bool, error) {
 	span := cl.collection.startKvOpTrace("map_exists", nil, false)
 	defer span.End()
 	ops := make([]LookupInSpec, 1)
 	ops[0] = ExistsSpec(id, nil)
-	result, err := cl.collection.LookupIn(cl.id, ops, nil)
+	result, err := cl.collection.LookupIn(cl.id, ops, &LookupInOptions{
+		ParentSpan: span,
+		Context:    ctx,
+	})
 	if err != nil {
 		return false, err
 	}
@@ -286,12 +465,19 @@ func (cl *CouchbaseMap) Exists(id string) (bool, error) {
 
 // Size returns the size of the map.
 func (cl *CouchbaseMap) Size() (int, error) {
+	return cl.SizeContext(context.Background())
+}
+
+// SizeContext is the same as Size, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) SizeContext(ctx context.Context) (int, error) {
 	span := cl.collection.startKvOpTrace("map_size", nil, false)
 	defer span.End()
 	ops := make([]LookupInSpec, 1)
 	ops[0] = CountSpec("", nil)
 	result, err := cl.collection.LookupIn(cl.id, ops, &LookupInOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return 0, err
@@ -308,10 +494,17 @@ func (cl *CouchbaseMap) Size() (int, error) {
 
 // Keys returns all of the keys within the map.
 func (cl *CouchbaseMap) Keys() ([]string, error) {
+	return cl.KeysContext(context.Background())
+}
+
+// KeysContext is the same as Keys, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) KeysContext(ctx context.Context) ([]string, error) {
 	span := cl.collection.startKvOpTrace("map_keys", nil, false)
 	defer span.End()
 	content, err := cl.collection.Get(cl.id, &GetOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return nil, err
@@ -333,9 +526,18 @@ func (cl *CouchbaseMap) Keys() ([]string, error) {
 
 // Values returns all of the values within the map.
 func (cl *CouchbaseMap) Values() ([]interface{}, error) {
+	return cl.ValuesContext(context.Background())
+}
+
+// ValuesContext is the same as Values, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) ValuesContext(ctx context.Context) ([]interface{}, error) {
 	span := cl.collection.startKvOpTrace("map_values", nil, false)
 	defer span.End()
-	content, err := cl.collection.Get(cl.id, nil)
+	content, err := cl.collection.Get(cl.id, &GetOptions{
+		ParentSpan: span,
+		Context:    ctx,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -356,10 +558,17 @@ func (cl *CouchbaseMap) Values() ([]interface{}, error) {
 
 // Clear clears a map, also removing it.
 func (cl *CouchbaseMap) Clear() error {
+	return cl.ClearContext(context.Background())
+}
+
+// ClearContext is the same as Clear, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) ClearContext(ctx context.Context) error {
 	span := cl.collection.startKvOpTrace("map_clear", nil, false)
 	defer span.End()
 	_, err := cl.collection.Remove(cl.id, &RemoveOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return err
@@ -384,13 +593,25 @@ func (c *Collection) Set(id string) *CouchbaseSet {
 
 // Iterator returns an iterable for all items in the set.
 func (cs *CouchbaseSet) Iterator() ([]interface{}, error) {
+	return cs.IteratorContext(context.Background())
+}
+
+// IteratorContext is the same as Iterator, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) IteratorContext(ctx context.Context) ([]interface{}, error) {
 	span := cs.collection.startKvOpTrace("set_iterator", nil, false)
 	defer span.End()
-	return dsListIterator(span, cs.collection, cs.id)
+	return dsListIterator(ctx, span, cs.collection, cs.id)
 }
 
 // Add adds a value to the set.
 func (cs *CouchbaseSet) Add(val interface{}) error {
+	return cs.AddContext(context.Background(), val)
+}
+
+// AddContext is the same as Add, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) AddContext(ctx context.Context, val interface{}) error {
 	span := cs.collection.startKvOpTrace("set_add", nil, false)
 	defer span.End()
 	ops := make([]MutateInSpec, 1)
@@ -398,6 +619,7 @@ func (cs *CouchbaseSet) Add(val interface{}) error {
 	_, err := cs.collection.MutateIn(cs.id, ops, &MutateInOptions{
 		StoreSemantic: StoreSemanticsUpsert,
 		ParentSpan:    span,
+		Context:       ctx,
 	})
 	if err != nil {
 		return err
@@ -408,11 +630,18 @@ func (cs *CouchbaseSet) Add(val interface{}) error {
 
 // Remove removes an value from the set.
 func (cs *CouchbaseSet) Remove(val string) error {
+	return cs.RemoveContext(context.Background(), val)
+}
+
+// RemoveContext is the same as Remove, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) RemoveContext(ctx context.Context, val string) error {
 	span := cs.collection.startKvOpTrace("set_remove", nil, false)
 	defer span.End()
 	for i := 0; i < 16; i++ {
 		content, err := cs.collection.Get(cs.id, &GetOptions{
 			ParentSpan: span,
+			Context:    ctx,
 		})
 		if err != nil {
 			return err
@@ -439,6 +668,7 @@ func (cs *CouchbaseSet) Remove(val string) error {
 			_, err = cs.collection.MutateIn(cs.id, ops, &MutateInOptions{
 				Cas:        cas,
 				ParentSpan: span,
+				Context:    ctx,
 			})
 			if errors.Is(err, ErrCasMismatch) || errors.Is(err, ErrDocumentExists) {
 				continue
@@ -455,10 +685,17 @@ func (cs *CouchbaseSet) Remove(val string) error {
 
 // Values returns all of the values within the set.
 func (cs *CouchbaseSet) Values() ([]interface{}, error) {
+	return cs.ValuesContext(context.Background())
+}
+
+// ValuesContext is the same as Values, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) ValuesContext(ctx context.Context) ([]interface{}, error) {
 	span := cs.collection.startKvOpTrace("set_values", nil, false)
 	defer span.End()
 	content, err := cs.collection.Get(cs.id, &GetOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return nil, err
@@ -475,10 +712,17 @@ func (cs *CouchbaseSet) Values() ([]interface{}, error) {
 
 // Contains verifies whether or not a value exists within the set.
 func (cs *CouchbaseSet) Contains(val string) (bool, error) {
+	return cs.ContainsContext(context.Background(), val)
+}
+
+// ContainsContext is the same as Contains, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) ContainsContext(ctx context.Context, val string) (bool, error) {
 	span := cs.collection.startKvOpTrace("set_contains", nil, false)
 	defer span.End()
 	content, err := cs.collection.Get(cs.id, &GetOptions{
 		ParentSpan: span,
+		Context:    ctx,
 	})
 	if err != nil {
 		return false, err
@@ -501,22 +745,35 @@ func (cs *CouchbaseSet) Contains(val string) (bool, error) {
 
 // Size returns the size of the set
 func (cs *CouchbaseSet) Size() (int, error) {
+	return cs.SizeContext(context.Background())
+}
+
+// SizeContext is the same as Size, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) SizeContext(ctx context.Context) (int, error) {
 	span := cs.collection.startKvOpTrace("set_size", nil, false)
 	defer span.End()
-	return dsListSize(span, cs.collection, cs.id)
+	return dsListSize(ctx, span, cs.collection, cs.id)
 }
 
 // Clear clears a set, also removing it.
 func (cs *CouchbaseSet) Clear() error {
+	return cs.ClearContext(context.Background())
+}
+
+// ClearContext is the same as Clear, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) ClearContext(ctx context.Context) error {
 	span := cs.collection.startKvOpTrace("set_clear", nil, false)
 	defer span.End()
-	return dsListClear(span, cs.collection, cs.id)
+	return dsListClear(ctx, span, cs.collection, cs.id)
 }
 
 // CouchbaseQueue represents a queue document.
 type CouchbaseQueue struct {
 	id         string
 	collection *Collection
+	maxSize    uint
 }
 
 // Queue returns a new CouchbaseQueue.
@@ -527,22 +784,68 @@ func (c *Collection) Queue(id string) *CouchbaseQueue {
 	}
 }
 
+// CouchbaseQueueOptions are the options available when creating a CouchbaseQueue.
+// UNCOMMITTED: This API may change in the future.
+type CouchbaseQueueOptions struct {
+	// MaxSize is the maximum number of items in the queue, 0 meaning unbounded. When an item is pushed onto a full
+	// queue then the oldest items, those which would next be popped, are dropped.
+	// The new item is pushed and the queue trimmed within a single atomic sub-document mutation, guarded by CAS.
+	// At most 15 items are removed by any one mutation, so if MaxSize is reduced the queue converges to the new size
+	// over subsequent pushes.
+	MaxSize uint
+}
+
+// QueueWithOptions returns a new CouchbaseQueue, using the provided options.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) QueueWithOptions(id string, opts *CouchbaseQueueOptions) *CouchbaseQueue {
+	if opts == nil {
+		opts = &CouchbaseQueueOptions{}
+	}
+
+	return &CouchbaseQueue{
+		id:         id,
+		collection: c,
+		maxSize:    opts.MaxSize,
+	}
+}
+
 // Iterator returns an iterable for all items in the queue.
 func (cs *CouchbaseQueue) Iterator() ([]interface{}, error) {
+	return cs.IteratorContext(context.Background())
+}
+
+// IteratorContext is the same as Iterator, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseQueue) IteratorContext(ctx context.Context) ([]interface{}, error) {
 	span := cs.collection.startKvOpTrace("queue_iterator", nil, false)
 	defer span.End()
-	return dsListIterator(span, cs.collection, cs.id)
+	return dsListIterator(ctx, span, cs.collection, cs.id)
 }
 
 // Push pushes a value onto the queue.
 func (cs *CouchbaseQueue) Push(val interface{}) error {
+	return cs.PushContext(context.Background(), val)
+}
+
+// PushContext is the same as Push, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseQueue) PushContext(ctx context.Context, val interface{}) error {
 	span := cs.collection.startKvOpTrace("queue_push", nil, false)
 	defer span.End()
-	return dsListPrepend(span, cs.collection, cs.id, val)
+	if cs.maxSize > 0 {
+		return dsBoundedListPush(ctx, span, cs.collection, cs.id, ArrayPrependSpec("", val, nil), "[-1]", cs.maxSize)
+	}
+	return dsListPrepend(ctx, span, cs.collection, cs.id, val)
 }
 
 // Pop pops an items off of the queue.
 func (cs *CouchbaseQueue) Pop(valuePtr interface{}) error {
+	return cs.PopContext(context.Background(), valuePtr)
+}
+
+// PopContext is the same as Pop, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseQueue) PopContext(ctx context.Context, valuePtr interface{}) error {
 	span := cs.collection.startKvOpTrace("queue_pop", nil, false)
 	defer span.End()
 	for i := 0; i < 16; i++ {
@@ -550,6 +853,7 @@ func (cs *CouchbaseQueue) Pop(valuePtr interface{}) error {
 		ops[0] = GetSpec("[-1]", nil)
 		content, err := cs.collection.LookupIn(cs.id, ops, &LookupInOptions{
 			ParentSpan: span,
+			Context:    ctx,
 		})
 		if err != nil {
 			return err
@@ -566,6 +870,7 @@ func (cs *CouchbaseQueue) Pop(valuePtr interface{}) error {
 		_, err = cs.collection.MutateIn(cs.id, mutateOps, &MutateInOptions{
 			Cas:        cas,
 			ParentSpan: span,
+			Context:    ctx,
 		})
 		if errors.Is(err, ErrCasMismatch) || errors.Is(err, ErrDocumentExists) {
 			continue
@@ -579,16 +884,76 @@ func (cs *CouchbaseQueue) Pop(valuePtr interface{}) error {
 	return errors.New("failed to perform operation after 16 retries")
 }
 
+// Peek retrieves the item which would next be popped from the queue into valuePtr, without removing it.
+// The item is a snapshot and may already have been popped by another client by the time Peek returns.
+func (cs *CouchbaseQueue) Peek(valuePtr interface{}) error {
+	return cs.PeekContext(context.Background(), valuePtr)
+}
+
+// PeekContext is the same as Peek, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseQueue) PeekContext(ctx context.Context, valuePtr interface{}) error {
+	span := cs.collection.startKvOpTrace("queue_peek", nil, false)
+	defer span.End()
+	ops := make([]LookupInSpec, 1)
+	ops[0] = GetSpec("[-1]", nil)
+	content, err := cs.collection.LookupIn(cs.id, ops, &LookupInOptions{
+		ParentSpan: span,
+		Context:    ctx,
+	})
+	if err != nil {
+		return err
+	}
+
+	return content.ContentAt(0, valuePtr)
+}
+
+// Contents retrieves all of the items in the queue into valuePtr, which should be a pointer to a slice, using a
+// single read and without removing anything. Items are ordered from most recently pushed to least recently pushed,
+// so the last item is the next to be popped.
+// The contents are a snapshot and may be stale as soon as Contents returns.
+func (cs *CouchbaseQueue) Contents(valuePtr interface{}) error {
+	return cs.ContentsContext(context.Background(), valuePtr)
+}
+
+// ContentsContext is the same as Contents, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseQueue) ContentsContext(ctx context.Context, valuePtr interface{}) error {
+	span := cs.collection.startKvOpTrace("queue_contents", nil, false)
+	defer span.End()
+	content, err := cs.collection.Get(cs.id, &GetOptions{
+		ParentSpan: span,
+		Context:    ctx,
+	})
+	if err != nil {
+		return err
+	}
+
+	return content.Content(valuePtr)
+}
+
 // Size returns the size of the queue.
 func (cs *CouchbaseQueue) Size() (int, error) {
+	return cs.SizeContext(context.Background())
+}
+
+// SizeContext is the same as Size, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseQueue) SizeContext(ctx context.Context) (int, error) {
 	span := cs.collection.startKvOpTrace("queue_size", nil, false)
 	defer span.End()
-	return dsListSize(span, cs.collection, cs.id)
+	return dsListSize(ctx, span, cs.collection, cs.id)
 }
 
 // Clear clears a queue, also removing it.
 func (cs *CouchbaseQueue) Clear() error {
+	return cs.ClearContext(context.Background())
+}
+
+// ClearContext is the same as Clear, cancelling ctx aborts the operation.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseQueue) ClearContext(ctx context.Context) error {
 	span := cs.collection.startKvOpTrace("queue_clear", nil, false)
 	defer span.End()
-	return dsListClear(span, cs.collection, cs.id)
+	return dsListClear(ctx, span, cs.collection, cs.id)
 }
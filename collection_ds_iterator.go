@@ -0,0 +1,243 @@
+package gocb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// dsIteratorPageSize is the number of items fetched by each request of a ListIterator, the server allows at most 16
+// operations within a single lookup.
+const dsIteratorPageSize = 16
+
+// ListIterator iterates over the items of a list or set document, fetching them in pages using sub-document
+// lookups so that the whole document is never held in memory at once.
+// The first page records the CAS of the document, if the document is modified whilst it is being iterated then Next
+// returns false and Err returns ErrCasMismatch, rather than returning items from different versions of the document.
+// Each page is fetched using the context which the iterator was created with, so cancelling it stops the iteration.
+// UNCOMMITTED: This API may change in the future.
+type ListIterator struct {
+	collection *Collection
+	id         string
+	ctx        context.Context
+
+	cas  Cas
+	size uint
+
+	page      *LookupInResult
+	pageStart uint
+	pageSpecs uint
+	pageLen   uint
+
+	index uint
+	err   error
+}
+
+func newListIterator(ctx context.Context, collection *Collection, id string) (*ListIterator, error) {
+	it := &ListIterator{
+		collection: collection,
+		id:         id,
+		ctx:        ctx,
+	}
+
+	// The first page also counts the items, so that we know when to stop.
+	specs := []LookupInSpec{CountSpec("", nil)}
+	for i := uint(0); i < dsIteratorPageSize-1; i++ {
+		specs = append(specs, GetSpec(fmt.Sprintf("[%d]", i), nil))
+	}
+
+	result, err := collection.LookupIn(id, specs, &LookupInOptions{
+		Context: ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = result.ContentAt(0, &it.size)
+	if err != nil {
+		return nil, err
+	}
+
+	it.cas = result.Cas()
+	it.page = result
+	it.pageSpecs = 1
+	it.pageLen = dsIteratorPageSize - 1
+
+	return it, nil
+}
+
+// Next decodes the next item of the list into valuePtr, returning false once all of the items have been read or an
+// error occurs, in which case Err should be checked.
+func (it *ListIterator) Next(valuePtr interface{}) bool {
+	if it.err != nil || it.index >= it.size {
+		return false
+	}
+
+	if it.index >= it.pageStart+it.pageLen {
+		if err := it.fetchPage(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	err := it.page.ContentAt(it.pageSpecs+it.index-it.pageStart, valuePtr)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.index++
+	return true
+}
+
+func (it *ListIterator) fetchPage() error {
+	var specs []LookupInSpec
+	for i := it.index; i < it.size && len(specs) < dsIteratorPageSize; i++ {
+		specs = append(specs, GetSpec(fmt.Sprintf("[%d]", i), nil))
+	}
+
+	result, err := it.collection.LookupIn(it.id, specs, &LookupInOptions{
+		Context: it.ctx,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Cas() != it.cas {
+		return wrapError(ErrCasMismatch, "document was modified during iteration")
+	}
+
+	it.page = result
+	it.pageStart = it.index
+	it.pageSpecs = 0
+	it.pageLen = uint(len(specs))
+
+	return nil
+}
+
+// Err returns the error which caused Next to return false, if any.
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// MapIterator iterates over the entries of a map document, decoding each value only as it is read.
+// Sub-document lookups cannot list the keys of a map, so unlike ListIterator the whole document is fetched when the
+// iterator is created. All of the entries therefore come from the same version of the document, and only the raw
+// document is held in memory rather than every decoded value, but a MapIterator does not reduce the size of the
+// document fetched from the server.
+// UNCOMMITTED: This API may change in the future.
+type MapIterator struct {
+	decoder *json.Decoder
+	key     string
+	err     error
+	done    bool
+}
+
+func newMapIterator(ctx context.Context, collection *Collection, id string) (*MapIterator, error) {
+	content, err := collection.Get(id, &GetOptions{
+		Context: ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The entries are decoded straight from the fetched bytes, rather than from a copy made by the transcoder.
+	decoder := json.NewDecoder(bytes.NewReader(content.contents))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("map document is not a JSON object")
+	}
+
+	return &MapIterator{
+		decoder: decoder,
+	}, nil
+}
+
+// Next decodes the value of the next entry of the map into valuePtr, the key of the entry is available from Key.
+// Next returns false once all of the entries have been read or an error occurs, in which case Err should be checked.
+func (it *MapIterator) Next(valuePtr interface{}) bool {
+	if it.err != nil || it.done {
+		return false
+	}
+
+	if !it.decoder.More() {
+		it.done = true
+		return false
+	}
+
+	token, err := it.decoder.Token()
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	key, ok := token.(string)
+	if !ok {
+		it.err = errors.New("map document has an invalid key")
+		return false
+	}
+
+	err = it.decoder.Decode(valuePtr)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.key = key
+	return true
+}
+
+// Key returns the key of the entry most recently read by Next.
+func (it *MapIterator) Key() string {
+	return it.key
+}
+
+// Err returns the error which caused Next to return false, if any.
+func (it *MapIterator) Err() error {
+	return it.err
+}
+
+// Stream returns an iterator which fetches the items of the list in pages as they are read, rather than fetching
+// the whole list at once as Iterator does.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) Stream() (*ListIterator, error) {
+	return cl.StreamContext(context.Background())
+}
+
+// StreamContext is the same as Stream, cancelling ctx aborts fetching the items.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseList) StreamContext(ctx context.Context) (*ListIterator, error) {
+	return newListIterator(ctx, cl.collection, cl.id)
+}
+
+// Stream returns an iterator which fetches the items of the set in pages as they are read, rather than fetching
+// the whole set at once as Iterator does.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) Stream() (*ListIterator, error) {
+	return cs.StreamContext(context.Background())
+}
+
+// StreamContext is the same as Stream, cancelling ctx aborts fetching the items.
+// UNCOMMITTED: This API may change in the future.
+func (cs *CouchbaseSet) StreamContext(ctx context.Context) (*ListIterator, error) {
+	return newListIterator(ctx, cs.collection, cs.id)
+}
+
+// Stream returns an iterator which decodes the entries of the map as they are read, rather than decoding every
+// entry at once as Iterator does. The whole document is still fetched, see MapIterator.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) Stream() (*MapIterator, error) {
+	return cl.StreamContext(context.Background())
+}
+
+// StreamContext is the same as Stream, cancelling ctx aborts fetching the document.
+// UNCOMMITTED: This API may change in the future.
+func (cl *CouchbaseMap) StreamContext(ctx context.Context) (*MapIterator, error) {
+	return newMapIterator(ctx, cl.collection, cl.id)
+}
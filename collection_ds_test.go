@@ -1,5 +1,16 @@
 package gocb
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
+)
+
 func (suite *IntegrationTestSuite) TestListCrud() {
 	suite.skipIfUnsupported(KeyValueFeature)
 
@@ -252,6 +263,24 @@ func (suite *IntegrationTestSuite) TestQueueCrud() {
 		suite.T().Fatalf("Expected queue size to be 4 but was %d", size)
 	}
 
+	var peeked string
+	err = queue.Peek(&peeked)
+	if err != nil {
+		suite.T().Fatalf("Failed to peek queue %v", err)
+	}
+
+	if peeked != "test1" {
+		suite.T().Fatalf("Expected test1 to be peeked but was %s", peeked)
+	}
+
+	var contents []string
+	err = queue.Contents(&contents)
+	if err != nil {
+		suite.T().Fatalf("Failed to get contents of queue %v", err)
+	}
+
+	suite.Assert().Equal([]string{"test4", "test3", "test2", "test1"}, contents)
+
 	iter, err := queue.Iterator()
 	if err != nil {
 		suite.T().Fatalf("Failed to get iterator for queue %v", err)
@@ -401,3 +430,206 @@ func (suite *IntegrationTestSuite) TestMapCrud() {
 		suite.T().Fatalf("Failed to clear map %v", err)
 	}
 }
+
+func (suite *UnitTestSuite) TestQueueBoundedPush() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var lookupErr error
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			suite.Require().Len(opts.Ops, 1)
+			suite.Assert().Equal(memd.SubDocOpGetCount, opts.Ops[0].Op)
+
+			if lookupErr != nil {
+				cb(nil, lookupErr)
+				return
+			}
+
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{
+					{Value: []byte("4")},
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	var mutateOpts gocbcore.MutateInOptions
+	provider.
+		On("MutateIn", mock.AnythingOfType("gocbcore.MutateInOptions"), mock.AnythingOfType("gocbcore.MutateInCallback")).
+		Run(func(args mock.Arguments) {
+			mutateOpts = args.Get(0).(gocbcore.MutateInOptions)
+			cb := args.Get(1).(gocbcore.MutateInCallback)
+
+			cb(&gocbcore.MutateInResult{
+				Cas: gocbcore.Cas(124),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+	queue := col.QueueWithOptions("queue", &CouchbaseQueueOptions{MaxSize: 3})
+
+	err := queue.Push("item")
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal(gocbcore.Cas(123), mutateOpts.Cas)
+	suite.Require().Len(mutateOpts.Ops, 3)
+	suite.Assert().Equal(memd.SubDocOpArrayPushFirst, mutateOpts.Ops[0].Op)
+	suite.Assert().Equal([]byte(`"item"`), mutateOpts.Ops[0].Value)
+	for _, op := range mutateOpts.Ops[1:] {
+		suite.Assert().Equal(memd.SubDocOpDelete, op.Op)
+		suite.Assert().Equal("[-1]", op.Path)
+	}
+
+	lookupErr = &gocbcore.KeyValueError{
+		InnerError: gocbcore.ErrDocumentNotFound,
+	}
+	err = queue.Push("item")
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Zero(mutateOpts.Cas)
+	suite.Assert().Equal(memd.SubdocDocFlagAddDoc, mutateOpts.Flags)
+	suite.Require().Len(mutateOpts.Ops, 1)
+	suite.Assert().Equal(memd.SubDocOpArrayPushFirst, mutateOpts.Ops[0].Op)
+}
+
+func (suite *UnitTestSuite) TestListStream() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var items []int
+	for i := 0; i < 40; i++ {
+		items = append(items, i)
+	}
+
+	var lookups int
+	cas := gocbcore.Cas(123)
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+			lookups++
+
+			suite.Require().LessOrEqual(len(opts.Ops), 16)
+
+			var results []gocbcore.SubDocResult
+			for _, op := range opts.Ops {
+				if op.Op == memd.SubDocOpGetCount {
+					results = append(results, gocbcore.SubDocResult{Value: []byte(strconv.Itoa(len(items)))})
+					continue
+				}
+
+				var idx int
+				_, err := fmt.Sscanf(op.Path, "[%d]", &idx)
+				suite.Require().Nil(err, err)
+				if idx >= len(items) {
+					results = append(results, gocbcore.SubDocResult{Err: gocbcore.ErrPathNotFound})
+					continue
+				}
+
+				results = append(results, gocbcore.SubDocResult{Value: []byte(strconv.Itoa(items[idx]))})
+			}
+
+			cb(&gocbcore.LookupInResult{
+				Cas: cas,
+				Ops: results,
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	iter, err := col.List("list").Stream()
+	suite.Require().Nil(err, err)
+
+	var read []int
+	var item int
+	for iter.Next(&item) {
+		read = append(read, item)
+	}
+	suite.Require().Nil(iter.Err())
+	suite.Assert().Equal(items, read)
+	suite.Assert().Equal(3, lookups)
+
+	iter, err = col.Set("set").Stream()
+	suite.Require().Nil(err, err)
+
+	read = nil
+	for iter.Next(&item) {
+		read = append(read, item)
+		if len(read) == 15 {
+			// Modify the document before the second page is fetched.
+			cas++
+		}
+	}
+	suite.Assert().Len(read, 15)
+	if !errors.Is(iter.Err(), ErrCasMismatch) {
+		suite.T().Fatalf("Expected cas mismatch error but was %v", iter.Err())
+	}
+}
+
+func (suite *IntegrationTestSuite) TestMapStream() {
+	suite.skipIfUnsupported(KeyValueFeature)
+
+	cMap := globalCollection.Map("testMapStream")
+	expected := map[string]string{
+		"key1": "value1",
+		"key2": "value2",
+		"key3": "value3",
+	}
+	for k, v := range expected {
+		err := cMap.Add(k, v)
+		suite.Require().Nil(err, err)
+	}
+
+	iter, err := cMap.Stream()
+	suite.Require().Nil(err, err)
+
+	actual := make(map[string]string)
+	var value string
+	for iter.Next(&value) {
+		actual[iter.Key()] = value
+	}
+	suite.Require().Nil(iter.Err())
+	suite.Assert().Equal(expected, actual)
+}
+
+func (suite *UnitTestSuite) TestDatastructureContextCanceled() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.On("Cancel").Return()
+
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			cb(nil, gocbcore.ErrRequestCanceled)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := col.List("list").SizeContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		suite.T().Fatalf("Expected error to be context canceled but was %v", err)
+	}
+	suite.Assert().True(errors.Is(err, ErrRequestCanceled))
+
+	_, err = col.Map("map").ExistsContext(ctx, "key")
+	if !errors.Is(err, context.Canceled) {
+		suite.T().Fatalf("Expected error to be context canceled but was %v", err)
+	}
+}
@@ -18,6 +18,39 @@ func (c *Collection) observeOnceSeqNo(
 	timeout time.Duration,
 	user string,
 ) (didReplicate, didPersist bool, errOut error) {
+	observeFn := func() (observeVbSeqNosResult, error) {
+		return c.observeVbOnce(ctx, trace, docID, mt, replicaIdx, cancelCh, timeout, user)
+	}
+
+	var res observeVbSeqNosResult
+	if c.bucket != nil && c.bucket.observeBatcher != nil {
+		res, errOut = c.bucket.observeBatcher.observe(observeVbKey{
+			bucketName: c.bucketName(),
+			vbID:       mt.VbID,
+			vbUUID:     mt.VbUUID,
+			replicaIdx: replicaIdx,
+			user:       user,
+		}, cancelCh, observeFn)
+	} else {
+		res, errOut = observeFn()
+	}
+	if errOut != nil {
+		return false, false, errOut
+	}
+
+	return res.currentSeqNo >= mt.SeqNo, res.persistSeqNo >= mt.SeqNo, nil
+}
+
+func (c *Collection) observeVbOnce(
+	ctx context.Context,
+	trace RequestSpan,
+	docID string,
+	mt gocbcore.MutationToken,
+	replicaIdx int,
+	cancelCh chan struct{},
+	timeout time.Duration,
+	user string,
+) (resOut observeVbSeqNosResult, errOut error) {
 	opm := c.newKvOpManager("observe_once", trace)
 	defer opm.Finish(true)
 
@@ -29,7 +62,7 @@ func (c *Collection) observeOnceSeqNo(
 
 	agent, err := c.getKvProvider()
 	if err != nil {
-		return false, false, err
+		return observeVbSeqNosResult{}, err
 	}
 	err = opm.Wait(agent.ObserveVb(gocbcore.ObserveVbOptions{
 		VbID:         mt.VbID,
@@ -45,12 +78,14 @@ func (c *Collection) observeOnceSeqNo(
 			return
 		}
 
-		didReplicate = res.CurrentSeqNo >= mt.SeqNo
-		didPersist = res.PersistSeqNo >= mt.SeqNo
+		resOut = observeVbSeqNosResult{
+			currentSeqNo: res.CurrentSeqNo,
+			persistSeqNo: res.PersistSeqNo,
+		}
 
 		opm.Resolve(nil)
 	}))
-	if err == nil {
+	if err != nil {
 		errOut = err
 	}
 	return
@@ -120,9 +155,11 @@ func (c *Collection) waitForDurability(
 	mt gocbcore.MutationToken,
 	replicateTo uint,
 	persistTo uint,
+	level DurabilityLevel,
 	deadline time.Time,
 	cancelCh chan struct{},
 	user string,
+	mode DurabilityMode,
 ) error {
 	opm := c.newKvOpManager("observe", trace)
 	defer opm.Finish(true)
@@ -144,9 +181,43 @@ func (c *Collection) waitForDurability(
 		return err
 	}
 
+	return c.observeDurability(ctx, opm, docID, mt, replicateTo, persistTo, level, numReplicas, deadline, cancelCh, user,
+		mode)
+}
+
+// observeDurability polls the active and replica copies of a document until the given durability requirements, or
+// the requirements equivalent to level, are met by the mutation identified by mt.
+func (c *Collection) observeDurability(
+	ctx context.Context,
+	opm *kvOpManager,
+	docID string,
+	mt gocbcore.MutationToken,
+	replicateTo uint,
+	persistTo uint,
+	level DurabilityLevel,
+	numReplicas int,
+	deadline time.Time,
+	cancelCh chan struct{},
+	user string,
+	mode DurabilityMode,
+) error {
+	if level > DurabilityLevelNone {
+		persistTo, replicateTo = observeDurabilityRequirements(level, numReplicas)
+	}
+
 	numServers := numReplicas + 1
 	if replicateTo > uint(numServers-1) || persistTo > uint(numServers) {
-		return opm.EnhanceErr(ErrDurabilityImpossible)
+		if mode != DurabilityModeBestEffort {
+			return opm.EnhanceErr(ErrDurabilityImpossible)
+		}
+
+		logInfof("Durability requirements could not be met, reducing them to the number of nodes due to best effort mode")
+		if replicateTo > uint(numServers-1) {
+			replicateTo = uint(numServers - 1)
+		}
+		if persistTo > uint(numServers) {
+			persistTo = uint(numServers)
+		}
 	}
 
 	subOpCancelCh := make(chan struct{}, 1)
@@ -178,6 +249,10 @@ func (c *Collection) waitForDurability(
 			// deadline exceeded
 			close(subOpCancelCh)
 			wg.Wait()
+			if level > DurabilityLevelNone {
+				return wrapError(ErrDurabilityAmbiguous,
+					"mutation was applied but its durability level could not be observed before the deadline")
+			}
 			return opm.EnhanceErr(ErrAmbiguousTimeout)
 		case <-cancelCh:
 			// parent asked for cancellation
@@ -193,3 +268,42 @@ func (c *Collection) waitForDurability(
 		}
 	}
 }
+
+// observeDurabilityRequirements returns the observe based durability requirements which are equivalent to the given
+// synchronous durability level, where a majority is more than half of the active and replica copies.
+func observeDurabilityRequirements(level DurabilityLevel, numReplicas int) (persistTo, replicateTo uint) {
+	majority := uint((numReplicas+1)/2 + 1)
+
+	switch level {
+	case DurabilityLevelMajority:
+		return 0, majority - 1
+	case DurabilityLevelMajorityAndPersistOnMaster:
+		return 1, majority - 1
+	case DurabilityLevelPersistToMajority:
+		return majority, 0
+	default:
+		return 0, 0
+	}
+}
+
+// useObserveDurabilityFallback returns whether mutations using a synchronous durability level should instead have
+// their durability observed, because fallback is enabled and the bucket does not support synchronous durability.
+// Support is unknown until the bucket configuration has been received, rejected is set once the server has rejected
+// a synchronous durability level so that unknown support is treated as no support.
+func (c *Collection) useObserveDurabilityFallback(rejected bool) bool {
+	if c.bucket == nil || !c.bucket.durabilityFallback {
+		return false
+	}
+
+	provider, err := c.bucket.getKvCapabilitiesProvider()
+	if err != nil {
+		return false
+	}
+
+	status := provider.BucketCapabilityStatus(gocbcore.BucketCapabilityDurableWrites)
+	if rejected {
+		return status != gocbcore.BucketCapabilityStatusSupported
+	}
+
+	return status == gocbcore.BucketCapabilityStatusUnsupported
+}
@@ -0,0 +1,82 @@
+package gocb
+
+import (
+	"sync"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+// observeVbKey identifies the observe requests which can share a single response. The response to an observe is the
+// current state of the vbucket on a node, so it answers every pending durability check against that vbucket.
+type observeVbKey struct {
+	bucketName string
+	vbID       uint16
+	vbUUID     gocbcore.VbUUID
+	replicaIdx int
+	user       string
+}
+
+type observeVbSeqNosResult struct {
+	currentSeqNo gocbcore.SeqNo
+	persistSeqNo gocbcore.SeqNo
+}
+
+type observeVbBatch struct {
+	done   chan struct{}
+	result observeVbSeqNosResult
+	err    error
+}
+
+// observeBatcher batches the observe requests made when waiting for PersistTo and ReplicateTo durability, so that
+// concurrent durable mutations to the same vbucket send a single observe to each node rather than one each.
+// Whilst an observe is in flight for a vbucket any other requests to observe that vbucket wait for, and use, its
+// response instead of sending their own.
+type observeBatcher struct {
+	lock     sync.Mutex
+	inflight map[observeVbKey]*observeVbBatch
+}
+
+func newObserveBatcher() *observeBatcher {
+	return &observeBatcher{
+		inflight: make(map[observeVbKey]*observeVbBatch),
+	}
+}
+
+// observe returns the result of the observe in flight for the key, if there is one, or otherwise sends an observe using
+// observeFn. If the shared observe fails then observeFn is used to send an observe for this request alone, as the
+// failure may have been caused by the request which sent it being canceled.
+func (b *observeBatcher) observe(key observeVbKey, cancelCh chan struct{},
+	observeFn func() (observeVbSeqNosResult, error)) (observeVbSeqNosResult, error) {
+	b.lock.Lock()
+	batch, ok := b.inflight[key]
+	if !ok {
+		batch = &observeVbBatch{
+			done: make(chan struct{}),
+		}
+		b.inflight[key] = batch
+	}
+	b.lock.Unlock()
+
+	if ok {
+		select {
+		case <-batch.done:
+		case <-cancelCh:
+			return observeVbSeqNosResult{}, ErrRequestCanceled
+		}
+
+		if batch.err == nil {
+			return batch.result, nil
+		}
+
+		return observeFn()
+	}
+
+	batch.result, batch.err = observeFn()
+
+	b.lock.Lock()
+	delete(b.inflight, key)
+	b.lock.Unlock()
+	close(batch.done)
+
+	return batch.result, batch.err
+}
@@ -0,0 +1,131 @@
+package gocb
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func (suite *UnitTestSuite) TestObserveBatcher() {
+	batcher := newObserveBatcher()
+	key := observeVbKey{bucketName: "default", vbID: 12, vbUUID: 1234}
+
+	var numObserves uint32
+	releaseCh := make(chan struct{})
+	observeFn := func() (observeVbSeqNosResult, error) {
+		atomic.AddUint32(&numObserves, 1)
+		<-releaseCh
+		return observeVbSeqNosResult{currentSeqNo: 10, persistSeqNo: 8}, nil
+	}
+
+	// The first observe becomes the shared observe, wait for it to be in flight before starting the others.
+	var wg sync.WaitGroup
+	results := make([]observeVbSeqNosResult, 20)
+	errs := make([]error, 20)
+	wg.Add(1)
+	go func() {
+		results[0], errs[0] = batcher.observe(key, nil, observeFn)
+		wg.Done()
+	}()
+	for atomic.LoadUint32(&numObserves) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			results[i], errs[i] = batcher.observe(key, nil, observeFn)
+			wg.Done()
+		}(i)
+	}
+	// Give the other observes time to join the shared observe.
+	time.Sleep(50 * time.Millisecond)
+	close(releaseCh)
+	wg.Wait()
+
+	suite.Assert().Equal(uint32(1), atomic.LoadUint32(&numObserves))
+	for i := range results {
+		suite.Require().Nil(errs[i], errs[i])
+		suite.Assert().Equal(observeVbSeqNosResult{currentSeqNo: 10, persistSeqNo: 8}, results[i])
+	}
+	suite.Assert().Empty(batcher.inflight)
+
+	// A different replica is observed separately.
+	otherKey := key
+	otherKey.replicaIdx = 1
+	_, err := batcher.observe(otherKey, nil, func() (observeVbSeqNosResult, error) {
+		atomic.AddUint32(&numObserves, 1)
+		return observeVbSeqNosResult{}, nil
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(2), atomic.LoadUint32(&numObserves))
+}
+
+func (suite *UnitTestSuite) TestObserveBatcherSharedObserveFails() {
+	batcher := newObserveBatcher()
+	key := observeVbKey{bucketName: "default", vbID: 12, vbUUID: 1234}
+
+	startedCh := make(chan struct{})
+	releaseCh := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := batcher.observe(key, nil, func() (observeVbSeqNosResult, error) {
+			close(startedCh)
+			<-releaseCh
+			return observeVbSeqNosResult{}, ErrRequestCanceled
+		})
+		errCh <- err
+	}()
+	<-startedCh
+
+	resCh := make(chan observeVbSeqNosResult, 1)
+	go func() {
+		res, err := batcher.observe(key, nil, func() (observeVbSeqNosResult, error) {
+			return observeVbSeqNosResult{currentSeqNo: 5}, nil
+		})
+		suite.Assert().Nil(err, err)
+		resCh <- res
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(releaseCh)
+
+	suite.Assert().True(errors.Is(<-errCh, ErrRequestCanceled))
+	suite.Assert().Equal(observeVbSeqNosResult{currentSeqNo: 5}, <-resCh)
+
+	// Waiting for a shared observe can be canceled.
+	batcher.inflight[key] = &observeVbBatch{done: make(chan struct{})}
+	cancelCh := make(chan struct{})
+	close(cancelCh)
+	_, err := batcher.observe(key, cancelCh, nil)
+	suite.Assert().True(errors.Is(err, ErrRequestCanceled))
+}
+
+// BenchmarkObserveBatcher reports the number of observes sent per durability check when many durable mutations to
+// the same vbucket are waiting for durability at once.
+func BenchmarkObserveBatcher(b *testing.B) {
+	batcher := newObserveBatcher()
+	key := observeVbKey{bucketName: "default", vbID: 12, vbUUID: 1234}
+
+	var numObserves uint32
+	observeFn := func() (observeVbSeqNosResult, error) {
+		atomic.AddUint32(&numObserves, 1)
+		// Simulate the network round trip of the observe.
+		time.Sleep(100 * time.Microsecond)
+		return observeVbSeqNosResult{currentSeqNo: 10, persistSeqNo: 10}, nil
+	}
+
+	b.SetParallelism(32)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, err := batcher.observe(key, nil, observeFn)
+			if err != nil {
+				b.Errorf("observe failed: %v", err)
+			}
+		}
+	})
+
+	b.ReportMetric(float64(atomic.LoadUint32(&numObserves))/float64(b.N), "observes/op")
+}
@@ -0,0 +1,92 @@
+package gocb
+
+import (
+	"context"
+	"time"
+)
+
+// GetMultiOptions are the set of options available to the GetMulti operation.
+// UNCOMMITTED: This API may change in the future.
+type GetMultiOptions struct {
+	// Timeout applies to the GetMulti call as a whole rather than to each document. Defaults to the KV timeout
+	// multiplied by the number of documents.
+	Timeout       time.Duration
+	Transcoder    Transcoder
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// GetMultiResult is the result of fetching a single document as part of GetMulti. Exactly one of Result and Err
+// is set.
+// UNCOMMITTED: This API may change in the future.
+type GetMultiResult struct {
+	Result *GetResult
+	Err    error
+}
+
+// Content assigns the value of the document to valuePtr, returning Err if the document could not be fetched.
+func (r GetMultiResult) Content(valuePtr interface{}) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	return r.Result.Content(valuePtr)
+}
+
+// GetMulti fetches the documents with the given ids, returning a map containing an entry for every id.
+// The documents are fetched using the same pipeline as Do, so each fetch succeeds or fails independently and an error
+// such as ErrDocumentNotFound is reported on the entry for that id rather than failing the whole call. An error is
+// only returned if the documents could not be requested at all.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) GetMulti(ids []string, opts *GetMultiOptions) (map[string]GetMultiResult, error) {
+	if opts == nil {
+		opts = &GetMultiOptions{}
+	}
+
+	results := make(map[string]GetMultiResult, len(ids))
+	ops := make([]BulkOp, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := results[id]; ok {
+			continue
+		}
+
+		if id == "" {
+			results[id] = GetMultiResult{Err: makeInvalidArgumentsError("id cannot be empty")}
+			continue
+		}
+
+		// Placeholder so that duplicate ids are only fetched once.
+		results[id] = GetMultiResult{}
+		ops = append(ops, &GetOp{ID: id})
+	}
+
+	if len(ops) == 0 {
+		return results, nil
+	}
+
+	err := c.Do(ops, &BulkOpOptions{
+		Timeout:       opts.Timeout,
+		Transcoder:    opts.Transcoder,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		getOp := op.(*GetOp)
+		results[getOp.ID] = GetMultiResult{
+			Result: getOp.Result,
+			Err:    getOp.Err,
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,183 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	defaultHistoryPath       = "history"
+	defaultHistoryMaxEntries = 10
+	defaultHistoryCasRetries = 10
+
+	// The server allows at most 16 specs in a single MutateIn, one of which is the history append and one the body.
+	maxHistoryTrimSpecs = 14
+)
+
+// ReplaceWithHistoryOptions are the options available to the ReplaceWithHistory operation.
+// UNCOMMITTED: This API may change in the future.
+type ReplaceWithHistoryOptions struct {
+	// HistoryPath is the extended attribute used to hold the history array, defaults to "history".
+	HistoryPath string
+
+	// MaxEntries is the maximum number of previous versions to retain, defaults to 10.
+	MaxEntries uint32
+
+	// CasRetries is the number of times that the operation is retried if the document is concurrently modified
+	// between being read and written, defaults to 10.
+	CasRetries uint32
+
+	Expiry          time.Duration
+	PreserveExpiry  bool
+	PersistTo       uint
+	ReplicateTo     uint
+	DurabilityLevel DurabilityLevel
+	Transcoder      Transcoder
+	Timeout         time.Duration
+	RetryStrategy   RetryStrategy
+	ParentSpan      RequestSpan
+
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// ReplaceWithHistory replaces the body of the document identified by id with val, appending the previous body to a
+// history array held within an extended attribute and trimming that array so that it holds at most MaxEntries of the
+// most recent versions, oldest first.
+//
+// The current body is read using LookupIn and then the append, trim and replace are applied within a single
+// MutateIn using the CAS of that read, so the history can never miss or duplicate a version. If the document is
+// modified concurrently then the whole sequence is retried, up to CasRetries times, after which ErrCasMismatch is
+// returned. Timeout applies to each individual read and write.
+//
+// Only 14 entries can be trimmed in any one call, so if MaxEntries is reduced significantly the history converges
+// to the new size over several calls.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) ReplaceWithHistory(id string, val interface{}, opts *ReplaceWithHistoryOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &ReplaceWithHistoryOptions{}
+	}
+
+	if opts.Expiry > 0 && opts.PreserveExpiry {
+		return nil, makeInvalidArgumentsError("cannot use preserve expiry with expiry")
+	}
+
+	historyPath := opts.HistoryPath
+	if historyPath == "" {
+		historyPath = defaultHistoryPath
+	}
+
+	maxEntries := opts.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = defaultHistoryMaxEntries
+	}
+
+	casRetries := opts.CasRetries
+	if casRetries == 0 {
+		casRetries = defaultHistoryCasRetries
+	}
+
+	transcoder := opts.Transcoder
+	if transcoder == nil {
+		transcoder = c.transcoder
+	}
+
+	value, _, err := transcoder.Encode(val)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(value) {
+		return nil, makeInvalidArgumentsError("value must encode to JSON to be stored with history")
+	}
+
+	expiry := opts.Expiry
+	if !opts.PreserveExpiry {
+		expiry = c.expiryOrDefault(expiry)
+	}
+
+	for attempt := uint32(0); ; attempt++ {
+		res, err := c.replaceWithHistoryAttempt(id, json.RawMessage(value), historyPath, maxEntries, expiry, opts)
+		if err == nil {
+			return &res.MutationResult, nil
+		}
+
+		if !errors.Is(err, ErrDocumentExists) {
+			return nil, err
+		}
+
+		if attempt+1 >= casRetries {
+			return nil, wrapError(ErrCasMismatch, "document was concurrently modified")
+		}
+	}
+}
+
+func (c *Collection) replaceWithHistoryAttempt(id string, value json.RawMessage, historyPath string, maxEntries uint32,
+	expiry time.Duration, opts *ReplaceWithHistoryOptions) (*MutateInResult, error) {
+	lookupRes, err := c.LookupIn(id, []LookupInSpec{
+		GetSpec("", nil),
+		CountSpec(historyPath, &CountSpecOptions{IsXattr: true}),
+	}, &LookupInOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var current json.RawMessage
+	err = lookupRes.ContentAt(0, &current)
+	if err != nil {
+		return nil, err
+	}
+
+	var historyLen uint32
+	err = lookupRes.ContentAt(1, &historyLen)
+	if err != nil && !errors.Is(err, ErrPathNotFound) {
+		return nil, err
+	}
+
+	specs := []MutateInSpec{
+		ArrayAppendSpec(historyPath, current, &ArrayAppendSpecOptions{
+			CreatePath: true,
+			IsXattr:    true,
+		}),
+	}
+
+	trim := 0
+	if historyLen+1 > maxEntries {
+		trim = int(historyLen + 1 - maxEntries)
+	}
+	if trim > maxHistoryTrimSpecs {
+		trim = maxHistoryTrimSpecs
+	}
+	for i := 0; i < trim; i++ {
+		specs = append(specs, RemoveSpec(historyPath+"[0]", &RemoveSpecOptions{IsXattr: true}))
+	}
+
+	specs = append(specs, ReplaceSpec("", value, nil))
+
+	return c.MutateIn(id, specs, &MutateInOptions{
+		Cas:             lookupRes.Cas(),
+		Expiry:          expiry,
+		PreserveExpiry:  opts.PreserveExpiry,
+		PersistTo:       opts.PersistTo,
+		ReplicateTo:     opts.ReplicateTo,
+		DurabilityLevel: opts.DurabilityLevel,
+		DurabilityMode:  opts.DurabilityMode,
+		Timeout:         opts.Timeout,
+		RetryStrategy:   opts.RetryStrategy,
+		ParentSpan:      opts.ParentSpan,
+		Context:         opts.Context,
+	})
+}
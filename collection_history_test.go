@@ -0,0 +1,89 @@
+package gocb
+
+import (
+	"errors"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestReplaceWithHistory() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var mutateAttempts int
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			suite.Require().Len(opts.Ops, 2)
+			suite.Assert().Equal(memd.SubDocOpGetDoc, opts.Ops[0].Op)
+			suite.Assert().Equal(memd.SubDocOpGetCount, opts.Ops[1].Op)
+			suite.Assert().Equal("versions", opts.Ops[1].Path)
+			suite.Assert().Equal(memd.SubdocFlagXattrPath, opts.Ops[1].Flags)
+
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{
+					{Value: []byte(`{"version":1}`)},
+					{Value: []byte("3")},
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("MutateIn", mock.AnythingOfType("gocbcore.MutateInOptions"), mock.AnythingOfType("gocbcore.MutateInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.MutateInOptions)
+			cb := args.Get(1).(gocbcore.MutateInCallback)
+
+			mutateAttempts++
+			suite.Assert().Equal(gocbcore.Cas(123), opts.Cas)
+			suite.Require().Len(opts.Ops, 3)
+			suite.Assert().Equal(memd.SubDocOpArrayPushLast, opts.Ops[0].Op)
+			suite.Assert().Equal("versions", opts.Ops[0].Path)
+			suite.Assert().Equal(memd.SubdocFlagXattrPath|memd.SubdocFlagMkDirP, opts.Ops[0].Flags)
+			suite.Assert().Equal([]byte(`{"version":1}`), opts.Ops[0].Value)
+			suite.Assert().Equal(memd.SubDocOpDelete, opts.Ops[1].Op)
+			suite.Assert().Equal("versions[0]", opts.Ops[1].Path)
+			suite.Assert().Equal(memd.SubDocOpSetDoc, opts.Ops[2].Op)
+			suite.Assert().Equal([]byte(`{"version":2}`), opts.Ops[2].Value)
+
+			if mutateAttempts == 1 {
+				cb(nil, &gocbcore.KeyValueError{
+					InnerError: gocbcore.ErrCasMismatch,
+				})
+				return
+			}
+
+			cb(&gocbcore.MutateInResult{
+				Cas: gocbcore.Cas(124),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.ReplaceWithHistory("someid", map[string]int{"version": 2}, &ReplaceWithHistoryOptions{
+		HistoryPath: "versions",
+		MaxEntries:  3,
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(Cas(124), res.Cas())
+	suite.Assert().Equal(2, mutateAttempts)
+
+	mutateAttempts = 0
+	_, err = col.ReplaceWithHistory("someid", map[string]int{"version": 2}, &ReplaceWithHistoryOptions{
+		HistoryPath: "versions",
+		MaxEntries:  3,
+		CasRetries:  1,
+	})
+	if !errors.Is(err, ErrCasMismatch) {
+		suite.T().Fatalf("Expected error to be cas mismatch but was %v", err)
+	}
+	suite.Assert().Equal(1, mutateAttempts)
+}
@@ -0,0 +1,130 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// LookupInAllOptions are the set of options available to LookupInAll.
+type LookupInAllOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// LookupInAllResult is the result of a LookupInAll operation. Documents are indexed in the order of the IDs passed
+// to LookupInAll and paths are indexed in the order of the specs.
+// UNCOMMITTED: This API may change in the future.
+type LookupInAllResult struct {
+	ops []*LookupInOp
+}
+
+// Len returns the number of documents in the result.
+func (r *LookupInAllResult) Len() int {
+	return len(r.ops)
+}
+
+// ID returns the ID of the document at docIdx.
+func (r *LookupInAllResult) ID(docIdx int) string {
+	return r.ops[docIdx].ID
+}
+
+// Result returns the lookup result of the document at docIdx, or the error which occurred fetching that document.
+func (r *LookupInAllResult) Result(docIdx int) (*LookupInResult, error) {
+	op := r.ops[docIdx]
+	if op.Err != nil {
+		return nil, op.Err
+	}
+
+	return op.Result, nil
+}
+
+// PathEqual reports whether the path at idx has the same value in every document, a path which is missing from
+// every document is considered equal. An error is returned if any document could not be fetched, or if fetching the
+// path failed for a reason other than the path not existing.
+func (r *LookupInAllResult) PathEqual(idx uint) (bool, error) {
+	var first interface{}
+	var firstExists bool
+	for i, op := range r.ops {
+		if op.Err != nil {
+			return false, wrapError(op.Err, fmt.Sprintf("failed to lookup document %s", op.ID))
+		}
+
+		var value interface{}
+		var raw []byte
+		err := op.Result.ContentAt(idx, &raw)
+		exists := err == nil
+		if err != nil && !errors.Is(err, ErrPathNotFound) {
+			return false, err
+		}
+		if exists && len(raw) > 0 {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return false, err
+			}
+		}
+
+		if i == 0 {
+			first = value
+			firstExists = exists
+			continue
+		}
+
+		if exists != firstExists || !reflect.DeepEqual(first, value) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// LookupInAll performs the same set of lookup operations against each of the given documents. The lookups are
+// pipelined as a single batch using Do, rather than waiting for each document in turn, and the results are returned
+// aligned so that the same path can be compared across documents using LookupInAllResult.PathEqual.
+// A failure to fetch one document does not fail the others, per document errors are returned by
+// LookupInAllResult.Result.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) LookupInAll(ids []string, ops []LookupInSpec, opts *LookupInAllOptions) (*LookupInAllResult, error) {
+	if opts == nil {
+		opts = &LookupInAllOptions{}
+	}
+
+	if len(ids) == 0 {
+		return nil, makeInvalidArgumentsError("at least one document id must be provided")
+	}
+	if len(ops) == 0 {
+		return nil, makeInvalidArgumentsError("at least one lookup in spec must be provided")
+	}
+
+	lookupOps := make([]*LookupInOp, len(ids))
+	bulkOps := make([]BulkOp, len(ids))
+	for i, id := range ids {
+		lookupOps[i] = &LookupInOp{
+			ID:  id,
+			Ops: ops,
+		}
+		bulkOps[i] = lookupOps[i]
+	}
+
+	err := c.Do(bulkOps, &BulkOpOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LookupInAllResult{
+		ops: lookupOps,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package gocb
+
+import (
+	"errors"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestLookupInAll() {
+	pendingOp := new(mockPendingOp)
+
+	docs := map[string][]gocbcore.SubDocResult{
+		"one": {
+			{Value: []byte(`{"first":"a","last":"b"}`)},
+			{Value: []byte(`1`)},
+			{Err: gocbcore.ErrPathNotFound},
+		},
+		"two": {
+			{Value: []byte(`{"last":"b","first":"a"}`)},
+			{Value: []byte(`2`)},
+			{Err: gocbcore.ErrPathNotFound},
+		},
+	}
+
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			suite.Require().Len(opts.Ops, 3)
+
+			ops, ok := docs[string(opts.Key)]
+			if !ok {
+				cb(nil, gocbcore.ErrDocumentNotFound)
+				return
+			}
+
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(1),
+				Ops: ops,
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	specs := []LookupInSpec{
+		GetSpec("name", nil),
+		GetSpec("count", nil),
+		GetSpec("missing", nil),
+	}
+	res, err := col.LookupInAll([]string{"one", "two"}, specs, nil)
+	suite.Require().Nil(err, err)
+	suite.Require().Equal(2, res.Len())
+	suite.Assert().Equal("one", res.ID(0))
+	suite.Assert().Equal("two", res.ID(1))
+
+	equal, err := res.PathEqual(0)
+	suite.Require().Nil(err, err)
+	suite.Assert().True(equal)
+
+	equal, err = res.PathEqual(1)
+	suite.Require().Nil(err, err)
+	suite.Assert().False(equal)
+
+	equal, err = res.PathEqual(2)
+	suite.Require().Nil(err, err)
+	suite.Assert().True(equal)
+
+	doc, err := res.Result(1)
+	suite.Require().Nil(err, err)
+	var count int
+	suite.Require().Nil(doc.ContentAt(1, &count))
+	suite.Assert().Equal(2, count)
+
+	res, err = col.LookupInAll([]string{"one", "three"}, specs, nil)
+	suite.Require().Nil(err, err)
+
+	_, err = res.Result(1)
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected error to be document not found but was %v", err)
+	}
+
+	_, err = res.PathEqual(0)
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected error to be document not found but was %v", err)
+	}
+}
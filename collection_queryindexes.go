@@ -0,0 +1,191 @@
+package gocb
+
+import "time"
+
+// CollectionQueryIndexManager provides methods for performing Couchbase query index management against a single
+// collection. It mirrors QueryIndexManager, but every operation targets the collection which it was obtained from
+// so the ScopeName and CollectionName options must not be set.
+// UNCOMMITTED: This API may change in the future.
+type CollectionQueryIndexManager struct {
+	base *QueryIndexManager
+
+	bucketName     string
+	scopeName      string
+	collectionName string
+}
+
+// QueryIndexes returns a CollectionQueryIndexManager for managing query indexes on this collection.
+// This requires Couchbase Server 7.0 or above.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) QueryIndexes() *CollectionQueryIndexManager {
+	scope := c.bucket.Scope(c.scope)
+
+	return &CollectionQueryIndexManager{
+		base: &QueryIndexManager{
+			provider:      scope,
+			mgmtProvider:  c.bucket,
+			globalTimeout: scope.timeoutsConfig.ManagementTimeout,
+			tracer:        c.tracer,
+			meter:         c.meter,
+		},
+		bucketName:     c.bucketName(),
+		scopeName:      c.scope,
+		collectionName: c.collectionName,
+	}
+}
+
+func (cm *CollectionQueryIndexManager) validateKeyspace(scope, collection string) error {
+	if scope != "" || collection != "" {
+		return makeInvalidArgumentsError("scope and collection names cannot be set when using a collection query index manager")
+	}
+
+	return nil
+}
+
+// CreateIndex creates an index over the specified fields.
+func (cm *CollectionQueryIndexManager) CreateIndex(indexName string, fields []string, opts *CreateQueryIndexOptions) error {
+	if opts == nil {
+		opts = &CreateQueryIndexOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.CreateIndex(cm.bucketName, indexName, fields, &scopedOpts)
+}
+
+// CreatePrimaryIndex creates a primary index.  An empty customName uses the default naming.
+func (cm *CollectionQueryIndexManager) CreatePrimaryIndex(opts *CreatePrimaryQueryIndexOptions) error {
+	if opts == nil {
+		opts = &CreatePrimaryQueryIndexOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.CreatePrimaryIndex(cm.bucketName, &scopedOpts)
+}
+
+// DropIndex drops a specific index by name.
+func (cm *CollectionQueryIndexManager) DropIndex(indexName string, opts *DropQueryIndexOptions) error {
+	if opts == nil {
+		opts = &DropQueryIndexOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.DropIndex(cm.bucketName, indexName, &scopedOpts)
+}
+
+// DropPrimaryIndex drops the primary index.  Pass an empty customName for unnamed primary indexes.
+func (cm *CollectionQueryIndexManager) DropPrimaryIndex(opts *DropPrimaryQueryIndexOptions) error {
+	if opts == nil {
+		opts = &DropPrimaryQueryIndexOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.DropPrimaryIndex(cm.bucketName, &scopedOpts)
+}
+
+// GetAllIndexes returns a list of all currently registered indexes on the collection.
+func (cm *CollectionQueryIndexManager) GetAllIndexes(opts *GetAllQueryIndexesOptions) ([]QueryIndex, error) {
+	if opts == nil {
+		opts = &GetAllQueryIndexesOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return nil, err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.GetAllIndexes(cm.bucketName, &scopedOpts)
+}
+
+// BuildDeferredIndexes builds all indexes on the collection which are currently in deferred state.
+func (cm *CollectionQueryIndexManager) BuildDeferredIndexes(opts *BuildDeferredQueryIndexOptions) ([]string, error) {
+	if opts == nil {
+		opts = &BuildDeferredQueryIndexOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return nil, err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.BuildDeferredIndexes(cm.bucketName, &scopedOpts)
+}
+
+// WatchIndexes waits for a set of indexes to come online.
+func (cm *CollectionQueryIndexManager) WatchIndexes(watchList []string, timeout time.Duration, opts *WatchQueryIndexOptions) error {
+	if opts == nil {
+		opts = &WatchQueryIndexOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.WatchIndexes(cm.bucketName, watchList, timeout, &scopedOpts)
+}
+
+// WaitForIndex waits for the named index to reach the given state. See QueryIndexManager.WaitForIndex.
+func (cm *CollectionQueryIndexManager) WaitForIndex(indexName, state string, timeout time.Duration,
+	opts *WaitForQueryIndexOptions) error {
+	if opts == nil {
+		opts = &WaitForQueryIndexOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.WaitForIndex(cm.bucketName, indexName, state, timeout, &scopedOpts)
+}
+
+// WaitUntilReady waits for all of the named indexes on the collection to be online. See
+// QueryIndexManager.WaitUntilReady.
+// UNCOMMITTED: This API may change in the future.
+func (cm *CollectionQueryIndexManager) WaitUntilReady(indexNames []string, timeout time.Duration,
+	opts *WaitUntilQueryIndexesReadyOptions) error {
+	if opts == nil {
+		opts = &WaitUntilQueryIndexesReadyOptions{}
+	}
+	if err := cm.validateKeyspace(opts.ScopeName, opts.CollectionName); err != nil {
+		return err
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = cm.scopeName
+	scopedOpts.CollectionName = cm.collectionName
+
+	return cm.base.WaitUntilReady(cm.bucketName, indexNames, timeout, &scopedOpts)
+}
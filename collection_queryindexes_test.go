@@ -0,0 +1,114 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestCollectionQueryIndexesCreateIndex() {
+	reader := &mockQueryIndexRowReader{
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta:  []byte("{}"),
+			Suite: suite,
+		},
+	}
+
+	cluster := suite.queryCluster(false, reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal("CREATE INDEX `idx` ON `mybucket`.`myscope`.`mycollection` (`name`)", actualOptions["statement"])
+	})
+
+	mgr := &CollectionQueryIndexManager{
+		base: &QueryIndexManager{
+			provider: cluster,
+			tracer:   &NoopTracer{},
+			meter:    &meterWrapper{meter: &NoopMeter{}},
+		},
+		bucketName:     "mybucket",
+		scopeName:      "myscope",
+		collectionName: "mycollection",
+	}
+
+	err := mgr.CreateIndex("idx", []string{"name"}, nil)
+	suite.Require().Nil(err, err)
+
+	err = mgr.CreateIndex("idx", []string{"name"}, &CreateQueryIndexOptions{
+		ScopeName:      "otherscope",
+		CollectionName: "othercollection",
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestCollectionQueryIndexesWaitUntilReady() {
+	var dataset testQueryIndexDataset
+	err := loadJSONTestDataset("query_index_response", &dataset)
+	suite.Require().Nil(err, err)
+
+	newMgr := func() *CollectionQueryIndexManager {
+		reader := &mockQueryIndexRowReader{
+			Dataset: dataset.Results,
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+				Suite: suite,
+			},
+		}
+
+		return &CollectionQueryIndexManager{
+			base: &QueryIndexManager{
+				provider: suite.queryCluster(false, reader, nil),
+				tracer:   &NoopTracer{},
+				meter:    &meterWrapper{meter: &NoopMeter{}},
+			},
+			bucketName:     "mybucket",
+			scopeName:      "myscope",
+			collectionName: "mycollection",
+		}
+	}
+
+	err = newMgr().WaitUntilReady([]string{"ih"}, 5*time.Second, nil)
+	suite.Require().Nil(err, err)
+
+	// An index which does not exist is waited for rather than failing straight away.
+	err = newMgr().WaitUntilReady([]string{"ih", "missing"}, 50*time.Millisecond, &WaitUntilQueryIndexesReadyOptions{
+		PollInterval: time.Second,
+	})
+	var notReadyErr *QueryIndexesNotReadyError
+	suite.Require().True(errors.As(err, &notReadyErr), "expected indexes not ready error but was %v", err)
+	suite.Assert().True(errors.Is(err, ErrUnambiguousTimeout))
+	suite.Assert().Equal(map[string]string{"missing": "absent"}, notReadyErr.Indexes)
+
+	// The states from the last check are also reported when the context is done first.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = newMgr().WaitUntilReady([]string{"ih", "missing"}, 5*time.Second, &WaitUntilQueryIndexesReadyOptions{
+		PollInterval: time.Second,
+		Context:      ctx,
+	})
+	suite.Require().True(errors.As(err, &notReadyErr), "expected indexes not ready error but was %v", err)
+	suite.Assert().True(errors.Is(err, context.DeadlineExceeded))
+	suite.Assert().Equal(map[string]string{"missing": "absent"}, notReadyErr.Indexes)
+
+	err = newMgr().WaitUntilReady(nil, 5*time.Second, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	err = newMgr().WaitUntilReady([]string{"ih"}, 5*time.Second, &WaitUntilQueryIndexesReadyOptions{
+		ScopeName: "otherscope",
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
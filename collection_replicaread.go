@@ -0,0 +1,305 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// replicaReadStage is a set of copies of a document which are read concurrently, by replica index where index 0 is
+// the active.
+type replicaReadStage struct {
+	copies []int
+
+	// allReplicas reads every replica, the number of which is only looked up when the stage is reached.
+	allReplicas bool
+}
+
+// replicaReadPlan describes the order in which a Get which may be served by a replica reads the copies of a
+// document. The first stage holds the preferred copies, and each later stage is only read once the stage before it
+// has failed in a way which allows the read to move on, or the fallback delay has elapsed.
+type replicaReadPlan struct {
+	stages []replicaReadStage
+
+	// splitDeadline gives each stage an equal share of the time remaining when it is reached, so that a copy which
+	// does not respond cannot use up the time of the stages after it.
+	splitDeadline bool
+
+	// advanceOnError moves on to the next stage whenever every copy of a stage fails, rather than only when the
+	// node holding the active is unavailable.
+	advanceOnError bool
+}
+
+type replicaReadCopy struct {
+	doc        *GetResult
+	replicaIdx int
+	stage      int
+	err        error
+}
+
+// planReplicaRead returns the plan for reading the given document using the replica options of opts.
+func (c *Collection) planReplicaRead(id string, opts *GetOptions) (*replicaReadPlan, error) {
+	defaultPlan := &replicaReadPlan{
+		stages: []replicaReadStage{{copies: []int{0}}, {allReplicas: true}},
+	}
+	if opts.ReadPreference == ReadPreferenceNoPreference {
+		return defaultPlan, nil
+	}
+
+	if c.bucket.serverGroups == nil {
+		return nil, makeInvalidArgumentsError("ClusterOptions.PreferredServerGroup must be set to use ReadPreference")
+	}
+
+	inGroup, err := c.bucket.serverGroups.copiesInGroup(id)
+	if err != nil {
+		if errors.Is(err, errServerGroupsNotFetched) &&
+			opts.ReadPreference == ReadPreferenceSelectedServerGroupOrAllAvailable {
+			logDebugf("Server groups of bucket %s have not been fetched yet, reading from the active", c.bucketName())
+			return defaultPlan, nil
+		}
+
+		return nil, err
+	}
+
+	plan := &replicaReadPlan{
+		splitDeadline:  true,
+		advanceOnError: true,
+	}
+
+	var activeInGroup bool
+	for _, replicaIdx := range inGroup {
+		activeInGroup = activeInGroup || replicaIdx == 0
+		plan.stages = append(plan.stages, replicaReadStage{copies: []int{replicaIdx}})
+	}
+
+	// The active copy is only read as a fallback if it is outside of the server group, as otherwise it has
+	// already been tried.
+	if opts.ReadPreference == ReadPreferenceSelectedServerGroupOrAllAvailable && !activeInGroup {
+		plan.stages = append(plan.stages, replicaReadStage{copies: []int{0}})
+	}
+
+	return plan, nil
+}
+
+// getWithReplicas performs a Get which may be served by a replica, following the plan given by the replica options
+// of opts.
+func (c *Collection) getWithReplicas(id string, opts *GetOptions) (*GetResult, error) {
+	plan, err := c.planReplicaRead(id, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.readReplicas(id, opts, plan)
+}
+
+func (c *Collection) readReplicas(id string, opts *GetOptions, plan *replicaReadPlan) (*GetResult, error) {
+	if len(plan.stages) == 0 {
+		return nil, &KeyValueError{
+			InnerError:     ErrDocumentUnretrievable,
+			BucketName:     c.bucketName(),
+			ScopeName:      c.scope,
+			CollectionName: c.collectionName,
+		}
+	}
+
+	start := time.Now()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = c.timeoutsConfig.KVTimeout
+	}
+	deadline := start.Add(timeout)
+
+	parentCtx := opts.Context
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parentCtx)
+	defer cancel()
+
+	retryStrategy := opts.RetryStrategy
+	var unavailable *nodeUnavailableRetryStrategy
+	if opts.ReplicaFallback {
+		wrapped := opts.RetryStrategy
+		if wrapped == nil {
+			wrapped = c.retryStrategyWrapper.wrapped
+		}
+		unavailable = &nodeUnavailableRetryStrategy{
+			wrapped: wrapped,
+		}
+		retryStrategy = unavailable
+	}
+
+	// Copies which are read after falling back are children of the fallback span, so that they can be told apart
+	// from the preferred copies.
+	span := opts.ParentSpan
+	var fallbackSpan RequestSpan
+	details := &ReplicaFallbackDetails{}
+	defer func() {
+		if fallbackSpan != nil {
+			fallbackSpan.End()
+			c.meter.KeyspaceValueRecord(meterValueServiceKV, "get_replica_fallback", opts.OperationLabel,
+				c.meterKeyspace(), start)
+		}
+	}()
+
+	copyCh := make(chan replicaReadCopy)
+	read := func(stage, replicaIdx int, timeout time.Duration) {
+		readSpan := span
+		go func() {
+			res := replicaReadCopy{replicaIdx: replicaIdx, stage: stage}
+			if replicaIdx == 0 {
+				activeOpts := *opts
+				activeOpts.Timeout = timeout
+				activeOpts.RetryStrategy = retryStrategy
+				activeOpts.ParentSpan = readSpan
+				activeOpts.Context = ctx
+
+				res.doc, res.err = c.getDirect(id, &activeOpts)
+			} else {
+				var replicaRes *GetReplicaResult
+				replicaRes, res.err = c.getOneReplica(ctx, readSpan, id, replicaIdx, opts.Transcoder, opts.RetryStrategy,
+					nil, timeout, opts.Internal.User)
+				if res.err == nil {
+					res.doc = &replicaRes.GetResult
+				}
+			}
+
+			select {
+			case copyCh <- res:
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	var preferredErr, firstErr error
+	pending := 0
+	lastStage := len(plan.stages) - 1
+	for stage := 0; ; stage++ {
+		if stage > 0 && fallbackSpan == nil {
+			details.FellBack = true
+			details.ActiveWait = time.Since(start)
+
+			var tracectx RequestSpanContext
+			if opts.ParentSpan != nil {
+				tracectx = opts.ParentSpan.Context()
+			}
+
+			fallbackSpan = c.startKvOpTrace("get_replica_fallback", tracectx, false)
+			if opts.OperationLabel != "" {
+				fallbackSpan.SetAttribute(spanAttribOperationLabelKey, opts.OperationLabel)
+			}
+			span = fallbackSpan
+		}
+
+		copies := plan.stages[stage].copies
+		if plan.stages[stage].allReplicas {
+			numReplicas, err := c.numReplicas()
+			if err != nil {
+				logDebugf("Failed to fetch number of replicas for replica fallback: %v", err)
+			}
+
+			copies = nil
+			for replicaIdx := 1; replicaIdx <= numReplicas; replicaIdx++ {
+				copies = append(copies, replicaIdx)
+			}
+		}
+
+		stageTimeout := time.Until(deadline)
+		if plan.splitDeadline {
+			stageTimeout /= time.Duration(len(plan.stages) - stage)
+		}
+		for _, replicaIdx := range copies {
+			read(stage, replicaIdx, stageTimeout)
+		}
+		pending += len(copies)
+		stagePending := len(copies)
+
+		var delayCh <-chan time.Time
+		if opts.ReplicaFallbackDelay > 0 && stage < lastStage {
+			timer = time.NewTimer(opts.ReplicaFallbackDelay)
+			delayCh = timer.C
+		}
+
+		advance := stagePending == 0 && stage < lastStage
+		for !advance {
+			if pending == 0 {
+				if preferredErr != nil {
+					return nil, preferredErr
+				}
+				if firstErr != nil {
+					return nil, firstErr
+				}
+
+				return nil, &KeyValueError{
+					InnerError:     ErrDocumentUnretrievable,
+					BucketName:     c.bucketName(),
+					ScopeName:      c.scope,
+					CollectionName: c.collectionName,
+				}
+			}
+
+			select {
+			case res := <-copyCh:
+				pending--
+				if res.err == nil {
+					if !details.FellBack {
+						details.ActiveWait = time.Since(start)
+					}
+					details.ReplicaIndex = res.replicaIdx
+					res.doc.replicaFallback = details
+					return res.doc, nil
+				}
+
+				logDebugf("Failed to fetch copy %d of document: %s", res.replicaIdx, res.err)
+				if res.stage == 0 && preferredErr == nil {
+					preferredErr = res.err
+				}
+				if firstErr == nil {
+					firstErr = res.err
+				}
+
+				if res.stage == stage {
+					stagePending--
+					advance = stagePending == 0 && stage < lastStage && time.Until(deadline) > 0 &&
+						c.canAdvanceReplicaRead(plan, res, unavailable)
+				}
+			case <-delayCh:
+				advance = true
+			}
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// canAdvanceReplicaRead returns whether a read which failed against every copy of its current stage, with res being
+// the final failure, moves on to the next stage of the plan.
+func (c *Collection) canAdvanceReplicaRead(plan *replicaReadPlan, res replicaReadCopy,
+	unavailable *nodeUnavailableRetryStrategy) bool {
+	// A document which does not exist on the active is never read from the replicas, they could only be staler.
+	if res.replicaIdx == 0 && errors.Is(res.err, ErrDocumentNotFound) {
+		return false
+	}
+
+	if plan.advanceOnError {
+		return true
+	}
+
+	if res.replicaIdx == 0 && unavailable != nil && unavailable.nodeUnavailable() {
+		logDebugFieldsf(logFields{operation: "get", bucket: c.bucketName()},
+			"Active copy is unavailable, reading from the replicas: %v", res.err)
+		return true
+	}
+
+	return false
+}
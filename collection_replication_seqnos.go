@@ -0,0 +1,214 @@
+package gocb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+// ReplicationSeqNosOptions are the options available to the ReplicationSeqNos operation.
+// UNCOMMITTED: This API may change in the future.
+type ReplicationSeqNosOptions struct {
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+
+	// Internal: This should never be used and is not supported.
+	Internal struct {
+		User string
+	}
+}
+
+// ReplicaSeqNo is the sequence number of a single replica of the vbucket which owns a document.
+// UNCOMMITTED: This API may change in the future.
+type ReplicaSeqNo struct {
+	// ReplicaIndex is the index of the replica, starting at 1.
+	ReplicaIndex int
+
+	// SeqNo is the highest sequence number which the replica has received.
+	SeqNo uint64
+
+	// PersistSeqNo is the highest sequence number which the replica has persisted to disk.
+	PersistSeqNo uint64
+
+	// Lag is the number of mutations which the replica is behind the active, at the time each was observed. Because
+	// the active and the replicas are observed concurrently, a replica which is fully caught up may briefly appear
+	// to be ahead, in which case Lag is 0.
+	Lag uint64
+
+	// Err is the error which occurred whilst observing the replica, if any, in which case the sequence numbers and
+	// lag are not set.
+	Err error
+}
+
+// ReplicationSeqNosResult is the result of a ReplicationSeqNos operation.
+// UNCOMMITTED: This API may change in the future.
+type ReplicationSeqNosResult struct {
+	// VbID is the ID of the vbucket which owns the document.
+	VbID uint16
+
+	// VbUUID is the current UUID of the vbucket on the active.
+	VbUUID uint64
+
+	// ActiveSeqNo is the highest sequence number of the vbucket on the active.
+	ActiveSeqNo uint64
+
+	// ActivePersistSeqNo is the highest sequence number of the vbucket which the active has persisted to disk.
+	ActivePersistSeqNo uint64
+
+	// Replicas contains the sequence numbers of each configured replica, ordered by replica index.
+	Replicas []ReplicaSeqNo
+}
+
+// ReplicationSeqNos observes the sequence numbers of the vbucket which owns a document on the active and on each of
+// its replicas, which can be used to measure how far each replica is lagging behind the active. The sequence numbers
+// are those of the vbucket rather than of the document itself, so the lag includes mutations to any document in the
+// same vbucket. The document does not need to exist.
+// An error is returned if the active cannot be observed, whereas an error observing a replica is reported in that
+// replica's ReplicaSeqNo.
+// This is a diagnostic operation and is not intended to be used on a hot path.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) ReplicationSeqNos(id string, opts *ReplicationSeqNosOptions) (*ReplicationSeqNosResult, error) {
+	if opts == nil {
+		opts = &ReplicationSeqNosOptions{}
+	}
+
+	opm := c.newKvOpManager("replication_seqnos", opts.ParentSpan)
+	defer opm.Finish(true)
+
+	opm.SetDocumentID(id)
+	opm.SetRetryStrategy(opts.RetryStrategy)
+	opm.SetTimeout(opts.Timeout)
+	opm.SetImpersonate(opts.Internal.User)
+	opm.SetContext(opts.Context)
+
+	if err := opm.CheckReadyForOp(); err != nil {
+		return nil, err
+	}
+
+	agent, err := c.getKvProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := agent.ConfigSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	numReplicas, err := snapshot.NumReplicas()
+	if err != nil {
+		return nil, err
+	}
+
+	vbID, err := snapshot.KeyToVbucket(opm.DocumentID())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	deadline := opm.Deadline()
+
+	// The active is observed first so that its vbucket UUID can be used when observing the replicas, the UUID
+	// doesn't need to be known for the active as it is returned regardless of whether the one we send matches.
+	active, err := c.observeVbSeqNos(ctx, opm.TraceSpan(), id, vbID, 0, 0, opm.RetryStrategy(),
+		time.Until(deadline), opts.Internal.User)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ReplicationSeqNosResult{
+		VbID:               vbID,
+		VbUUID:             uint64(active.VbUUID),
+		ActiveSeqNo:        uint64(active.CurrentSeqNo),
+		ActivePersistSeqNo: uint64(active.PersistSeqNo),
+		Replicas:           make([]ReplicaSeqNo, numReplicas),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < numReplicas; i++ {
+		wg.Add(1)
+		go func(replicaIdx int) {
+			defer wg.Done()
+
+			replica := ReplicaSeqNo{
+				ReplicaIndex: replicaIdx,
+			}
+
+			replicaRes, err := c.observeVbSeqNos(ctx, opm.TraceSpan(), id, vbID, active.VbUUID, replicaIdx,
+				opm.RetryStrategy(), time.Until(deadline), opts.Internal.User)
+			if err != nil {
+				replica.Err = err
+			} else {
+				replica.SeqNo = uint64(replicaRes.CurrentSeqNo)
+				replica.PersistSeqNo = uint64(replicaRes.PersistSeqNo)
+				if res.ActiveSeqNo > replica.SeqNo {
+					replica.Lag = res.ActiveSeqNo - replica.SeqNo
+				}
+			}
+
+			res.Replicas[replicaIdx-1] = replica
+		}(i + 1)
+	}
+	wg.Wait()
+
+	return res, nil
+}
+
+func (c *Collection) observeVbSeqNos(
+	ctx context.Context,
+	trace RequestSpan,
+	docID string,
+	vbID uint16,
+	vbUUID gocbcore.VbUUID,
+	replicaIdx int,
+	retryStrategy *retryStrategyWrapper,
+	timeout time.Duration,
+	user string,
+) (resOut *gocbcore.ObserveVbResult, errOut error) {
+	opm := c.newKvOpManager("observe_vb", trace)
+	defer opm.Finish(true)
+
+	opm.SetDocumentID(docID)
+	opm.SetTimeout(timeout)
+	opm.SetImpersonate(user)
+	opm.SetContext(ctx)
+
+	agent, err := c.getKvProvider()
+	if err != nil {
+		return nil, err
+	}
+	err = opm.Wait(agent.ObserveVb(gocbcore.ObserveVbOptions{
+		VbID:          vbID,
+		VbUUID:        vbUUID,
+		ReplicaIdx:    replicaIdx,
+		RetryStrategy: retryStrategy,
+		TraceContext:  opm.TraceSpanContext(),
+		Deadline:      opm.Deadline(),
+		User:          opm.Impersonate(),
+	}, func(res *gocbcore.ObserveVbResult, err error) {
+		if err != nil || res == nil {
+			errOut = opm.EnhanceErr(err)
+			opm.Reject()
+			return
+		}
+
+		resOut = res
+		opm.Resolve(nil)
+	}))
+	if err != nil {
+		errOut = err
+	}
+	return
+}
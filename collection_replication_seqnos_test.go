@@ -0,0 +1,52 @@
+package gocb
+
+import (
+	"time"
+)
+
+func (suite *IntegrationTestSuite) TestReplicationSeqNos() {
+	suite.skipIfUnsupported(KeyValueFeature)
+	suite.skipIfUnsupported(ReplicasFeature)
+
+	agent, err := globalCollection.getKvProvider()
+	if err != nil {
+		suite.T().Fatalf("Failed to get kv provider, was %v", err)
+	}
+
+	snapshot, err := agent.ConfigSnapshot()
+	if err != nil {
+		suite.T().Fatalf("Failed to get config snapshot, was %v", err)
+	}
+
+	numReplicas, err := snapshot.NumReplicas()
+	if err != nil {
+		suite.T().Fatalf("Failed to get numReplicas, was %v", err)
+	}
+
+	mutRes, err := globalCollection.Upsert("replicationSeqNosDoc", "value", &UpsertOptions{
+		PersistTo: uint(numReplicas + 1),
+	})
+	if err != nil {
+		suite.T().Fatalf("Upsert failed, error was %v", err)
+	}
+
+	res, err := globalCollection.ReplicationSeqNos("replicationSeqNosDoc", &ReplicationSeqNosOptions{
+		Timeout: 10 * time.Second,
+	})
+	suite.Require().Nil(err, err)
+
+	token := mutRes.MutationToken()
+	suite.Require().NotNil(token)
+
+	suite.Assert().Equal(token.PartitionID(), uint64(res.VbID))
+	suite.Assert().Equal(token.PartitionUUID(), res.VbUUID)
+	suite.Assert().GreaterOrEqual(res.ActiveSeqNo, token.SequenceNumber())
+	suite.Assert().GreaterOrEqual(res.ActivePersistSeqNo, token.SequenceNumber())
+
+	suite.Require().Len(res.Replicas, numReplicas)
+	for i, replica := range res.Replicas {
+		suite.Assert().Equal(i+1, replica.ReplicaIndex)
+		suite.Assert().Nil(replica.Err, replica.Err)
+		suite.Assert().GreaterOrEqual(replica.SeqNo, token.SequenceNumber())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/couchbase/gocbcore/v10/memd"
@@ -17,11 +18,25 @@ type LookupInOptions struct {
 	RetryStrategy RetryStrategy
 	ParentSpan    RequestSpan
 
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
+
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
 
+	// WithDeleted causes a document which has been deleted, but whose tombstone still exists on the server, to be
+	// looked up rather than failing with ErrDocumentNotFound, e.g. to read the xattrs of a document created with
+	// MutateInOptions.CreateAsDeleted. LookupInResult.IsDeleted reports whether the document is a tombstone.
+	// UNCOMMITTED: This API may change in the future.
+	WithDeleted bool
+
 	// Internal: This should never be used and is not supported.
 	Internal struct {
 		DocFlags SubdocDocFlag
@@ -44,20 +59,22 @@ func (c *Collection) LookupIn(id string, ops []LookupInSpec, opts *LookupInOptio
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 
 	if err := opm.CheckReadyForOp(); err != nil {
 		return nil, err
 	}
 
-	return c.internalLookupIn(opm, ops, memd.SubdocDocFlag(opts.Internal.DocFlags))
+	docFlags := memd.SubdocDocFlag(opts.Internal.DocFlags)
+	if opts.WithDeleted {
+		docFlags |= memd.SubdocDocFlagAccessDeleted
+	}
+
+	return c.internalLookupIn(opm, ops, docFlags)
 }
 
-func (c *Collection) internalLookupIn(
-	opm *kvOpManager,
-	ops []LookupInSpec,
-	flags memd.SubdocDocFlag,
-) (docOut *LookupInResult, errOut error) {
+func lookupInSpecsToSubdocs(ops []LookupInSpec) ([]gocbcore.SubDocOp, error) {
 	var subdocs []gocbcore.SubDocOp
 	for _, op := range ops {
 		if op.op == memd.SubDocOpGet && op.path == "" {
@@ -83,6 +100,14 @@ func (c *Collection) internalLookupIn(
 		}
 
 		flags := memd.SubdocFlagNone
+		if isLookupInMacroPath(op.path) {
+			if op.op != memd.SubDocOpGet && op.op != memd.SubDocOpExists {
+				return nil, makeInvalidArgumentsError("lookup in macros can only be used with GetSpec or ExistsSpec")
+			}
+
+			// Virtual extended attributes must always be requested as extended attributes.
+			flags |= memd.SubdocFlagXattrPath
+		}
 		if op.isXattr {
 			flags |= memd.SubdocFlagXattrPath
 		}
@@ -94,6 +119,24 @@ func (c *Collection) internalLookupIn(
 		})
 	}
 
+	return subdocs, nil
+}
+
+// isLookupInMacroPath returns whether path refers to the $document virtual extended attribute, or a field within it.
+func isLookupInMacroPath(path string) bool {
+	return path == string(LookupInMacroDocument) || strings.HasPrefix(path, string(LookupInMacroDocument)+".")
+}
+
+func (c *Collection) internalLookupIn(
+	opm *kvOpManager,
+	ops []LookupInSpec,
+	flags memd.SubdocDocFlag,
+) (docOut *LookupInResult, errOut error) {
+	subdocs, err := lookupInSpecsToSubdocs(ops)
+	if err != nil {
+		return nil, err
+	}
+
 	agent, err := c.getKvProvider()
 	if err != nil {
 		return nil, err
@@ -117,6 +160,9 @@ func (c *Collection) internalLookupIn(
 		if res != nil {
 			docOut = &LookupInResult{}
 			docOut.cas = Cas(res.Cas)
+			docOut.isDeleted = res.Internal.IsDeleted
+			docOut.serverDuration = opm.ServerDuration()
+			docOut.retries = opm.Retries()
 			docOut.contents = make([]lookupInPartial, len(subdocs))
 			for i, opRes := range res.Ops {
 				docOut.contents[i].err = opm.EnhanceErr(opRes.Err)
@@ -162,13 +208,52 @@ type MutateInOptions struct {
 	Timeout         time.Duration
 	RetryStrategy   RetryStrategy
 	ParentSpan      RequestSpan
-	PreserveExpiry  bool
+	// PreserveExpiry causes the existing expiry of the document to be retained, rather than being cleared by the
+	// mutation. It cannot be used together with Expiry. This requires Couchbase Server 7.0 or above, older servers
+	// return ErrFeatureNotAvailable.
+	PreserveExpiry bool
+
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// ExpiryTime is the absolute time at which the document will expire, as an alternative to Expiry. Times within
+	// 30 days are sent to the server as a relative expiry, and later times as a unix timestamp. A time in the past
+	// causes the document to expire immediately. It cannot be used together with Expiry or PreserveExpiry.
+	// UNCOMMITTED: This API may change in the future.
+	ExpiryTime time.Time
+
+	// OperationLabel is an application defined name for the logical operation that this request is a part of, e.g.
+	// "loadUserProfile". It is added to the request span and to the metrics recorded for the request, allowing them
+	// to be grouped by application level operation rather than only by KV command.
+	// Each distinct label creates a separate set of metrics, so labels should come from a small, fixed set of values
+	// and must never contain per request data such as document IDs or user IDs.
+	// UNCOMMITTED: This API may change in the future.
+	OperationLabel string
 
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
 	// also applies to global level timeouts.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
 
+	// CreateAsDeleted causes the document to be created as a tombstone, a deleted document which holds only xattrs
+	// and no body. The document is not visible to Get, but its xattrs can be read using LookupInOptions.WithDeleted.
+	// The StoreSemantic must be StoreSemanticsInsert or StoreSemanticsUpsert, and every spec must be an xattr spec.
+	// An existing tombstone is replaced when using StoreSemanticsUpsert. This requires Couchbase Server 6.6 or
+	// above, older servers return ErrFeatureNotAvailable.
+	// UNCOMMITTED: This API may change in the future.
+	CreateAsDeleted bool
+
+	// ReturnDocument causes the document to be read once the mutations have been applied, and made available via
+	// MutationResult.Content. The server does not support returning the document from a subdocument mutation, so it
+	// is read using a Get, which costs an additional round trip. If the document is modified again before it is read
+	// then Content returns ErrCasMismatch, rather than the later version of the document. If the read fails then the
+	// mutations have still been applied and Content returns the error from the read. It cannot be used together
+	// with CreateAsDeleted.
+	// UNCOMMITTED: This API may change in the future.
+	ReturnDocument bool
+
 	// Internal: This should never be used and is not supported.
 	Internal struct {
 		DocFlags SubdocDocFlag
@@ -177,11 +262,24 @@ type MutateInOptions struct {
 }
 
 // MutateIn performs a set of subdocument mutations on the document specified by id.
+// The server does not support returning the full document body from a subdocument mutation, only the results of
+// counter operations are returned within the MutateInResult, MutateInOptions.ReturnDocument can be used to read the
+// updated document once the mutations have been applied.
+// The mutations are applied atomically, in the order that they are specified, so later specs observe the effects of
+// earlier specs on the same path. Consecutive ArrayAppendSpecs to the same path with the same options are sent as a
+// single multi-value append, so appending many entries to an array does not count against the server's limit of 16
+// specs per request. Results and errors still refer to the specs by the index that they were passed in at, with the
+// failure of coalesced appends reported against the first of them.
 func (c *Collection) MutateIn(id string, ops []MutateInSpec, opts *MutateInOptions) (mutOut *MutateInResult, errOut error) {
 	if opts == nil {
 		opts = &MutateInOptions{}
 	}
 
+	expiry, err := c.mutationExpiry(opts.Expiry, opts.ExpiryTime, false)
+	if err != nil {
+		return nil, err
+	}
+
 	opm := c.newKvOpManager("mutate_in", opts.ParentSpan)
 	defer opm.Finish(false)
 
@@ -189,15 +287,89 @@ func (c *Collection) MutateIn(id string, ops []MutateInSpec, opts *MutateInOptio
 	opm.SetRetryStrategy(opts.RetryStrategy)
 	opm.SetTimeout(opts.Timeout)
 	opm.SetImpersonate(opts.Internal.User)
+	opm.SetOperationLabel(opts.OperationLabel)
 	opm.SetContext(opts.Context)
 	opm.SetPreserveExpiry(opts.PreserveExpiry)
 	opm.SetDuraOptions(opts.PersistTo, opts.ReplicateTo, opts.DurabilityLevel)
+	opm.SetDurabilityMode(opts.DurabilityMode)
 
 	if err := opm.CheckReadyForOp(); err != nil {
 		return nil, err
 	}
 
-	return c.internalMutateIn(opm, opts.StoreSemantic, opts.Expiry, opts.Cas, ops, memd.SubdocDocFlag(opts.Internal.DocFlags))
+	docFlags := memd.SubdocDocFlag(opts.Internal.DocFlags)
+	if opts.CreateAsDeleted {
+		if opts.ReturnDocument {
+			return nil, makeInvalidArgumentsError("cannot use return document with create as deleted")
+		}
+
+		if opts.StoreSemantic == StoreSemanticsReplace {
+			return nil, makeInvalidArgumentsError("cannot use create as deleted with replace store semantics")
+		}
+
+		for _, op := range ops {
+			if !op.isXattr {
+				return nil, makeInvalidArgumentsError("documents created as deleted can only contain xattrs")
+			}
+		}
+
+		if c.createAsDeletedUnsupported() {
+			return nil, wrapError(ErrFeatureNotAvailable, "creating documents as deleted is not supported by this bucket")
+		}
+
+		docFlags |= memd.SubdocDocFlagCreateAsDeleted | memd.SubdocDocFlagAccessDeleted
+	}
+
+	mutOut, errOut = c.internalMutateIn(opm, opts.StoreSemantic, expiry, opts.Cas, ops, docFlags)
+	if errOut == nil && opts.ReturnDocument {
+		c.readMutatedDocument(id, &mutOut.MutationResult, opts, opm.TraceSpan())
+	}
+
+	return
+}
+
+// readMutatedDocument reads the document once the mutations of a MutateIn have been applied, for
+// MutateInOptions.ReturnDocument.
+func (c *Collection) readMutatedDocument(id string, res *MutationResult, opts *MutateInOptions, span RequestSpan) {
+	getOpts := &GetOptions{
+		Timeout:        opts.Timeout,
+		RetryStrategy:  opts.RetryStrategy,
+		ParentSpan:     span,
+		OperationLabel: opts.OperationLabel,
+		Context:        opts.Context,
+	}
+	getOpts.Internal.User = opts.Internal.User
+
+	doc, err := c.Get(id, getOpts)
+	if err != nil {
+		res.contentErr = err
+		return
+	}
+
+	if doc.Cas() != res.Cas() {
+		res.contentErr = wrapError(ErrCasMismatch, "document was modified again before it could be read")
+		return
+	}
+
+	res.contents = doc.contents
+	res.flags = doc.flags
+	res.transcoder = doc.transcoder
+}
+
+// createAsDeletedUnsupported returns whether the bucket is known not to support creating documents as deleted. When
+// the support is not yet known the request is sent, and an older server rejects it.
+func (c *Collection) createAsDeletedUnsupported() bool {
+	if c.bucket == nil {
+		return false
+	}
+
+	provider, err := c.bucket.getKvCapabilitiesProvider()
+	if err != nil {
+		return false
+	}
+
+	status := provider.BucketCapabilityStatus(gocbcore.BucketCapabilityCreateAsDeleted)
+	return status == gocbcore.BucketCapabilityStatusUnsupported
 }
 
 func jsonMarshalMultiArray(in interface{}) ([]byte, error) {
@@ -235,10 +407,26 @@ func jsonMarshalMutateSpec(op MutateInSpec) ([]byte, memd.SubdocFlag, error) {
 	}
 
 	if macro, ok := op.value.(MutationMacro); ok {
+		switch op.op {
+		case memd.SubDocOpDictAdd, memd.SubDocOpDictSet, memd.SubDocOpReplace:
+		default:
+			return nil, memd.SubdocFlagNone,
+				makeInvalidArgumentsError("mutation macros can only be used with InsertSpec, UpsertSpec or ReplaceSpec")
+		}
+
 		return []byte(macro), memd.SubdocFlagExpandMacros | memd.SubdocFlagXattrPath, nil
 	}
 
 	if op.multiValue {
+		if values, ok := op.value.([]interface{}); ok {
+			for _, value := range values {
+				if _, ok := value.(MutationMacro); ok {
+					return nil, memd.SubdocFlagNone,
+						makeInvalidArgumentsError("mutation macros cannot be used with multiple values")
+				}
+			}
+		}
+
 		bytes, err := jsonMarshalMultiArray(op.value)
 		return bytes, memd.SubdocFlagNone, err
 	}
@@ -247,28 +435,79 @@ func jsonMarshalMutateSpec(op MutateInSpec) ([]byte, memd.SubdocFlag, error) {
 	return bytes, memd.SubdocFlagNone, err
 }
 
+// coalesceArrayAppends merges consecutive array appends to the same path, with the same flags, into a single
+// multi-value append so that they count as one operation against the server's limit on operations per request.
+// It returns the resulting operations along with the index of the operation that each original operation became.
+func coalesceArrayAppends(subdocs []gocbcore.SubDocOp) ([]gocbcore.SubDocOp, []int) {
+	opIndexes := make([]int, len(subdocs))
+	var out []gocbcore.SubDocOp
+	for i, op := range subdocs {
+		if len(out) > 0 {
+			last := &out[len(out)-1]
+			if op.Op == memd.SubDocOpArrayPushLast && last.Op == memd.SubDocOpArrayPushLast &&
+				op.Path == last.Path && op.Flags == last.Flags && op.Flags&memd.SubdocFlagExpandMacros == 0 &&
+				len(op.Value) > 0 && len(last.Value) > 0 {
+				value := make([]byte, 0, len(last.Value)+len(op.Value)+1)
+				value = append(value, last.Value...)
+				value = append(value, ',')
+				last.Value = append(value, op.Value...)
+				opIndexes[i] = len(out) - 1
+				continue
+			}
+		}
+
+		opIndexes[i] = len(out)
+		out = append(out, op)
+	}
+
+	return out, opIndexes
+}
+
+// remapSubDocErrIndex reports the failure of an operation against the index of the spec which it was built from,
+// rather than its index after coalescing. The failure of coalesced appends is reported against the first of them.
+func remapSubDocErrIndex(err error, opIndexes []int) error {
+	if kvErr, ok := err.(*gocbcore.KeyValueError); ok {
+		kvErr.InnerError = remapSubDocErrIndex(kvErr.InnerError, opIndexes)
+		return kvErr
+	}
+
+	if subdocErr, ok := err.(gocbcore.SubDocumentError); ok {
+		for i, opIdx := range opIndexes {
+			if opIdx == subdocErr.Index {
+				subdocErr.Index = i
+				break
+			}
+		}
+		return subdocErr
+	}
+
+	return err
+}
+
 func (c *Collection) internalMutateIn(
 	opm *kvOpManager,
 	action StoreSemantics,
-	expiry time.Duration,
+	expiry uint32,
 	cas Cas,
 	ops []MutateInSpec,
 	docFlags memd.SubdocDocFlag,
 ) (mutOut *MutateInResult, errOut error) {
 	preserveTTL := opm.PreserveExpiry()
-	if action == StoreSemanticsReplace {
+	if expiry > 0 && preserveTTL {
+		return nil, makeInvalidArgumentsError("cannot use preserve ttl with expiry")
+	}
+
+	switch action {
+	case StoreSemanticsReplace:
 		// this is the default behaviour
-		if expiry > 0 && preserveTTL {
-			return nil, makeInvalidArgumentsError("cannot use preserve ttl with expiry for replace store semantics")
-		}
-	} else if action == StoreSemanticsUpsert {
+	case StoreSemanticsUpsert:
 		docFlags |= memd.SubdocDocFlagMkDoc
-	} else if action == StoreSemanticsInsert {
+	case StoreSemanticsInsert:
 		if preserveTTL {
 			return nil, makeInvalidArgumentsError("cannot use preserve ttl with insert store semantics")
 		}
 		docFlags |= memd.SubdocDocFlagAddDoc
-	} else {
+	default:
 		return nil, makeInvalidArgumentsError("invalid StoreSemantics value provided")
 	}
 
@@ -288,7 +527,7 @@ func (c *Collection) internalMutateIn(
 			}
 		}
 
-		etrace := c.startKvOpTrace("request_encoding", opm.TraceSpanContext(), true)
+		etrace := c.startKvOpTrace("request_encoding", opm.TraceSpan().Context(), true)
 		bytes, flags, err := jsonMarshalMutateSpec(op)
 		etrace.End()
 		if err != nil {
@@ -311,50 +550,55 @@ func (c *Collection) internalMutateIn(
 		})
 	}
 
+	subdocs, opIndexes := coalesceArrayAppends(subdocs)
+
 	agent, err := c.getKvProvider()
 	if err != nil {
 		return nil, err
 	}
-	err = opm.Wait(agent.MutateIn(gocbcore.MutateInOptions{
-		Key:                    opm.DocumentID(),
-		Flags:                  docFlags,
-		Cas:                    gocbcore.Cas(cas),
-		Ops:                    subdocs,
-		Expiry:                 durationToExpiry(expiry),
-		CollectionName:         opm.CollectionName(),
-		ScopeName:              opm.ScopeName(),
-		DurabilityLevel:        opm.DurabilityLevel(),
-		DurabilityLevelTimeout: opm.DurabilityTimeout(),
-		RetryStrategy:          opm.RetryStrategy(),
-		TraceContext:           opm.TraceSpanContext(),
-		Deadline:               opm.Deadline(),
-		User:                   opm.Impersonate(),
-		PreserveExpiry:         preserveTTL,
-	}, func(res *gocbcore.MutateInResult, err error) {
-		if err != nil {
-			// GOCBC-1019: Due to a previous bug in gocbcore we need to convert cas mismatch back to exists.
-			if kvErr, ok := err.(*gocbcore.KeyValueError); ok {
-				if errors.Is(kvErr.InnerError, ErrCasMismatch) {
-					kvErr.InnerError = ErrDocumentExists
+	opm.WaitDurable(&errOut, func() (gocbcore.PendingOp, error) {
+		return agent.MutateIn(gocbcore.MutateInOptions{
+			Key:                    opm.DocumentID(),
+			Flags:                  docFlags,
+			Cas:                    gocbcore.Cas(cas),
+			Ops:                    subdocs,
+			Expiry:                 expiry,
+			CollectionName:         opm.CollectionName(),
+			ScopeName:              opm.ScopeName(),
+			DurabilityLevel:        opm.DurabilityLevel(),
+			DurabilityLevelTimeout: opm.DurabilityTimeout(),
+			RetryStrategy:          opm.RetryStrategy(),
+			TraceContext:           opm.TraceSpanContext(),
+			Deadline:               opm.Deadline(),
+			User:                   opm.Impersonate(),
+			PreserveExpiry:         preserveTTL,
+		}, func(res *gocbcore.MutateInResult, err error) {
+			if err != nil {
+				// GOCBC-1019: Due to a previous bug in gocbcore we need to convert cas mismatch back to exists.
+				if kvErr, ok := err.(*gocbcore.KeyValueError); ok {
+					if errors.Is(kvErr.InnerError, ErrCasMismatch) {
+						kvErr.InnerError = ErrDocumentExists
+					}
 				}
+				errOut = opm.EnhanceErr(remapSubDocErrIndex(err, opIndexes))
+				opm.Reject()
+				return
 			}
-			errOut = opm.EnhanceErr(err)
-			opm.Reject()
-			return
-		}
 
-		mutOut = &MutateInResult{}
-		mutOut.cas = Cas(res.Cas)
-		mutOut.mt = opm.EnhanceMt(res.MutationToken)
-		mutOut.contents = make([]mutateInPartial, len(res.Ops))
-		for i, op := range res.Ops {
-			mutOut.contents[i] = mutateInPartial{data: op.Value}
-		}
+			mutOut = &MutateInResult{}
+			mutOut.cas = Cas(res.Cas)
+			mutOut.serverDuration = opm.ServerDuration()
+			mutOut.retries = opm.Retries()
+			mutOut.mt = opm.EnhanceMt(res.MutationToken)
+			mutOut.contents = make([]mutateInPartial, len(opIndexes))
+			for i, opIdx := range opIndexes {
+				if opIdx < len(res.Ops) {
+					mutOut.contents[i] = mutateInPartial{data: res.Ops[opIdx].Value}
+				}
+			}
 
-		opm.Resolve(mutOut.mt)
-	}))
-	if err != nil {
-		errOut = err
-	}
+			opm.Resolve(mutOut.mt)
+		})
+	})
 	return
 }
@@ -2,7 +2,9 @@ package gocb
 
 import (
 	"errors"
+	"github.com/couchbase/gocbcore/v10"
 	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
 	"strings"
 	"time"
 )
@@ -260,6 +262,263 @@ func (suite *IntegrationTestSuite) TestMutateInBasicArray() {
 	}
 }
 
+func (suite *IntegrationTestSuite) TestMutateInArrayAddUniqueExists() {
+	suite.skipIfUnsupported(KeyValueFeature)
+	suite.skipIfUnsupported(SubdocFeature)
+	suite.skipIfUnsupported(PreserveExpiryFeature)
+
+	_, err := globalCollection.Upsert("mutateInAddUnique", map[string]interface{}{}, &UpsertOptions{
+		Expiry: 30 * time.Second,
+	})
+	suite.Require().Nil(err, err)
+
+	specs := []MutateInSpec{
+		ArrayAddUniqueSpec("audit.entries", "created", &ArrayAddUniqueSpecOptions{CreatePath: true}),
+	}
+	for i := 0; i < 20; i++ {
+		specs = append(specs, ArrayAppendSpec("audit.log", i, &ArrayAppendSpecOptions{CreatePath: true}))
+	}
+
+	_, err = globalCollection.MutateIn("mutateInAddUnique", specs, &MutateInOptions{PreserveExpiry: true})
+	suite.Require().Nil(err, err)
+
+	_, err = globalCollection.MutateIn("mutateInAddUnique", []MutateInSpec{
+		ArrayAddUniqueSpec("audit.entries", "created", nil),
+	}, &MutateInOptions{PreserveExpiry: true})
+	if !errors.Is(err, ErrPathExists) {
+		suite.T().Fatalf("Expected error to be path exists but was %v", err)
+	}
+
+	getRes, err := globalCollection.Get("mutateInAddUnique", &GetOptions{WithExpiry: true})
+	suite.Require().Nil(err, err)
+	suite.Assert().False(getRes.ExpiryTime().IsZero())
+
+	var doc struct {
+		Audit struct {
+			Entries []string `json:"entries"`
+			Log     []int    `json:"log"`
+		} `json:"audit"`
+	}
+	suite.Require().Nil(getRes.Content(&doc))
+	suite.Assert().Equal([]string{"created"}, doc.Audit.Entries)
+	suite.Require().Len(doc.Audit.Log, 20)
+	for i, entry := range doc.Audit.Log {
+		suite.Assert().Equal(i, entry)
+	}
+}
+
+func (suite *UnitTestSuite) TestMutateInCoalescesArrayAppends() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("MutateIn", mock.AnythingOfType("gocbcore.MutateInOptions"), mock.AnythingOfType("gocbcore.MutateInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.MutateInOptions)
+			cb := args.Get(1).(gocbcore.MutateInCallback)
+
+			suite.Require().Len(opts.Ops, 4)
+			suite.Assert().Equal(memd.SubDocOpArrayPushLast, opts.Ops[0].Op)
+			suite.Assert().Equal("log", opts.Ops[0].Path)
+			suite.Assert().Equal(`"a","b",1,2`, string(opts.Ops[0].Value))
+			suite.Assert().Equal(memd.SubDocOpCounter, opts.Ops[1].Op)
+			suite.Assert().Equal(`"c"`, string(opts.Ops[2].Value))
+			suite.Assert().Equal(memd.SubdocFlagXattrPath, opts.Ops[3].Flags)
+			suite.Assert().Equal(`"d"`, string(opts.Ops[3].Value))
+
+			cb(&gocbcore.MutateInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{
+					{},
+					{Value: []byte("5")},
+					{},
+					{},
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.MutateIn("someid", []MutateInSpec{
+		ArrayAppendSpec("log", "a", nil),
+		ArrayAppendSpec("log", "b", nil),
+		ArrayAppendSpec("log", []int{1, 2}, &ArrayAppendSpecOptions{HasMultiple: true}),
+		IncrementSpec("count", 1, nil),
+		ArrayAppendSpec("log", "c", nil),
+		ArrayAppendSpec("log", "d", &ArrayAppendSpecOptions{IsXattr: true}),
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	var count int
+	suite.Require().Nil(res.ContentAt(3, &count))
+	suite.Assert().Equal(5, count)
+	suite.Assert().NotNil(res.ContentAt(0, &count))
+}
+
+func (suite *UnitTestSuite) TestMutateInCoalescedErrorIndex() {
+	pendingOp := new(mockPendingOp)
+
+	provider := new(mockKvProvider)
+	provider.
+		On("MutateIn", mock.AnythingOfType("gocbcore.MutateInOptions"), mock.AnythingOfType("gocbcore.MutateInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.MutateInOptions)
+			cb := args.Get(1).(gocbcore.MutateInCallback)
+
+			suite.Require().Len(opts.Ops, 3)
+			cb(nil, &gocbcore.KeyValueError{
+				InnerError: gocbcore.SubDocumentError{
+					InnerError: gocbcore.ErrPathNotFound,
+					Index:      len(opts.Ops) - 1,
+				},
+			})
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	_, err := col.MutateIn("someid", []MutateInSpec{
+		ArrayAppendSpec("log", "a", nil),
+		ArrayAppendSpec("log", "b", nil),
+		ArrayAppendSpec("log", "c", nil),
+		UpsertSpec("name", "someone", nil),
+		ArrayAppendSpec("log", "d", nil),
+		ArrayAppendSpec("log", "e", nil),
+	}, nil)
+	suite.Require().True(errors.Is(err, ErrPathNotFound), "expected path not found but was %v", err)
+
+	var subdocErr gocbcore.SubDocumentError
+	suite.Require().True(errors.As(err, &subdocErr), "expected subdocument error but was %v", err)
+	suite.Assert().Equal(4, subdocErr.Index)
+
+	// Operations which are not coalesced keep their index.
+	suite.Assert().Equal(gocbcore.SubDocumentError{Index: 1},
+		remapSubDocErrIndex(gocbcore.SubDocumentError{Index: 1}, []int{0, 1}))
+}
+
+func (suite *UnitTestSuite) TestLookupInMacroSpecs() {
+	subdocs, err := lookupInSpecsToSubdocs([]LookupInSpec{
+		GetMacroSpec(LookupInMacroExpiryTime),
+		GetSpec(string(LookupInMacroCAS), nil),
+		ExistsSpec(string(LookupInMacroDocument), nil),
+		GetSpec("$documents", nil),
+	})
+	suite.Require().Nil(err, err)
+	suite.Require().Len(subdocs, 4)
+
+	suite.Assert().Equal(memd.SubDocOpGet, subdocs[0].Op)
+	suite.Assert().Equal("$document.exptime", subdocs[0].Path)
+	suite.Assert().Equal(memd.SubdocFlagXattrPath, subdocs[0].Flags)
+	suite.Assert().Equal(memd.SubdocFlagXattrPath, subdocs[1].Flags)
+	suite.Assert().Equal(memd.SubdocFlagXattrPath, subdocs[2].Flags)
+	suite.Assert().Equal(memd.SubdocFlagNone, subdocs[3].Flags)
+
+	_, err = lookupInSpecsToSubdocs([]LookupInSpec{
+		CountSpec(string(LookupInMacroDocument), nil),
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestMutateInMacroValidation() {
+	bytes, flags, err := jsonMarshalMutateSpec(UpsertSpec("cas", MutationMacroCAS, nil))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(`"${Mutation.CAS}"`, string(bytes))
+	suite.Assert().Equal(memd.SubdocFlagExpandMacros|memd.SubdocFlagXattrPath, flags)
+
+	provider := new(mockKvProvider)
+	col := suite.collection("mock", "", "", provider)
+
+	invalidSpecs := []MutateInSpec{
+		ArrayAppendSpec("log", MutationMacroSeqNo, nil),
+		ArrayAppendSpec("log", []interface{}{MutationMacroCAS}, &ArrayAppendSpecOptions{HasMultiple: true}),
+		ReplaceSpec("", MutationMacroCAS, nil),
+	}
+	for _, spec := range invalidSpecs {
+		_, err = col.MutateIn("someid", []MutateInSpec{spec}, nil)
+		if !errors.Is(err, ErrInvalidArgument) {
+			suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+		}
+	}
+
+	provider.AssertNotCalled(suite.T(), "MutateIn", mock.Anything, mock.Anything)
+}
+
+func (suite *UnitTestSuite) TestMutateInReturnDocument() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	readCas := gocbcore.Cas(123)
+	provider := new(mockKvProvider)
+	provider.
+		On("MutateIn", mock.AnythingOfType("gocbcore.MutateInOptions"), mock.AnythingOfType("gocbcore.MutateInCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.MutateInCallback)
+			cb(&gocbcore.MutateInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{{}},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: []byte(`{"name":"barry","age":30}`),
+				Flags: gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression),
+				Cas:   readCas,
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+	specs := []MutateInSpec{UpsertSpec("age", 30, nil)}
+
+	res, err := col.MutateIn("someid", specs, &MutateInOptions{
+		ReturnDocument: true,
+	})
+	suite.Require().Nil(err, err)
+
+	var content map[string]interface{}
+	suite.Require().Nil(res.Content(&content))
+	suite.Assert().Equal(map[string]interface{}{"name": "barry", "age": float64(30)}, content)
+
+	// A document which has been modified again since is not returned.
+	readCas = gocbcore.Cas(124)
+	res, err = col.MutateIn("someid", specs, &MutateInOptions{
+		ReturnDocument: true,
+	})
+	suite.Require().Nil(err, err)
+
+	err = res.Content(&content)
+	if !errors.Is(err, ErrCasMismatch) {
+		suite.T().Fatalf("Expected error to be cas mismatch but was %v", err)
+	}
+
+	res, err = col.MutateIn("someid", specs, nil)
+	suite.Require().Nil(err, err)
+
+	err = res.Content(&content)
+	if !errors.Is(err, ErrNoResult) {
+		suite.T().Fatalf("Expected error to be no result but was %v", err)
+	}
+	provider.AssertNumberOfCalls(suite.T(), "Get", 2)
+
+	_, err = col.MutateIn("someid", []MutateInSpec{UpsertSpec("x", 1, &UpsertSpecOptions{IsXattr: true})},
+		&MutateInOptions{
+			StoreSemantic:   StoreSemanticsInsert,
+			CreateAsDeleted: true,
+			ReturnDocument:  true,
+		})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
 func (suite *IntegrationTestSuite) TestInsertLookupInInsertGetFull() {
 	suite.skipIfUnsupported(KeyValueFeature)
 	suite.skipIfUnsupported(SubdocFeature)
@@ -798,3 +1057,141 @@ func (suite *IntegrationTestSuite) TestMutateInLookupInCountersXattrs() {
 		suite.T().Fatalf("Expected counter to be 25 but was %v", counter)
 	}
 }
+
+func (suite *IntegrationTestSuite) TestMutateInCreateAsDeleted() {
+	suite.skipIfUnsupported(KeyValueFeature)
+	suite.skipIfUnsupported(XattrFeature)
+	suite.skipIfUnsupported(CreateAsDeletedFeature)
+
+	_, err := globalCollection.MutateIn("createasdeleted", []MutateInSpec{
+		UpsertSpec("sync", map[string]string{"rev": "1-a"}, &UpsertSpecOptions{IsXattr: true}),
+	}, &MutateInOptions{StoreSemantic: StoreSemanticsInsert, CreateAsDeleted: true})
+	suite.Require().Nil(err, err)
+
+	_, err = globalCollection.Get("createasdeleted", nil)
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected document not found error but was %v", err)
+	}
+
+	_, err = globalCollection.LookupIn("createasdeleted", []LookupInSpec{
+		GetSpec("sync", &GetSpecOptions{IsXattr: true}),
+	}, nil)
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Expected document not found error but was %v", err)
+	}
+
+	res, err := globalCollection.LookupIn("createasdeleted", []LookupInSpec{
+		GetSpec("sync", &GetSpecOptions{IsXattr: true}),
+	}, &LookupInOptions{WithDeleted: true})
+	suite.Require().Nil(err, err)
+	suite.Assert().True(res.IsDeleted())
+
+	var sync map[string]string
+	suite.Require().Nil(res.ContentAt(0, &sync))
+	suite.Assert().Equal(map[string]string{"rev": "1-a"}, sync)
+
+	// Upserting replaces the existing tombstone.
+	_, err = globalCollection.MutateIn("createasdeleted", []MutateInSpec{
+		UpsertSpec("sync", map[string]string{"rev": "2-b"}, &UpsertSpecOptions{IsXattr: true}),
+	}, &MutateInOptions{StoreSemantic: StoreSemanticsUpsert, CreateAsDeleted: true})
+	suite.Require().Nil(err, err)
+
+	res, err = globalCollection.LookupIn("createasdeleted", []LookupInSpec{
+		GetSpec("sync.rev", &GetSpecOptions{IsXattr: true}),
+	}, &LookupInOptions{WithDeleted: true})
+	suite.Require().Nil(err, err)
+
+	var rev string
+	suite.Require().Nil(res.ContentAt(0, &rev))
+	suite.Assert().Equal("2-b", rev)
+}
+
+func (suite *UnitTestSuite) TestMutateInCreateAsDeleted() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("MutateIn", mock.AnythingOfType("gocbcore.MutateInOptions"), mock.AnythingOfType("gocbcore.MutateInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.MutateInOptions)
+			cb := args.Get(1).(gocbcore.MutateInCallback)
+
+			suite.Assert().Equal(
+				memd.SubdocDocFlagAddDoc|memd.SubdocDocFlagCreateAsDeleted|memd.SubdocDocFlagAccessDeleted, opts.Flags)
+
+			cb(&gocbcore.MutateInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{{}},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			suite.Assert().Equal(memd.SubdocDocFlagAccessDeleted, opts.Flags)
+
+			res := &gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{{Value: []byte(`"1-a"`)}},
+			}
+			res.Internal.IsDeleted = true
+			cb(res, nil)
+		}).
+		Return(pendingOp, nil)
+
+	capabilities := new(mockKvCapabilityVerifier)
+	capabilities.
+		On("BucketCapabilityStatus", gocbcore.BucketCapabilityCreateAsDeleted).
+		Return(gocbcore.BucketCapabilityStatusSupported).
+		Once()
+	capabilities.
+		On("BucketCapabilityStatus", gocbcore.BucketCapabilityCreateAsDeleted).
+		Return(gocbcore.BucketCapabilityStatusUnsupported)
+
+	cli := new(mockConnectionManager)
+	cli.On("getKvCapabilitiesProvider", "mock").Return(capabilities, nil)
+
+	col := suite.collection("mock", "", "", provider)
+	col.bucket = suite.bucket("mock", suite.defaultTimeoutConfig(), cli)
+
+	specs := []MutateInSpec{
+		UpsertSpec("sync.rev", "1-a", &UpsertSpecOptions{IsXattr: true, CreatePath: true}),
+	}
+	_, err := col.MutateIn("someid", specs, &MutateInOptions{
+		StoreSemantic:   StoreSemanticsInsert,
+		CreateAsDeleted: true,
+	})
+	suite.Require().Nil(err, err)
+
+	res, err := col.LookupIn("someid", []LookupInSpec{
+		GetSpec("sync.rev", &GetSpecOptions{IsXattr: true}),
+	}, &LookupInOptions{WithDeleted: true})
+	suite.Require().Nil(err, err)
+	suite.Assert().True(res.IsDeleted())
+
+	_, err = col.MutateIn("someid", specs, &MutateInOptions{CreateAsDeleted: true})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid args error but was %v", err)
+	}
+
+	_, err = col.MutateIn("someid", []MutateInSpec{
+		UpsertSpec("rev", "1-a", nil),
+	}, &MutateInOptions{StoreSemantic: StoreSemanticsInsert, CreateAsDeleted: true})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid args error but was %v", err)
+	}
+
+	// The second capability check reports that the bucket does not support it.
+	_, err = col.MutateIn("someid", specs, &MutateInOptions{
+		StoreSemantic:   StoreSemanticsInsert,
+		CreateAsDeleted: true,
+	})
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected feature not available error but was %v", err)
+	}
+	provider.AssertNumberOfCalls(suite.T(), "MutateIn", 1)
+}
@@ -0,0 +1,86 @@
+package gocb
+
+import (
+	"context"
+	"time"
+)
+
+// TouchMultiOptions are the set of options available to the TouchMulti operation.
+// UNCOMMITTED: This API may change in the future.
+type TouchMultiOptions struct {
+	// Timeout applies to the TouchMulti call as a whole rather than to each document. Defaults to the KV timeout
+	// multiplied by the number of documents.
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// TouchMultiResult is the result of touching a single document as part of TouchMulti. Exactly one of Result and Err
+// is set.
+// UNCOMMITTED: This API may change in the future.
+type TouchMultiResult struct {
+	Result *MutationResult
+	Err    error
+}
+
+// TouchMulti updates the expiry time of the documents with the given ids, returning a map containing an entry for
+// every id.
+// The documents are touched using the same pipeline as Do, so each touch succeeds or fails independently and an error
+// such as ErrDocumentNotFound is reported on the entry for that id rather than failing the whole call. An error is
+// only returned if the documents could not be touched at all.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) TouchMulti(
+	ids []string,
+	expiry time.Duration,
+	opts *TouchMultiOptions,
+) (map[string]TouchMultiResult, error) {
+	if opts == nil {
+		opts = &TouchMultiOptions{}
+	}
+
+	results := make(map[string]TouchMultiResult, len(ids))
+	ops := make([]BulkOp, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := results[id]; ok {
+			continue
+		}
+
+		if id == "" {
+			results[id] = TouchMultiResult{Err: makeInvalidArgumentsError("id cannot be empty")}
+			continue
+		}
+
+		// Placeholder so that duplicate ids are only touched once.
+		results[id] = TouchMultiResult{}
+		ops = append(ops, &TouchOp{ID: id, Expiry: expiry})
+	}
+
+	if len(ops) == 0 {
+		return results, nil
+	}
+
+	err := c.Do(ops, &BulkOpOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		touchOp := op.(*TouchOp)
+		results[touchOp.ID] = TouchMultiResult{
+			Result: touchOp.Result,
+			Err:    touchOp.Err,
+		}
+	}
+
+	return results, nil
+}
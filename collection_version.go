@@ -0,0 +1,185 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const defaultVersionCasRetries = 10
+
+// VersionMismatchError occurs when ReplaceIfVersion finds that the version field of a document does not hold the
+// expected value.
+// UNCOMMITTED: This API may change in the future.
+type VersionMismatchError struct {
+	DocumentID string
+	Path       string
+
+	// Expected is the version which the document was expected to have.
+	Expected interface{}
+
+	// Actual is the version which the document has, decoded from JSON, or nil if the document has no version field.
+	Actual interface{}
+}
+
+// Error returns the string representation of this error.
+func (e *VersionMismatchError) Error() string {
+	if e.Actual == nil {
+		return fmt.Sprintf("version mismatch: document %s has no %s field, expected %v", e.DocumentID, e.Path,
+			e.Expected)
+	}
+
+	return fmt.Sprintf("version mismatch: document %s has %s %v but expected %v", e.DocumentID, e.Path, e.Actual,
+		e.Expected)
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *VersionMismatchError) Unwrap() error {
+	return ErrCasMismatch
+}
+
+// ReplaceIfVersionOptions are the options available to the ReplaceIfVersion operation.
+// UNCOMMITTED: This API may change in the future.
+type ReplaceIfVersionOptions struct {
+	// CasRetries is the number of times that the operation is retried if the document is concurrently modified
+	// between being read and written without its version field changing, defaults to 10.
+	CasRetries uint32
+
+	Expiry          time.Duration
+	PreserveExpiry  bool
+	PersistTo       uint
+	ReplicateTo     uint
+	DurabilityLevel DurabilityLevel
+	Transcoder      Transcoder
+	Timeout         time.Duration
+	RetryStrategy   RetryStrategy
+	ParentSpan      RequestSpan
+
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// ReplaceIfVersion replaces the body of the document identified by id with val, but only if the field at versionPath
+// within the current body equals expectedVersion, otherwise a *VersionMismatchError is returned. This allows
+// optimistic concurrency to be based on a version field held within the document, such as one shared with other
+// systems, rather than on CAS. Values are compared by their JSON representation, so a version of 3 matches 3.0.
+// The version field is not updated automatically, val should contain the new version.
+//
+// The server cannot apply a mutation conditionally on the contents of a field, so this takes two round trips: the
+// version field and CAS are read using LookupIn, and the document is then replaced using that CAS. The CAS ensures
+// that the document cannot have been modified between the version being checked and the replace being applied. If
+// the document is modified in between then the version is checked again, and the replace retried, up to CasRetries
+// times, after which ErrCasMismatch is returned. Timeout applies to each individual read and write.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) ReplaceIfVersion(id string, versionPath string, expectedVersion interface{}, val interface{},
+	opts *ReplaceIfVersionOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &ReplaceIfVersionOptions{}
+	}
+
+	if versionPath == "" {
+		return nil, makeInvalidArgumentsError("version path cannot be empty")
+	}
+
+	expected, err := normalizeVersion(expectedVersion)
+	if err != nil {
+		return nil, makeInvalidArgumentsError("expected version must be encodable as JSON")
+	}
+
+	casRetries := opts.CasRetries
+	if casRetries == 0 {
+		casRetries = defaultVersionCasRetries
+	}
+
+	for attempt := uint32(0); ; attempt++ {
+		lookupRes, err := c.LookupIn(id, []LookupInSpec{
+			GetSpec(versionPath, nil),
+		}, &LookupInOptions{
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var actual interface{}
+		var raw json.RawMessage
+		err = lookupRes.ContentAt(0, &raw)
+		if err == nil {
+			actual, err = normalizeVersion(raw)
+			if err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, ErrPathNotFound) {
+			return nil, err
+		}
+
+		if actual == nil || !reflect.DeepEqual(actual, expected) {
+			return nil, &VersionMismatchError{
+				DocumentID: id,
+				Path:       versionPath,
+				Expected:   expectedVersion,
+				Actual:     actual,
+			}
+		}
+
+		res, err := c.Replace(id, val, &ReplaceOptions{
+			Cas:             lookupRes.Cas(),
+			Expiry:          opts.Expiry,
+			PreserveExpiry:  opts.PreserveExpiry,
+			PersistTo:       opts.PersistTo,
+			ReplicateTo:     opts.ReplicateTo,
+			DurabilityLevel: opts.DurabilityLevel,
+			DurabilityMode:  opts.DurabilityMode,
+			Transcoder:      opts.Transcoder,
+			Timeout:         opts.Timeout,
+			RetryStrategy:   opts.RetryStrategy,
+			ParentSpan:      opts.ParentSpan,
+			Context:         opts.Context,
+		})
+		if err == nil {
+			return res, nil
+		}
+
+		if !errors.Is(err, ErrCasMismatch) {
+			return nil, err
+		}
+
+		if attempt >= casRetries {
+			return nil, wrapError(ErrCasMismatch, "document was concurrently modified")
+		}
+	}
+}
+
+// normalizeVersion converts a version into the value it decodes to from JSON, so that versions can be compared
+// regardless of the Go type they were provided as.
+func normalizeVersion(version interface{}) (interface{}, error) {
+	data, ok := version.(json.RawMessage)
+	if !ok {
+		var err error
+		data, err = json.Marshal(version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var normalized interface{}
+	err := json.Unmarshal(data, &normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
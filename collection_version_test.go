@@ -0,0 +1,121 @@
+package gocb
+
+import (
+	"errors"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestReplaceIfVersion() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	storedVersion := []byte("3")
+	var replaceAttempts int
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.LookupInOptions)
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+
+			suite.Require().Len(opts.Ops, 1)
+			suite.Assert().Equal(memd.SubDocOpGet, opts.Ops[0].Op)
+			suite.Assert().Equal("meta.version", opts.Ops[0].Path)
+
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{
+					{Value: storedVersion},
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("Replace", mock.AnythingOfType("gocbcore.ReplaceOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.ReplaceOptions)
+			cb := args.Get(1).(gocbcore.StoreCallback)
+
+			suite.Assert().Equal(gocbcore.Cas(123), opts.Cas)
+
+			replaceAttempts++
+			if replaceAttempts == 1 {
+				// The document is modified by someone else without the version changing.
+				cb(nil, ErrCasMismatch)
+				return
+			}
+
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(124),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	doc := map[string]interface{}{"meta": map[string]int{"version": 4}}
+	res, err := col.ReplaceIfVersion("someid", "meta.version", 3.0, doc, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(Cas(124), res.Cas())
+	suite.Assert().Equal(2, replaceAttempts)
+
+	storedVersion = []byte("5")
+	replaceAttempts = 0
+	_, err = col.ReplaceIfVersion("someid", "meta.version", 3, doc, nil)
+	var mismatchErr *VersionMismatchError
+	suite.Require().True(errors.As(err, &mismatchErr), "expected version mismatch but was %v", err)
+	suite.Assert().Equal(float64(5), mismatchErr.Actual)
+	suite.Assert().Equal(3, mismatchErr.Expected)
+	suite.Assert().True(errors.Is(err, ErrCasMismatch))
+	suite.Assert().Zero(replaceAttempts)
+
+	_, err = col.ReplaceIfVersion("someid", "", 3, doc, nil)
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument))
+}
+
+func (suite *UnitTestSuite) TestReplaceIfVersionCasRetries() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var replaceAttempts uint32
+	provider := new(mockKvProvider)
+	provider.
+		On("LookupIn", mock.AnythingOfType("gocbcore.LookupInOptions"), mock.AnythingOfType("gocbcore.LookupInCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.LookupInCallback)
+			cb(&gocbcore.LookupInResult{
+				Cas: gocbcore.Cas(123),
+				Ops: []gocbcore.SubDocResult{
+					{Value: []byte("3")},
+				},
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("Replace", mock.AnythingOfType("gocbcore.ReplaceOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.StoreCallback)
+
+			// The document is always modified by someone else without the version changing.
+			replaceAttempts++
+			cb(nil, ErrCasMismatch)
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "", "", provider)
+
+	// The replace is made once and then retried CasRetries times.
+	_, err := col.ReplaceIfVersion("someid", "version", 3, map[string]int{"version": 4}, &ReplaceIfVersionOptions{
+		CasRetries: 3,
+	})
+	suite.Assert().True(errors.Is(err, ErrCasMismatch), "expected cas mismatch but was %v", err)
+	suite.Assert().Equal(uint32(4), replaceAttempts)
+
+	replaceAttempts = 0
+	_, err = col.ReplaceIfVersion("someid", "version", 3, map[string]int{"version": 4}, nil)
+	suite.Assert().True(errors.Is(err, ErrCasMismatch), "expected cas mismatch but was %v", err)
+	suite.Assert().Equal(uint32(defaultVersionCasRetries+1), replaceAttempts)
+}
@@ -0,0 +1,167 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultWithLockCasRetries = 10
+
+// WithLockOptions are the options available to the WithLock operation.
+// UNCOMMITTED: This API may change in the future.
+type WithLockOptions struct {
+	// CasRetries is the number of times that the operation is retried if the lock expires and the document is
+	// modified by someone else before it is replaced, defaults to 10.
+	CasRetries uint32
+
+	// Backoff calculates how long to wait before locking the document again after a CAS mismatch, given the number
+	// of attempts which have been retried so far. Defaults to an exponential backoff from 1ms up to 500ms.
+	Backoff BackoffCalculator
+
+	Expiry          time.Duration
+	PreserveExpiry  bool
+	PersistTo       uint
+	ReplicateTo     uint
+	DurabilityLevel DurabilityLevel
+	Transcoder      Transcoder
+	Timeout         time.Duration
+	RetryStrategy   RetryStrategy
+	ParentSpan      RequestSpan
+
+	// DurabilityMode specifies whether the mutation fails, or is applied with reduced durability, when its durability
+	// requirements cannot be met. Defaults to DurabilityModeStrict, which fails with ErrDurabilityImpossible.
+	// UNCOMMITTED: This API may change in the future.
+	DurabilityMode DurabilityMode
+
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// WithLock performs a read-modify-write of the document identified by id whilst holding a lock on it. The document is
+// fetched using GetAndLock and passed to fn, and the value returned by fn is then written using Replace with the CAS
+// of the lock, which also releases the lock. If fn returns an error wrapping ErrWithLockNoChange then the document is
+// unlocked without being modified and a nil result is returned. If fn returns any other error then the document is
+// unlocked and the error is returned.
+//
+// The lock is released by the server once lockTime has passed, at which point the document can be modified by
+// others. If that happens before the replace is applied then the replace fails with a CAS mismatch, and the whole
+// operation is retried after waiting for Backoff, up to CasRetries times, after which ErrCasMismatch is returned. fn
+// may therefore be called more than once and should not have side effects. No other errors are retried.
+//
+// The durability requirements apply only to the replace. If the replace fails with an ambiguous error, such as
+// ErrDurabilityAmbiguous, then the replace may have been applied, and it is not retried. Timeout applies to each
+// individual lock, replace and unlock.
+// UNCOMMITTED: This API may change in the future.
+func (c *Collection) WithLock(id string, lockTime time.Duration, fn func(GetResult) (interface{}, error),
+	opts *WithLockOptions) (*MutationResult, error) {
+	if opts == nil {
+		opts = &WithLockOptions{}
+	}
+
+	if fn == nil {
+		return nil, makeInvalidArgumentsError("fn cannot be nil")
+	}
+
+	casRetries := opts.CasRetries
+	if casRetries == 0 {
+		casRetries = defaultWithLockCasRetries
+	}
+
+	backoff := opts.Backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(1*time.Millisecond, 500*time.Millisecond, 2)
+	}
+
+	for attempt := uint32(0); ; attempt++ {
+		lockRes, err := c.GetAndLock(id, lockTime, &GetAndLockOptions{
+			Transcoder:    opts.Transcoder,
+			Timeout:       opts.Timeout,
+			RetryStrategy: opts.RetryStrategy,
+			ParentSpan:    opts.ParentSpan,
+			Context:       opts.Context,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := fn(*lockRes)
+		if err != nil {
+			c.unlockWithLock(id, lockRes.Cas(), opts)
+			if errors.Is(err, ErrWithLockNoChange) {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		res, err := c.Replace(id, val, &ReplaceOptions{
+			Cas:             lockRes.Cas(),
+			Expiry:          opts.Expiry,
+			PreserveExpiry:  opts.PreserveExpiry,
+			PersistTo:       opts.PersistTo,
+			ReplicateTo:     opts.ReplicateTo,
+			DurabilityLevel: opts.DurabilityLevel,
+			DurabilityMode:  opts.DurabilityMode,
+			Transcoder:      opts.Transcoder,
+			Timeout:         opts.Timeout,
+			RetryStrategy:   opts.RetryStrategy,
+			ParentSpan:      opts.ParentSpan,
+			Context:         opts.Context,
+		})
+		if err == nil {
+			return res, nil
+		}
+
+		if !errors.Is(err, ErrCasMismatch) {
+			// The replace may have failed without the document being modified, in which case it is still locked. If
+			// the replace was applied then the CAS no longer matches and this fails harmlessly.
+			c.unlockWithLock(id, lockRes.Cas(), opts)
+			return nil, err
+		}
+
+		if attempt >= casRetries {
+			return nil, wrapError(ErrCasMismatch, "document was concurrently modified")
+		}
+
+		if err := waitWithLockBackoff(opts.Context, backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// unlockWithLock releases a lock taken by WithLock. Failures are only logged as the lock is released by the server
+// once the lock time has passed anyway.
+func (c *Collection) unlockWithLock(id string, cas Cas, opts *WithLockOptions) {
+	err := c.Unlock(id, cas, &UnlockOptions{
+		Timeout:       opts.Timeout,
+		RetryStrategy: opts.RetryStrategy,
+		ParentSpan:    opts.ParentSpan,
+		Context:       opts.Context,
+	})
+	if err != nil {
+		logDebugf("Failed to release lock taken by WithLock: %v", err)
+	}
+}
+
+func waitWithLockBackoff(ctx context.Context, backoff time.Duration) error {
+	if backoff <= 0 {
+		return nil
+	}
+	if ctx == nil {
+		time.Sleep(backoff)
+		return nil
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return maybeWrapContextErr(ctx, ErrRequestCanceled)
+	}
+}
@@ -0,0 +1,135 @@
+package gocb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) withLockProvider(replaceErrs []error) (*mockKvProvider, *int) {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	var lockCas uint64 = 100
+	var replaceAttempts int
+	provider := new(mockKvProvider)
+	provider.
+		On("GetAndLock", mock.AnythingOfType("gocbcore.GetAndLockOptions"), mock.AnythingOfType("gocbcore.GetAndLockCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetAndLockCallback)
+
+			lockCas++
+			cb(&gocbcore.GetAndLockResult{
+				Value: []byte(`{"count":1}`),
+				Flags: gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression),
+				Cas:   gocbcore.Cas(lockCas),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("Replace", mock.AnythingOfType("gocbcore.ReplaceOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.ReplaceOptions)
+			cb := args.Get(1).(gocbcore.StoreCallback)
+
+			suite.Assert().Equal(gocbcore.Cas(lockCas), opts.Cas)
+			suite.Assert().Equal(`{"count":2}`, string(opts.Value))
+
+			replaceAttempts++
+			if replaceAttempts <= len(replaceErrs) {
+				cb(nil, replaceErrs[replaceAttempts-1])
+				return
+			}
+
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(lockCas + 1000),
+			}, nil)
+		}).
+		Return(pendingOp, nil)
+	provider.
+		On("Unlock", mock.AnythingOfType("gocbcore.UnlockOptions"), mock.AnythingOfType("gocbcore.UnlockCallback")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(0).(gocbcore.UnlockOptions)
+			cb := args.Get(1).(gocbcore.UnlockCallback)
+
+			suite.Assert().Equal(gocbcore.Cas(lockCas), opts.Cas)
+			cb(&gocbcore.UnlockResult{}, nil)
+		}).
+		Return(pendingOp, nil)
+
+	return provider, &replaceAttempts
+}
+
+func withLockIncrement(res GetResult) (interface{}, error) {
+	var doc map[string]int
+	if err := res.Content(&doc); err != nil {
+		return nil, err
+	}
+
+	doc["count"]++
+	return doc, nil
+}
+
+func (suite *UnitTestSuite) TestWithLockRetriesCasMismatch() {
+	// The lock expires and the document is modified by someone else twice before the replace is applied.
+	provider, replaceAttempts := suite.withLockProvider([]error{ErrCasMismatch, ErrCasMismatch})
+	col := suite.collection("mock", "", "", provider)
+
+	var backoffs []uint32
+	var calls int
+	res, err := col.WithLock("someid", 5*time.Second, func(res GetResult) (interface{}, error) {
+		calls++
+		return withLockIncrement(res)
+	}, &WithLockOptions{
+		Backoff: func(retryAttempts uint32) time.Duration {
+			backoffs = append(backoffs, retryAttempts)
+			return 0
+		},
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(Cas(1103), res.Cas())
+	suite.Assert().Equal(3, calls)
+	suite.Assert().Equal(3, *replaceAttempts)
+	suite.Assert().Equal([]uint32{0, 1}, backoffs)
+	provider.AssertNumberOfCalls(suite.T(), "GetAndLock", 3)
+	provider.AssertNotCalled(suite.T(), "Unlock", mock.Anything, mock.Anything)
+}
+
+func (suite *UnitTestSuite) TestWithLockCasRetriesExhausted() {
+	provider, replaceAttempts := suite.withLockProvider([]error{ErrCasMismatch, ErrCasMismatch, ErrCasMismatch})
+	col := suite.collection("mock", "", "", provider)
+
+	_, err := col.WithLock("someid", 5*time.Second, withLockIncrement, &WithLockOptions{
+		CasRetries: 2,
+		Backoff: func(retryAttempts uint32) time.Duration {
+			return 0
+		},
+	})
+	suite.Assert().True(errors.Is(err, ErrCasMismatch), "expected cas mismatch but was %v", err)
+	suite.Assert().Equal(3, *replaceAttempts)
+}
+
+func (suite *UnitTestSuite) TestWithLockUnlocks() {
+	provider, replaceAttempts := suite.withLockProvider(nil)
+	col := suite.collection("mock", "", "", provider)
+
+	res, err := col.WithLock("someid", 5*time.Second, func(res GetResult) (interface{}, error) {
+		return nil, ErrWithLockNoChange
+	}, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Nil(res)
+	provider.AssertNumberOfCalls(suite.T(), "Unlock", 1)
+
+	callbackErr := errors.New("callback failed")
+	_, err = col.WithLock("someid", 5*time.Second, func(res GetResult) (interface{}, error) {
+		return nil, callbackErr
+	}, nil)
+	suite.Assert().Equal(callbackErr, err)
+	provider.AssertNumberOfCalls(suite.T(), "Unlock", 2)
+	suite.Assert().Zero(*replaceAttempts)
+
+	_, err = col.WithLock("someid", 5*time.Second, nil, nil)
+	suite.Assert().True(errors.Is(err, ErrInvalidArgument))
+}
@@ -195,6 +195,27 @@ func (dl DurabilityLevel) toMemd() (memd.DurabilityLevel, error) {
 	}
 }
 
+// DurabilityMode specifies how a durable mutation behaves when its durability requirements cannot be met, for
+// example because the bucket has fewer replicas available than the durability requires.
+// UNCOMMITTED: This API may change in the future.
+type DurabilityMode uint8
+
+const (
+	// DurabilityModeStrict causes a mutation whose durability requirements cannot be met to fail with
+	// ErrDurabilityImpossible, this is the default. A mutation using DurabilityLevel is rejected by the server without
+	// being applied, whereas a mutation using PersistTo or ReplicateTo has already been applied when the error is
+	// returned.
+	DurabilityModeStrict DurabilityMode = iota
+
+	// DurabilityModeBestEffort causes a mutation whose durability requirements cannot be met to be applied with
+	// whatever durability is possible. A mutation using DurabilityLevel is sent again without a durability level,
+	// once the RetryStrategy has declined to retry it. PersistTo and ReplicateTo are reduced to the number of
+	// configured nodes.
+	// A minimum durability level set on the bucket is enforced by the server and cannot be reduced by the SDK, so
+	// mutations which cannot meet it fail with ErrDurabilityImpossible in either mode.
+	DurabilityModeBestEffort
+)
+
 func durabilityLevelFromManagementAPI(level string) DurabilityLevel {
 	switch level {
 	case "majority":
@@ -209,6 +230,8 @@ func durabilityLevelFromManagementAPI(level string) DurabilityLevel {
 }
 
 // MutationMacro can be supplied to MutateIn operations to perform ExpandMacros operations.
+// Macros can only be used as the value of an InsertSpec, UpsertSpec or ReplaceSpec, and are always written to an
+// extended attribute, the SDK sets the xattr and expand macros flags automatically.
 type MutationMacro string
 
 const (
@@ -222,6 +245,43 @@ const (
 	MutationMacroValueCRC32c MutationMacro = "\"${Mutation.value_crc32c}\""
 )
 
+// LookupInMacro is a virtual extended attribute which can be retrieved by LookupIn operations, see GetMacroSpec.
+// UNCOMMITTED: This API may change in the future.
+type LookupInMacro string
+
+const (
+	// LookupInMacroDocument retrieves all of the metadata of the document as a JSON object.
+	LookupInMacroDocument LookupInMacro = "$document"
+
+	// LookupInMacroExpiryTime retrieves the expiry time of the document as a unix timestamp, or 0 if the document
+	// does not expire.
+	LookupInMacroExpiryTime LookupInMacro = "$document.exptime"
+
+	// LookupInMacroCAS retrieves the CAS of the document as a hex string.
+	LookupInMacroCAS LookupInMacro = "$document.CAS"
+
+	// LookupInMacroSeqNo retrieves the sequence number of the last mutation of the document as a hex string.
+	LookupInMacroSeqNo LookupInMacro = "$document.seqno"
+
+	// LookupInMacroVbucketUUID retrieves the UUID of the vbucket which holds the document as a hex string.
+	LookupInMacroVbucketUUID LookupInMacro = "$document.vbucket_uuid"
+
+	// LookupInMacroLastModified retrieves the time at which the document was last modified as a unix timestamp.
+	LookupInMacroLastModified LookupInMacro = "$document.last_modified"
+
+	// LookupInMacroIsDeleted retrieves whether the document is a tombstone.
+	LookupInMacroIsDeleted LookupInMacro = "$document.deleted"
+
+	// LookupInMacroValueSizeBytes retrieves the size of the document body in bytes.
+	LookupInMacroValueSizeBytes LookupInMacro = "$document.value_bytes"
+
+	// LookupInMacroRevID retrieves the revision ID of the document.
+	LookupInMacroRevID LookupInMacro = "$document.revid"
+
+	// LookupInMacroFlags retrieves the flags of the document, which describe the format of the document body.
+	LookupInMacroFlags LookupInMacro = "$document.flags"
+)
+
 // ClusterState specifies the current state of the cluster
 type ClusterState uint
 
@@ -253,6 +313,27 @@ const (
 	EndpointStateDisconnecting
 )
 
+// ConnectFailureReason specifies why a bootstrap host could not be connected to, see ConnectError.
+// UNCOMMITTED: This API may change in the future.
+type ConnectFailureReason uint
+
+const (
+	// ConnectFailureReasonUnknown indicates that the host was reachable but the cause of the failure is not known.
+	ConnectFailureReasonUnknown ConnectFailureReason = iota + 1
+
+	// ConnectFailureReasonDNS indicates that the hostname could not be resolved.
+	ConnectFailureReasonDNS
+
+	// ConnectFailureReasonTCP indicates that a TCP connection could not be established, e.g. it was refused.
+	ConnectFailureReasonTCP
+
+	// ConnectFailureReasonTLS indicates that the TLS handshake failed, e.g. the certificate could not be verified.
+	ConnectFailureReasonTLS
+
+	// ConnectFailureReasonAuthentication indicates that the credentials were rejected.
+	ConnectFailureReasonAuthentication
+)
+
 // PingState specifies the result of the ping operation
 type PingState uint
 
@@ -322,10 +403,15 @@ const (
 	spanAttribDBCollectionNameKey = "db.couchbase.collection"
 	spanAttribDBScopeNameKey      = "db.couchbase.scope"
 	spanAttribDBDurability        = "db.couchbase.durability"
+	spanAttribOperationLabelKey   = "db.couchbase.operation_label"
 
 	meterNameCBOperations       = "db.couchbase.operations"
 	meterAttribServiceKey       = "db.couchbase.service"
 	meterAttribOperationKey     = "db.operation"
+	meterAttribOperationLabel   = "db.couchbase.operation_label"
+	meterAttribBucketKey        = "db.name"
+	meterAttribScopeKey         = "db.couchbase.scope"
+	meterAttribCollectionKey    = "db.couchbase.collection"
 	meterValueServiceKV         = "kv"
 	meterValueServiceQuery      = "query"
 	meterValueServiceAnalytics  = "analytics"
@@ -375,3 +461,21 @@ func analyticsEncryptionLevelFromString(level string) AnalyticsEncryptionLevel {
 
 	return AnalyticsEncryptionLevelNone
 }
+
+// ReadPreference specifies which copies of a document a read may be served from.
+// UNCOMMITTED: This API may change in the future.
+type ReadPreference uint8
+
+const (
+	// ReadPreferenceNoPreference reads the document from the active copy.
+	ReadPreferenceNoPreference ReadPreference = iota
+
+	// ReadPreferenceSelectedServerGroup reads the document from a copy, either the active or a replica, held by a
+	// node in the server group given by ClusterOptions.PreferredServerGroup. If no copy is held within the server
+	// group then the read fails with ErrDocumentUnretrievable.
+	ReadPreferenceSelectedServerGroup
+
+	// ReadPreferenceSelectedServerGroupOrAllAvailable behaves as ReadPreferenceSelectedServerGroup, but reads the
+	// document from the active copy if it cannot be read from a copy within the server group.
+	ReadPreferenceSelectedServerGroupOrAllAvailable
+)
@@ -44,6 +44,22 @@ func endpointStateToString(state EndpointState) string {
 	return ""
 }
 
+func connectFailureReasonToString(reason ConnectFailureReason) string {
+	switch reason {
+	case ConnectFailureReasonUnknown:
+		return "unknown"
+	case ConnectFailureReasonDNS:
+		return "dns"
+	case ConnectFailureReasonTCP:
+		return "tcp"
+	case ConnectFailureReasonTLS:
+		return "tls"
+	case ConnectFailureReasonAuthentication:
+		return "authentication"
+	}
+	return ""
+}
+
 func pingStateToString(state PingState) string {
 	switch state {
 	case PingStateOk:
@@ -55,3 +71,15 @@ func pingStateToString(state PingState) string {
 	}
 	return ""
 }
+
+func capabilityToString(capability Capability) string {
+	switch capability {
+	case CapabilityDurableWrites:
+		return "durable_writes"
+	case CapabilityCreateAsDeleted:
+		return "create_as_deleted"
+	case CapabilityReplaceBodyWithXattr:
+		return "replace_body_with_xattr"
+	}
+	return ""
+}
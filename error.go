@@ -143,7 +143,8 @@ var (
 	ErrDurabilityLevelNotAvailable = gocbcore.ErrDurabilityLevelNotAvailable
 
 	// ErrDurabilityImpossible occurs when a request is performed with impossible
-	// durability level requirements.
+	// durability level requirements. Mutations using DurabilityModeBestEffort only return
+	// this when the bucket's minimum durability level cannot be met.
 	ErrDurabilityImpossible = gocbcore.ErrDurabilityImpossible
 
 	// ErrDurabilityAmbiguous occurs when an SyncWrite does not complete in the specified
@@ -242,7 +243,8 @@ var (
 	// be compiled.
 	ErrCompilationFailure = gocbcore.ErrCompilationFailure
 
-	// ErrJobQueueFull occurs when the analytics service job queue is full.
+	// ErrJobQueueFull occurs when the analytics service job queue is full. The query was not run and can be retried
+	// after backing off.
 	ErrJobQueueFull = gocbcore.ErrJobQueueFull
 
 	// ErrDatasetNotFound occurs when the analytics dataset requested could not be found.
@@ -316,6 +318,18 @@ var (
 
 	// ErrEventingFunctionDeployed occurs when the eventing function requested is not undeployed.
 	ErrEventingFunctionDeployed = gocbcore.ErrEventingFunctionNotUndeployed
+
+	// ErrRemoteClusterNotFound occurs when the XDCR remote cluster reference requested could not be found.
+	ErrRemoteClusterNotFound = errors.New("remote cluster not found")
+
+	// ErrRemoteClusterExists occurs when creating an XDCR remote cluster reference failed because it already exists.
+	ErrRemoteClusterExists = errors.New("remote cluster already exists")
+
+	// ErrReplicationNotFound occurs when the XDCR replication requested could not be found.
+	ErrReplicationNotFound = errors.New("replication not found")
+
+	// ErrReplicationExists occurs when creating an XDCR replication failed because it already exists.
+	ErrReplicationExists = errors.New("replication already exists")
 )
 
 // SDK specific error definitions
@@ -325,4 +339,49 @@ var (
 
 	// ErrNoResult occurs when no results are available to a query.
 	ErrNoResult = errors.New("no result was available")
+
+	// ErrNotModified occurs when a document fetched using GetIfModified has not been modified.
+	// UNCOMMITTED: This API may change in the future.
+	ErrNotModified = errors.New("document not modified")
+
+	// ErrWithLockNoChange can be returned by the callback passed to WithLock to release the lock without modifying
+	// the document.
+	// UNCOMMITTED: This API may change in the future.
+	ErrWithLockNoChange = errors.New("no change to locked document")
+
+	// ErrQueryMaxRowsExceeded occurs when a query returns more rows than permitted by QueryOptions.MaxRows.
+	// UNCOMMITTED: This API may change in the future.
+	ErrQueryMaxRowsExceeded = errors.New("query returned more rows than the maximum allowed")
+
+	// ErrResultMemoryLimitExceeded occurs when reading a row from a result would exceed ResultMemoryConfig.MaxBytes.
+	// UNCOMMITTED: This API may change in the future.
+	ErrResultMemoryLimitExceeded = errors.New("result memory limit exceeded")
+
+	// ErrAnalyticsResultNotReady occurs when fetching the results of a deferred analytics query which has not yet
+	// completed.
+	// UNCOMMITTED: This API may change in the future.
+	ErrAnalyticsResultNotReady = errors.New("analytics result not ready")
+
+	// ErrVbucketNotOnPinnedNode occurs when a KV operation is performed whilst IoConfig.PinKVToBootstrapHosts is
+	// enabled and the vbucket of the document is not active on any of the pinned nodes.
+	// UNCOMMITTED: This API may change in the future.
+	ErrVbucketNotOnPinnedNode = errors.New("vbucket is not active on a pinned node")
+
+	// ErrCircuitBreakerOpen occurs when a request is not dispatched because the circuit breaker for the service is
+	// open, see CircuitBreakerOpenError.
+	// UNCOMMITTED: This API may change in the future.
+	ErrCircuitBreakerOpen = errors.New("circuit breaker open")
+
+	// ErrServerGroupMetadataUnavailable occurs when a read uses a ReadPreference but the cluster did not provide any
+	// server group information.
+	// UNCOMMITTED: This API may change in the future.
+	ErrServerGroupMetadataUnavailable = errors.New("server group metadata unavailable")
+
+	// ErrEncryptionFailure occurs when a FieldEncryptionTranscoder fails to encrypt a field.
+	// UNCOMMITTED: This API may change in the future.
+	ErrEncryptionFailure = errors.New("field encryption failure")
+
+	// ErrDecryptionFailure occurs when a FieldEncryptionTranscoder fails to decrypt a field.
+	// UNCOMMITTED: This API may change in the future.
+	ErrDecryptionFailure = errors.New("field decryption failure")
 )
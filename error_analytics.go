@@ -2,6 +2,8 @@ package gocb
 
 import (
 	"encoding/json"
+	"errors"
+
 	gocbcore "github.com/couchbase/gocbcore/v10"
 )
 
@@ -22,6 +24,38 @@ func translateCoreAnalyticsErrorDesc(descs []gocbcore.AnalyticsErrorDesc) []Anal
 	return descsOut
 }
 
+// analyticsErrorFromDescs maps the first of the error codes returned by the analytics service to an error. Query
+// responses are parsed, and their codes mapped, by gocbcore, this is only for the responses which gocb parses itself.
+func analyticsErrorFromDescs(descs []AnalyticsErrorDesc) error {
+	if len(descs) == 0 {
+		return errors.New("analytics query failed")
+	}
+
+	code := descs[0].Code
+	switch {
+	case code == 21002:
+		return ErrTimeout
+	case code == 23000 || code == 23003:
+		return ErrTemporaryFailure
+	case code == 23007:
+		return ErrJobQueueFull
+	case code == 24025 || code == 24044 || code == 24045:
+		return ErrDatasetNotFound
+	case code == 24034:
+		return ErrDataverseNotFound
+	case code == 24039:
+		return ErrDataverseExists
+	case code == 24040:
+		return ErrDatasetExists
+	case code == 24006:
+		return ErrLinkNotFound
+	case code >= 24000 && code < 25000:
+		return ErrCompilationFailure
+	}
+
+	return errors.New("analytics query failed")
+}
+
 // AnalyticsError is the error type of all analytics query errors.
 // UNCOMMITTED: This API may change in the future.
 type AnalyticsError struct {
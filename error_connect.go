@@ -0,0 +1,76 @@
+package gocb
+
+import (
+	"strings"
+)
+
+// ConnectHostFailure describes why a single bootstrap host could not be connected to.
+// UNCOMMITTED: This API may change in the future.
+type ConnectHostFailure struct {
+	// Address is the host and port which was connected to.
+	Address string
+	Reason  ConnectFailureReason
+	Err     error
+}
+
+// ConnectError occurs when Connect is configured with ConnectConfig.WaitUntilConnected and none of the nodes of the
+// cluster respond before TimeoutsConfig.ConnectTimeout elapses. It reports why each of the bootstrap hosts could not
+// be connected to.
+// UNCOMMITTED: This API may change in the future.
+type ConnectError struct {
+	InnerError error
+	Hosts      []ConnectHostFailure
+}
+
+// Error returns the string representation of this error.
+func (e *ConnectError) Error() string {
+	hosts := make([]string, len(e.Hosts))
+	for i, host := range e.Hosts {
+		hosts[i] = host.Address + " " + connectFailureReasonToString(host.Reason)
+		if host.Err != nil {
+			hosts[i] += " (" + host.Err.Error() + ")"
+		}
+	}
+
+	msg := e.InnerError.Error() + " | failed to connect to any bootstrap host"
+	if len(hosts) > 0 {
+		msg += ": [" + strings.Join(hosts, ", ") + "]"
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *ConnectError) Unwrap() error {
+	return e.InnerError
+}
+
+// SaslMechanismError occurs when a node cannot be authenticated with because it offers none of the mechanisms in
+// SecurityConfig.AllowedSaslMechanisms. It is only reported by Connect when ConnectConfig.WaitUntilConnected is set.
+// UNCOMMITTED: This API may change in the future.
+type SaslMechanismError struct {
+	// Requested are the mechanisms which the SDK was allowed to authenticate with.
+	Requested []SaslMechanism
+	// Offered are the mechanisms which the node offered.
+	Offered []SaslMechanism
+}
+
+// Error returns the string representation of this error.
+func (e *SaslMechanismError) Error() string {
+	return ErrAuthenticationFailure.Error() + " | no allowed sasl mechanism is offered by the server, allowed [" +
+		joinSaslMechanisms(e.Requested) + "], offered [" + joinSaslMechanisms(e.Offered) + "]"
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *SaslMechanismError) Unwrap() error {
+	return ErrAuthenticationFailure
+}
+
+func joinSaslMechanisms(mechs []SaslMechanism) string {
+	names := make([]string, len(mechs))
+	for i, mech := range mechs {
+		names[i] = string(mech)
+	}
+
+	return strings.Join(names, ", ")
+}
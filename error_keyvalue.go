@@ -6,6 +6,9 @@ import (
 )
 
 // KeyValueError wraps key-value errors that occur within the SDK.
+// The StatusCode is the status returned by the server, with ErrorName and ErrorDescription being taken from the
+// server's error map for that status. The sentinel errors, such as ErrDocumentNotFound, can be matched against a
+// KeyValueError using errors.Is.
 // UNCOMMITTED: This API may change in the future.
 type KeyValueError struct {
 	InnerError         error           `json:"-"`
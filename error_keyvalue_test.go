@@ -2,7 +2,11 @@ package gocb
 
 import (
 	"encoding/json"
+	"errors"
+
+	"github.com/couchbase/gocbcore/v10"
 	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/stretchr/testify/mock"
 )
 
 func (suite *UnitTestSuite) TestKeyValueError() {
@@ -36,3 +40,41 @@ func (suite *UnitTestSuite) TestKeyValueError() {
 		aErr.Error(),
 	)
 }
+
+func (suite *UnitTestSuite) TestKeyValueErrorPopulatesIdentifiers() {
+	pendingOp := new(mockPendingOp)
+	pendingOp.AssertNotCalled(suite.T(), "Cancel", mock.AnythingOfType("error"))
+
+	provider := new(mockKvProvider)
+	provider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(nil, &gocbcore.KeyValueError{
+				InnerError:       gocbcore.ErrDocumentNotFound,
+				StatusCode:       memd.StatusKeyNotFound,
+				ErrorName:        "KEY_ENOENT",
+				ErrorDescription: "Not Found",
+			})
+		}).
+		Return(pendingOp, nil)
+
+	col := suite.collection("mock", "scope", "collection", provider)
+
+	_, err := col.Get("someid", nil)
+	if !errors.Is(err, ErrDocumentNotFound) {
+		suite.T().Fatalf("Error should have been document not found but was %s", err)
+	}
+	var kvErr *KeyValueError
+	if !errors.As(err, &kvErr) {
+		suite.T().Fatalf("Error should have been KeyValueError but was %s", err)
+	}
+
+	suite.Assert().Equal(memd.StatusKeyNotFound, kvErr.StatusCode)
+	suite.Assert().Equal("KEY_ENOENT", kvErr.ErrorName)
+	suite.Assert().Equal("Not Found", kvErr.ErrorDescription)
+	suite.Assert().Equal("mock", kvErr.BucketName)
+	suite.Assert().Equal("scope", kvErr.ScopeName)
+	suite.Assert().Equal("collection", kvErr.CollectionName)
+	suite.Assert().Equal("someid", kvErr.DocumentID)
+}
@@ -2,6 +2,10 @@ package gocb
 
 import (
 	"encoding/json"
+	"errors"
+	"regexp"
+	"time"
+
 	gocbcore "github.com/couchbase/gocbcore/v10"
 )
 
@@ -71,6 +75,7 @@ func (e QueryError) MarshalJSON() ([]byte, error) {
 		RetryReasons    []RetryReason    `json:"retry_reasons,omitempty"`
 		RetryAttempts   uint32           `json:"retry_attempts,omitempty"`
 		HTTPStatusCode  int              `json:"http_status_code,omitempty"`
+		TimeoutSource   string           `json:"timeout_source,omitempty"`
 	}{
 		InnerError:      innerError,
 		Statement:       e.Statement,
@@ -80,6 +85,7 @@ func (e QueryError) MarshalJSON() ([]byte, error) {
 		RetryReasons:    e.RetryReasons,
 		RetryAttempts:   e.RetryAttempts,
 		HTTPStatusCode:  e.HTTPStatusCode,
+		TimeoutSource:   e.TimeoutSource().String(),
 	})
 }
 
@@ -95,6 +101,7 @@ func (e QueryError) Error() string {
 		RetryAttempts   uint32           `json:"retry_attempts,omitempty"`
 		ErrorText       string           `json:"error_text,omitempty"`
 		HTTPStatusCode  int              `json:"http_status_code,omitempty"`
+		TimeoutSource   string           `json:"timeout_source,omitempty"`
 	}{
 		InnerError:      e.InnerError,
 		Statement:       e.Statement,
@@ -105,6 +112,7 @@ func (e QueryError) Error() string {
 		RetryAttempts:   e.RetryAttempts,
 		ErrorText:       e.ErrorText,
 		HTTPStatusCode:  e.HTTPStatusCode,
+		TimeoutSource:   e.TimeoutSource().String(),
 	})
 	if serErr != nil {
 		logErrorf("failed to serialize error to json: %s", serErr.Error())
@@ -117,3 +125,78 @@ func (e QueryError) Error() string {
 func (e QueryError) Unwrap() error {
 	return e.InnerError
 }
+
+// The query service reports the timeout which it enforced in the message of its timeout error.
+const queryErrorCodeTimeout = 1080
+
+var queryTimeoutMessageRegexp = regexp.MustCompile(`^Timeout (\S+) exceeded`)
+
+// ServerTimeout returns the timeout which the query service applied to the query, if the query timed out on the
+// server. The server caps the requested timeout to its own configured maximum, so this can be lower than the timeout
+// requested by the SDK. The boolean is false if the error does not contain the server applied timeout.
+// UNCOMMITTED: This API may change in the future.
+func (e QueryError) ServerTimeout() (time.Duration, bool) {
+	for _, desc := range e.Errors {
+		if desc.Code != queryErrorCodeTimeout {
+			continue
+		}
+
+		matches := queryTimeoutMessageRegexp.FindStringSubmatch(desc.Message)
+		if len(matches) != 2 {
+			continue
+		}
+
+		timeout, err := time.ParseDuration(matches[1])
+		if err != nil {
+			continue
+		}
+
+		return timeout, true
+	}
+
+	return 0, false
+}
+
+// QueryTimeoutSource indicates whether a query timed out on the client or on the server.
+// UNCOMMITTED: This API may change in the future.
+type QueryTimeoutSource uint
+
+const (
+	// QueryTimeoutSourceNone indicates that the query did not time out.
+	QueryTimeoutSourceNone QueryTimeoutSource = iota
+
+	// QueryTimeoutSourceClient indicates that the client gave up waiting for the query, due to QueryOptions.Timeout
+	// or the deadline of QueryOptions.Context.
+	QueryTimeoutSourceClient
+
+	// QueryTimeoutSourceServer indicates that the query service stopped executing the query because it exceeded the
+	// server timeout.
+	QueryTimeoutSourceServer
+)
+
+// String returns the string representation of the timeout source.
+func (s QueryTimeoutSource) String() string {
+	switch s {
+	case QueryTimeoutSourceClient:
+		return "client"
+	case QueryTimeoutSourceServer:
+		return "server"
+	default:
+		return ""
+	}
+}
+
+// TimeoutSource returns whether the query timed out on the client or on the server, or QueryTimeoutSourceNone if the
+// error is not a timeout. ClientContextID can be used to find the query in the logs of the query service.
+// UNCOMMITTED: This API may change in the future.
+func (e QueryError) TimeoutSource() QueryTimeoutSource {
+	if _, ok := e.ServerTimeout(); ok {
+		return QueryTimeoutSourceServer
+	}
+
+	if errors.Is(e.InnerError, ErrTimeout) {
+		return QueryTimeoutSourceClient
+	}
+
+	return QueryTimeoutSourceNone
+}
@@ -1,6 +1,9 @@
 package gocb
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 func (suite *UnitTestSuite) TestQueryError() {
 	aErr := QueryError{
@@ -59,3 +62,44 @@ func (suite *UnitTestSuite) TestQueryErrorImproved() {
 		aErr.Error(),
 	)
 }
+
+func (suite *UnitTestSuite) TestQueryErrorServerTimeout() {
+	aErr := QueryError{
+		InnerError: ErrUnambiguousTimeout,
+		Errors: []QueryErrorDesc{{
+			Code:    1080,
+			Message: "Timeout 2.5s exceeded",
+		}},
+	}
+
+	timeout, ok := aErr.ServerTimeout()
+	suite.Require().True(ok)
+	suite.Assert().Equal(2500*time.Millisecond, timeout)
+
+	aErr.Errors[0].Code = 1000
+	_, ok = aErr.ServerTimeout()
+	suite.Assert().False(ok)
+}
+
+func (suite *UnitTestSuite) TestQueryErrorTimeoutSource() {
+	aErr := QueryError{
+		InnerError:      ErrUnambiguousTimeout,
+		ClientContextID: "12345",
+		Errors: []QueryErrorDesc{{
+			Code:    1080,
+			Message: "Timeout 2.5s exceeded",
+		}},
+	}
+
+	suite.Assert().Equal(QueryTimeoutSourceServer, aErr.TimeoutSource())
+	suite.Assert().Contains(aErr.Error(), "\"timeout_source\":\"server\"")
+	suite.Assert().Contains(aErr.Error(), "\"client_context_id\":\"12345\"")
+
+	aErr.Errors = nil
+	suite.Assert().Equal(QueryTimeoutSourceClient, aErr.TimeoutSource())
+	suite.Assert().Contains(aErr.Error(), "\"timeout_source\":\"client\"")
+
+	aErr.InnerError = ErrIndexFailure
+	suite.Assert().Equal(QueryTimeoutSourceNone, aErr.TimeoutSource())
+	suite.Assert().NotContains(aErr.Error(), "timeout_source")
+}
@@ -0,0 +1,41 @@
+package gocb
+
+import (
+	"sort"
+	"strings"
+)
+
+// queryIndexStateAbsent is the state reported by QueryIndexesNotReadyError for an index which does not exist.
+const queryIndexStateAbsent = "absent"
+
+// QueryIndexesNotReadyError occurs when QueryIndexManager.WaitUntilReady times out before all of the indexes are
+// online. It reports the state of each index which was not online when last checked.
+// UNCOMMITTED: This API may change in the future.
+type QueryIndexesNotReadyError struct {
+	InnerError error
+
+	// Indexes maps the name of each index which was not online to its state as reported by the query service, e.g.
+	// "building", "deferred" or "pending", or to "absent" if the index did not exist.
+	Indexes map[string]string
+}
+
+// Error returns the string representation of this error.
+func (e *QueryIndexesNotReadyError) Error() string {
+	indexes := make([]string, 0, len(e.Indexes))
+	for name, state := range e.Indexes {
+		indexes = append(indexes, name+" "+state)
+	}
+	sort.Strings(indexes)
+
+	msg := e.InnerError.Error() + " | indexes not online"
+	if len(indexes) > 0 {
+		msg += ": [" + strings.Join(indexes, ", ") + "]"
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *QueryIndexesNotReadyError) Unwrap() error {
+	return e.InnerError
+}
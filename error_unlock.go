@@ -0,0 +1,32 @@
+package gocb
+
+import (
+	"fmt"
+)
+
+// CasMismatchError occurs when Unlock is called with a cas which does not match the cas that the document was locked
+// with, either because the document is locked by another GetAndLock call or because the document is no longer
+// locked. Server versions do not consistently distinguish between these cases, InnerError holds the error returned by
+// the server. Servers before 7.0 respond with a temporary failure instead, which does not result in this error.
+// UNCOMMITTED: This API may change in the future.
+type CasMismatchError struct {
+	DocumentID string
+	Cas        Cas
+	InnerError error
+}
+
+// Error returns the string representation of this error.
+func (e *CasMismatchError) Error() string {
+	return fmt.Sprintf("cas mismatch: document %s is not locked with cas %d | %s", e.DocumentID, e.Cas, e.InnerError)
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *CasMismatchError) Unwrap() error {
+	return e.InnerError
+}
+
+// Is returns whether target is ErrCasMismatch, allowing errors.Is(err, ErrCasMismatch) to be used regardless of the
+// error returned by the server.
+func (e *CasMismatchError) Is(target error) bool {
+	return target == ErrCasMismatch
+}
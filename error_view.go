@@ -2,6 +2,8 @@ package gocb
 
 import (
 	"encoding/json"
+	"fmt"
+
 	gocbcore "github.com/couchbase/gocbcore/v10"
 )
 
@@ -93,3 +95,33 @@ func (e ViewError) Error() string {
 func (e ViewError) Unwrap() error {
 	return e.InnerError
 }
+
+// DesignDocumentNotFoundError occurs when a design document could not be found in the namespace it was looked up in.
+// InnerError holds the error returned by the server.
+// UNCOMMITTED: This API may change in the future.
+type DesignDocumentNotFoundError struct {
+	Name       string
+	Namespace  DesignDocumentNamespace
+	InnerError error
+}
+
+// Error returns the string representation of this error.
+func (e *DesignDocumentNotFoundError) Error() string {
+	namespace := "production"
+	if e.Namespace == DesignDocumentNamespaceDevelopment {
+		namespace = "development"
+	}
+
+	return fmt.Sprintf("design document %s not found in %s namespace | %s", e.Name, namespace, e.InnerError)
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *DesignDocumentNotFoundError) Unwrap() error {
+	return e.InnerError
+}
+
+// Is returns whether target is ErrDesignDocumentNotFound, allowing errors.Is(err, ErrDesignDocumentNotFound) to be
+// used regardless of the error returned by the server.
+func (e *DesignDocumentNotFoundError) Is(target error) bool {
+	return target == ErrDesignDocumentNotFound
+}
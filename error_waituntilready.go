@@ -0,0 +1,91 @@
+package gocb
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// waitUntilReadyReportTimeout is the longest that WaitUntilReady spends pinging the services, after failing, in order
+// to report their states.
+const waitUntilReadyReportTimeout = 2 * time.Second
+
+// waitUntilReadyReportTime returns how much of the timeout of WaitUntilReady is set aside for pinging the services
+// if it fails, so that reporting their states does not take it past the timeout. At most a tenth of the timeout is
+// used, so that short timeouts are still mostly spent waiting.
+func waitUntilReadyReportTime(timeout time.Duration) time.Duration {
+	reportTime := timeout / 10
+	if reportTime > waitUntilReadyReportTimeout {
+		reportTime = waitUntilReadyReportTimeout
+	}
+
+	return reportTime
+}
+
+// kvWarmupPollInterval is how often WaitUntilReady checks whether every KV connection has been established when
+// warming up the KV connection pool.
+const kvWarmupPollInterval = 50 * time.Millisecond
+
+// WaitUntilReadyError occurs when WaitUntilReady times out before the desired state is reached. It reports the
+// state of each of the services which were waited on, as observed by pinging them once the wait had failed.
+// UNCOMMITTED: This API may change in the future.
+type WaitUntilReadyError struct {
+	InnerError   error
+	DesiredState ClusterState
+
+	// Services holds the result of pinging the endpoints of each service, keyed by service type. This is empty if the
+	// services could not be pinged.
+	Services map[ServiceType][]EndpointPingReport
+}
+
+// ServiceState returns the state of a service: ClusterStateOnline if all of its endpoints responded,
+// ClusterStateDegraded if only some of them responded and ClusterStateOffline if none of them did, or it has no
+// endpoints.
+func (e *WaitUntilReadyError) ServiceState(service ServiceType) ClusterState {
+	var numOk int
+	endpoints := e.Services[service]
+	for _, endpoint := range endpoints {
+		if endpoint.State == PingStateOk {
+			numOk++
+		}
+	}
+
+	if numOk == 0 {
+		return ClusterStateOffline
+	}
+	if numOk < len(endpoints) {
+		return ClusterStateDegraded
+	}
+	return ClusterStateOnline
+}
+
+// Error returns the string representation of this error.
+func (e *WaitUntilReadyError) Error() string {
+	var services []string
+	for service, endpoints := range e.Services {
+		var states []string
+		for _, endpoint := range endpoints {
+			state := pingStateToString(endpoint.State)
+			if endpoint.Error != "" {
+				state += " (" + endpoint.Error + ")"
+			}
+			states = append(states, endpoint.Remote+" "+state)
+		}
+
+		services = append(services, serviceTypeToString(service)+" "+clusterStateToString(e.ServiceState(service))+
+			": ["+strings.Join(states, ", ")+"]")
+	}
+	sort.Strings(services)
+
+	msg := e.InnerError.Error() + " | desired state " + clusterStateToString(e.DesiredState)
+	if len(services) > 0 {
+		msg += ", " + strings.Join(services, ", ")
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *WaitUntilReadyError) Unwrap() error {
+	return e.InnerError
+}
@@ -1,7 +1,9 @@
 package gocb
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 
 	gocbcore "github.com/couchbase/gocbcore/v10"
 )
@@ -14,6 +16,37 @@ func serializeWrappedError(err error) string {
 	return string(errBytes)
 }
 
+// contextError is returned when an operation is canceled because its Context was canceled or passed its deadline.
+// It matches both ErrRequestCanceled and the error of the Context, e.g. context.Canceled, when used with errors.Is.
+type contextError struct {
+	InnerError   error
+	ContextError error
+}
+
+func (e *contextError) Error() string {
+	return e.InnerError.Error() + " | " + e.ContextError.Error()
+}
+
+func (e *contextError) Unwrap() error {
+	return e.InnerError
+}
+
+func (e *contextError) Is(target error) bool {
+	return target == e.ContextError
+}
+
+// maybeWrapContextErr wraps err in a contextError if err is the result of ctx being done.
+func maybeWrapContextErr(ctx context.Context, err error) error {
+	if err == nil || ctx == nil || ctx.Err() == nil || !errors.Is(err, ErrRequestCanceled) {
+		return err
+	}
+
+	return &contextError{
+		InnerError:   err,
+		ContextError: ctx.Err(),
+	}
+}
+
 func maybeEnhanceCoreErr(err error) error {
 	if kvErr, ok := err.(*gocbcore.KeyValueError); ok {
 		return &KeyValueError{
@@ -114,11 +147,30 @@ func maybeEnhanceCoreErr(err error) error {
 }
 
 func maybeEnhanceKVErr(err error, bucketName, scopeName, collName, docKey string) error {
-	return maybeEnhanceCoreErr(err)
+	err = maybeEnhanceCoreErr(err)
+
+	// Errors which occur before an operation is dispatched are not always populated with the identifiers of the
+	// document, so fill in anything which we already know.
+	if kvErr, ok := err.(*KeyValueError); ok {
+		if kvErr.BucketName == "" {
+			kvErr.BucketName = bucketName
+		}
+		if kvErr.ScopeName == "" {
+			kvErr.ScopeName = scopeName
+		}
+		if kvErr.CollectionName == "" {
+			kvErr.CollectionName = collName
+		}
+		if kvErr.DocumentID == "" {
+			kvErr.DocumentID = docKey
+		}
+	}
+
+	return err
 }
 
 func maybeEnhanceCollKVErr(err error, bucket kvProvider, coll *Collection, docKey string) error {
-	return maybeEnhanceKVErr(err, coll.bucketName(), coll.Name(), coll.ScopeName(), docKey)
+	return maybeEnhanceKVErr(err, coll.bucketName(), coll.ScopeName(), coll.Name(), docKey)
 }
 
 func maybeEnhanceViewError(err error) error {
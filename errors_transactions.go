@@ -73,6 +73,18 @@ func (tfe TransactionFailedError) Result() *TransactionResult {
 	return tfe.result
 }
 
+// Attempts returns the attempts made by the transaction, the last of which is the attempt which caused the
+// transaction to fail. Earlier attempts failed with errors that were retried, such as conflicts with other
+// transactions.
+// UNCOMMITTED: This API may change in the future.
+func (tfe TransactionFailedError) Attempts() []TransactionAttempt {
+	if tfe.result == nil {
+		return nil
+	}
+
+	return tfe.result.Attempts
+}
+
 type TransactionExpiredError struct {
 	result *TransactionResult
 }
@@ -0,0 +1,122 @@
+// Package gocbfieldcrypt provides the reference AEAD_AES_256_CBC_HMAC_SHA512 implementation used for field level
+// encryption, as described by draft-mcgrew-aead-aes-cbc-hmac-sha2-05.
+package gocbfieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// AlgorithmAEADAES256CBCHMACSHA512 is the identifier of the AEAD_AES_256_CBC_HMAC_SHA512 algorithm as used within
+	// the cross-SDK field level encryption format.
+	AlgorithmAEADAES256CBCHMACSHA512 = "AEAD_AES_256_CBC_HMAC_SHA512"
+
+	// KeySize is the size of the keys used by AEAD_AES_256_CBC_HMAC_SHA512, the first half of the key is used for
+	// HMAC-SHA512 and the second half for AES-256.
+	KeySize = 64
+
+	tagSize = 32
+)
+
+var (
+	// ErrInvalidKey occurs when a key is not KeySize bytes long.
+	ErrInvalidKey = errors.New("encryption key must be 64 bytes")
+
+	// ErrInvalidCiphertext occurs when a ciphertext is malformed, or fails authentication because it was not
+	// encrypted with the same key and associated data or has been tampered with.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// EncryptAEADAES256CBCHMACSHA512 encrypts plaintext with a random IV, returning IV || ciphertext || tag.
+func EncryptAEADAES256CBCHMACSHA512(key, plaintext, associatedData []byte) ([]byte, error) {
+	iv := make([]byte, aes.BlockSize)
+	_, err := rand.Read(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return EncryptAEADAES256CBCHMACSHA512WithIV(key, iv, plaintext, associatedData)
+}
+
+// EncryptAEADAES256CBCHMACSHA512WithIV encrypts plaintext with the given IV, returning IV || ciphertext || tag.
+// The IV must be unpredictable, this is only exposed so that the implementation can be verified against test
+// vectors.
+func EncryptAEADAES256CBCHMACSHA512WithIV(key, iv, plaintext, associatedData []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	macKey := key[:32]
+	encKey := key[32:]
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	padLen := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := make([]byte, len(plaintext)+padLen)
+	copy(padded, plaintext)
+	for i := len(plaintext); i < len(padded); i++ {
+		padded[i] = byte(padLen)
+	}
+
+	out := make([]byte, aes.BlockSize+len(padded), aes.BlockSize+len(padded)+tagSize)
+	copy(out, iv)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out[aes.BlockSize:], padded)
+
+	return append(out, authTag(macKey, associatedData, out)...), nil
+}
+
+// DecryptAEADAES256CBCHMACSHA512 authenticates and decrypts a ciphertext of the form IV || ciphertext || tag.
+func DecryptAEADAES256CBCHMACSHA512(key, ciphertext, associatedData []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	macKey := key[:32]
+	encKey := key[32:]
+
+	if len(ciphertext) < 2*aes.BlockSize+tagSize || (len(ciphertext)-tagSize)%aes.BlockSize != 0 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	tagStart := len(ciphertext) - tagSize
+	expectedTag := authTag(macKey, associatedData, ciphertext[:tagStart])
+	if subtle.ConstantTimeCompare(expectedTag, ciphertext[tagStart:]) != 1 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := ciphertext[:aes.BlockSize]
+	plaintext := make([]byte, tagStart-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext[aes.BlockSize:tagStart])
+
+	padLen := int(plaintext[len(plaintext)-1])
+	if padLen == 0 || padLen > aes.BlockSize {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext[:len(plaintext)-padLen], nil
+}
+
+func authTag(macKey, associatedData, ivAndCiphertext []byte) []byte {
+	associatedDataLen := make([]byte, 8)
+	binary.BigEndian.PutUint64(associatedDataLen, uint64(len(associatedData))*8)
+
+	mac := hmac.New(sha512.New, macKey)
+	mac.Write(associatedData)
+	mac.Write(ivAndCiphertext)
+	mac.Write(associatedDataLen)
+
+	return mac.Sum(nil)[:tagSize]
+}
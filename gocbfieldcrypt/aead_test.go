@@ -0,0 +1,63 @@
+package gocbfieldcrypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func testKey() []byte {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	return key
+}
+
+func TestAEADAES256CBCHMACSHA512TestVector(t *testing.T) {
+	iv, err := hex.DecodeString("1af38c2dc2b96ffdd86694092341bc04")
+	if err != nil {
+		t.Fatalf("Failed to decode iv: %v", err)
+	}
+
+	plaintext := []byte("A cipher system must not be required to be secret, and it must be able to fall into the hands " +
+		"of the enemy without inconvenience")
+	associatedData := []byte("The second principle of Auguste Kerckhoffs")
+
+	expected := "1af38c2dc2b96ffdd86694092341bc04" +
+		"4affaaadb78c31c5da4b1b590d10ffbd3dd8d5d302423526912da037ecbcc7bd822c301dd67c373bccb584ad3e9279c2e6d12a1374b7" +
+		"7f077553df829410446b36ebd97066296ae6427ea75c2e0846a11a09ccf5370dc80bfecbad28c73f09b3a3b75e662a2594410ae496b2" +
+		"e2e6609e31e6e02cc837f053d21f37ff4f51950bbe2638d09dd7a4930930806d0703b1f6" +
+		"4dd3b4c088a7f45c216839645b2012bf2e6269a8c56a816dbc1b267761955bc5"
+
+	ciphertext, err := EncryptAEADAES256CBCHMACSHA512WithIV(testKey(), iv, plaintext, associatedData)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if hex.EncodeToString(ciphertext) != expected {
+		t.Fatalf("Expected ciphertext to be %s but was %s", expected, hex.EncodeToString(ciphertext))
+	}
+
+	decrypted, err := DecryptAEADAES256CBCHMACSHA512(testKey(), ciphertext, associatedData)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if !bytes.Equal(plaintext, decrypted) {
+		t.Fatalf("Expected plaintext to be %s but was %s", plaintext, decrypted)
+	}
+
+	ciphertext[20] ^= 0x01
+	_, err = DecryptAEADAES256CBCHMACSHA512(testKey(), ciphertext, associatedData)
+	if !errors.Is(err, ErrInvalidCiphertext) {
+		t.Fatalf("Expected error to be invalid ciphertext but was %v", err)
+	}
+}
+
+func TestAEADAES256CBCHMACSHA512InvalidKey(t *testing.T) {
+	_, err := EncryptAEADAES256CBCHMACSHA512(testKey()[:32], []byte("plaintext"), nil)
+	if !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Expected error to be invalid key but was %v", err)
+	}
+}
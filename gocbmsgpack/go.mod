@@ -0,0 +1,10 @@
+module github.com/couchbase/gocb/gocbmsgpack
+
+require (
+	github.com/couchbase/gocb/v2 v2.4.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+)
+
+replace github.com/couchbase/gocb/v2 => ../
+
+go 1.13
@@ -0,0 +1,78 @@
+// Package gocbmsgpack provides a gocb Transcoder which encodes values using MessagePack, a compact binary format which
+// is well suited to numeric data. It is a separate module to gocb, so that applications which do not use it do not
+// depend on a MessagePack library.
+package gocbmsgpack
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Flags are the flags which documents encoded by MsgPackTranscoder are stored with. The common flags have no format
+// for MessagePack, so the private format, which the common flags reserve for encodings specific to an SDK, is used
+// without compression.
+const Flags = uint32(0x01) << 24
+
+// MsgPackTranscoder encodes values using MessagePack, using github.com/vmihailenco/msgpack.
+//
+// Documents are stored with the private common flags format, and so can only be decoded by a MsgPackTranscoder.
+// Decoding a document stored by any other transcoder returns a *gocb.DataTypeMismatchError, as does decoding a
+// document stored in the private format of another SDK when it is not valid MessagePack. As the documents are not
+// JSON they cannot be used with query, search, analytics or subdocument operations.
+//
+// Struct fields are named according to their json tags, including omitempty, so that the same types can be used
+// with gocb.JSONTranscoder. Values of type time.Time are encoded using the MessagePack timestamp extension type.
+// When decoding into an interface{} integers are decoded as int64, or as uint64 if they are too large for an int64,
+// and floats as float64.
+type MsgPackTranscoder struct {
+}
+
+// NewMsgPackTranscoder returns a new MsgPackTranscoder.
+func NewMsgPackTranscoder() *MsgPackTranscoder {
+	return &MsgPackTranscoder{}
+}
+
+// Decode applies MessagePack transcoding behaviour to decode into a Go type.
+func (t *MsgPackTranscoder) Decode(value []byte, flags uint32, out interface{}) error {
+	if flags != Flags {
+		return &gocb.DataTypeMismatchError{
+			Transcoder: "MsgPackTranscoder",
+			Expected:   gocb.DocumentFormatPrivate.String(),
+			Actual:     gocb.DocumentFormatFromFlags(flags).String(),
+			Flags:      flags,
+		}
+	}
+
+	dec := msgpack.NewDecoder(bytes.NewReader(value))
+	dec.SetCustomStructTag("json")
+	dec.UseLooseInterfaceDecoding(true)
+
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("failed to decode msgpack document: %v: %w", err, gocb.ErrDecodingFailure)
+	}
+
+	if _, err := dec.PeekCode(); err != io.EOF {
+		return fmt.Errorf("unexpected data after msgpack value: %w", gocb.ErrDecodingFailure)
+	}
+
+	return nil
+}
+
+// Encode applies MessagePack transcoding behaviour to encode a Go type.
+func (t *MsgPackTranscoder) Encode(value interface{}) ([]byte, uint32, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+
+	if err := enc.Encode(value); err != nil {
+		return nil, 0, fmt.Errorf("failed to encode msgpack document: %v: %w", err, gocb.ErrEncodingFailure)
+	}
+
+	return buf.Bytes(), Flags, nil
+}
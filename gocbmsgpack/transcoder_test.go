@@ -0,0 +1,130 @@
+package gocbmsgpack
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+type testSeries struct {
+	Sensor    string             `json:"sensor"`
+	Start     time.Time          `json:"start"`
+	Readings  []float64          `json:"readings"`
+	Counts    [3]int8            `json:"counts"`
+	Total     uint64             `json:"total"`
+	Offset    int64              `json:"offset"`
+	Raw       []byte             `json:"raw"`
+	Labels    map[string]string  `json:"labels,omitempty"`
+	Unit      *string            `json:"unit"`
+	Threshold float32            `json:"threshold"`
+	Ignored   string             `json:"-"`
+	Nested    map[string][]int32 `json:"nested"`
+}
+
+func TestMsgPackTranscoderRoundTrip(t *testing.T) {
+	unit := "celsius"
+	series := testSeries{
+		Sensor:    "s-1",
+		Start:     time.Date(2022, 3, 8, 12, 30, 15, 123456789, time.UTC),
+		Readings:  []float64{21.5, -3.25, math.MaxFloat64},
+		Counts:    [3]int8{-128, 0, 127},
+		Total:     math.MaxUint64,
+		Offset:    math.MinInt64,
+		Raw:       []byte{0x00, 0xff},
+		Unit:      &unit,
+		Threshold: 1.5,
+		Ignored:   "ignored",
+		Nested:    map[string][]int32{"a": {1, -70000}},
+	}
+
+	transcoder := NewMsgPackTranscoder()
+	bytes, flags, err := transcoder.Encode(series)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if flags != Flags {
+		t.Fatalf("Expected flags to be 0x%08x but were 0x%08x", Flags, flags)
+	}
+	if format := gocb.DocumentFormatFromFlags(flags); format != gocb.DocumentFormatPrivate {
+		t.Fatalf("Expected format to be private but was %s", format)
+	}
+
+	jsonBytes, _, err := gocb.NewJSONTranscoder().Encode(series)
+	if err != nil {
+		t.Fatalf("Failed to encode JSON: %v", err)
+	}
+	if len(bytes) >= len(jsonBytes) {
+		t.Fatalf("Expected msgpack to be smaller than JSON, was %d bytes vs %d", len(bytes), len(jsonBytes))
+	}
+
+	var actual testSeries
+	err = transcoder.Decode(bytes, flags, &actual)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	series.Ignored = ""
+	actual.Start = actual.Start.UTC()
+	if !reflect.DeepEqual(series, actual) {
+		t.Fatalf("Expected %+v but was %+v", series, actual)
+	}
+
+	var generic interface{}
+	bytes, flags, err = transcoder.Encode(map[string]interface{}{"small": 1, "large": uint64(math.MaxUint64), "f": 2.5})
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	err = transcoder.Decode(bytes, flags, &generic)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	expected := map[string]interface{}{"small": int64(1), "large": uint64(math.MaxUint64), "f": 2.5}
+	if !reflect.DeepEqual(expected, generic) {
+		t.Fatalf("Expected %#v but was %#v", expected, generic)
+	}
+}
+
+func TestMsgPackTranscoderErrors(t *testing.T) {
+	transcoder := NewMsgPackTranscoder()
+
+	var out interface{}
+	err := transcoder.Decode([]byte(`{"name":"test"}`), 0x02000000, &out)
+	var mismatchErr *gocb.DataTypeMismatchError
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("Expected DataTypeMismatchError but was %v", err)
+	}
+	if mismatchErr.Actual != "json" || mismatchErr.Expected != "private" {
+		t.Fatalf("Expected json document for private transcoder but was %+v", mismatchErr)
+	}
+	if !errors.Is(err, gocb.ErrDecodingFailure) {
+		t.Fatalf("Expected decoding failure but was %v", err)
+	}
+
+	bytes, flags, err := transcoder.Encode(300)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	var str string
+	err = transcoder.Decode(bytes, flags, &str)
+	if !errors.Is(err, gocb.ErrDecodingFailure) {
+		t.Fatalf("Expected decoding failure but was %v", err)
+	}
+
+	// Truncated, with trailing data, and an invalid code.
+	for _, data := range [][]byte{{0x92, 0x01}, {0x01, 0x02}, {0xc1}} {
+		err = transcoder.Decode(data, Flags, &out)
+		if !errors.Is(err, gocb.ErrDecodingFailure) {
+			t.Fatalf("Expected decoding failure for %x but was %v", data, err)
+		}
+	}
+
+	_, _, err = transcoder.Encode(make(chan int))
+	if !errors.Is(err, gocb.ErrEncodingFailure) {
+		t.Fatalf("Expected encoding failure but was %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/couchbase/gocb/gocbopentelemetry
+
+require (
+	github.com/couchbase/gocb/v2 v2.4.0
+	go.opentelemetry.io/otel v1.4.1
+	go.opentelemetry.io/otel/metric v0.27.0
+	go.opentelemetry.io/otel/trace v1.4.1
+)
+
+replace github.com/couchbase/gocb/v2 => ../
+
+go 1.13
@@ -0,0 +1,149 @@
+package gocbopentelemetry
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/couchbase/gocb/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+const meterName = "com.couchbase.client/go"
+
+// valueRecorderUnit is the unit of the values recorded by the SDK, which records operation durations in
+// microseconds.
+const valueRecorderUnit unit.Unit = "us"
+
+// OpenTelemetryMeter is a gocb.Meter which records metrics using OpenTelemetry. Each metric is created as a single
+// instrument, and the counters and value recorders returned for each set of tags are cached, so that recording a
+// value does not create any instruments or attributes.
+// UNCOMMITTED: This API may change in the future.
+type OpenTelemetryMeter struct {
+	wrapped metric.Meter
+
+	instrumentsLock sync.Mutex
+	counters        map[string]metric.Int64Counter
+	histograms      map[string]metric.Int64Histogram
+
+	counterCache  sync.Map
+	recorderCache sync.Map
+}
+
+// NewOpenTelemetryMeter creates a new OpenTelemetryMeter which records metrics using a meter obtained from the
+// provider. The meter can be set as ClusterOptions.Meter.
+// UNCOMMITTED: This API may change in the future.
+func NewOpenTelemetryMeter(provider metric.MeterProvider) *OpenTelemetryMeter {
+	return &OpenTelemetryMeter{
+		wrapped:    provider.Meter(meterName, metric.WithInstrumentationVersion(gocb.Version())),
+		counters:   make(map[string]metric.Int64Counter),
+		histograms: make(map[string]metric.Int64Histogram),
+	}
+}
+
+// Counter returns a counter for the metric name and tags.
+func (meter *OpenTelemetryMeter) Counter(name string, tags map[string]string) (gocb.Counter, error) {
+	key := metricKey(name, tags)
+	if counter, ok := meter.counterCache.Load(key); ok {
+		return counter.(*openTelemetryCounter), nil
+	}
+
+	meter.instrumentsLock.Lock()
+	instrument, ok := meter.counters[name]
+	if !ok {
+		var err error
+		instrument, err = meter.wrapped.NewInt64Counter(name)
+		if err != nil {
+			meter.instrumentsLock.Unlock()
+			return nil, err
+		}
+		meter.counters[name] = instrument
+	}
+	meter.instrumentsLock.Unlock()
+
+	// It doesn't matter if the counter is stored against the same key more than once, they are equivalent.
+	counter, _ := meter.counterCache.LoadOrStore(key, &openTelemetryCounter{
+		counter: instrument,
+		attribs: tagsToAttributes(tags),
+	})
+
+	return counter.(*openTelemetryCounter), nil
+}
+
+// ValueRecorder returns a value recorder for the metric name and tags. Values are recorded as a histogram.
+func (meter *OpenTelemetryMeter) ValueRecorder(name string, tags map[string]string) (gocb.ValueRecorder, error) {
+	key := metricKey(name, tags)
+	if recorder, ok := meter.recorderCache.Load(key); ok {
+		return recorder.(*openTelemetryValueRecorder), nil
+	}
+
+	meter.instrumentsLock.Lock()
+	instrument, ok := meter.histograms[name]
+	if !ok {
+		var err error
+		instrument, err = meter.wrapped.NewInt64Histogram(name, metric.WithUnit(valueRecorderUnit))
+		if err != nil {
+			meter.instrumentsLock.Unlock()
+			return nil, err
+		}
+		meter.histograms[name] = instrument
+	}
+	meter.instrumentsLock.Unlock()
+
+	recorder, _ := meter.recorderCache.LoadOrStore(key, &openTelemetryValueRecorder{
+		histogram: instrument,
+		attribs:   tagsToAttributes(tags),
+	})
+
+	return recorder.(*openTelemetryValueRecorder), nil
+}
+
+type openTelemetryCounter struct {
+	counter metric.Int64Counter
+	attribs []attribute.KeyValue
+}
+
+func (c *openTelemetryCounter) IncrementBy(num uint64) {
+	c.counter.Add(context.Background(), int64(num), c.attribs...)
+}
+
+type openTelemetryValueRecorder struct {
+	histogram metric.Int64Histogram
+	attribs   []attribute.KeyValue
+}
+
+func (r *openTelemetryValueRecorder) RecordValue(val uint64) {
+	r.histogram.Record(context.Background(), int64(val), r.attribs...)
+}
+
+func tagsToAttributes(tags map[string]string) []attribute.KeyValue {
+	attribs := make([]attribute.KeyValue, 0, len(tags))
+	for k, v := range tags {
+		attribs = append(attribs, attribute.String(k, v))
+	}
+
+	return attribs
+}
+
+// metricKey builds a key which uniquely identifies a metric name and set of tags.
+func metricKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, k := range keys {
+		sb.WriteByte(0)
+		sb.WriteString(k)
+		sb.WriteByte(0)
+		sb.WriteString(tags[k])
+	}
+
+	return sb.String()
+}
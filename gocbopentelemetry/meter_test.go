@@ -0,0 +1,61 @@
+package gocbopentelemetry
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/metrictest"
+)
+
+func TestOpenTelemetryMeterValueRecorder(t *testing.T) {
+	provider := metrictest.NewMeterProvider()
+	meter := NewOpenTelemetryMeter(provider)
+
+	recorder, err := meter.ValueRecorder("db.couchbase.operations", map[string]string{
+		"db.couchbase.service": "kv",
+		"db.operation":         "get",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create value recorder: %v", err)
+	}
+
+	again, err := meter.ValueRecorder("db.couchbase.operations", map[string]string{
+		"db.operation":         "get",
+		"db.couchbase.service": "kv",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create value recorder: %v", err)
+	}
+	if again != recorder {
+		t.Fatalf("Expected the value recorder to be reused for the same tags")
+	}
+
+	other, err := meter.ValueRecorder("db.couchbase.operations", map[string]string{
+		"db.couchbase.service": "query",
+		"db.operation":         "query",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create value recorder: %v", err)
+	}
+	if len(meter.histograms) != 1 {
+		t.Fatalf("Expected a single histogram instrument but was %d", len(meter.histograms))
+	}
+
+	recorder.RecordValue(1500)
+	other.RecordValue(2000)
+
+	measured := metrictest.AsStructs(provider.MeasurementBatches)
+	if len(measured) != 2 {
+		t.Fatalf("Expected 2 measurements but was %d", len(measured))
+	}
+
+	if measured[0].Name != "db.couchbase.operations" || measured[0].Number.AsInt64() != 1500 {
+		t.Fatalf("Unexpected measurement %v", measured[0])
+	}
+	if measured[0].Labels[attribute.Key("db.couchbase.service")].AsString() != "kv" {
+		t.Fatalf("Unexpected measurement labels %v", measured[0].Labels)
+	}
+	if measured[1].Labels[attribute.Key("db.couchbase.service")].AsString() != "query" {
+		t.Fatalf("Unexpected measurement labels %v", measured[1].Labels)
+	}
+}
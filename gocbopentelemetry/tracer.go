@@ -0,0 +1,128 @@
+// Package gocbopentelemetry provides a gocb RequestTracer which records the spans of SDK operations using
+// OpenTelemetry. It is a separate module to gocb, so that applications which do not use it do not depend on
+// OpenTelemetry.
+package gocbopentelemetry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "com.couchbase.client/go"
+
+	serverDurationKey = "db.couchbase.server_duration"
+)
+
+// OpenTelemetryRequestTracer is a gocb.RequestTracer which creates an OpenTelemetry span for each gocb span.
+// UNCOMMITTED: This API may change in the future.
+type OpenTelemetryRequestTracer struct {
+	wrapped trace.Tracer
+}
+
+// NewOpenTelemetryRequestTracer creates a new OpenTelemetryRequestTracer which creates spans using a tracer obtained
+// from the provider. The tracer can be set as ClusterOptions.Tracer.
+// UNCOMMITTED: This API may change in the future.
+func NewOpenTelemetryRequestTracer(provider trace.TracerProvider) gocb.RequestTracer {
+	return &OpenTelemetryRequestTracer{
+		wrapped: provider.Tracer(tracerName, trace.WithInstrumentationVersion(gocb.Version())),
+	}
+}
+
+// RequestSpan creates a new OpenTelemetry span as a child of the span carried by parentContext. The parentContext
+// is a context.Context when the parent was created by this tracer, or by NewOpenTelemetryRequestSpan. Any other
+// parentContext causes a new root span to be created.
+func (tracer *OpenTelemetryRequestTracer) RequestSpan(parentContext gocb.RequestSpanContext,
+	operationName string) gocb.RequestSpan {
+	parentCtx, ok := parentContext.(context.Context)
+	if !ok || parentCtx == nil {
+		parentCtx = context.Background()
+	}
+
+	return NewOpenTelemetryRequestSpan(tracer.wrapped.Start(parentCtx, operationName))
+}
+
+// OpenTelemetryRequestSpan is a gocb.RequestSpan which wraps an OpenTelemetry span.
+// UNCOMMITTED: This API may change in the future.
+type OpenTelemetryRequestSpan struct {
+	ctx  context.Context
+	span trace.Span
+
+	endOnce sync.Once
+}
+
+// NewOpenTelemetryRequestSpan wraps an OpenTelemetry span, and the context which carries it, as a gocb.RequestSpan.
+// This can be used to pass an existing OpenTelemetry span as the ParentSpan of an operation, so that the spans
+// created by the SDK for that operation are its children.
+// UNCOMMITTED: This API may change in the future.
+func NewOpenTelemetryRequestSpan(ctx context.Context, span trace.Span) *OpenTelemetryRequestSpan {
+	return &OpenTelemetryRequestSpan{
+		ctx:  ctx,
+		span: span,
+	}
+}
+
+// End completes the span. Only the first call has any effect, so ending a span which has already been ended, for
+// example when an operation is canceled after it has completed, is safe.
+func (span *OpenTelemetryRequestSpan) End() {
+	span.endOnce.Do(func() {
+		span.span.End()
+	})
+}
+
+// Context returns the context.Context carrying this span, which is used as the parent of any child spans.
+func (span *OpenTelemetryRequestSpan) Context() gocb.RequestSpanContext {
+	return span.ctx
+}
+
+// SetAttribute sets an attribute on the span. The db.couchbase.server_duration attribute is recorded in
+// microseconds, other durations are recorded in nanoseconds.
+func (span *OpenTelemetryRequestSpan) SetAttribute(key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		span.span.SetAttributes(attribute.String(key, v))
+	case *string:
+		if v != nil {
+			span.span.SetAttributes(attribute.String(key, *v))
+		}
+	case bool:
+		span.span.SetAttributes(attribute.Bool(key, v))
+	case int:
+		span.span.SetAttributes(attribute.Int(key, v))
+	case int32:
+		span.span.SetAttributes(attribute.Int64(key, int64(v)))
+	case int64:
+		span.span.SetAttributes(attribute.Int64(key, v))
+	case uint16:
+		span.span.SetAttributes(attribute.Int64(key, int64(v)))
+	case uint32:
+		span.span.SetAttributes(attribute.Int64(key, int64(v)))
+	case uint64:
+		span.span.SetAttributes(attribute.Int64(key, int64(v)))
+	case float64:
+		span.span.SetAttributes(attribute.Float64(key, v))
+	case time.Duration:
+		if key == serverDurationKey {
+			span.span.SetAttributes(attribute.Int64(key, v.Microseconds()))
+		} else {
+			span.span.SetAttributes(attribute.Int64(key, v.Nanoseconds()))
+		}
+	case time.Time:
+		span.span.AddEvent(key, trace.WithTimestamp(v))
+	case fmt.Stringer:
+		span.span.SetAttributes(attribute.String(key, v.String()))
+	default:
+		span.span.SetAttributes(attribute.String(key, fmt.Sprintf("%v", v)))
+	}
+}
+
+// AddEvent adds an event to the span.
+func (span *OpenTelemetryRequestSpan) AddEvent(name string, timestamp time.Time) {
+	span.span.AddEvent(name, trace.WithTimestamp(timestamp))
+}
@@ -0,0 +1,10 @@
+module github.com/couchbase/gocb/gocbprometheus
+
+require (
+	github.com/couchbase/gocb/v2 v2.4.0
+	github.com/prometheus/client_golang v1.12.1
+)
+
+replace github.com/couchbase/gocb/v2 => ../
+
+go 1.13
@@ -0,0 +1,295 @@
+// Package gocbprometheus provides a gocb Meter which exposes the metrics recorded by the SDK using Prometheus. It is a
+// separate module to gocb, so that applications which do not use it do not depend on Prometheus.
+package gocbprometheus
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// operationsMetricName is the name of the metric which the SDK records operation durations against.
+const operationsMetricName = "db.couchbase.operations"
+
+// operationsLabels are the tags which the SDK records operation durations with, the operation label is only set for
+// operations which specify one and the bucket, scope and collection only for operations made against them. Using a
+// fixed set of labels for the metric keeps the label names consistent, as Prometheus requires, tags which are not set
+// are given an empty value.
+var operationsLabels = []string{
+	"db.couchbase.service",
+	"db.operation",
+	"db.couchbase.operation_label",
+	"db.name",
+	"db.couchbase.scope",
+	"db.couchbase.collection",
+}
+
+// DefaultOperationBuckets are the histogram buckets, in seconds, used for the operation duration metric when none
+// are provided.
+var DefaultOperationBuckets = []float64{
+	0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// PrometheusMeterOptions are the options available when creating a PrometheusMeter.
+// UNCOMMITTED: This API may change in the future.
+type PrometheusMeterOptions struct {
+	// Namespace is prepended to the name of every metric, e.g. "myapp" results in the operation duration metric
+	// being named "myapp_db_couchbase_operations_seconds".
+	Namespace string
+
+	// Buckets are the histogram buckets, in seconds, used for value recorders, defaults to DefaultOperationBuckets.
+	Buckets []float64
+}
+
+// PrometheusMeter is a gocb.Meter which records metrics using Prometheus collectors. The values recorded by the SDK
+// are operation durations in microseconds, these are exposed as histograms in seconds with a "_seconds" suffix,
+// following Prometheus conventions. Counters are exposed with a "_total" suffix. Periods in metric and tag names
+// are replaced with underscores.
+// Each metric is registered as a single collector, and the counters and value recorders returned for each set of
+// tags are cached, so that recording a value does not create any collectors.
+// UNCOMMITTED: This API may change in the future.
+type PrometheusMeter struct {
+	registerer prometheus.Registerer
+	namespace  string
+	buckets    []float64
+
+	collectorsLock sync.Mutex
+	counters       map[string]*prometheusVec
+	histograms     map[string]*prometheusVec
+
+	counterCache  sync.Map
+	recorderCache sync.Map
+}
+
+type prometheusVec struct {
+	labels    []string
+	counter   *prometheus.CounterVec
+	histogram *prometheus.HistogramVec
+}
+
+// NewPrometheusMeter creates a new PrometheusMeter which registers its collectors with the registerer, if the
+// registerer is nil then prometheus.DefaultRegisterer is used. The meter can be set as ClusterOptions.Meter.
+// UNCOMMITTED: This API may change in the future.
+func NewPrometheusMeter(registerer prometheus.Registerer, opts *PrometheusMeterOptions) *PrometheusMeter {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	if opts == nil {
+		opts = &PrometheusMeterOptions{}
+	}
+
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultOperationBuckets
+	}
+
+	return &PrometheusMeter{
+		registerer: registerer,
+		namespace:  opts.Namespace,
+		buckets:    buckets,
+		counters:   make(map[string]*prometheusVec),
+		histograms: make(map[string]*prometheusVec),
+	}
+}
+
+// Counter returns a counter for the metric name and tags.
+func (meter *PrometheusMeter) Counter(name string, tags map[string]string) (gocb.Counter, error) {
+	key := metricKey(name, tags)
+	if counter, ok := meter.counterCache.Load(key); ok {
+		return counter.(*prometheusCounter), nil
+	}
+
+	meter.collectorsLock.Lock()
+	vec, ok := meter.counters[name]
+	if !ok {
+		labels := metricLabels(name, tags)
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: meter.namespace,
+			Name:      sanitizeName(name) + "_total",
+		}, sanitizeNames(labels))
+
+		collector, err := meter.register(counter)
+		if err != nil {
+			meter.collectorsLock.Unlock()
+			return nil, err
+		}
+
+		vec = &prometheusVec{
+			labels:  labels,
+			counter: collector.(*prometheus.CounterVec),
+		}
+		meter.counters[name] = vec
+	}
+	meter.collectorsLock.Unlock()
+
+	labelValues, err := vec.labelValues(name, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	counter, err := vec.counter.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		return nil, err
+	}
+
+	// It doesn't matter if the counter is stored against the same key more than once, they are equivalent.
+	cached, _ := meter.counterCache.LoadOrStore(key, &prometheusCounter{counter: counter})
+	return cached.(*prometheusCounter), nil
+}
+
+// ValueRecorder returns a value recorder for the metric name and tags. Values are recorded as a histogram.
+func (meter *PrometheusMeter) ValueRecorder(name string, tags map[string]string) (gocb.ValueRecorder, error) {
+	key := metricKey(name, tags)
+	if recorder, ok := meter.recorderCache.Load(key); ok {
+		return recorder.(*prometheusValueRecorder), nil
+	}
+
+	meter.collectorsLock.Lock()
+	vec, ok := meter.histograms[name]
+	if !ok {
+		labels := metricLabels(name, tags)
+		histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: meter.namespace,
+			Name:      sanitizeName(name) + "_seconds",
+			Buckets:   meter.buckets,
+		}, sanitizeNames(labels))
+
+		collector, err := meter.register(histogram)
+		if err != nil {
+			meter.collectorsLock.Unlock()
+			return nil, err
+		}
+
+		vec = &prometheusVec{
+			labels:    labels,
+			histogram: collector.(*prometheus.HistogramVec),
+		}
+		meter.histograms[name] = vec
+	}
+	meter.collectorsLock.Unlock()
+
+	labelValues, err := vec.labelValues(name, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	observer, err := vec.histogram.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, _ := meter.recorderCache.LoadOrStore(key, &prometheusValueRecorder{observer: observer})
+	return cached.(*prometheusValueRecorder), nil
+}
+
+// register registers the collector, returning the already registered collector if an equivalent one has already
+// been registered, for example by another PrometheusMeter using the same registerer.
+func (meter *PrometheusMeter) register(collector prometheus.Collector) (prometheus.Collector, error) {
+	err := meter.registerer.Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return alreadyRegistered.ExistingCollector, nil
+	}
+
+	return nil, err
+}
+
+// labelValues returns the values of the labels of the metric in label order, tags which are not set have an empty
+// value.
+func (vec *prometheusVec) labelValues(name string, tags map[string]string) ([]string, error) {
+	values := make([]string, len(vec.labels))
+	var matched int
+	for i, label := range vec.labels {
+		if value, ok := tags[label]; ok {
+			values[i] = value
+			matched++
+		}
+	}
+
+	if matched != len(tags) {
+		return nil, fmt.Errorf("tags for metric %s do not match the labels it was registered with: %v", name,
+			vec.labels)
+	}
+
+	return values, nil
+}
+
+type prometheusCounter struct {
+	counter prometheus.Counter
+}
+
+func (c *prometheusCounter) IncrementBy(num uint64) {
+	c.counter.Add(float64(num))
+}
+
+type prometheusValueRecorder struct {
+	observer prometheus.Observer
+}
+
+// RecordValue records a duration in microseconds.
+func (r *prometheusValueRecorder) RecordValue(val uint64) {
+	r.observer.Observe((time.Duration(val) * time.Microsecond).Seconds())
+}
+
+// metricLabels returns the label names to register a metric with, based on the tags of its first use.
+func metricLabels(name string, tags map[string]string) []string {
+	if name == operationsMetricName {
+		return operationsLabels
+	}
+
+	labels := make([]string, 0, len(tags))
+	for k := range tags {
+		labels = append(labels, k)
+	}
+	sort.Strings(labels)
+
+	return labels
+}
+
+func sanitizeNames(names []string) []string {
+	sanitized := make([]string, len(names))
+	for i, name := range names {
+		sanitized[i] = sanitizeName(name)
+	}
+
+	return sanitized
+}
+
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// metricKey builds a key which uniquely identifies a metric name and set of tags.
+func metricKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, k := range keys {
+		sb.WriteByte(0)
+		sb.WriteString(k)
+		sb.WriteByte(0)
+		sb.WriteString(tags[k])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,188 @@
+package gocbprometheus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPrometheusMeterValueRecorder(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	meter := NewPrometheusMeter(registry, &PrometheusMeterOptions{Namespace: "test"})
+
+	tags := map[string]string{
+		"db.couchbase.service": "kv",
+		"db.operation":         "get",
+	}
+	recorder, err := meter.ValueRecorder(operationsMetricName, tags)
+	if err != nil {
+		t.Fatalf("Failed to create value recorder: %v", err)
+	}
+
+	again, err := meter.ValueRecorder(operationsMetricName, map[string]string{
+		"db.couchbase.service": "kv",
+		"db.operation":         "get",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create value recorder: %v", err)
+	}
+	if again != recorder {
+		t.Fatalf("Expected the value recorder to be reused for the same tags")
+	}
+
+	labeled, err := meter.ValueRecorder(operationsMetricName, map[string]string{
+		"db.couchbase.service":         "kv",
+		"db.operation":                 "get",
+		"db.couchbase.operation_label": "checkout",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create labeled value recorder: %v", err)
+	}
+
+	recorder.RecordValue(1500)
+	recorder.RecordValue(500)
+	labeled.RecordValue(2000)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	if len(families) != 1 {
+		t.Fatalf("Expected 1 metric family but was %d", len(families))
+	}
+
+	family := families[0]
+	if family.GetName() != "test_db_couchbase_operations_seconds" {
+		t.Fatalf("Unexpected metric name %s", family.GetName())
+	}
+	if len(family.GetMetric()) != 2 {
+		t.Fatalf("Expected 2 metrics but was %d", len(family.GetMetric()))
+	}
+
+	for _, metric := range family.GetMetric() {
+		var label string
+		for _, pair := range metric.GetLabel() {
+			if pair.GetName() == "db_couchbase_operation_label" {
+				label = pair.GetValue()
+			}
+		}
+
+		histogram := metric.GetHistogram()
+		switch label {
+		case "":
+			if histogram.GetSampleCount() != 2 || histogram.GetSampleSum() != 0.002 {
+				t.Fatalf("Unexpected histogram count %d and sum %f", histogram.GetSampleCount(), histogram.GetSampleSum())
+			}
+		case "checkout":
+			if histogram.GetSampleCount() != 1 || histogram.GetSampleSum() != 0.002 {
+				t.Fatalf("Unexpected histogram count %d and sum %f", histogram.GetSampleCount(), histogram.GetSampleSum())
+			}
+		default:
+			t.Fatalf("Unexpected operation label %s", label)
+		}
+	}
+}
+
+func TestPrometheusMeterKeyspaceValueRecorder(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	meter := NewPrometheusMeter(registry, nil)
+
+	cluster, err := meter.ValueRecorder(operationsMetricName, map[string]string{
+		"db.couchbase.service": "query",
+		"db.operation":         "query",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create value recorder: %v", err)
+	}
+
+	kv, err := meter.ValueRecorder(operationsMetricName, map[string]string{
+		"db.couchbase.service":    "kv",
+		"db.operation":            "get",
+		"db.name":                 "travel-sample",
+		"db.couchbase.scope":      "inventory",
+		"db.couchbase.collection": "airline",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create keyspace value recorder: %v", err)
+	}
+
+	cluster.RecordValue(1000)
+	kv.RecordValue(500)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	if len(families) != 1 || len(families[0].GetMetric()) != 2 {
+		t.Fatalf("Unexpected metric families %v", families)
+	}
+
+	for _, metric := range families[0].GetMetric() {
+		labels := make(map[string]string)
+		for _, pair := range metric.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+
+		expected := map[string]string{
+			"db_couchbase_service":         "query",
+			"db_operation":                 "query",
+			"db_couchbase_operation_label": "",
+			"db_name":                      "",
+			"db_couchbase_scope":           "",
+			"db_couchbase_collection":      "",
+		}
+		if labels["db_couchbase_service"] == "kv" {
+			expected = map[string]string{
+				"db_couchbase_service":         "kv",
+				"db_operation":                 "get",
+				"db_couchbase_operation_label": "",
+				"db_name":                      "travel-sample",
+				"db_couchbase_scope":           "inventory",
+				"db_couchbase_collection":      "airline",
+			}
+		}
+		if !reflect.DeepEqual(expected, labels) {
+			t.Fatalf("Expected labels %v but were %v", expected, labels)
+		}
+		if metric.GetHistogram().GetSampleCount() != 1 {
+			t.Fatalf("Expected 1 sample but was %d", metric.GetHistogram().GetSampleCount())
+		}
+	}
+}
+
+func TestPrometheusMeterCounter(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	meter := NewPrometheusMeter(registry, nil)
+
+	counter, err := meter.Counter("db.couchbase.retries", map[string]string{"db.couchbase.service": "kv"})
+	if err != nil {
+		t.Fatalf("Failed to create counter: %v", err)
+	}
+	counter.IncrementBy(3)
+
+	// The labels of a metric are fixed by its first use.
+	_, err = meter.Counter("db.couchbase.retries", map[string]string{"db.operation": "get"})
+	if err == nil {
+		t.Fatalf("Expected mismatched tags to fail")
+	}
+
+	// A second meter sharing the registry reuses the registered collectors.
+	other := NewPrometheusMeter(registry, nil)
+	otherCounter, err := other.Counter("db.couchbase.retries", map[string]string{"db.couchbase.service": "kv"})
+	if err != nil {
+		t.Fatalf("Failed to create counter: %v", err)
+	}
+	otherCounter.IncrementBy(2)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	if len(families) != 1 || families[0].GetName() != "db_couchbase_retries_total" {
+		t.Fatalf("Unexpected metric families %v", families)
+	}
+	if value := families[0].GetMetric()[0].GetCounter().GetValue(); value != 5 {
+		t.Fatalf("Expected counter value of 5 but was %f", value)
+	}
+}
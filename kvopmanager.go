@@ -3,6 +3,7 @@ package gocb
 import (
 	"context"
 	"errors"
+	"math"
 	"time"
 
 	gocbcore "github.com/couchbase/gocbcore/v10"
@@ -27,14 +28,23 @@ type kvOpManager struct {
 	persistTo       uint
 	replicateTo     uint
 	durabilityLevel memd.DurabilityLevel
+	durabilityMode  DurabilityMode
+	observeLevel    DurabilityLevel
+	syncLevel       DurabilityLevel
 	retryStrategy   *retryStrategyWrapper
 	cancelCh        chan struct{}
 	impersonate     string
 
-	operationName string
-	createdTime   time.Time
-	meter         *meterWrapper
-	preserveTTL   bool
+	operationName  string
+	operationLabel string
+	createdTime    time.Time
+	meter          *meterWrapper
+	preserveTTL    bool
+
+	serverDuration serverDurationRecorder
+	retries        retryRecorder
+
+	durabilityImpossibleRetries uint32
 
 	ctx context.Context
 }
@@ -49,7 +59,7 @@ func (m *kvOpManager) getTimeout() time.Duration {
 	}
 
 	defaultTimeout := m.parent.timeoutsConfig.KVTimeout
-	if m.durabilityLevel > memd.DurabilityLevelMajority || m.persistTo > 0 {
+	if m.durabilityLevel > memd.DurabilityLevelMajority || m.persistTo > 0 || m.observeLevel > DurabilityLevelMajority {
 		defaultTimeout = m.parent.timeoutsConfig.KVDurableTimeout
 	}
 
@@ -122,6 +132,17 @@ func (m *kvOpManager) SetDuraOptions(persistTo, replicateTo uint, level Durabili
 	m.persistTo = persistTo
 	m.replicateTo = replicateTo
 	m.durabilityLevel, m.err = level.toMemd()
+	if m.err != nil {
+		return
+	}
+
+	m.syncLevel = level
+	if level > DurabilityLevelNone && m.parent.useObserveDurabilityFallback(false) {
+		m.err = m.setObserveFallback()
+		if m.err != nil {
+			return
+		}
+	}
 
 	if level > DurabilityLevelNone {
 		levelStr, err := level.toManagementAPI()
@@ -133,11 +154,77 @@ func (m *kvOpManager) SetDuraOptions(persistTo, replicateTo uint, level Durabili
 	}
 }
 
+// setObserveFallback sends the mutation without a durability level and observes the requested durability level once
+// the mutation has been applied instead.
+func (m *kvOpManager) setObserveFallback() error {
+	if !m.parent.useMutationTokens {
+		return makeInvalidArgumentsError("cannot fall back to observe based durability without mutation tokens")
+	}
+
+	logDebugFieldsf(logFields{operation: m.operationName, bucket: m.parent.bucketName()},
+		"Bucket does not support synchronous durability, falling back to observe based durability")
+	m.durabilityLevel = 0
+	m.observeLevel = m.syncLevel
+
+	return nil
+}
+
+// SetObserveDuraOptions is used by operations which the server does not support synchronous durability for, any
+// durability level is met by observing the mutation instead.
+func (m *kvOpManager) SetObserveDuraOptions(persistTo, replicateTo uint, level DurabilityLevel) {
+	if level == DurabilityLevelUnknown {
+		level = DurabilityLevelNone
+	}
+
+	if persistTo == 0 && replicateTo == 0 && level == DurabilityLevelNone {
+		return
+	}
+
+	if !m.parent.useMutationTokens {
+		m.err = makeInvalidArgumentsError("cannot use observe based durability without mutation tokens")
+		return
+	}
+
+	if level > DurabilityLevelNone && (persistTo != 0 || replicateTo != 0) {
+		m.err = makeInvalidArgumentsError("cannot mix observe based durability and a durability level")
+		return
+	}
+
+	if _, err := level.toMemd(); err != nil {
+		m.err = err
+		return
+	}
+
+	m.persistTo = persistTo
+	m.replicateTo = replicateTo
+	m.observeLevel = level
+
+	if level > DurabilityLevelNone {
+		levelStr, err := level.toManagementAPI()
+		if err != nil {
+			logDebugf("Could not convert durability level to string: %v", err)
+			return
+		}
+		m.span.SetAttribute(spanAttribDBDurability, levelStr)
+	}
+}
+
+func (m *kvOpManager) SetDurabilityMode(mode DurabilityMode) {
+	m.durabilityMode = mode
+}
+
 func (m *kvOpManager) SetRetryStrategy(retryStrategy RetryStrategy) {
 	wrapper := m.parent.retryStrategyWrapper
 	if retryStrategy != nil {
 		wrapper = newRetryStrategyWrapper(retryStrategy)
 	}
+	if wrapper != nil {
+		// Each operation gets its own wrapper so that the retries it makes can be reported on its result.
+		wrapper = &retryStrategyWrapper{
+			wrapped:  wrapper.wrapped,
+			recorder: &m.retries,
+		}
+	}
 	m.retryStrategy = wrapper
 }
 
@@ -145,6 +232,15 @@ func (m *kvOpManager) SetImpersonate(user string) {
 	m.impersonate = user
 }
 
+func (m *kvOpManager) SetOperationLabel(label string) {
+	if label == "" {
+		return
+	}
+
+	m.operationLabel = label
+	m.span.SetAttribute(spanAttribOperationLabelKey, label)
+}
+
 func (m *kvOpManager) SetContext(ctx context.Context) {
 	if ctx == nil {
 		ctx = context.Background()
@@ -160,12 +256,26 @@ func (m *kvOpManager) Finish(noMetrics bool) {
 	m.span.End()
 
 	if !noMetrics {
-		m.meter.ValueRecord(meterValueServiceKV, m.operationName, m.createdTime)
+		m.meter.KeyspaceValueRecord(meterValueServiceKV, m.operationName, m.operationLabel, m.parent.meterKeyspace(),
+			m.createdTime)
 	}
 }
 
+// TraceSpanContext returns the span context to pass to gocbcore, which records the server duration of the operation.
 func (m *kvOpManager) TraceSpanContext() RequestSpanContext {
-	return m.span.Context()
+	return &serverDurationSpanContext{
+		parent:   m.span.Context(),
+		recorder: &m.serverDuration,
+	}
+}
+
+func (m *kvOpManager) ServerDuration() time.Duration {
+	return m.serverDuration.get()
+}
+
+// Retries returns the recorder of the retries made by the operation, which is only complete once Wait has returned.
+func (m *kvOpManager) Retries() *retryRecorder {
+	return &m.retries
 }
 
 func (m *kvOpManager) TraceSpan() RequestSpan {
@@ -250,28 +360,136 @@ func (m *kvOpManager) CheckReadyForOp() error {
 		return errors.New("op manager had no timeout specified")
 	}
 
+	if m.parent.bucket != nil {
+		if err := m.parent.bucket.capabilityWatcher.requiredCapabilityErr(); err != nil {
+			return err
+		}
+
+		if err := m.parent.bucket.kvPinner.checkKey(m.documentID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (m *kvOpManager) NeedsObserve() bool {
-	return m.persistTo > 0 || m.replicateTo > 0
+	return m.persistTo > 0 || m.replicateTo > 0 || m.observeLevel > DurabilityLevelNone
 }
 
 func (m *kvOpManager) EnhanceErr(err error) error {
-	return maybeEnhanceCollKVErr(err, nil, m.parent, m.documentID)
+	return maybeWrapContextErr(m.ctx, maybeEnhanceCollKVErr(err, nil, m.parent, m.documentID))
 }
 
 func (m *kvOpManager) EnhanceMt(token gocbcore.MutationToken) *MutationToken {
 	if token.VbUUID != 0 {
 		return &MutationToken{
-			token:      token,
-			bucketName: m.BucketName(),
+			token:          token,
+			bucketName:     m.BucketName(),
+			scopeName:      m.ScopeName(),
+			collectionName: m.CollectionName(),
 		}
 	}
 
 	return nil
 }
 
+// RetryDurabilityImpossible consults the retry strategy about a durable mutation which failed because the durability
+// level was impossible, waiting for the backoff and returning true if the mutation should be sent again. If the retry
+// strategy declines and the durability mode is best effort then the durability level is dropped, and true is
+// returned so that the mutation is sent again without it.
+// The server doesn't apply a mutation which fails in this way, so it is always safe to send again.
+func (m *kvOpManager) RetryDurabilityImpossible(err error) bool {
+	if m.durabilityLevel == 0 || !errors.Is(err, ErrDurabilityImpossible) {
+		return false
+	}
+
+	if m.backoffDurabilityImpossible() {
+		return true
+	}
+
+	if m.durabilityMode != DurabilityModeBestEffort || m.ctx.Err() != nil {
+		return false
+	}
+
+	logInfof("Durability level could not be met, sending mutation again without durability due to best effort mode")
+	m.durabilityLevel = 0
+	m.span.SetAttribute(spanAttribDBDurability, "none")
+
+	return true
+}
+
+// backoffDurabilityImpossible waits for the backoff of the retry strategy before a mutation which failed with
+// ErrDurabilityImpossible is sent again, returning false if it should not be sent again. Only a
+// BestEffortRetryStrategy with RetryDurabilityImpossible set is consulted, any other strategy never retries it.
+func (m *kvOpManager) backoffDurabilityImpossible() bool {
+	if m.retryStrategy == nil {
+		return false
+	}
+
+	strategy, ok := m.retryStrategy.wrapped.(*BestEffortRetryStrategy)
+	if !ok || !strategy.RetryDurabilityImpossible {
+		return false
+	}
+
+	req := &durabilityImpossibleRetryRequest{
+		attempts:   m.durabilityImpossibleRetries,
+		identifier: m.documentID,
+	}
+	action := strategy.RetryAfter(req, KVDurabilityImpossibleRetryReason)
+	if action == nil {
+		return false
+	}
+
+	duration := action.Duration()
+	if duration == 0 || time.Now().Add(duration).After(m.Deadline()) {
+		return false
+	}
+
+	select {
+	case <-time.After(duration):
+	case <-m.cancelCh:
+		return false
+	case <-m.ctx.Done():
+		return false
+	}
+
+	m.durabilityImpossibleRetries++
+	return true
+}
+
+// RetryObserveFallback switches a durable mutation over to observe based durability when the server rejected its
+// durability level before the bucket was known not to support synchronous durability, returning true if the mutation
+// should be sent again. Without mutation tokens the mutation is not sent again and the rejection is returned as is.
+// The server doesn't apply a mutation which fails in this way, so it is always safe to send again.
+func (m *kvOpManager) RetryObserveFallback(err error) bool {
+	if m.durabilityLevel == 0 ||
+		(!errors.Is(err, ErrFeatureNotAvailable) && !errors.Is(err, ErrDurabilityLevelNotAvailable)) {
+		return false
+	}
+
+	if !m.parent.useObserveDurabilityFallback(true) {
+		return false
+	}
+
+	return m.setObserveFallback() == nil
+}
+
+// WaitDurable dispatches a mutation using dispatch and waits for it as Wait does, dispatching it again for as long as
+// RetryDurabilityImpossible or RetryObserveFallback allow. The callback of the mutation must report its error through
+// errOut.
+func (m *kvOpManager) WaitDurable(errOut *error, dispatch func() (gocbcore.PendingOp, error)) {
+	for {
+		*errOut = nil
+		if err := m.Wait(dispatch()); err != nil {
+			*errOut = err
+		}
+		if !m.RetryDurabilityImpossible(*errOut) && !m.RetryObserveFallback(*errOut) {
+			return
+		}
+	}
+}
+
 func (m *kvOpManager) Reject() {
 	m.signal <- struct{}{}
 }
@@ -301,7 +519,11 @@ func (m *kvOpManager) Wait(op gocbcore.PendingOp, err error) error {
 		<-m.signal
 	}
 
-	if m.wasResolved && (m.persistTo > 0 || m.replicateTo > 0) {
+	if req, ok := op.(retryCountingRequest); ok {
+		m.retries.recordCompleted(req)
+	}
+
+	if m.wasResolved && m.NeedsObserve() {
 		if m.mutationToken == nil {
 			return errors.New("expected a mutation token")
 		}
@@ -313,9 +535,11 @@ func (m *kvOpManager) Wait(op gocbcore.PendingOp, err error) error {
 			m.mutationToken.token,
 			m.replicateTo,
 			m.persistTo,
+			m.observeLevel,
 			m.Deadline(),
 			m.cancelCh,
 			m.impersonate,
+			m.durabilityMode,
 		)
 	}
 
@@ -360,3 +584,30 @@ func durationToExpiry(dura time.Duration) uint32 {
 	// Send the duration as a unix timestamp of now plus duration.
 	return uint32(time.Now().Add(dura).Unix())
 }
+
+func expiryTimeToExpiry(expiryTime time.Time) (uint32, error) {
+	// The zero time indicates never-expires.
+	if expiryTime.IsZero() {
+		return 0, nil
+	}
+
+	if expiryTime.Unix() > math.MaxUint32 {
+		return 0, makeInvalidArgumentsError("expiry time is too far in the future")
+	}
+
+	dura := time.Until(expiryTime)
+
+	// If the time has passed or is less than one second away, we must force
+	// the value to 1 to avoid accidentally making it never expire.
+	if dura < 1*time.Second {
+		return 1, nil
+	}
+
+	if dura < 30*24*time.Hour {
+		// Translate into a uint32 in seconds.
+		return uint32(dura / time.Second), nil
+	}
+
+	// Times beyond 30 days must be sent as a unix timestamp.
+	return uint32(expiryTime.Unix()), nil
+}
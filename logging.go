@@ -93,12 +93,31 @@ func (wrapper coreLogger) Log(level gocbcore.LogLevel, offset int, format string
 	return wrapper.wrapped.Log(LogLevel(level), offset+2, format, v...)
 }
 
+// coreFieldsLogger passes the logs of gocbcore to a fieldsLogger, marking them as coming from gocbcore.
+type coreFieldsLogger struct {
+	wrapped fieldsLogger
+}
+
+func (wrapper coreFieldsLogger) Log(level gocbcore.LogLevel, offset int, format string, v ...interface{}) error {
+	return wrapper.wrapped.LogFields(LogLevel(level), offset+2, logFields{component: "gocbcore"}, format, v...)
+}
+
 func getCoreLogger(logger Logger) gocbcore.Logger {
+	if logger == nil {
+		return nil
+	}
+
 	typedLogger, isCoreLogger := logger.(*coreLogWrapper)
 	if isCoreLogger {
 		return typedLogger.wrapped
 	}
 
+	if typedLogger, isFieldsLogger := logger.(fieldsLogger); isFieldsLogger {
+		return &coreFieldsLogger{
+			wrapped: typedLogger,
+		}
+	}
+
 	return &coreLogger{
 		wrapped: logger,
 	}
@@ -107,15 +126,40 @@ func getCoreLogger(logger Logger) gocbcore.Logger {
 // SetLogger sets a logger to be used by the library. A logger can be obtained via
 // the DefaultStdioLogger() or VerboseStdioLogger() functions. You can also implement
 // your own logger using the Logger interface.
+// Only one logger is active at a time, so this replaces any logger set by SetStructuredLogger.
 func SetLogger(logger Logger) {
 	globalLogger = logger
 	gocbcore.SetLogger(getCoreLogger(logger))
 	// gocbcore.SetLogRedactionLevel(gocbcore.LogRedactLevel(globalLogRedactionLevel))
 }
 
-func logExf(level LogLevel, offset int, format string, v ...interface{}) {
+// logFields describes the context in which a message was logged. The fields are passed as attributes to loggers
+// which support structured logging, such as the one set by SetStructuredLogger, and are otherwise ignored as the
+// message itself already contains any relevant details.
+type logFields struct {
+	component string
+	operation string
+	bucket    string
+	node      string
+}
+
+// fieldsLogger is implemented by loggers which support structured logging.
+type fieldsLogger interface {
+	Logger
+	LogFields(level LogLevel, offset int, fields logFields, format string, v ...interface{}) error
+}
+
+func logExf(level LogLevel, offset int, fields logFields, format string, v ...interface{}) {
 	if globalLogger != nil {
-		err := globalLogger.Log(level, offset+1, format, v...)
+		var err error
+		if logger, ok := globalLogger.(fieldsLogger); ok {
+			if fields.component == "" {
+				fields.component = "gocb"
+			}
+			err = logger.LogFields(level, offset+1, fields, format, v...)
+		} else {
+			err = globalLogger.Log(level, offset+1, format, v...)
+		}
 		if err != nil {
 			log.Printf("Logger error occurred (%s)\n", err)
 		}
@@ -123,23 +167,31 @@ func logExf(level LogLevel, offset int, format string, v ...interface{}) {
 }
 
 func logInfof(format string, v ...interface{}) {
-	logExf(LogInfo, 1, format, v...)
+	logExf(LogInfo, 1, logFields{}, format, v...)
 }
 
 func logDebugf(format string, v ...interface{}) {
-	logExf(LogDebug, 1, format, v...)
+	logExf(LogDebug, 1, logFields{}, format, v...)
 }
 
 func logSchedf(format string, v ...interface{}) {
-	logExf(LogSched, 1, format, v...)
+	logExf(LogSched, 1, logFields{}, format, v...)
 }
 
 func logWarnf(format string, v ...interface{}) {
-	logExf(LogWarn, 1, format, v...)
+	logExf(LogWarn, 1, logFields{}, format, v...)
 }
 
 func logErrorf(format string, v ...interface{}) {
-	logExf(LogError, 1, format, v...)
+	logExf(LogError, 1, logFields{}, format, v...)
+}
+
+func logDebugFieldsf(fields logFields, format string, v ...interface{}) {
+	logExf(LogDebug, 1, fields, format, v...)
+}
+
+func logWarnFieldsf(fields logFields, format string, v ...interface{}) {
+	logExf(LogWarn, 1, fields, format, v...)
 }
 
 func reindentLog(indent, message string) string {
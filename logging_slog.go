@@ -0,0 +1,86 @@
+//go:build go1.21
+// +build go1.21
+
+package gocb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// Levels onto which LogTrace and LogSched are mapped by SetStructuredLogger, slog has no equivalent levels.
+const (
+	slogLevelTrace = slog.LevelDebug - 4
+	slogLevelSched = slog.LevelDebug - 8
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogError:
+		return slog.LevelError
+	case LogWarn:
+		return slog.LevelWarn
+	case LogInfo:
+		return slog.LevelInfo
+	case LogDebug:
+		return slog.LevelDebug
+	case LogTrace:
+		return slogLevelTrace
+	default:
+		return slogLevelSched
+	}
+}
+
+func (l *slogLogger) Log(level LogLevel, offset int, format string, v ...interface{}) error {
+	return l.LogFields(level, offset+1, logFields{}, format, v...)
+}
+
+func (l *slogLogger) LogFields(level LogLevel, offset int, fields logFields, format string, v ...interface{}) error {
+	ctx := context.Background()
+	sLevel := slogLevel(level)
+	// Checking the level first avoids formatting the message and building attributes which would be discarded.
+	if !l.logger.Enabled(ctx, sLevel) {
+		return nil
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(offset+2, pcs[:])
+
+	record := slog.NewRecord(time.Now(), sLevel, fmt.Sprintf(format, v...), pcs[0])
+	if fields.component != "" {
+		record.AddAttrs(slog.String("component", fields.component))
+	}
+	if fields.operation != "" {
+		record.AddAttrs(slog.String("operation", fields.operation))
+	}
+	if fields.bucket != "" {
+		record.AddAttrs(slog.String("bucket", fields.bucket))
+	}
+	if fields.node != "" {
+		record.AddAttrs(slog.String("node", fields.node))
+	}
+
+	return l.logger.Handler().Handle(ctx, record)
+}
+
+// SetStructuredLogger sets a log/slog logger to be used by the library, in place of any logger set by SetLogger.
+// Messages are logged with the attributes component, which is either gocb or gocbcore, and where known operation,
+// bucket and node. LogError, LogWarn, LogInfo and LogDebug are mapped onto the slog levels of the same name, LogTrace
+// onto slog.LevelDebug-4 and LogSched onto slog.LevelDebug-8. Messages are only formatted when the handler of
+// logger is enabled for their level. Passing nil disables logging.
+// UNCOMMITTED: This API may change in the future.
+func SetStructuredLogger(logger *slog.Logger) {
+	if logger == nil {
+		SetLogger(nil)
+		return
+	}
+
+	SetLogger(&slogLogger{logger: logger})
+}
@@ -0,0 +1,67 @@
+//go:build go1.21
+// +build go1.21
+
+package gocb
+
+import (
+	"context"
+	"log/slog"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+type recordingSlogHandler struct {
+	level   slog.Level
+	records []slog.Record
+}
+
+func (h *recordingSlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordingSlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordingSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *recordingSlogHandler) WithGroup(name string) slog.Handler {
+	return h
+}
+
+func (suite *UnitTestSuite) TestStructuredLogger() {
+	previous := globalLogger
+	defer SetLogger(previous)
+
+	handler := &recordingSlogHandler{level: slog.LevelInfo}
+	SetStructuredLogger(slog.New(handler))
+
+	logWarnFieldsf(logFields{bucket: "default"}, "Bucket %s is %s", "default", "unhappy")
+	logDebugf("Not logged at info level")
+	getCoreLogger(globalLogger).Log(gocbcore.LogError, 0, "From %s", "gocbcore")
+
+	suite.Require().Len(handler.records, 2)
+	suite.Assert().Equal(slog.LevelWarn, handler.records[0].Level)
+	suite.Assert().Equal("Bucket default is unhappy", handler.records[0].Message)
+	suite.Assert().Equal(map[string]string{"component": "gocb", "bucket": "default"}, slogAttrs(handler.records[0]))
+	suite.Assert().Equal(slog.LevelError, handler.records[1].Level)
+	suite.Assert().Equal(map[string]string{"component": "gocbcore"}, slogAttrs(handler.records[1]))
+
+	// Only one logger is active at a time.
+	SetLogger(VerboseStdioLogger())
+	logWarnf("Not logged to slog")
+	suite.Assert().Len(handler.records, 2)
+}
+
+func slogAttrs(record slog.Record) map[string]string {
+	attrs := make(map[string]string)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+
+	return attrs
+}
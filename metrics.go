@@ -7,6 +7,10 @@ import (
 )
 
 // Meter handles metrics information for SDK operations.
+// The operations value recorder is tagged with the service (db.couchbase.service) and operation (db.operation) of
+// the request, and where applicable with the bucket (db.name), scope (db.couchbase.scope) and collection
+// (db.couchbase.collection) that the request was made against. Document IDs and other per request values are never
+// used as tags, so the number of distinct sets of tags remains bounded.
 type Meter interface {
 	Counter(name string, tags map[string]string) (Counter, error)
 	ValueRecorder(name string, tags map[string]string) (ValueRecorder, error)
@@ -117,21 +121,67 @@ func newMeterWrapper(meter Meter) *meterWrapper {
 	}
 }
 
+// meterKeyspace is the bucket, scope and collection that an operation was made against, any of which can be empty
+// if the operation was not made against them.
+type meterKeyspace struct {
+	bucket     string
+	scope      string
+	collection string
+}
+
+type meterAttribsKey struct {
+	service   string
+	operation string
+	label     string
+	keyspace  meterKeyspace
+}
+
 func (mw *meterWrapper) ValueRecorder(service, operation string) (ValueRecorder, error) {
+	return mw.LabeledValueRecorder(service, operation, "")
+}
+
+// LabeledValueRecorder returns a value recorder for the service and operation which is additionally tagged with the
+// user supplied operation label, if one is set.
+func (mw *meterWrapper) LabeledValueRecorder(service, operation, label string) (ValueRecorder, error) {
+	return mw.KeyspaceValueRecorder(service, operation, label, meterKeyspace{})
+}
+
+// KeyspaceValueRecorder returns a value recorder for the service and operation which is additionally tagged with the
+// user supplied operation label, if one is set, and with the parts of the keyspace which are set.
+func (mw *meterWrapper) KeyspaceValueRecorder(service, operation, label string,
+	keyspace meterKeyspace) (ValueRecorder, error) {
 	if mw.isNoopMeter {
 		// If it's a noop meter then let's not pay the overhead of creating and caching attributes.
 		return defaultNoopValueRecorder, nil
 	}
 
-	key := service + "." + operation
+	key := meterAttribsKey{
+		service:   service,
+		operation: operation,
+		label:     label,
+		keyspace:  keyspace,
+	}
 	attribs, ok := mw.attribsCache.Load(key)
 	if !ok {
 		// It doesn't really matter if we end up storing the attribs against the same key multiple times. We just need
 		// to have a read efficient cache that doesn't cause actual data races.
-		attribs = map[string]string{
+		labeledAttribs := map[string]string{
 			meterAttribServiceKey:   service,
 			meterAttribOperationKey: operation,
 		}
+		if label != "" {
+			labeledAttribs[meterAttribOperationLabel] = label
+		}
+		if keyspace.bucket != "" {
+			labeledAttribs[meterAttribBucketKey] = keyspace.bucket
+		}
+		if keyspace.scope != "" {
+			labeledAttribs[meterAttribScopeKey] = keyspace.scope
+		}
+		if keyspace.collection != "" {
+			labeledAttribs[meterAttribCollectionKey] = keyspace.collection
+		}
+		attribs = labeledAttribs
 		mw.attribsCache.Store(key, attribs)
 	}
 
@@ -144,7 +194,16 @@ func (mw *meterWrapper) ValueRecorder(service, operation string) (ValueRecorder,
 }
 
 func (mw *meterWrapper) ValueRecord(service, operation string, start time.Time) {
-	recorder, err := mw.ValueRecorder(service, operation)
+	mw.KeyspaceValueRecord(service, operation, "", meterKeyspace{}, start)
+}
+
+func (mw *meterWrapper) LabeledValueRecord(service, operation, label string, start time.Time) {
+	mw.KeyspaceValueRecord(service, operation, label, meterKeyspace{}, start)
+}
+
+func (mw *meterWrapper) KeyspaceValueRecord(service, operation, label string, keyspace meterKeyspace,
+	start time.Time) {
+	recorder, err := mw.KeyspaceValueRecorder(service, operation, label, keyspace)
 	if err != nil {
 		logDebugf("Failed to create value recorder: %v", err)
 		return
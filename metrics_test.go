@@ -15,6 +15,7 @@ func (tc *testCounter) IncrementBy(val uint64) {
 
 type testValueRecorder struct {
 	values []uint64
+	tags   map[string]string
 	lock   sync.Mutex
 }
 
@@ -64,7 +65,9 @@ func (tc *testMeter) ValueRecorder(name string, tags map[string]string) (ValueRe
 	tc.lock.Lock()
 	recorder := tc.recorders[key]
 	if recorder == nil {
-		recorder = &testValueRecorder{}
+		recorder = &testValueRecorder{
+			tags: tags,
+		}
 		tc.recorders[key] = recorder
 	}
 	tc.lock.Unlock()
@@ -11,6 +11,7 @@ import (
 
 type mgmtRequest struct {
 	Service      ServiceType
+	Endpoint     string
 	Method       string
 	Path         string
 	Body         []byte
@@ -53,6 +54,7 @@ func (c *Cluster) executeMgmtRequest(ctx context.Context, req mgmtRequest) (mgmt
 
 	corereq := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(req.Service),
+		Endpoint:      req.Endpoint,
 		Method:        req.Method,
 		Path:          req.Path,
 		Body:          req.Body,
@@ -67,7 +69,7 @@ func (c *Cluster) executeMgmtRequest(ctx context.Context, req mgmtRequest) (mgmt
 
 	coreresp, err := provider.DoHTTPRequest(ctx, corereq)
 	if err != nil {
-		return nil, makeGenericHTTPError(err, corereq, coreresp)
+		return nil, makeGenericHTTPError(maybeWrapContextErr(ctx, err), corereq, coreresp)
 	}
 
 	resp := &mgmtResponse{
@@ -96,6 +98,7 @@ func (b *Bucket) executeMgmtRequest(ctx context.Context, req mgmtRequest) (mgmtR
 
 	corereq := &gocbcore.HTTPRequest{
 		Service:       gocbcore.ServiceType(req.Service),
+		Endpoint:      req.Endpoint,
 		Method:        req.Method,
 		Path:          req.Path,
 		Body:          req.Body,
@@ -110,7 +113,7 @@ func (b *Bucket) executeMgmtRequest(ctx context.Context, req mgmtRequest) (mgmtR
 
 	coreresp, err := provider.DoHTTPRequest(ctx, corereq)
 	if err != nil {
-		return nil, makeGenericHTTPError(err, corereq, coreresp)
+		return nil, makeGenericHTTPError(maybeWrapContextErr(ctx, err), corereq, coreresp)
 	}
 
 	resp := &mgmtResponse{
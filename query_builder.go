@@ -0,0 +1,252 @@
+package gocb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder builds simple SELECT statements for use with the query service.
+//
+// Values given to Where are never added to the statement text, instead each one is bound to a named parameter which
+// is returned alongside the statement by Build. Keyspace and field names are escaped with backticks. The builder
+// only covers the common shapes of query, statements which need more of N1QL should be written by hand and use
+// QueryOptions.NamedParameters or QueryOptions.PositionalParameters for their values.
+//
+// A QueryBuilder must not be used concurrently.
+// UNCOMMITTED: This API may change in the future.
+type QueryBuilder struct {
+	fields   []string
+	keyspace string
+	where    []string
+	orderBy  []string
+	limit    *uint32
+	offset   *uint32
+	params   map[string]interface{}
+	err      error
+}
+
+// queryBuilderOperators are the comparison operators supported by QueryBuilder.Where.
+var queryBuilderOperators = map[string]struct{}{
+	"=":        {},
+	"!=":       {},
+	"<>":       {},
+	"<":        {},
+	"<=":       {},
+	">":        {},
+	">=":       {},
+	"LIKE":     {},
+	"NOT LIKE": {},
+	"IN":       {},
+	"NOT IN":   {},
+}
+
+// NewQueryBuilder creates a new QueryBuilder which selects the given fields, or all fields if none are given.
+// UNCOMMITTED: This API may change in the future.
+func NewQueryBuilder(fields ...string) *QueryBuilder {
+	qb := &QueryBuilder{
+		params: make(map[string]interface{}),
+	}
+
+	for _, field := range fields {
+		if field == "*" {
+			qb.fields = append(qb.fields, field)
+			continue
+		}
+
+		qb.fields = append(qb.fields, qb.escapePath(field))
+	}
+
+	return qb
+}
+
+// From sets the keyspace to select from. The keyspace is either a bucket name, or a bucket name, scope name and
+// collection name. When the statement is run using Scope.QueryWithBuilder then the collection name alone may be given.
+func (qb *QueryBuilder) From(keyspace ...string) *QueryBuilder {
+	if len(keyspace) != 1 && len(keyspace) != 3 {
+		qb.setErr(makeInvalidArgumentsError("keyspace must be a bucket name, or a bucket, scope and collection name"))
+		return qb
+	}
+
+	parts := make([]string, len(keyspace))
+	for i, part := range keyspace {
+		parts[i] = qb.escapeIdentifier(part)
+	}
+	qb.keyspace = strings.Join(parts, ".")
+
+	return qb
+}
+
+// Where adds a condition comparing a field against a value, multiple conditions are combined using AND.
+// The value is always sent as a query parameter. Supported operators are =, !=, <>, <, <=, >, >=, LIKE, NOT LIKE, IN
+// and NOT IN, for IN and NOT IN the value should be a slice.
+func (qb *QueryBuilder) Where(field, operator string, value interface{}) *QueryBuilder {
+	operator = strings.ToUpper(strings.TrimSpace(operator))
+	if _, ok := queryBuilderOperators[operator]; !ok {
+		qb.setErr(makeInvalidArgumentsError(fmt.Sprintf("unsupported where operator %s", operator)))
+		return qb
+	}
+
+	paramName := "qb" + strconv.Itoa(len(qb.params)+1)
+	qb.params[paramName] = value
+	qb.where = append(qb.where, fmt.Sprintf("%s %s $%s", qb.escapePath(field), operator, paramName))
+
+	return qb
+}
+
+// OrderBy adds a field to order the results by, fields are applied in the order that they are added.
+func (qb *QueryBuilder) OrderBy(field string, descending bool) *QueryBuilder {
+	order := qb.escapePath(field)
+	if descending {
+		order += " DESC"
+	}
+	qb.orderBy = append(qb.orderBy, order)
+
+	return qb
+}
+
+// Limit sets the maximum number of results to return.
+func (qb *QueryBuilder) Limit(limit uint32) *QueryBuilder {
+	qb.limit = &limit
+	return qb
+}
+
+// Offset sets the number of results to skip before results are returned.
+func (qb *QueryBuilder) Offset(offset uint32) *QueryBuilder {
+	qb.offset = &offset
+	return qb
+}
+
+// Build returns the statement along with the named parameters which must be used when executing it.
+func (qb *QueryBuilder) Build() (string, map[string]interface{}, error) {
+	if qb.err != nil {
+		return "", nil, qb.err
+	}
+
+	if qb.keyspace == "" {
+		return "", nil, makeInvalidArgumentsError("keyspace must be set using From")
+	}
+
+	fields := "*"
+	if len(qb.fields) > 0 {
+		fields = strings.Join(qb.fields, ", ")
+	}
+
+	var statement strings.Builder
+	statement.WriteString("SELECT ")
+	statement.WriteString(fields)
+	statement.WriteString(" FROM ")
+	statement.WriteString(qb.keyspace)
+
+	if len(qb.where) > 0 {
+		statement.WriteString(" WHERE ")
+		statement.WriteString(strings.Join(qb.where, " AND "))
+	}
+
+	if len(qb.orderBy) > 0 {
+		statement.WriteString(" ORDER BY ")
+		statement.WriteString(strings.Join(qb.orderBy, ", "))
+	}
+
+	if qb.limit != nil {
+		statement.WriteString(" LIMIT ")
+		statement.WriteString(strconv.FormatUint(uint64(*qb.limit), 10))
+	}
+
+	if qb.offset != nil {
+		statement.WriteString(" OFFSET ")
+		statement.WriteString(strconv.FormatUint(uint64(*qb.offset), 10))
+	}
+
+	params := make(map[string]interface{}, len(qb.params))
+	for k, v := range qb.params {
+		params[k] = v
+	}
+
+	return statement.String(), params, nil
+}
+
+// buildOptions builds the statement and returns a copy of the options with the parameters of the statement set.
+func (qb *QueryBuilder) buildOptions(opts *QueryOptions) (string, *QueryOptions, error) {
+	statement, params, err := qb.Build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var builtOpts QueryOptions
+	if opts != nil {
+		builtOpts = *opts
+	}
+
+	if builtOpts.PositionalParameters != nil {
+		return "", nil, makeInvalidArgumentsError("PositionalParameters cannot be used with a query builder")
+	}
+
+	namedParams := make(map[string]interface{}, len(builtOpts.NamedParameters)+len(params))
+	for k, v := range builtOpts.NamedParameters {
+		namedParams[strings.TrimPrefix(k, "$")] = v
+	}
+	for k, v := range params {
+		if _, ok := namedParams[k]; ok {
+			return "", nil, makeInvalidArgumentsError(fmt.Sprintf("named parameter %s is reserved by the query builder", k))
+		}
+		namedParams[k] = v
+	}
+	builtOpts.NamedParameters = namedParams
+
+	return statement, &builtOpts, nil
+}
+
+func (qb *QueryBuilder) setErr(err error) {
+	if qb.err == nil {
+		qb.err = err
+	}
+}
+
+func (qb *QueryBuilder) escapeIdentifier(identifier string) string {
+	if identifier == "" {
+		qb.setErr(makeInvalidArgumentsError("identifiers cannot be empty"))
+	}
+
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+// escapePath escapes each element of a dotted field path, e.g. address.city becomes `address`.`city`.
+func (qb *QueryBuilder) escapePath(path string) string {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		parts[i] = qb.escapeIdentifier(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// QueryWithBuilder executes the statement built by the query builder on the server, the parameters of the
+// statement are added to any NamedParameters set on the options.
+// UNCOMMITTED: This API may change in the future.
+func (c *Cluster) QueryWithBuilder(builder *QueryBuilder, opts *QueryOptions) (*QueryResult, error) {
+	statement, builtOpts, err := builder.buildOptions(opts)
+	if err != nil {
+		return nil, QueryError{
+			InnerError: err,
+			Statement:  statement,
+		}
+	}
+
+	return c.Query(statement, builtOpts)
+}
+
+// QueryWithBuilder executes the statement built by the query builder on the server, constraining the query to the
+// bucket and scope. The parameters of the statement are added to any NamedParameters set on the options.
+// UNCOMMITTED: This API may change in the future.
+func (s *Scope) QueryWithBuilder(builder *QueryBuilder, opts *QueryOptions) (*QueryResult, error) {
+	statement, builtOpts, err := builder.buildOptions(opts)
+	if err != nil {
+		return nil, QueryError{
+			InnerError: err,
+			Statement:  statement,
+		}
+	}
+
+	return s.Query(statement, builtOpts)
+}
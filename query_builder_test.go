@@ -0,0 +1,75 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestQueryBuilderBuild() {
+	statement, params, err := NewQueryBuilder("name", "address.city").
+		From("travel-sample", "inventory", "airline").
+		Where("country", "=", "France").
+		Where("id", "in", []int{1, 2}).
+		OrderBy("name", true).
+		Limit(10).
+		Offset(20).
+		Build()
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal("SELECT `name`, `address`.`city` FROM `travel-sample`.`inventory`.`airline` "+
+		"WHERE `country` = $qb1 AND `id` IN $qb2 ORDER BY `name` DESC LIMIT 10 OFFSET 20", statement)
+	suite.Assert().Equal(map[string]interface{}{
+		"qb1": "France",
+		"qb2": []int{1, 2},
+	}, params)
+
+	statement, _, err = NewQueryBuilder().From("we`ird").Build()
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("SELECT * FROM `we``ird`", statement)
+
+	_, _, err = NewQueryBuilder().From("default").Where("name", "= 1 OR 1 =", "x").Build()
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	_, _, err = NewQueryBuilder().Build()
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
+
+func (suite *UnitTestSuite) TestClusterQueryWithBuilder() {
+	reader := &mockQueryRowReader{
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta:  []byte("{}"),
+			Suite: suite,
+		},
+	}
+
+	cluster := suite.queryCluster(false, reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal("SELECT * FROM `default` WHERE `type` = $qb1", actualOptions["statement"])
+		suite.Assert().Equal("airline", actualOptions["$qb1"])
+		suite.Assert().Equal("bang", actualOptions["$cilit"])
+	})
+
+	opts := &QueryOptions{
+		NamedParameters: map[string]interface{}{
+			"cilit": "bang",
+		},
+	}
+	result, err := cluster.QueryWithBuilder(NewQueryBuilder().From("default").Where("type", "=", "airline"), opts)
+	suite.Require().Nil(err, err)
+	suite.Require().NotNil(result)
+
+	// The callers options must not be modified.
+	suite.Assert().Len(opts.NamedParameters, 1)
+}
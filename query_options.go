@@ -2,6 +2,8 @@ package gocb
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -19,11 +21,44 @@ const (
 	QueryScanConsistencyRequestPlus
 )
 
+// QueryScanVectorEntry is the position within a single vbucket which a query's indexes must have reached.
+// UNCOMMITTED: This API may change in the future.
+type QueryScanVectorEntry struct {
+	SeqNo  uint64
+	VbUUID uint64
+}
+
+// MarshalJSON marshals the entry into the [seqno, "vbuuid"] form expected by the query service.
+func (e QueryScanVectorEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.SeqNo, strconv.FormatUint(e.VbUUID, 10)})
+}
+
+// QueryScanVector is a full scan vector for a bucket, holding one entry for every vbucket of the bucket indexed by
+// vbucket ID.
+// UNCOMMITTED: This API may change in the future.
+type QueryScanVector []QueryScanVectorEntry
+
 // QueryOptions represents the options available when executing a query.
 type QueryOptions struct {
 	ScanConsistency QueryScanConsistency
-	ConsistentWith  *MutationState
-	Profile         QueryProfileMode
+
+	// ConsistentWith causes the query to wait until its indexes include at least the mutations held by the state.
+	// The tokens are sent as one scan vector per bucket, see MutationState for details of how the vectors are built.
+	// If only the mutations to some of the collections are relevant to the query then use MutationState.ForCollection
+	// to limit the state to them.
+	ConsistentWith *MutationState
+
+	// ScanVectors causes the query to wait until its indexes have reached the given positions, keyed by bucket name,
+	// as an alternative to ConsistentWith for when the positions do not come from mutations made by this SDK.
+	// Each vector must contain an entry for every vbucket of its bucket, the bucket must have been opened so that the
+	// number of vbuckets can be verified before the query is sent. ScanVectors cannot be used together with
+	// ScanConsistency or ConsistentWith.
+	// UNCOMMITTED: This API may change in the future.
+	ScanVectors map[string]QueryScanVector
+
+	// Profile requests profiling information for the query, which is returned in QueryMetaData.Profile and
+	// QueryMetaData.ParsedProfile.
+	Profile QueryProfileMode
 
 	// ScanCap is the maximum buffered channel size between the indexer connectionManager and the query service for index scans.
 	ScanCap uint32
@@ -36,6 +71,9 @@ type QueryOptions struct {
 
 	// ScanWait is how long the indexer is allowed to wait until it can satisfy ScanConsistency/ConsistentWith criteria.
 	ScanWait time.Duration
+
+	// Readonly indicates that the statement must not modify any data. The query service enforces this by rejecting
+	// DML and DDL statements, such as INSERT, DELETE or CREATE INDEX, with an error before they are executed.
 	Readonly bool
 
 	// MaxParallelism is the maximum number of index partitions, for computing aggregation in parallel.
@@ -43,18 +81,65 @@ type QueryOptions struct {
 
 	// ClientContextID provides a unique ID for this query which can be used matching up requests between connectionManager and
 	// server. If not provided will be assigned a uuid value.
-	ClientContextID      string
+	ClientContextID string
+
+	// PositionalParameters provides values for the $1, $2... placeholders within the statement.
+	// PositionalParameters and NamedParameters cannot be used together, doing so returns an ErrInvalidArgument
+	// before the query is sent.
 	PositionalParameters []interface{}
-	NamedParameters      map[string]interface{}
-	Metrics              bool
+
+	// NamedParameters provides values for the $name placeholders within the statement, the leading $ is optional
+	// within the map keys.
+	// PositionalParameters and NamedParameters cannot be used together, doing so returns an ErrInvalidArgument
+	// before the query is sent.
+	NamedParameters map[string]interface{}
+
+	Metrics bool
+
+	// Controls causes the query service to report the request level settings that it applied to the query, such as
+	// the scan consistency which was actually used, in QueryMetaData.Controls.
+	// UNCOMMITTED: This API may change in the future.
+	Controls bool
+
+	// QueryContext is the context in which unqualified keyspaces within the statement are resolved, in the form
+	// "default:`bucket`.`scope`". Scope.Query defaults this to the scope that it is called on, fully qualified
+	// keyspaces within the statement are always resolved as written regardless of the context.
+	// UNCOMMITTED: This API may change in the future.
+	QueryContext string
 
 	// Raw provides a way to provide extra parameters in the request body for the query.
 	Raw map[string]interface{}
 
-	Adhoc         bool
-	Timeout       time.Duration
+	// MaxRows is the maximum number of rows which may be read from the results, guarding against a query which is
+	// missing a LIMIT clause returning more rows than the application can hold in memory. Once more than MaxRows rows
+	// have been received the request is canceled, Next returns false and Err returns ErrQueryMaxRowsExceeded.
+	// This is a client side safety valve which is not sent to the server, the server may have done the work of
+	// producing more rows before the request is canceled, use a LIMIT clause to restrict the rows that the server
+	// produces. MaxRows does not apply to results read using QueryResult.Raw. A value of 0 means no limit.
+	// UNCOMMITTED: This API may change in the future.
+	MaxRows uint64
+
+	// Deserializer is used to decode the rows returned by Row and One, defaults to JSONDeserializer.
+	// UNCOMMITTED: This API may change in the future.
+	Deserializer Deserializer
+
+	Adhoc bool
+
+	// Timeout is the client side timeout for the query, defaults to TimeoutsConfig.QueryTimeout. The time remaining
+	// until the earlier of Timeout and the deadline of Context is also sent to the query service as the server timeout,
+	// on every attempt. There is no margin between the two, gocbcore enforces the same deadline on the client, so a
+	// query which runs too long usually fails with a client side timeout rather than with the server's timeout error.
+	// A timeout set using Raw is replaced by gocbcore and does not reach the server. QueryError.TimeoutSource reports
+	// which of the two was hit, and QueryError.ClientContextID can be used to find the query in the server logs.
+	Timeout time.Duration
+
 	RetryStrategy RetryStrategy
 
+	// CircuitBreakerCallback overrides the CompletionCallback of the query circuit breaker, configured by
+	// ClusterOptions.ServiceCircuitBreakerConfig, when deciding whether the outcome of this query counts as a success.
+	// UNCOMMITTED: This API may change in the future.
+	CircuitBreakerCallback CircuitBreakerCallback
+
 	// FlexIndex tells the query engine to use a flex index (utilizing the search service).
 	FlexIndex bool
 
@@ -65,7 +150,8 @@ type QueryOptions struct {
 	ParentSpan RequestSpan
 
 	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
-	// also applies to global level timeouts.
+	// also applies to global level timeouts. The deadline of Context is sent to the query service as the server
+	// timeout in the same way as Timeout, with the same lack of margin.
 	// UNCOMMITTED: This API may change in the future.
 	Context context.Context
 
@@ -76,6 +162,33 @@ type QueryOptions struct {
 	}
 }
 
+// validateScanVectors checks that each of the scan vectors has an entry for every vbucket of its bucket.
+func (opts *QueryOptions) validateScanVectors(getKvProvider func(bucketName string) (kvProvider, error)) error {
+	for bucketName, vector := range opts.ScanVectors {
+		agent, err := getKvProvider(bucketName)
+		if err != nil {
+			return makeInvalidArgumentsError(fmt.Sprintf("bucket %s must be opened to use its scan vector", bucketName))
+		}
+
+		snapshot, err := agent.ConfigSnapshot()
+		if err != nil {
+			return err
+		}
+
+		numVbuckets, err := snapshot.NumVbuckets()
+		if err != nil {
+			return err
+		}
+
+		if len(vector) != numVbuckets {
+			return makeInvalidArgumentsError(fmt.Sprintf("scan vector for bucket %s has %d entries but the bucket has %d vbuckets",
+				bucketName, len(vector), numVbuckets))
+		}
+	}
+
+	return nil
+}
+
 func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 	execOpts := make(map[string]interface{})
 
@@ -93,11 +206,20 @@ func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 		}
 	}
 
+	if opts.ScanVectors != nil && (opts.ScanConsistency != 0 || opts.ConsistentWith != nil) {
+		return nil, makeInvalidArgumentsError("ScanVectors cannot be used with ScanConsistency or ConsistentWith")
+	}
+
 	if opts.ConsistentWith != nil {
 		execOpts["scan_consistency"] = "at_plus"
 		execOpts["scan_vectors"] = opts.ConsistentWith
 	}
 
+	if opts.ScanVectors != nil {
+		execOpts["scan_consistency"] = "at_plus"
+		execOpts["scan_vectors"] = opts.ScanVectors
+	}
+
 	if opts.Profile != "" {
 		execOpts["profile"] = opts.Profile
 	}
@@ -107,7 +229,7 @@ func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 	}
 
 	if opts.PositionalParameters != nil && opts.NamedParameters != nil {
-		return nil, makeInvalidArgumentsError("Positional and named parameters must be used exclusively")
+		return nil, makeInvalidArgumentsError("PositionalParameters and NamedParameters must be used exclusively, only one of them may be set")
 	}
 
 	if opts.PositionalParameters != nil {
@@ -139,6 +261,10 @@ func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 		execOpts["scan_wait"] = opts.ScanWait.String()
 	}
 
+	if opts.QueryContext != "" {
+		execOpts["query_context"] = opts.QueryContext
+	}
+
 	if opts.Raw != nil {
 		for k, v := range opts.Raw {
 			execOpts[k] = v
@@ -153,8 +279,12 @@ func (opts *QueryOptions) toMap() (map[string]interface{}, error) {
 		execOpts["metrics"] = false
 	}
 
+	if opts.Controls {
+		execOpts["controls"] = true
+	}
+
 	if opts.ClientContextID == "" {
-		execOpts["client_context_id"] = uuid.New()
+		execOpts["client_context_id"] = uuid.New().String()
 	} else {
 		execOpts["client_context_id"] = opts.ClientContextID
 	}
@@ -2,12 +2,18 @@ package gocb
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Result is the base type for the return types of operations
 type Result struct {
-	cas Cas
+	cas             Cas
+	serverDuration  time.Duration
+	replicaFallback *ReplicaFallbackDetails
+	retries         *retryRecorder
 }
 
 // Cas returns the cas of the result.
@@ -15,6 +21,85 @@ func (d *Result) Cas() Cas {
 	return d.cas
 }
 
+// RetryAttempts returns the number of times that the operation was retried before it succeeded, including retries
+// which the SDK always makes without consulting the retry strategy, such as for KVNotMyVBucketRetryReason.
+// UNCOMMITTED: This API may change in the future.
+func (d *Result) RetryAttempts() uint32 {
+	if d.retries == nil {
+		return 0
+	}
+
+	return d.retries.get().attempts
+}
+
+// RetryReasons returns the distinct reasons for which the operation was retried, see RetryAttempts.
+// UNCOMMITTED: This API may change in the future.
+func (d *Result) RetryReasons() []RetryReason {
+	if d.retries == nil {
+		return nil
+	}
+
+	return d.retries.get().reasons
+}
+
+// ResultInternal provides access to internal only functionality.
+// Internal: This should never be used and is not supported.
+type ResultInternal struct {
+	serverDuration  time.Duration
+	replicaFallback *ReplicaFallbackDetails
+}
+
+// Internal provides access to internal only functionality.
+// Internal: This should never be used and is not supported.
+func (d *Result) Internal() *ResultInternal {
+	return &ResultInternal{
+		serverDuration:  d.serverDuration,
+		replicaFallback: d.replicaFallback,
+	}
+}
+
+// ServerDuration returns the time that the server reported spending processing the operation, allowing it to be
+// separated from the time spent on the network. This is 0 if the server did not report a duration, such as when
+// server durations are disabled with IoConfig.DisableServerDurations. If the operation was retried then this is the
+// duration reported for the final attempt.
+func (r *ResultInternal) ServerDuration() time.Duration {
+	return r.serverDuration
+}
+
+// ReplicaFallbackDetails describes how a Get using the replica options of GetOptions was served.
+// Internal: This should never be used and is not supported.
+type ReplicaFallbackDetails struct {
+	// FellBack is whether copies other than the preferred copy were read, because the preferred copy did not respond
+	// within the fallback delay or could not be read.
+	FellBack bool
+
+	// ActiveWait is how long the preferred copy was waited for before falling back to the other copies, or how long
+	// it took to respond if the read did not fall back.
+	ActiveWait time.Duration
+
+	// ReplicaIndex is the index of the copy which served the document, 0 being the active and 1 and above being
+	// the replicas. The preferred copy can still serve the document after falling back, if it responds first.
+	ReplicaIndex int
+}
+
+// ReplicaFallback returns how the document was read when any of GetOptions.ReplicaFallbackDelay,
+// GetOptions.ReplicaFallback or GetOptions.ReadPreference was set, or nil if none were set.
+func (r *ResultInternal) ReplicaFallback() *ReplicaFallbackDetails {
+	return r.replicaFallback
+}
+
+// GetResultSource describes where the document held by a GetResult was read from.
+// UNCOMMITTED: This API may change in the future.
+type GetResultSource uint8
+
+const (
+	// GetResultSourceActive indicates that the document was read from the active copy on the server.
+	GetResultSourceActive GetResultSource = iota
+
+	// GetResultSourceReplica indicates that the document was read from a replica copy on the server.
+	GetResultSourceReplica
+)
+
 // GetResult is the return type of Get operations.
 type GetResult struct {
 	Result
@@ -22,6 +107,21 @@ type GetResult struct {
 	flags      uint32
 	contents   []byte
 	expiryTime *time.Time
+	source     GetResultSource
+}
+
+// Source returns where the document was read from, allowing reads of active and replica copies to be told apart
+// without inspecting which operation produced the result.
+// UNCOMMITTED: This API may change in the future.
+func (d *GetResult) Source() GetResultSource {
+	return d.source
+}
+
+// IsReplica returns whether the document was read from a replica, and so may be stale, which is equivalent to
+// comparing Source with GetResultSourceReplica.
+// UNCOMMITTED: This API may change in the future.
+func (d *GetResult) IsReplica() bool {
+	return d.source == GetResultSourceReplica
 }
 
 // Content assigns the value of the result into the valuePtr using default decoding.
@@ -29,6 +129,22 @@ func (d *GetResult) Content(valuePtr interface{}) error {
 	return d.transcoder.Decode(d.contents, d.flags, valuePtr)
 }
 
+// Flags returns the raw flags stored alongside the document.
+// Documents written by Couchbase SDKs use the common flags format, where the top 8 bits of the flags describe the
+// format of the document: 0x01 private (legacy), 0x02 JSON, 0x03 binary and 0x04 string. The remaining bits are
+// reserved for legacy, SDK specific, flags. Documents written by older or non-Couchbase clients may use a different
+// format entirely, in which case the top 8 bits will be 0. Such documents can be written using RawFlagsTranscoder.
+func (d *GetResult) Flags() uint32 {
+	return d.flags
+}
+
+// Format returns the format of the document, as indicated by its flags, allowing documents written by other SDKs in
+// formats other than JSON to be detected before they are decoded.
+// UNCOMMITTED: This API may change in the future.
+func (d *GetResult) Format() DocumentFormat {
+	return DocumentFormatFromFlags(d.flags)
+}
+
 // Expiry returns the expiry value for the result if it available.  Note that a nil
 // pointer indicates that the Expiry was not fetched, while a valid pointer to a zero
 // Duration indicates that the document will never expire.
@@ -38,7 +154,10 @@ func (d *GetResult) Expiry() *time.Duration {
 		return nil
 	}
 
-	t := time.Until(*d.expiryTime)
+	var t time.Duration
+	if !d.expiryTime.IsZero() {
+		t = time.Until(*d.expiryTime)
+	}
 	return &t
 }
 
@@ -53,6 +172,28 @@ func (d *GetResult) ExpiryTime() time.Time {
 	return *d.expiryTime
 }
 
+// HasExpiry returns whether the document has an expiry time, allowing a document which never expires to be told
+// apart from one whose expiry was not fetched. It returns false if the result was not fetched with
+// GetOptions.WithExpiry.
+// UNCOMMITTED: This API may change in the future.
+func (d *GetResult) HasExpiry() bool {
+	return d.expiryTime != nil && !d.expiryTime.IsZero()
+}
+
+// ExpiryDuration returns the time remaining until the document expires.
+// This function will return a zero Duration if the value either was not fetched or the
+// document does not have an expiry time.
+// The server reports expiry as an absolute time so the remaining duration is calculated against the local
+// clock, any clock skew between the client and the server will be reflected in the value returned. A document
+// which is due to expire imminently may therefore return a negative duration.
+func (d *GetResult) ExpiryDuration() time.Duration {
+	if d.expiryTime == nil || d.expiryTime.IsZero() {
+		return 0
+	}
+
+	return time.Until(*d.expiryTime)
+}
+
 func (d *GetResult) fromFullProjection(ops []LookupInSpec, result *LookupInResult, fields []string) error {
 	if len(fields) == 0 {
 		// This is a special case where user specified a full doc fetch with expiration.
@@ -69,7 +210,7 @@ func (d *GetResult) fromFullProjection(ops []LookupInSpec, result *LookupInResul
 		return resultContent.err
 	}
 
-	var content map[string]interface{}
+	var content interface{}
 	err := json.Unmarshal(resultContent.data, &content)
 	if err != nil {
 		return err
@@ -77,8 +218,20 @@ func (d *GetResult) fromFullProjection(ops []LookupInSpec, result *LookupInResul
 
 	newContent := make(map[string]interface{})
 	for _, field := range fields {
+		if field == "" {
+			// The root of the document was requested so there is nothing to filter.
+			d.contents = resultContent.data
+			return nil
+		}
+
+		value, ok := projectionValue(content, field)
+		if !ok {
+			// Match the error returned when the projection is performed by the server.
+			return wrapError(ErrPathNotFound, fmt.Sprintf("projection path %s not found", field))
+		}
+
 		parts := d.pathParts(field)
-		d.set(parts, newContent, content[field])
+		d.set(parts, newContent, value)
 	}
 
 	bytes, err := json.Marshal(newContent)
@@ -223,6 +376,10 @@ func (d *GetResult) set(paths []subdocPath, content interface{}, value interface
 			// this isn't possible but the linter won't play nice without it
 			logErrorf("Failed to assert projection content to a map")
 		}
+		if existing, ok := cMap[path.path].(map[string]interface{}); ok {
+			// Another projection has already created this object, e.g. foo.bar when projecting foo.baz.
+			return d.set(paths[1:], existing, value)
+		}
 		cMap[path.path] = make(map[string]interface{})
 		return d.set(paths[1:], cMap[path.path], value)
 	}
@@ -230,10 +387,65 @@ func (d *GetResult) set(paths []subdocPath, content interface{}, value interface
 	return content
 }
 
+// projectionValue returns the value at the subdocument path within content, e.g. foo.bar[1].baz, and whether it
+// exists. Negative array indexes count back from the end of the array, as they do for subdocument operations.
+func projectionValue(content interface{}, path string) (interface{}, bool) {
+	for _, elem := range strings.Split(path, ".") {
+		name := elem
+		var indexes []string
+		if bracketIdx := strings.IndexByte(elem, '['); bracketIdx >= 0 {
+			name = elem[:bracketIdx]
+			indexes = strings.Split(strings.TrimSuffix(elem[bracketIdx+1:], "]"), "][")
+		}
+
+		if name != "" {
+			obj, ok := content.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			content, ok = obj[name]
+			if !ok {
+				return nil, false
+			}
+		}
+
+		for _, indexStr := range indexes {
+			arr, ok := content.([]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			index, err := strconv.Atoi(indexStr)
+			if err != nil {
+				return nil, false
+			}
+			if index < 0 {
+				index += len(arr)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, false
+			}
+
+			content = arr[index]
+		}
+	}
+
+	return content, true
+}
+
 // LookupInResult is the return type for LookupIn.
 type LookupInResult struct {
 	Result
-	contents []lookupInPartial
+	contents  []lookupInPartial
+	isDeleted bool
+}
+
+// IsDeleted returns whether the document has been deleted but its tombstone still exists, which can only be the case
+// when LookupInOptions.WithDeleted is set.
+// UNCOMMITTED: This API may change in the future.
+func (lir *LookupInResult) IsDeleted() bool {
+	return lir.isDeleted
 }
 
 type lookupInPartial struct {
@@ -284,6 +496,7 @@ func (lir *LookupInResult) Exists(idx uint) bool {
 type ExistsResult struct {
 	Result
 	docExists bool
+	isDeleted bool
 }
 
 // Exists returns whether or not the document exists.
@@ -291,10 +504,23 @@ func (d *ExistsResult) Exists() bool {
 	return d.docExists
 }
 
+// IsDeleted returns whether the document has been deleted but its tombstone still exists, distinguishing a
+// soft-deleted document from one which has never existed or whose tombstone has been purged. This is only reported
+// when ExistsOptions.WithDeleted is set.
+// UNCOMMITTED: This API may change in the future.
+func (d *ExistsResult) IsDeleted() bool {
+	return d.isDeleted
+}
+
 // MutationResult is the return type of any store related operations. It contains Cas and mutation tokens.
 type MutationResult struct {
 	Result
 	mt *MutationToken
+
+	contents   []byte
+	flags      uint32
+	transcoder Transcoder
+	contentErr error
 }
 
 // MutationToken returns the mutation token belonging to an operation.
@@ -302,6 +528,22 @@ func (mr MutationResult) MutationToken() *MutationToken {
 	return mr.mt
 }
 
+// Content assigns the document body written by the operation into the value pointer. The body is only available
+// when requested via the options for the operation, ReplaceOptions.ReturnDocument or MutateInOptions.ReturnDocument,
+// otherwise ErrNoResult is returned.
+// UNCOMMITTED: This API may change in the future.
+func (mr MutationResult) Content(valuePtr interface{}) error {
+	if mr.contentErr != nil {
+		return mr.contentErr
+	}
+
+	if mr.transcoder == nil {
+		return ErrNoResult
+	}
+
+	return mr.transcoder.Decode(mr.contents, mr.flags, valuePtr)
+}
+
 // MutateInResult is the return type of any mutate in related operations.
 // It contains Cas, mutation tokens and any returned content.
 type MutateInResult struct {
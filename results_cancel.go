@@ -0,0 +1,37 @@
+package gocb
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// streamCanceller allows a streaming result to be canceled from a goroutine other than the one iterating it. The
+// stream is closed exactly once, whether by canceling it or by closing the result.
+type streamCanceller struct {
+	once     sync.Once
+	canceled uint32
+}
+
+// cancel marks the stream as canceled and closes it, closing the stream is what unblocks any in progress read.
+func (sc *streamCanceller) cancel(closeFn func() error) {
+	sc.once.Do(func() {
+		atomic.StoreUint32(&sc.canceled, 1)
+		if err := closeFn(); err != nil {
+			logDebugf("Failed to close canceled result stream: %v", err)
+		}
+	})
+}
+
+// close closes the stream unless it has already been closed or canceled, returning any error from closing it.
+func (sc *streamCanceller) close(closeFn func() error) error {
+	var err error
+	sc.once.Do(func() {
+		err = closeFn()
+	})
+
+	return err
+}
+
+func (sc *streamCanceller) isCanceled() bool {
+	return atomic.LoadUint32(&sc.canceled) == 1
+}
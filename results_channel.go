@@ -0,0 +1,153 @@
+package gocb
+
+import (
+	"context"
+	"reflect"
+)
+
+// rowStreamer is the subset of a streaming result which is needed to push its rows into a channel.
+type rowStreamer interface {
+	Next() bool
+	Err() error
+	Cancel()
+	Close() error
+}
+
+// streamRowsToChannel reads every remaining row from the result, decodes each one using decode and sends it to
+// the channel held by chVal. The channel is always closed before returning. If ctx is done before all of the
+// rows have been sent then the result is canceled and the context error is returned, otherwise any error which
+// occurred on the stream is returned.
+func streamRowsToChannel(ctx context.Context, result rowStreamer, chVal reflect.Value,
+	decode func() (reflect.Value, error)) error {
+	defer chVal.Close()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Cancel the results if the context is done whilst we are waiting on the next row from the server, Cancel is
+	// safe to call concurrently with Next.
+	if ctx.Done() != nil {
+		doneCh := make(chan struct{})
+		defer close(doneCh)
+		go func() {
+			select {
+			case <-ctx.Done():
+				result.Cancel()
+			case <-doneCh:
+			}
+		}()
+	}
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectSend, Chan: chVal},
+	}
+
+	for result.Next() {
+		row, err := decode()
+		if err != nil {
+			result.Cancel()
+			return err
+		}
+
+		cases[1].Send = row
+		if chosen, _, _ := reflect.Select(cases); chosen == 0 {
+			result.Cancel()
+			return ctx.Err()
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		result.Cancel()
+		return err
+	}
+
+	if err := result.Err(); err != nil {
+		_ = result.Close()
+		return err
+	}
+
+	return result.Close()
+}
+
+// valueChannel validates that ch is a channel which rows can be sent to, returning the channel along with the
+// type which each row should be decoded into and whether the channel carries pointers to that type.
+func valueChannel(ch interface{}) (reflect.Value, reflect.Type, bool, error) {
+	chVal := reflect.ValueOf(ch)
+	if chVal.Kind() != reflect.Chan || chVal.IsNil() {
+		return reflect.Value{}, nil, false, makeInvalidArgumentsError("ch must be a non-nil channel")
+	}
+	if chVal.Type().ChanDir()&reflect.SendDir == 0 {
+		return reflect.Value{}, nil, false, makeInvalidArgumentsError("ch must be a channel which can be sent to")
+	}
+
+	elemType := chVal.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		return chVal, elemType.Elem(), true, nil
+	}
+
+	return chVal, elemType, false, nil
+}
+
+// streamDecodedRows sends every remaining row of result to ch, decoding each row using row.
+func streamDecodedRows(ctx context.Context, result rowStreamer, ch interface{},
+	row func(valuePtr interface{}) error) error {
+	chVal, rowType, isPtr, err := valueChannel(ch)
+	if err != nil {
+		return err
+	}
+
+	return streamRowsToChannel(ctx, result, chVal, func() (reflect.Value, error) {
+		valuePtr := reflect.New(rowType)
+		if err := row(valuePtr.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+
+		if isPtr {
+			return valuePtr, nil
+		}
+		return valuePtr.Elem(), nil
+	})
+}
+
+// RowsToChannel decodes each remaining row of the results and sends it to ch, which must be a channel of either
+// a type which rows can be decoded into or a pointer to such a type, e.g. chan map[string]interface{} or
+// chan *MyRow. The channel is closed once all rows have been sent or reading stops.
+// RowsToChannel blocks until all rows have been sent, so it is usually run in its own goroutine. The results are
+// closed on completion and any error which occurred on the stream is returned. If ctx is done before all rows
+// have been sent then the query is canceled and the context error is returned.
+// RowsToChannel consumes the same stream as Next and must not be used alongside it.
+// UNCOMMITTED: This API may change in the future.
+func (r *QueryResult) RowsToChannel(ctx context.Context, ch interface{}) error {
+	return streamDecodedRows(ctx, r, ch, r.Row)
+}
+
+// RowsToChannel decodes each remaining row of the results and sends it to ch, which must be a channel of either
+// a type which rows can be decoded into or a pointer to such a type. The channel is closed once all rows have
+// been sent or reading stops.
+// RowsToChannel blocks until all rows have been sent. The results are closed on completion and any error which
+// occurred on the stream is returned. If ctx is done before all rows have been sent then the query is canceled
+// and the context error is returned.
+// RowsToChannel consumes the same stream as Next and must not be used alongside it.
+// UNCOMMITTED: This API may change in the future.
+func (r *AnalyticsResult) RowsToChannel(ctx context.Context, ch interface{}) error {
+	return streamDecodedRows(ctx, r, ch, r.Row)
+}
+
+// RowsToChannel sends each remaining row of the results to ch, closing the channel once all rows have been sent
+// or reading stops.
+// RowsToChannel blocks until all rows have been sent. The results are closed on completion and any error which
+// occurred on the stream is returned. If ctx is done before all rows have been sent then the query is canceled
+// and the context error is returned.
+// RowsToChannel consumes the same stream as Next and must not be used alongside it.
+// UNCOMMITTED: This API may change in the future.
+func (r *SearchResult) RowsToChannel(ctx context.Context, ch chan<- SearchRow) error {
+	if ch == nil {
+		return makeInvalidArgumentsError("ch must be a non-nil channel")
+	}
+
+	return streamRowsToChannel(ctx, r, reflect.ValueOf(ch), func() (reflect.Value, error) {
+		return reflect.ValueOf(r.Row()), nil
+	})
+}
@@ -0,0 +1,84 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+)
+
+func (suite *UnitTestSuite) TestQueryResultRowsToChannel() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	newReader := func(rowsErr error) *mockQueryRowReader {
+		return &mockQueryRowReader{
+			Dataset: dataset.Results,
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:    suite.mustConvertToBytes(dataset.jsonQueryResponse),
+				RowsErr: rowsErr,
+				Suite:   suite,
+			},
+		}
+	}
+
+	suite.Run("all rows", func() {
+		result := newQueryResult(newReader(nil))
+
+		ch := make(chan *testBreweryDocument)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- result.RowsToChannel(context.Background(), ch)
+		}()
+
+		var breweries []testBreweryDocument
+		for doc := range ch {
+			breweries = append(breweries, *doc)
+		}
+
+		suite.Require().Nil(<-errCh)
+		suite.Assert().Equal(dataset.Results, breweries)
+	})
+
+	suite.Run("stream error", func() {
+		result := newQueryResult(newReader(errors.New("some error")))
+
+		ch := make(chan testBreweryDocument, len(dataset.Results))
+		err := result.RowsToChannel(context.Background(), ch)
+		suite.Require().NotNil(err)
+
+		var count int
+		for range ch {
+			count++
+		}
+		suite.Assert().Equal(len(dataset.Results), count)
+	})
+
+	suite.Run("context canceled", func() {
+		result := newQueryResult(newReader(nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan testBreweryDocument)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- result.RowsToChannel(ctx, ch)
+		}()
+
+		<-ch
+		cancel()
+
+		suite.Assert().True(errors.Is(<-errCh, context.Canceled))
+		for range ch {
+		}
+		suite.Assert().False(result.Next())
+	})
+
+	suite.Run("invalid channel", func() {
+		result := newQueryResult(newReader(nil))
+
+		err := result.RowsToChannel(context.Background(), make(<-chan testBreweryDocument))
+		suite.Assert().True(errors.Is(err, ErrInvalidArgument))
+
+		err = result.RowsToChannel(context.Background(), []testBreweryDocument{})
+		suite.Assert().True(errors.Is(err, ErrInvalidArgument))
+	})
+}
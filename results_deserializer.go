@@ -0,0 +1,52 @@
+package gocb
+
+import (
+	"encoding/json"
+)
+
+// Deserializer is used to decode the rows of query and analytics results, and the fields of search rows. It can
+// be implemented to decode rows using a different codec, or into pooled values to avoid allocating for each row.
+// UNCOMMITTED: This API may change in the future.
+type Deserializer interface {
+	Deserialize(data []byte, out interface{}) error
+}
+
+// JSONDeserializer implements the default deserialization behavior, decoding using encoding/json.
+// UNCOMMITTED: This API may change in the future.
+type JSONDeserializer struct{}
+
+// NewJSONDeserializer returns a new JSONDeserializer.
+// UNCOMMITTED: This API may change in the future.
+func NewJSONDeserializer() *JSONDeserializer {
+	return &JSONDeserializer{}
+}
+
+// Deserialize decodes the JSON data into out.
+func (d *JSONDeserializer) Deserialize(data []byte, out interface{}) error {
+	return json.Unmarshal(data, out)
+}
+
+// deserializeRow decodes a row using the deserializer, falling back to JSONDeserializer when none was specified.
+// A *json.RawMessage always receives the row bytes as they are, without being decoded.
+func deserializeRow(deserializer Deserializer, data []byte, out interface{}) error {
+	if bytesPtr, ok := out.(*json.RawMessage); ok {
+		*bytesPtr = data
+		return nil
+	}
+
+	if deserializer == nil {
+		return json.Unmarshal(data, out)
+	}
+
+	return deserializer.Deserialize(data, out)
+}
+
+// resultDeserializer returns the deserializer which was specified for a request, falling back to the deserializer
+// of the cluster.
+func resultDeserializer(deserializer, fallback Deserializer) Deserializer {
+	if deserializer != nil {
+		return deserializer
+	}
+
+	return fallback
+}
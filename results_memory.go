@@ -0,0 +1,164 @@
+package gocb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ResultMemoryConfig specifies options for limiting the memory used by the rows of query and analytics results.
+// UNCOMMITTED: This API may change in the future.
+type ResultMemoryConfig struct {
+	// MaxBytes is the maximum total size of the rows which may be held at once across all of the open query and
+	// analytics results of the cluster, including those of scope level queries. Each result holds the row most
+	// recently read from it using Next, which is counted against the limit until the next row is read, or until the
+	// result is closed, canceled, or Next has returned false. Rows must not be retained after that point if the limit
+	// is to reflect the memory in use. Rows read using One or using the Raw results are not counted. A value of 0
+	// means no limit.
+	MaxBytes uint64
+
+	// BlockWhenFull causes Next to wait for other results to release memory when reading a row would exceed
+	// MaxBytes, rather than failing. The wait ends when the Context or the timeout of the query expires, or when the
+	// result is canceled. A row which is larger than MaxBytes fails regardless, as it can never fit.
+	// When BlockWhenFull is not set, or the row cannot fit, the result is canceled, Next returns false and Err
+	// returns ErrResultMemoryLimitExceeded.
+	BlockWhenFull bool
+}
+
+// resultMemoryLimiter tracks the memory held by the rows of all of the open results of a cluster.
+type resultMemoryLimiter struct {
+	maxBytes      uint64
+	blockWhenFull bool
+
+	lock sync.Mutex
+	used uint64
+
+	// released is closed, and replaced, whenever memory is released so that waiting results can check again.
+	released chan struct{}
+}
+
+func newResultMemoryLimiter(config ResultMemoryConfig) *resultMemoryLimiter {
+	if config.MaxBytes == 0 {
+		return nil
+	}
+
+	return &resultMemoryLimiter{
+		maxBytes:      config.MaxBytes,
+		blockWhenFull: config.BlockWhenFull,
+		released:      make(chan struct{}),
+	}
+}
+
+// releaseLocked returns memory to the limiter and wakes any results which are waiting for it. The limiter lock must
+// be held.
+func (l *resultMemoryLimiter) releaseLocked(size uint64) {
+	l.used -= size
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// resultMemoryReservation is the memory held by the current row of a single result. The zero value, which has no
+// limiter, never fails to reserve memory.
+type resultMemoryReservation struct {
+	limiter *resultMemoryLimiter
+
+	// ctx and deadline bound how long reserve waits for memory when the limiter blocks when full.
+	ctx      context.Context
+	deadline time.Time
+
+	held     uint64
+	closed   bool
+	closedCh chan struct{}
+}
+
+// reserve releases the memory held for the previous row of the result and reserves size bytes for the next one.
+func (r *resultMemoryReservation) reserve(size int) error {
+	limiter := r.limiter
+	if limiter == nil {
+		return nil
+	}
+
+	var timeoutCh <-chan time.Time
+	if !r.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(r.deadline))
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	var doneCh <-chan struct{}
+	if r.ctx != nil {
+		doneCh = r.ctx.Done()
+	}
+
+	limiter.lock.Lock()
+	if r.held > 0 {
+		limiter.releaseLocked(r.held)
+		r.held = 0
+	}
+	if r.closedCh == nil {
+		r.closedCh = make(chan struct{})
+	}
+
+	for {
+		if r.closed {
+			limiter.lock.Unlock()
+			return ErrRequestCanceled
+		}
+
+		if limiter.used+uint64(size) <= limiter.maxBytes {
+			limiter.used += uint64(size)
+			r.held = uint64(size)
+			limiter.lock.Unlock()
+			return nil
+		}
+
+		if !limiter.blockWhenFull || uint64(size) > limiter.maxBytes {
+			limiter.lock.Unlock()
+			return wrapError(ErrResultMemoryLimitExceeded,
+				fmt.Sprintf("reading a row of %d bytes would exceed the limit of %d bytes", size, limiter.maxBytes))
+		}
+
+		releasedCh := limiter.released
+		closedCh := r.closedCh
+		limiter.lock.Unlock()
+
+		select {
+		case <-releasedCh:
+		case <-closedCh:
+		case <-doneCh:
+			return maybeWrapContextErr(r.ctx, wrapError(ErrRequestCanceled, "waiting for memory to read the next row"))
+		case <-timeoutCh:
+			return wrapError(ErrTimeout, "timed out waiting for memory to read the next row")
+		}
+
+		limiter.lock.Lock()
+	}
+}
+
+// release returns all of the memory held by the result to the limiter, after which no further memory can be
+// reserved for the result.
+func (r *resultMemoryReservation) release() {
+	limiter := r.limiter
+	if limiter == nil {
+		return
+	}
+
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	if r.closed {
+		return
+	}
+	r.closed = true
+
+	// This wakes a Next on this result which is blocked waiting for memory, so that it sees the result closed.
+	if r.closedCh != nil {
+		close(r.closedCh)
+	}
+
+	if r.held > 0 {
+		limiter.releaseLocked(r.held)
+		r.held = 0
+	}
+}
@@ -0,0 +1,133 @@
+package gocb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+func (suite *UnitTestSuite) TestResultMemoryLimiter() {
+	suite.Assert().Nil(newResultMemoryLimiter(ResultMemoryConfig{}))
+
+	var unlimited resultMemoryReservation
+	suite.Assert().Nil(unlimited.reserve(1 << 30))
+	unlimited.release()
+
+	limiter := newResultMemoryLimiter(ResultMemoryConfig{MaxBytes: 100})
+
+	first := resultMemoryReservation{limiter: limiter}
+	second := resultMemoryReservation{limiter: limiter}
+
+	suite.Require().Nil(first.reserve(60))
+	suite.Require().Nil(second.reserve(40))
+	suite.Assert().Equal(uint64(100), limiter.used)
+
+	// Only the current row is held, so the previous row of the result is released before reserving the next one.
+	err := first.reserve(61)
+	suite.Assert().True(errors.Is(err, ErrResultMemoryLimitExceeded))
+	suite.Assert().Equal(uint64(40), limiter.used)
+	suite.Require().Nil(first.reserve(60))
+
+	second.release()
+	suite.Assert().Equal(uint64(60), limiter.used)
+	suite.Assert().True(errors.Is(second.reserve(1), ErrRequestCanceled))
+
+	first.release()
+	suite.Assert().Equal(uint64(0), limiter.used)
+}
+
+func (suite *UnitTestSuite) TestResultMemoryLimiterBlocking() {
+	limiter := newResultMemoryLimiter(ResultMemoryConfig{MaxBytes: 100, BlockWhenFull: true})
+
+	first := resultMemoryReservation{limiter: limiter}
+	second := resultMemoryReservation{limiter: limiter}
+
+	suite.Require().Nil(first.reserve(60))
+
+	// A row which can never fit does not wait.
+	suite.Assert().True(errors.Is(first.reserve(101), ErrResultMemoryLimitExceeded))
+	suite.Require().Nil(first.reserve(60))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- second.reserve(50)
+	}()
+
+	select {
+	case err := <-errCh:
+		suite.T().Fatalf("Expected reserve to block but returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Reading a smaller row releases the larger one held before it, so the blocked result can continue.
+	suite.Require().Nil(first.reserve(10))
+
+	select {
+	case err := <-errCh:
+		suite.Assert().Nil(err, err)
+	case <-time.After(5 * time.Second):
+		suite.T().Fatalf("Expected reserve to be unblocked")
+	}
+	suite.Assert().Equal(uint64(60), limiter.used)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := resultMemoryReservation{limiter: limiter, ctx: ctx}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	err := waiting.reserve(50)
+	suite.Assert().True(errors.Is(err, ErrRequestCanceled), "expected canceled but was %v", err)
+	suite.Assert().True(errors.Is(err, context.Canceled), "expected context canceled but was %v", err)
+
+	waiting = resultMemoryReservation{limiter: limiter, deadline: time.Now().Add(50 * time.Millisecond)}
+	err = waiting.reserve(50)
+	suite.Assert().True(errors.Is(err, ErrTimeout), "expected timeout but was %v", err)
+	suite.Assert().Equal(uint64(60), limiter.used)
+}
+
+func (suite *UnitTestSuite) TestQueryResultsMemoryLimit() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	newResult := func() *QueryResult {
+		return newQueryResult(&mockQueryRowReader{
+			Dataset: dataset.Results,
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+				Suite: suite,
+			},
+		})
+	}
+
+	var maxRowSize int
+	for _, row := range dataset.Results {
+		if size := len(suite.mustConvertToBytes(row)); size > maxRowSize {
+			maxRowSize = size
+		}
+	}
+	limiter := newResultMemoryLimiter(ResultMemoryConfig{MaxBytes: uint64(maxRowSize)})
+
+	// Only the current row counts against the limit, so every row can be read even though together they exceed it.
+	result := newResult()
+	result.memory.limiter = limiter
+	var numRows int
+	for result.Next() {
+		numRows++
+	}
+	suite.Require().Nil(result.Err(), result.Err())
+	suite.Assert().Len(dataset.Results, numRows)
+	suite.Assert().Equal(uint64(0), limiter.used)
+	suite.Assert().Nil(result.Close())
+
+	other := resultMemoryReservation{limiter: limiter}
+	suite.Require().Nil(other.reserve(maxRowSize))
+
+	result = newResult()
+	result.memory.limiter = limiter
+	suite.Assert().False(result.Next())
+	suite.Assert().True(errors.Is(result.Err(), ErrResultMemoryLimitExceeded))
+	suite.Assert().Equal(uint64(maxRowSize), limiter.used)
+	suite.Assert().Nil(result.Close())
+
+	other.release()
+	suite.Assert().Equal(uint64(0), limiter.used)
+}
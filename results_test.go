@@ -21,11 +21,22 @@ func (suite *UnitTestSuite) TestGetResultCas() {
 	}
 }
 
+func (suite *UnitTestSuite) TestGetResultFlags() {
+	res := GetResult{
+		flags: 2 << 24,
+	}
+
+	suite.Assert().Equal(uint32(2<<24), res.Flags())
+	dataType, _ := gocbcore.DecodeCommonFlags(res.Flags())
+	suite.Assert().Equal(gocbcore.JSONType, dataType)
+}
+
 func (suite *UnitTestSuite) TestGetResultExpiry() {
 	res := GetResult{}
 
 	suite.Require().Nil(res.Expiry())
 	suite.Require().Zero(res.ExpiryTime())
+	suite.Require().Zero(res.ExpiryDuration())
 
 	expiry := 32 * time.Second
 	expiryTime := time.Now().Add(expiry)
@@ -36,6 +47,19 @@ func (suite *UnitTestSuite) TestGetResultExpiry() {
 	}
 
 	suite.Assert().Equal(expiryTime, res.ExpiryTime())
+	suite.Assert().InDelta(expiry, res.ExpiryDuration(), float64(1*time.Second))
+}
+
+func (suite *UnitTestSuite) TestGetResultNoExpiry() {
+	res := GetResult{
+		expiryTime: &time.Time{},
+	}
+
+	if suite.Assert().NotNil(res.Expiry()) {
+		suite.Assert().Zero(*res.Expiry())
+	}
+	suite.Assert().Zero(res.ExpiryTime())
+	suite.Assert().Zero(res.ExpiryDuration())
 }
 
 func (suite *UnitTestSuite) TestGetResultContent() {
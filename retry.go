@@ -1,6 +1,9 @@
 package gocb
 
 import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/couchbase/gocbcore/v10"
@@ -118,8 +121,54 @@ var (
 	// QueryErrorRetryable indicates that the operation is retryable as indicated by the query engine.
 	// Uncommitted: This API may change in the future.
 	QueryErrorRetryable = RetryReason(gocbcore.QueryErrorRetryable)
+
+	// KVDurabilityImpossibleRetryReason indicates that a durable mutation failed with ErrDurabilityImpossible because
+	// the durability level cannot currently be met, e.g. as not enough replicas are online during a failover. The
+	// mutation was not applied, so it is safe to retry, but it may be a long time before the replicas are available
+	// again and retrying holds up the caller for as long as the strategy allows, up to the operation timeout.
+	// This reason is only given to a BestEffortRetryStrategy with RetryDurabilityImpossible set, other strategies
+	// are never asked to retry it.
+	// UNCOMMITTED: This API may change in the future.
+	KVDurabilityImpossibleRetryReason = RetryReason(&durabilityImpossibleRetryReason{})
 )
 
+type durabilityImpossibleRetryReason struct{}
+
+func (rr *durabilityImpossibleRetryReason) AllowsNonIdempotentRetry() bool {
+	return true
+}
+
+func (rr *durabilityImpossibleRetryReason) AlwaysRetry() bool {
+	return false
+}
+
+func (rr *durabilityImpossibleRetryReason) Description() string {
+	return "KV_DURABILITY_IMPOSSIBLE"
+}
+
+// durabilityImpossibleRetryRequest is the request given to the retry strategy when a durable mutation fails with
+// ErrDurabilityImpossible, as the retrying is performed by the SDK rather than gocbcore.
+type durabilityImpossibleRetryRequest struct {
+	attempts   uint32
+	identifier string
+}
+
+func (req *durabilityImpossibleRetryRequest) RetryAttempts() uint32 {
+	return req.attempts
+}
+
+func (req *durabilityImpossibleRetryRequest) Identifier() string {
+	return req.identifier
+}
+
+func (req *durabilityImpossibleRetryRequest) Idempotent() bool {
+	return false
+}
+
+func (req *durabilityImpossibleRetryRequest) RetryReasons() []RetryReason {
+	return []RetryReason{KVDurabilityImpossibleRetryReason}
+}
+
 // RetryAction is used by a RetryStrategy to calculate the duration to wait before retrying an operation.
 // Returning a value of 0 indicates to not retry.
 type RetryAction interface {
@@ -146,6 +195,10 @@ func (ra *WithDurationRetryAction) Duration() time.Duration {
 }
 
 // RetryStrategy is to determine if an operation should be retried, and if so how long to wait before retrying.
+// The strategy set in ClusterOptions is used by every operation unless the options of the operation specify their own
+// RetryStrategy, which overrides it for that operation only. This allows, for example, idempotent reads to be retried
+// more aggressively than mutations. The reason passed to RetryAfter can be compared against the exported RetryReason
+// values, such as KVLockedRetryReason, to decide how each failure is handled.
 type RetryStrategy interface {
 	RetryAfter(req RetryRequest, reason RetryReason) RetryAction
 }
@@ -158,6 +211,9 @@ func newRetryStrategyWrapper(strategy RetryStrategy) *retryStrategyWrapper {
 
 type retryStrategyWrapper struct {
 	wrapped RetryStrategy
+
+	// recorder, if set, records each retry which the wrapped strategy decides to make.
+	recorder *retryRecorder
 }
 
 // RetryAfter calculates and returns a RetryAction describing how long to wait before retrying an operation.
@@ -166,20 +222,131 @@ func (rs *retryStrategyWrapper) RetryAfter(req gocbcore.RetryRequest, reason goc
 		req: req,
 	}
 	wrappedAction := rs.wrapped.RetryAfter(wreq, RetryReason(reason))
+	if rs.recorder != nil && wrappedAction != nil && wrappedAction.Duration() > 0 {
+		rs.recorder.record(wreq, RetryReason(reason))
+	}
 	return gocbcore.RetryAction(wrappedAction)
 }
 
+// retryRecord describes the retries made by a single operation.
+type retryRecord struct {
+	attempts uint32
+	reasons  []RetryReason
+}
+
+// retryCountingRequest is implemented by the pending ops of gocbcore requests which count their own retries.
+type retryCountingRequest interface {
+	RetryAttempts() uint32
+	RetryReasons() []gocbcore.RetryReason
+}
+
+// retryRecorder records the retries of a single operation as they are decided by its retry strategy.
+type retryRecorder struct {
+	lock    sync.Mutex
+	retries retryRecord
+}
+
+func (r *retryRecorder) record(req RetryRequest, reason RetryReason) {
+	// The retry being decided upon has not yet been counted by the request. The reasons are copied so that appending
+	// to them cannot write into a slice owned by the request.
+	reasons := append([]RetryReason(nil), req.RetryReasons()...)
+	found := false
+	for _, existing := range reasons {
+		if existing == reason {
+			found = true
+			break
+		}
+	}
+	if !found {
+		reasons = append(reasons, reason)
+	}
+
+	r.lock.Lock()
+	r.retries = retryRecord{
+		attempts: req.RetryAttempts() + 1,
+		reasons:  reasons,
+	}
+	r.lock.Unlock()
+}
+
+// recordCompleted records the retries counted by a request which has completed. Retries which are always made, such
+// as for KVNotMyVBucketRetryReason, never consult the retry strategy and so are only seen here.
+func (r *retryRecorder) recordCompleted(req retryCountingRequest) {
+	attempts := req.RetryAttempts()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if attempts < r.retries.attempts {
+		return
+	}
+
+	r.retries = retryRecord{
+		attempts: attempts,
+		reasons:  translateCoreRetryReasons(req.RetryReasons()),
+	}
+}
+
+func (r *retryRecorder) get() retryRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.retries
+}
+
 // BackoffCalculator defines how backoff durations will be calculated by the retry API.
 type BackoffCalculator func(retryAttempts uint32) time.Duration
 
+// ExponentialBackoff returns a BackoffCalculator which starts at min and multiplies the backoff by backoffFactor for
+// each retry attempt, up to max. A backoffFactor of 0 defaults to 2.
+func ExponentialBackoff(min, max time.Duration, backoffFactor float64) BackoffCalculator {
+	return BackoffCalculator(gocbcore.ExponentialBackoff(min, max, backoffFactor))
+}
+
+// ExponentialBackoffWithJitter returns a BackoffCalculator which picks a random duration between min and the
+// backoff calculated by ExponentialBackoff. Spreading out the retries of many requests which failed at the same
+// time, such as during a rebalance, avoids them all being retried in lockstep.
+func ExponentialBackoffWithJitter(min, max time.Duration, backoffFactor float64) BackoffCalculator {
+	calculator := ExponentialBackoff(min, max, backoffFactor)
+	return func(retryAttempts uint32) time.Duration {
+		backoff := calculator(retryAttempts)
+		if backoff <= min {
+			return backoff
+		}
+
+		return min + time.Duration(rand.Int63n(int64(backoff-min)+1))
+	}
+}
+
 // BestEffortRetryStrategy represents a strategy that will keep retrying until it succeeds (or the caller times out
 // the request).
 type BestEffortRetryStrategy struct {
 	BackoffCalculator BackoffCalculator
+
+	// MaxRetryDuration caps the total time that a request will spend backing off between retries, once the next
+	// retry would take the total beyond this the request is no longer retried and fails with the error which caused
+	// it to be retried. This allows a request to fail fast whilst leaving some of its timeout available for a fallback,
+	// such as reading from a replica. The operation timeout still applies, whichever of the two is reached first ends
+	// the retrying. A value of 0 means that retries are only bounded by the operation timeout.
+	// Only the time spent waiting between retries is counted, not the time spent dispatching each attempt, and retry
+	// reasons which always retry, such as KVNotMyVBucketRetryReason, are retried regardless of this value.
+	// The total is an estimate: the time already spent backing off is not recorded by the request, so it is
+	// recalculated by calling BackoffCalculator for each earlier attempt. With a calculator which adds jitter, such as
+	// ExponentialBackoffWithJitter, the recalculated durations differ from those actually waited, and so the request
+	// may stop retrying somewhat before or after MaxRetryDuration has really been spent.
+	// UNCOMMITTED: This API may change in the future.
+	MaxRetryDuration time.Duration
+
+	// RetryDurabilityImpossible causes durable mutations which fail with ErrDurabilityImpossible to be retried, with
+	// the same backoff as other retries, until they succeed or time out. By default they fail immediately.
+	// See KVDurabilityImpossibleRetryReason for the risks of retrying.
+	// UNCOMMITTED: This API may change in the future.
+	RetryDurabilityImpossible bool
 }
 
 // NewBestEffortRetryStrategy returns a new BestEffortRetryStrategy which will use the supplied calculator function
-// to calculate retry durations. If calculator is nil then a controlled backoff will be used.
+// to calculate retry durations, e.g. one created using ExponentialBackoffWithJitter. If calculator is nil then a
+// controlled backoff will be used.
 func NewBestEffortRetryStrategy(calculator BackoffCalculator) *BestEffortRetryStrategy {
 	if calculator == nil {
 		calculator = BackoffCalculator(gocbcore.ExponentialBackoff(1*time.Millisecond, 500*time.Millisecond, 2))
@@ -190,9 +357,50 @@ func NewBestEffortRetryStrategy(calculator BackoffCalculator) *BestEffortRetrySt
 
 // RetryAfter calculates and returns a RetryAction describing how long to wait before retrying an operation.
 func (rs *BestEffortRetryStrategy) RetryAfter(req RetryRequest, reason RetryReason) RetryAction {
-	if req.Idempotent() || reason.AllowsNonIdempotentRetry() {
-		return &WithDurationRetryAction{WithDuration: rs.BackoffCalculator(req.RetryAttempts())}
+	if reason == KVDurabilityImpossibleRetryReason && !rs.RetryDurabilityImpossible {
+		return &NoRetryRetryAction{}
+	}
+
+	if !req.Idempotent() && !reason.AllowsNonIdempotentRetry() {
+		return &NoRetryRetryAction{}
+	}
+
+	retryAttempts := req.RetryAttempts()
+	backoff := rs.BackoffCalculator(retryAttempts)
+	if rs.MaxRetryDuration > 0 {
+		// The backoff of earlier attempts is not recorded anywhere, so this is an estimate when the calculator jitters.
+		total := backoff
+		for i := uint32(0); i < retryAttempts; i++ {
+			total += rs.BackoffCalculator(i)
+		}
+
+		if total > rs.MaxRetryDuration {
+			return &NoRetryRetryAction{}
+		}
 	}
 
-	return &NoRetryRetryAction{}
+	return &WithDurationRetryAction{WithDuration: backoff}
+}
+
+// nodeUnavailableRetryStrategy stops retrying a request as soon as it fails because the node it was sent to cannot be
+// reached, recording that it did so, so that the request can instead be sent to another node. Any other reason is
+// passed to the wrapped strategy.
+type nodeUnavailableRetryStrategy struct {
+	wrapped     RetryStrategy
+	unavailable uint32
+}
+
+func (rs *nodeUnavailableRetryStrategy) RetryAfter(req RetryRequest, reason RetryReason) RetryAction {
+	switch reason {
+	case NodeNotAvailableRetryReason, SocketNotAvailableRetryReason, ServiceNotAvailableRetryReason,
+		SocketCloseInFlightRetryReason, CircuitBreakerOpenRetryReason:
+		atomic.StoreUint32(&rs.unavailable, 1)
+		return &NoRetryRetryAction{}
+	}
+
+	return rs.wrapped.RetryAfter(req, reason)
+}
+
+func (rs *nodeUnavailableRetryStrategy) nodeUnavailable() bool {
+	return atomic.LoadUint32(&rs.unavailable) == 1
 }
@@ -146,6 +146,22 @@ func (suite *UnitTestSuite) TestBestEffortRetryStrategy_RetryAfterDefaultCalcula
 	}
 }
 
+func (suite *UnitTestSuite) TestBestEffortRetryStrategy_RetryAfterMaxRetryDuration() {
+	strategy := NewBestEffortRetryStrategy(mockBackoffCalculator)
+	strategy.MaxRetryDuration = 10 * time.Millisecond
+
+	// 0+1+2+3 milliseconds already spent backing off, plus 4 for this retry.
+	action := strategy.RetryAfter(&mockRetryRequest{attempts: 4}, RetryReason(gocbcore.KVLockedRetryReason))
+	if action.Duration() != 4*time.Millisecond {
+		suite.T().Fatalf("Expected duration to be %d but was %d", 4*time.Millisecond, action.Duration())
+	}
+
+	action = strategy.RetryAfter(&mockRetryRequest{attempts: 5}, RetryReason(gocbcore.KVLockedRetryReason))
+	if action.Duration() != 0 {
+		suite.T().Fatalf("Expected duration to be %d but was %d", 0, action.Duration())
+	}
+}
+
 func (suite *UnitTestSuite) TestFailFastRetryStrategy_RetryAfterNoRetry() {
 	strategy := newFailFastRetryStrategy()
 	action := strategy.RetryAfter(&mockRetryRequest{}, RetryReason(gocbcore.UnknownRetryReason))
@@ -169,3 +185,24 @@ func (suite *UnitTestSuite) TestFailFastRetryStrategy_RetryAfterAllowsNonIdempot
 		suite.T().Fatalf("Expected duration to be %d but was %d", 0, action.Duration())
 	}
 }
+
+func (suite *UnitTestSuite) TestExponentialBackoffWithJitter() {
+	min := 10 * time.Millisecond
+	max := 500 * time.Millisecond
+	exponential := ExponentialBackoff(min, max, 2)
+	jittered := ExponentialBackoffWithJitter(min, max, 2)
+
+	for attempt := uint32(0); attempt < 10; attempt++ {
+		upper := exponential(attempt)
+		for i := 0; i < 20; i++ {
+			backoff := jittered(attempt)
+			suite.Assert().GreaterOrEqual(int64(backoff), int64(min))
+			suite.Assert().LessOrEqual(int64(backoff), int64(upper))
+		}
+	}
+
+	strategy := NewBestEffortRetryStrategy(jittered)
+	action := strategy.RetryAfter(&mockRetryRequest{attempts: 3, idempotent: true}, KVLockedRetryReason)
+	suite.Assert().LessOrEqual(int64(action.Duration()), int64(exponential(3)))
+	suite.Assert().GreaterOrEqual(int64(action.Duration()), int64(min))
+}
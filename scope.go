@@ -8,15 +8,22 @@ type Scope struct {
 	timeoutsConfig TimeoutsConfig
 
 	transcoder           Transcoder
+	deserializer         Deserializer
 	retryStrategyWrapper *retryStrategyWrapper
 	tracer               RequestTracer
 	meter                *meterWrapper
+	searchCapabilities   *searchCapabilities
+
+	preparedStatementCache *PreparedStatementCache
+	resultMemoryLimiter    *resultMemoryLimiter
+	activeQueries          *activeQueryTracker
 
 	useMutationTokens bool
 
 	getKvProvider        func() (kvProvider, error)
 	getQueryProvider     func() (queryProvider, error)
 	getAnalyticsProvider func() (analyticsProvider, error)
+	getSearchProvider    func() (searchProvider, error)
 }
 
 func newScope(bucket *Bucket, scopeName string) *Scope {
@@ -27,15 +34,22 @@ func newScope(bucket *Bucket, scopeName string) *Scope {
 		timeoutsConfig: bucket.timeoutsConfig,
 
 		transcoder:           bucket.transcoder,
+		deserializer:         bucket.deserializer,
 		retryStrategyWrapper: bucket.retryStrategyWrapper,
 		tracer:               bucket.tracer,
 		meter:                bucket.meter,
+		searchCapabilities:   bucket.searchCapabilities,
+
+		preparedStatementCache: bucket.preparedStatementCache,
+		resultMemoryLimiter:    bucket.resultMemoryLimiter,
+		activeQueries:          bucket.activeQueries,
 
 		useMutationTokens: bucket.useMutationTokens,
 
 		getKvProvider:        bucket.getKvProvider,
 		getQueryProvider:     bucket.getQueryProvider,
 		getAnalyticsProvider: bucket.getAnalyticsProvider,
+		getSearchProvider:    bucket.getSearchProvider,
 	}
 }
 
@@ -50,7 +64,27 @@ func (s *Scope) BucketName() string {
 	return s.bucket.Name()
 }
 
+func (s *Scope) meterKeyspace() meterKeyspace {
+	return meterKeyspace{
+		bucket: s.BucketName(),
+		scope:  s.Name(),
+	}
+}
+
 // Collection returns an instance of a collection.
 func (s *Scope) Collection(collectionName string) *Collection {
 	return newCollection(s, collectionName)
 }
+
+// CollectionWithOptions returns an instance of a collection, configured using the provided options.
+// UNCOMMITTED: This API may change in the future.
+func (s *Scope) CollectionWithOptions(collectionName string, opts *CollectionOptions) *Collection {
+	if opts == nil {
+		opts = &CollectionOptions{}
+	}
+
+	collection := newCollection(s, collectionName)
+	collection.defaultExpiry = opts.DefaultExpiry
+
+	return collection
+}
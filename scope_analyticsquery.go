@@ -1,18 +1,17 @@
 package gocb
 
-import (
-	"fmt"
-	"time"
-)
+import "time"
 
 // AnalyticsQuery executes the analytics query statement on the server, constraining the query to the bucket and scope.
+// Unqualified dataset names within the statement are resolved within the scope unless AnalyticsOptions.QueryContext is
+// set.
 func (s *Scope) AnalyticsQuery(statement string, opts *AnalyticsOptions) (*AnalyticsResult, error) {
 	if opts == nil {
 		opts = &AnalyticsOptions{}
 	}
 
 	start := time.Now()
-	defer s.meter.ValueRecord(meterValueServiceAnalytics, "analytics", start)
+	defer s.meter.KeyspaceValueRecord(meterValueServiceAnalytics, "analytics", "", s.meterKeyspace(), start)
 
 	span := createSpan(s.tracer, opts.ParentSpan, "analytics", "analytics")
 	span.SetAttribute("db.statement", statement)
@@ -46,7 +45,9 @@ func (s *Scope) AnalyticsQuery(statement string, opts *AnalyticsOptions) (*Analy
 	}
 
 	queryOpts["statement"] = statement
-	queryOpts["query_context"] = fmt.Sprintf("default:`%s`.`%s`", s.BucketName(), s.Name())
+	if _, ok := queryOpts["query_context"]; !ok {
+		queryOpts["query_context"] = scopeQueryContext(s.BucketName(), s.Name())
+	}
 
 	provider, err := s.getAnalyticsProvider()
 	if err != nil {
@@ -57,6 +58,23 @@ func (s *Scope) AnalyticsQuery(statement string, opts *AnalyticsOptions) (*Analy
 		}
 	}
 
-	return execAnalyticsQuery(opts.Context, span, queryOpts, priorityInt, deadline, retryStrategy, provider, s.tracer,
+	res, err := execAnalyticsQuery(opts.Context, span, queryOpts, priorityInt, deadline, retryStrategy, provider, s.tracer,
 		opts.Internal.User)
+	if err != nil {
+		return nil, err
+	}
+	res.memory.limiter = s.resultMemoryLimiter
+	res.memory.ctx = opts.Context
+	res.memory.deadline = deadline
+	res.deserializer = resultDeserializer(opts.Deserializer, s.deserializer)
+	res.deferred = &analyticsDeferredConfig{
+		provider:      s.bucket,
+		tracer:        s.tracer,
+		meter:         s.meter,
+		timeout:       s.timeoutsConfig.AnalyticsTimeout,
+		memoryLimiter: s.resultMemoryLimiter,
+		deserializer:  s.deserializer,
+	}
+
+	return res, nil
 }
@@ -1,8 +1,12 @@
 package gocb
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
 )
 
 func (suite *IntegrationTestSuite) TestScopeAnalyticsQuery() {
@@ -36,3 +40,39 @@ func (suite *IntegrationTestSuite) setupScopeAnalytics() int {
 
 	return n
 }
+
+func (suite *UnitTestSuite) TestScopeAnalyticsQueryContext() {
+	var queryContexts []interface{}
+	analyticsProvider := new(mockAnalyticsProvider)
+	analyticsProvider.
+		On("AnalyticsQuery", nil, mock.AnythingOfType("gocbcore.AnalyticsQueryOptions")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(1).(gocbcore.AnalyticsQueryOptions)
+
+			var actualOptions map[string]interface{}
+			err := json.Unmarshal(opts.Payload, &actualOptions)
+			suite.Require().Nil(err)
+
+			queryContexts = append(queryContexts, actualOptions["query_context"])
+		}).
+		Return(new(mockAnalyticsRowReader), nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getAnalyticsProvider").Return(analyticsProvider, nil)
+
+	b := suite.bucket("analyticsBucket", TimeoutsConfig{AnalyticsTimeout: 75 * time.Second}, cli)
+	scope := suite.newScope(b, "analyticsScope")
+
+	_, err := scope.AnalyticsQuery("SELECT * FROM dataset", nil)
+	suite.Require().Nil(err, err)
+
+	_, err = scope.AnalyticsQuery("SELECT * FROM dataset", &AnalyticsOptions{
+		QueryContext: "default:`otherBucket`.`otherScope`",
+	})
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal([]interface{}{
+		"default:`analyticsBucket`.`analyticsScope`",
+		"default:`otherBucket`.`otherScope`",
+	}, queryContexts)
+}
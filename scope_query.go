@@ -1,18 +1,23 @@
 package gocb
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
 // Query executes the query statement on the server, constraining the query to the bucket and scope.
+// Unqualified keyspaces within the statement are resolved within the scope unless QueryOptions.QueryContext is set,
+// fully qualified keyspaces are resolved as written. Scope level queries require Couchbase Server 7.0 or above, older
+// servers return ErrFeatureNotAvailable.
 func (s *Scope) Query(statement string, opts *QueryOptions) (*QueryResult, error) {
 	if opts == nil {
 		opts = &QueryOptions{}
 	}
 
 	start := time.Now()
-	defer s.meter.ValueRecord(meterValueServiceQuery, "query", start)
+	defer s.meter.KeyspaceValueRecord(meterValueServiceQuery, "query", "", s.meterKeyspace(), start)
 
 	span := createSpan(s.tracer, opts.ParentSpan, "query", "query")
 	span.SetAttribute("db.statement", statement)
@@ -24,7 +29,7 @@ func (s *Scope) Query(statement string, opts *QueryOptions) (*QueryResult, error
 	if timeout == 0 {
 		timeout = s.timeoutsConfig.QueryTimeout
 	}
-	deadline := time.Now().Add(timeout)
+	deadline := queryDeadline(opts.Context, timeout)
 
 	retryStrategy := s.retryStrategyWrapper
 	if opts.RetryStrategy != nil {
@@ -40,8 +45,21 @@ func (s *Scope) Query(statement string, opts *QueryOptions) (*QueryResult, error
 		}
 	}
 
+	if opts.ScanVectors != nil {
+		err = opts.validateScanVectors(s.bucket.connectionManager.getKvProvider)
+		if err != nil {
+			return nil, QueryError{
+				InnerError:      wrapError(err, "failed to validate scan vectors"),
+				Statement:       statement,
+				ClientContextID: opts.ClientContextID,
+			}
+		}
+	}
+
 	queryOpts["statement"] = statement
-	queryOpts["query_context"] = fmt.Sprintf("%s.%s", s.BucketName(), s.Name())
+	if _, ok := queryOpts["query_context"]; !ok {
+		queryOpts["query_context"] = scopeQueryContext(s.BucketName(), s.Name())
+	}
 
 	provider, err := s.getQueryProvider()
 	if err != nil {
@@ -51,7 +69,49 @@ func (s *Scope) Query(statement string, opts *QueryOptions) (*QueryResult, error
 			ClientContextID: maybeGetQueryOption(queryOpts, "client_context_id"),
 		}
 	}
+	canceller := newQueryServerCanceller(provider, queryOpts, s.timeoutsConfig.ManagementTimeout)
+	provider = withQueryCircuitBreakerCallback(provider, opts.CircuitBreakerCallback)
+
+	res, err := execN1qlQuery(opts.Context, span, queryOpts, deadline, retryStrategy, opts.Adhoc, provider,
+		s.preparedStatementCache, s.tracer, opts.Internal.User, opts.Internal.Endpoint)
+	if err != nil {
+		canceller.cancelIfContextDone(opts.Context)
+		return nil, maybeWrapContextErr(opts.Context, maybeEnhanceScopeQueryError(err))
+	}
+	res.track(opts.Context, s.activeQueries, canceller)
+	res.maxRows = opts.MaxRows
+	res.memory.limiter = s.resultMemoryLimiter
+	res.memory.ctx = opts.Context
+	res.memory.deadline = deadline
+	res.deserializer = resultDeserializer(opts.Deserializer, s.deserializer)
+
+	return res, nil
+}
+
+// scopeQueryContext returns the query context which resolves unqualified keyspaces within the given scope.
+func scopeQueryContext(bucketName, scopeName string) string {
+	return fmt.Sprintf("default:`%s`.`%s`", bucketName, scopeName)
+}
+
+// The query service reports an unrecognized parameter when it does not support query contexts.
+const queryErrorCodeUnrecognizedParameter = 1065
+
+// maybeEnhanceScopeQueryError reports a scope level query sent to a query service which predates query contexts as
+// ErrFeatureNotAvailable, rather than as an unrecognized parameter.
+func maybeEnhanceScopeQueryError(err error) error {
+	var qErr *QueryError
+	if !errors.As(err, &qErr) {
+		return err
+	}
+
+	for _, desc := range qErr.Errors {
+		if desc.Code == queryErrorCodeUnrecognizedParameter && strings.Contains(desc.Message, "query_context") {
+			enhancedErr := *qErr
+			enhancedErr.InnerError = wrapError(ErrFeatureNotAvailable,
+				"scope level queries require Couchbase Server 7.0 or above")
+			return &enhancedErr
+		}
+	}
 
-	return execN1qlQuery(opts.Context, span, queryOpts, deadline, retryStrategy, opts.Adhoc, provider, s.tracer,
-		opts.Internal.User, opts.Internal.Endpoint)
+	return err
 }
@@ -0,0 +1,139 @@
+package gocb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultRemoveByQueryBatchSize = 1000
+
+// RemoveByQueryOptions is the set of options available to the RemoveByQuery operation.
+// UNCOMMITTED: This API may change in the future.
+type RemoveByQueryOptions struct {
+	// BatchSize is the maximum number of documents removed by each DELETE statement, defaults to 1000.
+	BatchSize uint32
+	// Pacing is the time to wait between each batch.
+	Pacing time.Duration
+
+	ScanConsistency      QueryScanConsistency
+	PositionalParameters []interface{}
+	NamedParameters      map[string]interface{}
+
+	// Timeout is applied to each individual batch rather than to the operation as a whole.
+	Timeout       time.Duration
+	RetryStrategy RetryStrategy
+	ParentSpan    RequestSpan
+
+	// Context can be used to cancel the operation between or during batches.
+	// Using a deadlined Context alongside a Timeout will cause the shorter of the two to cause cancellation, this
+	// also applies to global level timeouts.
+	// UNCOMMITTED: This API may change in the future.
+	Context context.Context
+}
+
+// RemoveByQueryResult is the result of a RemoveByQuery operation.
+// UNCOMMITTED: This API may change in the future.
+type RemoveByQueryResult struct {
+	Removed uint64
+	Batches uint32
+}
+
+// RemoveByQuery removes all documents within the given collection which match the where clause provided, the removal
+// is performed as a series of bounded DELETE statements rather than a single statement.
+// If an error occurs part way through then the result will contain the number of documents removed up until that point,
+// including those removed by the failed batch before it failed.
+// UNCOMMITTED: This API may change in the future.
+func (s *Scope) RemoveByQuery(collectionName, where string, opts *RemoveByQueryOptions) (*RemoveByQueryResult, error) {
+	if opts == nil {
+		opts = &RemoveByQueryOptions{}
+	}
+
+	if collectionName == "" {
+		return nil, makeInvalidArgumentsError("collection name cannot be empty")
+	}
+	if where == "" {
+		return nil, makeInvalidArgumentsError("where clause cannot be empty")
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultRemoveByQueryBatchSize
+	}
+
+	span := createSpan(s.tracer, opts.ParentSpan, "remove_by_query", "query")
+	span.SetAttribute("db.name", s.BucketName())
+	span.SetAttribute("db.couchbase.scope", s.Name())
+	span.SetAttribute("db.couchbase.collection", collectionName)
+	defer span.End()
+
+	statement := fmt.Sprintf("DELETE FROM `%s` WHERE %s LIMIT %d RETURNING RAW META().id",
+		strings.ReplaceAll(collectionName, "`", "``"), where, batchSize)
+
+	ctx := opts.Context
+	result := &RemoveByQueryResult{}
+	for {
+		removed, err := s.removeByQueryBatch(statement, opts, span)
+		result.Removed += removed
+		if err != nil {
+			return result, err
+		}
+
+		result.Batches++
+
+		if removed < uint64(batchSize) {
+			return result, nil
+		}
+
+		if ctx != nil {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+		}
+
+		if opts.Pacing > 0 {
+			if ctx == nil {
+				time.Sleep(opts.Pacing)
+				continue
+			}
+
+			select {
+			case <-time.After(opts.Pacing):
+			case <-ctx.Done():
+				return result, ctx.Err()
+			}
+		}
+	}
+}
+
+// removeByQueryBatch runs a single DELETE statement, returning the number of documents which it removed. If the
+// statement fails after some documents have been returned then they have already been removed, so they are counted
+// alongside the error.
+func (s *Scope) removeByQueryBatch(statement string, opts *RemoveByQueryOptions, span RequestSpan) (uint64, error) {
+	res, err := s.Query(statement, &QueryOptions{
+		ScanConsistency:      opts.ScanConsistency,
+		PositionalParameters: opts.PositionalParameters,
+		NamedParameters:      opts.NamedParameters,
+		Timeout:              opts.Timeout,
+		RetryStrategy:        opts.RetryStrategy,
+		ParentSpan:           span,
+		Context:              opts.Context,
+		Adhoc:                true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var removed uint64
+	for res.Next() {
+		removed++
+	}
+
+	err = res.Close()
+	if err != nil {
+		return removed, err
+	}
+
+	return removed, nil
+}
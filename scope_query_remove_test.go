@@ -0,0 +1,146 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockQueryIDRowReader struct {
+	Dataset []string
+	mockQueryRowReaderBase
+}
+
+func (arr *mockQueryIDRowReader) NextRow() []byte {
+	if arr.idx == len(arr.Dataset) {
+		return nil
+	}
+
+	idx := arr.idx
+	arr.idx++
+
+	return arr.Suite.mustConvertToBytes(arr.Dataset[idx])
+}
+
+func (suite *UnitTestSuite) removeByQueryScope(ctx context.Context, batches [][]string, runFn func(args mock.Arguments)) (*Scope, *mockQueryProvider) {
+	queryProvider := new(mockQueryProvider)
+	for _, batch := range batches {
+		reader := &mockQueryIDRowReader{
+			Dataset: batch,
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:  suite.mustConvertToBytes(jsonQueryResponse{}),
+				Suite: suite,
+			},
+		}
+		queryProvider.
+			On("N1QLQuery", ctx, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+			Run(runFn).
+			Return(reader, nil).
+			Once()
+	}
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	b := suite.bucket("queryBucket", suite.defaultTimeoutConfig(), cli)
+
+	return suite.newScope(b, "queryScope"), queryProvider
+}
+
+func (suite *UnitTestSuite) TestScopeRemoveByQuery() {
+	var statements []string
+	scope, provider := suite.removeByQueryScope(nil, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal("default:`queryBucket`.`queryScope`", actualOptions["query_context"])
+		suite.Assert().Equal("request_plus", actualOptions["scan_consistency"])
+		suite.Assert().Equal([]interface{}{"brewery"}, actualOptions["args"])
+		statements = append(statements, actualOptions["statement"].(string))
+	})
+
+	res, err := scope.RemoveByQuery("beers", "type=$1", &RemoveByQueryOptions{
+		BatchSize:            2,
+		Pacing:               time.Millisecond,
+		ScanConsistency:      QueryScanConsistencyRequestPlus,
+		PositionalParameters: []interface{}{"brewery"},
+	})
+	suite.Require().Nil(err, err)
+
+	suite.Assert().Equal(uint64(5), res.Removed)
+	suite.Assert().Equal(uint32(3), res.Batches)
+	suite.Require().Len(statements, 3)
+	for _, statement := range statements {
+		suite.Assert().Equal("DELETE FROM `beers` WHERE type=$1 LIMIT 2 RETURNING RAW META().id", statement)
+	}
+	provider.AssertExpectations(suite.T())
+}
+
+func (suite *UnitTestSuite) TestScopeRemoveByQueryCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	scope, _ := suite.removeByQueryScope(ctx, [][]string{{"a", "b"}, {"c", "d"}}, func(args mock.Arguments) {
+		cancel()
+	})
+
+	res, err := scope.RemoveByQuery("beers", "type='brewery'", &RemoveByQueryOptions{
+		BatchSize: 2,
+		Context:   ctx,
+	})
+	suite.Require().ErrorIs(err, context.Canceled)
+
+	suite.Assert().Equal(uint64(2), res.Removed)
+	suite.Assert().Equal(uint32(1), res.Batches)
+}
+
+func (suite *UnitTestSuite) TestScopeRemoveByQueryPartialFailure() {
+	var statement string
+	scope, provider := suite.removeByQueryScope(nil, [][]string{{"a", "b"}}, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+		statement = actualOptions["statement"].(string)
+	})
+
+	// The second batch removes a document before failing.
+	closeErr := errors.New("stream failed")
+	provider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(&mockQueryIDRowReader{
+			Dataset: []string{"c"},
+			mockQueryRowReaderBase: mockQueryRowReaderBase{
+				Meta:     suite.mustConvertToBytes(jsonQueryResponse{}),
+				CloseErr: closeErr,
+				Suite:    suite,
+			},
+		}, nil).
+		Once()
+
+	res, err := scope.RemoveByQuery("be`ers", "type='brewery'", &RemoveByQueryOptions{
+		BatchSize: 2,
+	})
+	suite.Require().ErrorIs(err, closeErr)
+
+	suite.Assert().Equal(uint64(3), res.Removed)
+	suite.Assert().Equal(uint32(1), res.Batches)
+	suite.Assert().Equal("DELETE FROM `be``ers` WHERE type='brewery' LIMIT 2 RETURNING RAW META().id", statement)
+	provider.AssertExpectations(suite.T())
+}
+
+func (suite *UnitTestSuite) TestScopeRemoveByQueryInvalidArgs() {
+	scope, _ := suite.removeByQueryScope(nil, nil, nil)
+
+	_, err := scope.RemoveByQuery("", "type='brewery'", nil)
+	suite.Assert().ErrorIs(err, ErrInvalidArgument)
+
+	_, err = scope.RemoveByQuery("beers", "", nil)
+	suite.Assert().ErrorIs(err, ErrInvalidArgument)
+}
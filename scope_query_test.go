@@ -27,6 +27,12 @@ func (suite *IntegrationTestSuite) TestScopeQuery() {
 		suite.runScopePreparedQueryPositionalTest(n)
 		suite.runScopePreparedQueryNamedTest(n)
 	})
+	suite.Run("TestScopeQueryFullyQualifiedKeyspace", func() {
+		query := fmt.Sprintf("SELECT c.* FROM `%s`.`%s`.`%s` AS c WHERE service=? LIMIT %d;", globalBucket.Name(),
+			globalScope.Name(), globalCollection.Name(), n)
+		suite.runQueryTest(n, query, globalBucket.Name(), globalScope.Name(), globalScope, true,
+			[]interface{}{"scopequery"})
+	})
 }
 
 func (suite *IntegrationTestSuite) setupScopeQuery() int {
@@ -110,7 +116,7 @@ func (suite *UnitTestSuite) TestScopeQueryPrepared() {
 		suite.Require().Nil(err)
 
 		suite.Assert().Contains(actualOptions, "client_context_id")
-		suite.Assert().Equal(actualOptions["query_context"], "queryBucket.queryScope")
+		suite.Assert().Equal("default:`queryBucket`.`queryScope`", actualOptions["query_context"])
 	})
 
 	result, err := scope.Query(statement, nil)
@@ -119,3 +125,65 @@ func (suite *UnitTestSuite) TestScopeQueryPrepared() {
 
 	suite.assertQueryBeerResult(dataset, result)
 }
+
+func (suite *UnitTestSuite) TestScopeQueryContextOverride() {
+	var dataset testQueryDataset
+	err := loadJSONTestDataset("beer_sample_query_dataset", &dataset)
+	suite.Require().Nil(err, err)
+
+	reader := &mockQueryRowReader{
+		Dataset: dataset.Results,
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta:  suite.mustConvertToBytes(dataset.jsonQueryResponse),
+			Suite: suite,
+		},
+	}
+
+	scope := suite.queryScope(false, reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal("default:`otherBucket`.`otherScope`", actualOptions["query_context"])
+	})
+
+	result, err := scope.Query("SELECT * FROM dataset", &QueryOptions{
+		Adhoc:        true,
+		QueryContext: "default:`otherBucket`.`otherScope`",
+	})
+	suite.Require().Nil(err, err)
+
+	suite.assertQueryBeerResult(dataset, result)
+}
+
+func (suite *UnitTestSuite) TestScopeQueryContextNotSupported() {
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(nil, &gocbcore.N1QLError{
+			Statement: "SELECT * FROM dataset",
+			Errors: []gocbcore.N1QLErrorDesc{
+				{Code: 1065, Message: "Unrecognized parameter in request: query_context"},
+			},
+		})
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	b := suite.bucket("queryBucket", TimeoutsConfig{QueryTimeout: 75 * time.Second}, cli)
+	scope := suite.newScope(b, "queryScope")
+
+	_, err := scope.Query("SELECT * FROM dataset", &QueryOptions{
+		Adhoc: true,
+	})
+	if !errors.Is(err, ErrFeatureNotAvailable) {
+		suite.T().Fatalf("Expected error to be feature not available but was %v", err)
+	}
+
+	var qErr *QueryError
+	suite.Require().True(errors.As(err, &qErr))
+	suite.Assert().Equal("SELECT * FROM dataset", qErr.Statement)
+	suite.Assert().Equal(uint32(1065), qErr.Errors[0].Code)
+}
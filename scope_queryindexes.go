@@ -0,0 +1,134 @@
+package gocb
+
+import "time"
+
+// ScopeQueryIndexManager provides methods for performing Couchbase query index management against the collections
+// of a single scope. Each operation which targets a collection takes the name of the collection within the scope, so
+// the ScopeName and CollectionName options must not be set.
+// UNCOMMITTED: This API may change in the future.
+type ScopeQueryIndexManager struct {
+	base *QueryIndexManager
+
+	bucketName string
+	scopeName  string
+}
+
+// QueryIndexes returns a ScopeQueryIndexManager for managing query indexes on the collections of this scope.
+// This requires Couchbase Server 7.0 or above.
+// UNCOMMITTED: This API may change in the future.
+func (s *Scope) QueryIndexes() *ScopeQueryIndexManager {
+	return &ScopeQueryIndexManager{
+		base: &QueryIndexManager{
+			provider:      s,
+			mgmtProvider:  s.bucket,
+			globalTimeout: s.timeoutsConfig.ManagementTimeout,
+			tracer:        s.tracer,
+			meter:         s.meter,
+		},
+		bucketName: s.BucketName(),
+		scopeName:  s.Name(),
+	}
+}
+
+func (sm *ScopeQueryIndexManager) collection(collectionName string) (*CollectionQueryIndexManager, error) {
+	if collectionName == "" {
+		return nil, makeInvalidArgumentsError("collection name cannot be empty")
+	}
+
+	return &CollectionQueryIndexManager{
+		base:           sm.base,
+		bucketName:     sm.bucketName,
+		scopeName:      sm.scopeName,
+		collectionName: collectionName,
+	}, nil
+}
+
+// CreateIndex creates an index over the specified fields of a collection.
+func (sm *ScopeQueryIndexManager) CreateIndex(collectionName, indexName string, fields []string,
+	opts *CreateQueryIndexOptions) error {
+	cm, err := sm.collection(collectionName)
+	if err != nil {
+		return err
+	}
+
+	return cm.CreateIndex(indexName, fields, opts)
+}
+
+// CreatePrimaryIndex creates a primary index on a collection.  An empty customName uses the default naming.
+func (sm *ScopeQueryIndexManager) CreatePrimaryIndex(collectionName string, opts *CreatePrimaryQueryIndexOptions) error {
+	cm, err := sm.collection(collectionName)
+	if err != nil {
+		return err
+	}
+
+	return cm.CreatePrimaryIndex(opts)
+}
+
+// DropIndex drops a specific index on a collection by name.
+func (sm *ScopeQueryIndexManager) DropIndex(collectionName, indexName string, opts *DropQueryIndexOptions) error {
+	cm, err := sm.collection(collectionName)
+	if err != nil {
+		return err
+	}
+
+	return cm.DropIndex(indexName, opts)
+}
+
+// DropPrimaryIndex drops the primary index of a collection.  Pass an empty customName for unnamed primary indexes.
+func (sm *ScopeQueryIndexManager) DropPrimaryIndex(collectionName string, opts *DropPrimaryQueryIndexOptions) error {
+	cm, err := sm.collection(collectionName)
+	if err != nil {
+		return err
+	}
+
+	return cm.DropPrimaryIndex(opts)
+}
+
+// GetAllIndexes returns a list of all currently registered indexes on the collections of the scope.
+func (sm *ScopeQueryIndexManager) GetAllIndexes(opts *GetAllQueryIndexesOptions) ([]QueryIndex, error) {
+	if opts == nil {
+		opts = &GetAllQueryIndexesOptions{}
+	}
+	if opts.ScopeName != "" || opts.CollectionName != "" {
+		return nil, makeInvalidArgumentsError("scope and collection names cannot be set when using a scope query index manager")
+	}
+
+	scopedOpts := *opts
+	scopedOpts.ScopeName = sm.scopeName
+
+	return sm.base.GetAllIndexes(sm.bucketName, &scopedOpts)
+}
+
+// BuildDeferredIndexes builds all indexes on a collection which are currently in deferred state.
+func (sm *ScopeQueryIndexManager) BuildDeferredIndexes(collectionName string,
+	opts *BuildDeferredQueryIndexOptions) ([]string, error) {
+	cm, err := sm.collection(collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm.BuildDeferredIndexes(opts)
+}
+
+// WatchIndexes waits for a set of indexes on a collection to come online.
+func (sm *ScopeQueryIndexManager) WatchIndexes(collectionName string, watchList []string, timeout time.Duration,
+	opts *WatchQueryIndexOptions) error {
+	cm, err := sm.collection(collectionName)
+	if err != nil {
+		return err
+	}
+
+	return cm.WatchIndexes(watchList, timeout, opts)
+}
+
+// WaitForIndex waits for the named index on a collection to reach the given state. See
+// QueryIndexManager.WaitForIndex.
+func (sm *ScopeQueryIndexManager) WaitForIndex(collectionName, indexName, state string, timeout time.Duration,
+	opts *WaitForQueryIndexOptions) error {
+	cm, err := sm.collection(collectionName)
+	if err != nil {
+		return err
+	}
+
+	return cm.WaitForIndex(indexName, state, timeout, opts)
+}
@@ -0,0 +1,55 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestScopeQueryIndexesGetAllIndexes() {
+	reader := &mockQueryIndexRowReader{
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta:  []byte("{}"),
+			Suite: suite,
+		},
+	}
+
+	cluster := suite.queryCluster(false, reader, func(args mock.Arguments) {
+		opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+		var actualOptions map[string]interface{}
+		err := json.Unmarshal(opts.Payload, &actualOptions)
+		suite.Require().Nil(err)
+
+		suite.Assert().Equal("SELECT `indexes`.* FROM system:indexes WHERE bucket_id=? AND scope_id = ? AND `using`=\"gsi\" "+
+			"ORDER BY is_primary DESC, name ASC", actualOptions["statement"])
+		suite.Assert().Equal([]interface{}{"mybucket", "myscope"}, actualOptions["args"])
+	})
+
+	mgr := &ScopeQueryIndexManager{
+		base: &QueryIndexManager{
+			provider: cluster,
+			tracer:   &NoopTracer{},
+			meter:    &meterWrapper{meter: &NoopMeter{}},
+		},
+		bucketName: "mybucket",
+		scopeName:  "myscope",
+	}
+
+	_, err := mgr.GetAllIndexes(nil)
+	suite.Require().Nil(err, err)
+
+	_, err = mgr.GetAllIndexes(&GetAllQueryIndexesOptions{
+		CollectionName: "mycollection",
+	})
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	err = mgr.CreateIndex("", "idx", []string{"name"}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+}
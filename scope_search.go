@@ -0,0 +1,73 @@
+package gocb
+
+import (
+	"fmt"
+	"time"
+)
+
+// Search executes a search request, which may contain a search query, vector queries or both, against a search
+// index which was created within this scope. Vector search requires Couchbase Server 7.6 or above, older servers
+// return ErrFeatureNotAvailable.
+// UNCOMMITTED: This API may change in the future.
+func (s *Scope) Search(indexName string, request SearchRequest, opts *SearchOptions) (*SearchResult, error) {
+	if opts == nil {
+		opts = &SearchOptions{}
+	}
+
+	// Scoped indexes are addressed by their fully qualified name.
+	indexName = fmt.Sprintf("%s.%s.%s", s.BucketName(), s.Name(), indexName)
+
+	start := time.Now()
+	defer s.meter.KeyspaceValueRecord(meterValueServiceSearch, "search", "", s.meterKeyspace(), start)
+
+	span := createSpan(s.tracer, opts.ParentSpan, "search", "search")
+	span.SetAttribute("db.operation", indexName)
+	span.SetAttribute("db.name", s.BucketName())
+	span.SetAttribute("db.couchbase.scope", s.Name())
+	defer span.End()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = s.timeoutsConfig.SearchTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	retryStrategy := s.retryStrategyWrapper
+	if opts.RetryStrategy != nil {
+		retryStrategy = newRetryStrategyWrapper(opts.RetryStrategy)
+	}
+
+	searchOpts, err := opts.toMap(indexName)
+	if err != nil {
+		return nil, SearchError{
+			InnerError: wrapError(err, "failed to generate query options"),
+			Query:      request.SearchQuery,
+		}
+	}
+
+	err = request.applyTo(searchOpts)
+	if err != nil {
+		return nil, SearchError{
+			InnerError: wrapError(err, "failed to generate search request"),
+			Query:      request.SearchQuery,
+		}
+	}
+
+	if request.VectorSearch != nil {
+		err = verifyVectorSearchSupported(opts.Context, s.bucket, s.searchCapabilities, span, deadline,
+			retryStrategy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := execSearchQuery(opts.Context, span, s.getSearchProvider, s.tracer, indexName, searchOpts, deadline,
+		retryStrategy, opts.Internal.User)
+	if err != nil {
+		return nil, err
+	}
+	res.deserializer = resultDeserializer(opts.Deserializer, s.deserializer)
+	res.requestedFacets = opts.Facets
+
+	return res, nil
+}
@@ -0,0 +1,117 @@
+package gocb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeSearchIndexManager provides methods for performing Couchbase search index management on the indexes of a
+// single scope. This requires Couchbase Server 7.6 or above.
+// UNCOMMITTED: This API may change in the future.
+type ScopeSearchIndexManager struct {
+	base *SearchIndexManager
+}
+
+// SearchIndexes returns a ScopeSearchIndexManager for managing the search indexes of this scope.
+// UNCOMMITTED: This API may change in the future.
+func (s *Scope) SearchIndexes() *ScopeSearchIndexManager {
+	return &ScopeSearchIndexManager{
+		base: &SearchIndexManager{
+			mgmtProvider: s.bucket,
+			bucketName:   s.BucketName(),
+			scopeName:    s.Name(),
+			tracer:       s.tracer,
+			meter:        s.meter,
+		},
+	}
+}
+
+// fromScopedIndex converts an index returned by the server, which is named by its fully qualified name, to use the
+// name of the index within the scope so that it can be passed back to the manager.
+func (sm *ScopeSearchIndexManager) fromScopedIndex(index *SearchIndex) {
+	index.Name = strings.TrimPrefix(index.Name, fmt.Sprintf("%s.%s.", sm.base.bucketName, sm.base.scopeName))
+}
+
+// GetAllIndexes retrieves all of the search indexes for the scope.
+func (sm *ScopeSearchIndexManager) GetAllIndexes(opts *GetAllSearchIndexOptions) ([]SearchIndex, error) {
+	indexes, err := sm.base.GetAllIndexes(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range indexes {
+		sm.fromScopedIndex(&indexes[i])
+	}
+
+	return indexes, nil
+}
+
+// GetIndex retrieves a specific search index by name.
+func (sm *ScopeSearchIndexManager) GetIndex(indexName string, opts *GetSearchIndexOptions) (*SearchIndex, error) {
+	index, err := sm.base.GetIndex(indexName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.fromScopedIndex(index)
+
+	return index, nil
+}
+
+// UpsertIndex creates or updates a search index. The SourceName of the index defaults to the bucket of the scope,
+// and must not be set to any other bucket.
+func (sm *ScopeSearchIndexManager) UpsertIndex(indexDefinition SearchIndex, opts *UpsertSearchIndexOptions) error {
+	if indexDefinition.SourceName == "" {
+		indexDefinition.SourceName = sm.base.bucketName
+	} else if indexDefinition.SourceName != sm.base.bucketName {
+		return makeInvalidArgumentsError(fmt.Sprintf("index source name %s must be the bucket of the scope, %s",
+			indexDefinition.SourceName, sm.base.bucketName))
+	}
+
+	return sm.base.UpsertIndex(indexDefinition, opts)
+}
+
+// DropIndex removes the search index with the specific name.
+func (sm *ScopeSearchIndexManager) DropIndex(indexName string, opts *DropSearchIndexOptions) error {
+	return sm.base.DropIndex(indexName, opts)
+}
+
+// AnalyzeDocument returns how a doc is analyzed against a specific index.
+func (sm *ScopeSearchIndexManager) AnalyzeDocument(indexName string, doc interface{}, opts *AnalyzeDocumentOptions) ([]interface{}, error) {
+	return sm.base.AnalyzeDocument(indexName, doc, opts)
+}
+
+// GetIndexedDocumentsCount retrieves the document count for a search index.
+func (sm *ScopeSearchIndexManager) GetIndexedDocumentsCount(indexName string, opts *GetIndexedDocumentsCountOptions) (uint64, error) {
+	return sm.base.GetIndexedDocumentsCount(indexName, opts)
+}
+
+// PauseIngest pauses updates and maintenance for an index.
+func (sm *ScopeSearchIndexManager) PauseIngest(indexName string, opts *PauseIngestSearchIndexOptions) error {
+	return sm.base.PauseIngest(indexName, opts)
+}
+
+// ResumeIngest resumes updates and maintenance for an index.
+func (sm *ScopeSearchIndexManager) ResumeIngest(indexName string, opts *ResumeIngestSearchIndexOptions) error {
+	return sm.base.ResumeIngest(indexName, opts)
+}
+
+// AllowQuerying allows querying against an index.
+func (sm *ScopeSearchIndexManager) AllowQuerying(indexName string, opts *AllowQueryingSearchIndexOptions) error {
+	return sm.base.AllowQuerying(indexName, opts)
+}
+
+// DisallowQuerying disallows querying against an index.
+func (sm *ScopeSearchIndexManager) DisallowQuerying(indexName string, opts *AllowQueryingSearchIndexOptions) error {
+	return sm.base.DisallowQuerying(indexName, opts)
+}
+
+// FreezePlan freezes the assignment of index partitions to nodes.
+func (sm *ScopeSearchIndexManager) FreezePlan(indexName string, opts *AllowQueryingSearchIndexOptions) error {
+	return sm.base.FreezePlan(indexName, opts)
+}
+
+// UnfreezePlan unfreezes the assignment of index partitions to nodes.
+func (sm *ScopeSearchIndexManager) UnfreezePlan(indexName string, opts *AllowQueryingSearchIndexOptions) error {
+	return sm.base.UnfreezePlan(indexName, opts)
+}
@@ -0,0 +1,94 @@
+package gocb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *UnitTestSuite) TestScopeSearchIndexesGetIndex() {
+	resp := &mgmtResponse{
+		StatusCode: 200,
+		Body: ioutil.NopCloser(bytes.NewReader([]byte(
+			`{"status":"ok","indexDef":{"name":"mybucket.myscope.searchy","sourceName":"mybucket","type":"fulltext-index"}}`,
+		))),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/api/bucket/mybucket/scope/myscope/index/searchy", req.Path)
+			suite.Assert().Equal(ServiceTypeSearch, req.Service)
+			suite.Assert().Equal("GET", req.Method)
+		}).
+		Return(resp, nil)
+
+	mgr := &ScopeSearchIndexManager{
+		base: &SearchIndexManager{
+			mgmtProvider: mockProvider,
+			bucketName:   "mybucket",
+			scopeName:    "myscope",
+			tracer:       &NoopTracer{},
+			meter:        &meterWrapper{meter: &NoopMeter{}},
+		},
+	}
+
+	index, err := mgr.GetIndex("searchy", nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("searchy", index.Name)
+	suite.Assert().Equal("mybucket", index.SourceName)
+}
+
+func (suite *UnitTestSuite) TestScopeSearchIndexesUpsertIndex() {
+	resp := &mgmtResponse{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"status":"ok"}`))),
+	}
+
+	mockProvider := new(mockMgmtProvider)
+	mockProvider.
+		On("executeMgmtRequest", nil, mock.AnythingOfType("mgmtRequest")).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(mgmtRequest)
+
+			suite.Assert().Equal("/api/bucket/mybucket/scope/myscope/index/searchy", req.Path)
+			suite.Assert().Equal("PUT", req.Method)
+
+			var index jsonSearchIndex
+			suite.Require().Nil(json.Unmarshal(req.Body, &index))
+			suite.Assert().Equal("mybucket", index.SourceName)
+		}).
+		Return(resp, nil)
+
+	mgr := &ScopeSearchIndexManager{
+		base: &SearchIndexManager{
+			mgmtProvider: mockProvider,
+			bucketName:   "mybucket",
+			scopeName:    "myscope",
+			tracer:       &NoopTracer{},
+			meter:        &meterWrapper{meter: &NoopMeter{}},
+		},
+	}
+
+	err := mgr.UpsertIndex(SearchIndex{
+		Name: "searchy",
+		Type: "fulltext-index",
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	err = mgr.UpsertIndex(SearchIndex{
+		Name:       "searchy",
+		Type:       "fulltext-index",
+		SourceName: "otherbucket",
+	}, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+	mockProvider.AssertNumberOfCalls(suite.T(), "executeMgmtRequest", 1)
+}
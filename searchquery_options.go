@@ -31,9 +31,13 @@ const (
 	SearchScanConsistencyNotBounded
 )
 
-// SearchHighlightOptions are the options available for search highlighting.
+// SearchHighlightOptions are the options available for search highlighting. The highlighted fragments of each
+// hit are available from SearchRow.Fragments, keyed by field name.
 type SearchHighlightOptions struct {
-	Style  SearchHighlightStyle
+	// Style is the style used to mark matches within fragments, defaults to the style configured on the index.
+	Style SearchHighlightStyle
+
+	// Fields restricts highlighting to the given fields, by default all fields which are stored are highlighted.
 	Fields []string
 }
 
@@ -52,6 +56,10 @@ type SearchOptions struct {
 	// Raw provides a way to provide extra parameters in the request body for the query.
 	Raw map[string]interface{}
 
+	// Deserializer is used to decode the fields of each row by SearchRow.Fields, defaults to JSONDeserializer.
+	// UNCOMMITTED: This API may change in the future.
+	Deserializer Deserializer
+
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 
@@ -100,6 +108,12 @@ func (opts *SearchOptions) toMap(indexName string) (map[string]interface{}, erro
 	}
 
 	if opts.Highlight != nil {
+		switch opts.Highlight.Style {
+		case DefaultHighlightStyle, HTMLHighlightStyle, AnsiHightlightStyle:
+		default:
+			return nil, makeInvalidArgumentsError("unexpected highlight style")
+		}
+
 		highlight := make(map[string]interface{})
 		highlight["style"] = string(opts.Highlight.Style)
 		highlight["fields"] = opts.Highlight.Fields
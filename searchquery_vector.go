@@ -0,0 +1,186 @@
+package gocb
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	cbsearch "github.com/couchbase/gocb/v2/search"
+	"github.com/google/uuid"
+)
+
+// vectorSearchClusterCompatibility is the cluster compatibility version, 7.6, from which vector search is supported.
+const vectorSearchClusterCompatibility = 0x70006
+
+// VectorQueryCombination specifies how the results of multiple vector queries are combined.
+// UNCOMMITTED: This API may change in the future.
+type VectorQueryCombination string
+
+const (
+	// VectorQueryCombinationAnd specifies that a result must match all of the vector queries.
+	VectorQueryCombinationAnd VectorQueryCombination = "and"
+
+	// VectorQueryCombinationOr specifies that a result must match any of the vector queries.
+	VectorQueryCombinationOr VectorQueryCombination = "or"
+)
+
+// VectorQuery is a K nearest neighbour query against a single vector field.
+// UNCOMMITTED: This API may change in the future.
+type VectorQuery struct {
+	// Field is the name of the vector field to search.
+	Field string
+
+	// Vector is the vector to find the nearest neighbours of, it must have the same number of dimensions as the field.
+	Vector []float32
+
+	// NumCandidates is the number of nearest neighbours to return, defaults to 3.
+	NumCandidates int
+
+	// Boost is the relative weight of this query when combined with other queries.
+	Boost float32
+}
+
+func (vq VectorQuery) toMap() (map[string]interface{}, error) {
+	if vq.Field == "" {
+		return nil, makeInvalidArgumentsError("vector query field cannot be empty")
+	}
+	if len(vq.Vector) == 0 {
+		return nil, makeInvalidArgumentsError("vector query vector cannot be empty")
+	}
+	if vq.NumCandidates < 0 {
+		return nil, makeInvalidArgumentsError("vector query number of candidates cannot be negative")
+	}
+
+	numCandidates := vq.NumCandidates
+	if numCandidates == 0 {
+		numCandidates = 3
+	}
+
+	data := map[string]interface{}{
+		"field":  vq.Field,
+		"vector": vq.Vector,
+		"k":      numCandidates,
+	}
+	if vq.Boost != 0 {
+		data["boost"] = vq.Boost
+	}
+
+	return data, nil
+}
+
+// VectorSearch is a set of vector queries to execute as part of a SearchRequest.
+// UNCOMMITTED: This API may change in the future.
+type VectorSearch struct {
+	Queries []VectorQuery
+
+	// Combination specifies how the results of the queries are combined, defaults to the server default which is
+	// VectorQueryCombinationOr.
+	Combination VectorQueryCombination
+}
+
+// SearchRequest is a search to execute using Search, made up of an optional search query and optional vector
+// search. At least one of SearchQuery or VectorSearch must be set.
+// UNCOMMITTED: This API may change in the future.
+type SearchRequest struct {
+	SearchQuery  cbsearch.Query
+	VectorSearch *VectorSearch
+}
+
+func (req SearchRequest) applyTo(data map[string]interface{}) error {
+	if req.SearchQuery == nil && req.VectorSearch == nil {
+		return makeInvalidArgumentsError("search request must contain a search query or vector search")
+	}
+
+	if req.SearchQuery != nil {
+		data["query"] = req.SearchQuery
+	} else {
+		// The server requires a query, matching nothing means that results come only from the vector search.
+		data["query"] = cbsearch.NewMatchNoneQuery()
+	}
+
+	if req.VectorSearch == nil {
+		return nil
+	}
+
+	if len(req.VectorSearch.Queries) == 0 {
+		return makeInvalidArgumentsError("vector search must contain at least one vector query")
+	}
+
+	knn := make([]map[string]interface{}, len(req.VectorSearch.Queries))
+	for i, query := range req.VectorSearch.Queries {
+		queryData, err := query.toMap()
+		if err != nil {
+			return err
+		}
+		knn[i] = queryData
+	}
+	data["knn"] = knn
+
+	switch req.VectorSearch.Combination {
+	case "":
+	case VectorQueryCombinationAnd, VectorQueryCombinationOr:
+		data["knn_operator"] = string(req.VectorSearch.Combination)
+	default:
+		return makeInvalidArgumentsError("unexpected vector query combination")
+	}
+
+	return nil
+}
+
+// searchCapabilities records which search features the cluster is known to support, support is only cached once
+// it has been seen as clusters are not downgraded.
+type searchCapabilities struct {
+	vectorSearchSupported uint32
+}
+
+// verifyVectorSearchSupported returns ErrFeatureNotAvailable if the cluster does not support vector search. Servers
+// which do not support vector search silently ignore vector queries, so we must check before sending them.
+func verifyVectorSearchSupported(ctx context.Context, provider mgmtProvider, caps *searchCapabilities,
+	parent RequestSpan, deadline time.Time, retryStrategy *retryStrategyWrapper) error {
+	if caps != nil && atomic.LoadUint32(&caps.vectorSearchSupported) == 1 {
+		return nil
+	}
+
+	req := mgmtRequest{
+		Service:       ServiceTypeManagement,
+		Path:          "/pools/default",
+		Method:        "GET",
+		IsIdempotent:  true,
+		UniqueID:      uuid.New().String(),
+		Timeout:       time.Until(deadline),
+		parentSpanCtx: parent.Context(),
+	}
+	if retryStrategy != nil {
+		req.RetryStrategy = retryStrategy.wrapped
+	}
+
+	resp, err := provider.executeMgmtRequest(ctx, req)
+	if err != nil {
+		return makeGenericMgmtError(err, &req, resp, "")
+	}
+	defer ensureBodyClosed(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return makeMgmtBadStatusError("failed to get cluster version", &req, resp)
+	}
+
+	var clusterCfg jsonClusterCfg
+	jsonDec := json.NewDecoder(resp.Body)
+	err = jsonDec.Decode(&clusterCfg)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range clusterCfg.Nodes {
+		if node.ClusterCompatibility < vectorSearchClusterCompatibility {
+			return wrapError(ErrFeatureNotAvailable, "vector search requires Couchbase Server 7.6 or above")
+		}
+	}
+
+	if caps != nil {
+		atomic.StoreUint32(&caps.vectorSearchSupported, 1)
+	}
+
+	return nil
+}
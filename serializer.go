@@ -0,0 +1,49 @@
+package gocb
+
+import "encoding/json"
+
+// JSONSerializer is used to encode and decode JSON, allowing encoding/json to be replaced by a different JSON library
+// such as jsoniter. A JSONSerializer is shared by every operation of a cluster and so must be safe for concurrent
+// use by multiple goroutines.
+// UNCOMMITTED: This API may change in the future.
+type JSONSerializer interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte, out interface{}) error
+}
+
+// DefaultJSONSerializer implements JSONSerializer using encoding/json.
+// UNCOMMITTED: This API may change in the future.
+type DefaultJSONSerializer struct{}
+
+// NewDefaultJSONSerializer returns a new DefaultJSONSerializer.
+// UNCOMMITTED: This API may change in the future.
+func NewDefaultJSONSerializer() *DefaultJSONSerializer {
+	return &DefaultJSONSerializer{}
+}
+
+// Marshal encodes value as JSON.
+func (s *DefaultJSONSerializer) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes the JSON data into out.
+func (s *DefaultJSONSerializer) Unmarshal(data []byte, out interface{}) error {
+	return json.Unmarshal(data, out)
+}
+
+// serializerDeserializer decodes the rows of query, analytics and search results using a JSONSerializer.
+type serializerDeserializer struct {
+	serializer JSONSerializer
+}
+
+func newSerializerDeserializer(serializer JSONSerializer) Deserializer {
+	if serializer == nil {
+		return nil
+	}
+
+	return &serializerDeserializer{serializer: serializer}
+}
+
+func (d *serializerDeserializer) Deserialize(data []byte, out interface{}) error {
+	return d.serializer.Unmarshal(data, out)
+}
@@ -0,0 +1,154 @@
+package gocb
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+	"github.com/stretchr/testify/mock"
+)
+
+type countingJSONSerializer struct {
+	DefaultJSONSerializer
+	marshals   uint32
+	unmarshals uint32
+}
+
+func (s *countingJSONSerializer) Marshal(value interface{}) ([]byte, error) {
+	atomic.AddUint32(&s.marshals, 1)
+	return s.DefaultJSONSerializer.Marshal(value)
+}
+
+func (s *countingJSONSerializer) Unmarshal(data []byte, out interface{}) error {
+	atomic.AddUint32(&s.unmarshals, 1)
+	return s.DefaultJSONSerializer.Unmarshal(data, out)
+}
+
+type serializerTestRowReader struct {
+	mockQueryRowReaderBase
+	rows [][]byte
+}
+
+func (r *serializerTestRowReader) NextRow() []byte {
+	if r.idx == len(r.rows) {
+		return nil
+	}
+
+	r.idx++
+	return r.rows[r.idx-1]
+}
+
+// newSerializerTestCluster returns a cluster using the given serializer, with a collection whose documents are all
+// {"name":"beer"} and which returns two rows of the same document for every query.
+func newSerializerTestCluster(serializer JSONSerializer) (*Cluster, *Collection) {
+	doc := []byte(`{"name":"beer"}`)
+
+	kvProvider := new(mockKvProvider)
+	kvProvider.
+		On("Get", mock.AnythingOfType("gocbcore.GetOptions"), mock.AnythingOfType("gocbcore.GetCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.GetCallback)
+			cb(&gocbcore.GetResult{
+				Value: doc,
+				Flags: gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression),
+				Cas:   gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(new(mockPendingOp), nil)
+	kvProvider.
+		On("Set", mock.AnythingOfType("gocbcore.SetOptions"), mock.AnythingOfType("gocbcore.StoreCallback")).
+		Run(func(args mock.Arguments) {
+			cb := args.Get(1).(gocbcore.StoreCallback)
+			cb(&gocbcore.StoreResult{
+				Cas: gocbcore.Cas(1),
+			}, nil)
+		}).
+		Return(new(mockPendingOp), nil)
+
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", mock.Anything, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Return(func(ctx context.Context, opts gocbcore.N1QLQueryOptions) queryRowReader {
+			return &serializerTestRowReader{rows: [][]byte{doc, doc}}
+		}, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getKvProvider", "mock").Return(kvProvider, nil)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+
+	cluster := clusterFromOptions(ClusterOptions{
+		Tracer:     &NoopTracer{},
+		Meter:      &NoopMeter{},
+		Serializer: serializer,
+	})
+	cluster.connectionManager = cli
+
+	return cluster, newBucket(cluster, "mock").DefaultCollection()
+}
+
+func (suite *UnitTestSuite) TestClusterSerializer() {
+	serializer := &countingJSONSerializer{}
+	cluster, col := newSerializerTestCluster(serializer)
+
+	var doc testBreweryDocument
+	_, err := col.Upsert("key", map[string]string{"name": "beer"}, nil)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(1), atomic.LoadUint32(&serializer.marshals))
+
+	res, err := col.Get("key", nil)
+	suite.Require().Nil(err, err)
+	suite.Require().Nil(res.Content(&doc))
+	suite.Assert().Equal("beer", doc.Name)
+	suite.Assert().Equal(uint32(1), atomic.LoadUint32(&serializer.unmarshals))
+
+	result, err := cluster.Query("SELECT * FROM beer", &QueryOptions{Adhoc: true})
+	suite.Require().Nil(err, err)
+	for result.Next() {
+		suite.Require().Nil(result.Row(&doc))
+	}
+	suite.Assert().Equal(uint32(3), atomic.LoadUint32(&serializer.unmarshals))
+
+	// A deserializer specified for the request takes precedence over the serializer of the cluster.
+	result, err = cluster.Query("SELECT * FROM beer", &QueryOptions{
+		Adhoc:        true,
+		Deserializer: NewJSONDeserializer(),
+	})
+	suite.Require().Nil(err, err)
+	for result.Next() {
+		suite.Require().Nil(result.Row(&doc))
+	}
+	suite.Assert().Equal(uint32(3), atomic.LoadUint32(&serializer.unmarshals))
+}
+
+// BenchmarkSerializerRoundTrip reports the number of times the cluster serializer is used for a Get followed by a
+// Query returning two rows, with the KV and query services mocked out.
+func BenchmarkSerializerRoundTrip(b *testing.B) {
+	serializer := &countingJSONSerializer{}
+	cluster, col := newSerializerTestCluster(serializer)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var doc testBreweryDocument
+		res, err := col.Get("key", nil)
+		if err != nil {
+			b.Fatalf("failed to get: %v", err)
+		}
+		if err := res.Content(&doc); err != nil {
+			b.Fatalf("failed to decode document: %v", err)
+		}
+
+		result, err := cluster.Query("SELECT * FROM beer", &QueryOptions{Adhoc: true})
+		if err != nil {
+			b.Fatalf("failed to query: %v", err)
+		}
+		for result.Next() {
+			if err := result.Row(&doc); err != nil {
+				b.Fatalf("failed to decode row: %v", err)
+			}
+		}
+	}
+
+	b.ReportMetric(float64(atomic.LoadUint32(&serializer.unmarshals))/float64(b.N), "unmarshals/op")
+}
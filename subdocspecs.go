@@ -39,6 +39,17 @@ func GetSpec(path string, opts *GetSpecOptions) LookupInSpec {
 	}
 }
 
+// GetMacroSpec retrieves a virtual extended attribute describing the metadata of the document, such as its expiry
+// time or CAS. The value can later be retrieved from the LookupResult.
+// UNCOMMITTED: This API may change in the future.
+func GetMacroSpec(macro LookupInMacro) LookupInSpec {
+	return LookupInSpec{
+		op:      memd.SubDocOpGet,
+		path:    string(macro),
+		isXattr: true,
+	}
+}
+
 // ExistsSpecOptions are the options available to LookupIn subdoc Exists operations.
 type ExistsSpecOptions struct {
 	IsXattr bool
@@ -268,11 +279,16 @@ func ArrayInsertSpec(path string, val interface{}, opts *ArrayInsertSpecOptions)
 
 // ArrayAddUniqueSpecOptions are the options available to subdocument ArrayAddUnique operations.
 type ArrayAddUniqueSpecOptions struct {
+	// CreatePath creates the array, and any missing parent objects, if the path does not exist.
 	CreatePath bool
 	IsXattr    bool
 }
 
-// ArrayAddUniqueSpec adds an dictionary add unique operation to this mutation operation set.
+// ArrayAddUniqueSpec adds the value to the array at path only if the array does not already contain it. The value
+// must be a JSON primitive, and the array must only contain JSON primitives.
+// If the array already contains the value then MutateIn fails with an error matching ErrPathExists, and none of the
+// mutations are applied. Like all mutations, this clears the expiry of the document unless
+// MutateInOptions.PreserveExpiry is set.
 func ArrayAddUniqueSpec(path string, val interface{}, opts *ArrayAddUniqueSpecOptions) MutateInSpec {
 	if opts == nil {
 		opts = &ArrayAddUniqueSpecOptions{}
@@ -78,6 +78,7 @@ var (
 	CustomConflictResolutionFeature         = FeatureCode("customconflictresolution")
 	QueryImprovedErrorsFeature              = FeatureCode("queryimprovederrors")
 	TransactionsQueryFeature                = FeatureCode("transactionsquery")
+	CreateAsDeletedFeature                  = FeatureCode("createasdeleted")
 )
 
 type TestFeatureFlag struct {
@@ -181,6 +182,8 @@ func (c *testCluster) SupportsFeature(feature FeatureCode) bool {
 			supported = false
 		case TransactionsQueryFeature:
 			supported = false
+		case CreateAsDeletedFeature:
+			supported = false
 		}
 	} else {
 		switch feature {
@@ -268,6 +271,8 @@ func (c *testCluster) SupportsFeature(feature FeatureCode) bool {
 			supported = !c.Version.Lower(srvVer700)
 		case TransactionsQueryFeature:
 			supported = !c.Version.Lower(srvVer700)
+		case CreateAsDeletedFeature:
+			supported = !c.Version.Lower(srvVer660)
 		case TransactionsBulkFeature:
 			supported = !c.Version.Lower(srvVer700)
 		case CustomConflictResolutionFeature:
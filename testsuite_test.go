@@ -100,7 +100,16 @@ func (suite *IntegrationTestSuite) generateTransactionsKeys() {
 }
 
 func (suite *IntegrationTestSuite) AssertKVMetrics(metricName, op string, length int, atLeastLen bool) {
-	suite.AssertMetrics(makeMetricsKeyFromCmd(metricName, "kv", op), length, atLeastLen)
+	key := makeMetricsKeyFromCmd(metricName, "kv", op)
+	suite.AssertMetrics(key, length, atLeastLen)
+
+	globalMeter.lock.Lock()
+	defer globalMeter.lock.Unlock()
+	if recorder, ok := globalMeter.recorders[key]; ok {
+		suite.Assert().Contains(recorder.tags, meterAttribBucketKey)
+		suite.Assert().Contains(recorder.tags, meterAttribScopeKey)
+		suite.Assert().Contains(recorder.tags, meterAttribCollectionKey)
+	}
 }
 
 func makeMetricsKeyFromCmd(metricName, service, op string) string {
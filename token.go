@@ -9,9 +9,19 @@ import (
 )
 
 // MutationToken holds the mutation state information from an operation.
+//
+// A token identifies a mutation by the vbucket (partition) which the document belongs to, the UUID of that vbucket
+// at the time of the mutation and the sequence number assigned to the mutation within that vbucket. Sequence numbers
+// are only comparable between tokens with the same bucket name, partition ID and partition UUID, as a change of
+// partition UUID indicates that the vbucket history has diverged, e.g. following a failover. These are the same
+// values used by DCP, so tokens can be used to checkpoint a consumer or to build idempotent consumers.
+//
+// Mutation tokens are returned for all mutations unless IoConfig.DisableMutationTokens is set.
 type MutationToken struct {
-	token      gocbcore.MutationToken
-	bucketName string
+	token          gocbcore.MutationToken
+	bucketName     string
+	scopeName      string
+	collectionName string
 }
 
 type bucketToken struct {
@@ -24,17 +34,33 @@ func (mt MutationToken) BucketName() string {
 	return mt.bucketName
 }
 
-// PartitionUUID returns the UUID of the vbucket that this token belongs to.
+// ScopeName returns the name of the scope containing the document which the mutation was made to. This is empty for
+// tokens whose collection is not known, such as tokens which were unmarshalled from JSON.
+// UNCOMMITTED: This API may change in the future.
+func (mt MutationToken) ScopeName() string {
+	return mt.scopeName
+}
+
+// CollectionName returns the name of the collection containing the document which the mutation was made to. This is
+// empty for tokens whose collection is not known, such as tokens which were unmarshalled from JSON.
+// UNCOMMITTED: This API may change in the future.
+func (mt MutationToken) CollectionName() string {
+	return mt.collectionName
+}
+
+// PartitionUUID returns the UUID of the vbucket that this token belongs to, also known as the VbUUID.
+// The UUID changes whenever the vbucket history branches, such as after a failover.
 func (mt MutationToken) PartitionUUID() uint64 {
 	return uint64(mt.token.VbUUID)
 }
 
-// PartitionID returns the ID of the vbucket that this token belongs to.
+// PartitionID returns the ID of the vbucket that this token belongs to, also known as the VbID.
 func (mt MutationToken) PartitionID() uint64 {
 	return uint64(mt.token.VbID)
 }
 
-// SequenceNumber returns the sequence number of the vbucket that this token belongs to.
+// SequenceNumber returns the sequence number assigned to the mutation within the vbucket, also known as the SeqNo.
+// Sequence numbers increase monotonically for each mutation within a vbucket.
 func (mt MutationToken) SequenceNumber() uint64 {
 	return uint64(mt.token.SeqNo)
 }
@@ -55,6 +81,12 @@ type mutationStateData map[string]*bucketTokens
 type searchMutationState map[string]map[string]uint64
 
 // MutationState holds and aggregates MutationToken's across multiple operations.
+//
+// When used for query consistency the tokens are sent as one scan vector per bucket, mapping each vbucket to the
+// sequence number and vbucket UUID of the most recent mutation to it. Sequence numbers are shared by every collection
+// within a bucket, so a query waits for its indexes to reach the given sequence numbers regardless of which keyspace
+// the mutations were made to. Where only some of the tracked mutations are relevant to a query, ForCollection can be
+// used to avoid waiting on the others.
 type MutationState struct {
 	tokens []MutationToken
 }
@@ -75,6 +107,41 @@ func (mt *MutationState) Add(tokens ...MutationToken) {
 	}
 }
 
+// AddResults includes the mutation tokens of the given operation results in this mutation state. Results without a
+// mutation token, such as when mutation tokens are disabled, are ignored.
+// UNCOMMITTED: This API may change in the future.
+func (mt *MutationState) AddResults(results ...MutationResult) {
+	for _, result := range results {
+		if token := result.MutationToken(); token != nil {
+			mt.Add(*token)
+		}
+	}
+}
+
+// ForCollection returns a new MutationState containing only the tokens for mutations made to the given collection.
+// Tokens whose collection is not known, such as tokens which were unmarshalled from JSON, are kept if they belong to
+// the bucket as they may be relevant.
+// UNCOMMITTED: This API may change in the future.
+func (mt *MutationState) ForCollection(bucketName, scopeName, collectionName string) *MutationState {
+	state := &MutationState{}
+	for _, token := range mt.tokens {
+		if token.bucketName != bucketName {
+			continue
+		}
+
+		if token.scopeName == "" && token.collectionName == "" {
+			state.tokens = append(state.tokens, token)
+			continue
+		}
+
+		if token.scopeName == scopeName && token.collectionName == collectionName {
+			state.tokens = append(state.tokens, token)
+		}
+	}
+
+	return state
+}
+
 // MutationStateInternal specifies internal operations.
 // Internal: This should never be used and is not supported.
 type MutationStateInternal struct {
@@ -119,15 +186,19 @@ func (mt *MutationState) MarshalJSON() ([]byte, error) {
 		}
 
 		vbID := fmt.Sprintf("%d", token.token.VbID)
+		vbUUID := fmt.Sprintf("%d", token.token.VbUUID)
 		stateToken := (*(data)[bucketName])[vbID]
 		if stateToken == nil {
 			stateToken = &bucketToken{}
 			(*(data)[bucketName])[vbID] = stateToken
+		} else if stateToken.VbUUID == vbUUID && stateToken.SeqNo > uint64(token.token.SeqNo) {
+			// Tokens may have been added out of order, an older mutation to the same vbucket history must not lower
+			// the sequence number that we wait for.
+			continue
 		}
 
 		stateToken.SeqNo = uint64(token.token.SeqNo)
-		stateToken.VbUUID = fmt.Sprintf("%d", token.token.VbUUID)
-
+		stateToken.VbUUID = vbUUID
 	}
 
 	return json.Marshal(data)
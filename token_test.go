@@ -100,3 +100,95 @@ func (suite *UnitTestSuite) TestMutationState_toSeachMutationState() {
 		suite.T().Fatalf("Failed to generate correct JSON output %s", bytes)
 	}
 }
+
+func (suite *UnitTestSuite) TestMutationTokenAccessors() {
+	token := MutationToken{
+		token: gocbcore.MutationToken{
+			VbID:   12,
+			VbUUID: gocbcore.VbUUID(1234567),
+			SeqNo:  gocbcore.SeqNo(89),
+		},
+		bucketName: "frank",
+	}
+
+	suite.Assert().Equal("frank", token.BucketName())
+	suite.Assert().Equal(uint64(12), token.PartitionID())
+	suite.Assert().Equal(uint64(1234567), token.PartitionUUID())
+	suite.Assert().Equal(uint64(89), token.SequenceNumber())
+}
+
+func (suite *UnitTestSuite) TestMutationState_OutOfOrderTokens() {
+	newer := MutationToken{
+		token: gocbcore.MutationToken{
+			VbID:   1,
+			VbUUID: gocbcore.VbUUID(9),
+			SeqNo:  gocbcore.SeqNo(30),
+		},
+		bucketName: "frank",
+	}
+	older := MutationToken{
+		token: gocbcore.MutationToken{
+			VbID:   1,
+			VbUUID: gocbcore.VbUUID(9),
+			SeqNo:  gocbcore.SeqNo(12),
+		},
+		bucketName: "frank",
+	}
+
+	state := NewMutationState(newer, older)
+
+	bytes, err := json.Marshal(&state)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(`{"frank":{"1":[30,"9"]}}`, string(bytes))
+}
+
+func (suite *UnitTestSuite) TestMutationState_ForCollection() {
+	newToken := func(bucket, scope, collection string, vbID uint16) MutationToken {
+		return MutationToken{
+			token: gocbcore.MutationToken{
+				VbID:   vbID,
+				VbUUID: gocbcore.VbUUID(9),
+				SeqNo:  gocbcore.SeqNo(12),
+			},
+			bucketName:     bucket,
+			scopeName:      scope,
+			collectionName: collection,
+		}
+	}
+
+	users := newToken("frank", "inventory", "users", 1)
+	orders := newToken("frank", "inventory", "orders", 2)
+	otherBucket := newToken("bob", "inventory", "users", 3)
+	unknown := newToken("frank", "", "", 4)
+
+	state := NewMutationState(users, orders, otherBucket, unknown)
+
+	filtered := state.ForCollection("frank", "inventory", "users")
+	suite.Assert().Equal([]MutationToken{users, unknown}, filtered.Internal().Tokens())
+
+	bytes, err := json.Marshal(filtered)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(`{"frank":{"1":[12,"9"],"4":[12,"9"]}}`, string(bytes))
+
+	suite.Assert().Equal("inventory", users.ScopeName())
+	suite.Assert().Equal("users", users.CollectionName())
+}
+
+func (suite *UnitTestSuite) TestMutationStateAddResults() {
+	result := MutationResult{
+		mt: &MutationToken{
+			token: gocbcore.MutationToken{
+				VbID:   3,
+				VbUUID: gocbcore.VbUUID(7),
+				SeqNo:  gocbcore.SeqNo(42),
+			},
+			bucketName: "frank",
+		},
+	}
+
+	state := NewMutationState()
+	state.AddResults(result, MutationResult{})
+
+	suite.Require().Len(state.Internal().Tokens(), 1)
+	suite.Assert().Equal(*result.MutationToken(), state.Internal().Tokens()[0])
+}
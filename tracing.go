@@ -1,8 +1,10 @@
 package gocb
 
 import (
-	"github.com/couchbase/gocbcore/v10"
+	"sync/atomic"
 	"time"
+
+	"github.com/couchbase/gocbcore/v10"
 )
 
 func tracerAddRef(tracer RequestTracer) {
@@ -49,13 +51,21 @@ type coreRequestTracerWrapper struct {
 }
 
 func (tracer *coreRequestTracerWrapper) RequestSpan(parentContext gocbcore.RequestSpanContext, operationName string) gocbcore.RequestSpan {
+	var recorder *serverDurationRecorder
+	if sdCtx, ok := parentContext.(*serverDurationSpanContext); ok {
+		parentContext = sdCtx.parent
+		recorder = sdCtx.recorder
+	}
+
 	return &coreRequestSpanWrapper{
-		span: tracer.tracer.RequestSpan(parentContext, operationName),
+		span:           tracer.tracer.RequestSpan(parentContext, operationName),
+		serverDuration: recorder,
 	}
 }
 
 type coreRequestSpanWrapper struct {
-	span RequestSpan
+	span           RequestSpan
+	serverDuration *serverDurationRecorder
 }
 
 func (span *coreRequestSpanWrapper) End() {
@@ -63,13 +73,48 @@ func (span *coreRequestSpanWrapper) End() {
 }
 
 func (span *coreRequestSpanWrapper) Context() gocbcore.RequestSpanContext {
+	// gocbcore creates the dispatch spans, which carry the server duration, as children of the span for the operation,
+	// so the recorder must be passed on to them.
+	if span.serverDuration != nil {
+		return &serverDurationSpanContext{
+			parent:   span.span.Context(),
+			recorder: span.serverDuration,
+		}
+	}
+
 	return span.span.Context()
 }
 
 func (span *coreRequestSpanWrapper) SetAttribute(key string, value interface{}) {
+	if key == spanAttribServerDurationKey && span.serverDuration != nil {
+		if duration, ok := value.(time.Duration); ok {
+			span.serverDuration.record(duration)
+		}
+	}
+
 	span.span.SetAttribute(key, value)
 }
 
+// serverDurationRecorder captures the server duration which gocbcore reports on the dispatch spans of an operation.
+type serverDurationRecorder struct {
+	duration int64
+}
+
+func (r *serverDurationRecorder) record(duration time.Duration) {
+	atomic.StoreInt64(&r.duration, int64(duration))
+}
+
+func (r *serverDurationRecorder) get() time.Duration {
+	return time.Duration(atomic.LoadInt64(&r.duration))
+}
+
+// serverDurationSpanContext is passed to gocbcore as the parent of the spans that it creates for an operation, so
+// that the server duration set on them can be recorded. It is unwrapped before reaching the user's tracer.
+type serverDurationSpanContext struct {
+	parent   RequestSpanContext
+	recorder *serverDurationRecorder
+}
+
 func (span *coreRequestSpanWrapper) AddEvent(key string, timestamp time.Time) {
 	span.span.SetAttribute(key, timestamp)
 }
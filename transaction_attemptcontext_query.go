@@ -10,11 +10,16 @@ import (
 )
 
 // Query executes the query statement on the server.
+// If options does not specify a ScanConsistency then the ScanConsistency from TransactionsConfig.QueryConfig is used,
+// which defaults to QueryScanConsistencyRequestPlus.
 func (c *TransactionAttemptContext) Query(statement string, options *TransactionQueryOptions) (*TransactionQueryResult, error) {
 	var opts TransactionQueryOptions
 	if options != nil {
 		opts = *options
 	}
+	if opts.ScanConsistency == 0 {
+		opts.ScanConsistency = c.queryConfig.ScanConsistency
+	}
 	c.queryStateLock.Lock()
 	res, err := c.queryWrapperWrapper(opts.Scope, statement, opts.toSDKOptions(), "query", false, true,
 		nil)
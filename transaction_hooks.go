@@ -372,6 +372,22 @@ func (cthw *coreTxnsCleanupHooksWrapper) BeforeATRRemove(id []byte, cb func(erro
 	}()
 }
 
+// notifyingCleanupHooksWrapper wraps a set of cleanup hooks, notifying the transactions object each time cleanup
+// reaches the removal of an attempt from its ATR.
+type notifyingCleanupHooksWrapper struct {
+	transactionCleanupHooksWrapper
+	notify func(atrID string)
+}
+
+func (nhw *notifyingCleanupHooksWrapper) BeforeATRRemove(id []byte, cb func(error)) {
+	nhw.transactionCleanupHooksWrapper.BeforeATRRemove(id, func(err error) {
+		if err == nil {
+			nhw.notify(string(id))
+		}
+		cb(err)
+	})
+}
+
 type coreTxnsClientRecordHooksWrapper struct {
 	coreTxnsCleanupHooksWrapper
 	ClientRecordHooks TransactionClientRecordHooks
@@ -46,4 +46,23 @@ type TransactionResult struct {
 	// UnstagingComplete indicates whether the transaction was succesfully
 	// unstaged, or if a later cleanup job will be responsible.
 	UnstagingComplete bool
+
+	// Attempts holds the attempts made by the transaction, in the order that they were made. Attempts which failed
+	// due to a conflict with another transaction, or other transient errors, are retried automatically and so appear
+	// here along with the attempt which followed them.
+	// UNCOMMITTED: This API may change in the future.
+	Attempts []TransactionAttempt
+}
+
+// TransactionAttempt describes a single attempt made by a transaction.
+// UNCOMMITTED: This API may change in the future.
+type TransactionAttempt struct {
+	// ID is the UUID assigned to this attempt.
+	ID string
+
+	// State is the state that the attempt finished in.
+	State TransactionAttemptState
+
+	// Err is the error which caused the attempt to fail, or nil if it did not fail.
+	Err error
 }
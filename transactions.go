@@ -9,6 +9,10 @@ import (
 	"github.com/couchbase/gocbcore/v10"
 )
 
+// transactionCleanupEventQueueSize is the number of attempt cleanup events which can be queued for
+// CleanupConfig.OnAttemptCleanup before the cleanup process waits for the handler to catch up.
+const transactionCleanupEventQueueSize = 64
+
 // AttemptFunc represents the lambda used by the Transactions Run function.
 type AttemptFunc func(*TransactionAttemptContext) error
 
@@ -22,6 +26,15 @@ type Transactions struct {
 	hooksWrapper        transactionHooksWrapper
 	cleanupHooksWrapper transactionCleanupHooksWrapper
 	cleanupCollections  []gocbcore.TransactionLostATRLocation
+	atrLocation         gocbcore.TransactionATRLocation
+
+	closeLock sync.Mutex
+	closed    bool
+	closeCh   chan struct{}
+
+	// cleanupEvents queues the events passed to CleanupConfig.OnAttemptCleanup, which is called from its own
+	// goroutine rather than from the cleanup process.
+	cleanupEvents chan TransactionCleanupEvent
 }
 
 // initTransactions will initialize the transactions library and return a Transactions
@@ -29,6 +42,11 @@ type Transactions struct {
 func (c *Cluster) initTransactions(config TransactionsConfig) (*Transactions, error) {
 	// Note that gocbcore will handle a lot of default values for us.
 
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
 	if config.QueryConfig.ScanConsistency == 0 {
 		config.QueryConfig.ScanConsistency = QueryScanConsistencyRequestPlus
 	}
@@ -116,6 +134,17 @@ func (c *Cluster) initTransactions(config TransactionsConfig) (*Transactions, er
 		hooksWrapper:        hooksWrapper,
 		cleanupHooksWrapper: cleanupHooksWrapper,
 		cleanupCollections:  cleanupLocs,
+		atrLocation:         atrLocation,
+		closeCh:             make(chan struct{}),
+	}
+
+	if config.CleanupConfig.OnAttemptCleanup != nil {
+		t.cleanupEvents = make(chan TransactionCleanupEvent, transactionCleanupEventQueueSize)
+		cleanupHooksWrapper = &notifyingCleanupHooksWrapper{
+			transactionCleanupHooksWrapper: cleanupHooksWrapper,
+			notify:                         t.notifyAttemptCleanup,
+		}
+		t.cleanupHooksWrapper = cleanupHooksWrapper
 	}
 
 	corecfg := &gocbcore.TransactionsConfig{}
@@ -141,6 +170,10 @@ func (c *Cluster) initTransactions(config TransactionsConfig) (*Transactions, er
 	}
 
 	t.txns = txns
+	if t.cleanupEvents != nil {
+		go t.dispatchCleanupEvents()
+	}
+
 	return t, nil
 }
 
@@ -148,10 +181,16 @@ func (c *Cluster) initTransactions(config TransactionsConfig) (*Transactions, er
 // singular transaction.
 func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (*TransactionResult, error) {
 	if perConfig == nil {
-		perConfig = &TransactionOptions{
-			DurabilityLevel: t.config.DurabilityLevel,
-			Timeout:         t.config.Timeout,
-		}
+		perConfig = &TransactionOptions{}
+	}
+
+	durabilityLevel := perConfig.DurabilityLevel
+	if durabilityLevel == DurabilityLevelUnknown {
+		durabilityLevel = t.config.DurabilityLevel
+	}
+	timeout := perConfig.Timeout
+	if timeout == 0 {
+		timeout = t.config.Timeout
 	}
 
 	scanConsistency := t.config.QueryConfig.ScanConsistency
@@ -169,10 +208,21 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 		atrLocation.ScopeName = perConfig.MetadataCollection.ScopeName()
 	}
 
+	metadataLocation := t.atrLocation
+	if atrLocation.Agent != nil {
+		metadataLocation = atrLocation
+	}
+	if metadataLocation.Agent != nil {
+		err := t.verifyMetadataCollection(metadataLocation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO: fill in the rest of this config
 	txn, err := t.txns.BeginTransaction(&gocbcore.TransactionOptions{
-		DurabilityLevel:   gocbcore.TransactionDurabilityLevel(perConfig.DurabilityLevel),
-		ExpirationTime:    perConfig.Timeout,
+		DurabilityLevel:   gocbcore.TransactionDurabilityLevel(durabilityLevel),
+		ExpirationTime:    timeout,
 		CustomATRLocation: atrLocation,
 	})
 	if err != nil {
@@ -196,6 +246,7 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 		return time.Duration(backoff)
 	}
 
+	var attempts []TransactionAttempt
 	for {
 		err = txn.NewAttempt()
 		if err != nil {
@@ -254,6 +305,11 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 		}
 
 		a := attempt.attempt()
+		attempts = append(attempts, TransactionAttempt{
+			ID:    txn.Attempt().ID,
+			State: a.State,
+			Err:   finalErrCause,
+		})
 
 		if !a.Expired && attempt.shouldRetry() && !wasUserError {
 			logDebugf("retrying lambda after backoff")
@@ -274,6 +330,7 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 			if finalErr == nil && !autoRollback {
 				return &TransactionResult{
 					TransactionID: txn.ID(),
+					Attempts:      attempts,
 				}, nil
 			}
 
@@ -282,6 +339,7 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 					result: &TransactionResult{
 						TransactionID:     txn.ID(),
 						UnstagingComplete: false,
+						Attempts:          attempts,
 					},
 				}
 			}
@@ -291,6 +349,7 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 				result: &TransactionResult{
 					TransactionID:     txn.ID(),
 					UnstagingComplete: false,
+					Attempts:          attempts,
 				},
 			}
 		case TransactionAttemptStateCommitting:
@@ -299,6 +358,7 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 				result: &TransactionResult{
 					TransactionID:     txn.ID(),
 					UnstagingComplete: false,
+					Attempts:          attempts,
 				},
 			}
 		case TransactionAttemptStateCommitted:
@@ -309,6 +369,7 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 			return &TransactionResult{
 				TransactionID:     txn.ID(),
 				UnstagingComplete: unstagingComplete,
+				Attempts:          attempts,
 			}, nil
 		default:
 			return nil, errors.New("invalid final transaction state")
@@ -316,6 +377,36 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 	}
 }
 
+// verifyMetadataCollection checks that the collection used for storing transaction metadata exists, so that
+// a missing collection is reported up front rather than as a failure part way through the transaction.
+func (t *Transactions) verifyMetadataCollection(location gocbcore.TransactionATRLocation) error {
+	if (location.ScopeName == "" || location.ScopeName == "_default") &&
+		(location.CollectionName == "" || location.CollectionName == "_default") {
+		return nil
+	}
+
+	opm := newAsyncOpManager(nil)
+	var errOut error
+	err := opm.Wait(location.Agent.GetCollectionID(location.ScopeName, location.CollectionName,
+		gocbcore.GetCollectionIDOptions{
+			RetryStrategy: t.cluster.retryStrategyWrapper,
+			Deadline:      time.Now().Add(t.cluster.timeoutsConfig.KVTimeout),
+		}, func(result *gocbcore.GetCollectionIDResult, err error) {
+			if err != nil {
+				errOut = wrapError(maybeEnhanceCoreErr(err), "failed to verify transactions metadata collection")
+				opm.Reject()
+				return
+			}
+
+			opm.Resolve()
+		}))
+	if err != nil {
+		errOut = err
+	}
+
+	return errOut
+}
+
 //
 // func (t *Transactions) Query(statement string, options *SingleQueryTransactionConfig) (*SingleQueryTransactionResult, error) {
 // 	if options == nil {
@@ -343,12 +434,64 @@ func (t *Transactions) Run(logicFn AttemptFunc, perConfig *TransactionOptions) (
 // 	return &qResult, nil
 // }
 
-// Close will shut down this Transactions object, shutting down all
-// background tasks associated with it.
-func (t *Transactions) close() error {
+// Close will shut down this Transactions object, shutting down all background cleanup tasks associated with it.
+// Once Close has returned no further calls are made to CleanupConfig.OnAttemptCleanup, although a call which is
+// already in progress may still be running. Close can be called from OnAttemptCleanup.
+// Close is called as a part of Cluster.Close and is safe to call more than once.
+// UNCOMMITTED: This API may change in the future.
+func (t *Transactions) Close() error {
+	if !t.stopCleanupEvents() {
+		return nil
+	}
+
 	return t.txns.Close()
 }
 
+func (t *Transactions) close() error {
+	return t.Close()
+}
+
+// stopCleanupEvents stops the delivery of attempt cleanup events, returning false if they were already stopped.
+func (t *Transactions) stopCleanupEvents() bool {
+	t.closeLock.Lock()
+	defer t.closeLock.Unlock()
+
+	if t.closed {
+		return false
+	}
+	t.closed = true
+	close(t.closeCh)
+
+	return true
+}
+
+// notifyAttemptCleanup queues an attempt cleanup event for the user's handler, unless Close has been called.
+func (t *Transactions) notifyAttemptCleanup(atrID string) {
+	select {
+	case t.cleanupEvents <- TransactionCleanupEvent{AtrID: atrID}:
+	case <-t.closeCh:
+	}
+}
+
+// dispatchCleanupEvents passes the queued attempt cleanup events to the user's handler until Close is called. The
+// handler is not called whilst any lock is held, so that it can call Close itself.
+func (t *Transactions) dispatchCleanupEvents() {
+	for {
+		select {
+		case event := <-t.cleanupEvents:
+			select {
+			case <-t.closeCh:
+				return
+			default:
+			}
+
+			t.config.CleanupConfig.OnAttemptCleanup(event)
+		case <-t.closeCh:
+			return
+		}
+	}
+}
+
 func (t *Transactions) agentProvider(bucketName string) (*gocbcore.Agent, string, error) {
 	b := t.cluster.Bucket(bucketName)
 	agent, err := b.Internal().IORouter()
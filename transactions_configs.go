@@ -25,19 +25,37 @@ type TransactionsCleanupConfig struct {
 	// CleanupCollections is a set of extra collections that should be monitored
 	// by the cleanup thread.
 	CleanupCollections []TransactionKeyspace
+
+	// OnAttemptCleanup, if set, is called each time the cleanup process finishes cleaning up the documents of a
+	// transaction attempt and is about to remove the attempt from its active transaction record. This covers
+	// both lost attempts and, unless DisableClientAttemptCleanup is set, attempts made by this client.
+	// It is called from its own goroutine, one event at a time, and may call Transactions.Close. The cleanup process
+	// waits for the handler once many events are queued, so it should return promptly.
+	// UNCOMMITTED: This API may change in the future.
+	OnAttemptCleanup func(TransactionCleanupEvent)
+}
+
+// TransactionCleanupEvent describes a transaction attempt which has been cleaned up by the cleanup process.
+// UNCOMMITTED: This API may change in the future.
+type TransactionCleanupEvent struct {
+	// AtrID is the ID of the active transaction record which the attempt belonged to.
+	AtrID string
 }
 
 // TransactionsConfig specifies various tunable options related to transactions.
+// These values act as the defaults for every transaction run by the cluster, any value set on TransactionOptions
+// will take precedence over the value set here for that transaction only.
 type TransactionsConfig struct {
 	// MetadataCollection specifies a specific location to place meta-data.
 	MetadataCollection *TransactionKeyspace
 
-	// ExpirationTimout sets the maximum time that transactions created
+	// Timeout sets the maximum time that transactions created
 	// by this Transactions object can run for, before expiring.
 	Timeout time.Duration
 
 	// DurabilityLevel specifies the durability level that should be used
 	// for all write operations performed by this Transactions object.
+	// Defaults to DurabilityLevelMajority.
 	DurabilityLevel DurabilityLevel
 
 	// QueryConfig specifies any query configuration to use in transactions.
@@ -57,6 +75,7 @@ type TransactionsConfig struct {
 }
 
 // TransactionOptions specifies options which can be overridden on a per transaction basis.
+// Any value left unset will fall back to the value specified in TransactionsConfig.
 type TransactionOptions struct {
 	// DurabilityLevel specifies the durability level that should be used
 	// for all write operations performed by this transaction.
@@ -69,8 +88,36 @@ type TransactionOptions struct {
 	MetadataCollection *Collection
 }
 
+func (config *TransactionsConfig) validate() error {
+	if config.Timeout < 0 {
+		return makeInvalidArgumentsError("transactions timeout cannot be negative")
+	}
+
+	if config.DurabilityLevel > DurabilityLevelPersistToMajority {
+		return makeInvalidArgumentsError("transactions durability level is not valid")
+	}
+
+	if config.MetadataCollection != nil {
+		err := config.MetadataCollection.validate()
+		if err != nil {
+			return wrapError(err, "invalid transactions metadata collection")
+		}
+	}
+
+	for _, keyspace := range config.CleanupConfig.CleanupCollections {
+		err := keyspace.validate()
+		if err != nil {
+			return wrapError(err, "invalid transactions cleanup collection")
+		}
+	}
+
+	return nil
+}
+
 // TransactionsQueryConfig specifies various tunable query options related to transactions.
 type TransactionsQueryConfig struct {
+	// ScanConsistency specifies the default scan consistency used for queries within a transaction,
+	// defaults to QueryScanConsistencyRequestPlus.
 	ScanConsistency QueryScanConsistency
 }
 
@@ -89,9 +136,23 @@ type TransactionKeyspace struct {
 	CollectionName string
 }
 
+func (keyspace TransactionKeyspace) validate() error {
+	if keyspace.BucketName == "" {
+		return makeInvalidArgumentsError("bucket name cannot be empty")
+	}
+
+	if (keyspace.ScopeName == "") != (keyspace.CollectionName == "") {
+		return makeInvalidArgumentsError("scope name and collection name must either both be set or both be empty")
+	}
+
+	return nil
+}
+
 // TransactionQueryOptions specifies the set of options available when running queries as a part of a transaction.
 // This is a subset of QueryOptions.
 type TransactionQueryOptions struct {
+	// ScanConsistency specifies the scan consistency for this statement only, if not set then the value from
+	// TransactionsConfig.QueryConfig is used.
 	ScanConsistency QueryScanConsistency
 	Profile         QueryProfileMode
 
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/couchbase/gocbcore/v10"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -612,3 +614,135 @@ func (suite *UnitTestSuite) TestTransactionsQueryGocbcoreCauseError() {
 	err = cluster.Close(nil)
 	suite.Require().Nil(err, err)
 }
+
+func (suite *UnitTestSuite) TestTransactionQueryOptionsScanConsistency() {
+	opts := TransactionQueryOptions{}
+	suite.Assert().Equal(QueryScanConsistencyRequestPlus, opts.toSDKOptions().ScanConsistency)
+
+	opts.ScanConsistency = QueryScanConsistencyNotBounded
+	suite.Assert().Equal(QueryScanConsistencyNotBounded, opts.toSDKOptions().ScanConsistency)
+
+	var beginWorkDataset struct {
+		jsonQueryResponse
+	}
+	err := loadJSONTestDataset("transaction_begin_work_response", &beginWorkDataset)
+	suite.Require().Nil(err, err)
+
+	reader := &mockQueryRowReader{
+		Dataset: []testBreweryDocument{},
+		mockQueryRowReaderBase: mockQueryRowReaderBase{
+			Meta: suite.mustConvertToBytes(beginWorkDataset.jsonQueryResponse),
+		},
+	}
+
+	// The scan consistency sent with each statement, keyed by the statement.
+	scanConsistencies := make(map[string]interface{})
+	queryProvider := new(mockQueryProvider)
+	queryProvider.
+		On("N1QLQuery", nil, mock.AnythingOfType("gocbcore.N1QLQueryOptions")).
+		Run(func(args mock.Arguments) {
+			opts := args.Get(1).(gocbcore.N1QLQueryOptions)
+
+			var payload map[string]interface{}
+			err := json.Unmarshal(opts.Payload, &payload)
+			suite.Require().Nil(err, err)
+
+			statement, _ := payload["statement"].(string)
+			scanConsistencies[statement] = payload["scan_consistency"]
+		}).
+		Return(reader, nil)
+
+	cli := new(mockConnectionManager)
+	cli.On("getQueryProvider").Return(queryProvider, nil)
+	cli.On("close").Return(nil)
+
+	cluster := suite.newCluster(cli)
+	cluster.transactions, err = cluster.initTransactions(TransactionsConfig{
+		QueryConfig: TransactionsQueryConfig{
+			ScanConsistency: QueryScanConsistencyNotBounded,
+		},
+		CleanupConfig: TransactionsCleanupConfig{
+			DisableLostAttemptCleanup: true,
+		},
+	})
+	suite.Require().Nil(err, err)
+
+	_, err = cluster.Transactions().Run(func(ctx *TransactionAttemptContext) error {
+		_, err := ctx.Query("SELECT 1=1", nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = ctx.Query("SELECT 2=2", &TransactionQueryOptions{
+			ScanConsistency: QueryScanConsistencyRequestPlus,
+		})
+		return err
+	}, nil)
+	suite.Require().Nil(err, err)
+
+	// Statements without a scan consistency fall back to the one configured for transactions.
+	suite.Assert().Equal("not_bounded", scanConsistencies["BEGIN WORK"])
+	suite.Assert().Equal("not_bounded", scanConsistencies["SELECT 1=1"])
+	suite.Assert().Equal("request_plus", scanConsistencies["SELECT 2=2"])
+
+	err = cluster.Close(nil)
+	suite.Require().Nil(err, err)
+}
+
+func (suite *IntegrationTestSuite) TestTransactionsQueryFirstThenKVAttempts() {
+	suite.skipIfUnsupported(TransactionsFeature)
+	suite.skipIfUnsupported(TransactionsQueryFeature)
+
+	docID := "queryfirstthenkv"
+	docValue := map[string]interface{}{
+		"test": "test",
+	}
+
+	txns := globalCluster.Cluster.Transactions()
+
+	txnRes, err := txns.Run(func(ctx *TransactionAttemptContext) error {
+		_, err := ctx.Query(fmt.Sprintf("INSERT INTO `%s` VALUES ('%s', {\"test\": \"test\"})", globalCollection.Name(), docID),
+			&TransactionQueryOptions{
+				Scope: globalScope,
+			})
+		if err != nil {
+			return err
+		}
+
+		getRes, err := ctx.Get(globalCollection, docID)
+		if err != nil {
+			return err
+		}
+
+		var actualDocValue map[string]interface{}
+		err = getRes.Content(&actualDocValue)
+		if err != nil {
+			return err
+		}
+		suite.Assert().Equal(docValue, actualDocValue)
+
+		return nil
+	}, nil)
+	suite.Require().NoError(err, err)
+
+	suite.Require().NotEmpty(txnRes.Attempts)
+	lastAttempt := txnRes.Attempts[len(txnRes.Attempts)-1]
+	suite.Assert().NotEmpty(lastAttempt.ID)
+	suite.Assert().Nil(lastAttempt.Err)
+
+	suite.verifyDocument(docID, docValue)
+
+	userErr := errors.New("user error")
+	_, err = txns.Run(func(ctx *TransactionAttemptContext) error {
+		_, err := ctx.Query("SELECT 1=1", nil)
+		if err != nil {
+			return err
+		}
+
+		return userErr
+	}, nil)
+	var failedErr *TransactionFailedError
+	suite.Require().True(errors.As(err, &failedErr), "expected transaction failed error but was %v", err)
+	suite.Require().Len(failedErr.Attempts(), 1)
+	suite.Assert().True(errors.Is(failedErr.Attempts()[0].Err, userErr))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/couchbase/gocbcore/v10"
 	"log"
+	"testing"
 	"time"
 )
 
@@ -56,6 +57,24 @@ func (suite *IntegrationTestSuite) TestTransactionsDoubleInsert() {
 	}
 }
 
+func (suite *IntegrationTestSuite) TestTransactionsMetadataCollectionNotFound() {
+	suite.skipIfUnsupported(TransactionsFeature)
+	suite.skipIfUnsupported(CollectionsFeature)
+
+	txns := globalCluster.Cluster.Transactions()
+
+	var lambdaCalled bool
+	txnRes, err := txns.Run(func(ctx *TransactionAttemptContext) error {
+		lambdaCalled = true
+		return nil
+	}, &TransactionOptions{
+		MetadataCollection: globalBucket.Scope(globalScope.Name()).Collection("txnsmetadatamissing"),
+	})
+	suite.Assert().Nil(txnRes)
+	suite.Assert().ErrorIs(err, ErrCollectionNotFound)
+	suite.Assert().False(lambdaCalled)
+}
+
 func (suite *IntegrationTestSuite) TestTransactionsInsert() {
 	suite.skipIfUnsupported(TransactionsFeature)
 
@@ -1175,3 +1194,136 @@ func (suite *IntegrationTestSuite) runTranasctionLoadTest(grps []transactionTest
 		}
 	}
 }
+
+func (suite *UnitTestSuite) TestTransactionsConfigValidation() {
+	type tCase struct {
+		name   string
+		config TransactionsConfig
+		valid  bool
+	}
+
+	testCases := []tCase{
+		{
+			name:   "empty",
+			config: TransactionsConfig{},
+			valid:  true,
+		},
+		{
+			name: "full",
+			config: TransactionsConfig{
+				MetadataCollection: &TransactionKeyspace{
+					BucketName:     "default",
+					ScopeName:      "txns",
+					CollectionName: "meta",
+				},
+				Timeout:         10 * time.Second,
+				DurabilityLevel: DurabilityLevelPersistToMajority,
+			},
+			valid: true,
+		},
+		{
+			name: "default collection",
+			config: TransactionsConfig{
+				MetadataCollection: &TransactionKeyspace{
+					BucketName: "default",
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "negative timeout",
+			config: TransactionsConfig{
+				Timeout: -1 * time.Second,
+			},
+		},
+		{
+			name: "invalid durability",
+			config: TransactionsConfig{
+				DurabilityLevel: DurabilityLevelPersistToMajority + 1,
+			},
+		},
+		{
+			name: "missing bucket",
+			config: TransactionsConfig{
+				MetadataCollection: &TransactionKeyspace{
+					ScopeName:      "txns",
+					CollectionName: "meta",
+				},
+			},
+		},
+		{
+			name: "missing collection",
+			config: TransactionsConfig{
+				MetadataCollection: &TransactionKeyspace{
+					BucketName: "default",
+					ScopeName:  "txns",
+				},
+			},
+		},
+		{
+			name: "invalid cleanup collection",
+			config: TransactionsConfig{
+				CleanupConfig: TransactionsCleanupConfig{
+					CleanupCollections: []TransactionKeyspace{{CollectionName: "meta"}},
+				},
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		suite.T().Run(tCase.name, func(te *testing.T) {
+			err := tCase.config.validate()
+			if tCase.valid {
+				if err != nil {
+					te.Fatalf("Expected config to be valid but was %v", err)
+				}
+			} else if !errors.Is(err, ErrInvalidArgument) {
+				te.Fatalf("Expected invalid argument error but was %v", err)
+			}
+		})
+	}
+}
+
+func (suite *UnitTestSuite) TestTransactionsOnAttemptCleanup() {
+	events := make(chan TransactionCleanupEvent, 2)
+	txns := &Transactions{
+		closeCh:       make(chan struct{}),
+		cleanupEvents: make(chan TransactionCleanupEvent, 1),
+	}
+	txns.config.CleanupConfig.OnAttemptCleanup = func(event TransactionCleanupEvent) {
+		// The handler can stop the events itself without deadlocking.
+		suite.Assert().True(txns.stopCleanupEvents())
+
+		events <- event
+	}
+	go txns.dispatchCleanupEvents()
+
+	wrapper := &notifyingCleanupHooksWrapper{
+		transactionCleanupHooksWrapper: &noopCleanupHooksWrapper{},
+		notify:                         txns.notifyAttemptCleanup,
+	}
+
+	removeATR := func(id string) error {
+		waitCh := make(chan error, 1)
+		wrapper.BeforeATRRemove([]byte(id), func(err error) {
+			waitCh <- err
+		})
+		return <-waitCh
+	}
+
+	suite.Require().Nil(removeATR("_txn:atr-1-#1"))
+	select {
+	case event := <-events:
+		suite.Assert().Equal(TransactionCleanupEvent{AtrID: "_txn:atr-1-#1"}, event)
+	case <-time.After(5 * time.Second):
+		suite.T().Fatalf("Timed out waiting for cleanup event")
+	}
+
+	// Once stopped, cleanup is never blocked by events which are not delivered.
+	suite.Require().Nil(removeATR("_txn:atr-2-#2"))
+	suite.Require().Nil(removeATR("_txn:atr-3-#3"))
+	suite.Assert().False(txns.stopCleanupEvents())
+
+	time.Sleep(50 * time.Millisecond)
+	suite.Assert().Empty(events)
+}
@@ -3,6 +3,7 @@ package gocb
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	gocbcore "github.com/couchbase/gocbcore/v10"
 )
@@ -17,42 +18,162 @@ type Transcoder interface {
 	Encode(interface{}) ([]byte, uint32, error)
 }
 
+// DataTypeMismatchError occurs when a Transcoder is asked to decode a document which was stored with a data type
+// that it does not support, such as a JSON document being decoded by RawStringTranscoder.
+// UNCOMMITTED: This API may change in the future.
+type DataTypeMismatchError struct {
+	// Transcoder is the name of the transcoder which could not decode the document.
+	Transcoder string
+	// Expected is the data type which the transcoder supports.
+	Expected string
+	// Actual is the data type which the document was stored with, as indicated by Flags.
+	Actual string
+	// Flags are the flags which the document was stored with.
+	Flags uint32
+}
+
+// Error returns the string representation of this error.
+func (e *DataTypeMismatchError) Error() string {
+	return fmt.Sprintf("document is %s (flags 0x%08x) but %s expects %s: %s",
+		e.Actual, e.Flags, e.Transcoder, e.Expected, ErrDecodingFailure.Error())
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *DataTypeMismatchError) Unwrap() error {
+	return ErrDecodingFailure
+}
+
+func commonFlagsDataTypeName(flags uint32) string {
+	return DocumentFormatFromFlags(flags).String()
+}
+
+func newDataTypeMismatchError(transcoder, expected string, flags uint32) *DataTypeMismatchError {
+	return &DataTypeMismatchError{
+		Transcoder: transcoder,
+		Expected:   expected,
+		Actual:     commonFlagsDataTypeName(flags),
+		Flags:      flags,
+	}
+}
+
 // JSONTranscoder implements the default transcoding behavior and applies JSON transcoding to all values.
 //
 // This will apply the following behavior to the value:
 // binary ([]byte) -> error.
 // default -> JSON value, JSON Flags.
+//
+// Values are encoded and decoded using encoding/json, or the JSONSerializer passed to
+// NewJSONTranscoderWithSerializer, unless the type of the value has been registered using RegisterType or
+// SetTimeFormat.
+// If a JSON document cannot be decoded then a *JSONDecodeError is returned, holding the raw bytes of the document,
+// unless a fallback has been set using SetDecodeFallback.
 type JSONTranscoder struct {
+	state      *jsonTranscoderState
+	serializer JSONSerializer
+}
+
+// JSONDecodeFallbackFunc is called by JSONTranscoder when a JSON document cannot be decoded, with the raw bytes of the
+// document, the value which was being decoded into and the error which occurred. The error it returns, if any, is
+// returned in place of the decoding error, allowing malformed documents to be handled, e.g. by decoding them into a
+// placeholder value, rather than failing the operation.
+// UNCOMMITTED: This API may change in the future.
+type JSONDecodeFallbackFunc func(data []byte, out interface{}, err error) error
+
+// JSONDecodeError occurs when JSONTranscoder fails to decode a document which is stored as JSON, usually because
+// the document is not valid JSON or does not match the type being decoded into.
+// UNCOMMITTED: This API may change in the future.
+type JSONDecodeError struct {
+	// Raw is the raw bytes of the document which could not be decoded.
+	Raw []byte
+	// InnerError is the error returned by the JSON decoder.
+	InnerError error
+}
+
+// Error returns the string representation of this error.
+func (e *JSONDecodeError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDecodingFailure.Error(), e.InnerError.Error())
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *JSONDecodeError) Unwrap() error {
+	return e.InnerError
+}
+
+// Is returns true if target is ErrDecodingFailure, so that decoding failures can be detected with errors.Is.
+func (e *JSONDecodeError) Is(target error) bool {
+	return target == ErrDecodingFailure
 }
 
 // NewJSONTranscoder returns a new JSONTranscoder.
 func NewJSONTranscoder() *JSONTranscoder {
-	return &JSONTranscoder{}
+	return &JSONTranscoder{
+		state: newJSONTranscoderState(),
+	}
+}
+
+// NewJSONTranscoderWithSerializer returns a new JSONTranscoder which encodes and decodes values using serializer.
+// UNCOMMITTED: This API may change in the future.
+func NewJSONTranscoderWithSerializer(serializer JSONSerializer) *JSONTranscoder {
+	return &JSONTranscoder{
+		state:      newJSONTranscoderState(),
+		serializer: serializer,
+	}
 }
 
 // Decode applies JSON transcoding behaviour to decode into a Go type.
+// Documents which are not JSON return a *DataTypeMismatchError, unless out is a *RawDocument.
 func (t *JSONTranscoder) Decode(bytes []byte, flags uint32, out interface{}) error {
-	valueType, compression := gocbcore.DecodeCommonFlags(flags)
+	if decodeRawDocument(bytes, flags, out) {
+		return nil
+	}
+
+	_, compression := gocbcore.DecodeCommonFlags(flags)
 
 	// Make sure compression is disabled
 	if compression != gocbcore.NoCompression {
 		return errors.New("unexpected value compression")
 	}
 
-	// Normal types of decoding
-	if valueType == gocbcore.BinaryType {
-		return errors.New("binary datatype is not supported by JSONTranscoder")
-	} else if valueType == gocbcore.StringType {
-		return errors.New("string datatype is not supported by JSONTranscoder")
-	} else if valueType == gocbcore.JSONType {
-		err := json.Unmarshal(bytes, &out)
-		if err != nil {
-			return err
+	if DocumentFormatFromFlags(flags) != DocumentFormatJSON {
+		return newDataTypeMismatchError("JSONTranscoder", DocumentFormatJSON.String(), flags)
+	}
+
+	err := t.unmarshal(bytes, out)
+	if err != nil {
+		if fallback := t.getDecodeFallback(); fallback != nil {
+			return fallback(bytes, out, err)
+		}
+		return &JSONDecodeError{
+			Raw:        bytes,
+			InnerError: err,
 		}
+	}
+
+	return nil
+}
+
+// SetDecodeFallback sets a function to be called when a JSON document cannot be decoded, replacing any existing
+// fallback. Passing nil removes the fallback, so that a *JSONDecodeError is returned instead.
+// UNCOMMITTED: This API may change in the future.
+func (t *JSONTranscoder) SetDecodeFallback(fallback JSONDecodeFallbackFunc) {
+	if t.state == nil {
+		t.state = newJSONTranscoderState()
+	}
+
+	t.state.lock.Lock()
+	t.state.decodeFallback = fallback
+	t.state.lock.Unlock()
+}
+
+func (t *JSONTranscoder) getDecodeFallback() JSONDecodeFallbackFunc {
+	if t.state == nil {
 		return nil
 	}
 
-	return errors.New("unexpected expectedFlags value")
+	t.state.lock.RLock()
+	defer t.state.lock.RUnlock()
+
+	return t.state.decodeFallback
 }
 
 // Encode applies JSON transcoding behaviour to encode a Go type.
@@ -75,7 +196,7 @@ func (t *JSONTranscoder) Encode(value interface{}) ([]byte, uint32, error) {
 	case *interface{}:
 		return t.Encode(*typeValue)
 	default:
-		bytes, err = json.Marshal(value)
+		bytes, err = t.marshal(value)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -112,11 +233,7 @@ func (t *RawJSONTranscoder) Decode(bytes []byte, flags uint32, out interface{})
 	}
 
 	// Normal types of decoding
-	if valueType == gocbcore.BinaryType {
-		return errors.New("binary datatype is not supported by RawJSONTranscoder")
-	} else if valueType == gocbcore.StringType {
-		return errors.New("string datatype is not supported by RawJSONTranscoder")
-	} else if valueType == gocbcore.JSONType {
+	if valueType == gocbcore.JSONType {
 		switch typedOut := out.(type) {
 		case *[]byte:
 			*typedOut = bytes
@@ -129,7 +246,7 @@ func (t *RawJSONTranscoder) Decode(bytes []byte, flags uint32, out interface{})
 		}
 	}
 
-	return errors.New("unexpected expectedFlags value")
+	return newDataTypeMismatchError("RawJSONTranscoder", DocumentFormatJSON.String(), flags)
 }
 
 // Encode applies raw JSON transcoding behaviour to encode a Go type.
@@ -190,9 +307,7 @@ func (t *RawStringTranscoder) Decode(bytes []byte, flags uint32, out interface{}
 	}
 
 	// Normal types of decoding
-	if valueType == gocbcore.BinaryType {
-		return errors.New("only string datatype is supported by RawStringTranscoder")
-	} else if valueType == gocbcore.StringType {
+	if valueType == gocbcore.StringType {
 		switch typedOut := out.(type) {
 		case *string:
 			*typedOut = string(bytes)
@@ -203,11 +318,9 @@ func (t *RawStringTranscoder) Decode(bytes []byte, flags uint32, out interface{}
 		default:
 			return errors.New("you must encode a string in a string or interface")
 		}
-	} else if valueType == gocbcore.JSONType {
-		return errors.New("only string datatype is supported by RawStringTranscoder")
 	}
 
-	return errors.New("unexpected expectedFlags value")
+	return newDataTypeMismatchError("RawStringTranscoder", DocumentFormatString.String(), flags)
 }
 
 // Encode applies raw string transcoding behaviour to encode a Go type.
@@ -267,13 +380,9 @@ func (t *RawBinaryTranscoder) Decode(bytes []byte, flags uint32, out interface{}
 		default:
 			return errors.New("you must encode binary in a byte array or interface")
 		}
-	} else if valueType == gocbcore.StringType {
-		return errors.New("only binary datatype is supported by RawBinaryTranscoder")
-	} else if valueType == gocbcore.JSONType {
-		return errors.New("only binary datatype is supported by RawBinaryTranscoder")
 	}
 
-	return errors.New("unexpected expectedFlags value")
+	return newDataTypeMismatchError("RawBinaryTranscoder", DocumentFormatBinary.String(), flags)
 }
 
 // Encode applies raw binary transcoding behaviour to encode a Go type.
@@ -315,7 +424,12 @@ func NewLegacyTranscoder() *LegacyTranscoder {
 }
 
 // Decode applies legacy transcoding behaviour to decode into a Go type.
+// Documents of any format can be read without being decoded by passing a *RawDocument as out.
 func (t *LegacyTranscoder) Decode(bytes []byte, flags uint32, out interface{}) error {
+	if decodeRawDocument(bytes, flags, out) {
+		return nil
+	}
+
 	valueType, compression := gocbcore.DecodeCommonFlags(flags)
 
 	// Make sure compression is disabled
@@ -354,7 +468,7 @@ func (t *LegacyTranscoder) Decode(bytes []byte, flags uint32, out interface{}) e
 		return nil
 	}
 
-	return errors.New("unexpected expectedFlags value")
+	return newDataTypeMismatchError("LegacyTranscoder", "json, string or binary", flags)
 }
 
 // Encode applies legacy transcoding behavior to encode a Go type.
@@ -396,3 +510,57 @@ func (t *LegacyTranscoder) Encode(value interface{}) ([]byte, uint32, error) {
 
 	return bytes, flags, nil
 }
+
+// RawFlagsTranscoder implements passthrough behaviour of documents with arbitrary flags. It is intended only for
+// interoperating with clients which do not use the common flags format, such as older clients which store values
+// with their own flags, e.g. for Java-serialized or compressed values. This transcoder does not apply any
+// serialization and does not check the flags, which are written exactly as given, so documents which it writes may
+// not be readable by other SDKs.
+//
+// This will apply the following behavior to the value:
+// RawDocument -> Value bytes, Flags as given, Format is ignored.
+// default -> error.
+//
+// When decoding, a *RawDocument receives the bytes and flags of the document, and a *[]byte or *interface{} receives
+// the bytes, whatever the flags.
+// UNCOMMITTED: This API may change in the future.
+type RawFlagsTranscoder struct {
+}
+
+// NewRawFlagsTranscoder returns a new RawFlagsTranscoder.
+// UNCOMMITTED: This API may change in the future.
+func NewRawFlagsTranscoder() *RawFlagsTranscoder {
+	return &RawFlagsTranscoder{}
+}
+
+// Decode applies raw flags transcoding behaviour to decode into a Go type.
+func (t *RawFlagsTranscoder) Decode(bytes []byte, flags uint32, out interface{}) error {
+	if decodeRawDocument(bytes, flags, out) {
+		return nil
+	}
+
+	switch typedOut := out.(type) {
+	case *[]byte:
+		*typedOut = bytes
+		return nil
+	case *interface{}:
+		*typedOut = bytes
+		return nil
+	default:
+		return errors.New("you must decode raw flags documents into a RawDocument, byte array or interface")
+	}
+}
+
+// Encode applies raw flags transcoding behaviour to encode a Go type.
+func (t *RawFlagsTranscoder) Encode(value interface{}) ([]byte, uint32, error) {
+	switch typeValue := value.(type) {
+	case RawDocument:
+		return typeValue.Value, typeValue.Flags, nil
+	case *RawDocument:
+		return typeValue.Value, typeValue.Flags, nil
+	case *interface{}:
+		return t.Encode(*typeValue)
+	default:
+		return nil, 0, makeInvalidArgumentsError("only RawDocument values are supported by RawFlagsTranscoder")
+	}
+}
@@ -0,0 +1,539 @@
+package gocb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/couchbase/gocb/v2/gocbfieldcrypt"
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+const (
+	// FieldEncryptionAlgorithmAES256CBCHMACSHA512 is the identifier of the AEAD_AES_256_CBC_HMAC_SHA512 algorithm
+	// as used within the cross-SDK field level encryption format.
+	FieldEncryptionAlgorithmAES256CBCHMACSHA512 = gocbfieldcrypt.AlgorithmAEADAES256CBCHMACSHA512
+
+	// DefaultFieldEncryptionPrefix is the prefix applied to the names of encrypted fields.
+	DefaultFieldEncryptionPrefix = "encrypted$"
+)
+
+// FieldEncryptionKeyring provides the keys used for field level encryption.
+// UNCOMMITTED: This API may change in the future.
+type FieldEncryptionKeyring interface {
+	// GetKey returns the key with the given ID. Keys for AEAD_AES_256_CBC_HMAC_SHA512 must be 64 bytes long.
+	GetKey(keyID string) ([]byte, error)
+}
+
+// StaticFieldEncryptionKeyring is a FieldEncryptionKeyring backed by an in memory map of key ID to key.
+// UNCOMMITTED: This API may change in the future.
+type StaticFieldEncryptionKeyring map[string][]byte
+
+// GetKey returns the key with the given ID.
+func (k StaticFieldEncryptionKeyring) GetKey(keyID string) ([]byte, error) {
+	key, ok := k[keyID]
+	if !ok {
+		return nil, errors.New("encryption key not found: " + keyID)
+	}
+
+	return key, nil
+}
+
+// EncryptedField is the value of an encrypted field within a document, following the cross-SDK field level
+// encryption format.
+// UNCOMMITTED: This API may change in the future.
+type EncryptedField struct {
+	// Algorithm is the identifier of the algorithm used to encrypt the field, e.g. AEAD_AES_256_CBC_HMAC_SHA512.
+	Algorithm string `json:"alg"`
+	// KeyID is the ID of the key used to encrypt the field.
+	KeyID string `json:"kid"`
+	// Ciphertext is the base64 encoded ciphertext.
+	Ciphertext string `json:"ciphertext"`
+}
+
+// CryptoManager encrypts and decrypts the values of fields on behalf of a FieldEncryptionTranscoder.
+// Values are passed to and returned from a CryptoManager as JSON.
+// UNCOMMITTED: This API may change in the future.
+type CryptoManager interface {
+	// Encrypt encrypts plaintext using the encrypter identified by alias, e.g. the alias given in the encrypted
+	// struct tag of the field.
+	Encrypt(alias string, plaintext []byte) (*EncryptedField, error)
+
+	// Decrypt decrypts an encrypted field, which may have been written by another SDK.
+	Decrypt(field *EncryptedField) ([]byte, error)
+}
+
+// NewAEADCryptoManager returns a CryptoManager which encrypts fields with AEAD_AES_256_CBC_HMAC_SHA512 using keys
+// from keyring. The alias passed to Encrypt is used as the ID of the key.
+// UNCOMMITTED: This API may change in the future.
+func NewAEADCryptoManager(keyring FieldEncryptionKeyring) CryptoManager {
+	return &aeadCryptoManager{
+		keyring: keyring,
+	}
+}
+
+type aeadCryptoManager struct {
+	keyring FieldEncryptionKeyring
+}
+
+func (m *aeadCryptoManager) Encrypt(alias string, plaintext []byte) (*EncryptedField, error) {
+	key, err := m.keyring.GetKey(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := gocbfieldcrypt.EncryptAEADAES256CBCHMACSHA512(key, plaintext, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedField{
+		Algorithm:  FieldEncryptionAlgorithmAES256CBCHMACSHA512,
+		KeyID:      alias,
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+func (m *aeadCryptoManager) Decrypt(field *EncryptedField) ([]byte, error) {
+	if field.Algorithm != FieldEncryptionAlgorithmAES256CBCHMACSHA512 {
+		return nil, errors.New("unsupported encryption algorithm: " + field.Algorithm)
+	}
+
+	key, err := m.keyring.GetKey(field.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(field.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return gocbfieldcrypt.DecryptAEADAES256CBCHMACSHA512(key, ciphertext, nil)
+}
+
+// CryptoError occurs when a FieldEncryptionTranscoder fails to encrypt or decrypt a field, for example because the key
+// is not available or the ciphertext has been tampered with.
+// UNCOMMITTED: This API may change in the future.
+type CryptoError struct {
+	// FieldName is the name of the field which could not be encrypted or decrypted, without the encrypted prefix.
+	FieldName string
+	// InnerError is the error returned by the CryptoManager.
+	InnerError error
+
+	decrypting bool
+}
+
+// Error returns the string representation of this error.
+func (e *CryptoError) Error() string {
+	if e.decrypting {
+		return fmt.Sprintf("%s: field %s | %s", ErrDecryptionFailure.Error(), e.FieldName, e.InnerError)
+	}
+
+	return fmt.Sprintf("%s: field %s | %s", ErrEncryptionFailure.Error(), e.FieldName, e.InnerError)
+}
+
+// Unwrap returns the underlying reason for the error.
+func (e *CryptoError) Unwrap() error {
+	return e.InnerError
+}
+
+// Is returns true if target is ErrDecryptionFailure for errors which occurred whilst decrypting, or
+// ErrEncryptionFailure for errors which occurred whilst encrypting.
+func (e *CryptoError) Is(target error) bool {
+	if e.decrypting {
+		return target == ErrDecryptionFailure
+	}
+
+	return target == ErrEncryptionFailure
+}
+
+// FieldEncryptionTranscoder wraps a JSON based Transcoder, transparently encrypting the configured fields on write and
+// decrypting any encrypted fields on read.
+//
+// Fields are encrypted if they are configured by path or if the struct field is tagged with `encrypted:"<alias>"`,
+// where alias identifies the encrypter to be used by the CryptoManager, e.g. the key ID when using
+// NewAEADCryptoManager. Tags are also honoured on the fields of nested structs.
+//
+// Encrypted fields follow the cross-SDK field level encryption format, so documents can be read and written
+// interchangeably with other Couchbase SDKs. An encrypted field has its name prefixed with "encrypted$" and its value
+// replaced with an object of the form {"alg":"AEAD_AES_256_CBC_HMAC_SHA512","kid":"<key id>","ciphertext":"<base64>"},
+// where the ciphertext is the JSON encoded field value encrypted with AEAD_AES_256_CBC_HMAC_SHA512.
+// If a field cannot be encrypted or decrypted then a *CryptoError is returned.
+// UNCOMMITTED: This API may change in the future.
+type FieldEncryptionTranscoder struct {
+	transcoder Transcoder
+	manager    CryptoManager
+	alias      string
+	fields     [][]string
+	prefix     string
+}
+
+// NewFieldEncryptionTranscoder returns a new FieldEncryptionTranscoder.
+// Fields are specified as dot separated paths, e.g. "address.street", and are encrypted using the key identified by
+// keyID. If transcoder is nil then a JSONTranscoder is used.
+// UNCOMMITTED: This API may change in the future.
+func NewFieldEncryptionTranscoder(keyring FieldEncryptionKeyring, keyID string, fields []string,
+	transcoder Transcoder) *FieldEncryptionTranscoder {
+	if transcoder == nil {
+		transcoder = NewJSONTranscoder()
+	}
+
+	paths := make([][]string, len(fields))
+	for i, field := range fields {
+		paths[i] = strings.Split(field, ".")
+	}
+
+	return &FieldEncryptionTranscoder{
+		transcoder: transcoder,
+		manager:    NewAEADCryptoManager(keyring),
+		alias:      keyID,
+		fields:     paths,
+		prefix:     DefaultFieldEncryptionPrefix,
+	}
+}
+
+// NewCryptoManagerTranscoder returns a new FieldEncryptionTranscoder which encrypts the struct fields tagged with
+// `encrypted:"<alias>"` using manager. If transcoder is nil then a JSONTranscoder is used.
+// UNCOMMITTED: This API may change in the future.
+func NewCryptoManagerTranscoder(manager CryptoManager, transcoder Transcoder) *FieldEncryptionTranscoder {
+	if transcoder == nil {
+		transcoder = NewJSONTranscoder()
+	}
+
+	return &FieldEncryptionTranscoder{
+		transcoder: transcoder,
+		manager:    manager,
+		prefix:     DefaultFieldEncryptionPrefix,
+	}
+}
+
+// Decode decrypts any encrypted fields within the document and then decodes it using the wrapped transcoder.
+func (t *FieldEncryptionTranscoder) Decode(bytes []byte, flags uint32, out interface{}) error {
+	valueType, _ := gocbcore.DecodeCommonFlags(flags)
+	if valueType != gocbcore.JSONType {
+		return t.transcoder.Decode(bytes, flags, out)
+	}
+
+	decrypted, err := t.decryptValue(bytes)
+	if err != nil {
+		return err
+	}
+
+	return t.transcoder.Decode(decrypted, flags, out)
+}
+
+// Encode encodes the value using the wrapped transcoder and then encrypts the configured and tagged fields.
+func (t *FieldEncryptionTranscoder) Encode(value interface{}) ([]byte, uint32, error) {
+	bytes, flags, err := t.transcoder.Encode(value)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tagged := encryptedFieldsOf(reflect.TypeOf(value))
+	if len(t.fields) == 0 && len(tagged) == 0 {
+		return bytes, flags, nil
+	}
+
+	valueType, _ := gocbcore.DecodeCommonFlags(flags)
+	if valueType != gocbcore.JSONType {
+		return nil, 0, errors.New("field encryption is only supported for JSON values")
+	}
+
+	for _, path := range t.fields {
+		bytes, err = t.encryptPath(bytes, path, t.alias)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	for _, field := range tagged {
+		bytes, err = t.encryptPath(bytes, field.path, field.alias)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return bytes, flags, nil
+}
+
+func (t *FieldEncryptionTranscoder) encryptPath(value []byte, path []string, alias string) ([]byte, error) {
+	var obj map[string]json.RawMessage
+	err := json.Unmarshal(value, &obj)
+	if err != nil || obj == nil {
+		// The path does not exist within this document, so there is nothing to encrypt.
+		return value, nil
+	}
+
+	fieldValue, ok := obj[path[0]]
+	if !ok {
+		return value, nil
+	}
+
+	if len(path) > 1 {
+		newValue, err := t.encryptPath(fieldValue, path[1:], alias)
+		if err != nil {
+			return nil, err
+		}
+		obj[path[0]] = newValue
+
+		return json.Marshal(obj)
+	}
+
+	encrypted, err := t.encryptField(alias, fieldValue)
+	if err != nil {
+		return nil, &CryptoError{
+			FieldName:  path[0],
+			InnerError: err,
+		}
+	}
+
+	delete(obj, path[0])
+	obj[t.prefix+path[0]] = encrypted
+
+	return json.Marshal(obj)
+}
+
+func (t *FieldEncryptionTranscoder) encryptField(alias string, plaintext []byte) (json.RawMessage, error) {
+	field, err := t.manager.Encrypt(alias, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(field)
+}
+
+func (t *FieldEncryptionTranscoder) decryptValue(value []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 {
+		return value, nil
+	}
+
+	switch trimmed[0] {
+	case '{':
+		var obj map[string]json.RawMessage
+		err := json.Unmarshal(trimmed, &obj)
+		if err != nil {
+			return nil, err
+		}
+
+		newObj := make(map[string]json.RawMessage, len(obj))
+		for name, fieldValue := range obj {
+			if strings.HasPrefix(name, t.prefix) {
+				name = strings.TrimPrefix(name, t.prefix)
+
+				decrypted, err := t.decryptField(fieldValue)
+				if err != nil {
+					return nil, &CryptoError{
+						FieldName:  name,
+						InnerError: err,
+						decrypting: true,
+					}
+				}
+
+				fieldValue = decrypted
+			}
+
+			newValue, err := t.decryptValue(fieldValue)
+			if err != nil {
+				return nil, err
+			}
+			newObj[name] = newValue
+		}
+
+		return json.Marshal(newObj)
+	case '[':
+		var arr []json.RawMessage
+		err := json.Unmarshal(trimmed, &arr)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, item := range arr {
+			arr[i], err = t.decryptValue(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return json.Marshal(arr)
+	default:
+		return value, nil
+	}
+}
+
+func (t *FieldEncryptionTranscoder) decryptField(value []byte) ([]byte, error) {
+	var field EncryptedField
+	err := json.Unmarshal(value, &field)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.manager.Decrypt(&field)
+}
+
+type encryptedField struct {
+	path  []string
+	alias string
+}
+
+var encryptedFieldsCache sync.Map
+
+// encryptedFieldsOf returns the JSON paths of the fields of a struct type, including those of nested structs, which
+// are tagged with `encrypted:"<alias>"`.
+func encryptedFieldsOf(typ reflect.Type) []encryptedField {
+	if typ == nil {
+		return nil
+	}
+
+	if cached, ok := encryptedFieldsCache.Load(typ); ok {
+		return cached.([]encryptedField)
+	}
+
+	var fields []encryptedField
+	var walk func(typ reflect.Type, path []string, visited map[reflect.Type]struct{})
+	walk = func(typ reflect.Type, path []string, visited map[reflect.Type]struct{}) {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return
+		}
+		if _, ok := visited[typ]; ok {
+			return
+		}
+		visited[typ] = struct{}{}
+		defer delete(visited, typ)
+
+		for _, field := range jsonStructFieldsOf(typ) {
+			fieldPath := make([]string, len(path)+1)
+			copy(fieldPath, path)
+			fieldPath[len(path)] = field.name
+
+			sf := typ.FieldByIndex(field.index)
+			if alias, ok := sf.Tag.Lookup("encrypted"); ok {
+				fields = append(fields, encryptedField{
+					path:  fieldPath,
+					alias: alias,
+				})
+				continue
+			}
+
+			walk(sf.Type, fieldPath, visited)
+		}
+	}
+	walk(typ, nil, make(map[reflect.Type]struct{}))
+
+	encryptedFieldsCache.Store(typ, fields)
+	return fields
+}
+
+type jsonStructField struct {
+	name   string
+	index  []int
+	tagged bool
+}
+
+var jsonStructFieldsCache sync.Map
+
+// jsonStructFieldsOf returns the fields of a struct type which are encoded, following the rules of encoding/json.
+func jsonStructFieldsOf(typ reflect.Type) []jsonStructField {
+	if cached, ok := jsonStructFieldsCache.Load(typ); ok {
+		return cached.([]jsonStructField)
+	}
+
+	var candidates []jsonStructField
+	var walk func(typ reflect.Type, index []int, visited map[reflect.Type]struct{})
+	walk = func(typ reflect.Type, index []int, visited map[reflect.Type]struct{}) {
+		if _, ok := visited[typ]; ok {
+			return
+		}
+		visited[typ] = struct{}{}
+
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+
+			fieldIndex := make([]int, len(index)+1)
+			copy(fieldIndex, index)
+			fieldIndex[len(index)] = i
+
+			name := strings.Split(tag, ",")[0]
+
+			if sf.Anonymous {
+				embeddedType := sf.Type
+				if embeddedType.Kind() == reflect.Ptr {
+					embeddedType = embeddedType.Elem()
+				}
+
+				if name == "" && embeddedType.Kind() == reflect.Struct {
+					walk(embeddedType, fieldIndex, visited)
+					continue
+				}
+			}
+
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			field := jsonStructField{
+				name:   name,
+				index:  fieldIndex,
+				tagged: name != "",
+			}
+			if field.name == "" {
+				field.name = sf.Name
+			}
+
+			candidates = append(candidates, field)
+		}
+	}
+	walk(typ, nil, make(map[reflect.Type]struct{}))
+
+	// Where several fields share a name, the shallowest wins, with tagged fields preferred at the same depth. If that
+	// still leaves more than one then none of them are encoded.
+	var fields []jsonStructField
+	for _, candidate := range candidates {
+		dominant := true
+		for _, other := range candidates {
+			if other.name != candidate.name || equalIntSlices(other.index, candidate.index) {
+				continue
+			}
+
+			if len(other.index) < len(candidate.index) ||
+				(len(other.index) == len(candidate.index) && (other.tagged || !candidate.tagged)) {
+				dominant = false
+				break
+			}
+		}
+
+		if dominant {
+			fields = append(fields, candidate)
+		}
+	}
+
+	jsonStructFieldsCache.Store(typ, fields)
+	return fields
+}
+
+func equalIntSlices(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,166 @@
+package gocb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+func (suite *UnitTestSuite) fieldEncryptionTestKey() []byte {
+	key := make([]byte, 64)
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	return key
+}
+
+func (suite *UnitTestSuite) TestFieldEncryptionTranscoder() {
+	type address struct {
+		Street string `json:"street"`
+		City   string `json:"city"`
+	}
+	type person struct {
+		Name    string  `json:"name"`
+		SSN     string  `json:"ssn"`
+		Address address `json:"address"`
+	}
+
+	keyring := StaticFieldEncryptionKeyring{
+		"mykey": suite.fieldEncryptionTestKey(),
+	}
+	transcoder := NewFieldEncryptionTranscoder(keyring, "mykey", []string{"ssn", "address.street", "missing"}, nil)
+
+	val := person{
+		Name: "barry",
+		SSN:  "123-45-6789",
+		Address: address{
+			Street: "1 Some Street",
+			City:   "Manchester",
+		},
+	}
+
+	bytes, flags, err := transcoder.Encode(val)
+	suite.Require().Nil(err, err)
+
+	var raw map[string]json.RawMessage
+	err = json.Unmarshal(bytes, &raw)
+	suite.Require().Nil(err, err)
+
+	suite.Assert().NotContains(raw, "ssn")
+	suite.Assert().Contains(raw, "name")
+	suite.Require().Contains(raw, "encrypted$ssn")
+
+	var encrypted EncryptedField
+	err = json.Unmarshal(raw["encrypted$ssn"], &encrypted)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("AEAD_AES_256_CBC_HMAC_SHA512", encrypted.Algorithm)
+	suite.Assert().Equal("mykey", encrypted.KeyID)
+	_, err = base64.StdEncoding.DecodeString(encrypted.Ciphertext)
+	suite.Assert().Nil(err, err)
+
+	var rawAddress map[string]json.RawMessage
+	err = json.Unmarshal(raw["address"], &rawAddress)
+	suite.Require().Nil(err, err)
+	suite.Assert().Contains(rawAddress, "encrypted$street")
+	suite.Assert().Contains(rawAddress, "city")
+
+	var decoded person
+	err = transcoder.Decode(bytes, flags, &decoded)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(val, decoded)
+
+	err = NewFieldEncryptionTranscoder(StaticFieldEncryptionKeyring{}, "mykey", nil, nil).
+		Decode(bytes, flags, &decoded)
+	if !errors.Is(err, ErrDecryptionFailure) {
+		suite.T().Fatalf("Expected error to be decryption failure but was %v", err)
+	}
+
+	var cryptoErr *CryptoError
+	suite.Require().True(errors.As(err, &cryptoErr))
+	suite.Assert().Equal("ssn", cryptoErr.FieldName)
+}
+
+type fieldEncryptionTestCryptoManager struct {
+	aliases []string
+}
+
+func (m *fieldEncryptionTestCryptoManager) Encrypt(alias string, plaintext []byte) (*EncryptedField, error) {
+	m.aliases = append(m.aliases, alias)
+
+	return &EncryptedField{
+		Algorithm:  "TEST",
+		KeyID:      alias,
+		Ciphertext: base64.StdEncoding.EncodeToString(plaintext),
+	}, nil
+}
+
+func (m *fieldEncryptionTestCryptoManager) Decrypt(field *EncryptedField) ([]byte, error) {
+	if field.Algorithm != "TEST" {
+		return nil, errors.New("unsupported algorithm")
+	}
+
+	return base64.StdEncoding.DecodeString(field.Ciphertext)
+}
+
+func (suite *UnitTestSuite) TestCryptoManagerTranscoderTaggedFields() {
+	type address struct {
+		Street string `json:"street" encrypted:"street-key"`
+		City   string `json:"city"`
+	}
+	type person struct {
+		Name    string   `json:"name"`
+		SSN     string   `json:"ssn" encrypted:"ssn-key"`
+		Address *address `json:"address"`
+	}
+
+	manager := &fieldEncryptionTestCryptoManager{}
+	transcoder := NewCryptoManagerTranscoder(manager, nil)
+
+	val := person{
+		Name: "barry",
+		SSN:  "123-45-6789",
+		Address: &address{
+			Street: "1 Some Street",
+			City:   "Manchester",
+		},
+	}
+
+	bytes, flags, err := transcoder.Encode(&val)
+	suite.Require().Nil(err, err)
+	suite.Assert().ElementsMatch([]string{"ssn-key", "street-key"}, manager.aliases)
+
+	var raw map[string]json.RawMessage
+	err = json.Unmarshal(bytes, &raw)
+	suite.Require().Nil(err, err)
+	suite.Assert().NotContains(raw, "ssn")
+	suite.Assert().Contains(raw, "encrypted$ssn")
+
+	var rawAddress map[string]json.RawMessage
+	err = json.Unmarshal(raw["address"], &rawAddress)
+	suite.Require().Nil(err, err)
+	suite.Assert().Contains(rawAddress, "encrypted$street")
+	suite.Assert().Contains(rawAddress, "city")
+
+	var decoded person
+	err = transcoder.Decode(bytes, flags, &decoded)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(val, decoded)
+
+	// Values without tagged fields, including non JSON values, are passed through untouched.
+	bytes, flags, err = NewCryptoManagerTranscoder(manager, NewRawBinaryTranscoder()).Encode([]byte("binary"))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal([]byte("binary"), bytes)
+
+	// Documents written by another SDK with an unsupported algorithm fail to decrypt.
+	doc := []byte(`{"name":"barry","encrypted$ssn":{"alg":"UNKNOWN","kid":"ssn-key","ciphertext":"AAAA"}}`)
+	err = transcoder.Decode(doc, gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression), &decoded)
+
+	var cryptoErr *CryptoError
+	suite.Require().True(errors.As(err, &cryptoErr))
+	suite.Assert().Equal("ssn", cryptoErr.FieldName)
+	suite.Assert().True(errors.Is(err, ErrDecryptionFailure))
+	suite.Assert().False(errors.Is(err, ErrEncryptionFailure))
+}
@@ -0,0 +1,139 @@
+package gocb
+
+import (
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+// DocumentFormat is the format of a stored document, as indicated by the flags stored alongside it.
+// UNCOMMITTED: This API may change in the future.
+type DocumentFormat uint8
+
+const (
+	// DocumentFormatUnknown indicates that the flags do not identify a known format, e.g. the flags were set by a
+	// legacy client using a client specific format.
+	DocumentFormatUnknown DocumentFormat = iota
+
+	// DocumentFormatJSON indicates a JSON document.
+	DocumentFormatJSON
+
+	// DocumentFormatBinary indicates a document containing raw binary data.
+	DocumentFormatBinary
+
+	// DocumentFormatString indicates a document containing a raw UTF-8 string.
+	DocumentFormatString
+
+	// DocumentFormatPrivate indicates a document encoded in a format private to the SDK which wrote it, such as a
+	// serialized Java object, a Python pickle or a document written by gocbmsgpack.MsgPackTranscoder. These documents
+	// can only be decoded by the SDK which wrote them.
+	DocumentFormatPrivate
+)
+
+// commonFlagsFormatPrivate is the common flags format used by SDKs for their own private, language specific,
+// encodings.
+const commonFlagsFormatPrivate = 0x01
+
+// String returns the name of the format.
+func (f DocumentFormat) String() string {
+	switch f {
+	case DocumentFormatJSON:
+		return "json"
+	case DocumentFormatBinary:
+		return "binary"
+	case DocumentFormatString:
+		return "string"
+	case DocumentFormatPrivate:
+		return "private"
+	}
+
+	return "unknown"
+}
+
+// DataType is the type of a document's value as indicated by its common flags.
+// UNCOMMITTED: This API may change in the future.
+type DataType gocbcore.DataType
+
+const (
+	// DataTypeUnknown indicates that the flags do not identify a data type.
+	DataTypeUnknown DataType = DataType(gocbcore.UnknownType)
+
+	// DataTypeJSON indicates a JSON value.
+	DataTypeJSON DataType = DataType(gocbcore.JSONType)
+
+	// DataTypeBinary indicates a raw binary value.
+	DataTypeBinary DataType = DataType(gocbcore.BinaryType)
+
+	// DataTypeString indicates a raw UTF-8 string value.
+	DataTypeString DataType = DataType(gocbcore.StringType)
+)
+
+// CompressionType is the compression of a document's value as indicated by its common flags.
+// UNCOMMITTED: This API may change in the future.
+type CompressionType gocbcore.CompressionType
+
+const (
+	// CompressionTypeUnknown indicates that the flags specify an unknown compression.
+	CompressionTypeUnknown CompressionType = CompressionType(gocbcore.UnknownCompression)
+
+	// CompressionTypeNone indicates that the value is not compressed.
+	CompressionTypeNone CompressionType = CompressionType(gocbcore.NoCompression)
+)
+
+// DecodeCommonFlags returns the data type and compression of a document stored with the given flags. Both the
+// common flags used by current SDKs and the flags used by legacy clients are recognized. DocumentFormatFromFlags
+// can be used to further identify documents with an unknown data type.
+// UNCOMMITTED: This API may change in the future.
+func DecodeCommonFlags(flags uint32) (DataType, CompressionType) {
+	valueType, compression := gocbcore.DecodeCommonFlags(flags)
+	return DataType(valueType), CompressionType(compression)
+}
+
+// DocumentFormatFromFlags returns the format of a document stored with the given flags. Both the common flags used
+// by current SDKs and the flags used by legacy clients are recognized.
+// UNCOMMITTED: This API may change in the future.
+func DocumentFormatFromFlags(flags uint32) DocumentFormat {
+	if (flags>>24)&0x0f == commonFlagsFormatPrivate {
+		return DocumentFormatPrivate
+	}
+
+	valueType, _ := gocbcore.DecodeCommonFlags(flags)
+	switch valueType {
+	case gocbcore.JSONType:
+		return DocumentFormatJSON
+	case gocbcore.BinaryType:
+		return DocumentFormatBinary
+	case gocbcore.StringType:
+		return DocumentFormatString
+	}
+
+	return DocumentFormatUnknown
+}
+
+// RawDocument can be passed to JSONTranscoder and LegacyTranscoder in order to read a document of any format without
+// decoding it, e.g. to read documents written in formats private to other SDKs. Format can then be used to decide
+// how the document should be handled. RawFlagsTranscoder can also write a RawDocument, with its flags unchanged.
+// UNCOMMITTED: This API may change in the future.
+type RawDocument struct {
+	// Format is the format of the document, as indicated by Flags.
+	Format DocumentFormat
+
+	// Flags are the raw flags stored alongside the document.
+	Flags uint32
+
+	// Value is the raw, undecoded, bytes of the document.
+	Value []byte
+}
+
+// decodeRawDocument populates out if it is a *RawDocument, returning false if it is not.
+func decodeRawDocument(bytes []byte, flags uint32, out interface{}) bool {
+	doc, ok := out.(*RawDocument)
+	if !ok {
+		return false
+	}
+
+	*doc = RawDocument{
+		Format: DocumentFormatFromFlags(flags),
+		Flags:  flags,
+		Value:  bytes,
+	}
+	return true
+}
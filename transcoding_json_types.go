@@ -0,0 +1,199 @@
+package gocb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// JSONTypeEncodeFunc converts a value of a type registered with JSONTranscoder.RegisterType into the value to be
+// encoded in its place, e.g. a time.Time into an int64. The returned value is encoded using encoding/json, or the
+// JSONSerializer of the transcoder.
+// UNCOMMITTED: This API may change in the future.
+type JSONTypeEncodeFunc func(value interface{}) (interface{}, error)
+
+// JSONTypeDecodeFunc decodes the JSON of a value of a type registered with JSONTranscoder.RegisterType, it must
+// return a value of the registered type.
+// UNCOMMITTED: This API may change in the future.
+type JSONTypeDecodeFunc func(data []byte) (interface{}, error)
+
+// JSONTimeFormat specifies how JSONTranscoder encodes time.Time values.
+// UNCOMMITTED: This API may change in the future.
+type JSONTimeFormat uint8
+
+const (
+	// JSONTimeFormatRFC3339 encodes times as RFC 3339 strings, this is the standard encoding/json behaviour.
+	JSONTimeFormatRFC3339 JSONTimeFormat = iota
+
+	// JSONTimeFormatEpochMillis encodes times as the number of milliseconds since the Unix epoch.
+	JSONTimeFormatEpochMillis
+
+	// JSONTimeFormatEpochSeconds encodes times as the number of seconds since the Unix epoch.
+	JSONTimeFormatEpochSeconds
+)
+
+var jsonTimeType = reflect.TypeOf(time.Time{})
+
+type jsonTypeCodec struct {
+	encode JSONTypeEncodeFunc
+	decode JSONTypeDecodeFunc
+}
+
+// jsonTranscoderState holds the settings of a JSONTranscoder which can be changed after it is created, it is held
+// by pointer so that the transcoder itself can be copied.
+type jsonTranscoderState struct {
+	lock           sync.RWMutex
+	codecs         map[reflect.Type]jsonTypeCodec
+	decodeFallback JSONDecodeFallbackFunc
+}
+
+func newJSONTranscoderState() *jsonTranscoderState {
+	return &jsonTranscoderState{}
+}
+
+// RegisterType registers functions to encode and decode values of the same type as sample, which are used in place of
+// encoding/json when the value passed to Encode, or the value pointed to by the out argument of Decode, is of that
+// type. Values of the type nested within structs, slices and maps are handled by encoding/json, implement
+// json.Marshaler and json.Unmarshaler on the type for those. Registering a type which is already registered replaces
+// the existing functions.
+// Types should be registered before the transcoder is used.
+// UNCOMMITTED: This API may change in the future.
+func (t *JSONTranscoder) RegisterType(sample interface{}, encode JSONTypeEncodeFunc, decode JSONTypeDecodeFunc) error {
+	if sample == nil {
+		return makeInvalidArgumentsError("sample cannot be nil")
+	}
+	if encode == nil || decode == nil {
+		return makeInvalidArgumentsError("encode and decode functions must be provided")
+	}
+
+	t.setCodec(reflect.TypeOf(sample), &jsonTypeCodec{
+		encode: encode,
+		decode: decode,
+	})
+	return nil
+}
+
+// SetTimeFormat sets how time.Time values passed to Encode and Decode are encoded, by default they are encoded as
+// RFC 3339 strings. As with RegisterType, this does not apply to times nested within other values. When an epoch
+// based format is set, RFC 3339 strings are still accepted when decoding so that existing documents can be read.
+// UNCOMMITTED: This API may change in the future.
+func (t *JSONTranscoder) SetTimeFormat(format JSONTimeFormat) error {
+	var unit time.Duration
+	switch format {
+	case JSONTimeFormatRFC3339:
+		t.setCodec(jsonTimeType, nil)
+		return nil
+	case JSONTimeFormatEpochMillis:
+		unit = time.Millisecond
+	case JSONTimeFormatEpochSeconds:
+		unit = time.Second
+	default:
+		return makeInvalidArgumentsError(fmt.Sprintf("unknown time format %d", format))
+	}
+
+	t.setCodec(jsonTimeType, &jsonTypeCodec{
+		encode: func(value interface{}) (interface{}, error) {
+			return value.(time.Time).UnixNano() / int64(unit), nil
+		},
+		decode: func(data []byte) (interface{}, error) {
+			if len(data) > 0 && data[0] == '"' {
+				var tm time.Time
+				err := json.Unmarshal(data, &tm)
+				return tm, err
+			}
+
+			var epoch json.Number
+			err := json.Unmarshal(data, &epoch)
+			if err != nil {
+				return nil, err
+			}
+
+			if epochInt, err := epoch.Int64(); err == nil {
+				return time.Unix(0, epochInt*int64(unit)), nil
+			}
+
+			epochFloat, err := epoch.Float64()
+			if err != nil {
+				return nil, err
+			}
+
+			return time.Unix(0, int64(epochFloat*float64(unit))), nil
+		},
+	})
+	return nil
+}
+
+func (t *JSONTranscoder) setCodec(typ reflect.Type, codec *jsonTypeCodec) {
+	if t.state == nil {
+		t.state = newJSONTranscoderState()
+	}
+
+	t.state.lock.Lock()
+	defer t.state.lock.Unlock()
+
+	if codec == nil {
+		delete(t.state.codecs, typ)
+		return
+	}
+
+	if t.state.codecs == nil {
+		t.state.codecs = make(map[reflect.Type]jsonTypeCodec)
+	}
+	t.state.codecs[typ] = *codec
+}
+
+func (t *JSONTranscoder) getCodec(typ reflect.Type) (jsonTypeCodec, bool) {
+	if t.state == nil {
+		return jsonTypeCodec{}, false
+	}
+
+	t.state.lock.RLock()
+	defer t.state.lock.RUnlock()
+
+	codec, ok := t.state.codecs[typ]
+	return codec, ok
+}
+
+func (t *JSONTranscoder) marshal(value interface{}) ([]byte, error) {
+	if codec, ok := t.getCodec(reflect.TypeOf(value)); ok {
+		encoded, err := codec.encode(value)
+		if err != nil {
+			return nil, err
+		}
+		value = encoded
+	}
+
+	if t.serializer != nil {
+		return t.serializer.Marshal(value)
+	}
+
+	return json.Marshal(value)
+}
+
+func (t *JSONTranscoder) unmarshal(data []byte, out interface{}) error {
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() == reflect.Ptr && !outVal.IsNil() {
+		if codec, ok := t.getCodec(outVal.Type().Elem()); ok {
+			decoded, err := codec.decode(data)
+			if err != nil {
+				return err
+			}
+
+			decodedVal := reflect.ValueOf(decoded)
+			if !decodedVal.IsValid() || decodedVal.Type() != outVal.Type().Elem() {
+				return fmt.Errorf("decode function for %s returned a value of type %T", outVal.Type().Elem(), decoded)
+			}
+
+			outVal.Elem().Set(decodedVal)
+			return nil
+		}
+	}
+
+	if t.serializer != nil {
+		return t.serializer.Unmarshal(data, out)
+	}
+
+	return json.Unmarshal(data, &out)
+}
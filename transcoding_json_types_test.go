@@ -0,0 +1,112 @@
+package gocb
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	gocbcore "github.com/couchbase/gocbcore/v10"
+)
+
+type testJSONTypesDocument struct {
+	Created time.Time `json:"created"`
+}
+
+func (suite *UnitTestSuite) TestJSONTranscoderTimeFormat() {
+	transcoder := NewJSONTranscoder()
+	err := transcoder.SetTimeFormat(JSONTimeFormatEpochMillis)
+	suite.Require().Nil(err, err)
+
+	created := time.Unix(1600000000, 123000000)
+
+	b, flags, err := transcoder.Encode(created)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression), flags)
+	suite.Assert().Equal("1600000000123", string(b))
+
+	var decoded time.Time
+	err = transcoder.Decode(b, flags, &decoded)
+	suite.Require().Nil(err, err)
+	suite.Assert().True(created.Equal(decoded))
+
+	// Existing documents using the standard format can still be read.
+	err = transcoder.Decode([]byte(`"2020-09-13T12:26:40.123Z"`), flags, &decoded)
+	suite.Require().Nil(err, err)
+	suite.Assert().True(created.Equal(decoded))
+
+	// Times nested within other values are handled by encoding/json.
+	doc := testJSONTypesDocument{Created: created}
+	b, _, err = transcoder.Encode(doc)
+	suite.Require().Nil(err, err)
+	expected, err := json.Marshal(doc)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(expected, b)
+
+	err = transcoder.SetTimeFormat(JSONTimeFormatRFC3339)
+	suite.Require().Nil(err, err)
+
+	b, _, err = transcoder.Encode(created)
+	suite.Require().Nil(err, err)
+	expected, err = json.Marshal(created)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(expected, b)
+
+	err = transcoder.SetTimeFormat(JSONTimeFormat(99))
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
+
+type testJSONTypesCents int64
+
+func (suite *UnitTestSuite) TestJSONTranscoderRegisterType() {
+	transcoder := NewJSONTranscoder()
+	err := transcoder.RegisterType(testJSONTypesCents(0), func(value interface{}) (interface{}, error) {
+		cents := value.(testJSONTypesCents)
+		return strconv.FormatFloat(float64(cents)/100, 'f', 2, 64), nil
+	}, func(data []byte) (interface{}, error) {
+		var amount string
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return nil, err
+		}
+
+		f, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, err
+		}
+		return testJSONTypesCents(f*100 + 0.5), nil
+	})
+	suite.Require().Nil(err, err)
+
+	b, flags, err := transcoder.Encode(testJSONTypesCents(1250))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(`"12.50"`, string(b))
+
+	var decoded testJSONTypesCents
+	err = transcoder.Decode(b, flags, &decoded)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(testJSONTypesCents(1250), decoded)
+
+	// A transcoder created without a constructor can also have types registered.
+	var zero JSONTranscoder
+	err = zero.RegisterType(testJSONTypesCents(0), func(value interface{}) (interface{}, error) {
+		return int64(value.(testJSONTypesCents)), nil
+	}, func(data []byte) (interface{}, error) {
+		return nil, nil
+	})
+	suite.Require().Nil(err, err)
+
+	b, _, err = zero.Encode(testJSONTypesCents(1250))
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("1250", string(b))
+
+	// A decode function must return a value of the registered type.
+	err = zero.Decode(b, flags, &decoded)
+	suite.Assert().True(errors.Is(err, ErrDecodingFailure), "expected decoding failure but was %v", err)
+
+	err = transcoder.RegisterType(nil, nil, nil)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected error to be invalid argument but was %v", err)
+	}
+}
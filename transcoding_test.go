@@ -2,6 +2,7 @@ package gocb
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -509,6 +510,119 @@ func (suite *UnitTestSuite) TestDecodeJSON() {
 	}
 }
 
+func (suite *UnitTestSuite) TestDecodeJSONFallback() {
+	type jsonType struct {
+		Name string `json:"name"`
+	}
+
+	malformed := []byte(`{"name":"something"`)
+	flags := gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression)
+	transcoder := NewJSONTranscoder()
+
+	var actual jsonType
+	err := transcoder.Decode(malformed, flags, &actual)
+	suite.Require().NotNil(err)
+	suite.Assert().True(errors.Is(err, ErrDecodingFailure))
+
+	var decodeErr *JSONDecodeError
+	suite.Require().True(errors.As(err, &decodeErr))
+	suite.Assert().Equal(malformed, decodeErr.Raw)
+
+	var syntaxErr *json.SyntaxError
+	suite.Assert().True(errors.As(err, &syntaxErr))
+
+	var quarantined [][]byte
+	transcoder.SetDecodeFallback(func(data []byte, out interface{}, err error) error {
+		quarantined = append(quarantined, data)
+		out.(*jsonType).Name = "quarantined"
+		return nil
+	})
+
+	err = transcoder.Decode(malformed, flags, &actual)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("quarantined", actual.Name)
+	suite.Assert().Equal([][]byte{malformed}, quarantined)
+
+	err = transcoder.Decode([]byte(`{"name":"valid"}`), flags, &actual)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal("valid", actual.Name)
+	suite.Assert().Len(quarantined, 1)
+
+	transcoder.SetDecodeFallback(nil)
+	err = transcoder.Decode(malformed, flags, &actual)
+	suite.Assert().True(errors.As(err, &decodeErr))
+}
+
+func (suite *UnitTestSuite) TestDecodeRawDocument() {
+	type test struct {
+		flags  uint32
+		format DocumentFormat
+	}
+	tests := []test{
+		{flags: 0x02000000, format: DocumentFormatJSON},
+		{flags: 0x01000000, format: DocumentFormatPrivate},
+		{flags: 0x03000000, format: DocumentFormatBinary},
+		{flags: 0x04000000, format: DocumentFormatString},
+		{flags: 0x05000000, format: DocumentFormatUnknown},
+	}
+
+	value := []byte{0xac, 0xed, 0x00, 0x05}
+	for _, transcoder := range []Transcoder{NewJSONTranscoder(), NewLegacyTranscoder()} {
+		for _, tt := range tests {
+			suite.Assert().Equal(tt.format, DocumentFormatFromFlags(tt.flags))
+
+			var doc RawDocument
+			err := transcoder.Decode(value, tt.flags, &doc)
+			suite.Require().Nil(err, err)
+			suite.Assert().Equal(RawDocument{Format: tt.format, Flags: tt.flags, Value: value}, doc)
+		}
+	}
+
+	var actual interface{}
+	err := NewJSONTranscoder().Decode(value, 0x01000000, &actual)
+	var mismatchErr *DataTypeMismatchError
+	suite.Require().True(errors.As(err, &mismatchErr), "expected data type mismatch but was %v", err)
+	suite.Assert().Equal("private", mismatchErr.Actual)
+	suite.Assert().True(errors.Is(err, ErrDecodingFailure))
+
+	result := &GetResult{flags: 0x01000000}
+	suite.Assert().Equal(DocumentFormatPrivate, result.Format())
+}
+
+func (suite *UnitTestSuite) TestRawFlagsTranscoder() {
+	transcoder := NewRawFlagsTranscoder()
+	value := []byte{0xac, 0xed, 0x00, 0x05}
+
+	bytes, flags, err := transcoder.Encode(RawDocument{Flags: 0x00000102, Value: value})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(value, bytes)
+	suite.Assert().Equal(uint32(0x00000102), flags)
+
+	var iface interface{} = &RawDocument{Flags: 0x00000102, Value: value}
+	_, flags, err = transcoder.Encode(&iface)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(uint32(0x00000102), flags)
+
+	_, _, err = transcoder.Encode(value)
+	if !errors.Is(err, ErrInvalidArgument) {
+		suite.T().Fatalf("Expected invalid argument error but was %v", err)
+	}
+
+	var doc RawDocument
+	err = transcoder.Decode(value, 0x00000102, &doc)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(RawDocument{Format: DocumentFormatUnknown, Flags: 0x00000102, Value: value}, doc)
+
+	var raw []byte
+	err = transcoder.Decode(value, 0x02000000, &raw)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(value, raw)
+
+	var str string
+	err = transcoder.Decode(value, 0x04000000, &str)
+	suite.Assert().NotNil(err)
+}
+
 func (suite *UnitTestSuite) TestDecodeJSONInterface() {
 	type jsonType struct {
 		Name string `json:"name"`
@@ -859,3 +973,94 @@ func (suite *UnitTestSuite) TestDecodeString() {
 		}
 	}
 }
+
+func (suite *UnitTestSuite) TestDecodeDataTypeMismatch() {
+	jsonFlags := gocbcore.EncodeCommonFlags(gocbcore.JSONType, gocbcore.NoCompression)
+	stringFlags := gocbcore.EncodeCommonFlags(gocbcore.StringType, gocbcore.NoCompression)
+	binaryFlags := gocbcore.EncodeCommonFlags(gocbcore.BinaryType, gocbcore.NoCompression)
+
+	dataType, compression := DecodeCommonFlags(jsonFlags)
+	suite.Assert().Equal(DataTypeJSON, dataType)
+	suite.Assert().Equal(CompressionTypeNone, compression)
+
+	dataType, _ = DecodeCommonFlags(stringFlags)
+	suite.Assert().Equal(DataTypeString, dataType)
+
+	dataType, _ = DecodeCommonFlags(binaryFlags)
+	suite.Assert().Equal(DataTypeBinary, dataType)
+
+	tests := []struct {
+		transcoder Transcoder
+		flags      uint32
+		name       string
+		expected   string
+		actual     string
+	}{
+		{NewRawStringTranscoder(), jsonFlags, "RawStringTranscoder", "string", "json"},
+		{NewRawJSONTranscoder(), stringFlags, "RawJSONTranscoder", "json", "string"},
+		{NewRawBinaryTranscoder(), jsonFlags, "RawBinaryTranscoder", "binary", "json"},
+		{NewJSONTranscoder(), binaryFlags, "JSONTranscoder", "json", "binary"},
+		{NewLegacyTranscoder(), 0x01000000, "LegacyTranscoder", "json, string or binary", "private"},
+	}
+	for _, tt := range tests {
+		var out interface{}
+		err := tt.transcoder.Decode([]byte(`{"name":"something"}`), tt.flags, &out)
+
+		var mismatchErr *DataTypeMismatchError
+		suite.Require().True(errors.As(err, &mismatchErr), "expected DataTypeMismatchError but was %v", err)
+		suite.Assert().Equal(tt.name, mismatchErr.Transcoder)
+		suite.Assert().Equal(tt.expected, mismatchErr.Expected)
+		suite.Assert().Equal(tt.actual, mismatchErr.Actual)
+		suite.Assert().True(errors.Is(err, ErrDecodingFailure))
+	}
+}
+
+func (suite *IntegrationTestSuite) TestRawStringTranscoderDecodeJSONDocument() {
+	suite.skipIfUnsupported(KeyValueFeature)
+
+	_, err := globalCollection.Upsert("rawStringDecodeJSON", map[string]string{"name": "something"}, &UpsertOptions{
+		Transcoder: NewJSONTranscoder(),
+	})
+	suite.Require().Nil(err, err)
+
+	res, err := globalCollection.Get("rawStringDecodeJSON", &GetOptions{
+		Transcoder: NewRawStringTranscoder(),
+	})
+	suite.Require().Nil(err, err)
+
+	var content string
+	err = res.Content(&content)
+
+	var mismatchErr *DataTypeMismatchError
+	suite.Require().True(errors.As(err, &mismatchErr), "expected DataTypeMismatchError but was %v", err)
+	suite.Assert().Equal("json", mismatchErr.Actual)
+	suite.Assert().Equal("string", mismatchErr.Expected)
+	suite.Assert().True(errors.Is(err, ErrDecodingFailure))
+	suite.Assert().Contains(err.Error(), "document is json")
+}
+
+func (suite *IntegrationTestSuite) TestRawFlagsTranscoderRoundTrip() {
+	suite.skipIfUnsupported(KeyValueFeature)
+
+	// Flags which do not use the common flags format, as written by an old client for a Java-serialized value.
+	doc := RawDocument{
+		Flags: 0x00000102,
+		Value: []byte{0xac, 0xed, 0x00, 0x05, 0x74, 0x00, 0x02, 0x68, 0x69},
+	}
+	_, err := globalCollection.Upsert("rawFlagsRoundTrip", doc, &UpsertOptions{
+		Transcoder: NewRawFlagsTranscoder(),
+	})
+	suite.Require().Nil(err, err)
+
+	res, err := globalCollection.Get("rawFlagsRoundTrip", &GetOptions{
+		Transcoder: NewRawFlagsTranscoder(),
+	})
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(doc.Flags, res.Flags())
+
+	var actual RawDocument
+	err = res.Content(&actual)
+	suite.Require().Nil(err, err)
+	suite.Assert().Equal(doc.Flags, actual.Flags)
+	suite.Assert().Equal(doc.Value, actual.Value)
+}
@@ -68,6 +68,15 @@ type ViewOptions struct {
 
 	Namespace DesignDocumentNamespace
 
+	// ScopeName and CollectionName state which collection the view is expected to index. Views only ever index
+	// documents within the default collection of the bucket, so these may only be empty or "_default", any other
+	// value causes the query to fail with ErrFeatureNotAvailable rather than silently returning no results for
+	// documents held in other collections.
+	// UNCOMMITTED: This API may change in the future.
+	ScopeName string
+	// UNCOMMITTED: This API may change in the future.
+	CollectionName string
+
 	Timeout       time.Duration
 	RetryStrategy RetryStrategy
 
@@ -82,6 +91,16 @@ type ViewOptions struct {
 	}
 }
 
+func (opts *ViewOptions) validateCollection() error {
+	if (opts.ScopeName == "" || opts.ScopeName == "_default") &&
+		(opts.CollectionName == "" || opts.CollectionName == "_default") {
+		return nil
+	}
+
+	return wrapError(ErrFeatureNotAvailable,
+		"views only index the default collection, they cannot be queried against a non-default scope or collection")
+}
+
 func (opts *ViewOptions) toURLValues() (*url.Values, error) {
 	options := &url.Values{}
 